@@ -10,11 +10,10 @@ package main
 import "C"
 
 import (
+	"context"
 	"encoding/csv"
 	"rpc/pkg/utils"
 	"strings"
-
-	log "github.com/sirupsen/logrus"
 )
 
 //export rpcCheckAccess
@@ -29,7 +28,7 @@ func rpcCheckAccess() int {
 //export rpcExec
 func rpcExec(Input *C.char, Output **C.char) int {
 	if accessStatus := rpcCheckAccess(); accessStatus != int(utils.Success) {
-		*Output = C.CString(AccessErrMsg)
+		*Output = C.CString(accessGuidance(utils.ReturnCode(accessStatus)))
 		return accessStatus
 	}
 
@@ -44,7 +43,8 @@ func rpcExec(Input *C.char, Output **C.char) int {
 		return int(utils.InvalidParameterCombination)
 	}
 	args = append([]string{"rpc"}, args...)
-	rc := runRPC(args)
+	// the signals belong to the process loading the library, they do not cancel the command
+	rc := runRPC(context.Background(), args)
 	if rc != utils.Success {
 		*Output = C.CString("rpcExec failed: " + inputString)
 	}
@@ -5,16 +5,34 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"rpc/internal/agent"
 	"rpc/internal/amt"
 	"rpc/internal/flags"
 	"rpc/internal/local"
+	"rpc/internal/logging"
+	"rpc/internal/mqtt"
+	"rpc/internal/output"
 	"rpc/internal/rps"
+	"rpc/internal/service"
+	"rpc/internal/telemetry"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
-
-	log "github.com/sirupsen/logrus"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 )
 
+var log = logging.For(logging.ModuleRPC)
+
 const AccessErrMsg = "Failed to execute due to access issues. " +
 	"Please ensure that Intel ME is present, " +
 	"the MEI driver is installed, " +
@@ -22,66 +40,259 @@ const AccessErrMsg = "Failed to execute due to access issues. " +
 
 func checkAccess() (utils.ReturnCode, error) {
 	amtCommand := amt.NewAMTCommand()
-	rc, err := amtCommand.Initialize()
-	if rc != utils.Success || err != nil {
+	if err := amtCommand.Initialize(); err != nil {
+		switch rc := rpcerr.ReturnCodeOf(err); rc {
+		case utils.NotAdministrator, utils.MEIDriverMissing, utils.UnsupportedPlatform:
+			return rc, err
+		}
 		return utils.AmtNotDetected, err
 	}
 	return utils.Success, nil
 }
 
-func runRPC(args []string) utils.ReturnCode {
-	flags, rc := parseCommandLine(args)
+// accessGuidance returns what the user can do about a failed access check
+func accessGuidance(rc utils.ReturnCode) string {
+	switch {
+	case rc == utils.NotAdministrator && runtime.GOOS == "windows":
+		return "rpc needs administrator privileges, run it from a command prompt opened with Run as administrator."
+	case rc == utils.NotAdministrator:
+		return "rpc needs root privileges to open the MEI device, run it as root or with sudo."
+	case rc == utils.MEIDriverMissing && runtime.GOOS == "windows":
+		return "No MEI device was found. Install the Intel Management Engine Interface driver and check that Intel ME is enabled in the BIOS."
+	case rc == utils.MEIDriverMissing:
+		return "No MEI device was found. Load the mei_me kernel module with modprobe mei_me and check that Intel ME is enabled in the BIOS."
+	}
+	return AccessErrMsg
+}
+
+// requiresAccess reports whether the command talks to AMT and
+// therefore needs the MEI driver and elevated privileges
+func requiresAccess(args []string) bool {
+	if len(args) >= 2 && (args[1] == utils.CommandReturnCodes || args[1] == utils.CommandService || args[1] == utils.CommandBulk || args[1] == utils.CommandSelfTest || args[1] == utils.CommandHelp || args[1] == utils.CommandDiag) {
+		return false
+	}
+	// a remote device is reached over the network, this host may not have AMT at all
+	for _, arg := range args {
+		if name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "="); name == "host" && strings.HasPrefix(arg, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// errorHint returns what the user can do about a failure, the description of the return
+// code when there is no more specific advice
+func errorHint(rc utils.ReturnCode) string {
+	switch rc {
+	case utils.NotAdministrator, utils.MEIDriverMissing, utils.UnsupportedPlatform, utils.AmtNotDetected:
+		return accessGuidance(rc)
+	case utils.IncorrectCommandLineParameters, utils.InvalidParameterCombination:
+		return "Run rpc help COMMAND to list the options of the command."
+	case utils.MissingOrIncorrectPassword, utils.AMTAuthenticationFailed:
+		return "Check the AMT password given with -password, -passwordFile, -passwordFromKeyring or AMT_PASSWORD."
+	case utils.MissingOrIncorrectURL, utils.ServerCerificateVerificationFailed, utils.RPSAuthenticationFailed:
+		return "Check the -u URL of the server, that this host reaches it and that its certificate is trusted."
+	case utils.AMTConnectionFailed, utils.AmtNotReady, utils.MEITimeout:
+		return "Check that AMT is enabled and ready, then run the command again, a longer -timeout may help."
+	}
+	return rc.Description()
+}
+
+// errorEnvelope is written to stderr for a failed command with -json, so scripts read
+// the failure without parsing log lines
+type errorEnvelope struct {
+	Code    utils.ReturnCode `json:"code"`
+	Name    string           `json:"name"`
+	Message string           `json:"message"`
+	Hint    string           `json:"hint"`
+}
+
+// writeErrorEnvelope writes the envelope of a failure as one line of JSON, the message is
+// the one of err, the last error logged when err has none
+func writeErrorEnvelope(w io.Writer, rc utils.ReturnCode, err error, hint string) {
+	envelope := errorEnvelope{Code: rc, Name: rc.String(), Message: failureMessage(rc, err), Hint: hint}
+	line, _ := json.Marshal(envelope)
+	fmt.Fprintln(w, string(line))
+}
+
+func failureMessage(rc utils.ReturnCode, err error) string {
+	var rpcErr *rpcerr.Error
+	if errors.As(err, &rpcErr) && rpcErr.Message != "" {
+		return rpcErr.Error()
+	}
+	if rpcErr != nil && rpcErr.Cause != nil {
+		return rpcErr.Cause.Error()
+	}
+	if err != nil && rpcErr == nil {
+		return err.Error()
+	}
+	if message := logging.LastError(); message != "" {
+		return message
+	}
+	return rc.Description()
+}
+
+// setupJSONErrors logs in JSON when the command line has -json, until the flags are
+// parsed nothing else configures the loggers
+func setupJSONErrors(args []string) bool {
+	if !flags.JSONRequested(args) {
+		return false
+	}
+	if err := logging.Setup(logging.Options{JSON: true}); err != nil {
+		log.Warn(err)
+	}
+	return true
+}
+
+func runRPC(ctx context.Context, args []string) utils.ReturnCode {
+	jsonErrors := setupJSONErrors(args)
+	flags, err := parseCommandLine(ctx, args, jsonErrors)
+	rc := rpcerr.ReturnCodeOf(err)
+	jsonErrors = jsonErrors || flags.JsonOutput
 	if rc != utils.Success {
+		if jsonErrors {
+			writeErrorEnvelope(os.Stderr, rc, err, errorHint(rc))
+		}
 		return rc
 	}
-	if flags.Local {
-		rc = local.ExecuteCommand(flags)
+	telemetry.Setup(flags.OTelEndpoint)
+	operation := telemetry.StartOperation(flags.Command, flags.SubCommand)
+	status := newStatusReporter(flags)
+	status.Started()
+	// the command name is kept, the RPS commands add their arguments to flags.Command
+	command, subCommand := flags.Command, flags.SubCommand
+	// the result document printed by the command is published and copied to -output
+	var result bytes.Buffer
+	out := io.MultiWriter(os.Stdout, &result)
+	if flags.Command == utils.CommandAgent {
+		rc = service.Run(agent.NewAgent(flags))
+	} else if flags.Command == utils.CommandService {
+		rc = service.ExecuteCommand(flags)
+	} else if len(flags.MaintenanceTasks) > 0 {
+		rc = rps.ExecuteBatchTo(flags, out)
+	} else if flags.Local {
+		rc = local.ExecuteCommandTo(flags, out)
 	} else {
-		rc = rps.ExecuteCommand(flags)
+		rc = rps.ExecuteCommandTo(flags, out)
+	}
+	if rc != utils.Success && ctx.Err() != nil {
+		rc = utils.CancelledByUser
+	}
+	var info []byte
+	if flags.Local && command == utils.CommandAMTInfo {
+		// the info snapshot is published with the result
+		info = result.Bytes()
+	}
+	status.Finished(rc, info)
+	writeOutput(flags.Output, resultDocument(command, subCommand, rc, result.Bytes(), flags.JsonOutput), rc)
+	if rc != utils.Success && jsonErrors {
+		writeErrorEnvelope(os.Stderr, rc, nil, errorHint(rc))
+	}
+	operation.End(rpcerr.FromReturnCode(rc))
+	if err := telemetry.Shutdown(); err != nil {
+		log.Warn(err)
 	}
 	return rc
 }
 
-func parseCommandLine(args []string) (*flags.Flags, utils.ReturnCode) {
-	//process flags
-	flags := flags.NewFlags(args)
-	rc := flags.ParseFlags()
+// resultDocument returns what the command printed, or a line with its return code when it
+// printed nothing, ex. activate with RPS
+func resultDocument(command, subCommand string, rc utils.ReturnCode, printed []byte, jsonOutput bool) []byte {
+	if len(bytes.TrimSpace(printed)) > 0 {
+		return printed
+	}
+	name := strings.TrimSpace(command + " " + subCommand)
+	if jsonOutput {
+		document, _ := json.MarshalIndent(map[string]interface{}{
+			"command":    name,
+			"returnCode": rc,
+			"result":     rc.String(),
+		}, "", "  ")
+		return append(document, '\n')
+	}
+	return []byte(fmt.Sprintf("rpc %s: return code %d (%s)\n", name, rc, rc))
+}
 
-	if flags.Verbose {
-		log.SetLevel(log.TraceLevel)
-	} else {
-		lvl, err := log.ParseLevel(flags.LogLevel)
-		if err != nil {
-			log.Warn(err)
-			log.SetLevel(log.InfoLevel)
-		} else {
-			log.SetLevel(lvl)
+// writeOutput copies the result document to the destinations of -output, a destination that
+// can not be written does not change the return code
+func writeOutput(destinations []string, document []byte, rc utils.ReturnCode) {
+	for _, destination := range destinations {
+		if err := output.WriteDocument(destination, document, rc != utils.Success); err != nil {
+			log.Warnf("unable to write the result to %s: %s", destination, err)
 		}
 	}
+}
 
-	if flags.JsonOutput {
-		log.SetFormatter(&log.JSONFormatter{})
-	} else {
-		log.SetFormatter(&log.TextFormatter{
-			DisableColors: true,
-			FullTimestamp: true,
-		})
+// newStatusReporter returns the reporter publishing to the MQTT broker, or nil when no broker is set
+func newStatusReporter(flags *flags.Flags) *mqtt.StatusReporter {
+	if flags.MQTTBroker == "" {
+		return nil
 	}
-	return flags, rc
+	publisher, err := mqtt.NewPublisher(flags.MQTTBroker, utils.ProjectName+"-"+strconv.Itoa(os.Getpid()))
+	if err != nil {
+		log.Warn(err)
+		return nil
+	}
+	if flags.MQTTUser != "" {
+		publisher.Username = flags.MQTTUser
+		publisher.Password = flags.MQTTPassword
+	}
+	var uuid string
+	if !flags.IsRemote() {
+		uuid, _ = amt.NewAMTCommandContext(flags.Context, flags.Timeout).GetUUID()
+	}
+	return mqtt.NewStatusReporter(publisher, flags.MQTTTopic, flags.Command, flags.SubCommand, uuid)
+}
+
+// parseCommandLine parses the flags and sets up logging, the message of a parse error is
+// printed unless it is reported in the JSON error envelope
+func parseCommandLine(ctx context.Context, args []string, jsonErrors bool) (*flags.Flags, error) {
+	//process flags
+	flags := flags.NewFlags(args)
+	flags.Context = ctx
+	parseErr := flags.Parse()
+	flags.PrintError(parseErr)
+
+	err := logging.Setup(logging.Options{
+		Level:        flags.LogLevel,
+		Verbose:      flags.Verbose,
+		JSON:         jsonErrors || flags.JsonOutput || flags.LogJSON,
+		File:         flags.LogFile,
+		MaxSize:      int64(flags.LogMaxSize) * 1024 * 1024,
+		ModuleLevels: flags.LogLevels,
+	})
+	if err != nil {
+		log.Warn(err)
+	}
+	logging.Redact(flags.Secrets()...)
+	return flags, parseErr
 }
 
 func main() {
-	rc, err := checkAccess()
-	if rc != utils.Success {
-		if err != nil {
-			log.Error(err.Error())
+	if requiresAccess(os.Args) {
+		rc, err := checkAccess()
+		if rc != utils.Success {
+			if setupJSONErrors(os.Args) {
+				writeErrorEnvelope(os.Stderr, rc, err, accessGuidance(rc))
+				os.Exit(int(rc))
+			}
+			if err != nil {
+				log.Error(err.Error())
+			}
+			log.Error(accessGuidance(rc))
+			os.Exit(int(rc))
 		}
-		log.Error(AccessErrMsg)
-		os.Exit(int(rc))
 	}
-	rc = runRPC(os.Args)
-	if err != nil {
-		log.Error(err.Error())
+	// SIGINT and SIGTERM cancel the command in flight, which closes the RPS session and
+	// rolls back what it added to AMT. A second signal exits right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	rc := runRPC(ctx, os.Args)
+	if rc != utils.Success {
+		log.Debugf("exiting with return code %d (%s)", rc, rc)
 	}
 	os.Exit(int(rc))
 }
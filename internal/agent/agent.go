@@ -0,0 +1,151 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"rpc/internal/flags"
+	"rpc/internal/local"
+	"rpc/internal/logging"
+	"rpc/internal/rps"
+	"rpc/pkg/utils"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var log = logging.For(logging.ModuleAgent)
+
+// Agent periodically runs maintenance tasks against RPS until it is stopped.
+// Every task opens its own connection to RPS so a lost connection only
+// affects the task in progress.
+type Agent struct {
+	flags        *flags.Flags
+	execute      func(f *flags.Flags) utils.ReturnCode
+	executeLocal func(f *flags.Flags, out io.Writer) utils.ReturnCode
+	state        *agentState
+}
+
+// agentState is shared by the copies of the agent, the control API reads it
+type agentState struct {
+	// mu serializes the tasks and the amtinfo requests of the control API, they share the
+	// MEI and the AMT password
+	mu        sync.Mutex
+	resultsMu sync.Mutex
+	started   time.Time
+	nextRun   time.Time
+	results   map[string]TaskResult
+}
+
+func NewAgent(f *flags.Flags) Agent {
+	return Agent{
+		flags:        f,
+		execute:      rps.ExecuteCommand,
+		executeLocal: local.ExecuteCommandTo,
+		state:        &agentState{results: map[string]TaskResult{}},
+	}
+}
+
+// Run executes the tasks right away and then on every interval until SIGINT or SIGTERM
+func (a Agent) Run() utils.ReturnCode {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	stop := make(chan struct{})
+	if a.flags.AgentControl != "" {
+		if err := a.serveControl(stop); err != nil {
+			log.Error("unable to serve the control API: ", err)
+			return utils.ControlAPIFailed
+		}
+	}
+	go func() {
+		<-interrupt
+		log.Info("agent stopping")
+		close(stop)
+	}()
+	a.loop(stop)
+	return utils.Success
+}
+
+// RunUntil executes the tasks right away and then on every interval until stop is closed.
+// The tasks still run when the control API can not be served.
+func (a Agent) RunUntil(stop <-chan struct{}) {
+	if a.flags.AgentControl != "" {
+		if err := a.serveControl(stop); err != nil {
+			log.Error("unable to serve the control API: ", err)
+		}
+	}
+	a.loop(stop)
+}
+
+func (a Agent) loop(stop <-chan struct{}) {
+	ticker := time.NewTicker(a.flags.AgentInterval)
+	defer ticker.Stop()
+	a.state.resultsMu.Lock()
+	a.state.started = time.Now()
+	a.state.resultsMu.Unlock()
+	for {
+		a.RunTasks()
+		a.state.resultsMu.Lock()
+		a.state.nextRun = time.Now().Add(a.flags.AgentInterval)
+		a.state.resultsMu.Unlock()
+		log.Infof("next maintenance run in %s", a.flags.AgentInterval)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// RunTasks executes each configured maintenance task once. Failures are
+// logged and do not prevent the remaining tasks from running.
+func (a Agent) RunTasks() map[string]utils.ReturnCode {
+	results := map[string]utils.ReturnCode{}
+	for _, task := range a.flags.AgentTasks {
+		results[task] = a.runAndRecord(task).ReturnCode
+	}
+	return results
+}
+
+// runAndRecord runs the task once no other task runs and keeps its result for the status
+// of the control API
+func (a Agent) runAndRecord(task string) TaskResult {
+	a.state.mu.Lock()
+	rc := a.runTask(task)
+	a.state.mu.Unlock()
+	if rc != utils.Success {
+		log.Errorf("maintenance %s failed with return code %d (%s)", task, rc, rc)
+	} else {
+		log.Infof("maintenance %s complete", task)
+	}
+	result := TaskResult{Task: task, ReturnCode: rc, Status: rc.String(), Finished: time.Now()}
+	a.state.resultsMu.Lock()
+	a.state.results[task] = result
+	a.state.resultsMu.Unlock()
+	return result
+}
+
+func (a Agent) runTask(task string) utils.ReturnCode {
+	// the rps client modifies the command, so each task gets its own copy
+	taskFlags := *a.flags
+	taskFlags.Command = utils.CommandMaintenance
+	taskFlags.SubCommand = task
+	// host settings may change between runs
+	switch task {
+	case utils.SubCommandSyncHostname:
+		if rc := taskFlags.LookupHostnameInfo(); rc != utils.Success {
+			return rc
+		}
+	case utils.SubCommandSyncIP:
+		taskFlags.IpConfiguration = flags.IPConfiguration{}
+		if rc := taskFlags.LookupIpConfiguration(); rc != utils.Success {
+			return rc
+		}
+	}
+	return a.execute(&taskFlags)
+}
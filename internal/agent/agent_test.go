@@ -0,0 +1,94 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import (
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []flags.Flags
+	rc    utils.ReturnCode
+}
+
+func (r *recorder) execute(f *flags.Flags) utils.ReturnCode {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, *f)
+	// mimic the rps client changing the command
+	f.Command += " -password " + f.Password
+	return r.rc
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+func TestRunTasks(t *testing.T) {
+	f := &flags.Flags{
+		Command:    utils.CommandAgent,
+		URL:        "wss://localhost",
+		Password:   "P@ssw0rd",
+		AgentTasks: []string{utils.SubCommandSyncClock, utils.SubCommandSyncDeviceInfo},
+	}
+	t.Run("runs each task as a maintenance command", func(t *testing.T) {
+		r := &recorder{}
+		a := NewAgent(f)
+		a.execute = r.execute
+		results := a.RunTasks()
+		assert.Equal(t, map[string]utils.ReturnCode{
+			utils.SubCommandSyncClock:      utils.Success,
+			utils.SubCommandSyncDeviceInfo: utils.Success,
+		}, results)
+		assert.Equal(t, 2, len(r.calls))
+		for i, task := range f.AgentTasks {
+			assert.Equal(t, utils.CommandMaintenance, r.calls[i].Command)
+			assert.Equal(t, task, r.calls[i].SubCommand)
+			assert.Equal(t, f.URL, r.calls[i].URL)
+		}
+		// agent flags are left untouched
+		assert.Equal(t, utils.CommandAgent, f.Command)
+	})
+	t.Run("continues after a failed task", func(t *testing.T) {
+		r := &recorder{rc: utils.SyncClockFailed}
+		a := NewAgent(f)
+		a.execute = r.execute
+		results := a.RunTasks()
+		assert.Equal(t, utils.SyncClockFailed, results[utils.SubCommandSyncClock])
+		assert.Equal(t, 2, len(r.calls))
+	})
+}
+
+func TestLoop(t *testing.T) {
+	f := &flags.Flags{
+		AgentInterval: 10 * time.Millisecond,
+		AgentTasks:    []string{utils.SubCommandSyncClock},
+	}
+	r := &recorder{}
+	a := NewAgent(f)
+	a.execute = r.execute
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.loop(stop)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return r.count() >= 2 }, time.Second, time.Millisecond)
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("agent did not stop")
+	}
+}
@@ -0,0 +1,220 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Control is the control API of the agent. Local management software reads amtinfo, runs
+// maintenance tasks and reads the status of the agent through it instead of starting rpc.
+// It is served with JSON-RPC 1.0 as the methods Control.Info, Control.Maintenance and
+// Control.Status, every request carries the token of the agent.
+type Control struct {
+	agent Agent
+	token string
+}
+
+// InfoRequest selects the amtinfo values, All adds those of amtinfo -all
+type InfoRequest struct {
+	Token string `json:"token"`
+	All   bool   `json:"all"`
+}
+
+// InfoReply holds the JSON of amtinfo, Info is null when amtinfo failed
+type InfoReply struct {
+	ReturnCode utils.ReturnCode `json:"returnCode"`
+	Status     string           `json:"status"`
+	Info       json.RawMessage  `json:"info"`
+}
+
+// MaintenanceRequest runs one of the maintenance tasks of the agent against RPS
+type MaintenanceRequest struct {
+	Token string `json:"token"`
+	Task  string `json:"task"`
+}
+
+// TaskResult is the outcome of the last run of a maintenance task
+type TaskResult struct {
+	Task       string           `json:"task"`
+	ReturnCode utils.ReturnCode `json:"returnCode"`
+	Status     string           `json:"status"`
+	Finished   time.Time        `json:"finished"`
+}
+
+// StatusRequest reads the status of the agent
+type StatusRequest struct {
+	Token string `json:"token"`
+}
+
+// StatusReply is the status of the agent, Results holds the last result of each task run
+// so far, by the schedule or the control API
+type StatusReply struct {
+	Version  string       `json:"version"`
+	Started  time.Time    `json:"started"`
+	Interval string       `json:"interval"`
+	Tasks    []string     `json:"tasks"`
+	NextRun  time.Time    `json:"nextRun"`
+	Results  []TaskResult `json:"results"`
+}
+
+var errUnauthorized = errors.New("unauthorized")
+
+func (c *Control) authorize(token string) error {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		log.Warn("control API request with a wrong token")
+		return errUnauthorized
+	}
+	return nil
+}
+
+// Info returns the JSON of amtinfo read from the MEI of this device
+func (c *Control) Info(req *InfoRequest, reply *InfoReply) error {
+	if err := c.authorize(req.Token); err != nil {
+		return err
+	}
+	infoFlags := *c.agent.flags
+	infoFlags.Command = utils.CommandAMTInfo
+	infoFlags.SubCommand = ""
+	infoFlags.Local = true
+	infoFlags.JsonOutput, infoFlags.YamlOutput = true, false
+	infoFlags.InfoCache = flags.InfoCacheFlags{TTL: flags.DefaultInfoCacheTTL}
+	infoFlags.AmtInfo = flags.AmtInfoFlags{
+		Ver: true, Bld: true, Sku: true, UUID: true, Mode: true, DNS: true, Ras: true, Lan: true, Hostname: true,
+		Cert: req.All, OpState: req.All, Modes: req.All, Hardware: req.All, BIOS: req.All, Sys: req.All,
+	}
+	var out bytes.Buffer
+	c.agent.state.mu.Lock()
+	rc := c.agent.executeLocal(&infoFlags, &out)
+	c.agent.state.mu.Unlock()
+	reply.ReturnCode, reply.Status = rc, rc.String()
+	if rc == utils.Success && json.Valid(out.Bytes()) {
+		reply.Info = out.Bytes()
+	}
+	return nil
+}
+
+// Maintenance runs the task right away, after the task in progress
+func (c *Control) Maintenance(req *MaintenanceRequest, reply *TaskResult) error {
+	if err := c.authorize(req.Token); err != nil {
+		return err
+	}
+	if !flags.IsMaintenanceTask(req.Task) {
+		return errors.New("unsupported maintenance task: " + req.Task)
+	}
+	log.Infof("maintenance %s requested through the control API", req.Task)
+	*reply = c.agent.runAndRecord(req.Task)
+	return nil
+}
+
+// Status returns the schedule of the agent and the last result of each task
+func (c *Control) Status(req *StatusRequest, reply *StatusReply) error {
+	if err := c.authorize(req.Token); err != nil {
+		return err
+	}
+	state := c.agent.state
+	state.resultsMu.Lock()
+	defer state.resultsMu.Unlock()
+	*reply = StatusReply{
+		Version:  utils.ProjectVersion,
+		Started:  state.started,
+		Interval: c.agent.flags.AgentInterval.String(),
+		Tasks:    c.agent.flags.AgentTasks,
+		NextRun:  state.nextRun,
+		Results:  []TaskResult{},
+	}
+	for _, result := range state.results {
+		reply.Results = append(reply.Results, result)
+	}
+	sort.Slice(reply.Results, func(i, j int) bool { return reply.Results[i].Task < reply.Results[j].Task })
+	return nil
+}
+
+// serveControl serves the control API on the -control address until stop is closed
+func (a Agent) serveControl(stop <-chan struct{}) error {
+	token, err := controlToken(a.flags.AgentControlToken)
+	if err != nil {
+		return err
+	}
+	listener, err := listenControl(a.flags.AgentControl)
+	if err != nil {
+		return err
+	}
+	server := rpc.NewServer()
+	if err = server.RegisterName("Control", &Control{agent: a, token: token}); err != nil {
+		listener.Close()
+		return err
+	}
+	go func() {
+		<-stop
+		listener.Close()
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Error("control API stopped: ", err)
+				}
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	log.Infof("control API listening on %s", a.flags.AgentControl)
+	return nil
+}
+
+// controlToken reads the token of the control API from path, or from control.token in the
+// rpc cache folder when path is empty. A missing file is created with a random token that
+// only its owner can read.
+func controlToken(path string) (string, error) {
+	if path == "" {
+		dir, err := utils.CacheDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, "control.token")
+	}
+	content, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(content))
+		if token == "" {
+			return "", errors.New("the control token file " + path + " is empty")
+		}
+		return token, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	random := make([]byte, 32)
+	if _, err = rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", err
+	}
+	log.Infof("control API token written to %s", path)
+	return token, nil
+}
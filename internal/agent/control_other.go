@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+)
+
+// listenControl listens on the unix socket at path, only its owner can connect. The socket
+// left by an agent that did not stop cleanly is replaced.
+func listenControl(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, errors.New(path + " exists and is not a socket")
+		}
+		if err = os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
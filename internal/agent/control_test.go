@@ -0,0 +1,129 @@
+//go:build !windows
+// +build !windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import (
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startControl(t *testing.T, a Agent) *rpc.Client {
+	dir := t.TempDir()
+	a.flags.AgentControl = filepath.Join(dir, "control.sock")
+	a.flags.AgentControlToken = filepath.Join(dir, "control.token")
+	assert.NoError(t, os.WriteFile(a.flags.AgentControlToken, []byte("secret\n"), 0600))
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	assert.NoError(t, a.serveControl(stop))
+	client, err := jsonrpc.Dial("unix", a.flags.AgentControl)
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestControl(t *testing.T) {
+	f := &flags.Flags{
+		Command:       utils.CommandAgent,
+		AgentInterval: time.Hour,
+		AgentTasks:    []string{utils.SubCommandSyncClock},
+	}
+	r := &recorder{}
+	var infoFlags flags.Flags
+	a := NewAgent(f)
+	a.execute = r.execute
+	a.executeLocal = func(f *flags.Flags, out io.Writer) utils.ReturnCode {
+		infoFlags = *f
+		out.Write([]byte(`{"amt":"16.1.25"}`))
+		return utils.Success
+	}
+	client := startControl(t, a)
+
+	t.Run("rejects a wrong token", func(t *testing.T) {
+		var reply StatusReply
+		err := client.Call("Control.Status", StatusRequest{Token: "wrong"}, &reply)
+		assert.EqualError(t, err, "unauthorized")
+	})
+	t.Run("info", func(t *testing.T) {
+		var reply InfoReply
+		assert.NoError(t, client.Call("Control.Info", InfoRequest{Token: "secret", All: true}, &reply))
+		assert.Equal(t, utils.Success, reply.ReturnCode)
+		assert.JSONEq(t, `{"amt":"16.1.25"}`, string(reply.Info))
+		assert.Equal(t, utils.CommandAMTInfo, infoFlags.Command)
+		assert.True(t, infoFlags.Local)
+		assert.True(t, infoFlags.JsonOutput)
+		assert.True(t, infoFlags.AmtInfo.Ver)
+		assert.True(t, infoFlags.AmtInfo.OpState)
+		// the agent flags are left untouched
+		assert.Equal(t, utils.CommandAgent, f.Command)
+	})
+	t.Run("maintenance", func(t *testing.T) {
+		var reply TaskResult
+		assert.NoError(t, client.Call("Control.Maintenance", MaintenanceRequest{Token: "secret", Task: utils.SubCommandSyncDeviceInfo}, &reply))
+		assert.Equal(t, utils.SubCommandSyncDeviceInfo, reply.Task)
+		assert.Equal(t, utils.Success, reply.ReturnCode)
+		assert.Equal(t, 1, r.count())
+		assert.Equal(t, utils.SubCommandSyncDeviceInfo, r.calls[0].SubCommand)
+	})
+	t.Run("rejects an unsupported task", func(t *testing.T) {
+		var reply TaskResult
+		err := client.Call("Control.Maintenance", MaintenanceRequest{Token: "secret", Task: "reboot"}, &reply)
+		assert.EqualError(t, err, "unsupported maintenance task: reboot")
+	})
+	t.Run("status", func(t *testing.T) {
+		var reply StatusReply
+		assert.NoError(t, client.Call("Control.Status", StatusRequest{Token: "secret"}, &reply))
+		assert.Equal(t, utils.ProjectVersion, reply.Version)
+		assert.Equal(t, "1h0m0s", reply.Interval)
+		assert.Equal(t, []string{utils.SubCommandSyncClock}, reply.Tasks)
+		assert.Equal(t, 1, len(reply.Results))
+		assert.Equal(t, utils.SubCommandSyncDeviceInfo, reply.Results[0].Task)
+	})
+}
+
+func TestControlToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpc", "control.token")
+	token, err := controlToken(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 64, len(token))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	again, err := controlToken(path)
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	assert.NoError(t, os.WriteFile(path, []byte("\n"), 0600))
+	_, err = controlToken(path)
+	assert.Error(t, err)
+}
+
+func TestListenControlReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.sock")
+	first, err := listenControl(path)
+	assert.NoError(t, err)
+	// a killed agent leaves the socket file behind
+	first.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	first.Close()
+	second, err := listenControl(path)
+	assert.NoError(t, err)
+	second.Close()
+
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0600))
+	_, err = listenControl(file)
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package agent
+
+import "net"
+
+// listenControl listens on the loopback address, the token keeps out other users of the host
+func listenControl(address string) (net.Listener, error) {
+	return net.Listen("tcp", address)
+}
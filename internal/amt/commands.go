@@ -5,10 +5,15 @@
 package amt
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"rpc/internal/telemetry"
+	"rpc/pkg/heci"
 	"rpc/pkg/pthi"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +48,8 @@ type InterfaceSettings struct {
 	DHCPMode    string `json:"dhcpMode"`
 	IPAddress   string `json:"ipAddress"` //net.IP
 	MACAddress  string `json:"macAddress"`
+	// IPv6Addresses of the host interface sharing the MAC address, in CIDR notation
+	IPv6Addresses []string `json:"ipv6Addresses,omitempty"`
 }
 
 // RemoteAccessStatus holds connect status information
@@ -53,13 +60,28 @@ type RemoteAccessStatus struct {
 	MPSHostname   string `json:"mpsHostname"`
 }
 
+// OperationalState reports whether AMT is enabled in the MEBx and how far provisioning got
+type OperationalState struct {
+	AMTEnabled        bool   `json:"amtEnabled"`
+	ProvisioningState string `json:"provisioningState"`
+	ProvisioningMode  string `json:"provisioningMode"`
+}
+
+// ChangeEnabled reports whether AMT is enabled and whether the OS may enable or disable it
+type ChangeEnabled struct {
+	AMTEnabled        bool `json:"amtEnabled"`
+	TransitionAllowed bool `json:"transitionAllowed"`
+	// NewInterfaceVersion is set by the firmware that supports SetAMTEnabled
+	NewInterfaceVersion bool `json:"newInterfaceVersion"`
+}
+
 // CertHashEntry is the GO struct for holding Cert Hash Entries
 type CertHashEntry struct {
-	Hash      string
-	Name      string
-	Algorithm string
-	IsActive  bool
-	IsDefault bool
+	Hash      string `json:"hash"`
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	IsActive  bool   `json:"isActive"`
+	IsDefault bool   `json:"isDefault"`
 }
 
 // LocalSystemAccount holds username and password
@@ -69,17 +91,24 @@ type LocalSystemAccount struct {
 }
 
 type Interface interface {
-	Initialize() (utils.ReturnCode, error)
+	Initialize() error
 	GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error)
 	GetUUID() (string, error)
 	GetControlMode() (int, error)
+	GetOperationalState() (OperationalState, error)
 	GetOSDNSSuffix() (string, error)
+	GetOSDNSServers() ([]string, error)
 	GetDNSSuffix() (string, error)
 	GetCertificateHashes() ([]CertHashEntry, error)
 	GetRemoteAccessConnectionStatus() (RemoteAccessStatus, error)
 	GetLANInterfaceSettings(useWireless bool) (InterfaceSettings, error)
 	GetLocalSystemAccount() (LocalSystemAccount, error)
 	Unprovision() (mode int, err error)
+	SetDNSSuffix(suffix string) (status int, err error)
+	GetChangeEnabled() (ChangeEnabled, error)
+	GetZeroTouchEnabled() (bool, error)
+	GetProvisioningTLSMode() (string, error)
+	SetAMTEnabled(enabled bool) (status int, err error)
 }
 
 func ANSI2String(ansi pthi.AMTANSIString) string {
@@ -91,57 +120,162 @@ func ANSI2String(ansi pthi.AMTANSIString) string {
 	return output
 }
 
+// DefaultTimeout bounds a single MEI command unless -timeout sets another
+const DefaultTimeout = 30 * time.Second
+
 type AMTCommand struct {
 	PTHI pthi.Interface
+	// Context cancels the MEI commands, nil is never cancelled
+	Context context.Context
+	// Timeout bounds each MEI command, 0 waits as long as the driver takes
+	Timeout time.Duration
 }
 
 func NewAMTCommand() AMTCommand {
+	return NewAMTCommandContext(context.Background(), DefaultTimeout)
+}
+
+// NewAMTCommandContext returns a command whose MEI calls give up when ctx is done or after timeout
+func NewAMTCommandContext(ctx context.Context, timeout time.Duration) AMTCommand {
 	return AMTCommand{
-		PTHI: pthi.NewCommand(),
+		PTHI:    pthi.NewCommand(),
+		Context: ctx,
+		Timeout: timeout,
+	}
+}
+
+// ErrCodeVersionNotFound is wrapped by the error of GetVersionDataFromME for a code version
+// the firmware does not report, AMT itself was reached
+var ErrCodeVersionNotFound = errors.New("code version not found")
+
+// meiBusy is held from opening the MEI connection until it is closed, so a
+// command never opens the PTHI handle while another one still uses it
+var meiBusy = make(chan struct{}, 1)
+
+// call opens the MEI connection, runs fn and closes the connection. It returns
+// MEITimeout when the context is done or the timeout passes first. A driver call
+// that hangs cannot be interrupted, it is left behind and closes its connection
+// if it ever returns. Until then the next commands wait for it in meiBusy and
+// time out the same way.
+func (amt AMTCommand) call(fn func() error) (err error) {
+	span := telemetry.StartMEICall(callerName())
+	defer func() { span.End(err) }()
+	ctx := amt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if amt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, amt.Timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return rpcerr.Wrap(utils.MEITimeout, err, "the MEI command was cancelled")
+	}
+	select {
+	case meiBusy <- struct{}{}:
+	case <-ctx.Done():
+		return rpcerr.Wrap(utils.MEITimeout, ctx.Err(), "the MEI is still busy with a command that did not answer")
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-meiBusy }()
+		if err := amt.open(); err != nil {
+			done <- err
+			return
+		}
+		err := fn()
+		amt.PTHI.Close()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return rpcerr.Wrap(utils.MEITimeout, ctx.Err(), "the MEI driver did not answer")
 	}
 }
 
+// callerName returns the name of the AMTCommand method that calls call, ex. GetUUID
+func callerName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndex(name, "AMTCommand."); i >= 0 {
+		name = name[i+len("AMTCommand."):]
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name
+}
+
 // Initialize determines if rpc is able to initialize the heci driver
-func (amt AMTCommand) Initialize() (utils.ReturnCode, error) {
+func (amt AMTCommand) Initialize() error {
 	// initialize HECI interface
-	err := amt.PTHI.Open(false)
+	err := amt.call(func() error { return nil })
 
-	if err != nil {
-		if err.Error() == "The handle is invalid." {
-			return utils.HECIDriverNotDetected, errors.New("AMT not found: MEI/driver is missing or the call to the HECI driver failed")
-		} else {
-			return utils.HECIDriverNotDetected, errors.New("unable to initialize")
-		}
+	if rpcerr.ReturnCodeOf(err) != utils.HECIDriverNotDetected {
+		return err
+	}
+	cause := errors.Unwrap(err)
+	if cause.Error() == "The handle is invalid." {
+		return rpcerr.Wrap(utils.HECIDriverNotDetected, cause, "AMT not found: MEI/driver is missing or the call to the HECI driver failed")
 	}
+	return rpcerr.Wrap(utils.HECIDriverNotDetected, cause, "unable to initialize")
+}
 
-	defer amt.PTHI.Close()
-	return utils.Success, nil
+// open opens the MEI connection of a command, the caller closes it
+func (amt AMTCommand) open() error {
+	if err := amt.PTHI.Open(false); err != nil {
+		switch {
+		case errors.Is(err, heci.ErrUnsupportedPlatform):
+			return rpcerr.Wrap(utils.UnsupportedPlatform, err, "")
+		case errors.Is(err, heci.ErrAccessDenied):
+			return rpcerr.Wrap(utils.NotAdministrator, err, "")
+		case errors.Is(err, heci.ErrDriverMissing):
+			return rpcerr.Wrap(utils.MEIDriverMissing, err, "")
+		}
+		return rpcerr.Wrap(utils.HECIDriverNotDetected, err, "unable to open the MEI connection")
+	}
+	return nil
 }
 
 // GetVersionDataFromME ...
 func (amt AMTCommand) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
-	err1 := amt.PTHI.Open(false)
-	if err1 != nil {
-		return "", err1
-	}
-	ticker := time.NewTicker(15 * time.Second)
-	startTime := time.Now()
-	var result, err = amt.PTHI.GetCodeVersions()
-	// retry upto flag AMTTimeoutDuration
-	if err != nil {
+	var result pthi.GetCodeVersionsResponse
+	getCodeVersions := func() error {
+		return amt.call(func() (err error) {
+			result, err = amt.PTHI.GetCodeVersions()
+			return err
+		})
+	}
+	ctx := amt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	err := getCodeVersions()
+	// retry upto flag AMTTimeoutDuration while AMT is not ready, errors that
+	// carry a return code (no driver, timeout, cancelled) are not retried
+	if err != nil && rpcerr.ReturnCodeOf(err) == utils.GenericFailure {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		deadline := time.NewTimer(amtTimeout)
+		defer deadline.Stop()
 	timeout: //label this for-select so we can break out of it when needed
 		for {
 			select {
+			case <-ctx.Done():
+				break timeout
+			case <-deadline.C: // we have tried for longer than specified timeout
+				break timeout
 			case <-ticker.C:
-				result, err = amt.PTHI.GetCodeVersions()
-				if err == nil || time.Now().Sub(startTime) > amtTimeout { // if we didnt get an error OR we have tried for longer than specified timeout
-					ticker.Stop()
+				if err = getCodeVersions(); err == nil {
 					break timeout
 				}
 			}
 		}
 	}
-	amt.PTHI.Close()
 	if err != nil {
 		return "", err
 	}
@@ -152,59 +286,104 @@ func (amt AMTCommand) GetVersionDataFromME(key string, amtTimeout time.Duration)
 		}
 	}
 
-	return "", errors.New(key + " Not Found")
+	return "", fmt.Errorf("%w: %s", ErrCodeVersionNotFound, key)
 }
 
 // GetUUID ...
 func (amt AMTCommand) GetUUID() (string, error) {
-	err := amt.PTHI.Open(false)
-	if err != nil {
-		return "", err
-	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetUUID()
+	var result string
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetUUID()
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
+	return FormatUUID([]byte(result)), nil
+}
 
+// FormatUUID formats the 16 bytes of a UUID as AMT reports it, with the first three
+// fields little endian. It is also the byte order of the PlatformGUID read with wsman.
+func FormatUUID(raw []byte) string {
 	var hexValues [16]string
 
 	for i := 0; i < 16; i++ {
-		hexValues[i] = fmt.Sprintf("%02x", int(result[i]))
+		hexValues[i] = fmt.Sprintf("%02x", int(raw[i]))
 	}
 
-	uuidStr := hexValues[3] + hexValues[2] + hexValues[1] + hexValues[0] + "-" +
+	return hexValues[3] + hexValues[2] + hexValues[1] + hexValues[0] + "-" +
 		hexValues[5] + hexValues[4] + "-" +
 		hexValues[7] + hexValues[6] + "-" +
 		hexValues[8] + hexValues[9] + "-" +
 		hexValues[10] + hexValues[11] + hexValues[12] + hexValues[13] + hexValues[14] + hexValues[15]
-	return uuidStr, nil
-
 }
 
 // GetControlMode ...
 func (amt AMTCommand) GetControlMode() (int, error) {
-	err := amt.PTHI.Open(false)
+	var result int
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetControlMode()
+		return err
+	})
 	if err != nil {
 		return -1, err
 	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetControlMode()
+
+	return result, nil
+}
+
+// GetOperationalState distinguishes AMT disabled in the MEBx from AMT that is not activated yet
+func (amt AMTCommand) GetOperationalState() (OperationalState, error) {
+	opState := OperationalState{}
+	var state pthi.GetProvisioningStateResponse
+	var mode pthi.GetProvisioningModeResponse
+	err := amt.call(func() (err error) {
+		if state, err = amt.PTHI.GetProvisioningState(); err != nil || state.Header.Status != pthi.AMT_STATUS_SUCCESS {
+			return err
+		}
+		mode, err = amt.PTHI.GetProvisioningMode()
+		return err
+	})
 	if err != nil {
-		return -1, err
+		return opState, err
+	}
+	if state.Header.Status == pthi.AMT_STATUS_INVALID_PT_MODE {
+		return opState, nil
+	}
+	if state.Header.Status != pthi.AMT_STATUS_SUCCESS {
+		return opState, rpcerr.Newf(utils.AmtPtStatusCodeBase+utils.ReturnCode(state.Header.Status), "get provisioning state failed with status %d", state.Header.Status)
 	}
+	opState.AMTEnabled = true
+	opState.ProvisioningState = utils.InterpretProvisioningState(int(state.ProvisioningState))
 
-	return result, nil
+	if mode.Header.Status != pthi.AMT_STATUS_SUCCESS {
+		return opState, rpcerr.Newf(utils.AmtPtStatusCodeBase+utils.ReturnCode(mode.Header.Status), "get provisioning mode failed with status %d", mode.Header.Status)
+	}
+	opState.ProvisioningMode = utils.InterpretProvisioningMode(int(mode.ProvisioningMode))
+	return opState, nil
 }
 
 // Unprovision ...
 func (amt AMTCommand) Unprovision() (int, error) {
-	err := amt.PTHI.Open(false)
+	var result int
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.Unprovision()
+		return err
+	})
 	if err != nil {
 		return -1, err
 	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.Unprovision()
+
+	return result, nil
+}
+
+// SetDNSSuffix sets the PKI DNS suffix and returns the AMT status
+func (amt AMTCommand) SetDNSSuffix(suffix string) (int, error) {
+	var result int
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.SetDNSSuffix(suffix)
+		return err
+	})
 	if err != nil {
 		return -1, err
 	}
@@ -212,13 +391,79 @@ func (amt AMTCommand) Unprovision() (int, error) {
 	return result, nil
 }
 
-func (amt AMTCommand) GetDNSSuffix() (string, error) {
-	err := amt.PTHI.Open(false)
+// GetChangeEnabled reads the AMT state and whether the BIOS lets the OS change it
+func (amt AMTCommand) GetChangeEnabled() (ChangeEnabled, error) {
+	var result pthi.ChangeEnabledResponse
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetChangeEnabled()
+		return err
+	})
+	if err != nil {
+		return ChangeEnabled{}, err
+	}
+	return ChangeEnabled{
+		AMTEnabled:          result.IsAMTEnabled(),
+		TransitionAllowed:   result.IsTransitionAllowed(),
+		NewInterfaceVersion: result.IsNewInterfaceVersion(),
+	}, nil
+}
+
+// GetZeroTouchEnabled reads whether remote configuration with a provisioning certificate
+// is enabled in the MEBx
+func (amt AMTCommand) GetZeroTouchEnabled() (bool, error) {
+	var result bool
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetZeroTouchEnabled()
+		return err
+	})
+	return result, err
+}
+
+// GetProvisioningTLSMode reads whether AMT is provisioned with a pre-shared key (PSK) or a
+// provisioning certificate (PKI)
+func (amt AMTCommand) GetProvisioningTLSMode() (string, error) {
+	var result int
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetProvisioningTLSMode()
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetDNSSuffix()
+	switch result {
+	case pthi.PROVISIONING_TLS_MODE_PSK:
+		return "PSK", nil
+	case pthi.PROVISIONING_TLS_MODE_PKI:
+		return "PKI", nil
+	case pthi.PROVISIONING_TLS_MODE_NOT_READY:
+		return "not ready", nil
+	}
+	return "unknown", nil
+}
+
+// SetAMTEnabled enables or disables AMT and returns the AMT status
+func (amt AMTCommand) SetAMTEnabled(enabled bool) (int, error) {
+	state := pthi.AMT_OPERATIONAL_STATE_DISABLED
+	if enabled {
+		state = pthi.AMT_OPERATIONAL_STATE_ENABLED
+	}
+	var result int
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.SetAMTOperationalState(state)
+		return err
+	})
+	if err != nil {
+		return -1, err
+	}
+	return result, nil
+}
+
+func (amt AMTCommand) GetDNSSuffix() (string, error) {
+	var result string
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetDNSSuffix()
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -227,13 +472,12 @@ func (amt AMTCommand) GetDNSSuffix() (string, error) {
 }
 
 func (amt AMTCommand) GetCertificateHashes() ([]CertHashEntry, error) {
-	err := amt.PTHI.Open(false)
 	amtEntryList := []CertHashEntry{}
-	if err != nil {
-		return amtEntryList, err
-	}
-	defer amt.PTHI.Close()
-	pthiEntryList, err := amt.PTHI.GetCertificateHashes(pthi.AMTHashHandles{})
+	var pthiEntryList []pthi.CertHashEntry
+	err := amt.call(func() (err error) {
+		pthiEntryList, err = amt.PTHI.GetCertificateHashes(pthi.AMTHashHandles{})
+		return err
+	})
 	if err != nil {
 		return amtEntryList, err
 	}
@@ -263,13 +507,12 @@ func (amt AMTCommand) GetCertificateHashes() ([]CertHashEntry, error) {
 }
 
 func (amt AMTCommand) GetRemoteAccessConnectionStatus() (RemoteAccessStatus, error) {
-	err := amt.PTHI.Open(false)
 	emptyRAStatus := RemoteAccessStatus{}
-	if err != nil {
-		return emptyRAStatus, err
-	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetRemoteAccessConnectionStatus()
+	var result pthi.GetRemoteAccessConnectionStatusResponse
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetRemoteAccessConnectionStatus()
+		return err
+	})
 	if err != nil {
 		return emptyRAStatus, err
 	}
@@ -285,13 +528,12 @@ func (amt AMTCommand) GetRemoteAccessConnectionStatus() (RemoteAccessStatus, err
 }
 
 func (amt AMTCommand) GetLANInterfaceSettings(useWireless bool) (InterfaceSettings, error) {
-	err := amt.PTHI.Open(false)
 	emptySettings := InterfaceSettings{}
-	if err != nil {
-		return emptySettings, err
-	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetLANInterfaceSettings(useWireless)
+	var result pthi.GetLANInterfaceSettingsResponse
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetLANInterfaceSettings(useWireless)
+		return err
+	})
 	if err != nil {
 		return emptySettings, err
 	}
@@ -331,13 +573,12 @@ func (amt AMTCommand) GetLANInterfaceSettings(useWireless bool) (InterfaceSettin
 }
 
 func (amt AMTCommand) GetLocalSystemAccount() (LocalSystemAccount, error) {
-	err := amt.PTHI.Open(false)
 	emptySystemAccount := LocalSystemAccount{}
-	if err != nil {
-		return emptySystemAccount, err
-	}
-	defer amt.PTHI.Close()
-	result, err := amt.PTHI.GetLocalSystemAccount()
+	var result pthi.GetLocalSystemAccountResponse
+	err := amt.call(func() (err error) {
+		result, err = amt.PTHI.GetLocalSystemAccount()
+		return err
+	})
 	if err != nil {
 		return emptySystemAccount, err
 	}
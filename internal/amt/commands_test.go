@@ -5,22 +5,43 @@
 package amt
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"rpc/internal/telemetry"
+	"rpc/pkg/heci"
 	"rpc/pkg/pthi"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-type MockPTHICommands struct{}
+type MockPTHICommands struct {
+	// hang blocks GetUUID until it is closed, like a driver that does not answer
+	hang chan struct{}
+}
 
 var flag bool = false
 var flag1 bool = false
 var returnError bool = false
+var unsupported bool = false
+
+// openErr is returned by Open when set
+var openErr error
 
 func (c MockPTHICommands) Open(useLME bool) error {
-	if flag == true {
+	if unsupported {
+		return heci.ErrUnsupportedPlatform
+	} else if openErr != nil {
+		return openErr
+	} else if flag == true {
 		return errors.New("The handle is invalid.")
 	} else if flag1 == true {
 		return errors.New("")
@@ -71,6 +92,9 @@ func (c MockPTHICommands) GetCodeVersions() (pthi.GetCodeVersionsResponse, error
 }
 
 func (c MockPTHICommands) GetUUID() (uuid string, err error) {
+	if c.hang != nil {
+		<-c.hang
+	}
 	return "\xd2?\x11\x1c%3\x94E\xa2rT\xb2\x03\x8b\xeb\a", nil
 }
 func (c MockPTHICommands) GetControlMode() (state int, err error)   { return 0, nil }
@@ -122,6 +146,32 @@ func (c MockPTHICommands) GetLocalSystemAccount() (localAccount pthi.GetLocalSys
 	}, nil
 }
 func (c MockPTHICommands) Unprovision() (state int, err error) { return 0, nil }
+func (c MockPTHICommands) SetDNSSuffix(suffix string) (status int, err error) {
+	return 0, nil
+}
+func (c MockPTHICommands) GetChangeEnabled() (pthi.ChangeEnabledResponse, error) {
+	return 0x83, nil
+}
+func (c MockPTHICommands) GetZeroTouchEnabled() (bool, error) {
+	return true, nil
+}
+func (c MockPTHICommands) GetProvisioningTLSMode() (int, error) {
+	return pthi.PROVISIONING_TLS_MODE_PKI, nil
+}
+func (c MockPTHICommands) SetAMTOperationalState(state pthi.AMTOperationalState) (status int, err error) {
+	return 0, nil
+}
+
+var provisioningStateStatus uint32 = pthi.AMT_STATUS_SUCCESS
+
+func (c MockPTHICommands) GetProvisioningState() (pthi.GetProvisioningStateResponse, error) {
+	response := pthi.GetProvisioningStateResponse{ProvisioningState: 2}
+	response.Header.Status = provisioningStateStatus
+	return response, nil
+}
+func (c MockPTHICommands) GetProvisioningMode() (pthi.GetProvisioningModeResponse, error) {
+	return pthi.GetProvisioningModeResponse{ProvisioningMode: 1}, nil
+}
 
 var amt AMTCommand
 
@@ -130,21 +180,41 @@ func init() {
 	amt.PTHI = MockPTHICommands{}
 }
 func TestInitializeNoError(t *testing.T) {
-	result, err := amt.Initialize()
-	assert.NoError(t, err, result)
+	err := amt.Initialize()
+	assert.NoError(t, err)
 }
 func TestInitializeMEIError(t *testing.T) {
 	flag = true
-	result, err := amt.Initialize()
-	assert.Error(t, err, result)
+	err := amt.Initialize()
+	assert.Error(t, err)
+	assert.Equal(t, utils.HECIDriverNotDetected, rpcerr.ReturnCodeOf(err))
 	flag = false
 }
 func TestInitializeError(t *testing.T) {
 	flag1 = true
-	result, err := amt.Initialize()
-	assert.Error(t, err, result)
+	err := amt.Initialize()
+	assert.Error(t, err)
+	assert.Equal(t, utils.HECIDriverNotDetected, rpcerr.ReturnCodeOf(err))
 	flag1 = false
 }
+func TestInitializeUnsupportedPlatform(t *testing.T) {
+	unsupported = true
+	defer func() { unsupported = false }()
+	err := amt.Initialize()
+	assert.ErrorIs(t, err, heci.ErrUnsupportedPlatform)
+	assert.Equal(t, utils.UnsupportedPlatform, rpcerr.ReturnCodeOf(err))
+}
+func TestInitializeAccessErrors(t *testing.T) {
+	defer func() { openErr = nil }()
+	openErr = fmt.Errorf("%w: %w", heci.ErrAccessDenied, fs.ErrPermission)
+	err := amt.Initialize()
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	assert.Equal(t, utils.NotAdministrator, rpcerr.ReturnCodeOf(err))
+
+	openErr = fmt.Errorf("%w: %w", heci.ErrDriverMissing, fs.ErrNotExist)
+	err = amt.Initialize()
+	assert.Equal(t, utils.MEIDriverMissing, rpcerr.ReturnCodeOf(err))
+}
 func TestGetVersionDataFromME(t *testing.T) {
 	result, err := amt.GetVersionDataFromME("Flash", 1*time.Second)
 	assert.NoError(t, err)
@@ -152,7 +222,8 @@ func TestGetVersionDataFromME(t *testing.T) {
 }
 func TestGetVersionDataFromMEError(t *testing.T) {
 	result, err := amt.GetVersionDataFromME("", 1*time.Second)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrCodeVersionNotFound)
+	assert.Equal(t, utils.GenericFailure, rpcerr.ReturnCodeOf(err), "AMT was detected")
 	assert.Equal(t, "", result)
 }
 
@@ -176,12 +247,64 @@ func TestGetGUID(t *testing.T) {
 	assert.Equal(t, "1c113fd2-3325-4594-a272-54b2038beb07", result)
 }
 
+func TestCommandTimeout(t *testing.T) {
+	t.Run("returns MEITimeout when the driver does not answer", func(t *testing.T) {
+		hang := make(chan struct{})
+		defer close(hang)
+		cmd := AMTCommand{PTHI: MockPTHICommands{hang: hang}, Timeout: 10 * time.Millisecond}
+		_, err := cmd.GetUUID()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, utils.MEITimeout, rpcerr.ReturnCodeOf(err))
+	})
+	t.Run("waits for a command that did not answer before opening the MEI again", func(t *testing.T) {
+		hang := make(chan struct{})
+		cmd := AMTCommand{PTHI: MockPTHICommands{hang: hang}, Timeout: 10 * time.Millisecond}
+		_, err := cmd.GetUUID()
+		assert.Equal(t, utils.MEITimeout, rpcerr.ReturnCodeOf(err))
+		_, err = cmd.GetControlMode()
+		assert.Equal(t, utils.MEITimeout, rpcerr.ReturnCodeOf(err))
+		close(hang)
+		cmd.Timeout = time.Second
+		_, err = cmd.GetControlMode()
+		assert.NoError(t, err)
+	})
+	t.Run("returns MEITimeout when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		cmd := AMTCommand{PTHI: MockPTHICommands{}, Context: ctx}
+		_, err := cmd.GetControlMode()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, utils.MEITimeout, rpcerr.ReturnCodeOf(err))
+	})
+}
+
 func TestGetControlmode(t *testing.T) {
 	result, err := amt.GetControlMode()
 	assert.NoError(t, err)
 	assert.Equal(t, 0, result)
 }
 
+func TestGetOperationalState(t *testing.T) {
+	t.Run("reports provisioning state when AMT is enabled", func(t *testing.T) {
+		result, err := amt.GetOperationalState()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationalState{AMTEnabled: true, ProvisioningState: "post-provisioning", ProvisioningMode: "enterprise"}, result)
+	})
+	t.Run("reports AMT disabled in MEBx", func(t *testing.T) {
+		provisioningStateStatus = pthi.AMT_STATUS_INVALID_PT_MODE
+		defer func() { provisioningStateStatus = pthi.AMT_STATUS_SUCCESS }()
+		result, err := amt.GetOperationalState()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationalState{}, result)
+	})
+	t.Run("returns error on unexpected status", func(t *testing.T) {
+		provisioningStateStatus = 1
+		defer func() { provisioningStateStatus = pthi.AMT_STATUS_SUCCESS }()
+		_, err := amt.GetOperationalState()
+		assert.Error(t, err)
+	})
+}
+
 func TestGetDNSSuffix(t *testing.T) {
 	result, err := amt.GetDNSSuffix()
 	assert.NoError(t, err)
@@ -240,3 +363,50 @@ func TestUnprovision(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, result)
 }
+
+func TestSetDNSSuffix(t *testing.T) {
+	result, err := amt.SetDNSSuffix("corp.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+}
+
+func TestGetChangeEnabled(t *testing.T) {
+	result, err := amt.GetChangeEnabled()
+	assert.NoError(t, err)
+	assert.Equal(t, ChangeEnabled{AMTEnabled: true, TransitionAllowed: true, NewInterfaceVersion: true}, result)
+}
+
+func TestGetZeroTouchEnabled(t *testing.T) {
+	result, err := amt.GetZeroTouchEnabled()
+	assert.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestGetProvisioningTLSMode(t *testing.T) {
+	result, err := amt.GetProvisioningTLSMode()
+	assert.NoError(t, err)
+	assert.Equal(t, "PKI", result)
+}
+
+func TestSetAMTEnabled(t *testing.T) {
+	result, err := amt.SetAMTEnabled(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+}
+
+func TestCallRecordsMEISpan(t *testing.T) {
+	var traces []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			traces, _ = io.ReadAll(r.Body)
+		}
+	}))
+	defer server.Close()
+	telemetry.Setup(server.URL)
+	defer telemetry.Setup("")
+
+	_, err := amt.GetUUID()
+	assert.NoError(t, err)
+	assert.NoError(t, telemetry.Flush())
+	assert.Contains(t, string(traces), "mei GetUUID")
+}
@@ -4,6 +4,7 @@
 package amt
 
 import (
+	"net"
 	"os"
 	"strings"
 )
@@ -19,3 +20,40 @@ func (amt AMTCommand) GetOSDNSSuffix() (string, error) {
 	}
 	return hostname, err
 }
+
+// GetOSDNSSuffixOf returns the DNS suffix of the host. Linux has no DNS suffix per
+// interface, so it is the same for every MAC address.
+func (amt AMTCommand) GetOSDNSSuffixOf(mac string) (string, error) {
+	return amt.GetOSDNSSuffix()
+}
+
+// resolvConf is the resolver configuration the OS DNS servers are read from. With
+// systemd-resolved it lists the local stub, resolvedConf lists the servers it forwards to.
+var (
+	resolvConf   = "/etc/resolv.conf"
+	resolvedConf = "/run/systemd/resolve/resolv.conf"
+)
+
+// GetOSDNSServers returns the IPv4 name servers of the host, the loopback addresses of
+// local resolvers such as the 127.0.0.53 stub of systemd-resolved are left out since AMT
+// can not reach them
+func (amt AMTCommand) GetOSDNSServers() ([]string, error) {
+	var servers []string
+	content, err := os.ReadFile(resolvedConf)
+	if err != nil {
+		content, err = os.ReadFile(resolvConf)
+	}
+	if err != nil {
+		return servers, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil && ip.To4() != nil && !ip.IsLoopback() {
+			servers = append(servers, ip.String())
+		}
+	}
+	return servers, nil
+}
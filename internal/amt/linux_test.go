@@ -0,0 +1,47 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package amt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOSDNSServers(t *testing.T) {
+	origConf, origResolved := resolvConf, resolvedConf
+	defer func() { resolvConf, resolvedConf = origConf, origResolved }()
+
+	resolvedConf = filepath.Join(t.TempDir(), "missing.conf")
+	resolvConf = filepath.Join(t.TempDir(), "resolv.conf")
+	content := "# generated\nsearch corp.example.com\nnameserver 10.0.0.53\nnameserver fe80::1\nnameserver 127.0.0.1\nnameserver 10.0.0.54\n"
+	assert.NoError(t, os.WriteFile(resolvConf, []byte(content), 0600))
+	servers, err := amt.GetOSDNSServers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.53", "10.0.0.54"}, servers)
+
+	resolvConf = filepath.Join(t.TempDir(), "missing.conf")
+	_, err = amt.GetOSDNSServers()
+	assert.Error(t, err)
+}
+
+func TestGetOSDNSServersSystemdResolved(t *testing.T) {
+	origConf, origResolved := resolvConf, resolvedConf
+	defer func() { resolvConf, resolvedConf = origConf, origResolved }()
+
+	dir := t.TempDir()
+	resolvConf = filepath.Join(dir, "resolv.conf")
+	resolvedConf = filepath.Join(dir, "resolved.conf")
+	assert.NoError(t, os.WriteFile(resolvConf, []byte("nameserver 127.0.0.53\noptions edns0 trust-ad\n"), 0600))
+	assert.NoError(t, os.WriteFile(resolvedConf, []byte("nameserver 10.0.0.53\n"), 0600))
+	servers, err := amt.GetOSDNSServers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.53"}, servers)
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package amt
+
+import "rpc/pkg/heci"
+
+// the OS lookups are only needed together with the MEI, which is not supported here
+
+func (amt AMTCommand) GetOSDNSSuffix() (string, error) {
+	return "", heci.ErrUnsupportedPlatform
+}
+
+func (amt AMTCommand) GetOSDNSSuffixOf(mac string) (string, error) {
+	return "", heci.ErrUnsupportedPlatform
+}
+
+func (amt AMTCommand) GetOSDNSServers() ([]string, error) {
+	return nil, heci.ErrUnsupportedPlatform
+}
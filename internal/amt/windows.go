@@ -13,9 +13,46 @@ import (
 )
 
 func (amt AMTCommand) GetOSDNSSuffix() (string, error) {
+	aa, err := amt.findAMTAdapter()
+	if err != nil || aa == nil {
+		return "", err
+	}
+	return windows.UTF16PtrToString(aa.DnsSuffix), nil
+}
+
+// GetOSDNSServers returns the DNS servers of the OS network adapter that shares its MAC address with AMT
+func (amt AMTCommand) GetOSDNSServers() ([]string, error) {
+	var servers []string
+	aa, err := amt.findAMTAdapter()
+	if err != nil || aa == nil {
+		return servers, err
+	}
+	for dns := aa.FirstDnsServerAddress; dns != nil; dns = dns.Next {
+		ip := dns.Address.IP()
+		if ip != nil && ip.To4() != nil {
+			servers = append(servers, ip.String())
+		}
+	}
+	return servers, nil
+}
+
+// GetOSDNSSuffixOf returns the DNS suffix of the OS network adapter with the MAC address
+func (amt AMTCommand) GetOSDNSSuffixOf(mac string) (string, error) {
+	aa, err := findAdapter(mac)
+	if err != nil || aa == nil {
+		return "", err
+	}
+	return windows.UTF16PtrToString(aa.DnsSuffix), nil
+}
+
+// findAMTAdapter returns the OS network adapter that shares its MAC address with AMT, or nil when there is none
+func (amt AMTCommand) findAMTAdapter() (*windows.IpAdapterAddresses, error) {
 	lanResult, _ := amt.GetLANInterfaceSettings(false)
+	return findAdapter(lanResult.MACAddress)
+}
 
-	var primarySuffix = ""
+// findAdapter returns the OS network adapter with the MAC address, or nil when there is none
+func findAdapter(mac string) (*windows.IpAdapterAddresses, error) {
 	var b []byte
 	l := uint32(15000) // recommended initial size
 	for {
@@ -23,15 +60,15 @@ func (amt AMTCommand) GetOSDNSSuffix() (string, error) {
 		err := windows.GetAdaptersAddresses(syscall.AF_UNSPEC, windows.GAA_FLAG_INCLUDE_PREFIX, 0, (*windows.IpAdapterAddresses)(unsafe.Pointer(&b[0])), &l)
 		if err == nil {
 			if l == 0 {
-				return primarySuffix, nil
+				return nil, nil
 			}
 			break
 		}
 		if err.(syscall.Errno) != syscall.ERROR_BUFFER_OVERFLOW {
-			return primarySuffix, os.NewSyscallError("getadaptersaddresses", err)
+			return nil, os.NewSyscallError("getadaptersaddresses", err)
 		}
 		if l <= uint32(len(b)) {
-			return primarySuffix, os.NewSyscallError("getadaptersaddresses", err)
+			return nil, os.NewSyscallError("getadaptersaddresses", err)
 		}
 	}
 	for aa := (*windows.IpAdapterAddresses)(unsafe.Pointer(&b[0])); aa != nil; aa = aa.Next {
@@ -40,10 +77,9 @@ func (amt AMTCommand) GetOSDNSSuffix() (string, error) {
 		}
 		var curMacAddr = make(net.HardwareAddr, aa.PhysicalAddressLength)
 		copy(curMacAddr, aa.PhysicalAddress[:])
-		if curMacAddr.String() == lanResult.MACAddress {
-			primarySuffix = windows.UTF16PtrToString(aa.DnsSuffix)
-			break
+		if curMacAddr.String() == mac {
+			return aa, nil
 		}
 	}
-	return primarySuffix, nil
+	return nil, nil
 }
@@ -2,44 +2,44 @@ package config
 
 type (
 	Config struct {
-		Password         string `yaml:"password"`
-		WifiConfigs      `yaml:"wifiConfigs"`
-		Ieee8021xConfigs `yaml:"ieee8021xConfigs"`
-		ACMSettings      `yaml:"acmactivate"`
+		Password         string `yaml:"password" json:"password"`
+		WifiConfigs      `yaml:"wifiConfigs" json:"wifiConfigs"`
+		Ieee8021xConfigs `yaml:"ieee8021xConfigs" json:"ieee8021xConfigs"`
+		ACMSettings      `yaml:"acmactivate" json:"acmactivate"`
 	}
 	WifiConfigs []WifiConfig
 	WifiConfig  struct {
-		ProfileName          string `yaml:"profileName"`
-		SSID                 string `yaml:"ssid"`
-		Priority             int    `yaml:"priority"`
-		AuthenticationMethod int    `yaml:"authenticationMethod"`
-		EncryptionMethod     int    `yaml:"encryptionMethod"`
-		PskPassphrase        string `yaml:"pskPassphrase"`
-		Ieee8021xProfileName string `yaml:"ieee8021xProfileName"`
+		ProfileName          string `yaml:"profileName" json:"profileName"`
+		SSID                 string `yaml:"ssid" json:"ssid"`
+		Priority             int    `yaml:"priority" json:"priority"`
+		AuthenticationMethod int    `yaml:"authenticationMethod" json:"authenticationMethod"`
+		EncryptionMethod     int    `yaml:"encryptionMethod" json:"encryptionMethod"`
+		PskPassphrase        string `yaml:"pskPassphrase" json:"pskPassphrase"`
+		Ieee8021xProfileName string `yaml:"ieee8021xProfileName" json:"ieee8021xProfileName"`
 	}
 	SecretConfig struct {
-		Secrets []Secret `yaml:"secrets"`
+		Secrets []Secret `yaml:"secrets" json:"secrets"`
 	}
 	Secret struct {
-		ProfileName   string `yaml:"profileName"`
-		PskPassphrase string `yaml:"pskPassphrase"`
-		PrivateKey    string `yaml:"privateKey"`
-		Password      string `yaml:"password"`
+		ProfileName   string `yaml:"profileName" json:"profileName"`
+		PskPassphrase string `yaml:"pskPassphrase" json:"pskPassphrase"`
+		PrivateKey    string `yaml:"privateKey" json:"privateKey"`
+		Password      string `yaml:"password" json:"password"`
 	}
 	Ieee8021xConfigs []Ieee8021xConfig
 	Ieee8021xConfig  struct {
-		ProfileName            string `yaml:"profileName"`
-		Username               string `yaml:"username"`
-		Password               string `yaml:"password"`
-		AuthenticationProtocol int    `yaml:"authenticationProtocol"`
-		ClientCert             string `yaml:"clientCert"`
-		CACert                 string `yaml:"caCert"`
-		PrivateKey             string `yaml:"privateKey"`
+		ProfileName            string `yaml:"profileName" json:"profileName"`
+		Username               string `yaml:"username" json:"username"`
+		Password               string `yaml:"password" json:"password"`
+		AuthenticationProtocol int    `yaml:"authenticationProtocol" json:"authenticationProtocol"`
+		ClientCert             string `yaml:"clientCert" json:"clientCert"`
+		CACert                 string `yaml:"caCert" json:"caCert"`
+		PrivateKey             string `yaml:"privateKey" json:"privateKey"`
 	}
 
 	ACMSettings struct {
-		AMTPassword         string `yaml:"amtPassword"`
-		ProvisioningCert    string `yaml:"provisioningCert"`
-		ProvisioningCertPwd string `yaml:"provisioningCertPwd"`
+		AMTPassword         string `yaml:"amtPassword" json:"amtPassword"`
+		ProvisioningCert    string `yaml:"provisioningCert" json:"provisioningCert"`
+		ProvisioningCertPwd string `yaml:"provisioningCertPwd" json:"provisioningCertPwd"`
 	}
 )
@@ -1,15 +1,52 @@
 package flags
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"rpc/internal/secretstore"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ActivateFlags selects what local activation does with a device that is already activated
+type ActivateFlags struct {
+	// Upgrade moves a device activated in CCM to ACM
+	Upgrade bool
+	// Reprovision deactivates an activated device and activates it again
+	Reprovision bool
+	// Resume sends the progress of an interrupted RPS activation saved in SessionFile to the
+	// server, RPS does not continue an activation and starts it over
+	Resume bool
+	// SessionFile is where the state of an RPS activation is saved, the rpc folder in the
+	// user cache directory when empty
+	SessionFile string
+	// GeneratePassword sets a password generated by rpc in local CCM activation, it is saved
+	// to the -out, -keyring or -vault of ChangePassword
+	GeneratePassword bool
+	// RPSAPI is the base URL of the REST API of RPS, the device is checked against the
+	// profile read from it with -token before the activation starts. Not checked when empty.
+	RPSAPI string
+}
 
-	log "github.com/sirupsen/logrus"
+// Activation paths, how activate brings the device to the requested control mode
+const (
+	ActivationPathActivate    = "activate"
+	ActivationPathNone        = "none"
+	ActivationPathUpgrade     = "upgrade"
+	ActivationPathReprovision = "reprovision"
 )
 
-func (f *Flags) handleActivateCommand() utils.ReturnCode {
+// setupActivateFlags registers the flags of activate, which sets their defaults
+func (f *Flags) setupActivateFlags() {
 	f.amtActivateCommand.StringVar(&f.DNS, "d", f.lookupEnvOrString("DNS_SUFFIX", ""), "dns suffix override")
 	f.amtActivateCommand.StringVar(&f.Hostname, "h", f.lookupEnvOrString("HOSTNAME", ""), "hostname override")
 	f.amtActivateCommand.StringVar(&f.Profile, "profile", f.lookupEnvOrString("PROFILE", ""), "name of the profile to use")
@@ -25,14 +62,33 @@ func (f *Flags) handleActivateCommand() utils.ReturnCode {
 	// for local activation in ACM mode need a few more items
 	f.amtActivateCommand.StringVar(&f.configContent, "config", "", "specify a config file or smb: file share URL")
 	f.amtActivateCommand.StringVar(&f.LocalConfig.ACMSettings.AMTPassword, "amtPassword", f.lookupEnvOrString("AMT_PASSWORD", ""), "amt password")
-	f.amtActivateCommand.StringVar(&f.LocalConfig.ACMSettings.ProvisioningCert, "provisioningCert", f.lookupEnvOrString("PROVISIONING_CERT", ""), "provisioning certificate")
+	f.amtActivateCommand.StringVar(&f.LocalConfig.ACMSettings.ProvisioningCert, "provisioningCert", f.lookupEnvOrString("PROVISIONING_CERT", ""), "provisioning certificate, base64 encoded or the path to a .pfx file")
 	f.amtActivateCommand.StringVar(&f.LocalConfig.ACMSettings.ProvisioningCertPwd, "provisioningCertPwd", f.lookupEnvOrString("PROVISIONING_CERT_PASSWORD", ""), "provisioning certificate password")
+	f.amtActivateCommand.StringVar(&f.MEBxPassword, "mebxPassword", f.lookupEnvOrString("MEBX_PASSWORD", ""), "MEBx password to set after local ACM activation")
+	f.amtActivateCommand.BoolVar(&f.Interactive, "interactive", false, "Prompt for the activation settings and run pre-flight checks before activating")
+	f.amtActivateCommand.BoolVar(&f.Precheck.Enabled, "precheck", false, "Check control mode, DNS suffix, clock, certificate hashes and server reachability before activating, and stop at the first failed check")
+	f.amtActivateCommand.DurationVar(&f.Precheck.MaxSkew, "maxSkew", 2*time.Minute, "Clock difference to the server or -ntp time above which -precheck fails")
+	f.amtActivateCommand.StringVar(&f.NTPServer, "ntp", "", "NTP server (host or host:port) -precheck compares the host clock with, the server is used when not set")
+	f.amtActivateCommand.BoolVar(&f.Activate.Upgrade, "upgrade", false, "Upgrade a device activated in client control mode to admin control mode, with -local -acm")
+	f.amtActivateCommand.BoolVar(&f.Activate.Reprovision, "reprovision", false, "Deactivate a device that is already activated and activate it again, with -local")
+	f.amtActivateCommand.BoolVar(&f.Activate.Resume, "resume", false, "Send the progress saved for an interrupted RPS activation of the device to the server with the same -profile, for a server that continues activations. RPS starts the activation over")
+	f.amtActivateCommand.StringVar(&f.Activate.SessionFile, "sessionFile", "", "file the state of an RPS activation is saved to until it completes (default activation.json in the rpc folder of the user cache directory)")
+	f.amtActivateCommand.BoolVar(&f.Activate.GeneratePassword, "generatePassword", false, "Generate the AMT password of local CCM activation instead of reading it, it is saved with -out, -keyring or -vault before activating")
+	f.amtActivateCommand.BoolVar(&f.FIPS, "fips", false, fipsUsage)
+	f.amtActivateCommand.StringVar(&f.ChangePassword.OutFile, "out", "", "Write the generated password to this file, readable by the owner only")
+	f.amtActivateCommand.BoolVar(&f.ChangePassword.Keyring, "keyring", false, "Store the generated password in the OS keyring, it is read with -passwordFromKeyring")
+	f.amtActivateCommand.StringVar(&f.ChangePassword.Vault, "vault", "", "Write the generated password to this secret store, ex. 'vault://vault.example.com:8200/secret/amt/device1'")
+	f.amtActivateCommand.StringVar(&f.ChangePassword.VaultToken, "vaultToken", f.lookupEnvOrString("VAULT_TOKEN", ""), "Token of the secret store given with -vault")
+	f.amtActivateCommand.StringVar(&f.Activate.RPSAPI, "rpsAPI", "", "Base URL of the RPS REST API, ex. 'https://server/rps'. The control mode, DNS suffix and certificate hashes of the device are checked against the profile read from it before activating")
+}
 
-	if len(f.commandLineArgs) == 2 {
+func (f *Flags) handleActivateCommand() error {
+	f.setupActivateFlags()
+	if len(f.commandLineArgs) == 2 && len(f.flagDefaults) == 0 {
 		f.amtActivateCommand.PrintDefaults()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
 	}
-	if err := f.amtActivateCommand.Parse(f.commandLineArgs[2:]); err != nil {
+	if err := f.parseWithDefaults(f.amtActivateCommand, f.commandLineArgs[2:]); err != nil {
 		re := regexp.MustCompile(`: .*`)
 		var rc = utils.IncorrectCommandLineParameters
 		switch re.FindString(err.Error()) {
@@ -47,68 +103,248 @@ func (f *Flags) handleActivateCommand() utils.ReturnCode {
 		default:
 			rc = utils.IncorrectCommandLineParameters
 		}
-		return rc
+		return rpcerr.Wrap(rc, err, "")
+	}
+	if f.Interactive {
+		if f.JsonOutput || f.YamlOutput {
+			return newError(utils.InvalidParameterCombination, "error.activate.interactiveWithJSON")
+		}
+		if f.NonInteractive {
+			return newError(utils.InvalidParameterCombination, "error.activate.interactiveOrNonInteractive")
+		}
+		if err := f.promptActivateSettings(); err != nil {
+			return err
+		}
 	}
+	if err := f.validateActivate(); err != nil {
+		return err
+	}
+	if f.Interactive {
+		return f.activatePreflight()
+	}
+	return nil
+}
+
+// validateActivate checks the combination of the activate flags and reads the local
+// configuration of ACM activation
+func (f *Flags) validateActivate() error {
 	if f.Local && f.URL != "" {
-		fmt.Println("provide either a 'url' or a 'local', but not both")
-		return utils.InvalidParameterCombination
+		return newError(utils.InvalidParameterCombination, "error.urlOrLocal")
+	}
+	if (f.Activate.Upgrade || f.Activate.Reprovision) && !f.Local {
+		return newError(utils.InvalidParameterCombination, "error.activate.upgradeOnlyLocal")
+	}
+	if (f.Activate.Resume || f.Activate.SessionFile != "") && f.Local {
+		return newError(utils.InvalidParameterCombination, "error.activate.resumeOnlyRPS")
+	}
+	if f.Activate.RPSAPI != "" {
+		if f.Local {
+			return newError(utils.InvalidParameterCombination, "error.activate.rpsAPIOnlyRPS")
+		}
+		if u, err := url.Parse(f.Activate.RPSAPI); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return newError(utils.IncorrectCommandLineParameters, "error.activate.rpsAPIURL")
+		}
+	}
+	if f.Activate.Upgrade && f.Activate.Reprovision {
+		return newError(utils.InvalidParameterCombination, "error.activate.upgradeOrReprovision")
+	}
+	if f.Activate.GeneratePassword && (!f.Local || !f.UseCCM || f.Activate.Upgrade || f.Activate.Reprovision) {
+		return newError(utils.InvalidParameterCombination, "error.activate.generateOnlyLocalCCM")
+	}
+	if f.Activate.GeneratePassword && f.Password != "" {
+		return newError(utils.InvalidParameterCombination, "error.activate.generateWithPassword")
+	}
+	// the generated password is saved before AMT is changed and is never printed
+	sinks := f.passwordSinks()
+	if f.Activate.GeneratePassword && sinks != 1 {
+		return newError(utils.InvalidParameterCombination, "error.activate.generateWithoutStore")
+	}
+	if !f.Activate.GeneratePassword && sinks > 0 {
+		return newError(utils.InvalidParameterCombination, "error.activate.storeWithoutGenerate")
+	}
+	if f.ChangePassword.Vault != "" {
+		if _, err := secretstore.Open(f.ChangePassword.Vault, f.ChangePassword.VaultToken); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidVault")
+		}
+	}
+	if f.FIPS && !f.Activate.GeneratePassword {
+		return newError(utils.InvalidParameterCombination, "error.activate.fipsWithoutGenerate")
+	}
+	if f.FIPS {
+		if err := utils.CheckFIPS(); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.fips")
+		}
+	}
+	if f.Activate.Upgrade && !f.UseACM {
+		return newError(utils.InvalidParameterCombination, "error.activate.upgradeWithoutACM")
+	}
+	if f.MEBxPassword != "" {
+		if !f.Local || !f.UseACM {
+			return newError(utils.InvalidParameterCombination, "error.activate.mebxOnlyLocalACM")
+		}
+		if err := validateStrongPassword(f.MEBxPassword); err != nil {
+			return wrapError(utils.MissingOrIncorrectMEBxPassword, err, "error.invalidMEBxPassword")
+		}
 	}
 
 	if !f.Local {
 		if f.URL == "" {
-			fmt.Println("-u flag is required and cannot be empty")
 			f.amtActivateCommand.Usage()
-			return utils.MissingOrIncorrectURL
+			return newError(utils.MissingOrIncorrectURL, "error.urlRequired")
 		}
 		if f.Profile == "" {
-			fmt.Println("-profile flag is required and cannot be empty")
 			f.amtActivateCommand.Usage()
-			return utils.MissingOrIncorrectProfile
+			return newError(utils.MissingOrIncorrectProfile, "error.profileRequired")
 		}
 		if f.UUID != "" {
 			uuidPattern := regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
 			if matched := uuidPattern.MatchString(f.UUID); !matched {
-				fmt.Println("uuid provided does not follow proper uuid format")
 				f.amtActivateCommand.Usage()
-				return utils.InvalidUUID
+				return newError(utils.InvalidUUID, "error.activate.invalidUUID")
 			}
 			fmt.Println("Warning: Overriding UUID prevents device from connecting to MPS")
 		}
 	} else {
 		if !f.UseCCM && !f.UseACM || f.UseCCM && f.UseACM {
-			fmt.Println("must specify -ccm or -acm, but not both")
-			return utils.InvalidParameterCombination
+			return newError(utils.InvalidParameterCombination, "error.activate.ccmOrACM")
 		}
 
 		if f.UseACM {
 			rc := f.handleLocalConfig()
 			if rc != utils.Success {
-				return rc
+				return rpcerr.FromReturnCode(rc)
+			}
+			// the common -password flag is accepted in place of -amtPassword
+			if f.LocalConfig.ACMSettings.AMTPassword == "" {
+				f.LocalConfig.ACMSettings.AMTPassword = f.Password
+			}
+			rc = f.loadProvisioningCert()
+			if rc != utils.Success {
+				return rpcerr.FromReturnCode(rc)
 			}
 			// Check if all fields are filled
 			v := reflect.ValueOf(f.LocalConfig.ACMSettings)
 			for i := 0; i < v.NumField(); i++ {
 				if v.Field(i).Interface() == "" { // not checking 0 since authenticantProtocol can and needs to be 0 for EAP-TLS
-					log.Error("Missing value for field: ", v.Type().Field(i).Name)
-					return utils.IncorrectCommandLineParameters
+					return newError(utils.IncorrectCommandLineParameters, "error.activate.missingField", v.Type().Field(i).Name)
 				}
 			}
+			if err := validateStrongPassword(f.LocalConfig.ACMSettings.AMTPassword); err != nil {
+				return wrapError(utils.MissingOrIncorrectPassword, err, "error.activate.invalidAMTPassword")
+			}
 
 		}
 
 		// Only for CCM it asks for password.
-		if !f.UseACM && f.Password == "" {
+		if !f.UseACM && f.Password == "" && !f.Activate.GeneratePassword {
 			if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
-				return utils.MissingOrIncorrectPassword
+				return rpcerr.New(utils.MissingOrIncorrectPassword, "")
 			}
 		}
 		f.LocalConfig.Password = f.Password
 
 		if f.UUID != "" {
-			fmt.Println("-uuid cannot be use in local activation")
 			f.amtActivateCommand.Usage()
-			return utils.InvalidParameterCombination
+			return newError(utils.InvalidParameterCombination, "error.activate.uuidLocal")
+		}
+		// the password of CCM activation becomes the AMT admin password
+		if !f.UseACM && !f.Activate.GeneratePassword {
+			if err := validateStrongPassword(f.Password); err != nil {
+				return wrapError(utils.MissingOrIncorrectPassword, err, "error.invalidPassword")
+			}
+		}
+	}
+	if f.Precheck.Enabled {
+		if f.Precheck.MaxSkew < 0 {
+			return newError(utils.IncorrectCommandLineParameters, "error.negativeMaxSkew")
+		}
+		if err := f.activatePrecheck(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActivationPath returns how activate brings a device in the control mode to the requested
+// control mode. A device already in the requested mode needs nothing, so activate can be
+// run again and again, other activated devices need -upgrade or -reprovision.
+func (f *Flags) ActivationPath(controlMode int) (string, error) {
+	if controlMode == 0 {
+		return ActivationPathActivate, nil
+	}
+	if !f.Local {
+		return "", newError(utils.UnableToActivate, "error.activate.alreadyActivated", utils.InterpretControlMode(controlMode))
+	}
+	if f.Activate.Reprovision {
+		return ActivationPathReprovision, nil
+	}
+	requested := 1
+	if f.UseACM {
+		requested = 2
+	}
+	switch {
+	case controlMode == requested:
+		return ActivationPathNone, nil
+	case controlMode == 1 && f.Activate.Upgrade:
+		return ActivationPathUpgrade, nil
+	case controlMode == 1:
+		return "", newError(utils.UnableToActivate, "error.activate.alreadyCCM")
+	}
+	return "", newError(utils.UnableToActivate, "error.activate.alreadyActivatedReprovision", utils.InterpretControlMode(controlMode))
+}
+
+// validateStrongPassword checks the strong password rules of AMT and the MEBx: 8 to 32
+// ASCII characters with at least one digit, one lower case, one upper case and one non
+// alphanumeric character. '_' and space are valid but do not count as non alphanumeric,
+// ':', ',' and '"' are not allowed. The error names every rule the password breaks.
+func validateStrongPassword(password string) error {
+	if len(password) < utils.MinPasswordLength || len(password) > utils.MaxPasswordLength {
+		return fmt.Errorf("must be %d to %d characters, not %d", utils.MinPasswordLength, utils.MaxPasswordLength, len(password))
+	}
+	var digit, lower, upper, special bool
+	for _, c := range password {
+		switch {
+		case c > unicode.MaxASCII || unicode.IsControl(c):
+			return errors.New("must only contain printable ASCII characters")
+		case c == ':' || c == ',' || c == '"':
+			return fmt.Errorf("must not contain '%c'", c)
+		case unicode.IsDigit(c):
+			digit = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsUpper(c):
+			upper = true
+		case c != '_' && c != ' ':
+			special = true
 		}
 	}
+	var missing []string
+	for _, class := range []struct {
+		found bool
+		name  string
+	}{{digit, "a digit"}, {lower, "a lower case letter"}, {upper, "an upper case letter"}, {special, "a non alphanumeric character other than '_' and space"}} {
+		if !class.found {
+			missing = append(missing, class.name)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New("must contain " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// loadProvisioningCert allows the provisioning certificate to be given as a path
+// to a .pfx file instead of the base64 encoded contents
+func (f *Flags) loadProvisioningCert() utils.ReturnCode {
+	certPath := f.LocalConfig.ACMSettings.ProvisioningCert
+	if !strings.EqualFold(filepath.Ext(certPath), ".pfx") {
+		return utils.Success
+	}
+	pfxBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		log.Error("provisioning certificate error: ", err)
+		return utils.FailedReadingConfiguration
+	}
+	f.LocalConfig.ACMSettings.ProvisioningCert = base64.StdEncoding.EncodeToString(pfxBytes)
 	return utils.Success
 }
@@ -1,7 +1,11 @@
 package flags
 
 import (
+	"encoding/base64"
+	"net"
 	"os"
+	"path/filepath"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
 	"strings"
 	"testing"
@@ -47,6 +51,21 @@ func TestHandleActivateCommandWithTimeOut(t *testing.T) {
 	assert.Equal(t, "16992", flags.LMSPort)
 	assert.Equal(t, AMTTimeoutDuration, flags.AMTTimeoutDuration)
 }
+func TestHandleActivateCommandWithRetries(t *testing.T) {
+	args := []string{"./rpc", "activate", "-u", "wss://localhost", "-profile", "profileName", "-password", "Password"}
+	flags := NewFlags(args)
+	rc := flags.ParseFlags()
+	assert.Equal(t, utils.Success, rc)
+	assert.Equal(t, 3, flags.Retries)
+	assert.Equal(t, time.Second, flags.RetryDelay)
+
+	args = append(args, "-retries", "5", "-retryDelay", "250ms")
+	flags = NewFlags(args)
+	rc = flags.ParseFlags()
+	assert.Equal(t, utils.Success, rc)
+	assert.Equal(t, 5, flags.Retries)
+	assert.Equal(t, 250*time.Millisecond, flags.RetryDelay)
+}
 func TestHandleActivateCommandWithLMS(t *testing.T) {
 	args := []string{"./rpc", "activate", "-u", "wss://localhost", "-profile", "profileName", "-lmsaddress", "1.1.1.1", "-lmsport", "99"}
 	flags := NewFlags(args)
@@ -229,12 +248,121 @@ func TestHandleActivateCommandLocal(t *testing.T) {
 			wantResult: utils.Success,
 		},
 		"should pass wif acm and ACM Settings specified": {
+			cmdLine: "./rpc activate -local -acm " +
+				" -amtPassword " + strongPassword +
+				` -provisioningCert MIIW/gIBAzCCFroGCSqGSIb3DQEHAaCCFqsEghanMIIWozCCBgwGCSqGSIb3DQEHAaCCBf0EggX5MIIF9TCCBfEGCyqGSIb3DQEMCgECoIIE/jCCBPowHAYKKoZIhvc` +
+				" -provisioningCertPwd " + trickyPassword,
+			wantResult: utils.Success,
+		},
+		"should pass with acm and common password flag": {
+			cmdLine: "./rpc activate -local -acm " +
+				" -password " + strongPassword +
+				` -provisioningCert MIIW/gIBAzCCFroGCSqGSIb3DQEHAaCCFqsEghanMIIWozCCBgwGCSqGSIb3DQEHAaCCBf0EggX5MIIF9TCCBfEGCyqGSIb3DQEMCgECoIIE/jCCBPowHAYKKoZIhvc` +
+				" -provisioningCertPwd " + trickyPassword,
+			wantResult: utils.Success,
+		},
+		"should pass with acm and mebx password": {
+			cmdLine: "./rpc activate -local -acm " +
+				" -password " + strongPassword +
+				` -provisioningCert MIIW/gIBAzCCFroGCSqGSIb3DQEHAaCCFqsEghanMIIWozCCBgwGCSqGSIb3DQEHAaCCBf0EggX5MIIF9TCCBfEGCyqGSIb3DQEMCgECoIIE/jCCBPowHAYKKoZIhvc` +
+				" -provisioningCertPwd " + trickyPassword +
+				" -mebxPassword Mebx!Passw0rd",
+			wantResult: utils.Success,
+		},
+		"should fail with acm and weak amt password": {
 			cmdLine: "./rpc activate -local -acm " +
 				" -amtPassword " + trickyPassword +
 				` -provisioningCert MIIW/gIBAzCCFroGCSqGSIb3DQEHAaCCFqsEghanMIIWozCCBgwGCSqGSIb3DQEHAaCCBf0EggX5MIIF9TCCBfEGCyqGSIb3DQEMCgECoIIE/jCCBPowHAYKKoZIhvc` +
 				" -provisioningCertPwd " + trickyPassword,
+			wantResult: utils.MissingOrIncorrectPassword,
+		},
+		"should fail with ccm and weak password": {
+			cmdLine:    "./rpc activate -local -ccm -password password",
+			wantResult: utils.MissingOrIncorrectPassword,
+		},
+		"should fail with acm and weak mebx password": {
+			cmdLine:    "./rpc activate -local -acm -config ../../config.yaml -mebxPassword password",
+			wantResult: utils.MissingOrIncorrectMEBxPassword,
+		},
+		"should fail with ccm and mebx password": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -mebxPassword Mebx!Passw0rd",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should pass with ccm and reprovision": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -reprovision",
+			wantResult: utils.Success,
+		},
+		"should pass with acm and upgrade": {
+			cmdLine:    "./rpc activate -local -acm -config ../../config.yaml -upgrade",
+			wantResult: utils.Success,
+		},
+		"should pass with ccm and generatePassword": {
+			cmdLine:    "./rpc activate -local -ccm -generatePassword -keyring",
+			wantResult: utils.Success,
+		},
+		"should fail with generatePassword without out, keyring or vault": {
+			cmdLine:    "./rpc activate -local -ccm -generatePassword",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with generatePassword and out and keyring": {
+			cmdLine:    "./rpc activate -local -ccm -generatePassword -out amt.pwd -keyring",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with out without generatePassword": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -out amt.pwd",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with generatePassword and password": {
+			cmdLine:    "./rpc activate -local -ccm -generatePassword -password P@ssw0rd",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with acm and generatePassword": {
+			cmdLine:    "./rpc activate -local -acm -config ../../config.yaml -generatePassword",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with fips without generatePassword": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -fips",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with ccm and upgrade": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -upgrade",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with upgrade and reprovision": {
+			cmdLine:    "./rpc activate -local -acm -config ../../config.yaml -upgrade -reprovision",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with remote activation and reprovision": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -reprovision",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should pass with remote activation and resume": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -resume -sessionFile activation.json",
+			wantResult: utils.Success,
+		},
+		"should pass with remote activation and rpsAPI": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -rpsAPI https://localhost/rps",
 			wantResult: utils.Success,
 		},
+		"should fail with an rpsAPI that is not an http URL": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -rpsAPI wss://localhost",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail with local activation and rpsAPI": {
+			cmdLine:    "./rpc activate -local -ccm -password " + strongPassword + " -rpsAPI https://localhost/rps",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with local activation and resume": {
+			cmdLine:    "./rpc activate -local -ccm -password P@ssw0rd -resume",
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail with acm and missing pfx file": {
+			cmdLine: "./rpc activate -local -acm " +
+				" -amtPassword " + strongPassword +
+				" -provisioningCert ./nofilehere.pfx" +
+				" -provisioningCertPwd " + trickyPassword,
+			wantResult: utils.FailedReadingConfiguration,
+		},
 	}
 
 	for name, tc := range tests {
@@ -248,3 +376,171 @@ func TestHandleActivateCommandLocal(t *testing.T) {
 	}
 
 }
+
+func TestHandleActivateCommandFIPS(t *testing.T) {
+	// -fips fails unless the binary runs with a FIPS 140 module enabled
+	want := utils.IncorrectCommandLineParameters
+	if utils.CheckFIPS() == nil {
+		want = utils.Success
+	}
+	flags := NewFlags(strings.Fields("./rpc activate -local -ccm -generatePassword -keyring -fips"))
+	assert.Equal(t, want, flags.ParseFlags())
+}
+
+func TestValidateStrongPassword(t *testing.T) {
+	tests := map[string]struct {
+		password string
+		wantErr  bool
+	}{
+		"valid":                  {password: "Mebx!Passw0rd"},
+		"valid with max length":  {password: "Aa1!" + strings.Repeat("x", 28)},
+		"too short":              {password: "Aa1!", wantErr: true},
+		"too long":               {password: "Aa1!" + strings.Repeat("x", 29), wantErr: true},
+		"missing digit":          {password: "Mebx!Password", wantErr: true},
+		"missing upper case":     {password: "mebx!passw0rd", wantErr: true},
+		"missing lower case":     {password: "MEBX!PASSW0RD", wantErr: true},
+		"underscore not special": {password: "Mebx_Passw0rd", wantErr: true},
+		"contains colon":         {password: "Mebx:Passw0rd", wantErr: true},
+		"contains non ascii":     {password: "Mebx!Passw0rdé", wantErr: true},
+		"only symbols":           {password: trickyPassword, wantErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateStrongPassword(tc.password)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestLoadProvisioningCert(t *testing.T) {
+	pfxPath := filepath.Join(t.TempDir(), "provisioning.pfx")
+	err := os.WriteFile(pfxPath, []byte("pfx contents"), 0600)
+	assert.Nil(t, err)
+	flags := NewFlags([]string{"./rpc", "activate"})
+	flags.LocalConfig.ACMSettings.ProvisioningCert = pfxPath
+	assert.Equal(t, utils.Success, flags.loadProvisioningCert())
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("pfx contents")), flags.LocalConfig.ACMSettings.ProvisioningCert)
+
+	// already encoded content is left alone
+	assert.Equal(t, utils.Success, flags.loadProvisioningCert())
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("pfx contents")), flags.LocalConfig.ACMSettings.ProvisioningCert)
+}
+
+func TestHandleActivateCommandInteractive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	serverURL := "wss://" + listener.Addr().String() + "/activate"
+	newFlags := func(args ...string) *Flags {
+		f := NewFlags(append([]string{"./rpc", "activate", "-interactive"}, args...))
+		f.amtCommand.PTHI = MockPTHICommands{}
+		return f
+	}
+
+	t.Run("remote activation with a profile", func(t *testing.T) {
+		defer userInput(t, "1\n"+serverURL+"\nacmprofile\n\ny\n")()
+		f := newFlags()
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.False(t, f.Local)
+		assert.Equal(t, serverURL, f.URL)
+		assert.Equal(t, "acmprofile", f.Profile)
+	})
+	t.Run("keeps the command line values as defaults", func(t *testing.T) {
+		defer userInput(t, "\n\n\n\ny\n")()
+		f := newFlags("-u", serverURL, "-profile", "ccmprofile")
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.Equal(t, "ccmprofile", f.Profile)
+	})
+	t.Run("local CCM asks the password again when the confirmation differs", func(t *testing.T) {
+		defer userInput(t, "2\nP@ssw0rd1\nP@ssw0rd2\nP@ssw0rd1\nP@ssw0rd1\ny\n")()
+		f := newFlags()
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.True(t, f.Local)
+		assert.True(t, f.UseCCM)
+		assert.Equal(t, "P@ssw0rd1", f.Password)
+	})
+	t.Run("asks again for an invalid server URL", func(t *testing.T) {
+		defer userInput(t, "1\nhttps://server\nserver\n\n")()
+		f := newFlags()
+		assert.Equal(t, utils.MissingOrIncorrectURL, f.ParseFlags())
+	})
+	t.Run("stops when the answer is not yes", func(t *testing.T) {
+		defer userInput(t, "1\n"+serverURL+"\nacmprofile\n\nn\n")()
+		f := newFlags()
+		assert.Equal(t, utils.InvalidUserInput, f.ParseFlags())
+	})
+	t.Run("fails the pre-flight check of an activated device", func(t *testing.T) {
+		mode = 1
+		defer func() { mode = 0 }()
+		defer userInput(t, "1\n"+serverURL+"\nacmprofile\n\n")()
+		f := newFlags()
+		assert.Equal(t, utils.UnableToActivate, f.ParseFlags())
+	})
+	t.Run("fails the pre-flight check of an unreachable server", func(t *testing.T) {
+		closed, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		closed.Close()
+		defer userInput(t, "1\nwss://"+closed.Addr().String()+"\nacmprofile\n\n")()
+		f := newFlags()
+		assert.Equal(t, utils.MissingOrIncorrectURL, f.ParseFlags())
+	})
+	t.Run("can not be used with json output", func(t *testing.T) {
+		f := newFlags("-json")
+		assert.Equal(t, utils.InvalidParameterCombination, f.ParseFlags())
+	})
+}
+
+func TestActivationPath(t *testing.T) {
+	tests := map[string]struct {
+		flags       Flags
+		controlMode int
+		want        string
+	}{
+		"activates a device in pre-provisioning": {
+			flags: Flags{Local: true, UseCCM: true},
+			want:  ActivationPathActivate,
+		},
+		"does nothing in the requested mode": {
+			flags:       Flags{Local: true, UseACM: true},
+			controlMode: 2,
+			want:        ActivationPathNone,
+		},
+		"upgrades CCM to ACM": {
+			flags:       Flags{Local: true, UseACM: true, Activate: ActivateFlags{Upgrade: true}},
+			controlMode: 1,
+			want:        ActivationPathUpgrade,
+		},
+		"reprovisions ACM to CCM": {
+			flags:       Flags{Local: true, UseCCM: true, Activate: ActivateFlags{Reprovision: true}},
+			controlMode: 2,
+			want:        ActivationPathReprovision,
+		},
+		"reprovisions in the requested mode": {
+			flags:       Flags{Local: true, UseCCM: true, Activate: ActivateFlags{Reprovision: true}},
+			controlMode: 1,
+			want:        ActivationPathReprovision,
+		},
+		"fails CCM to ACM without upgrade": {
+			flags:       Flags{Local: true, UseACM: true},
+			controlMode: 1,
+		},
+		"fails ACM to CCM without reprovision": {
+			flags:       Flags{Local: true, UseCCM: true},
+			controlMode: 2,
+		},
+		"fails remote activation of an activated device": {
+			controlMode: 1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := tc.flags.ActivationPath(tc.controlMode)
+			assert.Equal(t, tc.want, path)
+			if tc.want == "" {
+				assert.Equal(t, utils.UnableToActivate, rpcerr.ReturnCodeOf(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
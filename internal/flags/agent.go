@@ -0,0 +1,94 @@
+package flags
+
+import (
+	"errors"
+	"net"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// maintenanceTasks are the maintenance tasks the agent and maintenance -task run against the server
+var maintenanceTasks = []string{
+	utils.SubCommandSyncClock,
+	utils.SubCommandSyncHostname,
+	utils.SubCommandSyncIP,
+	utils.SubCommandSyncDeviceInfo,
+}
+
+func (f *Flags) handleAgentCommand() error {
+	return f.parseAgentFlags(f.commandLineArgs[2:])
+}
+
+// parseAgentFlags parses the agent options, they are also used to install the agent as a service
+func (f *Flags) parseAgentFlags(args []string) error {
+	var tasks string
+	f.amtAgentCommand.DurationVar(&f.AgentInterval, "interval", time.Hour, "Time between maintenance runs (ex. '1h' or '30m')")
+	f.amtAgentCommand.StringVar(&tasks, "tasks", strings.Join(maintenanceTasks[:3], ","), "Comma separated maintenance tasks to run ("+strings.Join(maintenanceTasks, ",")+")")
+	f.amtAgentCommand.StringVar(&f.AgentControl, "control", "", "Unix socket, or localhost address on Windows, the control API is served on, none when empty")
+	f.amtAgentCommand.StringVar(&f.AgentSecretsFile, "secretsFile", "", "File with the AMT_PASSWORD, PROXY_PASSWORD and RPS_TOKEN of the agent as NAME=value lines, written by service install")
+	f.amtAgentCommand.StringVar(&f.AgentControlToken, "controlToken", "", "File with the token clients of the control API authenticate with, created when missing (default control.token in the rpc folder of the user cache directory)")
+	f.setupInterfaceFlags(f.amtAgentCommand)
+	f.setupSyncHostnameFlags(f.amtAgentCommand)
+	if err := f.parseWithDefaults(f.amtAgentCommand, args); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if err := validateControlAddress(f.AgentControl, runtime.GOOS); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if rc := f.validateInterfaceFlags(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	if f.AgentInterval < time.Minute {
+		return newError(utils.IncorrectCommandLineParameters, "error.intervalTooShort")
+	}
+	f.AgentTasks = nil
+	for _, task := range strings.Split(tasks, ",") {
+		task = strings.TrimSpace(task)
+		if !IsMaintenanceTask(task) {
+			return newError(utils.IncorrectCommandLineParameters, "error.agent.unsupportedTask", task)
+		}
+		f.AgentTasks = append(f.AgentTasks, task)
+	}
+	if f.URL == "" {
+		f.amtAgentCommand.Usage()
+		return newError(utils.MissingOrIncorrectURL, "error.urlRequired")
+	}
+	if f.AgentSecretsFile != "" {
+		if err := f.readSecretsFile(); err != nil {
+			return wrapError(utils.MissingOrIncorrectPassword, err, "error.agent.readSecretsFile")
+		}
+	}
+	if f.Password == "" {
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	f.LocalConfig.Password = f.Password
+	return nil
+}
+
+// validateControlAddress checks the -control address: a path on the OS with unix sockets,
+// a loopback address on Windows so only local software reaches the API
+func validateControlAddress(address string, goos string) error {
+	if address == "" || goos != "windows" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+		return errors.New("-control must be a loopback address such as 127.0.0.1:16995 on Windows")
+	}
+	return nil
+}
+
+// IsMaintenanceTask tells whether the agent and maintenance -task can run the task
+func IsMaintenanceTask(task string) bool {
+	for _, t := range maintenanceTasks {
+		if t == task {
+			return true
+		}
+	}
+	return false
+}
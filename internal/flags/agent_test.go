@@ -0,0 +1,103 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlagsAgent(t *testing.T) {
+	argUrl := "-u wss://localhost"
+	argCurPw := "-password " + trickyPassword
+	cmdBase := "./rpc agent"
+
+	tests := map[string]struct {
+		cmdLine      string
+		wantResult   utils.ReturnCode
+		wantInterval time.Duration
+		wantTasks    []string
+		userInput    string
+	}{
+		"should pass with defaults": {
+			cmdLine:      cmdBase + " " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock", "synchostname", "syncip"},
+		},
+		"should pass with interval and tasks": {
+			cmdLine:      cmdBase + " -interval 15m -tasks syncclock,syncdeviceinfo " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantInterval: 15 * time.Minute,
+			wantTasks:    []string{"syncclock", "syncdeviceinfo"},
+		},
+		"should pass with password user input": {
+			cmdLine:      cmdBase + " " + argUrl,
+			wantResult:   utils.Success,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock", "synchostname", "syncip"},
+			userInput:    trickyPassword,
+		},
+		"should pass with control socket": {
+			cmdLine:      cmdBase + " -control /run/rpc/control.sock " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock", "synchostname", "syncip"},
+		},
+		"should fail - interval too short": {
+			cmdLine:      cmdBase + " -interval 10s " + argUrl + " " + argCurPw,
+			wantResult:   utils.IncorrectCommandLineParameters,
+			wantInterval: 10 * time.Second,
+		},
+		"should fail - unsupported task": {
+			cmdLine:      cmdBase + " -tasks syncclock,changepassword " + argUrl + " " + argCurPw,
+			wantResult:   utils.IncorrectCommandLineParameters,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock"},
+		},
+		"should fail - required websocket URL": {
+			cmdLine:      cmdBase + " " + argCurPw,
+			wantResult:   utils.MissingOrIncorrectURL,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock", "synchostname", "syncip"},
+		},
+		"should fail - required amt password": {
+			cmdLine:      cmdBase + " " + argUrl,
+			wantResult:   utils.MissingOrIncorrectPassword,
+			wantInterval: time.Hour,
+			wantTasks:    []string{"syncclock", "synchostname", "syncip"},
+		},
+		"should fail - bad param": {
+			cmdLine:      cmdBase + " -nope " + argUrl + " " + argCurPw,
+			wantResult:   utils.IncorrectCommandLineParameters,
+			wantInterval: time.Hour,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			args := strings.Fields(tc.cmdLine)
+			if tc.userInput != "" {
+				defer userInput(t, tc.userInput)()
+			}
+			flags := NewFlags(args)
+			gotResult := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, gotResult)
+			assert.Equal(t, utils.CommandAgent, flags.Command)
+			assert.Equal(t, tc.wantInterval, flags.AgentInterval)
+			assert.Equal(t, tc.wantTasks, flags.AgentTasks)
+		})
+	}
+}
+
+func TestValidateControlAddress(t *testing.T) {
+	assert.NoError(t, validateControlAddress("", "windows"))
+	assert.NoError(t, validateControlAddress("/run/rpc/control.sock", "linux"))
+	assert.NoError(t, validateControlAddress("127.0.0.1:16995", "windows"))
+	assert.NoError(t, validateControlAddress("[::1]:16995", "windows"))
+	assert.Error(t, validateControlAddress("0.0.0.0:16995", "windows"))
+	assert.Error(t, validateControlAddress("localhost:16995", "windows"))
+	assert.Error(t, validateControlAddress(`C:\rpc\control`, "windows"))
+}
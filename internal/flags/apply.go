@@ -0,0 +1,296 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"rpc/internal/config"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// documentFromStdin as the value of -f reads the document from stdin
+const documentFromStdin = "-"
+
+// Sections of an apply document, in the order apply brings them to the desired state
+const (
+	ApplySectionActivation = "activation"
+	ApplySectionHostname   = "hostname"
+	ApplySectionWifi       = "wifi"
+	ApplySectionTLS        = "tls"
+	ApplySectionCIRA       = "cira"
+)
+
+type ApplyFlags struct {
+	// File is the desired state document, or - for stdin
+	File string
+	// Sections lists the sections given in the document, a section left out is not changed
+	Sections []string
+}
+
+// Has reports whether the document describes the section
+func (a ApplyFlags) Has(section string) bool {
+	for _, s := range a.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyDocument is the desired state of the device read by apply. The settings are those of
+// activate -local, configure and maintenance synchostname, certificates are file paths.
+type ApplyDocument struct {
+	Password         string                  `json:"password"`
+	Activation       *ApplyActivation        `json:"activation"`
+	Hostname         *ApplyHostname          `json:"hostname"`
+	WifiConfigs      config.WifiConfigs      `json:"wifiConfigs"`
+	Ieee8021xConfigs config.Ieee8021xConfigs `json:"ieee8021xConfigs"`
+	TLS              *ApplyTLS               `json:"tls"`
+	CIRA             *ApplyCIRA              `json:"cira"`
+}
+
+type ApplyActivation struct {
+	// Mode is ccm or acm, a device activated in CCM is upgraded to ACM
+	Mode                string `json:"mode"`
+	ProvisioningCert    string `json:"provisioningCert"`
+	ProvisioningCertPwd string `json:"provisioningCertPwd"`
+	MEBxPassword        string `json:"mebxPassword"`
+}
+
+type ApplyHostname struct {
+	FQDN      bool   `json:"fqdn"`
+	Lowercase bool   `json:"lowercase"`
+	Truncate  bool   `json:"truncate"`
+	Template  string `json:"template"`
+}
+
+type ApplyTLS struct {
+	Mode      string `json:"mode"`
+	Cert      string `json:"cert"`
+	CACert    string `json:"caCert"`
+	TrustedCN string `json:"trustedCN"`
+	Local     bool   `json:"local"`
+}
+
+type ApplyCIRA struct {
+	MPSAddress           string   `json:"mpsAddress"`
+	MPSPort              int      `json:"mpsPort"`
+	MPSUser              string   `json:"mpsUser"`
+	MPSPassword          string   `json:"mpsPassword"`
+	MPSCert              string   `json:"mpsCert"`
+	MPSCommonName        string   `json:"mpsCommonName"`
+	SecondaryAddress     string   `json:"secondaryAddress"`
+	SecondaryPort        int      `json:"secondaryPort"`
+	SecondaryCommonName  string   `json:"secondaryCommonName"`
+	EnvironmentDetection []string `json:"environmentDetection"`
+	PeriodicInterval     *int     `json:"periodicInterval"`
+}
+
+// handleApplyCommand reads the desired state document and fills the flags of the commands
+// that bring the device to it
+func (f *Flags) handleApplyCommand() error {
+	fs := f.applyCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Apply.File, "f", "", "JSON document with the desired state of the device, - reads it from stdin")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password, the password of the document is used when not set")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.BoolVar(&f.DryRun, "dryrun", false, "Print the plan of changes without applying it")
+	fs.String(defaultsFlag, "", defaultsUsage)
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if f.Apply.File == "" {
+		fs.Usage()
+		return newError(utils.IncorrectCommandLineParameters, "error.apply.documentRequired")
+	}
+	var content []byte
+	var err error
+	if f.Apply.File == documentFromStdin {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(f.Apply.File)
+	}
+	if err != nil {
+		return wrapError(utils.FailedReadingConfiguration, err, "error.apply.readDocument")
+	}
+	var doc ApplyDocument
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// a misspelled setting would otherwise be left out of the desired state silently
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return wrapError(utils.FailedReadingConfiguration, err, "error.apply.invalidDocument")
+	}
+	if err := f.loadApplyDocument(doc); err != nil {
+		return err
+	}
+
+	f.Local = true
+	if f.Password == "" {
+		f.Password = doc.Password
+	}
+	if f.Password == "" {
+		// the prompt fails when the document was read from stdin
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	f.LocalConfig.Password = f.Password
+	f.LocalConfig.ACMSettings.AMTPassword = f.Password
+	return nil
+}
+
+// loadApplyDocument checks the sections of the document like the flags of the matching
+// commands and fills them in
+func (f *Flags) loadApplyDocument(doc ApplyDocument) error {
+	f.Apply.Sections = nil
+	if a := doc.Activation; a != nil {
+		switch strings.ToLower(a.Mode) {
+		case "ccm":
+			f.UseCCM = true
+		case "acm":
+			f.UseACM = true
+			f.Activate.Upgrade = true
+			f.LocalConfig.ACMSettings.ProvisioningCert = a.ProvisioningCert
+			f.LocalConfig.ACMSettings.ProvisioningCertPwd = a.ProvisioningCertPwd
+			if a.ProvisioningCert == "" || a.ProvisioningCertPwd == "" {
+				return newError(utils.MissingOrInvalidConfiguration, "error.apply.acmCertRequired")
+			}
+			if rc := f.loadProvisioningCert(); rc != utils.Success {
+				return rpcerr.FromReturnCode(rc)
+			}
+		default:
+			return newError(utils.MissingOrInvalidConfiguration, "error.apply.activationMode", a.Mode)
+		}
+		if a.MEBxPassword != "" {
+			if !f.UseACM {
+				return newError(utils.MissingOrInvalidConfiguration, "error.apply.mebxOnlyACM")
+			}
+			if err := validateStrongPassword(a.MEBxPassword); err != nil {
+				return wrapError(utils.MissingOrIncorrectMEBxPassword, err, "error.invalidMEBxPassword")
+			}
+			f.MEBxPassword = a.MEBxPassword
+		}
+		f.Apply.Sections = append(f.Apply.Sections, ApplySectionActivation)
+	}
+	if h := doc.Hostname; h != nil {
+		if h.Template != "" && strings.Count(h.Template, hostnamePlaceholder) != 1 {
+			return newError(utils.MissingOrInvalidConfiguration, "error.apply.hostnameTemplate", hostnamePlaceholder)
+		}
+		f.SyncHostname = SyncHostnameFlags{FQDN: h.FQDN, Lowercase: h.Lowercase, Truncate: h.Truncate, Template: h.Template}
+		if rc := f.LookupHostnameInfo(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+		f.Apply.Sections = append(f.Apply.Sections, ApplySectionHostname)
+	}
+	if doc.WifiConfigs != nil {
+		f.LocalConfig.WifiConfigs = doc.WifiConfigs
+		f.LocalConfig.Ieee8021xConfigs = doc.Ieee8021xConfigs
+		if rc := f.verifyWifiConfigurations(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+		f.Apply.Sections = append(f.Apply.Sections, ApplySectionWifi)
+	}
+	if t := doc.TLS; t != nil {
+		if err := f.loadApplyTLS(*t); err != nil {
+			return err
+		}
+		f.Apply.Sections = append(f.Apply.Sections, ApplySectionTLS)
+	}
+	if c := doc.CIRA; c != nil {
+		if err := f.loadApplyCIRA(*c); err != nil {
+			return err
+		}
+		f.Apply.Sections = append(f.Apply.Sections, ApplySectionCIRA)
+	}
+	if len(f.Apply.Sections) == 0 {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.emptyDocument")
+	}
+	return nil
+}
+
+func (f *Flags) loadApplyTLS(t ApplyTLS) error {
+	f.TLSSettings = TLSSettingsFlags{Mode: TLSModeServer, TrustedCN: t.TrustedCN, Local: t.Local}
+	if t.Mode != "" {
+		mode, err := ParseTLSMode(t.Mode)
+		if err != nil {
+			return rpcerr.Wrap(utils.MissingOrInvalidConfiguration, err, "")
+		}
+		f.TLSSettings.Mode = mode
+	}
+	// the key pair of a CSR stays in AMT until the signed certificate is installed
+	if t.Cert == "" {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.tlsCertRequired")
+	}
+	if !f.TLSSettings.Mode.IsMutual() && (t.CACert != "" || t.TrustedCN != "") {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.tlsCACertWithoutMutual")
+	}
+	if f.TLSSettings.Mode.IsMutual() && t.CACert == "" {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.tlsMutualWithoutCACert")
+	}
+	var err error
+	if f.TLSSettings.Cert, err = readCertificateFile(t.Cert); err != nil {
+		return wrapError(utils.FailedReadingConfiguration, err, "error.readCert")
+	}
+	if t.CACert != "" {
+		if f.TLSSettings.CACert, err = readCertificateFile(t.CACert); err != nil {
+			return wrapError(utils.FailedReadingConfiguration, err, "error.readCACert")
+		}
+	}
+	return nil
+}
+
+func (f *Flags) loadApplyCIRA(c ApplyCIRA) error {
+	cira := CIRASettingsFlags{
+		MPSAddress:    c.MPSAddress,
+		MPSPort:       c.MPSPort,
+		MPSUser:       c.MPSUser,
+		MPSPassword:   c.MPSPassword,
+		MPSCommonName: c.MPSCommonName,
+		// the secondary MPS is left out when secondaryAddress is empty
+		SecondaryAddress:    c.SecondaryAddress,
+		SecondaryPort:       c.SecondaryPort,
+		SecondaryCommonName: c.SecondaryCommonName,
+		// the defaults of configure cira
+		PeriodicInterval: 60,
+	}
+	if cira.MPSPort == 0 {
+		cira.MPSPort = 4433
+	}
+	if c.PeriodicInterval != nil {
+		cira.PeriodicInterval = *c.PeriodicInterval
+	}
+	if cira.MPSAddress == "" || cira.MPSUser == "" || cira.MPSPassword == "" || c.MPSCert == "" {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.ciraRequired")
+	}
+	if cira.MPSPort < 1 || cira.MPSPort > 65535 {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.ciraPort")
+	}
+	if cira.PeriodicInterval < 0 {
+		return newError(utils.MissingOrInvalidConfiguration, "error.apply.ciraNegativePeriodic")
+	}
+	if cira.MPSCommonName == "" {
+		cira.MPSCommonName = cira.MPSAddress
+	}
+	if err := cira.setSecondaryDefaults(); err != nil {
+		return wrapError(utils.MissingOrInvalidConfiguration, err, "error.apply.invalidSecondaryMPS")
+	}
+	cira.addEnvironmentDetection(c.EnvironmentDetection)
+	var err error
+	if cira.MPSRootCert, err = readCertificateFile(c.MPSCert); err != nil {
+		return wrapError(utils.MissingOrIncorrectCACert, err, "error.readMPSCert")
+	}
+	f.CIRASettings = cira
+	return nil
+}
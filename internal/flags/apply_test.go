@@ -0,0 +1,101 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleApplyCommand(t *testing.T) {
+	cert := writeTestCertificate(t)
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	full := write("full.json", `{
+		"password": "P@ssw0rd",
+		"activation": {"mode": "ccm"},
+		"wifiConfigs": [{"profileName": "office", "ssid": "Office", "priority": 1, "authenticationMethod": 6, "encryptionMethod": 4, "pskPassphrase": "Passphrase1"}],
+		"tls": {"mode": "Server", "cert": "`+filepath.ToSlash(cert)+`"},
+		"cira": {"mpsAddress": "mps.example.com", "mpsUser": "admin", "mpsPassword": "MPSPassw0rd!", "mpsCert": "`+filepath.ToSlash(cert)+`", "environmentDetection": ["corp.example.com"]}
+	}`)
+	tlsOnly := write("tls.json", `{"tls": {"mode": "Mutual", "cert": "`+filepath.ToSlash(cert)+`", "caCert": "`+filepath.ToSlash(cert)+`"}}`)
+	unknown := write("unknown.json", `{"password": "P@ssw0rd", "tsl": {}}`)
+	empty := write("empty.json", `{"password": "P@ssw0rd"}`)
+	badMode := write("mode.json", `{"password": "P@ssw0rd", "activation": {"mode": "admin"}}`)
+	noCert := write("nocert.json", `{"password": "P@ssw0rd", "tls": {"mode": "Server"}}`)
+
+	tests := map[string]struct {
+		cmdLine      string
+		wantResult   utils.ReturnCode
+		wantSections []string
+	}{
+		"should read every section": {
+			cmdLine:      "./rpc apply -f " + full,
+			wantResult:   utils.Success,
+			wantSections: []string{ApplySectionActivation, ApplySectionWifi, ApplySectionTLS, ApplySectionCIRA},
+		},
+		"should take the password from the command line": {
+			cmdLine:      "./rpc apply -password P@ssw0rd -dryrun -f " + tlsOnly,
+			wantResult:   utils.Success,
+			wantSections: []string{ApplySectionTLS},
+		},
+		"should fail without -f": {
+			cmdLine:    "./rpc apply -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on a missing file": {
+			cmdLine:    "./rpc apply -f " + filepath.Join(dir, "missing.json"),
+			wantResult: utils.FailedReadingConfiguration,
+		},
+		"should fail on unknown settings": {
+			cmdLine:    "./rpc apply -f " + unknown,
+			wantResult: utils.FailedReadingConfiguration,
+		},
+		"should fail on a document without sections": {
+			cmdLine:    "./rpc apply -f " + empty,
+			wantResult: utils.MissingOrInvalidConfiguration,
+		},
+		"should fail on an unknown activation mode": {
+			cmdLine:    "./rpc apply -f " + badMode,
+			wantResult: utils.MissingOrInvalidConfiguration,
+		},
+		"should fail on tls without a certificate": {
+			cmdLine:    "./rpc apply -f " + noCert,
+			wantResult: utils.MissingOrInvalidConfiguration,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandApply, flags.Command)
+			if rc != utils.Success {
+				return
+			}
+			assert.True(t, flags.Local)
+			assert.Equal(t, tc.wantSections, flags.Apply.Sections)
+			assert.Equal(t, "P@ssw0rd", flags.LocalConfig.Password)
+		})
+	}
+
+	t.Run("should fill the flags of the sections", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "apply", "-f", full})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.True(t, flags.UseCCM)
+		assert.Equal(t, "office", flags.LocalConfig.WifiConfigs[0].ProfileName)
+		assert.Equal(t, TLSModeServer, flags.TLSSettings.Mode)
+		assert.NotEmpty(t, flags.TLSSettings.Cert)
+		assert.Equal(t, 4433, flags.CIRASettings.MPSPort)
+		assert.Equal(t, 60, flags.CIRASettings.PeriodicInterval)
+		assert.Equal(t, "mps.example.com", flags.CIRASettings.MPSCommonName)
+		assert.Equal(t, []string{"corp.example.com"}, flags.CIRASettings.EnvironmentDetection)
+	})
+}
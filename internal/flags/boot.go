@@ -0,0 +1,75 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// BootSources are the values of boot -source
+var BootSources = []string{"pxe", "hdd", "cd"}
+
+type BootFlags struct {
+	// Source is the device AMT boots from on the next boot, one of BootSources
+	Source string
+	// Once is always set, AMT applies the boot settings to the next boot only. -once is
+	// accepted for the scripts that pass it.
+	Once bool
+	// Reset resets the device after the boot source is set
+	Reset bool
+}
+
+// handleBootCommand reads the boot source rpc boot selects in AMT
+func (f *Flags) handleBootCommand() error {
+	fs := f.bootCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.StringVar(&f.Boot.Source, "source", "", "Device to boot from on the next boot: "+strings.Join(BootSources, ", "))
+	fs.BoolVar(&f.Boot.Once, "once", true, "Boot from -source on the next boot only, the only mode AMT supports")
+	fs.BoolVar(&f.Boot.Reset, "reset", false, "Reset the device once the boot source is set")
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if !f.Boot.Once {
+		return newError(utils.IncorrectCommandLineParameters, "error.boot.once")
+	}
+	f.Boot.Source = strings.ToLower(f.Boot.Source)
+	if f.Boot.Source == "usb" {
+		return newError(utils.IncorrectCommandLineParameters, "error.boot.usb")
+	}
+	if !isBootSource(f.Boot.Source) {
+		fs.Usage()
+		return newError(utils.IncorrectCommandLineParameters, "error.boot.source", strings.Join(BootSources, ", "))
+	}
+
+	// the boot configuration is sent to AMT directly
+	f.Local = true
+	if f.Password == "" {
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	return nil
+}
+
+func isBootSource(source string) bool {
+	for _, s := range BootSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleBootCommand(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantBoot   BootFlags
+	}{
+		"should boot from PXE and reset": {
+			cmdLine:    "./rpc boot -source pxe -reset -password P@ssw0rd",
+			wantResult: utils.Success,
+			wantBoot:   BootFlags{Source: "pxe", Once: true, Reset: true},
+		},
+		"should accept -once": {
+			cmdLine:    "./rpc boot -source pxe -once -reset -password P@ssw0rd",
+			wantResult: utils.Success,
+			wantBoot:   BootFlags{Source: "pxe", Once: true, Reset: true},
+		},
+		"should fail on -once=false, AMT has no persistent boot source": {
+			cmdLine:    "./rpc boot -source pxe -once=false -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should accept an upper case source": {
+			cmdLine:    "./rpc boot -source CD -password P@ssw0rd",
+			wantResult: utils.Success,
+			wantBoot:   BootFlags{Source: "cd", Once: true},
+		},
+		"should fail on usb, AMT has no USB boot source": {
+			cmdLine:    "./rpc boot -source USB -once -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail without -source": {
+			cmdLine:    "./rpc boot -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on an unknown source": {
+			cmdLine:    "./rpc boot -source floppy -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on an argument": {
+			cmdLine:    "./rpc boot -source hdd -password P@ssw0rd now",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandBoot, flags.Command)
+			if rc == utils.Success {
+				assert.True(t, flags.Local)
+				assert.Equal(t, tc.wantBoot, flags.Boot)
+			}
+		})
+	}
+
+	t.Run("should tell why usb is not supported", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "boot", "-source", "usb", "-password", "P@ssw0rd"})
+		assert.ErrorContains(t, flags.Parse(), "USB redirection")
+	})
+
+	t.Run("should prompt for the password", func(t *testing.T) {
+		defer userInput(t, "P@ssw0rd")()
+		flags := NewFlags([]string{"./rpc", "boot", "-source", "cd"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "P@ssw0rd", flags.Password)
+	})
+}
@@ -0,0 +1,255 @@
+package flags
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+// defaultBulkWorkers is the number of devices bulk works on at the same time
+const defaultBulkWorkers = 8
+
+// bulkCommands are the commands that reach a device over the network with -host
+var bulkCommands = map[string]bool{
+	utils.CommandAMTInfo:   true,
+	utils.CommandPower:     true,
+	utils.CommandConfigure: true,
+	utils.CommandWSMAN:     true,
+}
+
+type BulkFlags struct {
+	// File is the CSV or JSON device list
+	File string
+	// Command is the command line run on every device, without the executable and -host
+	Command []string
+	Workers int
+	// Report is the file the JSON results are written to, none when empty
+	Report string
+	// TLS, User, CACertFile and SkipCertCheck apply to the devices that do not set them
+	TLS           bool
+	User          string
+	CACertFile    string
+	SkipCertCheck bool
+	Devices       []BulkDevice
+}
+
+// BulkDevice is a remote AMT device of the device list with the flags of its command
+type BulkDevice struct {
+	Host     string
+	Port     int
+	TLS      bool
+	User     string
+	Password string
+	// Flags are parsed from Command with the -host and credentials of the device
+	Flags *Flags
+}
+
+// bulkEntry is a device of the list as it is read, TLS is nil when the list does not set it
+type bulkEntry struct {
+	Host     string `json:"host"`
+	Port     int    `json:"amtPort"`
+	TLS      *bool  `json:"tls"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+// handleBulkCommand reads the device list and parses the command of each device, so a
+// mistake on the command line fails before any device is changed
+func (f *Flags) handleBulkCommand() error {
+	fs := f.bulkCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTelemetryFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.StringVar(&f.Bulk.File, "file", "", "CSV or JSON file with the devices, the columns or keys are host, amtPort, tls, user and password")
+	command := fs.String("command", "", "command run on every device, ex. 'amtinfo -ver -uuid' or 'power cycle', arguments after the flags are added to it")
+	fs.IntVar(&f.Bulk.Workers, "workers", defaultBulkWorkers, "number of devices the command runs on at the same time")
+	fs.StringVar(&f.Bulk.Report, "report", "", "file the results of all devices are written to as JSON")
+	fs.BoolVar(&f.Bulk.TLS, "tls", false, "connect with TLS to the devices that do not set tls")
+	fs.StringVar(&f.Bulk.User, "user", defaultAMTUser, "digest user of the devices that do not set user")
+	fs.StringVar(&f.Bulk.CACertFile, "amtCACert", "", "PEM file with the CA certificates that verify the TLS certificates of the devices (default the system roots)")
+	fs.BoolVar(&f.Bulk.SkipCertCheck, "skipAMTCertCheck", false, "do not verify the TLS certificates of the devices")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password of the devices that do not set password")
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	f.Bulk.Command = append(strings.Fields(*command), fs.Args()...)
+	if f.Bulk.File == "" || len(f.Bulk.Command) == 0 {
+		fs.Usage()
+		return newError(utils.IncorrectCommandLineParameters, "error.bulk.fileAndCommand")
+	}
+	if !bulkCommands[f.Bulk.Command[0]] {
+		return newError(utils.IncorrectCommandLineParameters, "error.bulk.command", f.Bulk.Command[0])
+	}
+	for _, arg := range f.Bulk.Command {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch {
+		case !strings.HasPrefix(arg, "-"):
+		case name == "host" || name == "amtPort" || name == "user" || name == "password":
+			return newError(utils.IncorrectCommandLineParameters, "error.bulk.flagFromDeviceList", name)
+		}
+	}
+	if f.Bulk.Workers < 1 {
+		return newError(utils.IncorrectCommandLineParameters, "error.bulk.workers")
+	}
+	entries, err := readBulkFile(f.Bulk.File)
+	if err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.bulk.readDeviceList")
+	}
+	if len(entries) == 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.bulk.noDevices", f.Bulk.File)
+	}
+
+	// the devices are reached directly with the admin credentials
+	f.Local = true
+	readPassword := f.PasswordFile != "" || f.Password == passwordFromStdin
+	for _, entry := range entries {
+		readPassword = readPassword || (entry.Password == "" && f.Password == "")
+	}
+	if readPassword {
+		// asked once for all the devices without a password
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	f.Bulk.Devices = make([]BulkDevice, len(entries))
+	for i, entry := range entries {
+		device, err := f.newBulkDevice(entry)
+		if err != nil {
+			return err
+		}
+		f.Bulk.Devices[i] = device
+	}
+	return nil
+}
+
+// newBulkDevice parses the command of -command for the device of the entry
+func (f *Flags) newBulkDevice(entry bulkEntry) (BulkDevice, error) {
+	device := BulkDevice{Host: entry.Host, Port: entry.Port, TLS: f.Bulk.TLS, User: entry.User, Password: entry.Password}
+	if entry.TLS != nil {
+		device.TLS = *entry.TLS
+	}
+	if device.User == "" {
+		device.User = f.Bulk.User
+	}
+	if device.Password == "" {
+		device.Password = f.Password
+	}
+	args := append([]string{f.commandLineArgs[0]}, f.Bulk.Command...)
+	args = append(args, "-host", device.Host, "-user", device.User, "-password", device.Password, "-json", "-lang", f.Language)
+	if device.Port != 0 {
+		args = append(args, "-amtPort", strconv.Itoa(device.Port))
+	}
+	if device.TLS {
+		args = append(args, "-tls")
+		if f.Bulk.CACertFile != "" {
+			args = append(args, "-amtCACert", f.Bulk.CACertFile)
+		}
+		if f.Bulk.SkipCertCheck {
+			args = append(args, "-skipAMTCertCheck")
+		}
+	}
+	if f.NonInteractive {
+		args = append(args, "-nonInteractive")
+	}
+	device.Flags = NewFlags(args)
+	if err := device.Flags.Parse(); err != nil {
+		return device, wrapError(rpcerr.ReturnCodeOf(err), err, "error.bulk.invalidCommand", device.Host)
+	}
+	if device.Flags.WSMAN.XML == xmlFromStdin {
+		return device, newError(utils.IncorrectCommandLineParameters, "error.bulk.xmlFromStdin")
+	}
+	return device, nil
+}
+
+// readBulkFile reads the devices of a JSON array or of a CSV file with a header row
+func readBulkFile(path string) ([]bulkEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []bulkEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err = json.Unmarshal(content, &entries); err != nil {
+			return nil, err
+		}
+	case ".csv":
+		if entries, err = readBulkCSV(strings.NewReader(string(content))); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s is neither a .csv nor a .json file", path)
+	}
+	for i, entry := range entries {
+		if entry.Host == "" {
+			return nil, fmt.Errorf("device %d has no host", i+1)
+		}
+	}
+	return entries, nil
+}
+
+func readBulkCSV(r io.Reader) ([]bulkEntry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range header {
+		switch strings.ToLower(column) {
+		case "host", "amtport", "tls", "user", "password":
+		default:
+			return nil, fmt.Errorf("unknown column %s, the columns are host, amtPort, tls, user and password", column)
+		}
+	}
+	var entries []bulkEntry
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var entry bulkEntry
+		for i, value := range record {
+			if value == "" {
+				continue
+			}
+			switch strings.ToLower(header[i]) {
+			case "host":
+				entry.Host = value
+			case "amtport":
+				if entry.Port, err = strconv.Atoi(value); err != nil {
+					return nil, fmt.Errorf("invalid amtPort %q in row %d", value, row)
+				}
+			case "tls":
+				enabled, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid tls %q in row %d", value, row)
+				}
+				entry.TLS = &enabled
+			case "user":
+				entry.User = value
+			case "password":
+				entry.Password = value
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,80 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadBulkFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	enabled := true
+
+	entries, err := readBulkFile(write("devices.csv", "host,amtPort,tls,user,password\n# lab 1\namt1.example.com,,,,\namt2.example.com,16993,true,operator,P@ssw0rd\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []bulkEntry{
+		{Host: "amt1.example.com"},
+		{Host: "amt2.example.com", Port: 16993, TLS: &enabled, User: "operator", Password: "P@ssw0rd"},
+	}, entries)
+
+	entries, err = readBulkFile(write("devices.json", `[{"host": "amt1.example.com"}, {"host": "amt2.example.com", "amtPort": 16993, "tls": true}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []bulkEntry{{Host: "amt1.example.com"}, {Host: "amt2.example.com", Port: 16993, TLS: &enabled}}, entries)
+
+	_, err = readBulkFile(write("unknown.csv", "host,mac\namt1.example.com,00:11:22:33:44:55\n"))
+	assert.ErrorContains(t, err, "unknown column mac")
+	_, err = readBulkFile(write("port.csv", "host,amtPort\namt1.example.com,https\n"))
+	assert.ErrorContains(t, err, "row 2")
+	_, err = readBulkFile(write("nohost.json", `[{"amtPort": 16992}]`))
+	assert.ErrorContains(t, err, "no host")
+	_, err = readBulkFile(write("devices.txt", "amt1.example.com\n"))
+	assert.Error(t, err)
+}
+
+func TestHandleBulkCommand(t *testing.T) {
+	dir := t.TempDir()
+	devices := filepath.Join(dir, "devices.csv")
+	assert.NoError(t, os.WriteFile(devices, []byte("host,tls,password\namt1.example.com,,\namt2.example.com,false,Other-P@ssw0rd\n"), 0600))
+
+	f := NewFlags(strings.Fields("./rpc bulk -file " + devices + " -tls -skipAMTCertCheck -password P@ssw0rd -command power cycle"))
+	assert.Equal(t, utils.Success, f.ParseFlags())
+	assert.True(t, f.Local)
+	assert.Equal(t, []string{"power", "cycle"}, f.Bulk.Command)
+	assert.Len(t, f.Bulk.Devices, 2)
+	first := f.Bulk.Devices[0].Flags
+	assert.Equal(t, utils.CommandPower, first.Command)
+	assert.Equal(t, "amt1.example.com", first.Remote.Host)
+	assert.True(t, first.Remote.TLS && first.Remote.SkipCertCheck, "the device gets the TLS flags of bulk")
+	assert.Equal(t, "P@ssw0rd", first.Password)
+	second := f.Bulk.Devices[1].Flags
+	assert.False(t, second.Remote.TLS, "the device list overrides -tls")
+	assert.Equal(t, "Other-P@ssw0rd", second.Password)
+	assert.Contains(t, f.Secrets(), "Other-P@ssw0rd")
+
+	cases := []struct {
+		description string
+		args        string
+	}{
+		{description: "missing command", args: "-file " + devices},
+		{description: "command without -host", args: "-file " + devices + " -command activate"},
+		{description: "host in the command", args: "-file " + devices + " amtinfo -host amt3.example.com"},
+		{description: "invalid command flag", args: "-file " + devices + " amtinfo -unknown"},
+		{description: "no workers", args: "-file " + devices + " -workers 0 amtinfo"},
+		{description: "missing device list", args: "-file " + devices + ".missing amtinfo"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			f := NewFlags(strings.Fields("./rpc bulk -password P@ssw0rd " + tc.args))
+			assert.Equal(t, utils.IncorrectCommandLineParameters, f.ParseFlags())
+		})
+	}
+}
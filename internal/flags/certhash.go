@@ -0,0 +1,129 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// hash types of AMT, as numbered by utils.InterpretHashAlgorithm
+const (
+	CertHashTypeSHA1   = 1
+	CertHashTypeSHA256 = 2
+	CertHashTypeSHA512 = 3
+)
+
+// CertHashFlags hold the trusted root certificate hash configure certhash adds or deletes,
+// the hashes are only listed when neither is given
+type CertHashFlags struct {
+	// Add is the hex encoded hash to add
+	Add string
+	// HashType is the algorithm of Add
+	HashType int
+	// Alias is the name of the hash to add
+	Alias string
+	// Delete is the name or the hash of the entry to delete
+	Delete string
+}
+
+func (f *Flags) handleConfigureCertHash() error {
+	var add string
+	fs := f.flagSetCertHash
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&add, "add", "", "PEM or DER file of the root certificate, or its SHA256, SHA1 or SHA512 hash in hex, to add to the trusted root hashes")
+	fs.StringVar(&f.CertHash.Alias, "alias", "", "name of the hash of -add, the common name of the certificate by default")
+	fs.StringVar(&f.CertHash.Delete, "delete", "", "name or hex hash of the trusted root hash to delete")
+
+	// certhash takes no arguments besides its flags
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if add != "" && f.CertHash.Delete != "" {
+		return newError(utils.InvalidParameterCombination, "error.addWithDelete")
+	}
+	if add == "" {
+		if f.CertHash.Alias != "" {
+			return newError(utils.InvalidParameterCombination, "error.certhash.aliasWithoutAdd")
+		}
+		return nil
+	}
+	hash, hashType, commonName, err := parseCertHash(add)
+	if err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.certhash.invalidAdd")
+	}
+	f.CertHash.Add, f.CertHash.HashType = hash, hashType
+	f.CertHash.Alias = strings.TrimSpace(f.CertHash.Alias)
+	if f.CertHash.Alias == "" {
+		f.CertHash.Alias = commonName
+	}
+	if f.CertHash.Alias == "" {
+		return newError(utils.IncorrectCommandLineParameters, "error.certhash.aliasRequired")
+	}
+	return nil
+}
+
+// parseCertHash returns the hex hash and the hash type of value, the SHA256 hash and the
+// common name of the certificate when value is a certificate file
+func parseCertHash(value string) (string, int, string, error) {
+	data, err := os.ReadFile(value)
+	if err == nil {
+		if block, _ := pem.Decode(data); block != nil {
+			data = block.Bytes
+		}
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return "", 0, "", err
+		}
+		sum := sha256.Sum256(cert.Raw)
+		return hex.EncodeToString(sum[:]), CertHashTypeSHA256, cert.Subject.CommonName, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", 0, "", err
+	}
+	hash, err := NormalizeCertHash(value)
+	if err != nil {
+		return "", 0, "", fmt.Errorf("%q is neither a certificate file nor a hash: %w", value, err)
+	}
+	switch len(hash) / 2 {
+	case sha256.Size:
+		return hash, CertHashTypeSHA256, "", nil
+	case 20:
+		return hash, CertHashTypeSHA1, "", nil
+	case 64:
+		return hash, CertHashTypeSHA512, "", nil
+	}
+	return "", 0, "", fmt.Errorf("a hash of %d bytes is neither SHA1, SHA256 nor SHA512", len(hash)/2)
+}
+
+// NormalizeCertHash returns the hash in lowercase hex, colons and spaces between the bytes are removed
+func NormalizeCertHash(value string) (string, error) {
+	hash := strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(value)))
+	if _, err := hex.DecodeString(hash); err != nil || hash == "" {
+		return "", errors.New("not a hex encoded hash")
+	}
+	return hash, nil
+}
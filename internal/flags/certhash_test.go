@@ -0,0 +1,86 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleConfigureCertHash(t *testing.T) {
+	certPath := writeTestCertificate(t)
+	content, err := os.ReadFile(certPath)
+	assert.NoError(t, err)
+	block, _ := pem.Decode(content)
+	sum := sha256.Sum256(block.Bytes)
+	certHash := hex.EncodeToString(sum[:])
+	sha1Hash := strings.Repeat("ab", 20)
+
+	cases := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantFlags  CertHashFlags
+	}{
+		"lists the hashes": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -json",
+			wantResult: utils.Success,
+		},
+		"adds the SHA256 hash of a certificate named by its common name": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add " + certPath,
+			wantResult: utils.Success,
+			wantFlags:  CertHashFlags{Add: certHash, HashType: CertHashTypeSHA256, Alias: "device"},
+		},
+		"adds the hash of a certificate with an alias": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add " + certPath + " -alias CorpRoot",
+			wantResult: utils.Success,
+			wantFlags:  CertHashFlags{Add: certHash, HashType: CertHashTypeSHA256, Alias: "CorpRoot"},
+		},
+		"adds a hex hash with colons": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add " + strings.ToUpper(strings.Repeat("ab:", 19)) + "AB -alias Legacy",
+			wantResult: utils.Success,
+			wantFlags:  CertHashFlags{Add: sha1Hash, HashType: CertHashTypeSHA1, Alias: "Legacy"},
+		},
+		"a hash needs an alias": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add " + certHash,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"a hash of an unknown length fails": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add abcdef -alias Short",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"deletes a hash": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -delete CorpRoot",
+			wantResult: utils.Success,
+			wantFlags:  CertHashFlags{Delete: "CorpRoot"},
+		},
+		"-add cannot be used with -delete": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -add " + certPath + " -delete CorpRoot",
+			wantResult: utils.InvalidParameterCombination,
+			wantFlags:  CertHashFlags{Delete: "CorpRoot"},
+		},
+		"-alias requires -add": {
+			cmdLine:    "rpc configure certhash -password Passw0rd! -alias CorpRoot",
+			wantResult: utils.InvalidParameterCombination,
+			wantFlags:  CertHashFlags{Alias: "CorpRoot"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			assert.Equal(t, tc.wantResult, rpcerr.ReturnCodeOf(flags.handleConfigureCertHash()))
+			if tc.wantResult == utils.Success || tc.wantFlags != (CertHashFlags{}) {
+				assert.Equal(t, tc.wantFlags, flags.CertHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+)
+
+// handleCheckCertCommand reads the provisioning certificate checkcert verifies against the
+// trusted root certificate hashes of AMT, the same way as activate -local -acm
+func (f *Flags) handleCheckCertCommand() error {
+	fs := f.checkCertCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.configContent, "config", "", "specify a config file or smb: file share URL with the acmactivate settings")
+	fs.StringVar(&f.LocalConfig.ACMSettings.ProvisioningCert, "provisioningCert", f.lookupEnvOrString("PROVISIONING_CERT", ""), "provisioning certificate, base64 encoded or the path to a .pfx file")
+	fs.StringVar(&f.LocalConfig.ACMSettings.ProvisioningCertPwd, "provisioningCertPwd", f.lookupEnvOrString("PROVISIONING_CERT_PASSWORD", ""), "provisioning certificate password")
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if rc := f.handleLocalConfig(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	if rc := f.loadProvisioningCert(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	if f.LocalConfig.ACMSettings.ProvisioningCert == "" {
+		fs.Usage()
+		return newError(utils.MissingOrInvalidConfiguration, "error.checkcert.certRequired")
+	}
+	// reads the certificate hashes through the MEI
+	f.Local = true
+	return nil
+}
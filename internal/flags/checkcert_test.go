@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/internal/certtest"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleCheckCertCommand(t *testing.T) {
+	testCerts := certtest.New("P@ssw0rd")
+
+	t.Run("reads a .pfx file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "provisioning.pfx")
+		assert.NoError(t, os.WriteFile(path, testCerts.PfxData, 0600))
+		f := NewFlags([]string{"./rpc", "checkcert", "-provisioningCert", path, "-provisioningCertPwd", testCerts.PfxPassword, "-json"})
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.True(t, f.Local)
+		assert.Equal(t, testCerts.Pfxb64, f.LocalConfig.ACMSettings.ProvisioningCert)
+	})
+	t.Run("requires the provisioning certificate", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "checkcert"})
+		assert.Equal(t, utils.MissingOrInvalidConfiguration, f.ParseFlags())
+	})
+	t.Run("fails on a missing .pfx file", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "checkcert", "-provisioningCert", filepath.Join(t.TempDir(), "missing.pfx")})
+		assert.Equal(t, utils.FailedReadingConfiguration, f.ParseFlags())
+	})
+	t.Run("fails on extra arguments", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "checkcert", "now"})
+		assert.Equal(t, utils.IncorrectCommandLineParameters, f.ParseFlags())
+	})
+}
@@ -1,62 +1,81 @@
 package flags
 
 import (
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"rpc/internal/config"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"strings"
+	"time"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
 
 	"github.com/ilyakaznacheev/cleanenv"
-	log "github.com/sirupsen/logrus"
 )
 
 func (f *Flags) printConfigurationUsage() string {
-	executable := filepath.Base(os.Args[0])
-	usage := "\nRemote Provisioning Client (RPC) - used for activation, deactivation, maintenance and status of AMT\n\n"
-	usage = usage + "Usage: " + executable + " configure COMMAND [OPTIONS]\n\n"
-	usage = usage + "Supported Configuration Commands:\n"
-	usage = usage + "  addwifisettings Add or modify WiFi settings in AMT. AMT password is required. A config.yml or command line flags must be provided for all settings. This command runs without cloud interaction.\n"
-	usage = usage + "                 Example: " + executable + " configure addwifisettings -password YourAMTPassword -config wificonfig.yaml\n"
-	usage = usage + "  enablewifiport  Enables WiFi port and local profile synchronization settings in AMT. AMT password is required.\n"
-	usage = usage + "                 Example: " + executable + " configure enablewifiport -password YourAMTPassword\n"
-	usage = usage + "\nRun '" + executable + " configure COMMAND -h' for more information on a command.\n"
-	fmt.Println(usage)
+	usage := configureUsage.text()
+	f.printText(usage)
 	return usage
 }
 
-func (f *Flags) handleConfigureCommand() utils.ReturnCode {
+func (f *Flags) handleConfigureCommand() error {
 	if len(f.commandLineArgs) == 2 {
 		f.printConfigurationUsage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
 	}
 
-	var rc = utils.Success
+	var err error
 
 	f.SubCommand = f.commandLineArgs[2]
 	switch f.SubCommand {
 	case "addwifisettings":
-		rc = f.handleAddWifiSettings()
+		err = f.handleAddWifiSettings()
 	case "enablewifiport":
-		rc = f.handleEnableWifiPort()
+		err = f.handleEnableWifiPort()
+	case utils.SubCommandConfigureTLS:
+		err = f.handleConfigureTLS()
+	case utils.SubCommandConfigureCIRA, utils.SubCommandConfigureMPS:
+		// mps is the name enterprise consoles use, both configure CIRA the same way
+		f.SubCommand = utils.SubCommandConfigureCIRA
+		err = f.handleConfigureCIRA()
+	case utils.SubCommandWired8021x:
+		err = f.handleConfigureWired8021x()
+	case utils.SubCommandAlarmClock:
+		err = f.handleConfigureAlarmClock()
+	case utils.SubCommandRedirection:
+		err = f.handleConfigureRedirection()
+	case utils.SubCommandDNSSuffix:
+		err = f.handleConfigureDNSSuffix()
+	case utils.SubCommandAMTFeatures:
+		err = f.handleConfigureAMTFeatures()
+	case utils.SubCommandCertHash:
+		err = f.handleConfigureCertHash()
 	default:
 		f.printConfigurationUsage()
-		rc = utils.IncorrectCommandLineParameters
+		err = rpcerr.New(utils.IncorrectCommandLineParameters, "")
 	}
-	if rc != utils.Success {
-		return rc
+	if err != nil {
+		return err
 	}
 
 	f.Local = true
+	// the PKI DNS suffix and the AMT state are set over the MEI, which needs no AMT password
+	if f.SubCommand == utils.SubCommandDNSSuffix || f.SubCommand == utils.SubCommandAMTFeatures {
+		return nil
+	}
 	if f.Password == "" {
 		if f.LocalConfig.Password != "" {
 			f.Password = f.LocalConfig.Password
 		} else {
-			if _, rc = f.ReadPasswordFromUser(); rc != utils.Success {
-				return utils.MissingOrIncorrectPassword
+			if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+				return rpcerr.New(utils.MissingOrIncorrectPassword, "")
 			}
 			f.LocalConfig.Password = f.Password
 		}
@@ -64,46 +83,641 @@ func (f *Flags) handleConfigureCommand() utils.ReturnCode {
 		if f.LocalConfig.Password == "" {
 			f.LocalConfig.Password = f.Password
 		} else if f.LocalConfig.Password != f.Password {
-			log.Error("password does not match config file password")
-			return utils.MissingOrIncorrectPassword
+			return newError(utils.MissingOrIncorrectPassword, "error.configPasswordMismatch")
 		}
 	}
-	return utils.Success
+	return nil
 }
 
-func (f *Flags) handleEnableWifiPort() utils.ReturnCode {
+func (f *Flags) handleEnableWifiPort() error {
 	var err error
-	// var rc utils.ReturnCode
-	if len(f.commandLineArgs) > 5 {
-		f.printConfigurationUsage()
-		return utils.IncorrectCommandLineParameters
-	}
 	f.flagSetEnableWifiPort.BoolVar(&f.Verbose, "v", false, "Verbose output")
-	f.flagSetEnableWifiPort.StringVar(&f.LogLevel, "l", "info", "Log level (panic,fatal,error,warn,info,debug,trace)")
+	f.setupLogFlags(f.flagSetEnableWifiPort)
+	f.setupTimeoutFlag(f.flagSetEnableWifiPort)
+	f.setupTelemetryFlag(f.flagSetEnableWifiPort)
+	f.setupRemoteFlags(f.flagSetEnableWifiPort)
 	f.flagSetEnableWifiPort.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	f.flagSetEnableWifiPort.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
 	f.flagSetEnableWifiPort.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	f.flagSetEnableWifiPort.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	f.flagSetEnableWifiPort.String(defaultsFlag, "", defaultsUsage)
+	f.flagSetEnableWifiPort.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	f.flagSetEnableWifiPort.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	f.flagSetEnableWifiPort.BoolVar(&f.WifiPort.Disable, "disable", false, "disable the WiFi port and local profile synchronization")
+	f.flagSetEnableWifiPort.StringVar(&f.WifiPort.LinkPreference, "linkPreference", "", "WiFi link preference: me or host. Leave empty to keep the current preference")
+	f.flagSetEnableWifiPort.IntVar(&f.WifiPort.LinkPreferenceTimeout, "linkPreferenceTimeout", 60, "seconds AMT keeps the WiFi link with -linkPreference me before returning it to the host")
 
-	if err = f.flagSetEnableWifiPort.Parse(f.commandLineArgs[3:]); err != nil {
+	// enablewifiport takes no arguments besides its flags
+	if err = f.parseWithDefaults(f.flagSetEnableWifiPort, f.commandLineArgs[3:]); err != nil || f.flagSetEnableWifiPort.NArg() > 0 {
 		f.printConfigurationUsage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
-	return utils.Success
+	switch f.WifiPort.LinkPreference {
+	case "", LinkPreferenceME, LinkPreferenceHost:
+	default:
+		return newError(utils.IncorrectCommandLineParameters, "error.enablewifiport.linkPreference", LinkPreferenceME, LinkPreferenceHost)
+	}
+	if f.WifiPort.Disable && f.WifiPort.LinkPreference != "" {
+		return newError(utils.InvalidParameterCombination, "error.enablewifiport.linkPreferenceWithDisable")
+	}
+	if f.WifiPort.LinkPreferenceTimeout < 1 || f.WifiPort.LinkPreferenceTimeout > 65535 {
+		return newError(utils.IncorrectCommandLineParameters, "error.enablewifiport.linkPreferenceTimeout")
+	}
+	return nil
+}
+
+const (
+	LinkPreferenceME   = "me"
+	LinkPreferenceHost = "host"
+)
+
+// WifiPortFlags select the state of the AMT WiFi port set by configure enablewifiport
+type WifiPortFlags struct {
+	Disable bool
+	// LinkPreference gives the WiFi link to AMT (me) or the host OS (host), unchanged when empty
+	LinkPreference        string
+	LinkPreferenceTimeout int
+}
+
+// TLSMode selects server or mutual authentication and whether non-TLS connections are still accepted
+type TLSMode int
+
+const (
+	TLSModeServer TLSMode = iota + 1
+	TLSModeServerAndNonTLS
+	TLSModeMutual
+	TLSModeMutualAndNonTLS
+)
+
+var tlsModeNames = []string{"Server", "ServerAndNonTLS", "Mutual", "MutualAndNonTLS"}
+
+func ParseTLSMode(s string) (TLSMode, error) {
+	for i, name := range tlsModeNames {
+		if strings.EqualFold(s, name) {
+			return TLSMode(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid TLS mode %s, expected one of %s", s, strings.Join(tlsModeNames, ", "))
+}
+
+func (m TLSMode) String() string {
+	if m < TLSModeServer || m > TLSModeMutualAndNonTLS {
+		return fmt.Sprintf("TLSMode(%d)", int(m))
+	}
+	return tlsModeNames[m-1]
+}
+
+func (m TLSMode) IsMutual() bool {
+	return m == TLSModeMutual || m == TLSModeMutualAndNonTLS
+}
+
+func (m TLSMode) AllowsNonTLS() bool {
+	return m == TLSModeServerAndNonTLS || m == TLSModeMutualAndNonTLS
+}
+
+type TLSSettingsFlags struct {
+	Mode TLSMode
+	// CSRFile receives the CSR for the key pair generated in AMT, stdout when empty
+	CSRFile string
+	// Cert and CACert are base64 encoded DER certificates read from the -cert and -caCert files
+	Cert       string
+	CACert     string
+	CommonName string
+	TrustedCN  string
+	Local      bool
+}
+
+func (f *Flags) handleConfigureTLS() error {
+	if len(f.commandLineArgs) == 3 {
+		f.printConfigurationUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	var certFile, caCertFile string
+	f.TLSSettings.Mode = TLSModeServer
+	f.flagSetTLSSettings.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(f.flagSetTLSSettings)
+	f.setupTimeoutFlag(f.flagSetTLSSettings)
+	f.setupTelemetryFlag(f.flagSetTLSSettings)
+	f.setupRemoteFlags(f.flagSetTLSSettings)
+	f.flagSetTLSSettings.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	f.flagSetTLSSettings.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	f.flagSetTLSSettings.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	f.flagSetTLSSettings.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	f.flagSetTLSSettings.String(defaultsFlag, "", defaultsUsage)
+	f.flagSetTLSSettings.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	f.flagSetTLSSettings.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	f.flagSetTLSSettings.Func("mode", "TLS authentication mode: "+strings.Join(tlsModeNames, ", ")+" (default Server)", func(flagValue string) error {
+		mode, err := ParseTLSMode(flagValue)
+		f.TLSSettings.Mode = mode
+		return err
+	})
+	f.flagSetTLSSettings.StringVar(&f.TLSSettings.CSRFile, "csr", "", "file to write the certificate signing request for the key pair generated in AMT")
+	f.flagSetTLSSettings.StringVar(&f.TLSSettings.CommonName, "commonName", "", "common name of the certificate signing request (default OS hostname)")
+	f.flagSetTLSSettings.StringVar(&certFile, "cert", "", "signed TLS certificate (PEM or DER) for the key pair generated in AMT")
+	f.flagSetTLSSettings.StringVar(&caCertFile, "caCert", "", "CA certificate (PEM or DER) trusted for client certificates in mutual authentication")
+	f.flagSetTLSSettings.StringVar(&f.TLSSettings.TrustedCN, "trustedCN", "", "common name required in client certificates in mutual authentication")
+	f.flagSetTLSSettings.BoolVar(&f.TLSSettings.Local, "local", false, "also enable TLS on the local (LMS) interface, rpc then reaches AMT with -transport lms-tls")
+
+	if err := f.parseWithDefaults(f.flagSetTLSSettings, f.commandLineArgs[3:]); err != nil {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if certFile != "" && f.TLSSettings.CSRFile != "" {
+		return newError(utils.InvalidParameterCombination, "error.tls.csrOrCert")
+	}
+	if !f.TLSSettings.Mode.IsMutual() && (caCertFile != "" || f.TLSSettings.TrustedCN != "") {
+		return newError(utils.InvalidParameterCombination, "error.tls.caCertWithoutMutual")
+	}
+	if certFile == "" {
+		return nil
+	}
+	if f.TLSSettings.Mode.IsMutual() && caCertFile == "" {
+		return newError(utils.IncorrectCommandLineParameters, "error.tls.mutualWithoutCACert")
+	}
+	var err error
+	if f.TLSSettings.Cert, err = readCertificateFile(certFile); err != nil {
+		return wrapError(utils.FailedReadingConfiguration, err, "error.readCert")
+	}
+	if caCertFile != "" {
+		if f.TLSSettings.CACert, err = readCertificateFile(caCertFile); err != nil {
+			return wrapError(utils.FailedReadingConfiguration, err, "error.readCACert")
+		}
+	}
+	return nil
+}
+
+// CIRASettingsFlags describe the MPS servers AMT connects to and when it connects
+type CIRASettingsFlags struct {
+	MPSAddress  string
+	MPSPort     int
+	MPSUser     string
+	MPSPassword string
+	// MPSRootCert is the base64 encoded DER root certificate of the MPS read from the -mpscert file
+	MPSRootCert string
+	// MPSCommonName is the common name AMT expects in the MPS certificate, the address when empty
+	MPSCommonName string
+	// SecondaryAddress is the MPS AMT falls back to when the primary can not be reached, none
+	// when empty. It shares the user, password and root certificate of the primary.
+	SecondaryAddress    string
+	SecondaryPort       int
+	SecondaryCommonName string
+	// EnvironmentDetection lists the intranet domains where AMT does not connect to the MPS
+	EnvironmentDetection []string
+	// PeriodicInterval is the seconds between periodic connections, 0 leaves out the periodic trigger
+	PeriodicInterval int
+	// Force replaces a configuration that already matches, the MPS password can not be read
+	// from AMT so a changed password is only applied with it
+	Force bool
+}
+
+// setSecondaryDefaults fills the port and common name of the secondary MPS from the
+// primary and its address, and returns an error when both are the same server
+func (cira *CIRASettingsFlags) setSecondaryDefaults() error {
+	if cira.SecondaryAddress == "" {
+		return nil
+	}
+	if cira.SecondaryPort == 0 {
+		cira.SecondaryPort = cira.MPSPort
+	}
+	if cira.SecondaryCommonName == "" {
+		cira.SecondaryCommonName = cira.SecondaryAddress
+	}
+	if cira.SecondaryPort < 1 || cira.SecondaryPort > 65535 {
+		return errors.New("the port of the secondary MPS must be between 1 and 65535")
+	}
+	if strings.EqualFold(cira.SecondaryAddress, cira.MPSAddress) && cira.SecondaryPort == cira.MPSPort {
+		return errors.New("the secondary MPS must not be the primary MPS")
+	}
+	return nil
 }
 
-func (f *Flags) handleAddWifiSettings() utils.ReturnCode {
+// addEnvironmentDetection appends the domains without blanks and duplicates, domain names
+// are not case sensitive
+func (cira *CIRASettingsFlags) addEnvironmentDetection(domains []string) {
+next:
+	for _, domain := range domains {
+		if domain = strings.TrimSpace(domain); domain == "" {
+			continue
+		}
+		for _, added := range cira.EnvironmentDetection {
+			if strings.EqualFold(added, domain) {
+				continue next
+			}
+		}
+		cira.EnvironmentDetection = append(cira.EnvironmentDetection, domain)
+	}
+}
+
+func (f *Flags) handleConfigureCIRA() error {
+	if len(f.commandLineArgs) == 3 {
+		f.printConfigurationUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	var certFile, envDetection string
+	f.flagSetCIRASettings.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(f.flagSetCIRASettings)
+	f.setupTimeoutFlag(f.flagSetCIRASettings)
+	f.setupTelemetryFlag(f.flagSetCIRASettings)
+	f.setupRemoteFlags(f.flagSetCIRASettings)
+	f.flagSetCIRASettings.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	f.flagSetCIRASettings.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	f.flagSetCIRASettings.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	f.flagSetCIRASettings.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	f.flagSetCIRASettings.String(defaultsFlag, "", defaultsUsage)
+	f.flagSetCIRASettings.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	f.flagSetCIRASettings.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.MPSAddress, "mpsaddress", "", "hostname or IP address of the MPS")
+	f.flagSetCIRASettings.IntVar(&f.CIRASettings.MPSPort, "mpsport", 4433, "port of the MPS")
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.MPSUser, "mpsuser", "", "username AMT uses to authenticate with the MPS")
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.MPSPassword, "mpspassword", f.lookupEnvOrString("MPS_PASSWORD", ""), "password AMT uses to authenticate with the MPS")
+	f.flagSetCIRASettings.StringVar(&certFile, "mpscert", "", "root certificate (PEM or DER) of the MPS server certificate")
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.MPSCommonName, "mpscn", "", "common name of the MPS server certificate (default -mpsaddress)")
+	f.flagSetCIRASettings.StringVar(&envDetection, "envdetection", "", "comma separated intranet domains where AMT does not connect to the MPS")
+	f.flagSetCIRASettings.IntVar(&f.CIRASettings.PeriodicInterval, "periodic", 60, "seconds between periodic connections to the MPS, 0 to connect only on user request and alerts")
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.SecondaryAddress, "secondary", "", "hostname or IP address of the MPS AMT falls back to when the primary can not be reached")
+	f.flagSetCIRASettings.IntVar(&f.CIRASettings.SecondaryPort, "secondaryport", 0, "port of the secondary MPS (default -mpsport)")
+	f.flagSetCIRASettings.StringVar(&f.CIRASettings.SecondaryCommonName, "secondarycn", "", "common name of the secondary MPS server certificate (default -secondary)")
+	f.flagSetCIRASettings.BoolVar(&f.CIRASettings.Force, "force", false, "replace the CIRA configuration even when it already matches, needed to change the MPS password")
+
+	if err := f.parseWithDefaults(f.flagSetCIRASettings, f.commandLineArgs[3:]); err != nil || f.flagSetCIRASettings.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	cira := &f.CIRASettings
+	if cira.MPSAddress == "" || cira.MPSUser == "" || cira.MPSPassword == "" || certFile == "" {
+		return newError(utils.IncorrectCommandLineParameters, "error.cira.required")
+	}
+	if cira.MPSPort < 1 || cira.MPSPort > 65535 {
+		return newError(utils.IncorrectCommandLineParameters, "error.cira.mpsport")
+	}
+	if cira.PeriodicInterval < 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.cira.negativePeriodic")
+	}
+	if cira.MPSCommonName == "" {
+		cira.MPSCommonName = cira.MPSAddress
+	}
+	if err := cira.setSecondaryDefaults(); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	cira.addEnvironmentDetection(strings.Split(envDetection, ","))
+	var err error
+	if cira.MPSRootCert, err = readCertificateFile(certFile); err != nil {
+		return wrapError(utils.MissingOrIncorrectCACert, err, "error.readMPSCert")
+	}
+	return nil
+}
+
+// Wired8021xFlags select the IEEE 802.1x configuration of the wired interface
+type Wired8021xFlags struct {
+	// ProfileName is the ieee8021xConfigs entry that is configured
+	ProfileName string
+	// PxeTimeout is the seconds AMT allows a PXE boot without 802.1x authentication, 0 disables PXE boot
+	PxeTimeout int
+	Disable    bool
+}
+
+func (f *Flags) handleConfigureWired8021x() error {
+	if len(f.commandLineArgs) == 3 {
+		f.printConfigurationUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	var secretsFilePath string
+	fs := f.flagSetWired8021x
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&f.configContent, "config", "", "specify a config file or smb: file share URL with ieee8021xConfigs")
+	fs.StringVar(&secretsFilePath, "secrets", "", "specify a secrets file ")
+	fs.StringVar(&f.Wired8021x.ProfileName, "ieee8021xProfileName", "", "ieee8021xConfigs entry of the config file to configure")
+	fs.IntVar(&f.Wired8021x.PxeTimeout, "pxeTimeout", 120, "seconds AMT allows a PXE boot before 802.1x authentication, 0 disables PXE boot")
+	fs.BoolVar(&f.Wired8021x.Disable, "disable", false, "disable 802.1x on the wired interface")
+	// Params for entering the 802.1x config from command line
+	ieee8021xCfg := config.Ieee8021xConfig{}
+	fs.StringVar(&ieee8021xCfg.Username, "username", "", "specify username")
+	fs.StringVar(&ieee8021xCfg.Password, "ieee8021xPassword", f.lookupEnvOrString("IEE8021X_PASSWORD", ""), "8021x password if authenticationProtocol is PEAPv0/EAP-MSCHAPv2(2)")
+	fs.IntVar(&ieee8021xCfg.AuthenticationProtocol, "authenticationProtocol", 0, "specify authentication protocol")
+	fs.StringVar(&ieee8021xCfg.ClientCert, "clientCert", "", "specify client certificate")
+	fs.StringVar(&ieee8021xCfg.CACert, "caCert", "", "specify CA certificate of the RADIUS server")
+	fs.StringVar(&ieee8021xCfg.PrivateKey, "privateKey", f.lookupEnvOrString("IEE8021X_PRIVATE_KEY", ""), "specify private key")
+
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if f.Wired8021x.PxeTimeout < 0 || f.Wired8021x.PxeTimeout > 86400 {
+		return newError(utils.IncorrectCommandLineParameters, "error.wired8021x.pxeTimeout")
+	}
+	if f.Wired8021x.Disable {
+		if f.configContent != "" || f.Wired8021x.ProfileName != "" || ieee8021xCfg.Username != "" {
+			return newError(utils.InvalidParameterCombination, "error.wired8021x.disableWithConfig")
+		}
+		return nil
+	}
+
+	// a configuration entered on the command line is used when no profile of a config file is named
+	if f.Wired8021x.ProfileName == "" {
+		if ieee8021xCfg.Username == "" {
+			f.printConfigurationUsage()
+			return newError(utils.MissingOrInvalidConfiguration, "error.wired8021x.profileRequired")
+		}
+		f.Wired8021x.ProfileName = "wired"
+		ieee8021xCfg.ProfileName = f.Wired8021x.ProfileName
+		f.LocalConfig.Ieee8021xConfigs = append(f.LocalConfig.Ieee8021xConfigs, ieee8021xCfg)
+	}
+	if rc := f.handleLocalConfig(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	if secretsFilePath != "" {
+		var secretConfig config.SecretConfig
+		if err := cleanenv.ReadConfig(secretsFilePath, &secretConfig); err != nil {
+			return wrapError(utils.FailedReadingConfiguration, err, "error.readSecretsFile")
+		}
+		if rc := f.mergeWifiSecrets(secretConfig); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	if rc := f.promptForSecrets(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	return rpcerr.FromReturnCode(f.verifyMatchingIeee8021xConfig(f.Wired8021x.ProfileName))
+}
+
+// AlarmClockFlags select the operation of configure alarmclock, the alarms are listed
+// when neither Add nor Delete is given
+type AlarmClockFlags struct {
+	// Add is the name of the alarm to add
+	Add string
+	// Delete is the name of the alarm to delete
+	Delete string
+	// Start is the first time the alarm wakes the device
+	Start time.Time
+	// Interval repeats the alarm, 0 wakes the device once
+	Interval           time.Duration
+	DeleteOnCompletion bool
+}
+
+func (f *Flags) handleConfigureAlarmClock() error {
+	var start string
+	fs := f.flagSetAlarmClock
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&f.AlarmClock.Add, "add", "", "name of the wake alarm to add")
+	fs.StringVar(&f.AlarmClock.Delete, "delete", "", "name of the wake alarm to delete")
+	fs.StringVar(&start, "start", "", "first wake time of -add, HH:MM of the local clock for the next occurrence or RFC3339")
+	fs.DurationVar(&f.AlarmClock.Interval, "interval", 0, "time between wake ups of -add in whole minutes, ex. 24h, 0 wakes once")
+	fs.BoolVar(&f.AlarmClock.DeleteOnCompletion, "deleteOnCompletion", false, "AMT deletes the alarm of -add once it is completed")
+
+	// alarmclock takes no arguments besides its flags
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	alarm := &f.AlarmClock
+	if alarm.Add != "" && alarm.Delete != "" {
+		return newError(utils.InvalidParameterCombination, "error.addWithDelete")
+	}
+	if alarm.Add == "" {
+		if start != "" || alarm.Interval != 0 || alarm.DeleteOnCompletion {
+			return newError(utils.InvalidParameterCombination, "error.alarmclock.addRequired")
+		}
+		return nil
+	}
+	if start == "" {
+		return newError(utils.IncorrectCommandLineParameters, "error.alarmclock.startRequired")
+	}
+	if alarm.Interval < 0 || alarm.Interval%time.Minute != 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.alarmclock.interval")
+	}
+	var err error
+	if alarm.Start, err = parseAlarmStart(start, time.Now()); err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.alarmclock.invalidStart")
+	}
+	return nil
+}
+
+// parseAlarmStart returns the next time after now at HH:MM of the local clock, or the
+// RFC3339 time when it is in the future
+func parseAlarmStart(value string, now time.Time) (time.Time, error) {
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		start := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !start.After(now) {
+			start = start.AddDate(0, 0, 1)
+		}
+		return start, nil
+	}
+	start, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither HH:MM nor RFC3339", value)
+	}
+	if !start.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", value)
+	}
+	return start, nil
+}
+
+const (
+	RedirectionKVM  = "kvm"
+	RedirectionSOL  = "sol"
+	RedirectionIDER = "ider"
+)
+
+// RedirectionFlags select the redirection features configure redirection enables and
+// disables, features that are not named keep their state
+type RedirectionFlags struct {
+	Enable  []string
+	Disable []string
+}
+
+func (f *Flags) handleConfigureRedirection() error {
+	var enable, disable string
+	fs := f.flagSetRedirection
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&enable, "enable", "", "comma separated redirection features to enable: kvm, sol, ider")
+	fs.StringVar(&disable, "disable", "", "comma separated redirection features to disable: kvm, sol, ider")
+
+	// redirection takes no arguments besides its flags
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	var err error
+	if f.Redirection.Enable, err = parseRedirectionFeatures(enable); err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.redirection.invalidEnable")
+	}
+	if f.Redirection.Disable, err = parseRedirectionFeatures(disable); err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.redirection.invalidDisable")
+	}
+	if len(f.Redirection.Enable) == 0 && len(f.Redirection.Disable) == 0 {
+		f.printConfigurationUsage()
+		return newError(utils.IncorrectCommandLineParameters, "error.redirection.enableOrDisable")
+	}
+	for _, feature := range f.Redirection.Enable {
+		for _, disabled := range f.Redirection.Disable {
+			if feature == disabled {
+				return newError(utils.InvalidParameterCombination, "error.enabledAndDisabled", feature)
+			}
+		}
+	}
+	return nil
+}
+
+// maxDNSSuffixLength is the longest domain name DNS allows
+const maxDNSSuffixLength = 253
+
+// DNSSuffixFlags hold the PKI DNS suffix set by configure dnssuffix
+type DNSSuffixFlags struct {
+	Value string
+}
+
+func (f *Flags) handleConfigureDNSSuffix() error {
+	fs := f.flagSetDNSSuffix
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.StringVar(&f.DNSSuffix.Value, "value", "", "PKI DNS suffix matched against the domain of the provisioning certificate, e.g. corp.example.com")
+
+	// dnssuffix takes no arguments besides its flags
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	f.DNSSuffix.Value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(f.DNSSuffix.Value), "."), ".")
+	if f.DNSSuffix.Value == "" {
+		f.printConfigurationUsage()
+		return newError(utils.IncorrectCommandLineParameters, "error.dnssuffix.valueRequired")
+	}
+	if len(f.DNSSuffix.Value) > maxDNSSuffixLength {
+		return newError(utils.IncorrectCommandLineParameters, "error.dnssuffix.tooLong", maxDNSSuffixLength)
+	}
+	if err := validateHostname(f.DNSSuffix.Value); err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.dnssuffix.invalidValue")
+	}
+	return nil
+}
+
+const (
+	AMTStateEnable  = "enable"
+	AMTStateDisable = "disable"
+)
+
+// AMTFeaturesFlags hold the AMT state configure amtfeatures changes to, the state is only
+// shown when AMT is empty
+type AMTFeaturesFlags struct {
+	AMT string
+}
+
+func (f *Flags) handleConfigureAMTFeatures() error {
+	fs := f.flagSetAMTFeatures
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	fs.String(defaultsFlag, "", defaultsUsage)
+	fs.StringVar(&f.AMTFeatures.AMT, "amt", "", "enable or disable AMT, the BIOS must allow the change from the OS. The state is shown without -amt")
+
+	// amtfeatures takes no arguments besides its flags
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+		f.printConfigurationUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	f.AMTFeatures.AMT = strings.ToLower(strings.TrimSpace(f.AMTFeatures.AMT))
+	switch f.AMTFeatures.AMT {
+	case "", AMTStateEnable, AMTStateDisable:
+	default:
+		return newError(utils.IncorrectCommandLineParameters, "error.amtfeatures.invalidAMT", f.AMTFeatures.AMT, AMTStateEnable, AMTStateDisable)
+	}
+	return nil
+}
+
+// parseRedirectionFeatures splits a comma separated list of redirection features
+func parseRedirectionFeatures(value string) ([]string, error) {
+	var features []string
+	for _, feature := range strings.Split(value, ",") {
+		feature = strings.ToLower(strings.TrimSpace(feature))
+		switch feature {
+		case "":
+		case RedirectionKVM, RedirectionSOL, RedirectionIDER:
+			features = append(features, feature)
+		default:
+			return nil, fmt.Errorf("%q is not a redirection feature, use %s, %s or %s", feature, RedirectionKVM, RedirectionSOL, RedirectionIDER)
+		}
+	}
+	return features, nil
+}
+
+// readCertificateFile reads a PEM or DER certificate and returns it base64 encoded DER as AMT expects
+func readCertificateFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	if _, err := x509.ParseCertificate(data); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (f *Flags) handleAddWifiSettings() error {
 	var err error
 	var rc utils.ReturnCode
 	var secretsFilePath string
 	if len(f.commandLineArgs) == 3 {
 		f.printConfigurationUsage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
 	}
 	var wifiSecretConfig config.SecretConfig
 	var configJson string
 	f.flagSetAddWifiSettings.BoolVar(&f.Verbose, "v", false, "Verbose output")
-	f.flagSetAddWifiSettings.StringVar(&f.LogLevel, "l", "info", "Log level (panic,fatal,error,warn,info,debug,trace)")
+	f.setupLogFlags(f.flagSetAddWifiSettings)
+	f.setupTimeoutFlag(f.flagSetAddWifiSettings)
+	f.setupTelemetryFlag(f.flagSetAddWifiSettings)
+	f.setupRemoteFlags(f.flagSetAddWifiSettings)
 	f.flagSetAddWifiSettings.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	f.flagSetAddWifiSettings.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
 	f.flagSetAddWifiSettings.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	f.flagSetAddWifiSettings.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	f.flagSetAddWifiSettings.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	f.flagSetAddWifiSettings.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
 	f.flagSetAddWifiSettings.StringVar(&f.configContent, "config", "", "specify a config file or smb: file share URL")
 	f.flagSetAddWifiSettings.StringVar(&configJson, "configJson", "", "configuration as a JSON string")
 	f.flagSetAddWifiSettings.StringVar(&secretsFilePath, "secrets", "", "specify a secrets file ")
@@ -125,11 +739,13 @@ func (f *Flags) handleAddWifiSettings() utils.ReturnCode {
 
 	// rpc configure addwifisettings -configstring "{ prop: val, prop2: val }"
 	// rpc configure add -config "filename" -secrets "someotherfile"
-	if err = f.flagSetAddWifiSettings.Parse(f.commandLineArgs[3:]); err != nil {
+	if err = f.parseWithDefaults(f.flagSetAddWifiSettings, f.commandLineArgs[3:]); err != nil {
 		f.printConfigurationUsage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
 
+	// a profile entered on the command line is only added when it is named,
+	// otherwise an empty entry would fail verification of a config file
 	if wifiCfg.ProfileName != "" {
 		authMethod := models.AuthenticationMethod(wifiCfg.AuthenticationMethod)
 		if authMethod == models.AuthenticationMethod_WPA_IEEE8021x ||
@@ -137,53 +753,50 @@ func (f *Flags) handleAddWifiSettings() utils.ReturnCode {
 			// reuse profilename as configuration reference
 			wifiCfg.Ieee8021xProfileName = wifiCfg.ProfileName
 			ieee8021xCfg.ProfileName = wifiCfg.ProfileName
+			f.LocalConfig.Ieee8021xConfigs = append(f.LocalConfig.Ieee8021xConfigs, ieee8021xCfg)
 		}
+		f.LocalConfig.WifiConfigs = append(f.LocalConfig.WifiConfigs, wifiCfg)
 	}
 
-	f.LocalConfig.WifiConfigs = append(f.LocalConfig.WifiConfigs, wifiCfg)
-	f.LocalConfig.Ieee8021xConfigs = append(f.LocalConfig.Ieee8021xConfigs, ieee8021xCfg)
 	rc = f.handleLocalConfig()
 	if rc != utils.Success {
-		return rc
+		return rpcerr.FromReturnCode(rc)
 	}
 	if configJson != "" {
 		err := json.Unmarshal([]byte(configJson), &f.LocalConfig)
 		if err != nil {
-			log.Error(err)
-			return utils.IncorrectCommandLineParameters
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidConfigJSON")
 		}
 	}
 
 	if len(f.LocalConfig.WifiConfigs) == 0 {
-		log.Error("missing wifi configuration")
-		return utils.MissingOrInvalidConfiguration
+		return newError(utils.MissingOrInvalidConfiguration, "error.wifi.missingConfiguration")
 	}
 
 	if secretsFilePath != "" {
 		err = cleanenv.ReadConfig(secretsFilePath, &wifiSecretConfig)
 		if err != nil {
-			log.Error("error reading secrets file: ", err)
-			return utils.FailedReadingConfiguration
+			return wrapError(utils.FailedReadingConfiguration, err, "error.readSecretsFile")
 		}
 	}
 
 	// merge secrets with configs
 	rc = f.mergeWifiSecrets(wifiSecretConfig)
 	if rc != utils.Success {
-		return rc
+		return rpcerr.FromReturnCode(rc)
 	}
 
 	// prompt for missing secrets
 	rc = f.promptForSecrets()
 	if rc != utils.Success {
-		return rc
+		return rpcerr.FromReturnCode(rc)
 	}
 	// verify configs
 	rc = f.verifyWifiConfigurations()
 	if rc != utils.Success {
-		return rc
+		return rpcerr.FromReturnCode(rc)
 	}
-	return utils.Success
+	return nil
 }
 
 func (f *Flags) mergeWifiSecrets(wifiSecretConfig config.SecretConfig) utils.ReturnCode {
@@ -1,11 +1,21 @@
 package flags
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
 	"rpc/internal/config"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
 
@@ -157,6 +167,43 @@ func TestCmdLine(t *testing.T) {
 		gotResult := f.ParseFlags()
 		assert.Equal(t, utils.IncorrectCommandLineParameters, gotResult)
 	})
+	t.Run("enablewifiport: expect Success with -linkPreference", func(t *testing.T) {
+		f := NewFlags([]string{
+			`rpc`, `configure`, `enablewifiport`, `-password`, `testpw`, `-linkPreference`, `me`, `-linkPreferenceTimeout`, `300`,
+		})
+		gotResult := f.ParseFlags()
+		assert.Equal(t, utils.Success, gotResult)
+		assert.Equal(t, WifiPortFlags{LinkPreference: LinkPreferenceME, LinkPreferenceTimeout: 300}, f.WifiPort)
+	})
+	t.Run("enablewifiport: expect Success with -disable", func(t *testing.T) {
+		f := NewFlags([]string{
+			`rpc`, `configure`, `enablewifiport`, `-password`, `testpw`, `-disable`,
+		})
+		gotResult := f.ParseFlags()
+		assert.Equal(t, utils.Success, gotResult)
+		assert.True(t, f.WifiPort.Disable)
+	})
+	t.Run("enablewifiport: expect IncorrectCommandLineParameters for unknown -linkPreference", func(t *testing.T) {
+		f := NewFlags([]string{
+			`rpc`, `configure`, `enablewifiport`, `-password`, `testpw`, `-linkPreference`, `amt`,
+		})
+		gotResult := f.ParseFlags()
+		assert.Equal(t, utils.IncorrectCommandLineParameters, gotResult)
+	})
+	t.Run("enablewifiport: expect IncorrectCommandLineParameters for -linkPreferenceTimeout out of range", func(t *testing.T) {
+		f := NewFlags([]string{
+			`rpc`, `configure`, `enablewifiport`, `-password`, `testpw`, `-linkPreference`, `me`, `-linkPreferenceTimeout`, `0`,
+		})
+		gotResult := f.ParseFlags()
+		assert.Equal(t, utils.IncorrectCommandLineParameters, gotResult)
+	})
+	t.Run("enablewifiport: expect InvalidParameterCombination for -disable with -linkPreference", func(t *testing.T) {
+		f := NewFlags([]string{
+			`rpc`, `configure`, `enablewifiport`, `-password`, `testpw`, `-disable`, `-linkPreference`, `host`,
+		})
+		gotResult := f.ParseFlags()
+		assert.Equal(t, utils.InvalidParameterCombination, gotResult)
+	})
 	t.Run("enablewifiport: ssexpect IncorrectCommandLineParameters", func(t *testing.T) {
 		f := NewFlags([]string{
 			`rpc`, `configure`, `enablewifiport`, `-bogus`, `testpw`,
@@ -225,6 +272,10 @@ func TestHandleAddWifiSettings(t *testing.T) {
 			cmdLine:        `rpc configure addwifisettings -password Passw0rd! -profileName cliprofname -authenticationMethod 6 -encryptionMethod 4 -ssid "myclissid" -priority 1 -pskPassphrase "mypassword"`,
 			expectedResult: utils.Success,
 		},
+		{description: "Unnamed wifi config command line",
+			cmdLine:        `rpc configure addwifisettings -password Passw0rd! -ssid "myclissid" -priority 1`,
+			expectedResult: utils.MissingOrInvalidConfiguration,
+		},
 		{description: "Valid with reading from file",
 			cmdLine:        "rpc configure addwifisettings -password Passw0rd! -config ../../config.yaml -secrets ../../secrets.yaml",
 			expectedResult: utils.Success,
@@ -234,12 +285,26 @@ func TestHandleAddWifiSettings(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			args := strings.Fields(tc.cmdLine)
 			flags := NewFlags(args)
-			gotResult := flags.handleAddWifiSettings()
+			gotResult := rpcerr.ReturnCodeOf(flags.handleAddWifiSettings())
 			assert.Equal(t, tc.expectedResult, gotResult)
 		})
 	}
 }
 
+func TestHandleAddWifiSettingsJsonFile(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "wificonfig.json")
+	cfgJson := `{"wifiConfigs":[{"profileName":"wifiWPA2","ssid":"ssid","priority":1,"authenticationMethod":6,"encryptionMethod":4,"pskPassphrase":"wifiWPA2PassPhrase"}]}`
+	err := os.WriteFile(cfgPath, []byte(cfgJson), 0600)
+	assert.Nil(t, err)
+	args := []string{`rpc`, `configure`, `addwifisettings`, `-password`, `Passw0rd!`, `-config`, cfgPath}
+	flags := NewFlags(args)
+	err = flags.handleAddWifiSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(flags.LocalConfig.WifiConfigs))
+	assert.Equal(t, "wifiWPA2PassPhrase", flags.LocalConfig.WifiConfigs[0].PskPassphrase)
+	assert.Equal(t, 0, len(flags.LocalConfig.Ieee8021xConfigs))
+}
+
 var wifiCfgWPA = config.WifiConfig{
 	ProfileName:          "wifiWPA",
 	SSID:                 "ssid",
@@ -535,3 +600,393 @@ func TestInvalidAuthenticationProtocols(t *testing.T) {
 			})
 	}
 }
+
+func writeTestCertificate(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "device"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return path
+}
+
+func TestHandleConfigureTLS(t *testing.T) {
+	certPath := writeTestCertificate(t)
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+		expectedMode   TLSMode
+	}{
+		{description: "Missing all params",
+			cmdLine:        "rpc configure tlssettings",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Generate CSR with default mode",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -csr amt.csr",
+			expectedResult: utils.Success,
+			expectedMode:   TLSModeServer,
+		},
+		{description: "Invalid mode",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -mode Bogus",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Both csr and cert",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -csr amt.csr -cert " + certPath,
+			expectedResult: utils.InvalidParameterCombination,
+			expectedMode:   TLSModeServer,
+		},
+		{description: "caCert with server mode",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -cert " + certPath + " -caCert " + certPath,
+			expectedResult: utils.InvalidParameterCombination,
+			expectedMode:   TLSModeServer,
+		},
+		{description: "Mutual without caCert",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -mode Mutual -cert " + certPath,
+			expectedResult: utils.IncorrectCommandLineParameters,
+			expectedMode:   TLSModeMutual,
+		},
+		{description: "Unreadable cert",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -cert missing.pem",
+			expectedResult: utils.FailedReadingConfiguration,
+			expectedMode:   TLSModeServer,
+		},
+		{description: "Mutual with caCert",
+			cmdLine:        "rpc configure tlssettings -password Passw0rd! -mode mutualandnontls -cert " + certPath + " -caCert " + certPath + " -trustedCN client",
+			expectedResult: utils.Success,
+			expectedMode:   TLSModeMutualAndNonTLS,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			args := strings.Fields(tc.cmdLine)
+			flags := NewFlags(args)
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureTLS())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if tc.expectedMode != 0 {
+				assert.Equal(t, tc.expectedMode, flags.TLSSettings.Mode)
+			}
+			if gotResult == utils.Success && strings.Contains(tc.cmdLine, "-cert") {
+				assert.NotEmpty(t, flags.TLSSettings.Cert)
+				assert.NotEmpty(t, flags.TLSSettings.CACert)
+			}
+		})
+	}
+}
+
+func TestTLSModeString(t *testing.T) {
+	assert.Equal(t, "ServerAndNonTLS", TLSModeServerAndNonTLS.String())
+	assert.Equal(t, "TLSMode(9)", TLSMode(9).String())
+	assert.True(t, TLSModeMutual.IsMutual())
+	assert.False(t, TLSModeMutual.AllowsNonTLS())
+}
+
+func TestHandleConfigureCIRA(t *testing.T) {
+	certPath := writeTestCertificate(t)
+	required := " -password Passw0rd! -mpsaddress mps.example.com -mpsuser admin -mpspassword MPSPassw0rd! -mpscert " + certPath
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+	}{
+		{description: "Missing all params",
+			cmdLine:        "rpc configure cira",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Missing mps certificate",
+			cmdLine:        "rpc configure cira -password Passw0rd! -mpsaddress mps.example.com -mpsuser admin -mpspassword MPSPassw0rd!",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Invalid port",
+			cmdLine:        "rpc configure cira" + required + " -mpsport 70000",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Negative periodic interval",
+			cmdLine:        "rpc configure cira" + required + " -periodic -1",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Unreadable certificate",
+			cmdLine:        "rpc configure cira -password Passw0rd! -mpsaddress mps.example.com -mpsuser admin -mpspassword MPSPassw0rd! -mpscert missing.pem",
+			expectedResult: utils.MissingOrIncorrectCACert,
+		},
+		{description: "Secondary is the primary",
+			cmdLine:        "rpc configure mps" + required + " -secondary MPS.example.com",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Invalid secondary port",
+			cmdLine:        "rpc configure mps" + required + " -secondary mps2.example.com -secondaryport 70000",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "All params",
+			cmdLine:        "rpc configure cira" + required + " -envdetection corp.example.com,,lab.example.com,Corp.Example.com",
+			expectedResult: utils.Success,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureCIRA())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success {
+				assert.Equal(t, 4433, flags.CIRASettings.MPSPort)
+				assert.Equal(t, 60, flags.CIRASettings.PeriodicInterval)
+				assert.Equal(t, "mps.example.com", flags.CIRASettings.MPSCommonName)
+				assert.Equal(t, []string{"corp.example.com", "lab.example.com"}, flags.CIRASettings.EnvironmentDetection)
+				assert.NotEmpty(t, flags.CIRASettings.MPSRootCert)
+				assert.Empty(t, flags.CIRASettings.SecondaryAddress)
+			}
+		})
+	}
+	t.Run("mps configures a secondary MPS with the defaults of the primary", func(t *testing.T) {
+		flags := NewFlags(strings.Fields("rpc configure mps" + required + " -mpsport 443 -secondary 10.0.0.2"))
+		assert.Nil(t, flags.handleConfigureCommand())
+		assert.Equal(t, utils.SubCommandConfigureCIRA, flags.SubCommand)
+		assert.Equal(t, "10.0.0.2", flags.CIRASettings.SecondaryAddress)
+		assert.Equal(t, 443, flags.CIRASettings.SecondaryPort)
+		assert.Equal(t, "10.0.0.2", flags.CIRASettings.SecondaryCommonName)
+		assert.False(t, flags.CIRASettings.Force)
+	})
+}
+
+func TestHandleConfigureWired8021x(t *testing.T) {
+	peap := " -password Passw0rd! -username user -ieee8021xPassword secret -authenticationProtocol 2 -caCert AAAA"
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+	}{
+		{description: "Missing all params",
+			cmdLine:        "rpc configure wired8021x",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Missing profile and username",
+			cmdLine:        "rpc configure wired8021x -password Passw0rd! -caCert AAAA",
+			expectedResult: utils.MissingOrInvalidConfiguration,
+		},
+		{description: "Missing CA certificate",
+			cmdLine:        "rpc configure wired8021x -password Passw0rd! -username user -ieee8021xPassword secret -authenticationProtocol 2",
+			expectedResult: utils.MissingOrInvalidConfiguration,
+		},
+		{description: "Invalid pxe timeout",
+			cmdLine:        "rpc configure wired8021x" + peap + " -pxeTimeout 90000",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Disable with a configuration",
+			cmdLine:        "rpc configure wired8021x" + peap + " -disable",
+			expectedResult: utils.InvalidParameterCombination,
+		},
+		{description: "Disable",
+			cmdLine:        "rpc configure wired8021x -password Passw0rd! -disable",
+			expectedResult: utils.Success,
+		},
+		{description: "PEAP from the command line",
+			cmdLine:        "rpc configure wired8021x" + peap,
+			expectedResult: utils.Success,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureWired8021x())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success && !flags.Wired8021x.Disable {
+				assert.Equal(t, "wired", flags.Wired8021x.ProfileName)
+				assert.Equal(t, 120, flags.Wired8021x.PxeTimeout)
+			}
+		})
+	}
+}
+
+func TestHandleConfigureAlarmClock(t *testing.T) {
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+	}{
+		{description: "List",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -json",
+			expectedResult: utils.Success,
+		},
+		{description: "Add",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -add nightly -start 02:00 -interval 24h",
+			expectedResult: utils.Success,
+		},
+		{description: "Delete",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -delete nightly",
+			expectedResult: utils.Success,
+		},
+		{description: "Add without start",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -add nightly",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Invalid start",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -add nightly -start tonight",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Interval not in whole minutes",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -add nightly -start 02:00 -interval 90s",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Add with delete",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -add nightly -start 02:00 -delete weekly",
+			expectedResult: utils.InvalidParameterCombination,
+		},
+		{description: "Start without add",
+			cmdLine:        "rpc configure alarmclock -password Passw0rd! -start 02:00",
+			expectedResult: utils.InvalidParameterCombination,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureAlarmClock())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success && flags.AlarmClock.Add != "" {
+				assert.True(t, flags.AlarmClock.Start.After(time.Now()))
+				assert.Equal(t, 24*time.Hour, flags.AlarmClock.Interval)
+			}
+		})
+	}
+}
+
+func TestParseAlarmStart(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	start, err := parseAlarmStart("14:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC), start)
+	start, err = parseAlarmStart("02:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 2, 2, 0, 0, 0, time.UTC), start)
+	start, err = parseAlarmStart("2024-06-01T02:00:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), start)
+	_, err = parseAlarmStart("2024-04-01T02:00:00Z", now)
+	assert.Error(t, err)
+}
+
+func TestHandleConfigureRedirection(t *testing.T) {
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+		expected       RedirectionFlags
+	}{
+		{description: "Enable and disable",
+			cmdLine:        "rpc configure redirection -password Passw0rd! -enable KVM -disable sol,ider",
+			expectedResult: utils.Success,
+			expected:       RedirectionFlags{Enable: []string{"kvm"}, Disable: []string{"sol", "ider"}},
+		},
+		{description: "Neither enable nor disable",
+			cmdLine:        "rpc configure redirection -password Passw0rd!",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Unknown feature",
+			cmdLine:        "rpc configure redirection -password Passw0rd! -enable usbr",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Feature enabled and disabled",
+			cmdLine:        "rpc configure redirection -password Passw0rd! -enable kvm,sol -disable sol",
+			expectedResult: utils.InvalidParameterCombination,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureRedirection())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success {
+				assert.Equal(t, tc.expected, flags.Redirection)
+			}
+		})
+	}
+}
+
+func TestHandleConfigureDNSSuffix(t *testing.T) {
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+		expected       string
+	}{
+		{description: "Suffix",
+			cmdLine:        "rpc configure dnssuffix -value corp.example.com",
+			expectedResult: utils.Success,
+			expected:       "corp.example.com",
+		},
+		{description: "Leading and trailing dots are dropped",
+			cmdLine:        "rpc configure dnssuffix -value .corp.example.com.",
+			expectedResult: utils.Success,
+			expected:       "corp.example.com",
+		},
+		{description: "Missing value",
+			cmdLine:        "rpc configure dnssuffix",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Invalid label",
+			cmdLine:        "rpc configure dnssuffix -value corp_example.com",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Too long",
+			cmdLine:        "rpc configure dnssuffix -value " + strings.Repeat("a.", 127) + "com",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureCommand())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success {
+				assert.Equal(t, tc.expected, flags.DNSSuffix.Value)
+				assert.True(t, flags.Local)
+				assert.Empty(t, flags.Password, "no AMT password is required")
+			}
+		})
+	}
+}
+
+func TestHandleConfigureAMTFeatures(t *testing.T) {
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+		expected       string
+	}{
+		{description: "Enable",
+			cmdLine:        "rpc configure amtfeatures -amt enable",
+			expectedResult: utils.Success,
+			expected:       AMTStateEnable,
+		},
+		{description: "Disable in any case",
+			cmdLine:        "rpc configure amtfeatures -amt Disable",
+			expectedResult: utils.Success,
+			expected:       AMTStateDisable,
+		},
+		{description: "Shows the state without -amt",
+			cmdLine:        "rpc configure amtfeatures -json",
+			expectedResult: utils.Success,
+		},
+		{description: "Invalid state",
+			cmdLine:        "rpc configure amtfeatures -amt on",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+		{description: "Unexpected argument",
+			cmdLine:        "rpc configure amtfeatures enable",
+			expectedResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := rpcerr.ReturnCodeOf(flags.handleConfigureCommand())
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success {
+				assert.Equal(t, tc.expected, flags.AMTFeatures.AMT)
+				assert.True(t, flags.Local)
+				assert.Empty(t, flags.Password, "no AMT password is required")
+			}
+		})
+	}
+}
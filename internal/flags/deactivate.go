@@ -2,33 +2,110 @@ package flags
 
 import (
 	"fmt"
+	"os"
+	"rpc/internal/amt"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"strings"
 )
 
-func (f *Flags) handleDeactivateCommand() utils.ReturnCode {
+// DeactivateFlags guard the deactivation of a managed device, -f or -force skips its confirmation
+type DeactivateFlags struct {
+	// Reason is logged and sent to the server with the deactivation
+	Reason string
+}
+
+// setupDeactivateFlags registers the flags of deactivate, which sets their defaults
+func (f *Flags) setupDeactivateFlags() {
 	f.amtDeactivateCommand.BoolVar(&f.Local, "local", false, "Execute command to AMT directly without cloud interaction")
-	if len(f.commandLineArgs) == 2 {
+	f.amtDeactivateCommand.BoolVar(&f.PartialDeactivate, "partial", false, "Remove CIRA, TLS and wifi configuration but leave AMT activated. Runs locally")
+	f.amtDeactivateCommand.BoolVar(&f.WipeStorage, "wipe", false, "Remove wifi profiles, certificates, CIRA configuration and the audit log, then deactivate. Runs locally")
+	f.amtDeactivateCommand.BoolVar(&f.Force, "force", false, "Same as -f, also deactivates without asking for confirmation when AMT is in admin control mode or CIRA is connected")
+	f.amtDeactivateCommand.StringVar(&f.Deactivate.Reason, "reason", "", "Reason for the deactivation, it is logged and sent to the server")
+}
+
+func (f *Flags) handleDeactivateCommand() error {
+	f.setupDeactivateFlags()
+	if len(f.commandLineArgs) == 2 && len(f.flagDefaults) == 0 {
 		f.amtDeactivateCommand.PrintDefaults()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	if err := f.parseWithDefaults(f.amtDeactivateCommand, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
-	if err := f.amtDeactivateCommand.Parse(f.commandLineArgs[2:]); err != nil {
-		return utils.IncorrectCommandLineParameters
+	return f.validateDeactivate()
+}
+
+// validateDeactivate checks the combination of the deactivate flags, the partial
+// deactivation and the wipe run locally
+func (f *Flags) validateDeactivate() error {
+	if f.PartialDeactivate {
+		if f.URL != "" {
+			return newError(utils.InvalidParameterCombination, "error.deactivate.urlOrPartial")
+		}
+		// partial deactivation is done directly against AMT
+		f.Local = true
+	}
+	if f.WipeStorage {
+		if f.PartialDeactivate {
+			return newError(utils.InvalidParameterCombination, "error.deactivate.wipeOrPartial")
+		}
+		if f.URL != "" {
+			return newError(utils.InvalidParameterCombination, "error.deactivate.urlOrWipe")
+		}
+		// the wipe needs the admin password on the device
+		f.Local = true
 	}
 	if f.Local && f.URL != "" {
-		fmt.Println("provide either a 'url' or a 'local', but not both")
-		return utils.InvalidParameterCombination
+		return newError(utils.InvalidParameterCombination, "error.urlOrLocal")
 	}
 	if !f.Local {
 		if f.URL == "" {
-			fmt.Println("-u flag is required and cannot be empty")
 			f.amtDeactivateCommand.Usage()
-			return utils.MissingOrIncorrectURL
+			return newError(utils.MissingOrIncorrectURL, "error.urlRequired")
 		}
 		if f.Password == "" {
 			if _, rc := f.ReadPasswordFromUser(); rc != 0 {
-				return utils.MissingOrIncorrectPassword
+				return rpcerr.New(utils.MissingOrIncorrectPassword, "")
 			}
 		}
 	}
+	return nil
+}
+
+// ConfirmDeactivation asks to type yes before AMT is deactivated in admin control mode or
+// while CIRA is connected, so a copy-pasted command does not unprovision a managed device.
+// -f or -force skips the question. The device is not asked about when its state can not be
+// read, the deactivation fails on that itself. With -json or -yaml the question goes to
+// stderr, so stdout only holds the result document.
+func (f *Flags) ConfirmDeactivation(amtCommand amt.Interface) utils.ReturnCode {
+	if f.Force {
+		return utils.Success
+	}
+	var managed []string
+	if controlMode, err := amtCommand.GetControlMode(); err == nil && controlMode == 2 {
+		managed = append(managed, "AMT is activated in admin control mode")
+	}
+	if status, err := amtCommand.GetRemoteAccessConnectionStatus(); err == nil && status.RemoteStatus == "connected" {
+		managed = append(managed, "CIRA is connected to "+status.MPSHostname)
+	}
+	if len(managed) == 0 {
+		return utils.Success
+	}
+	if f.NonInteractive {
+		log.Error(strings.Join(managed, " and ") + ", use -f or -force to deactivate without confirmation")
+		return f.inputRequired("Type yes to deactivate AMT")
+	}
+	prompt := os.Stdout
+	if f.JsonOutput || f.YamlOutput {
+		prompt = os.Stderr
+	}
+	fmt.Fprintf(prompt, "%s.\nType yes to deactivate AMT: ", strings.Join(managed, " and "))
+	answer, err := readLine()
+	answer = strings.TrimSpace(answer)
+	if err != nil || !strings.EqualFold(answer, "yes") {
+		log.Error("deactivation not confirmed, use -f or -force to deactivate without confirmation")
+		return utils.InvalidUserInput
+	}
 	return utils.Success
 }
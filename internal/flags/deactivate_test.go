@@ -1,6 +1,9 @@
 package flags
 
 import (
+	"io"
+	"os"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
 	"testing"
 
@@ -11,14 +14,14 @@ func TestHandleDeactivateCommandNoFlags(t *testing.T) {
 	args := []string{"./rpc", "deactivate"}
 	flags := NewFlags(args)
 	flags.amtCommand.PTHI = MockPTHICommands{}
-	success := flags.handleDeactivateCommand()
+	success := rpcerr.ReturnCodeOf(flags.handleDeactivateCommand())
 	assert.EqualValues(t, success, utils.IncorrectCommandLineParameters)
 }
 func TestHandleDeactivateInvalidFlag(t *testing.T) {
 	args := []string{"./rpc", "deactivate", "-x"}
 
 	flags := NewFlags(args)
-	success := flags.handleDeactivateCommand()
+	success := rpcerr.ReturnCodeOf(flags.handleDeactivateCommand())
 	assert.EqualValues(t, success, utils.IncorrectCommandLineParameters)
 }
 
@@ -36,14 +39,14 @@ func TestHandleDeactivateCommandNoPasswordPromptEmpy(t *testing.T) {
 	args := []string{"./rpc", "deactivate", "-u", "wss://localhost"}
 	defer userInput(t, "")()
 	flags := NewFlags(args)
-	success := flags.handleDeactivateCommand()
+	success := rpcerr.ReturnCodeOf(flags.handleDeactivateCommand())
 	assert.EqualValues(t, success, utils.MissingOrIncorrectPassword)
 }
 func TestHandleDeactivateCommandNoURL(t *testing.T) {
 	args := []string{"./rpc", "deactivate", "--password", "password"}
 
 	flags := NewFlags(args)
-	success := flags.handleDeactivateCommand()
+	success := rpcerr.ReturnCodeOf(flags.handleDeactivateCommand())
 	assert.EqualValues(t, success, utils.MissingOrIncorrectURL)
 }
 func TestHandleDeactivateCommand(t *testing.T) {
@@ -59,7 +62,7 @@ func TestHandleDeactivateCommand(t *testing.T) {
 func TestHandleDeactivateCommandWithURLAndLocal(t *testing.T) {
 	args := []string{"./rpc", "deactivate", "-u", "wss://localhost", "--password", "password", "-local"}
 	flags := NewFlags(args)
-	success := flags.handleDeactivateCommand()
+	success := rpcerr.ReturnCodeOf(flags.handleDeactivateCommand())
 	assert.EqualValues(t, success, utils.InvalidParameterCombination)
 	assert.Equal(t, "wss://localhost", flags.URL)
 }
@@ -95,3 +98,116 @@ func TestParseFlagsDeactivate(t *testing.T) {
 	assert.EqualValues(t, result, utils.IncorrectCommandLineParameters)
 	assert.Equal(t, utils.CommandDeactivate, flags.Command)
 }
+
+func TestHandleDeactivateCommandPartial(t *testing.T) {
+	args := []string{"./rpc", "deactivate", "-partial", "--password", "password"}
+	flags := NewFlags(args)
+	success := flags.ParseFlags()
+	assert.EqualValues(t, utils.Success, success)
+	assert.Equal(t, true, flags.PartialDeactivate)
+	assert.Equal(t, true, flags.Local)
+}
+
+func TestHandleDeactivateCommandPartialWithURL(t *testing.T) {
+	args := []string{"./rpc", "deactivate", "-partial", "-u", "wss://localhost", "--password", "password"}
+	flags := NewFlags(args)
+	success := flags.ParseFlags()
+	assert.EqualValues(t, utils.InvalidParameterCombination, success)
+}
+
+func TestParseDeactivatePartialWithURLError(t *testing.T) {
+	args := []string{"./rpc", "deactivate", "-partial", "-u", "wss://localhost", "--password", "password"}
+	flags := NewFlags(args)
+	err := flags.Parse()
+	var rpcErr *rpcerr.Error
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, utils.InvalidParameterCombination, rpcErr.ReturnCode)
+	assert.NotEmpty(t, rpcErr.Message)
+}
+
+func TestHandleDeactivateCommandWipe(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected utils.ReturnCode
+	}{
+		"runs locally": {
+			args:     []string{"./rpc", "deactivate", "-wipe", "--password", "password"},
+			expected: utils.Success,
+		},
+		"rejects partial": {
+			args:     []string{"./rpc", "deactivate", "-wipe", "-partial", "--password", "password"},
+			expected: utils.InvalidParameterCombination,
+		},
+		"rejects url": {
+			args:     []string{"./rpc", "deactivate", "-wipe", "-u", "wss://localhost", "--password", "password"},
+			expected: utils.InvalidParameterCombination,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(tc.args)
+			rc := flags.ParseFlags()
+			assert.EqualValues(t, tc.expected, rc)
+			if tc.expected == utils.Success {
+				assert.True(t, flags.WipeStorage)
+				assert.True(t, flags.Local)
+			}
+		})
+	}
+}
+
+func TestConfirmDeactivation(t *testing.T) {
+	orig := mode
+	defer func() { mode = orig }()
+	flags := NewFlags([]string{"./rpc", "deactivate", "-local"})
+	flags.amtCommand.PTHI = MockPTHICommands{}
+
+	t.Run("does not ask in CCM without CIRA", func(t *testing.T) {
+		mode = 1
+		assert.Equal(t, utils.Success, flags.ConfirmDeactivation(flags.amtCommand))
+	})
+	t.Run("asks in ACM", func(t *testing.T) {
+		mode = 2
+		defer userInput(t, "yes\n")()
+		assert.Equal(t, utils.Success, flags.ConfirmDeactivation(flags.amtCommand))
+	})
+	t.Run("fails when not confirmed", func(t *testing.T) {
+		mode = 2
+		defer userInput(t, "y\n")()
+		assert.Equal(t, utils.InvalidUserInput, flags.ConfirmDeactivation(flags.amtCommand))
+	})
+	t.Run("fails without asking with -nonInteractive", func(t *testing.T) {
+		mode = 2
+		defer userInput(t, "yes\n")()
+		flags.NonInteractive = true
+		defer func() { flags.NonInteractive = false }()
+		assert.Equal(t, utils.InputRequired, flags.ConfirmDeactivation(flags.amtCommand))
+	})
+	t.Run("asks on stderr with -json", func(t *testing.T) {
+		mode = 2
+		defer userInput(t, "yes\n")()
+		r, w, err := os.Pipe()
+		assert.NoError(t, err)
+		stdout := os.Stdout
+		os.Stdout = w
+		flags.JsonOutput = true
+		defer func() { flags.JsonOutput = false }()
+		rc := flags.ConfirmDeactivation(flags.amtCommand)
+		os.Stdout = stdout
+		w.Close()
+		written, _ := io.ReadAll(r)
+		assert.Equal(t, utils.Success, rc)
+		assert.Empty(t, written, "stdout only holds the result document")
+	})
+	for _, force := range []string{"-f", "-force"} {
+		t.Run("does not ask with "+force, func(t *testing.T) {
+			mode = 2
+			flags := NewFlags([]string{"./rpc", "deactivate", "-local", force, "-reason", "decommissioned"})
+			flags.amtCommand.PTHI = MockPTHICommands{}
+			assert.Equal(t, utils.Success, flags.ParseFlags())
+			assert.True(t, flags.Force)
+			assert.Equal(t, "decommissioned", flags.Deactivate.Reason)
+			assert.Equal(t, utils.Success, flags.ConfirmDeactivation(flags.amtCommand))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// envPrefix names the environment variables holding flag defaults, ex. RPC_PROFILE for -profile
+	envPrefix = "RPC_"
+	// defaultsFlag selects the file holding flag defaults
+	defaultsFlag  = "config"
+	defaultsUsage = "File with default flag values (rpc.yaml or rpc.json)"
+)
+
+// defaultsFiles are looked for next to the executable when no -config is given
+var defaultsFiles = []string{"rpc.yaml", "rpc.json"}
+
+// loadFlagDefaults reads the flag defaults from the -config file, RPC_CONFIG or an
+// rpc.yaml or rpc.json next to the executable. The file maps flag names to values:
+//
+//	u: wss://rps.example.com/activate
+//	profile: acmprofile
+//	json: true
+func (f *Flags) loadFlagDefaults() utils.ReturnCode {
+	f.flagDefaults = map[string]string{}
+	if f.request {
+		return utils.Success
+	}
+	path := f.defaultsFile()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		// ex. an smb: share or a .toml local configuration read by activate -config
+		return utils.Success
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Error("unable to read flag defaults: ", err)
+		return utils.FailedReadingConfiguration
+	}
+	// YAML is a superset of JSON, rpc.json is read the same way
+	var values map[string]interface{}
+	if err = yaml.Unmarshal(content, &values); err != nil {
+		log.Errorf("unable to parse flag defaults in %s: %s", path, err)
+		return utils.FailedReadingConfiguration
+	}
+	for name, value := range values {
+		switch v := value.(type) {
+		case string, bool, int, float64:
+			f.flagDefaults[name] = fmt.Sprint(v)
+		case []interface{}:
+			// lists are given to flags taking comma separated values, ex. -tasks, an
+			// item with commas is quoted like a CSV field
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+				if strings.Contains(items[i], ",") {
+					items[i] = `"` + strings.ReplaceAll(items[i], `"`, `""`) + `"`
+				}
+			}
+			f.flagDefaults[name] = strings.Join(items, ",")
+		default:
+			// nested settings belong to the local configuration read by -config, ex. wifiConfigs
+		}
+	}
+	log.Debugf("read %d flag defaults from %s", len(f.flagDefaults), path)
+	return utils.Success
+}
+
+// defaultsFile returns the file named by -config on the command line or RPC_CONFIG,
+// or the first defaults file found next to the executable
+func (f *Flags) defaultsFile() string {
+	for i, arg := range f.commandLineArgs {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != defaultsFlag {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(f.commandLineArgs) {
+			return f.commandLineArgs[i+1]
+		}
+	}
+	if path, ok := os.LookupEnv(envPrefix + strings.ToUpper(defaultsFlag)); ok {
+		return path
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	for _, name := range defaultsFiles {
+		path := filepath.Join(filepath.Dir(executable), name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// parseWithDefaults parses the command line of a flag set after applying the defaults
+// from the environment and the defaults file. The command line takes precedence over
+// RPC_* environment variables, which take precedence over the file. Defaults are not
+// reported by FlagSet.Visit, which only visits the flags given on the command line.
+func (f *Flags) parseWithDefaults(fs *flag.FlagSet, args []string) error {
+	var err error
+	fs.VisitAll(func(fl *flag.Flag) {
+		if err != nil || fl.Name == defaultsFlag || f.usageOnly || f.request {
+			return
+		}
+		value, ok := os.LookupEnv(envName(fl.Name))
+		source := "environment"
+		if !ok {
+			value, ok = f.flagDefaults[fl.Name]
+			source = "defaults file"
+		}
+		if !ok {
+			return
+		}
+		var setErr error
+		// flags taking a single item on the command line parse the list of the defaults themselves
+		if list, ok := fl.Value.(interface{ setDefault(string) error }); ok {
+			setErr = list.setDefault(value)
+		} else {
+			setErr = fl.Value.Set(value)
+		}
+		if setErr != nil {
+			err = fmt.Errorf("invalid value %q for flag -%s in %s: %w", value, fl.Name, source, setErr)
+		}
+		// lists on the command line replace the default instead of adding to it
+		if list, ok := fl.Value.(interface{ defaultsApplied() }); ok {
+			list.defaultsApplied()
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return err
+	}
+	if err = f.parse(fs, args); err != nil {
+		return err
+	}
+	// the MEI commands made while handling the command line use its timeout
+	f.amtCommand.Context, f.amtCommand.Timeout = f.Context, f.Timeout
+	return f.readPasswordSource(fs)
+}
+
+// envName returns the environment variable holding the default of a flag, ex. RPC_VERBOSE_PROGRESS
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flagName))
+}
@@ -0,0 +1,81 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeDefaults(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestParseFlagsDefaultsFile(t *testing.T) {
+	yamlFile := writeDefaults(t, "rpc.yaml", "u: wss://rps.example.com/activate\npassword: P@ssw0rd\nretries: 5\nn: true\n")
+
+	t.Run("reads defaults from the -config file", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config", yamlFile})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "wss://rps.example.com/activate", flags.URL)
+		assert.Equal(t, "P@ssw0rd", flags.Password)
+		assert.Equal(t, 5, flags.Retries)
+		assert.True(t, flags.SkipCertCheck)
+	})
+	t.Run("command line flags take precedence", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config=" + yamlFile, "-u", "wss://other/activate", "-retries", "1"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "wss://other/activate", flags.URL)
+		assert.Equal(t, 1, flags.Retries)
+	})
+	t.Run("RPC_ environment variables take precedence over the file", func(t *testing.T) {
+		t.Setenv("RPC_RETRIES", "7")
+		t.Setenv("RPC_VERBOSE_PROGRESS", "true")
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config", yamlFile})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, 7, flags.Retries)
+		assert.True(t, flags.VerboseProgress)
+	})
+	t.Run("reads the file from RPC_CONFIG", func(t *testing.T) {
+		t.Setenv("RPC_CONFIG", yamlFile)
+		flags := NewFlags([]string{"./rpc", "deactivate"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "wss://rps.example.com/activate", flags.URL)
+	})
+	t.Run("reads json and joins lists", func(t *testing.T) {
+		jsonFile := writeDefaults(t, "rpc.json", `{"u": "wss://rps.example.com/activate", "password": "P@ssw0rd", "tasks": ["syncclock", "syncip"], "interval": "2h"}`)
+		flags := NewFlags([]string{"./rpc", "agent", "-config", jsonFile})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, []string{utils.SubCommandSyncClock, utils.SubCommandSyncIP}, flags.AgentTasks)
+		assert.Equal(t, 2*time.Hour, flags.AgentInterval)
+	})
+	t.Run("returns IncorrectCommandLineParameters for an invalid value", func(t *testing.T) {
+		badFile := writeDefaults(t, "rpc.yaml", "retries: many\n")
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config", badFile, "-u", "wss://localhost", "-password", "P@ssw0rd"})
+		assert.Equal(t, utils.IncorrectCommandLineParameters, flags.ParseFlags())
+	})
+	t.Run("returns FailedReadingConfiguration for a missing file", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config", filepath.Join(t.TempDir(), "missing.yaml")})
+		assert.Equal(t, utils.FailedReadingConfiguration, flags.ParseFlags())
+	})
+	t.Run("returns FailedReadingConfiguration for a malformed file", func(t *testing.T) {
+		badFile := writeDefaults(t, "rpc.yaml", "u: [wss://localhost\n")
+		flags := NewFlags([]string{"./rpc", "deactivate", "-config", badFile})
+		assert.Equal(t, utils.FailedReadingConfiguration, flags.ParseFlags())
+	})
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "RPC_U", envName("u"))
+	assert.Equal(t, "RPC_MQTTBROKER", envName("mqttBroker"))
+	assert.Equal(t, "RPC_WARN_ONLY", envName("warn-only"))
+}
@@ -0,0 +1,62 @@
+package flags
+
+import (
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+type DiagFlags struct {
+	// Bundle writes the diagnostics bundle, the only action of diag so far
+	Bundle bool
+	// Dir is the directory the bundle is written to, the current directory when empty
+	Dir string
+	// Logs are the rpc log files added to the bundle with their rotated files
+	Logs []string
+}
+
+func (f *Flags) handleDiagCommand() error {
+	fs := f.diagCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.BoolVar(&f.Diag.Bundle, "bundle", false, "Write amtinfo, the AMT event log, the rpc logs, the OS network configuration and the LMS status to a timestamped zip, with the secrets redacted")
+	fs.StringVar(&f.Diag.Dir, "dir", "", "Directory the bundle is written to (default the current directory)")
+	logs := fs.String("logs", "", "rpc log files to add to the bundle, comma separated, their rotated files are added too")
+	fs.StringVar(&f.LMSAddress, "lmsaddress", utils.LMSAddress, "LMS address to check")
+	fs.StringVar(&f.LMSPort, "lmsport", utils.LMSPort, "LMS port to check")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password, the event log is only added with it")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	if err := f.parse(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if !f.Diag.Bundle {
+		return newError(utils.IncorrectCommandLineParameters, "error.diag.bundleRequired")
+	}
+	if f.Diag.Dir != "" {
+		if info, err := os.Stat(f.Diag.Dir); err != nil || !info.IsDir() {
+			return newError(utils.IncorrectCommandLineParameters, "error.diag.notDirectory", f.Diag.Dir)
+		}
+	}
+	f.Diag.Logs = nil
+	for _, path := range strings.Split(*logs, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			f.Diag.Logs = append(f.Diag.Logs, path)
+		}
+	}
+	// the values are collected on this host, what can not be read is noted in the bundle
+	f.Local = true
+	if f.Password == "" && f.PasswordFromKeyring {
+		if _, rc := f.readPasswordFromKeyring(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleDiagCommand(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantDiag   DiagFlags
+	}{
+		"should accept bundle": {
+			cmdLine:    "./rpc diag -bundle",
+			wantResult: utils.Success,
+			wantDiag:   DiagFlags{Bundle: true},
+		},
+		"should split logs and take dir": {
+			cmdLine:    "./rpc diag -bundle -dir " + dir + " -logs /var/log/rpc.log,,/tmp/agent.log",
+			wantResult: utils.Success,
+			wantDiag:   DiagFlags{Bundle: true, Dir: dir, Logs: []string{"/var/log/rpc.log", "/tmp/agent.log"}},
+		},
+		"should require bundle": {
+			cmdLine:    "./rpc diag -json",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on missing dir": {
+			cmdLine:    "./rpc diag -bundle -dir " + dir + "/missing",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantDiag:   DiagFlags{Bundle: true, Dir: dir + "/missing"},
+		},
+		"should fail on extra arguments": {
+			cmdLine:    "./rpc diag -bundle now",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantDiag:   DiagFlags{Bundle: true},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandDiag, flags.Command)
+			assert.Equal(t, tc.wantDiag, flags.Diag)
+			assert.Equal(t, tc.wantResult == utils.Success, flags.Local)
+		})
+	}
+}
@@ -6,29 +6,46 @@ package flags
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"rpc/internal/amt"
 	"rpc/internal/config"
+	"rpc/internal/i18n"
+	"rpc/internal/keyring"
+	"rpc/internal/lm"
+	"rpc/internal/logging"
+	"rpc/internal/mqtt"
+	"rpc/internal/output"
+	"rpc/internal/secretstore"
 	"rpc/internal/smb"
+	"rpc/internal/telemetry"
+	"rpc/internal/wlan"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
-
-	log "github.com/sirupsen/logrus"
 )
 
+var log = logging.For(logging.ModuleFlags)
+
 // A NetEnumerator enumerates local IP addresses.
 type NetEnumerator struct {
 	Interfaces     func() ([]net.Interface, error)
 	InterfaceAddrs func(*net.Interface) ([]net.Addr, error)
+	// VLANID returns the VLAN tag of a VLAN subinterface and 0 for untagged
+	// interfaces. When nil, no interface is treated as a VLAN subinterface.
+	VLANID func(*net.Interface) int
 }
 
 type IPConfiguration struct {
@@ -37,6 +54,10 @@ type IPConfiguration struct {
 	Gateway      string `json:"gateway"`
 	PrimaryDns   string `json:"primaryDns"`
 	SecondaryDns string `json:"secondaryDns"`
+	// the IPv6 settings are optional, AMT keeps using IPv4 for the static configuration
+	IPv6Address      string `json:"ipv6Address,omitempty"`
+	IPv6PrefixLength int    `json:"ipv6PrefixLength,omitempty"`
+	IPv6Gateway      string `json:"ipv6Gateway,omitempty"`
 }
 
 type HostnameInfo struct {
@@ -46,127 +67,550 @@ type HostnameInfo struct {
 
 // Flags holds data received from the command line
 type Flags struct {
-	commandLineArgs                     []string
-	URL                                 string
-	DNS                                 string
-	Hostname                            string
-	Proxy                               string
-	Command                             string
-	SubCommand                          string
-	Profile                             string
-	LMSAddress                          string
-	LMSPort                             string
-	SkipCertCheck                       bool
-	Verbose                             bool
-	Force                               bool
-	JsonOutput                          bool
-	RandomPassword                      bool
-	Local                               bool
-	StaticPassword                      string
-	Password                            string
-	LogLevel                            string
-	Token                               string
-	TenantID                            string
-	UseCCM                              bool
-	UseACM                              bool
+	commandLineArgs   []string
+	URL               string
+	ServerOrder       string
+	DNS               string
+	Hostname          string
+	Proxy             string
+	ProxyUser         string
+	ProxyPassword     string
+	Command           string
+	SubCommand        string
+	Profile           string
+	LMSAddress        string
+	LMSPort           string
+	SkipCertCheck     bool
+	ServerTLS         ServerTLSFlags
+	Verbose           bool
+	VerboseProgress   bool
+	HeartbeatInterval time.Duration
+	NoCompression     bool
+	ChunkSize         int
+	UploadLogs        bool
+	Force             bool
+	DryRun            bool
+	JsonOutput        bool
+	YamlOutput        bool
+	// Language selects the catalog of the usage texts and labels, see the i18n package
+	Language            string
+	RandomPassword      bool
+	FIPS                bool
+	Local               bool
+	StaticPassword      string
+	NTPServer           string
+	Password            string
+	PasswordFromKeyring bool
+	PasswordFile        string
+	LogLevel            string
+	LogLevels           string
+	LogFile             string
+	LogMaxSize          int
+	LogJSON             bool
+	MQTTBroker          string
+	MQTTTopic           string
+	MQTTUser            string
+	MQTTPassword        string
+	OTelEndpoint        string
+	Token               string
+	TenantID            string
+	Tags                map[string]string
+	UseCCM              bool
+	UseACM              bool
+	PartialDeactivate   bool
+	WipeStorage         bool
+	MEBxPassword        string
+	Interactive         bool
+	NonInteractive      bool
+	Output              []string
+	// Transport is the path to the WS-MAN interface of the local AMT, lms, lms-tls, lme or auto
+	Transport                           lm.Transport
+	Precheck                            PrecheckFlags
 	configContent                       string
+	flagDefaults                        map[string]string
 	UUID                                string
 	LocalConfig                         config.Config
 	amtInfoCommand                      *flag.FlagSet
 	amtActivateCommand                  *flag.FlagSet
 	amtDeactivateCommand                *flag.FlagSet
+	amtAgentCommand                     *flag.FlagSet
 	amtMaintenanceSyncIPCommand         *flag.FlagSet
 	amtMaintenanceSyncClockCommand      *flag.FlagSet
 	amtMaintenanceSyncHostnameCommand   *flag.FlagSet
 	amtMaintenanceChangePasswordCommand *flag.FlagSet
 	amtMaintenanceSyncDeviceInfoCommand *flag.FlagSet
+	amtMaintenanceSyncDNSCommand        *flag.FlagSet
+	amtMaintenanceSyncWifiCommand       *flag.FlagSet
+	amtMaintenanceBatchCommand          *flag.FlagSet
 	versionCommand                      *flag.FlagSet
+	returnCodesCommand                  *flag.FlagSet
 	flagSetAddWifiSettings              *flag.FlagSet
 	flagSetEnableWifiPort               *flag.FlagSet
+	flagSetTLSSettings                  *flag.FlagSet
+	flagSetCIRASettings                 *flag.FlagSet
+	flagSetWired8021x                   *flag.FlagSet
+	flagSetAlarmClock                   *flag.FlagSet
+	flagSetRedirection                  *flag.FlagSet
+	flagSetDNSSuffix                    *flag.FlagSet
+	flagSetAMTFeatures                  *flag.FlagSet
+	flagSetCertHash                     *flag.FlagSet
+	amtPowerCommand                     *flag.FlagSet
+	amtStatusCommand                    *flag.FlagSet
+	checkCertCommand                    *flag.FlagSet
+	wsmanCommand                        *flag.FlagSet
+	bulkCommand                         *flag.FlagSet
+	selfTestCommand                     *flag.FlagSet
+	applyCommand                        *flag.FlagSet
+	helpCommand                         *flag.FlagSet
+	solCommand                          *flag.FlagSet
+	bootCommand                         *flag.FlagSet
+	diagCommand                         *flag.FlagSet
 	amtCommand                          amt.AMTCommand
 	netEnumerator                       NetEnumerator
-	IpConfiguration                     IPConfiguration
-	HostnameInfo                        HostnameInfo
-	AMTTimeoutDuration                  time.Duration
-	FriendlyName                        string
-	AmtInfo                             AmtInfoFlags
+	keyringGet                          func(service string, account string) (string, error)
+	wlanProfiles                        func() ([]wlan.Profile, error)
+	// passwordErr keeps the return code of a password that can not be read, the
+	// command handlers report every error of parsing as IncorrectCommandLineParameters
+	passwordErr error
+	// usageOnly parses a command only for the options help lists, its usage texts
+	// are discarded and the environment is not read
+	usageOnly bool
+	// request is a command run in process by the rpc package, see NewRequest
+	request bool
+	// jsonErrors is set by -json, a failure is then reported in the JSON error envelope
+	// of the caller instead of usage texts and messages on stdout
+	jsonErrors bool
+	// parsedFlagSet is the flag set of the parsed command
+	parsedFlagSet      *flag.FlagSet
+	IpConfiguration    IPConfiguration
+	InterfaceName      string
+	InterfaceMAC       string
+	PreferSubnet       *net.IPNet
+	HostnameInfo       HostnameInfo
+	AMTTimeoutDuration time.Duration
+	// Timeout bounds each MEI command, Context cancels them, nil is never cancelled
+	Timeout       time.Duration
+	Context       context.Context
+	Retries       int
+	RetryDelay    time.Duration
+	FriendlyName  string
+	AgentInterval time.Duration
+	AgentTasks    []string
+	// AgentControl is the socket or address of the control API of the agent, off when empty
+	AgentControl string
+	// AgentControlToken is the file of the token of the control API, the default when empty
+	AgentControlToken string
+	// AgentSecretsFile holds the secrets of the agent installed as a service, see ServiceFlags
+	AgentSecretsFile string
+	MaintenanceTasks []string
+	AmtInfo          AmtInfoFlags
+	InfoCache        InfoCacheFlags
+	TLSSettings      TLSSettingsFlags
+	CIRASettings     CIRASettingsFlags
+	Wired8021x       Wired8021xFlags
+	WifiPort         WifiPortFlags
+	Service          ServiceFlags
+	ChangePassword   ChangePasswordFlags
+	Power            PowerFlags
+	Status           StatusFlags
+	SyncHostname     SyncHostnameFlags
+	SyncClock        SyncClockFlags
+	Deactivate       DeactivateFlags
+	AlarmClock       AlarmClockFlags
+	Redirection      RedirectionFlags
+	SyncDeviceInfo   SyncDeviceInfoFlags
+	Activate         ActivateFlags
+	WSMAN            WSMANFlags
+	DNSSuffix        DNSSuffixFlags
+	Remote           RemoteFlags
+	Bulk             BulkFlags
+	AMTFeatures      AMTFeaturesFlags
+	CertHash         CertHashFlags
+	Apply            ApplyFlags
+	Help             HelpFlags
+	SOL              SOLFlags
+	Boot             BootFlags
+	Diag             DiagFlags
 }
 
 func NewFlags(args []string) *Flags {
-	flags := &Flags{}
+	return newFlags(args, false)
+}
+
+// newFlags returns the flags of the command line or, for request, of a command filled in
+// by the rpc package, see NewRequest
+func newFlags(args []string, request bool) *Flags {
+	flags := &Flags{request: request}
 	flags.commandLineArgs = args
 	flags.amtInfoCommand = flag.NewFlagSet(utils.CommandAMTInfo, flag.ContinueOnError)
 	flags.amtInfoCommand.BoolVar(&flags.JsonOutput, "json", false, "json output")
+	flags.amtInfoCommand.BoolVar(&flags.YamlOutput, "yaml", false, "yaml output")
 
 	flags.amtActivateCommand = flag.NewFlagSet(utils.CommandActivate, flag.ContinueOnError)
 	flags.amtDeactivateCommand = flag.NewFlagSet(utils.CommandDeactivate, flag.ContinueOnError)
+	flags.amtAgentCommand = flag.NewFlagSet(utils.CommandAgent, flag.ContinueOnError)
 
 	flags.amtMaintenanceSyncIPCommand = flag.NewFlagSet("syncip", flag.ContinueOnError)
 	flags.amtMaintenanceSyncClockCommand = flag.NewFlagSet("syncclock", flag.ContinueOnError)
 	flags.amtMaintenanceSyncHostnameCommand = flag.NewFlagSet("synchostname", flag.ContinueOnError)
 	flags.amtMaintenanceChangePasswordCommand = flag.NewFlagSet("changepassword", flag.ContinueOnError)
 	flags.amtMaintenanceSyncDeviceInfoCommand = flag.NewFlagSet("syncdeviceinfo", flag.ContinueOnError)
+	flags.amtMaintenanceSyncDNSCommand = flag.NewFlagSet("syncdns", flag.ContinueOnError)
+	flags.amtMaintenanceSyncWifiCommand = flag.NewFlagSet("syncwifi", flag.ContinueOnError)
+	flags.amtMaintenanceBatchCommand = flag.NewFlagSet(utils.CommandMaintenance, flag.ContinueOnError)
 
 	flags.versionCommand = flag.NewFlagSet(utils.CommandVersion, flag.ContinueOnError)
 	flags.versionCommand.BoolVar(&flags.JsonOutput, "json", false, "json output")
+	flags.versionCommand.BoolVar(&flags.YamlOutput, "yaml", false, "yaml output")
+
+	flags.returnCodesCommand = flag.NewFlagSet(utils.CommandReturnCodes, flag.ContinueOnError)
+	flags.returnCodesCommand.BoolVar(&flags.JsonOutput, "json", false, "json output")
+	flags.returnCodesCommand.BoolVar(&flags.YamlOutput, "yaml", false, "yaml output")
 
 	flags.flagSetAddWifiSettings = flag.NewFlagSet(utils.SubCommandAddWifiSettings, flag.ContinueOnError)
 	flags.flagSetEnableWifiPort = flag.NewFlagSet(utils.SubCommandEnableWifiPort, flag.ContinueOnError)
+	flags.flagSetTLSSettings = flag.NewFlagSet(utils.SubCommandConfigureTLS, flag.ContinueOnError)
+	flags.flagSetCIRASettings = flag.NewFlagSet(utils.SubCommandConfigureCIRA, flag.ContinueOnError)
+	flags.flagSetWired8021x = flag.NewFlagSet(utils.SubCommandWired8021x, flag.ContinueOnError)
+	flags.flagSetAlarmClock = flag.NewFlagSet(utils.SubCommandAlarmClock, flag.ContinueOnError)
+	flags.flagSetRedirection = flag.NewFlagSet(utils.SubCommandRedirection, flag.ContinueOnError)
+	flags.flagSetDNSSuffix = flag.NewFlagSet(utils.SubCommandDNSSuffix, flag.ContinueOnError)
+	flags.flagSetAMTFeatures = flag.NewFlagSet(utils.SubCommandAMTFeatures, flag.ContinueOnError)
+	flags.flagSetCertHash = flag.NewFlagSet(utils.SubCommandCertHash, flag.ContinueOnError)
+
+	flags.amtPowerCommand = flag.NewFlagSet(utils.CommandPower, flag.ContinueOnError)
+	flags.amtStatusCommand = flag.NewFlagSet(utils.CommandStatus, flag.ContinueOnError)
+	flags.checkCertCommand = flag.NewFlagSet(utils.CommandCheckCert, flag.ContinueOnError)
+	flags.wsmanCommand = flag.NewFlagSet(utils.CommandWSMAN, flag.ContinueOnError)
+	flags.bulkCommand = flag.NewFlagSet(utils.CommandBulk, flag.ContinueOnError)
+	flags.selfTestCommand = flag.NewFlagSet(utils.CommandSelfTest, flag.ContinueOnError)
+	flags.applyCommand = flag.NewFlagSet(utils.CommandApply, flag.ContinueOnError)
+	flags.helpCommand = flag.NewFlagSet(utils.CommandHelp, flag.ContinueOnError)
+	flags.solCommand = flag.NewFlagSet(utils.CommandSOL, flag.ContinueOnError)
+	flags.bootCommand = flag.NewFlagSet(utils.CommandBoot, flag.ContinueOnError)
+	flags.diagCommand = flag.NewFlagSet(utils.CommandDiag, flag.ContinueOnError)
 
 	flags.amtCommand = amt.NewAMTCommand()
 	flags.netEnumerator = NetEnumerator{}
 	flags.netEnumerator.Interfaces = net.Interfaces
 	flags.netEnumerator.InterfaceAddrs = (*net.Interface).Addrs
+	flags.netEnumerator.VLANID = hostVLANID
+	flags.keyringGet = keyring.Get
+	flags.wlanProfiles = wlan.Profiles
 	flags.setupCommonFlags()
 
 	return flags
 }
 
-// ParseFlags is used for understanding the command line flags
+// ParseFlags is used for understanding the command line flags. It prints the
+// message of a failure and returns its return code, Parse returns the error itself.
 func (f *Flags) ParseFlags() utils.ReturnCode {
-	var rc utils.ReturnCode
+	err := f.Parse()
+	f.PrintError(err)
+	return rpcerr.ReturnCodeOf(err)
+}
+
+// PrintError prints the message of a failure of Parse in the selected language. It is not
+// printed with -json, the caller reports the failure in the JSON error envelope instead.
+func (f *Flags) PrintError(err error) {
+	var rpcErr *rpcerr.Error
+	if errors.As(err, &rpcErr) && rpcErr.Message != "" && !f.jsonErrors && !f.JsonOutput {
+		fmt.Println(rpcErr.TranslatedError())
+	}
+}
+
+// newError returns the failure of a command line with the message of the catalog id. The
+// message is English for the JSON output and the library, PrintError prints its translation.
+func newError(rc utils.ReturnCode, id string, args ...interface{}) *rpcerr.Error {
+	err := rpcerr.New(rc, i18n.English(id, args...))
+	err.Translation = i18n.T(id, args...)
+	return err
+}
+
+// wrapError returns the failure of a command line caused by another error, see newError
+func wrapError(rc utils.ReturnCode, cause error, id string, args ...interface{}) *rpcerr.Error {
+	err := newError(rc, id, args...)
+	err.Cause = cause
+	return err
+}
+
+// Parse reads the command line flags, a failure is returned as an *rpcerr.Error
+func (f *Flags) Parse() error {
+	f.jsonErrors = JSONRequested(f.commandLineArgs)
+	if err := f.selectLanguage(); err != nil {
+		return err
+	}
+	if err := f.selectNonInteractive(); err != nil {
+		return err
+	}
+	if err := f.selectOutput(); err != nil {
+		return err
+	}
+	if err := f.selectTransport(); err != nil {
+		return err
+	}
 	if len(f.commandLineArgs) > 1 {
 		f.Command = f.commandLineArgs[1]
 	}
+	if rc := f.loadFlagDefaults(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	var err error
 	switch f.Command {
 	case utils.CommandAMTInfo:
-		rc = f.handleAMTInfo(f.amtInfoCommand)
+		err = f.handleAMTInfo(f.amtInfoCommand)
 	case utils.CommandActivate:
-		rc = f.handleActivateCommand()
+		err = f.handleActivateCommand()
+	case utils.CommandAgent:
+		err = f.handleAgentCommand()
 	case utils.CommandDeactivate:
-		rc = f.handleDeactivateCommand()
+		err = f.handleDeactivateCommand()
 	case utils.CommandMaintenance:
-		rc = f.handleMaintenanceCommand()
+		err = f.handleMaintenanceCommand()
+	case utils.CommandReturnCodes:
+		err = f.handleReturnCodesCommand()
 	case utils.CommandVersion:
-		rc = f.handleVersionCommand()
+		err = f.handleVersionCommand()
 	case utils.CommandConfigure:
-		rc = f.handleConfigureCommand()
+		err = f.handleConfigureCommand()
+	case utils.CommandService:
+		err = f.handleServiceCommand()
+	case utils.CommandPower:
+		err = f.handlePowerCommand()
+	case utils.CommandStatus:
+		err = f.handleStatusCommand()
+	case utils.CommandCheckCert:
+		err = f.handleCheckCertCommand()
+	case utils.CommandWSMAN:
+		err = f.handleWSMANCommand()
+	case utils.CommandBulk:
+		err = f.handleBulkCommand()
+	case utils.CommandSelfTest:
+		err = f.handleSelfTestCommand()
+	case utils.CommandApply:
+		err = f.handleApplyCommand()
+	case utils.CommandHelp:
+		err = f.handleHelpCommand()
+	case utils.CommandSOL:
+		err = f.handleSOLCommand()
+	case utils.CommandBoot:
+		err = f.handleBootCommand()
+	case utils.CommandDiag:
+		err = f.handleDiagCommand()
 	default:
-		rc = utils.IncorrectCommandLineParameters
 		f.printUsage()
+		err = rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	if f.passwordErr != nil {
+		err = f.passwordErr
+	}
+	if err == nil {
+		err = f.validateConnection()
+	}
+	return err
+}
+
+// validateConnection checks the proxy, MQTT broker, telemetry endpoint, server TLS and
+// remote device the commands share
+func (f *Flags) validateConnection() error {
+	if f.Proxy != "" {
+		if rc := f.validateProxy(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	if f.MQTTBroker != "" {
+		if rc := f.validateMQTTBroker(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	if f.OTelEndpoint != "" {
+		if err := telemetry.ValidateEndpoint(f.OTelEndpoint); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidOTelEndpoint")
+		}
+	}
+	if f.ServerTLS.CACertFile != "" || f.ServerTLS.PinSHA256 != "" {
+		if rc := f.validateServerTLS(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	return f.validateRemote()
+}
+
+// validateProxy normalizes the proxy address to a URL, assuming http:// when no scheme is given
+func (f *Flags) validateProxy() utils.ReturnCode {
+	if !strings.Contains(f.Proxy, "://") {
+		f.Proxy = "http://" + f.Proxy
+	}
+	proxyURL, err := url.Parse(f.Proxy)
+	if err != nil || proxyURL.Host == "" {
+		log.Error("invalid proxy address: ", f.Proxy)
+		return utils.MissingProxyAddressAndPort
+	}
+	// the websocket dialer connects to http and socks5 proxies only, not over TLS
+	switch proxyURL.Scheme {
+	case "http", "socks5":
+	default:
+		log.Error("unsupported proxy scheme: ", proxyURL.Scheme, ", use http:// or socks5://")
+		return utils.IncorrectCommandLineParameters
+	}
+	return utils.Success
+}
+
+// selectLanguage takes -lang out of the arguments, it is accepted anywhere on the command
+// line so the usage texts printed before a command is parsed are translated as well. An
+// unsupported RPC_LANG falls back to English rather than failing every command.
+func (f *Flags) selectLanguage() error {
+	f.Language = i18n.DefaultLanguage
+	if f.request {
+		return nil
 	}
-	return rc
+	if language := f.lookupEnvOrString("RPC_LANG", ""); language != "" && i18n.SetLanguage(language) == nil {
+		f.Language = language
+	}
+	args := f.commandLineArgs[:0:0]
+	for i := 0; i < len(f.commandLineArgs); i++ {
+		arg := f.commandLineArgs[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if i == 0 || !strings.HasPrefix(arg, "-") || name != "lang" {
+			args = append(args, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(f.commandLineArgs) {
+				return newError(utils.IncorrectCommandLineParameters, "error.langValue")
+			}
+			i++
+			value = f.commandLineArgs[i]
+		}
+		f.Language = value
+	}
+	f.commandLineArgs = args
+	if err := i18n.SetLanguage(f.Language); err != nil {
+		return rpcerr.New(utils.IncorrectCommandLineParameters, err.Error())
+	}
+	return nil
+}
+
+// selectNonInteractive takes -nonInteractive out of the arguments, like -lang it applies to
+// every command. RPC_NON_INTERACTIVE=true sets it without changing the command lines.
+func (f *Flags) selectNonInteractive() error {
+	f.NonInteractive = f.request || f.lookupEnvOrBool("RPC_NON_INTERACTIVE", false)
+	args := f.commandLineArgs[:0:0]
+	for i, arg := range f.commandLineArgs {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if i == 0 || !strings.HasPrefix(arg, "-") || name != "nonInteractive" {
+			args = append(args, arg)
+			continue
+		}
+		f.NonInteractive = true
+		if hasValue {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return newError(utils.IncorrectCommandLineParameters, "error.nonInteractiveValue", value)
+			}
+			f.NonInteractive = enabled
+		}
+	}
+	f.commandLineArgs = args
+	return nil
+}
+
+// JSONRequested reports whether the command line has -json, it is read before the flags
+// are parsed so a failure to parse them is reported as JSON too
+func JSONRequested(args []string) bool {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if i == 0 || !strings.HasPrefix(arg, "-") || name != "json" {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		enabled, err := strconv.ParseBool(value)
+		return err == nil && enabled
+	}
+	return false
+}
+
+// selectOutput takes -output out of the arguments, like -lang it applies to every command. It
+// is given once per destination or as a comma separated list, RPC_OUTPUT sets it as well.
+func (f *Flags) selectOutput() error {
+	var destinations []string
+	args := f.commandLineArgs[:0:0]
+	for i := 0; i < len(f.commandLineArgs); i++ {
+		arg := f.commandLineArgs[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if i == 0 || !strings.HasPrefix(arg, "-") || name != "output" {
+			args = append(args, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(f.commandLineArgs) {
+				return newError(utils.IncorrectCommandLineParameters, "error.outputValue")
+			}
+			i++
+			value = f.commandLineArgs[i]
+		}
+		destinations = append(destinations, strings.Split(value, ",")...)
+	}
+	f.commandLineArgs = args
+	// the command line replaces RPC_OUTPUT
+	if value := f.lookupEnvOrString("RPC_OUTPUT", ""); value != "" && destinations == nil {
+		destinations = strings.Split(value, ",")
+	}
+	f.Output = nil
+	for _, destination := range destinations {
+		destination = strings.TrimSpace(destination)
+		if destination == "" {
+			continue
+		}
+		if err := output.CheckDestination(destination); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidOutput")
+		}
+		f.Output = append(f.Output, destination)
+	}
+	return nil
+}
+
+// selectTransport takes -transport out of the arguments, like -lang it applies to every
+// command that talks to the local AMT. RPC_TRANSPORT sets it as well, auto is the default.
+func (f *Flags) selectTransport() error {
+	value := f.lookupEnvOrString("RPC_TRANSPORT", string(lm.TransportAuto))
+	args := f.commandLineArgs[:0:0]
+	for i := 0; i < len(f.commandLineArgs); i++ {
+		arg := f.commandLineArgs[i]
+		name, argValue, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if i == 0 || !strings.HasPrefix(arg, "-") || name != "transport" {
+			args = append(args, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(f.commandLineArgs) {
+				return newError(utils.IncorrectCommandLineParameters, "error.transportValue")
+			}
+			i++
+			argValue = f.commandLineArgs[i]
+		}
+		value = argValue
+	}
+	f.commandLineArgs = args
+	transport, err := lm.ParseTransport(value)
+	if err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidTransport")
+	}
+	f.Transport = transport
+	return nil
+}
+
+// inputRequired logs the prompt -nonInteractive did not show and returns InputRequired
+func (f *Flags) inputRequired(prompt string) utils.ReturnCode {
+	log.Errorf("-nonInteractive does not prompt: %s", strings.TrimRight(prompt, ": "))
+	return utils.InputRequired
 }
 
 func (f *Flags) printUsage() string {
-	executable := filepath.Base(os.Args[0])
-	usage := "\nRemote Provisioning Client (RPC) - used for activation, deactivation, maintenance and status of AMT\n\n"
-	usage = usage + "Usage: " + executable + " COMMAND [OPTIONS]\n\n"
-	usage = usage + "Supported Commands:\n"
-	usage = usage + "  activate    Activate this device with a specified profile\n"
-	usage = usage + "              Example: " + executable + " activate -u wss://server/activate --profile acmprofile\n"
-	usage = usage + "  amtinfo     Displays information about AMT status and configuration\n"
-	usage = usage + "              Example: " + executable + " amtinfo\n"
-	usage = usage + "  configure   Local configuration of a feature on this device. AMT password is required\n"
-	usage = usage + "              Example: " + executable + " configure addwifisettings ...\n"
-	usage = usage + "  deactivate  Deactivates this device. AMT password is required\n"
-	usage = usage + "              Example: " + executable + " deactivate -u wss://server/activate\n"
-	usage = usage + "  maintenance Execute a maintenance task for the device. AMT password is required\n"
-	usage = usage + "              Example: " + executable + " maintenance syncclock -u wss://server/activate \n"
-	usage = usage + "  version     Displays the current version of RPC and the RPC Protocol version\n"
-	usage = usage + "              Example: " + executable + " version\n"
-	usage = usage + "\nRun '" + executable + " COMMAND' for more information on a command.\n"
-	fmt.Println(usage)
+	usage := topUsage.text()
+	f.printText(usage)
 	return usage
 }
 
@@ -174,36 +618,146 @@ func (f *Flags) setupCommonFlags() {
 	for _, fs := range []*flag.FlagSet{
 		f.amtActivateCommand,
 		f.amtDeactivateCommand,
+		f.amtAgentCommand,
 		f.amtMaintenanceChangePasswordCommand,
 		f.amtMaintenanceSyncDeviceInfoCommand,
 		f.amtMaintenanceSyncClockCommand,
 		f.amtMaintenanceSyncHostnameCommand,
-		f.amtMaintenanceSyncIPCommand} {
-		fs.StringVar(&f.URL, "u", "", "Websocket address of server to activate against") //required
+		f.amtMaintenanceSyncIPCommand,
+		f.amtMaintenanceSyncDNSCommand,
+		f.amtMaintenanceSyncWifiCommand,
+		f.amtMaintenanceBatchCommand} {
+		fs.Var(&urlListValue{url: &f.URL}, "u", urlUsage) //required
+		fs.Func("serverOrder", serverOrderUsage, f.setServerOrder)
 		fs.BoolVar(&f.SkipCertCheck, "n", false, "Skip Websocket server certificate verification")
+		f.setupServerTLSFlags(fs)
 		fs.StringVar(&f.Proxy, "p", "", "Proxy address and port")
+		fs.StringVar(&f.Proxy, "proxy", "", "Proxy URL (http:// or socks5://). HTTPS_PROXY and NO_PROXY are used when not set")
+		fs.StringVar(&f.ProxyUser, "proxyuser", "", "Proxy basic authentication user")
+		fs.StringVar(&f.ProxyPassword, "proxypassword", f.lookupEnvOrString("PROXY_PASSWORD", ""), "Proxy basic authentication password")
+		fs.IntVar(&f.Retries, "retries", 3, "Number of times to retry a failed connection to the server")
+		fs.DurationVar(&f.RetryDelay, "retryDelay", time.Second, "Delay before the first retry, doubled with jitter on each further retry (ex. '1s' or '500ms')")
 		fs.StringVar(&f.Token, "token", "", "JWT Token for Authorization")
 		fs.StringVar(&f.TenantID, "tenant", "", "TenantID")
+		fs.StringVar(&f.TenantID, "tenantId", "", "TenantID, same as -tenant")
+		fs.Var(tagsValue{f: f}, "tag", tagUsage)
 		fs.StringVar(&f.LMSAddress, "lmsaddress", utils.LMSAddress, "LMS address. Can be used to change location of LMS for debugging.")
 		fs.StringVar(&f.LMSPort, "lmsport", utils.LMSPort, "LMS port")
 		fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
-		fs.StringVar(&f.LogLevel, "l", "info", "Log level (panic,fatal,error,warn,info,debug,trace)")
+		fs.BoolVar(&f.VerboseProgress, "verbose-progress", false, "Show a progress indicator while the server configures AMT")
+		fs.DurationVar(&f.HeartbeatInterval, "heartbeat", 30*time.Second, "Interval of websocket pings that keep the server connection alive, 0 disables them")
+		fs.BoolVar(&f.NoCompression, "nocompression", false, "Do not negotiate permessage-deflate compression of the websocket messages")
+		fs.IntVar(&f.ChunkSize, "chunksize", 0, "Split response payloads larger than this many bytes into chunks the server reassembles, 0 sends them whole")
+		fs.BoolVar(&f.UploadLogs, "uploadLogs", false, "Send the log entries of the command to the server, which keeps them with the device for troubleshooting")
+		f.setupLogFlags(fs)
+		f.setupTimeoutFlag(fs)
+		f.setupTelemetryFlag(fs)
+		f.setupMQTTFlags(fs)
 		fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+		fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
 		fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+		fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+		fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
 		fs.DurationVar(&f.AMTTimeoutDuration, "t", 2*time.Minute, "AMT timeout - time to wait until AMT is ready (ex. '2m' or '30s')")
-		if fs.Name() != "activate" { // activate does not use the -f flag
+		if fs.Name() != "activate" { // activate does not use the -f flag and reads its local configuration with -config
 			fs.BoolVar(&f.Force, "f", false, "Force even if device is not registered with a server")
+			fs.String(defaultsFlag, "", defaultsUsage)
+		}
+		if fs.Name() != utils.CommandAgent { // the agent runs unattended, it is never a dry run
+			fs.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+		}
+	}
+}
+
+// setupLogFlags adds the flags selecting the log level, format and file
+func (f *Flags) setupLogFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.LogLevel, "l", "info", "Log level (panic,fatal,error,warn,info,debug,trace)")
+	fs.StringVar(&f.LogLevels, "loglevels", "", "Log level per module, ex. 'rps=debug,amt=trace' (modules: "+strings.Join(logging.Modules, ",")+")")
+	fs.StringVar(&f.LogFile, "logfile", "", "Write the log to this file instead of stderr")
+	fs.IntVar(&f.LogMaxSize, "logmaxsize", 10, "Size in MB at which the log file is rotated")
+	fs.BoolVar(&f.LogJSON, "logjson", false, "JSON log format")
+}
+
+// setupTimeoutFlag adds the flag bounding each MEI command, so a hung driver does not block the command
+func (f *Flags) setupTimeoutFlag(fs *flag.FlagSet) {
+	fs.DurationVar(&f.Timeout, "timeout", amt.DefaultTimeout, "Time to wait for each MEI command before failing with MEITimeout (ex. '30s'), 0 waits without limit")
+}
+
+// setupTelemetryFlag adds the flag selecting the OTLP endpoint that traces and metrics are exported to
+func (f *Flags) setupTelemetryFlag(fs *flag.FlagSet) {
+	fs.StringVar(&f.OTelEndpoint, "otel-endpoint", f.lookupEnvOrString("OTEL_EXPORTER_OTLP_ENDPOINT", ""), "OpenTelemetry collector to export traces and metrics to with OTLP over HTTP, ex. 'http://collector:4318'")
+}
+
+// setupMQTTFlags adds the flags selecting the MQTT broker that status events are published to
+func (f *Flags) setupMQTTFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.MQTTBroker, "mqttBroker", f.lookupEnvOrString("MQTT_BROKER", ""), "MQTT broker to publish operation status to, ex. 'tcp://broker:1883' or 'ssl://broker:8883'")
+	fs.StringVar(&f.MQTTTopic, "mqttTopic", mqtt.DefaultTopic, "MQTT topic of the status events")
+	fs.StringVar(&f.MQTTUser, "mqttUser", "", "MQTT broker user")
+	fs.StringVar(&f.MQTTPassword, "mqttPassword", f.lookupEnvOrString("MQTT_PASSWORD", ""), "MQTT broker password")
+}
+
+// validateMQTTBroker checks the broker address before any operation is started
+func (f *Flags) validateMQTTBroker() utils.ReturnCode {
+	if _, err := mqtt.NewPublisher(f.MQTTBroker, ""); err != nil {
+		log.Error("invalid mqtt broker: ", err)
+		return utils.MissingOrIncorrectMQTTBroker
+	}
+	return utils.Success
+}
+
+// Secrets returns the passwords and keys given on the command line or in the
+// configuration, they are redacted from the log
+func (f *Flags) Secrets() []string {
+	secrets := []string{
+		f.Password,
+		f.ProxyPassword,
+		f.StaticPassword,
+		f.MEBxPassword,
+		f.MQTTPassword,
+		f.ChangePassword.VaultToken,
+		f.Token,
+		f.CIRASettings.MPSPassword,
+		f.LocalConfig.Password,
+		f.LocalConfig.ACMSettings.AMTPassword,
+		f.LocalConfig.ACMSettings.ProvisioningCertPwd,
+	}
+	// the password of a smb: -config URL is logged with the URL
+	if strings.HasPrefix(f.configContent, "smb:") {
+		if u, err := url.Parse(f.configContent); err == nil {
+			if password, ok := u.User.Password(); ok {
+				secrets = append(secrets, password)
+			}
 		}
 	}
+	for _, device := range f.Bulk.Devices {
+		secrets = append(secrets, device.Password)
+		if device.Flags != nil {
+			secrets = append(secrets, device.Flags.Secrets()...)
+		}
+	}
+	for _, wifiConfig := range f.LocalConfig.WifiConfigs {
+		secrets = append(secrets, wifiConfig.PskPassphrase)
+	}
+	for _, ieee8021xConfig := range f.LocalConfig.Ieee8021xConfigs {
+		secrets = append(secrets, ieee8021xConfig.Password, ieee8021xConfig.PrivateKey)
+	}
+	return secrets
 }
 
 func (f *Flags) lookupEnvOrString(key string, defaultVal string) string {
+	// the options listed by help must not show the AMT_PASSWORD of the environment
+	if f.usageOnly || f.request {
+		return defaultVal
+	}
 	if val, ok := os.LookupEnv(key); ok {
 		return val
 	}
 	return defaultVal
 }
 func (f *Flags) lookupEnvOrBool(key string, defaultVal bool) bool {
+	if f.usageOnly || f.request {
+		return defaultVal
+	}
 	if val, ok := os.LookupEnv(key); ok {
 		parsedVal, err := strconv.ParseBool(val)
 		if err != nil {
@@ -215,27 +769,166 @@ func (f *Flags) lookupEnvOrBool(key string, defaultVal bool) bool {
 	return defaultVal
 }
 
+// PromptUserInput prompts for a secret of the configuration, it is redacted in the log
 func (f *Flags) PromptUserInput(prompt string, value *string) utils.ReturnCode {
+	if f.NonInteractive {
+		return f.inputRequired(prompt)
+	}
 	fmt.Println(prompt)
 	_, err := fmt.Scanln(value)
 	if err != nil {
 		log.Error(err)
 		return utils.InvalidUserInput
 	}
+	logging.Redact(*value)
 	return utils.Success
 }
 
+// ReadPasswordFromUser prompts for the AMT password. It reads it from the file given
+// with -passwordFile, from stdin without a prompt for -password -, or from the OS
+// keyring when -passwordFromKeyring is set, or from the secret store of changepassword
+// -vault instead. With -nonInteractive it fails with MissingOrIncorrectPassword instead
+// of prompting.
 func (f *Flags) ReadPasswordFromUser() (bool, utils.ReturnCode) {
+	switch {
+	case f.PasswordFile != "":
+		return f.readPasswordFile()
+	case f.Password == passwordFromStdin:
+		return f.readPasswordFromStdin()
+	case f.PasswordFromKeyring:
+		return f.readPasswordFromKeyring()
+	case f.ChangePassword.Vault != "":
+		return f.readPasswordFromVault()
+	}
+	if f.NonInteractive {
+		log.Error("the AMT password is required, -nonInteractive does not prompt for it. Use -password, -passwordFile or -passwordFromKeyring")
+		return false, utils.MissingOrIncorrectPassword
+	}
 	fmt.Println("Please enter AMT Password: ")
 	var password string
 	_, err := fmt.Scanln(&password)
 	if password == "" || err != nil {
 		return false, utils.MissingOrIncorrectPassword
 	}
+	logging.Redact(password)
+	f.Password = password
+	return true, utils.Success
+}
+
+// ConfirmPassword asks to enter the AMT password again before an action that can not be undone
+func (f *Flags) ConfirmPassword(action string) utils.ReturnCode {
+	if f.NonInteractive {
+		return f.inputRequired("Enter the AMT password again to " + action)
+	}
+	fmt.Printf("Enter the AMT password again to %s: \n", action)
+	var password string
+	_, err := fmt.Scanln(&password)
+	if err != nil || password == "" || password != f.Password {
+		fmt.Println("AMT password does not match")
+		return utils.MissingOrIncorrectPassword
+	}
+	return utils.Success
+}
+
+const fipsUsage = "Generate the password with the DRBG of a FIPS 140 validated crypto module, rpc fails when none is enabled"
+
+const dryRunUsage = "Check the command and print what would be sent to AMT or the server without changing anything"
+
+const keyringUsage = "Read the AMT password from the OS keyring (service '" + keyring.Service + "', account '" + keyring.Account + "') instead of prompting"
+
+// passwordFromStdin as the value of -password reads the password from stdin
+const passwordFromStdin = "-"
+
+const passwordFileUsage = "Read the AMT password from the first line of this file, it must not be readable by group or others"
+
+// readPasswordSource reads the password given with -password - or -passwordFile
+// right after parsing, so the commands only see the password itself. The file
+// takes precedence over AMT_PASSWORD, but not over -password on the command line.
+func (f *Flags) readPasswordSource(fs *flag.FlagSet) error {
+	if f.Password != passwordFromStdin && f.PasswordFile == "" {
+		return nil
+	}
+	if f.PasswordFile != "" {
+		fs.Visit(func(fl *flag.Flag) {
+			if fl.Name == "password" {
+				f.passwordErr = newError(utils.InvalidParameterCombination, "error.passwordOrPasswordFile")
+			}
+		})
+		if f.passwordErr != nil {
+			return f.passwordErr
+		}
+		f.Password = ""
+	}
+	if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+		f.passwordErr = rpcerr.FromReturnCode(rc)
+	}
+	return f.passwordErr
+}
+
+func (f *Flags) readPasswordFromStdin() (bool, utils.ReturnCode) {
+	password, err := readLine()
+	password = strings.TrimSuffix(password, "\r")
+	if err != nil || password == "" {
+		log.Error("unable to read AMT password from stdin: ", err)
+		f.Password = ""
+		return false, utils.MissingOrIncorrectPassword
+	}
+	logging.Redact(password)
+	f.Password = password
+	return true, utils.Success
+}
+
+func (f *Flags) readPasswordFile() (bool, utils.ReturnCode) {
+	info, err := os.Stat(f.PasswordFile)
+	if err != nil {
+		log.Error("unable to read AMT password file: ", err)
+		return false, utils.MissingOrIncorrectPassword
+	}
+	// Windows does not keep the permission bits, access is left to the ACL of the file
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		log.Errorf("AMT password file %s has permissions %s, it must not be accessible by group or others (chmod 600)", f.PasswordFile, info.Mode().Perm())
+		return false, utils.MissingOrIncorrectPassword
+	}
+	content, err := os.ReadFile(f.PasswordFile)
+	if err != nil {
+		log.Error("unable to read AMT password file: ", err)
+		return false, utils.MissingOrIncorrectPassword
+	}
+	password, _, _ := strings.Cut(string(content), "\n")
+	password = strings.TrimSuffix(password, "\r")
+	if password == "" {
+		log.Error("AMT password file is empty: ", f.PasswordFile)
+		return false, utils.MissingOrIncorrectPassword
+	}
+	logging.Redact(password)
 	f.Password = password
 	return true, utils.Success
 }
 
+func (f *Flags) readPasswordFromKeyring() (bool, utils.ReturnCode) {
+	password, err := f.keyringGet(keyring.Service, keyring.Account)
+	if err != nil {
+		log.Error("unable to read AMT password from keyring: ", err)
+		return false, utils.MissingOrIncorrectPassword
+	}
+	logging.Redact(password)
+	f.Password = password
+	return true, utils.Success
+}
+
+func (f *Flags) readPasswordFromVault() (bool, utils.ReturnCode) {
+	store, err := secretstore.Open(f.ChangePassword.Vault, f.ChangePassword.VaultToken)
+	if err == nil {
+		f.Password, err = store.Read()
+	}
+	if err != nil {
+		log.Errorf("unable to read AMT password from %s: %s", secretstore.Redact(f.ChangePassword.Vault), err)
+		return false, utils.MissingOrIncorrectPassword
+	}
+	logging.Redact(f.Password)
+	return true, utils.Success
+}
+
 func (f *Flags) handleLocalConfig() utils.ReturnCode {
 	if f.configContent == "" {
 		return utils.Success
@@ -243,12 +936,14 @@ func (f *Flags) handleLocalConfig() utils.ReturnCode {
 	if strings.HasPrefix(f.configContent, "smb:") {
 		ext := filepath.Ext(strings.ToLower(f.configContent))
 		isYaml := ext == ".yaml" || ext == ".yml"
+		isJson := ext == ".json"
 		isPfx := ext == ".pfx"
-		if !isYaml && !isPfx {
+		if !isYaml && !isJson && !isPfx {
 			log.Error("remote config unsupported smb file extension: ", ext)
 			return utils.FailedReadingConfiguration
 		}
 		smbService := smb.NewSambaService(f.configContent)
+		smbService.NonInteractive = f.NonInteractive
 		err := smbService.Fetch()
 		if err != nil {
 			log.Error("config error: ", err)
@@ -261,6 +956,13 @@ func (f *Flags) handleLocalConfig() utils.ReturnCode {
 				return utils.FailedReadingConfiguration
 			}
 		}
+		if isJson {
+			err := cleanenv.ParseJSON(bytes.NewReader(smbService.FileContents), &f.LocalConfig)
+			if err != nil {
+				log.Error("config error: ", err)
+				return utils.FailedReadingConfiguration
+			}
+		}
 		if isPfx {
 			f.LocalConfig.ACMSettings.ProvisioningCert = base64.StdEncoding.EncodeToString(smbService.FileContents)
 		}
@@ -0,0 +1,59 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+type HelpFlags struct {
+	// Text is the usage of rpc, of a command or of a subcommand
+	Text string
+}
+
+func (f *Flags) handleHelpCommand() error {
+	if err := f.parse(f.helpCommand, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	path := f.helpCommand.Args()
+	switch {
+	case len(path) == 0:
+		f.Help.Text = topUsage.text()
+	case len(path) == 1 && usageGroups[path[0]] != nil:
+		f.Help.Text = usageGroups[path[0]].text()
+	default:
+		command, ok := findCommandUsage(path)
+		if !ok {
+			f.printUsage()
+			return newError(utils.IncorrectCommandLineParameters, "error.help.unknownCommand", strings.Join(path, " "))
+		}
+		f.Help.Text = f.commandHelp(path, command)
+	}
+	// runs locally
+	f.Local = true
+	return nil
+}
+
+// findCommandUsage looks up a command, or the subcommand of a command in usageGroups
+func findCommandUsage(path []string) (commandUsage, bool) {
+	group := &topUsage
+	switch len(path) {
+	case 1:
+	case 2:
+		if group = usageGroups[path[0]]; group == nil {
+			return commandUsage{}, false
+		}
+	default:
+		return commandUsage{}, false
+	}
+	for _, command := range group.Commands {
+		if command.Name == path[len(path)-1] {
+			return command, true
+		}
+	}
+	return commandUsage{}, false
+}
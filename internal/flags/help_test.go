@@ -0,0 +1,134 @@
+package flags
+
+import (
+	"flag"
+	"os"
+	"rpc/internal/i18n"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHelpCommand(t *testing.T) {
+	t.Run("without a command shows the usage of rpc", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "help"})
+		assert.NoError(t, f.Parse())
+		assert.True(t, f.Local)
+		assert.Equal(t, topUsage.text(), f.Help.Text)
+	})
+	t.Run("a command with subcommands shows their usage", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "help", "maintenance"})
+		assert.NoError(t, f.Parse())
+		assert.Equal(t, maintenanceUsage.text(), f.Help.Text)
+	})
+	t.Run("a subcommand lists its examples, options and return codes", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "help", "maintenance", "syncclock"})
+		assert.NoError(t, f.Parse())
+		assert.Contains(t, f.Help.Text, "maintenance syncclock [OPTIONS]")
+		assert.Contains(t, f.Help.Text, "Example: ")
+		assert.Contains(t, f.Help.Text, "\nOptions:\n")
+		assert.Contains(t, f.Help.Text, "  -ntp string\n")
+		assert.Contains(t, f.Help.Text, "\nReturn codes:\n")
+		assert.Contains(t, f.Help.Text, "  150   SyncClockFailed")
+		assert.Contains(t, f.Help.Text, "  28    IncorrectCommandLineParameters")
+	})
+	t.Run("options are the flags registered by the handler", func(t *testing.T) {
+		f := NewFlags([]string{"./rpc", "help", "power", "reset"})
+		assert.NoError(t, f.Parse())
+		assert.Contains(t, f.Help.Text, "  -bootToBIOS\n")
+		assert.Contains(t, f.Help.Text, "  121   PowerActionFailed")
+	})
+	t.Run("options do not show the AMT password of the environment", func(t *testing.T) {
+		os.Setenv("AMT_PASSWORD", "FromTheEnvironment")
+		defer os.Unsetenv("AMT_PASSWORD")
+		f := NewFlags([]string{"./rpc", "help", "amtinfo"})
+		assert.NoError(t, f.Parse())
+		assert.Contains(t, f.Help.Text, "  -password string\n")
+		assert.NotContains(t, f.Help.Text, "FromTheEnvironment")
+	})
+	t.Run("keeps the language of the command line", func(t *testing.T) {
+		defer i18n.SetLanguage(i18n.DefaultLanguage)
+		f := NewFlags([]string{"./rpc", "help", "selftest", "-lang", "de"})
+		assert.NoError(t, f.Parse())
+		assert.Contains(t, f.Help.Text, "Rückgabecodes:")
+		assert.Equal(t, "de", i18n.Language())
+	})
+	t.Run("translates the options", func(t *testing.T) {
+		defer i18n.SetLanguage(i18n.DefaultLanguage)
+		f := NewFlags([]string{"./rpc", "help", "maintenance", "syncclock", "-lang", "es"})
+		assert.NoError(t, f.Parse())
+		assert.Contains(t, f.Help.Text, "  -json\n    \tSalida JSON\n")
+		assert.NotContains(t, f.Help.Text, "JSON output")
+	})
+	t.Run("an unknown command fails", func(t *testing.T) {
+		for _, args := range [][]string{{"bogus"}, {"configure", "bogus"}, {"version", "bogus"}, {"power", "on", "bogus"}} {
+			f := NewFlags(append([]string{"./rpc", "help"}, args...))
+			assert.Equal(t, utils.IncorrectCommandLineParameters, rpcerr.ReturnCodeOf(f.Parse()), args)
+		}
+	})
+}
+
+func TestUsageGroupsListEveryCommand(t *testing.T) {
+	// every command and subcommand described for help must be handled by Parse
+	for _, command := range topUsage.Commands {
+		if usageGroups[command.Name] != nil {
+			continue
+		}
+		f := NewFlags([]string{"./rpc", command.Name, "-h"})
+		f.usageOnly = true
+		_ = f.Parse()
+		assert.NotNil(t, f.parsedFlagSet, command.Name)
+	}
+	for name, group := range usageGroups {
+		for _, command := range group.Commands {
+			f := NewFlags([]string{"./rpc", name, command.Name, "-h"})
+			f.usageOnly = true
+			_ = f.Parse()
+			assert.NotNil(t, f.parsedFlagSet, name+" "+command.Name)
+		}
+	}
+}
+
+func TestEveryOptionIsTranslated(t *testing.T) {
+	defer i18n.SetLanguage(i18n.DefaultLanguage)
+	paths := [][]string{}
+	for _, command := range topUsage.Commands {
+		if group := usageGroups[command.Name]; group != nil {
+			for _, sub := range group.Commands {
+				paths = append(paths, []string{command.Name, sub.Name})
+			}
+			continue
+		}
+		paths = append(paths, []string{command.Name})
+	}
+	// the English usage of the flags translated as flag.NAME, which must be the same
+	// wherever the flag is shared, a command with another usage has flag.COMMAND.NAME
+	shared := map[string]string{}
+	for _, path := range paths {
+		f := NewFlags(append(append([]string{"./rpc"}, path...), "-h"))
+		f.usageOnly = true
+		_ = f.Parse()
+		if f.parsedFlagSet == nil {
+			continue
+		}
+		command := f.parsedFlagSet.Name()
+		f.parsedFlagSet.VisitAll(func(fl *flag.Flag) {
+			for _, language := range []string{"es", "de"} {
+				assert.NoError(t, i18n.SetLanguage(language))
+				_, ok := i18n.FlagUsage(command, fl.Name)
+				assert.True(t, ok, "%s has no usage of -%s of %v", language, fl.Name, path)
+			}
+			_, specific := i18n.Lookup("flag." + command + "." + fl.Name)
+			assert.NoError(t, i18n.SetLanguage(i18n.DefaultLanguage))
+			if specific {
+				return
+			}
+			if usage, ok := shared[fl.Name]; ok {
+				assert.Equal(t, usage, fl.Usage, "-%s of %v needs flag.%s.%s", fl.Name, path, command, fl.Name)
+			}
+			shared[fl.Name] = fl.Usage
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// maxAMTHostnameLength is the longest host name AMT accepts in AMT_GeneralSettings
+const maxAMTHostnameLength = 63
+
+// hostnamePlaceholder is replaced by the host name in -template
+const hostnamePlaceholder = "{hostname}"
+
+// SyncHostnameFlags select how the OS host name is turned into the host name synced to AMT,
+// without any of them the OS host name is synced as it is
+type SyncHostnameFlags struct {
+	// Short syncs the host name without its domain
+	Short bool
+	// FQDN syncs the host name with the DNS suffix of the OS
+	FQDN      bool
+	Lowercase bool
+	// Validate fails on names AMT does not accept, longer than it accepts or with
+	// other characters than letters, digits and hyphens
+	Validate bool
+	// Truncate shortens names longer than AMT accepts
+	Truncate bool
+	// Template adds a prefix or suffix around {hostname}, ex. 'amt-{hostname}'
+	Template string
+}
+
+// setupSyncHostnameFlags adds the flags of the host name policy
+func (f *Flags) setupSyncHostnameFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.SyncHostname.Short, "short", false, "Sync the host name without its domain")
+	fs.BoolVar(&f.SyncHostname.FQDN, "fqdn", false, "Sync the fully qualified host name with the DNS suffix of the OS")
+	fs.BoolVar(&f.SyncHostname.Lowercase, "lowercase", false, "Sync the host name in lowercase")
+	fs.BoolVar(&f.SyncHostname.Validate, "validate", false, fmt.Sprintf("Fail on host names longer than the %d characters AMT accepts or with other characters than letters, digits and '-'", maxAMTHostnameLength))
+	fs.BoolVar(&f.SyncHostname.Truncate, "truncate", false, fmt.Sprintf("Shorten host names longer than the %d characters AMT accepts", maxAMTHostnameLength))
+	fs.Func("template", "Prefix and suffix around the host name, ex. 'amt-"+hostnamePlaceholder+"-lab'", func(val string) error {
+		if strings.Count(val, hostnamePlaceholder) != 1 {
+			return errors.New("the template must contain " + hostnamePlaceholder + " once")
+		}
+		f.SyncHostname.Template = val
+		return nil
+	})
+}
+
+// Apply returns the host name synced to AMT for the OS host name and DNS suffix
+func (p SyncHostnameFlags) Apply(hostname string, dnsSuffix string) (string, error) {
+	if p.Short && p.FQDN {
+		return "", errors.New("-short and -fqdn can not be used together")
+	}
+	name := hostname
+	if p.Short {
+		name, _, _ = strings.Cut(hostname, ".")
+	}
+	if p.FQDN {
+		switch {
+		case strings.Contains(hostname, "."):
+			name = hostname
+		case dnsSuffix != "":
+			name = hostname + "." + strings.TrimPrefix(dnsSuffix, ".")
+		default:
+			return "", errors.New("-fqdn needs the DNS suffix of the OS, none is available")
+		}
+	}
+	prefix, suffix := "", ""
+	if p.Template != "" {
+		prefix, suffix, _ = strings.Cut(p.Template, hostnamePlaceholder)
+	}
+	if length := len(prefix) + len(name) + len(suffix); length > maxAMTHostnameLength && (p.Truncate || p.Validate) {
+		if !p.Truncate {
+			return "", fmt.Errorf("host name %q is %d characters, AMT accepts %d, use -truncate to shorten it", prefix+name+suffix, length, maxAMTHostnameLength)
+		}
+		available := maxAMTHostnameLength - len(prefix) - len(suffix)
+		if available <= 0 {
+			return "", fmt.Errorf("the template leaves no room for the host name within %d characters", maxAMTHostnameLength)
+		}
+		// a label can not end with a hyphen or be empty
+		name = strings.TrimRight(name[:available], "-.")
+	}
+	name = prefix + name + suffix
+	if p.Lowercase {
+		name = strings.ToLower(name)
+	}
+	if p.Validate {
+		if err := validateHostname(name); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// validateHostname checks that AMT accepts the name: labels of letters, digits
+// and hyphens that do not start or end with a hyphen
+func validateHostname(name string) error {
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("host name %q has an empty label or a label starting or ending with '-'", name)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return fmt.Errorf("host name %q contains %q, AMT accepts letters, digits and '-'", name, c)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncHostnameApply(t *testing.T) {
+	long := strings.Repeat("a", 60)
+	tests := map[string]struct {
+		policy    SyncHostnameFlags
+		hostname  string
+		dnsSuffix string
+		want      string
+		wantErr   bool
+	}{
+		"syncs the OS name as it is by default": {
+			hostname:  "Host_1.corp.example.com",
+			dnsSuffix: "corp.example.com",
+			want:      "Host_1.corp.example.com",
+		},
+		"strips the domain for short": {
+			policy:    SyncHostnameFlags{Short: true},
+			hostname:  "Host1.corp.example.com",
+			dnsSuffix: "corp.example.com",
+			want:      "Host1",
+		},
+		"fails short with fqdn": {
+			policy:   SyncHostnameFlags{Short: true, FQDN: true},
+			hostname: "host1",
+			wantErr:  true,
+		},
+		"adds the DNS suffix for fqdn": {
+			policy:    SyncHostnameFlags{FQDN: true},
+			hostname:  "host1",
+			dnsSuffix: "corp.example.com",
+			want:      "host1.corp.example.com",
+		},
+		"keeps a fully qualified OS name for fqdn": {
+			policy:   SyncHostnameFlags{FQDN: true},
+			hostname: "host1.lab.example.com",
+			want:     "host1.lab.example.com",
+		},
+		"fails fqdn without a DNS suffix": {
+			policy:   SyncHostnameFlags{FQDN: true},
+			hostname: "host1",
+			wantErr:  true,
+		},
+		"lowercases with the template": {
+			policy:   SyncHostnameFlags{Lowercase: true, Template: "AMT-{hostname}-Lab"},
+			hostname: "HOST1",
+			want:     "amt-host1-lab",
+		},
+		"keeps a long name without validate": {
+			policy:   SyncHostnameFlags{Template: "amt-{hostname}"},
+			hostname: long,
+			want:     "amt-" + long,
+		},
+		"fails on a long name with validate": {
+			policy:   SyncHostnameFlags{Validate: true, Template: "amt-{hostname}"},
+			hostname: long,
+			wantErr:  true,
+		},
+		"truncates the name and keeps the template": {
+			policy:   SyncHostnameFlags{Truncate: true, Template: "amt-{hostname}-x"},
+			hostname: long,
+			want:     "amt-" + long[:57] + "-x",
+		},
+		"does not end a truncated label with a hyphen": {
+			policy:   SyncHostnameFlags{Truncate: true},
+			hostname: strings.Repeat("b", 62) + "-c",
+			want:     strings.Repeat("b", 62),
+		},
+		"fails on characters AMT rejects with validate": {
+			policy:   SyncHostnameFlags{Validate: true},
+			hostname: "host_1",
+			wantErr:  true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.policy.Apply(tc.hostname, tc.dnsSuffix)
+			assert.Equal(t, tc.wantErr, err != nil, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSyncHostnameFlags(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantPolicy SyncHostnameFlags
+	}{
+		"parses the policy": {
+			cmdLine:    "./rpc maintenance synchostname -u wss://localhost -password P@ssw0rd -short -lowercase -validate -truncate -template amt-{hostname}",
+			wantResult: utils.Success,
+			wantPolicy: SyncHostnameFlags{Short: true, Lowercase: true, Validate: true, Truncate: true, Template: "amt-{hostname}"},
+		},
+		"fails on a template without the placeholder": {
+			cmdLine:    "./rpc maintenance synchostname -u wss://localhost -password P@ssw0rd -template amt-host",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			assert.Equal(t, tc.wantResult, flags.ParseFlags())
+			assert.Equal(t, tc.wantPolicy, flags.SyncHostname)
+		})
+	}
+}
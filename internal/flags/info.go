@@ -2,10 +2,18 @@ package flags
 
 import (
 	"flag"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"strings"
+	"time"
 )
 
+// DefaultInfoCacheTTL is how long amtinfo uses the values of the MEI it cached
+const DefaultInfoCacheTTL = time.Hour
+
 type AmtInfoFlags struct {
+	// All selects every value that needs no AMT password, except -warn-only and -seccheck
+	All      bool
 	Ver      bool
 	Bld      bool
 	Sku      bool
@@ -17,9 +25,48 @@ type AmtInfoFlags struct {
 	Ras      bool
 	Lan      bool
 	Hostname bool
+	OpState  bool
+	// Hardware reads the manufacturer, model, serial number, asset tag, CPU and memory from SMBIOS
+	Hardware bool
+	// BIOS reads the BIOS vendor, version and release date from SMBIOS and the ME firmware versions
+	BIOS bool
+	// Sys reports the host OS, the rpc version and whether the WS-MAN interface of the
+	// transport or of -host accepts connections
+	Sys      bool
+	Audit    bool
+	EventLog bool
+	// Redirection reads the KVM, SOL and IDE-R redirection state, it needs the AMT password
+	Redirection bool
+	// EventLogClear clears the event log after reading it, the AMT password must be entered again
+	EventLogClear bool
+	// RasDetails adds the CIRA configuration to -ras, it needs the AMT password
+	RasDetails bool
+	// RasProbe connects to the MPS servers from the host OS, implies -ras
+	RasProbe bool
+	// Modes decodes whether the firmware settings allow CCM and ACM activation
+	Modes bool
+	// CertWarnOnly limits -cert to the hashes of deprecated CAs
+	CertWarnOnly bool
+	// SecCheck compares the firmware version with the Intel security advisories
+	SecCheck bool
+	// Advisories is the JSON file of the advisory table, the table rpc was built with when empty
+	Advisories string
+	// paging of the audit and event log records, a count of 0 reads all records
+	AuditCount  int
+	AuditOffset int
+}
+
+// InfoCacheFlags control the cache of the MEI values of amtinfo that only change with a
+// firmware update, kept apart from AmtInfoFlags as they select no value
+type InfoCacheFlags struct {
+	// NoCache reads every value from the MEI, the values read are cached again
+	NoCache bool
+	// TTL is how long the cached values are used, 0 turns the cache off
+	TTL time.Duration
 }
 
-func (f *Flags) handleAMTInfo(amtInfoCommand *flag.FlagSet) utils.ReturnCode {
+// setupAMTInfoFlags registers the flags of amtinfo, which sets their defaults
+func (f *Flags) setupAMTInfoFlags(amtInfoCommand *flag.FlagSet) {
 	// runs locally
 	f.Local = true
 
@@ -28,23 +75,72 @@ func (f *Flags) handleAMTInfo(amtInfoCommand *flag.FlagSet) utils.ReturnCode {
 	amtInfoCommand.BoolVar(&f.AmtInfo.Sku, "sku", false, "Product SKU")
 	amtInfoCommand.BoolVar(&f.AmtInfo.UUID, "uuid", false, "Unique Identifier")
 	amtInfoCommand.BoolVar(&f.AmtInfo.Mode, "mode", false, "Current Control Mode")
-	amtInfoCommand.BoolVar(&f.AmtInfo.DNS, "dns", false, "Domain Name Suffix")
+	amtInfoCommand.BoolVar(&f.AmtInfo.DNS, "dns", false, "PKI DNS suffix of AMT and the DNS suffix of the OS")
 	amtInfoCommand.BoolVar(&f.AmtInfo.Cert, "cert", false, "System Certificate Hashes (and User Certificates if AMT password is provided)")
+	amtInfoCommand.BoolVar(&f.AmtInfo.CertWarnOnly, "warn-only", false, "Only the certificate hashes of deprecated (SHA1) CAs, implies -cert")
 	amtInfoCommand.BoolVar(&f.AmtInfo.UserCert, "userCert", false, "User Certificates only. AMT password is required")
-	amtInfoCommand.BoolVar(&f.AmtInfo.Ras, "ras", false, "Remote Access Status")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Ras, "ras", false, "Remote Access Status (and MPS servers, environment detection and triggers if AMT password is provided)")
+	amtInfoCommand.BoolVar(&f.AmtInfo.RasProbe, "probe", false, "Connect to the MPS servers from the host OS and report reachability, TLS handshake and latency, implies -ras")
 	amtInfoCommand.BoolVar(&f.AmtInfo.Lan, "lan", false, "LAN Settings")
 	amtInfoCommand.BoolVar(&f.AmtInfo.Hostname, "hostname", false, "OS Hostname")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Hardware, "hw", false, "Hardware inventory from SMBIOS: manufacturer, model, serial number, asset tag, CPU and memory")
+	amtInfoCommand.BoolVar(&f.AmtInfo.BIOS, "bios", false, "BIOS vendor, version and release date from SMBIOS, and the ME firmware, recovery and security versions")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Sys, "sys", false, "Host OS name, version and build, architecture, rpc version, and whether LMS or the AMT of -host accepts connections")
+	amtInfoCommand.BoolVar(&f.AmtInfo.OpState, "opstate", false, "AMT Operational State (enabled in MEBx) and Provisioning State")
+	amtInfoCommand.BoolVar(&f.AmtInfo.SecCheck, "seccheck", false, "Compare the firmware version with the Intel security advisories (INTEL-SA) and report whether it is affected")
+	amtInfoCommand.StringVar(&f.AmtInfo.Advisories, "advisories", "", "JSON file of the advisory table -seccheck uses instead of the table rpc was built with")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Modes, "modes", false, "Whether the firmware settings allow CCM and ACM activation: AMT state and change from OS, remote configuration, provisioning TLS mode and active root hashes")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Audit, "audit", false, "AMT Audit Log. AMT password is required")
+	amtInfoCommand.BoolVar(&f.AmtInfo.EventLog, "eventlog", false, "AMT Event Log. AMT password is required")
+	amtInfoCommand.BoolVar(&f.AmtInfo.Redirection, "kvm", false, "KVM, SOL and IDE-R redirection state and redirection listener. AMT password is required")
+	amtInfoCommand.BoolVar(&f.AmtInfo.EventLogClear, "clear", false, "Clear the AMT Event Log after displaying all its records, the AMT password must be entered again. Requires -eventlog, not allowed with -count or -offset")
+	amtInfoCommand.IntVar(&f.AmtInfo.AuditCount, "count", 0, "Maximum number of audit or event log records to display, 0 displays all records")
+	amtInfoCommand.IntVar(&f.AmtInfo.AuditOffset, "offset", 0, "Number of audit or event log records to skip")
+	amtInfoCommand.BoolVar(&f.InfoCache.NoCache, "nocache", false, "Read the version, build, SKU, UUID and certificate hashes from the MEI instead of the cache, and cache them again")
+	amtInfoCommand.DurationVar(&f.InfoCache.TTL, "cacheTTL", DefaultInfoCacheTTL, "How long the version, build, SKU, UUID and certificate hashes read from the MEI are cached (ex. '1h' or '10m'), 0 turns the cache off")
+	amtInfoCommand.BoolVar(&f.AmtInfo.All, "all", false, "All information, including certificate hashes, operational state, provisioning modes, hardware inventory, BIOS and host OS")
 	amtInfoCommand.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT Password")
+	amtInfoCommand.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	amtInfoCommand.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	f.setupTimeoutFlag(amtInfoCommand)
+	f.setupTelemetryFlag(amtInfoCommand)
+	f.setupRemoteFlags(amtInfoCommand)
+	f.setupMQTTFlags(amtInfoCommand)
+	amtInfoCommand.String(defaultsFlag, "", defaultsUsage)
+}
 
-	if err := amtInfoCommand.Parse(f.commandLineArgs[2:]); err != nil {
-		return utils.IncorrectCommandLineParameters
+func (f *Flags) handleAMTInfo(amtInfoCommand *flag.FlagSet) error {
+	f.setupAMTInfoFlags(amtInfoCommand)
+	if err := f.parseWithDefaults(amtInfoCommand, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
+	// output formats from the defaults file or environment are not on the command line,
+	// neither are the flags that select no value
+	defaultFlagCount := 2 + f.countFlagArgs(amtInfoCommand, amtInfoOptionFlags)
+	return f.validateAMTInfo(amtInfoCommand, len(f.commandLineArgs) == defaultFlagCount)
+}
 
-	defaultFlagCount := 2
-	if f.JsonOutput {
-		defaultFlagCount = defaultFlagCount + 1
+// validateAMTInfo checks the paging and the options of the selected values. Without a
+// selection, defaults selects the values shown by default.
+func (f *Flags) validateAMTInfo(amtInfoCommand *flag.FlagSet, defaults bool) error {
+	if f.AmtInfo.AuditCount < 0 || f.AmtInfo.AuditOffset < 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.info.negativeCount")
+	}
+	if f.InfoCache.TTL < 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.info.negativeCacheTTL")
+	}
+	if f.AmtInfo.EventLogClear && !f.AmtInfo.EventLog {
+		return newError(utils.IncorrectCommandLineParameters, "error.info.clearWithoutEventlog")
 	}
-	if len(f.commandLineArgs) == defaultFlagCount {
+	// ClearLog removes every record, not only the ones displayed
+	if f.AmtInfo.EventLogClear && (f.AmtInfo.AuditCount > 0 || f.AmtInfo.AuditOffset > 0) {
+		return newError(utils.IncorrectCommandLineParameters, "error.info.clearWithCount")
+	}
+	if f.AmtInfo.Advisories != "" && !f.AmtInfo.SecCheck {
+		return newError(utils.IncorrectCommandLineParameters, "error.info.advisoriesWithoutSeccheck")
+	}
+
+	if f.AmtInfo.All || defaults {
 		f.AmtInfo.Ver = true
 		f.AmtInfo.Bld = true
 		f.AmtInfo.Sku = true
@@ -55,15 +151,104 @@ func (f *Flags) handleAMTInfo(amtInfoCommand *flag.FlagSet) utils.ReturnCode {
 		f.AmtInfo.Lan = true
 		f.AmtInfo.Hostname = true
 	}
+	if f.AmtInfo.All {
+		f.AmtInfo.Cert = true
+		f.AmtInfo.OpState = true
+		f.AmtInfo.Modes = true
+		f.AmtInfo.Hardware = true
+		f.AmtInfo.BIOS = true
+		f.AmtInfo.Sys = true
+	}
+	if f.AmtInfo.CertWarnOnly {
+		f.AmtInfo.Cert = true
+	}
+	if f.AmtInfo.RasProbe {
+		f.AmtInfo.Ras = true
+	}
+
+	if f.IsRemote() {
+		if err := f.handleRemoteAMTInfo(amtInfoCommand); err != nil {
+			return err
+		}
+	}
 
 	// no password - same behavior only cert hashes
 	// with password - shows user certs too
 	if f.AmtInfo.Cert && f.Password != "" {
 		f.AmtInfo.UserCert = true
 	}
+	// same for the CIRA configuration
+	if f.AmtInfo.Ras && f.Password != "" {
+		f.AmtInfo.RasDetails = true
+	}
 
-	// NOTE: UserCert and password check happen later
+	// NOTE: UserCert, Audit, EventLog, Redirection and password check happen later
 	// when provisioning mode is available
 
-	return utils.Success
+	return nil
+}
+
+// amtInfoOptionFlags are the amtinfo flags that do not select a value, the default values
+// are shown with them
+var amtInfoOptionFlags = map[string]bool{
+	"json": true, "yaml": true, "password": true, "passwordFile": true, "passwordFromKeyring": true,
+	"host": true, "amtPort": true, "tls": true, "user": true, "amtCACert": true, "skipAMTCertCheck": true,
+	"nocache": true, "cacheTTL": true,
+}
+
+// countFlagArgs returns the number of command line arguments taken by the named flags
+func (f *Flags) countFlagArgs(fs *flag.FlagSet, names map[string]bool) int {
+	count := 0
+	fs.Visit(func(fl *flag.Flag) {
+		if !names[fl.Name] {
+			return
+		}
+		count++
+		if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			return
+		}
+		// -name value takes two arguments, -name=value one
+		for _, arg := range f.commandLineArgs {
+			if strings.HasPrefix(strings.TrimLeft(arg, "-"), fl.Name+"=") {
+				return
+			}
+		}
+		count++
+	})
+	return count
+}
+
+// remoteAMTInfoFlags are the amtinfo flags that read the MEI or the host OS, which a
+// remote device does not offer over wsman. -sys only reports the rpc version and whether
+// the device accepts connections.
+var remoteAMTInfoFlags = []string{"dns", "lan", "hostname", "hw", "bios", "opstate", "modes"}
+
+// handleRemoteAMTInfo drops the values a remote device can not report and reads the AMT
+// password, every value of a remote device is read with it
+func (f *Flags) handleRemoteAMTInfo(amtInfoCommand *flag.FlagSet) error {
+	amtInfoCommand.Visit(func(fl *flag.Flag) {
+		for _, name := range remoteAMTInfoFlags {
+			if fl.Name == name {
+				log.Warnf("-%s is not available for a remote device", name)
+			}
+		}
+	})
+	f.AmtInfo.DNS = false
+	f.AmtInfo.Lan = false
+	f.AmtInfo.Hostname = false
+	f.AmtInfo.Hardware = false
+	f.AmtInfo.BIOS = false
+	f.AmtInfo.OpState = false
+	f.AmtInfo.Modes = false
+	// the certificate hashes are read from the MEI, the user certificates with wsman
+	if f.AmtInfo.Cert {
+		f.AmtInfo.Cert = false
+		f.AmtInfo.UserCert = true
+	}
+	if f.Password == "" {
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"rpc/pkg/utils"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseFlagsAmtInfo(t *testing.T) {
@@ -31,6 +32,73 @@ func TestParseFlagsAmtInfo(t *testing.T) {
 			wantResult: utils.Success,
 			wantFlags:  defaultFlags,
 		},
+		"expect success for basic command with yaml": {
+			cmdLine:    "./rpc amtinfo -yaml",
+			wantResult: utils.Success,
+			wantFlags:  defaultFlags,
+		},
+		"expect all flags with -all": {
+			cmdLine:    "./rpc amtinfo -all -json",
+			wantResult: utils.Success,
+			wantFlags: AmtInfoFlags{
+				All:      true,
+				Ver:      true,
+				Bld:      true,
+				Sku:      true,
+				UUID:     true,
+				Mode:     true,
+				DNS:      true,
+				Cert:     true,
+				Ras:      true,
+				Lan:      true,
+				Hostname: true,
+				OpState:  true,
+				Modes:    true,
+				Hardware: true,
+				BIOS:     true,
+				Sys:      true,
+			},
+		},
+		"expect only opstate with -opstate": {
+			cmdLine:    "./rpc amtinfo -opstate",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{OpState: true},
+		},
+		"expect only modes with -modes": {
+			cmdLine:    "./rpc amtinfo -modes",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Modes: true},
+		},
+		"expect audit with paging": {
+			cmdLine:    "./rpc amtinfo -audit -count 20 -offset 10 -password testPassword",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Audit: true, AuditCount: 20, AuditOffset: 10},
+		},
+		"expect eventlog with clear": {
+			cmdLine:    "./rpc amtinfo -eventlog -clear -password testPassword",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{EventLog: true, EventLogClear: true},
+		},
+		"expect IncorrectCommandLineParameters for clear with count": {
+			cmdLine:    "./rpc amtinfo -eventlog -clear -count 50 -password testPassword",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantFlags:  AmtInfoFlags{EventLog: true, EventLogClear: true, AuditCount: 50},
+		},
+		"expect IncorrectCommandLineParameters for clear with offset": {
+			cmdLine:    "./rpc amtinfo -eventlog -clear -offset 10 -password testPassword",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantFlags:  AmtInfoFlags{EventLog: true, EventLogClear: true, AuditOffset: 10},
+		},
+		"expect IncorrectCommandLineParameters for clear without eventlog": {
+			cmdLine:    "./rpc amtinfo -clear -password testPassword",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantFlags:  AmtInfoFlags{EventLogClear: true},
+		},
+		"expect IncorrectCommandLineParameters on negative audit count": {
+			cmdLine:    "./rpc amtinfo -audit -count -1",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantFlags:  AmtInfoFlags{Audit: true, AuditCount: -1},
+		},
 		"expect IncorrectCommandLineParameters on Parse error": {
 			cmdLine:    "./rpc amtinfo -balderdash",
 			wantResult: utils.IncorrectCommandLineParameters,
@@ -51,6 +119,56 @@ func TestParseFlagsAmtInfo(t *testing.T) {
 				UserCert: true,
 			},
 		},
+		"expect cert with -warn-only": {
+			cmdLine:    "./rpc amtinfo -warn-only",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Cert: true, CertWarnOnly: true},
+		},
+		"expect only ras flag with no password on command line": {
+			cmdLine:    "./rpc amtinfo -ras",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Ras: true},
+		},
+		"expect ras details with password on command line": {
+			cmdLine:    "./rpc amtinfo -ras -password testPassword",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Ras: true, RasDetails: true},
+		},
+		"expect hardware inventory": {
+			cmdLine:    "./rpc amtinfo -hw",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Hardware: true},
+		},
+		"expect bios and firmware versions": {
+			cmdLine:    "./rpc amtinfo -bios",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{BIOS: true},
+		},
+		"expect security check": {
+			cmdLine:    "./rpc amtinfo -seccheck -advisories advisories.json",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{SecCheck: true, Advisories: "advisories.json"},
+		},
+		"expect failure for advisories without seccheck": {
+			cmdLine:    "./rpc amtinfo -advisories advisories.json",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantFlags:  AmtInfoFlags{Advisories: "advisories.json"},
+		},
+		"expect host system": {
+			cmdLine:    "./rpc amtinfo -sys",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Sys: true},
+		},
+		"expect redirection state": {
+			cmdLine:    "./rpc amtinfo -kvm -password P@ssw0rd",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Redirection: true},
+		},
+		"expect ras with probe": {
+			cmdLine:    "./rpc amtinfo -probe",
+			wantResult: utils.Success,
+			wantFlags:  AmtInfoFlags{Ras: true, RasProbe: true},
+		},
 		"expect success for userCert with no password": {
 			cmdLine:    "./rpc amtinfo -userCert",
 			wantResult: utils.Success,
@@ -90,3 +208,39 @@ func TestParseFlagsAmtInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFlagsAmtInfoCache(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantCache  InfoCacheFlags
+		wantFlags  AmtInfoFlags
+	}{
+		"caches for an hour by default": {
+			cmdLine:    "./rpc amtinfo -uuid",
+			wantResult: utils.Success,
+			wantCache:  InfoCacheFlags{TTL: time.Hour},
+			wantFlags:  AmtInfoFlags{UUID: true},
+		},
+		"-nocache reads the MEI and keeps the default values": {
+			cmdLine:    "./rpc amtinfo -nocache -cacheTTL 10m",
+			wantResult: utils.Success,
+			wantCache:  InfoCacheFlags{NoCache: true, TTL: 10 * time.Minute},
+			wantFlags: AmtInfoFlags{Ver: true, Bld: true, Sku: true, UUID: true, Mode: true,
+				DNS: true, Ras: true, Lan: true, Hostname: true},
+		},
+		"expect error for a negative -cacheTTL": {
+			cmdLine:    "./rpc amtinfo -cacheTTL -1m",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantCache:  InfoCacheFlags{TTL: -time.Minute},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			assert.Equal(t, tc.wantResult, flags.ParseFlags())
+			assert.Equal(t, tc.wantCache, flags.InfoCache)
+			assert.Equal(t, tc.wantFlags, flags.AmtInfo)
+		})
+	}
+}
@@ -2,127 +2,626 @@ package flags
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"os"
-	"path/filepath"
 	"regexp"
 	"rpc/internal/amt"
+	"rpc/internal/config"
+	"rpc/internal/secretstore"
+	"rpc/internal/wlan"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
 )
 
 func (f *Flags) printMaintenanceUsage() string {
-	executable := filepath.Base(os.Args[0])
-	usage := "\nRemote Provisioning Client (RPC) - used for activation, deactivation, maintenance and status of AMT\n\n"
-	usage = usage + "Usage: " + executable + " maintenance COMMAND [OPTIONS]\n\n"
-	usage = usage + "Supported Maintenance Commands:\n"
-	usage = usage + "  changepassword Change the AMT password. A random password is generated by default. Specify -static to set manually. AMT password is required\n"
-	usage = usage + "                 Example: " + executable + " maintenance changepassword -u wss://server/activate\n"
-	usage = usage + "  syncdeviceinfo Sync device information. AMT password is required\n"
-	usage = usage + "                 Example: " + executable + " maintenance syncdeviceinfo -u wss://server/activate\n"
-	usage = usage + "  syncclock      Sync the host OS clock to AMT. AMT password is required\n"
-	usage = usage + "                 Example: " + executable + " maintenance syncclock -u wss://server/activate\n"
-	usage = usage + "  synchostname   Sync the hostname of the client to AMT. AMT password is required\n"
-	usage = usage + "                 Example: " + executable + " maintenance synchostname -u wss://server/activate\n"
-	usage = usage + "  syncip         Sync the IP configuration of the host OS to AMT Network Settings. AMT password is required\n"
-	usage = usage + "                 Example: " + executable + " maintenance syncip -staticip 192.168.1.7 -netmask 255.255.255.0 -gateway 192.168.1.1 -primarydns 8.8.8.8 -secondarydns 4.4.4.4 -u wss://server/activate\n"
-	usage = usage + "                 If a static ip is not specified, the ip address and netmask of the host OS is used\n"
-	usage = usage + "\nRun '" + executable + " maintenance COMMAND -h' for more information on a command.\n"
-	fmt.Println(usage)
+	usage := maintenanceUsage.text()
+	f.printText(usage)
 	return usage
 }
 
-func (f *Flags) handleMaintenanceCommand() utils.ReturnCode {
+func (f *Flags) handleMaintenanceCommand() error {
 	//validation section
 	if len(f.commandLineArgs) == 2 {
 		f.printMaintenanceUsage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
 	}
 
-	var rc = utils.Success
+	var err error
 
 	f.SubCommand = f.commandLineArgs[2]
 	switch f.SubCommand {
 	case "syncclock":
-		rc = f.handleMaintenanceSyncClock()
+		err = f.handleMaintenanceSyncClock()
 		break
 	case "synchostname":
-		rc = f.handleMaintenanceSyncHostname()
+		err = f.handleMaintenanceSyncHostname()
 		break
 	case "syncip":
-		rc = f.handleMaintenanceSyncIP()
+		err = f.handleMaintenanceSyncIP()
 		break
 	case "changepassword":
-		rc = f.handleMaintenanceSyncChangePassword()
+		err = f.handleMaintenanceSyncChangePassword()
 		break
 	case "syncdeviceinfo":
-		rc = f.handleMaintenanceSyncDeviceInfo()
+		err = f.handleMaintenanceSyncDeviceInfo()
+		break
+	case "syncdns":
+		err = f.handleMaintenanceSyncDNS()
+		break
+	case "syncwifi":
+		err = f.handleMaintenanceSyncWifi()
 		break
 	default:
+		if strings.HasPrefix(f.SubCommand, "-") {
+			err = f.handleMaintenanceBatch()
+			break
+		}
 		f.printMaintenanceUsage()
-		rc = utils.IncorrectCommandLineParameters
+		err = rpcerr.New(utils.IncorrectCommandLineParameters, "")
 		break
 	}
-	if rc != utils.Success {
-		return rc
+	if err != nil {
+		return err
 	}
+	return f.validateMaintenance()
+}
+
+// setupMaintenanceFlags registers the flags of the maintenance task, which sets their
+// defaults. An empty task registers the flags of -task.
+func (f *Flags) setupMaintenanceFlags(task string) {
+	switch task {
+	case utils.SubCommandSyncClock:
+		f.setupMaintenanceSyncClockFlags()
+	case utils.SubCommandSyncHostname:
+		f.setupMaintenanceSyncHostnameFlags()
+	case utils.SubCommandSyncIP:
+		f.setupMaintenanceSyncIPFlags()
+	case utils.SubCommandChangePassword:
+		f.setupMaintenanceChangePasswordFlags()
+	case utils.SubCommandSyncDeviceInfo:
+		f.setupMaintenanceSyncDeviceInfoFlags()
+	case utils.SubCommandSyncDNS:
+		f.setupMaintenanceSyncDNSFlags()
+	case "":
+		f.setupMaintenanceBatchFlags()
+	}
+}
+
+// validateMaintenanceTask checks the flags of the task in SubCommand, or of the tasks in
+// MaintenanceTasks without one, and reads what the task syncs from the host OS
+func (f *Flags) validateMaintenanceTask() error {
+	switch f.SubCommand {
+	case utils.SubCommandSyncClock:
+		return f.validateMaintenanceSyncClock()
+	case utils.SubCommandSyncHostname:
+		return f.validateMaintenanceSyncHostname()
+	case utils.SubCommandSyncIP:
+		return f.validateMaintenanceSyncIP()
+	case utils.SubCommandChangePassword:
+		return f.validateMaintenanceChangePassword()
+	case utils.SubCommandSyncDeviceInfo:
+		return f.validateMaintenanceSyncDeviceInfo()
+	case utils.SubCommandSyncDNS:
+		return f.validateMaintenanceSyncDNS()
+	case utils.SubCommandSyncWifi:
+		return f.validateMaintenanceSyncWifi(nil)
+	case "":
+		if len(f.MaintenanceTasks) > 0 {
+			return f.validateMaintenanceBatch()
+		}
+	}
+	return newError(utils.IncorrectCommandLineParameters, "error.maintenance.unsupportedTask", f.SubCommand)
+}
 
+// validateMaintenance reads the AMT password and checks the server of a maintenance task
+func (f *Flags) validateMaintenance() error {
 	if f.Password == "" {
 		if _, rc := f.ReadPasswordFromUser(); rc != 0 {
-			return utils.MissingOrIncorrectPassword
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
 		}
 	}
 	f.LocalConfig.Password = f.Password
 
-	// if this is a local command, then we dont care about -u or what task/command since its not going to the cloud
-	if !f.Local {
+	// if this is a local command, then we dont care about -u or what task/command since its not going to the cloud.
+	// syncdeviceinfo -show only prints what would be sent.
+	if !f.Local && !f.SyncDeviceInfo.Show {
 		if f.URL == "" {
-			fmt.Print("\n-u flag is required and cannot be empty\n\n")
 			f.printMaintenanceUsage()
-			return utils.MissingOrIncorrectURL
+			return newError(utils.MissingOrIncorrectURL, "error.urlRequired")
 		}
 	}
 
-	return utils.Success
+	return nil
 }
 
-func (f *Flags) handleMaintenanceSyncClock() utils.ReturnCode {
-	if err := f.amtMaintenanceSyncClockCommand.Parse(f.commandLineArgs[3:]); err != nil {
-		return utils.IncorrectCommandLineParameters
+// handleMaintenanceBatch parses maintenance -task and -all, which run several
+// maintenance tasks against the server in one invocation
+func (f *Flags) handleMaintenanceBatch() error {
+	var tasks string
+	var all bool
+	f.amtMaintenanceBatchCommand.StringVar(&tasks, "task", "", "Comma separated maintenance tasks to run one after the other ("+strings.Join(maintenanceTasks, ",")+")")
+	f.amtMaintenanceBatchCommand.BoolVar(&all, "all", false, "Run all maintenance tasks: "+strings.Join(maintenanceTasks, ","))
+	f.setupMaintenanceBatchFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceBatchCommand, f.commandLineArgs[2:]); err != nil || f.amtMaintenanceBatchCommand.NArg() > 0 {
+		f.printMaintenanceUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
-	return utils.Success
+	if tasks == "" && !all {
+		f.printMaintenanceUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	if tasks != "" && all {
+		return newError(utils.InvalidParameterCombination, "error.maintenance.taskOrAll")
+	}
+	f.MaintenanceTasks = nil
+	if all {
+		f.MaintenanceTasks = append(f.MaintenanceTasks, maintenanceTasks...)
+	}
+	for _, task := range strings.Split(tasks, ",") {
+		if task = strings.TrimSpace(task); task != "" {
+			f.MaintenanceTasks = append(f.MaintenanceTasks, task)
+		}
+	}
+	return f.validateMaintenanceBatch()
+}
+
+// setupMaintenanceBatchFlags registers the flags of -task and -all the tasks share
+func (f *Flags) setupMaintenanceBatchFlags() {
+	f.setupInterfaceFlags(f.amtMaintenanceBatchCommand)
+	f.setupSyncHostnameFlags(f.amtMaintenanceBatchCommand)
+	f.setupPreferSubnetFlag(f.amtMaintenanceBatchCommand)
+}
+
+// validateMaintenanceBatch checks the tasks of MaintenanceTasks and reads the host name
+// and IP configuration the tasks sync
+func (f *Flags) validateMaintenanceBatch() error {
+	if rc := f.validateInterfaceFlags(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	for i, task := range f.MaintenanceTasks {
+		if !IsMaintenanceTask(task) {
+			return newError(utils.IncorrectCommandLineParameters, "error.maintenance.unsupportedTask", task)
+		}
+		for _, t := range f.MaintenanceTasks[:i] {
+			if t == task {
+				return newError(utils.IncorrectCommandLineParameters, "error.maintenance.taskTwice", task)
+			}
+		}
+	}
+	// the status events report all tasks of the batch
+	f.SubCommand = strings.Join(f.MaintenanceTasks, ",")
+	for _, task := range f.MaintenanceTasks {
+		switch task {
+		case utils.SubCommandSyncHostname:
+			if rc := f.LookupHostnameInfo(); rc != utils.Success {
+				return rpcerr.FromReturnCode(rc)
+			}
+		case utils.SubCommandSyncIP:
+			if rc := f.LookupIpConfiguration(); rc != utils.Success {
+				return rpcerr.FromReturnCode(rc)
+			}
+		}
+	}
+	return nil
+}
+
+type SyncClockFlags struct {
+	// MaxSkew is the clock difference within which a local sync leaves the AMT clock as it
+	// is, 0 always syncs
+	MaxSkew time.Duration
+	// TimeZone is the zone the clock times are reported in and the AMT clock is checked
+	// against for local time or a missed DST change
+	TimeZone *time.Location
+}
+
+func (f *Flags) setupMaintenanceSyncClockFlags() {
+	fs := f.amtMaintenanceSyncClockCommand
+	fs.StringVar(&f.NTPServer, "ntp", "", "NTP server (host or host:port) to query for the time instead of using the host OS clock")
+	fs.BoolVar(&f.Local, "local", false, "Sync AMT to the host OS clock directly without cloud interaction")
+	fs.DurationVar(&f.SyncClock.MaxSkew, "maxSkew", 0, "Clock difference between AMT and the host or -ntp time within which the clock is left as it is (ex. '2s' or '1m'), 0 always syncs")
+}
+
+func (f *Flags) handleMaintenanceSyncClock() error {
+	fs := f.amtMaintenanceSyncClockCommand
+	f.setupMaintenanceSyncClockFlags()
+	tz := fs.String("tz", "", "IANA time zone (ex. 'Europe/Berlin') the times are reported in and the AMT clock is checked against for local time or a missed DST change (default the host time zone)")
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[3:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if *tz != "" {
+		location, err := time.LoadLocation(*tz)
+		if err != nil {
+			return newError(utils.IncorrectCommandLineParameters, "error.syncclock.unknownTZ", *tz)
+		}
+		f.SyncClock.TimeZone = location
+	}
+	return f.validateMaintenanceSyncClock()
+}
+
+// validateMaintenanceSyncClock checks the time source of syncclock, a nil TimeZone is the
+// host time zone
+func (f *Flags) validateMaintenanceSyncClock() error {
+	if f.SyncClock.MaxSkew < 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.negativeMaxSkew")
+	}
+	if f.SyncClock.TimeZone == nil {
+		f.SyncClock.TimeZone = time.Local
+	}
+	if f.NTPServer != "" {
+		if f.URL != "" {
+			return newError(utils.InvalidParameterCombination, "error.syncclock.urlOrNTP")
+		}
+		// time is pushed to AMT directly without cloud interaction
+		f.Local = true
+	}
+	if f.Local && f.URL != "" {
+		return newError(utils.InvalidParameterCombination, "error.syncclock.urlOrLocal")
+	}
+	// the server syncs the clock without reporting the difference
+	if !f.Local && (f.SyncClock.MaxSkew != 0 || f.SyncClock.TimeZone != time.Local) {
+		return newError(utils.InvalidParameterCombination, "error.syncclock.localOrNTP")
+	}
+	return nil
+}
+
+// The fields of the device info that -exclude can leave out of the syncdeviceinfo payload
+const (
+	DeviceInfoHostname     = "hostname"
+	DeviceInfoFQDN         = "fqdn"
+	DeviceInfoIPAddress    = "ipaddress"
+	DeviceInfoHardware     = "hardware"
+	DeviceInfoCertHashes   = "certhashes"
+	DeviceInfoFriendlyName = "friendlyname"
+	DeviceInfoTags         = "tags"
+)
+
+// DeviceInfoFields lists the fields that -exclude accepts. The identity, versions and
+// mode of AMT are always sent, the server can not update the device without them.
+var DeviceInfoFields = []string{
+	DeviceInfoHostname,
+	DeviceInfoFQDN,
+	DeviceInfoIPAddress,
+	DeviceInfoHardware,
+	DeviceInfoCertHashes,
+	DeviceInfoFriendlyName,
+	DeviceInfoTags,
+}
+
+// SyncDeviceInfoFlags holds the options of maintenance syncdeviceinfo
+type SyncDeviceInfoFlags struct {
+	// Show prints the device info that would be sent instead of sending it
+	Show bool
+	// Exclude lists the fields of DeviceInfoFields left out of the payload
+	Exclude []string
+	// Continuous keeps the connection to the server open and sends the device info again
+	// every Interval until rpc is stopped
+	Continuous bool
+	Interval   time.Duration
+}
+
+// Excludes reports whether the field is left out of the payload
+func (o SyncDeviceInfoFlags) Excludes(field string) bool {
+	for _, excluded := range o.Exclude {
+		if excluded == field {
+			return true
+		}
+	}
+	return false
 }
 
-func (f *Flags) handleMaintenanceSyncDeviceInfo() utils.ReturnCode {
-	if err := f.amtMaintenanceSyncDeviceInfoCommand.Parse(f.commandLineArgs[3:]); err != nil {
-		return utils.IncorrectCommandLineParameters
+func (f *Flags) setupMaintenanceSyncDeviceInfoFlags() {
+	fs := f.amtMaintenanceSyncDeviceInfoCommand
+	fs.BoolVar(&f.SyncDeviceInfo.Show, "show", false, "Print the device info that would be sent to the server without connecting to it")
+	fs.Func("exclude", "Comma separated fields left out of the device info ("+strings.Join(DeviceInfoFields, ",")+")", f.setDeviceInfoExclude)
+	fs.BoolVar(&f.SyncDeviceInfo.Continuous, "continuous", false, "Keep running and send the device info again every -interval over the same server connection")
+	fs.DurationVar(&f.SyncDeviceInfo.Interval, "interval", 15*time.Minute, "Time between device info updates of -continuous (ex. '15m' or '1h')")
+}
+
+func (f *Flags) handleMaintenanceSyncDeviceInfo() error {
+	f.setupMaintenanceSyncDeviceInfoFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceSyncDeviceInfoCommand, f.commandLineArgs[3:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	return f.validateMaintenanceSyncDeviceInfo()
+}
+
+// validateMaintenanceSyncDeviceInfo checks the interval of -continuous
+func (f *Flags) validateMaintenanceSyncDeviceInfo() error {
+	if !f.SyncDeviceInfo.Continuous {
+		return nil
+	}
+	if f.SyncDeviceInfo.Show || f.DryRun {
+		return newError(utils.InvalidParameterCombination, "error.syncdeviceinfo.continuousWithShow")
+	}
+	if f.SyncDeviceInfo.Interval < time.Minute {
+		return newError(utils.IncorrectCommandLineParameters, "error.intervalTooShort")
+	}
+	return nil
+}
+
+// setDeviceInfoExclude parses the fields of -exclude
+func (f *Flags) setDeviceInfoExclude(value string) error {
+	f.SyncDeviceInfo.Exclude = nil
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		known := false
+		for _, name := range DeviceInfoFields {
+			known = known || name == field
+		}
+		if !known {
+			return fmt.Errorf("unknown field %s, expected %s", field, strings.Join(DeviceInfoFields, ","))
+		}
+		if !f.SyncDeviceInfo.Excludes(field) {
+			f.SyncDeviceInfo.Exclude = append(f.SyncDeviceInfo.Exclude, field)
+		}
+	}
+	return nil
+}
+
+// setupInterfaceFlags adds the flags selecting the host interface the OS settings are read from
+func (f *Flags) setupInterfaceFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.InterfaceName, "ifname", "", "Name of the host interface to read the settings from, instead of the interface with the MAC address of AMT")
+	fs.Func("mac", "MAC address of the host interface to read the settings from, ex. 'a4:bb:6d:01:02:03'", func(val string) error {
+		mac, err := net.ParseMAC(val)
+		if err != nil {
+			return err
+		}
+		f.InterfaceMAC = mac.String()
+		return nil
+	})
+}
+
+// setupPreferSubnetFlag adds -preferSubnet, which picks the host address synced to
+// AMT when the host interface has more than one
+func (f *Flags) setupPreferSubnetFlag(fs *flag.FlagSet) {
+	fs.Func("preferSubnet", "IPv4 subnet in CIDR notation of the host address to sync when the host interface has several, ex. '192.168.1.0/24'", func(val string) error {
+		_, subnet, err := net.ParseCIDR(val)
+		if err != nil {
+			return err
+		}
+		if subnet.IP.To4() == nil {
+			return errors.New("not an IPv4 subnet")
+		}
+		f.PreferSubnet = subnet
+		return nil
+	})
+}
+
+// validateInterfaceFlags checks -ifname and -mac, which select the same thing
+func (f *Flags) validateInterfaceFlags() utils.ReturnCode {
+	if f.InterfaceName != "" && f.InterfaceMAC != "" {
+		fmt.Println("provide either an 'ifname' or a 'mac', but not both")
+		return utils.InvalidParameterCombination
 	}
 	return utils.Success
 }
 
-func (f *Flags) handleMaintenanceSyncHostname() utils.ReturnCode {
-	var err error
-	if err = f.amtMaintenanceSyncHostnameCommand.Parse(f.commandLineArgs[3:]); err != nil {
+func (f *Flags) setupMaintenanceSyncHostnameFlags() {
+	f.setupInterfaceFlags(f.amtMaintenanceSyncHostnameCommand)
+	f.setupSyncHostnameFlags(f.amtMaintenanceSyncHostnameCommand)
+}
+
+func (f *Flags) handleMaintenanceSyncHostname() error {
+	f.setupMaintenanceSyncHostnameFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceSyncHostnameCommand, f.commandLineArgs[3:]); err != nil {
 		f.amtMaintenanceSyncHostnameCommand.Usage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
+	return f.validateMaintenanceSyncHostname()
+}
+
+// validateMaintenanceSyncHostname reads the host name synced from the host OS
+func (f *Flags) validateMaintenanceSyncHostname() error {
+	if rc := f.validateInterfaceFlags(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	return rpcerr.FromReturnCode(f.LookupHostnameInfo())
+}
+
+// LookupHostnameInfo fills HostnameInfo from the host OS
+func (f *Flags) LookupHostnameInfo() utils.ReturnCode {
+	var err error
 	amtCommand := amt.NewAMTCommand()
-	if f.HostnameInfo.DnsSuffixOS, err = amtCommand.GetOSDNSSuffix(); err != nil {
+	if f.InterfaceName != "" || f.InterfaceMAC != "" {
+		ifaces, rc := f.selectInterfaces("")
+		if rc != utils.Success {
+			return rc
+		}
+		if f.HostnameInfo.DnsSuffixOS, err = amtCommand.GetOSDNSSuffixOf(ifaces[0].HardwareAddr.String()); err != nil {
+			log.Error(err)
+		}
+	} else if f.HostnameInfo.DnsSuffixOS, err = amtCommand.GetOSDNSSuffix(); err != nil {
 		log.Error(err)
 	}
-	f.HostnameInfo.Hostname, err = os.Hostname()
+	hostname, err := os.Hostname()
 	if err != nil {
 		log.Error(err)
 		return utils.OSNetworkInterfacesLookupFailed
-	} else if f.HostnameInfo.Hostname == "" {
+	} else if hostname == "" {
 		log.Error("OS hostname is not available")
 		return utils.OSNetworkInterfacesLookupFailed
 	}
+	if f.HostnameInfo.Hostname, err = f.SyncHostname.Apply(hostname, f.HostnameInfo.DnsSuffixOS); err != nil {
+		log.Error(err)
+		return utils.SyncHostnameFailed
+	}
+	log.Debugf("syncing host name %s for OS host name %s", f.HostnameInfo.Hostname, hostname)
+	return utils.Success
+}
+
+func (f *Flags) setupMaintenanceSyncDNSFlags() {
+	f.amtMaintenanceSyncDNSCommand.StringVar(&f.DNS, "dnssuffix", "", "DNS suffix to be assigned to AMT - if not specified, the DNS suffix of the host OS is used")
+	f.amtMaintenanceSyncDNSCommand.Func("primarydns", "Primary DNS to be assigned to AMT - if not specified, the DNS servers of the host OS are used", validateIP(&f.IpConfiguration.PrimaryDns))
+	f.amtMaintenanceSyncDNSCommand.Func("secondarydns", "Secondary DNS to be assigned to AMT", validateIP(&f.IpConfiguration.SecondaryDns))
+}
+
+func (f *Flags) handleMaintenanceSyncDNS() error {
+	f.setupMaintenanceSyncDNSFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceSyncDNSCommand, f.commandLineArgs[3:]); err != nil {
+		f.amtMaintenanceSyncDNSCommand.Usage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	return f.validateMaintenanceSyncDNS()
+}
+
+// validateMaintenanceSyncDNS reads the DNS settings not given from the host OS
+func (f *Flags) validateMaintenanceSyncDNS() error {
+	if f.URL != "" {
+		return newError(utils.InvalidParameterCombination, "error.syncdns.url")
+	}
+	// DNS settings are pushed to AMT directly without cloud interaction
+	f.Local = true
+	return rpcerr.FromReturnCode(f.LookupDNSConfiguration())
+}
+
+// LookupDNSConfiguration fills the DNS suffix and DNS servers not given
+// on the command line from the host OS
+func (f *Flags) LookupDNSConfiguration() utils.ReturnCode {
+	var err error
+	if f.DNS == "" {
+		if f.DNS, err = f.amtCommand.GetOSDNSSuffix(); err != nil {
+			log.Error(err)
+		}
+		if f.DNS == "" {
+			log.Error("OS DNS suffix is not available, use -dnssuffix")
+			return utils.MissingDNSSuffix
+		}
+	}
+	if f.IpConfiguration.PrimaryDns == "" {
+		servers, err := f.amtCommand.GetOSDNSServers()
+		if err != nil {
+			log.Warn("unable to read OS DNS servers: ", err)
+		}
+		if len(servers) > 0 {
+			f.IpConfiguration.PrimaryDns = servers[0]
+		}
+		if len(servers) > 1 && f.IpConfiguration.SecondaryDns == "" {
+			f.IpConfiguration.SecondaryDns = servers[1]
+		}
+	}
+	return utils.Success
+}
+
+func (f *Flags) handleMaintenanceSyncWifi() error {
+	var ssids string
+	f.amtMaintenanceSyncWifiCommand.StringVar(&ssids, "ssid", "", "Comma separated SSIDs of the host OS wifi profiles to sync - if not specified, all profiles AMT supports are synced")
+	if err := f.parseWithDefaults(f.amtMaintenanceSyncWifiCommand, f.commandLineArgs[3:]); err != nil {
+		f.amtMaintenanceSyncWifiCommand.Usage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	var filter []string
+	for _, ssid := range strings.Split(ssids, ",") {
+		if ssid = strings.TrimSpace(ssid); ssid != "" {
+			filter = append(filter, ssid)
+		}
+	}
+	return f.validateMaintenanceSyncWifi(filter)
+}
+
+// validateMaintenanceSyncWifi reads the wifi profiles with the SSIDs, or all of them, from
+// the host OS
+func (f *Flags) validateMaintenanceSyncWifi(ssids []string) error {
+	if f.URL != "" {
+		return newError(utils.InvalidParameterCombination, "error.syncwifi.url")
+	}
+	// wifi profiles are pushed to AMT directly without cloud interaction
+	f.Local = true
+	return rpcerr.FromReturnCode(f.LookupWifiProfiles(ssids))
+}
+
+// LookupWifiProfiles fills the wifi configurations from the profiles of the host OS with
+// the given SSIDs, or all of them. AMT only takes the pre-shared key of personal profiles,
+// enterprise profiles need the 802.1x certificates and are left to addwifisettings.
+func (f *Flags) LookupWifiProfiles(ssids []string) utils.ReturnCode {
+	profiles, err := f.wlanProfiles()
+	if err != nil {
+		log.Error("unable to read the wifi profiles of the host OS: ", err)
+		return utils.SyncWifiFailed
+	}
+	selected := map[string]bool{}
+	for _, ssid := range ssids {
+		selected[ssid] = false
+	}
+	names := map[string]bool{}
+	f.LocalConfig.WifiConfigs = nil
+	for _, profile := range profiles {
+		if _, ok := selected[profile.SSID]; len(ssids) > 0 && !ok {
+			continue
+		}
+		var authentication int
+		switch profile.Authentication {
+		case wlan.AuthWPAPSK:
+			authentication = int(models.AuthenticationMethod_WPA_PSK)
+		case wlan.AuthWPA2PSK:
+			authentication = int(models.AuthenticationMethod_WPA2_PSK)
+		case wlan.AuthWPA3SAE:
+			authentication = int(models.AuthenticationMethod_WPA3_SAE)
+		default:
+			log.Warnf("skipping wifi profile %s: %s authentication is not synced", profile.SSID, profile.Authentication)
+			continue
+		}
+		if profile.Passphrase == "" {
+			log.Warnf("skipping wifi profile %s: the OS did not reveal its passphrase", profile.SSID)
+			continue
+		}
+		encryption := int(models.EncryptionMethod_CCMP)
+		if profile.Encryption == wlan.EncryptionTKIP {
+			encryption = int(models.EncryptionMethod_TKIP)
+		}
+		selected[profile.SSID] = true
+		f.LocalConfig.WifiConfigs = append(f.LocalConfig.WifiConfigs, config.WifiConfig{
+			ProfileName:          wifiProfileName(profile.SSID, names),
+			SSID:                 profile.SSID,
+			Priority:             len(f.LocalConfig.WifiConfigs) + 1,
+			AuthenticationMethod: authentication,
+			EncryptionMethod:     encryption,
+			PskPassphrase:        profile.Passphrase,
+		})
+	}
+	for _, ssid := range ssids {
+		if !selected[ssid] {
+			log.Errorf("the host OS has no wifi profile AMT supports for ssid %s", ssid)
+			return utils.SyncWifiFailed
+		}
+	}
+	if len(f.LocalConfig.WifiConfigs) == 0 {
+		log.Error("the host OS has no wifi profiles AMT supports")
+		return utils.SyncWifiFailed
+	}
 	return utils.Success
 }
 
+// notAlphanumeric matches the characters an AMT profile name can not have
+var notAlphanumeric = regexp.MustCompile("[^a-zA-Z0-9]+")
+
+// wifiProfileName derives a unique AMT profile name from the SSID, AMT only accepts
+// alphanumeric names of up to 32 characters
+func wifiProfileName(ssid string, names map[string]bool) string {
+	base := notAlphanumeric.ReplaceAllString(ssid, "")
+	if base == "" {
+		base = "wifi"
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+	name := base
+	for i := 2; names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	names[name] = true
+	return name
+}
+
 // wrap the flag.Func method signature with the assignment value
 func validateIP(assignee *string) func(string) error {
 	return func(val string) error {
@@ -134,31 +633,75 @@ func validateIP(assignee *string) func(string) error {
 	}
 }
 
-func (f *Flags) handleMaintenanceSyncIP() utils.ReturnCode {
+func validateIPv4(assignee *string) func(string) error {
+	return func(val string) error {
+		ip := net.ParseIP(val)
+		if ip == nil || ip.To4() == nil {
+			return errors.New("not a valid IPv4 address, use -ipv6addr for IPv6")
+		}
+		*assignee = val
+		return nil
+	}
+}
+
+func validateIPv6(assignee *string) func(string) error {
+	return func(val string) error {
+		ip := net.ParseIP(val)
+		if ip == nil || ip.To4() != nil {
+			return errors.New("not a valid IPv6 address")
+		}
+		*assignee = val
+		return nil
+	}
+}
+
+func validatePrefixLength(assignee *int) func(string) error {
+	return func(val string) error {
+		length, err := strconv.Atoi(val)
+		if err != nil || length < 1 || length > 128 {
+			return errors.New("not a valid IPv6 prefix length, expected 1 to 128")
+		}
+		*assignee = length
+		return nil
+	}
+}
+
+func (f *Flags) setupMaintenanceSyncIPFlags() {
 	f.amtMaintenanceSyncIPCommand.Func(
 		"staticip",
 		"IP address to be assigned to AMT - if not specified, the IP Address of the active OS newtork interface is used",
-		validateIP(&f.IpConfiguration.IpAddress))
+		validateIPv4(&f.IpConfiguration.IpAddress))
 	f.amtMaintenanceSyncIPCommand.Func(
 		"netmask",
 		"Network mask to be assigned to AMT - if not specified, the Network mask of the active OS newtork interface is used",
-		validateIP(&f.IpConfiguration.Netmask))
-	f.amtMaintenanceSyncIPCommand.Func("gateway", "Gateway address to be assigned to AMT", validateIP(&f.IpConfiguration.Gateway))
+		validateIPv4(&f.IpConfiguration.Netmask))
+	f.amtMaintenanceSyncIPCommand.Func("gateway", "Gateway address to be assigned to AMT", validateIPv4(&f.IpConfiguration.Gateway))
+	f.amtMaintenanceSyncIPCommand.Func(
+		"ipv6addr",
+		"IPv6 address to be assigned to AMT - if not specified, the global IPv6 address of the active OS network interface is used",
+		validateIPv6(&f.IpConfiguration.IPv6Address))
+	f.amtMaintenanceSyncIPCommand.Func("prefixlen", "Prefix length of the IPv6 address (default 64)", validatePrefixLength(&f.IpConfiguration.IPv6PrefixLength))
+	f.amtMaintenanceSyncIPCommand.Func("ipv6gateway", "IPv6 gateway address to be assigned to AMT", validateIPv6(&f.IpConfiguration.IPv6Gateway))
+	f.setupInterfaceFlags(f.amtMaintenanceSyncIPCommand)
+	f.setupPreferSubnetFlag(f.amtMaintenanceSyncIPCommand)
 	f.amtMaintenanceSyncIPCommand.Func("primarydns", "Primary DNS to be assigned to AMT", validateIP(&f.IpConfiguration.PrimaryDns))
 	f.amtMaintenanceSyncIPCommand.Func("secondarydns", "Secondary DNS to be assigned to AMT", validateIP(&f.IpConfiguration.SecondaryDns))
+}
 
-	if err := f.amtMaintenanceSyncIPCommand.Parse(f.commandLineArgs[3:]); err != nil {
+func (f *Flags) handleMaintenanceSyncIP() error {
+	f.setupMaintenanceSyncIPFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceSyncIPCommand, f.commandLineArgs[3:]); err != nil {
 		f.amtMaintenanceSyncIPCommand.Usage()
 		// Parse the error message to find the problematic flag.
 		// The problematic flag is of the following format '-' followed by flag name and then a ':'
 		var rc utils.ReturnCode
 		re := regexp.MustCompile(`-.*:`)
 		switch re.FindString(err.Error()) {
-		case "-netmask:":
+		case "-netmask:", "-prefixlen:":
 			rc = utils.MissingOrIncorrectNetworkMask
-		case "-staticip:":
+		case "-staticip:", "-ipv6addr:":
 			rc = utils.MissingOrIncorrectStaticIP
-		case "-gateway:":
+		case "-gateway:", "-ipv6gateway:":
 			rc = utils.MissingOrIncorrectGateway
 		case "-primarydns:":
 			rc = utils.MissingOrIncorrectPrimaryDNS
@@ -167,56 +710,263 @@ func (f *Flags) handleMaintenanceSyncIP() utils.ReturnCode {
 		default:
 			rc = utils.IncorrectCommandLineParameters
 		}
-		return rc
-	} else if len(f.IpConfiguration.IpAddress) != 0 {
-		return utils.Success
+		return rpcerr.Wrap(rc, err, "")
 	}
+	return f.validateMaintenanceSyncIP()
+}
 
-	amtLanIfc, err := f.amtCommand.GetLANInterfaceSettings(false)
-	if err != nil {
-		log.Error(err)
-		return utils.AMTConnectionFailed
+// validateMaintenanceSyncIP checks the IPv6 settings and reads the IP configuration not
+// given from the host OS
+func (f *Flags) validateMaintenanceSyncIP() error {
+	if rc := f.validateInterfaceFlags(); rc != utils.Success {
+		return rpcerr.FromReturnCode(rc)
+	}
+	if f.PreferSubnet != nil && f.IpConfiguration.IpAddress != "" {
+		return newError(utils.InvalidParameterCombination, "error.syncip.preferSubnetWithStaticIP")
+	}
+	if f.IpConfiguration.IPv6PrefixLength != 0 && f.IpConfiguration.IPv6Address == "" {
+		return newError(utils.InvalidParameterCombination, "error.syncip.prefixlenWithoutIPv6")
+	}
+	if f.IpConfiguration.IPv6Address != "" && f.IpConfiguration.IPv6PrefixLength == 0 {
+		f.IpConfiguration.IPv6PrefixLength = 64
+	}
+	if len(f.IpConfiguration.IpAddress) != 0 {
+		return nil
 	}
+	return rpcerr.FromReturnCode(f.LookupIpConfiguration())
+}
 
-	ifaces, err := f.netEnumerator.Interfaces()
-	if err != nil {
-		log.Error(err)
-		return utils.OSNetworkInterfacesLookupFailed
+// LookupIpConfiguration fills the ip address and netmask of IpConfiguration
+// from the OS network interface that shares its MAC address with AMT, or the
+// interface selected with -ifname or -mac
+func (f *Flags) LookupIpConfiguration() utils.ReturnCode {
+	amtMAC := ""
+	if f.InterfaceName == "" && f.InterfaceMAC == "" {
+		amtLanIfc, err := f.amtCommand.GetLANInterfaceSettings(false)
+		if err != nil {
+			log.Error(err)
+			return utils.AMTConnectionFailed
+		}
+		amtMAC = amtLanIfc.MACAddress
+	}
+	ifaces, rc := f.selectInterfaces(amtMAC)
+	if rc != utils.Success {
+		return rc
 	}
 
+	var candidates []ipv4Candidate
+	ipv6ByInterface := map[string]*net.IPNet{}
 	for _, i := range ifaces {
-		if len(f.IpConfiguration.IpAddress) != 0 {
-			break
-		}
-		if i.HardwareAddr.String() != amtLanIfc.MACAddress {
-			continue
-		}
-		addrs, _ := f.netEnumerator.InterfaceAddrs(&i)
+		addrs, err := f.netEnumerator.InterfaceAddrs(&i)
 		if err != nil {
 			continue
 		}
+		vlan := f.netEnumerator.vlanID(&i)
 		for _, address := range addrs {
-			if ipnet, ok := address.(*net.IPNet); ok &&
-				ipnet.IP.To4() != nil &&
-				!ipnet.IP.IsLoopback() {
-				f.IpConfiguration.IpAddress = ipnet.IP.String()
-				f.IpConfiguration.Netmask = net.IP(ipnet.Mask).String()
+			ipnet, ok := address.(*net.IPNet)
+			if !ok || ipnet.IP.IsLoopback() {
+				continue
+			}
+			if ipnet.IP.To4() != nil {
+				candidates = append(candidates, ipv4Candidate{ipnet: ipnet, ifname: i.Name, vlan: vlan})
+			} else if ipnet.IP.IsGlobalUnicast() && ipv6ByInterface[i.Name] == nil {
+				// dual stack hosts also report the IPv6 configuration, link local addresses are skipped
+				ipv6ByInterface[i.Name] = ipnet
 			}
 		}
 	}
 
-	if len(f.IpConfiguration.IpAddress) == 0 {
-		log.Errorf("static ip address not found")
-		return utils.OSNetworkInterfacesLookupFailed
+	chosen, rc := f.chooseIPv4(candidates)
+	if rc != utils.Success {
+		return rc
+	}
+	f.IpConfiguration.IpAddress = chosen.ipnet.IP.String()
+	f.IpConfiguration.Netmask = net.IP(chosen.ipnet.Mask).String()
+	if ipv6 := ipv6ByInterface[chosen.ifname]; ipv6 != nil && f.IpConfiguration.IPv6Address == "" {
+		f.IpConfiguration.IPv6Address = ipv6.IP.String()
+		f.IpConfiguration.IPv6PrefixLength, _ = ipv6.Mask.Size()
 	}
 	return utils.Success
 }
 
-func (f *Flags) handleMaintenanceSyncChangePassword() utils.ReturnCode {
+// ipv4Candidate is an IPv4 address of a host interface selected for syncip
+type ipv4Candidate struct {
+	ipnet  *net.IPNet
+	ifname string
+	vlan   int
+}
+
+// chooseIPv4 picks the address synced to AMT. With -preferSubnet it is the first
+// address in that subnet. Otherwise untagged interfaces win over VLAN subinterfaces,
+// because AMT sends untagged frames unless it has a VLAN tag of its own, and the
+// first address enumerated is the primary one.
+func (f *Flags) chooseIPv4(candidates []ipv4Candidate) (ipv4Candidate, utils.ReturnCode) {
+	if len(candidates) == 0 {
+		log.Errorf("static ip address not found")
+		return ipv4Candidate{}, utils.OSNetworkInterfacesLookupFailed
+	}
+	var pool []ipv4Candidate
+	if f.PreferSubnet != nil {
+		for _, c := range candidates {
+			if f.PreferSubnet.Contains(c.ipnet.IP) {
+				pool = append(pool, c)
+			}
+		}
+		if len(pool) == 0 {
+			log.Errorf("no ip address of the host interface is in subnet %s", f.PreferSubnet)
+			return ipv4Candidate{}, utils.OSNetworkInterfacesLookupFailed
+		}
+	} else {
+		for _, c := range candidates {
+			if c.vlan == 0 {
+				pool = append(pool, c)
+			} else {
+				log.Debugf("skipping %s of VLAN %d subinterface %s", c.ipnet.IP, c.vlan, c.ifname)
+			}
+		}
+		if len(pool) == 0 {
+			pool = candidates
+		}
+	}
+	chosen := pool[0]
+	if len(pool) > 1 {
+		var others []string
+		for _, c := range pool[1:] {
+			others = append(others, c.ipnet.IP.String())
+		}
+		log.Warnf("using ip address %s of %s, also found %s. Select another one with -preferSubnet", chosen.ipnet.IP, chosen.ifname, strings.Join(others, ", "))
+	}
+	if chosen.vlan != 0 {
+		log.Warnf("ip address %s is on VLAN %d subinterface %s, AMT must be configured with the same VLAN tag", chosen.ipnet.IP, chosen.vlan, chosen.ifname)
+	}
+	return chosen, utils.Success
+}
+
+// selectInterfaces returns the host interfaces named with -ifname, with the MAC address
+// given with -mac or, without either, with the MAC address of AMT. Bonded and bridged
+// interfaces can share a MAC address, so more than one interface may be returned.
+func (f *Flags) selectInterfaces(amtMAC string) ([]net.Interface, utils.ReturnCode) {
+	ifaces, err := f.netEnumerator.Interfaces()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.OSNetworkInterfacesLookupFailed
+	}
+	var selected []net.Interface
+	for _, i := range ifaces {
+		switch {
+		case f.InterfaceName != "":
+			if i.Name != f.InterfaceName {
+				continue
+			}
+		case f.InterfaceMAC != "":
+			if i.HardwareAddr.String() != f.InterfaceMAC {
+				continue
+			}
+		case i.HardwareAddr.String() != amtMAC:
+			continue
+		}
+		selected = append(selected, i)
+	}
+	if len(selected) == 0 && (f.InterfaceName != "" || f.InterfaceMAC != "") {
+		log.Errorf("host interface %s%s not found", f.InterfaceName, f.InterfaceMAC)
+		return nil, utils.OSNetworkInterfacesLookupFailed
+	}
+	return selected, utils.Success
+}
+
+const vaultUsage = "Read the current password from this secret store and write the generated one to it, " +
+	"ex. 'vault://vault.example.com:8200/secret/amt/device1' or 'cyberark://ccp.example.com/?appId=rpc&safe=AMT&object=device1&accountId=12_3'"
+
+// ChangePasswordFlags control the password generated by rpc with changepassword -generate
+type ChangePasswordFlags struct {
+	Generate  bool
+	Length    int
+	NoSymbols bool
+	// the new password is printed unless it is written to OutFile, the keyring or Vault
+	OutFile string
+	Keyring bool
+	// Vault is the URL of the secret store the current password is read from and the new
+	// one written to, VaultToken authenticates rpc with it
+	Vault      string
+	VaultToken string
+}
+
+// passwordSinks counts the places given for a generated password: -out, -keyring and -vault
+func (f *Flags) passwordSinks() int {
+	sinks := 0
+	for _, set := range []bool{f.ChangePassword.OutFile != "", f.ChangePassword.Keyring, f.ChangePassword.Vault != ""} {
+		if set {
+			sinks++
+		}
+	}
+	return sinks
+}
+
+func (f *Flags) setupMaintenanceChangePasswordFlags() {
 	f.amtMaintenanceChangePasswordCommand.StringVar(&f.StaticPassword, "static", "", "specify a new password for AMT")
-	if err := f.amtMaintenanceChangePasswordCommand.Parse(f.commandLineArgs[3:]); err != nil {
+	f.amtMaintenanceChangePasswordCommand.BoolVar(&f.ChangePassword.Generate, "generate", false, "Generate a random password and set it in AMT without cloud interaction")
+	f.amtMaintenanceChangePasswordCommand.IntVar(&f.ChangePassword.Length, "length", utils.DefaultGeneratedPasswordLength, "Length of the generated password (8-32)")
+	f.amtMaintenanceChangePasswordCommand.BoolVar(&f.ChangePassword.NoSymbols, "nosymbols", false, "Generate the password with the symbols + - . / = only, they need no quoting in shells or XML")
+	f.amtMaintenanceChangePasswordCommand.BoolVar(&f.FIPS, "fips", false, fipsUsage)
+	f.amtMaintenanceChangePasswordCommand.StringVar(&f.ChangePassword.OutFile, "out", "", "Write the generated password to this file, readable by the owner only")
+	f.amtMaintenanceChangePasswordCommand.BoolVar(&f.ChangePassword.Keyring, "keyring", false, "Store the generated password in the OS keyring, it is read with -passwordFromKeyring")
+	f.amtMaintenanceChangePasswordCommand.StringVar(&f.ChangePassword.Vault, "vault", "", vaultUsage)
+	f.amtMaintenanceChangePasswordCommand.StringVar(&f.ChangePassword.VaultToken, "vaultToken", f.lookupEnvOrString("VAULT_TOKEN", ""), "Token of the secret store given with -vault")
+}
+
+func (f *Flags) handleMaintenanceSyncChangePassword() error {
+	f.setupMaintenanceChangePasswordFlags()
+	if err := f.parseWithDefaults(f.amtMaintenanceChangePasswordCommand, f.commandLineArgs[3:]); err != nil {
 		f.amtMaintenanceChangePasswordCommand.Usage()
-		return utils.IncorrectCommandLineParameters
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
-	return utils.Success
+	if err := f.validateMaintenanceChangePassword(); err != nil || f.ChangePassword.Generate {
+		return err
+	}
+	policySet := false
+	f.amtMaintenanceChangePasswordCommand.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "length", "nosymbols", "fips", "out", "keyring", "vault":
+			policySet = true
+		}
+	})
+	if policySet {
+		return newError(utils.InvalidParameterCombination, "error.changepassword.generateRequired")
+	}
+	return nil
+}
+
+// validateMaintenanceChangePassword checks the static password or the policy and store of
+// the generated one
+func (f *Flags) validateMaintenanceChangePassword() error {
+	if f.StaticPassword != "" {
+		if err := validateStrongPassword(f.StaticPassword); err != nil {
+			return wrapError(utils.MissingOrIncorrectPassword, err, "error.changepassword.invalidStatic")
+		}
+	}
+	if !f.ChangePassword.Generate {
+		return nil
+	}
+	if f.passwordSinks() > 1 {
+		return newError(utils.InvalidParameterCombination, "error.changepassword.oneStore")
+	}
+	if f.ChangePassword.Vault != "" {
+		if _, err := secretstore.Open(f.ChangePassword.Vault, f.ChangePassword.VaultToken); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.invalidVault")
+		}
+	}
+	if f.StaticPassword != "" || f.URL != "" {
+		return newError(utils.InvalidParameterCombination, "error.changepassword.generateWithStatic")
+	}
+	if f.ChangePassword.Length < utils.MinPasswordLength || f.ChangePassword.Length > utils.MaxPasswordLength {
+		return newError(utils.IncorrectCommandLineParameters, "error.changepassword.length", utils.MinPasswordLength, utils.MaxPasswordLength)
+	}
+	if f.FIPS {
+		if err := utils.CheckFIPS(); err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.fips")
+		}
+	}
+	// the password is set in AMT directly without cloud interaction
+	f.Local = true
+	return nil
 }
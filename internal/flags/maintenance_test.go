@@ -1,8 +1,13 @@
 package flags
 
 import (
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"rpc/internal/config"
+	"rpc/internal/wlan"
 	"rpc/pkg/utils"
 	"strings"
 	"testing"
@@ -20,16 +25,37 @@ func TestPrintMaintenanceUsage(t *testing.T) {
 	usage = usage + "Supported Maintenance Commands:\n"
 	usage = usage + "  changepassword Change the AMT password. A random password is generated by default. Specify -static to set manually. AMT password is required\n"
 	usage = usage + "                 Example: " + executable + " maintenance changepassword -u wss://server/activate\n"
+	usage = usage + "                 Specify -generate to generate the password locally, without cloud interaction\n"
+	usage = usage + "                 Example: " + executable + " maintenance changepassword -generate -length 20 -nosymbols -out amt.pwd\n"
 	usage = usage + "  syncdeviceinfo Sync device information. AMT password is required\n"
 	usage = usage + "                 Example: " + executable + " maintenance syncdeviceinfo -u wss://server/activate\n"
+	usage = usage + "                 Specify -show to print the device info without sending it and -exclude to leave fields out\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncdeviceinfo -show -exclude hostname,ipaddress\n"
+	usage = usage + "                 Specify -continuous to keep running and send the device info again every -interval\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncdeviceinfo -u wss://server/activate -continuous -interval 15m\n"
 	usage = usage + "  syncclock      Sync the host OS clock to AMT. AMT password is required\n"
 	usage = usage + "                 Example: " + executable + " maintenance syncclock -u wss://server/activate\n"
+	usage = usage + "                 Specify -ntp to sync AMT to an NTP server instead, without cloud interaction\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncclock -ntp pool.ntp.org\n"
+	usage = usage + "                 Specify -local to sync AMT to the host OS clock, -maxSkew leaves a clock within the tolerance as it is\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncclock -local -maxSkew 2s -tz Europe/Berlin -json\n"
 	usage = usage + "  synchostname   Sync the hostname of the client to AMT. AMT password is required\n"
 	usage = usage + "                 Example: " + executable + " maintenance synchostname -u wss://server/activate\n"
+	usage = usage + "                 Example: " + executable + " maintenance synchostname -u wss://server/activate -fqdn -lowercase -truncate -template amt-{hostname}\n"
 	usage = usage + "  syncip         Sync the IP configuration of the host OS to AMT Network Settings. AMT password is required\n"
 	usage = usage + "                 Example: " + executable + " maintenance syncip -staticip 192.168.1.7 -netmask 255.255.255.0 -gateway 192.168.1.1 -primarydns 8.8.8.8 -secondarydns 4.4.4.4 -u wss://server/activate\n"
 	usage = usage + "                 If a static ip is not specified, the ip address and netmask of the host OS is used\n"
-	usage = usage + "\nRun '" + executable + " maintenance COMMAND -h' for more information on a command.\n"
+	usage = usage + "                 Specify -ipv6addr and -prefixlen to also set an IPv6 address, a global IPv6 address of the host OS is used if present\n"
+	usage = usage + "                 Specify -ifname or -mac to read the host OS settings from a bonded or bridged interface, also for synchostname\n"
+	usage = usage + "                 Specify -preferSubnet to choose among several host addresses, ex. -preferSubnet 192.168.1.0/24\n"
+	usage = usage + "  syncdns        Sync the DNS suffix and DNS servers of the host OS to AMT, without cloud interaction. AMT password is required\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncdns -dnssuffix corp.example.com\n"
+	usage = usage + "                 If not specified, the DNS suffix and DNS servers of the host OS are used\n"
+	usage = usage + "  syncwifi       Replace the wifi profiles in AMT with the WPA/WPA2 personal profiles of the host OS, without cloud interaction. AMT password is required\n"
+	usage = usage + "                 Example: " + executable + " maintenance syncwifi -ssid office,lab\n"
+	usage = usage + "\nRun several tasks over one server connection with -task, or all of them with -all:\n"
+	usage = usage + "                 Example: " + executable + " maintenance -task syncclock,synchostname,syncip -u wss://server/activate\n"
+	usage = usage + "\nRun '" + executable + " help maintenance COMMAND' for more information on a command.\n"
 	assert.Equal(t, usage, output)
 }
 
@@ -37,17 +63,21 @@ func TestParseFlagsMaintenance(t *testing.T) {
 	argUrl := "-u wss://localhost"
 	argCurPw := "-password " + trickyPassword
 	argSyncClock := "syncclock"
+	argNtp := "-ntp pool.ntp.org"
 	argAddWiFiSettings := "addwifisettings"
 	argSyncHostname := "synchostname"
 	argSyncIp := "syncip"
 	argChangePw := "changepassword"
 	argSyncDeviceInfo := "syncdeviceinfo"
-	newPassword := trickyPassword + "123"
+	newPassword := trickyPassword + "Aa123"
 	cmdBase := "./rpc maintenance"
 
+	// the test interfaces are dual stack, the IPv6 address is looked up with the IPv4 address
 	ipCfgNoParams := IPConfiguration{
-		IpAddress: "192.168.1.1",
-		Netmask:   "255.255.255.0",
+		IpAddress:        "192.168.1.1",
+		Netmask:          "255.255.255.0",
+		IPv6Address:      "::1234:5678",
+		IPv6PrefixLength: 64,
 	}
 	ipCfgWithParams := IPConfiguration{
 		IpAddress:    "10.20.30.40",
@@ -57,11 +87,20 @@ func TestParseFlagsMaintenance(t *testing.T) {
 		SecondaryDns: "4.4.4.4",
 	}
 	ipCfgWithLookup := IPConfiguration{
-		IpAddress:    ipCfgNoParams.IpAddress,
-		Netmask:      ipCfgNoParams.Netmask,
-		Gateway:      "10.0.0.0",
-		PrimaryDns:   "1.2.3.4",
-		SecondaryDns: "5.6.7.8",
+		IpAddress:        ipCfgNoParams.IpAddress,
+		Netmask:          ipCfgNoParams.Netmask,
+		Gateway:          "10.0.0.0",
+		PrimaryDns:       "1.2.3.4",
+		SecondaryDns:     "5.6.7.8",
+		IPv6Address:      ipCfgNoParams.IPv6Address,
+		IPv6PrefixLength: ipCfgNoParams.IPv6PrefixLength,
+	}
+	ipCfgWithIPv6 := IPConfiguration{
+		IpAddress:        ipCfgNoParams.IpAddress,
+		Netmask:          ipCfgNoParams.Netmask,
+		IPv6Address:      "2001:db8::7",
+		IPv6PrefixLength: 48,
+		IPv6Gateway:      "2001:db8::1",
 	}
 	tests := map[string]struct {
 		cmdLine      string
@@ -89,6 +128,34 @@ func TestParseFlagsMaintenance(t *testing.T) {
 			cmdLine:    cmdBase + " " + argSyncClock + " " + argUrl + " " + argCurPw,
 			wantResult: utils.Success,
 		},
+		"should pass - syncclock with ntp": {
+			cmdLine:    cmdBase + " " + argSyncClock + " " + argNtp + " " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - syncclock with ntp and url": {
+			cmdLine:    cmdBase + " " + argSyncClock + " " + argNtp + " " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should pass - syncclock local with maxSkew and tz": {
+			cmdLine:    cmdBase + " " + argSyncClock + " -local -maxSkew 5s -tz Europe/Berlin " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - syncclock local and url": {
+			cmdLine:    cmdBase + " " + argSyncClock + " -local " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - syncclock maxSkew with url": {
+			cmdLine:    cmdBase + " " + argSyncClock + " -maxSkew 5s " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - syncclock negative maxSkew": {
+			cmdLine:    cmdBase + " " + argSyncClock + " " + argNtp + " -maxSkew -5s " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - syncclock unknown tz": {
+			cmdLine:    cmdBase + " " + argSyncClock + " " + argNtp + " -tz Mars/Olympus " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
 		"should fail - syncclock bad param": {
 			cmdLine:    cmdBase + " " + argSyncClock + " -nope " + argUrl + " " + argCurPw,
 			wantResult: utils.IncorrectCommandLineParameters,
@@ -109,6 +176,26 @@ func TestParseFlagsMaintenance(t *testing.T) {
 			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " " + argUrl + " " + argCurPw,
 			wantResult: utils.Success,
 		},
+		"should pass - syncdeviceinfo show without url": {
+			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -show -exclude hostname,IPAddress " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - syncdeviceinfo exclude unknown field": {
+			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -exclude uuid " + argUrl + " " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should pass - syncdeviceinfo continuous": {
+			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -continuous -interval 30m " + argUrl + " " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - syncdeviceinfo continuous interval below a minute": {
+			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -continuous -interval 10s " + argUrl + " " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - syncdeviceinfo continuous with show": {
+			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -continuous -show " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
 		"should fail - syncdeviceinfo bad param": {
 			cmdLine:    cmdBase + " " + argSyncDeviceInfo + " -nope " + argUrl + " " + argCurPw,
 			wantResult: utils.IncorrectCommandLineParameters,
@@ -140,6 +227,97 @@ func TestParseFlagsMaintenance(t *testing.T) {
 			wantResult:   utils.Success,
 			wantIPConfig: ipCfgWithLookup,
 		},
+		"should pass - syncip with ipv6": {
+			cmdLine: cmdBase + " " +
+				argSyncIp +
+				" -ipv6addr " + ipCfgWithIPv6.IPv6Address +
+				" -prefixlen 48" +
+				" -ipv6gateway " + ipCfgWithIPv6.IPv6Gateway +
+				" " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantIPConfig: ipCfgWithIPv6,
+		},
+		"should pass - syncip ipv6 default prefix length": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -staticip 10.20.30.40 -ipv6addr 2001:db8::7 " + argUrl + " " + argCurPw,
+			wantResult: utils.Success,
+			wantIPConfig: IPConfiguration{
+				IpAddress:        "10.20.30.40",
+				IPv6Address:      "2001:db8::7",
+				IPv6PrefixLength: 64,
+			},
+		},
+		"should fail - syncip ipv6 address as staticip": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -staticip 2001:db8::7 " + argUrl + " " + argCurPw,
+			wantResult: utils.MissingOrIncorrectStaticIP,
+		},
+		"should fail - syncip ipv4 address as ipv6addr": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -ipv6addr 10.20.30.40 " + argUrl + " " + argCurPw,
+			wantResult: utils.MissingOrIncorrectStaticIP,
+		},
+		"should fail - syncip bad prefixlen": {
+			cmdLine:      cmdBase + " " + argSyncIp + " -ipv6addr 2001:db8::7 -prefixlen 129 " + argUrl + " " + argCurPw,
+			wantResult:   utils.MissingOrIncorrectNetworkMask,
+			wantIPConfig: IPConfiguration{IPv6Address: "2001:db8::7"},
+		},
+		"should fail - syncip bad ipv6gateway": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -ipv6gateway 10.0.0.1 " + argUrl + " " + argCurPw,
+			wantResult: utils.MissingOrIncorrectGateway,
+		},
+		"should fail - syncip prefixlen without ipv6addr": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -prefixlen 64 " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+			wantIPConfig: IPConfiguration{
+				IPv6PrefixLength: 64,
+			},
+		},
+		"should pass - syncip with ifname": {
+			cmdLine:      cmdBase + " " + argSyncIp + " -ifname wlanTest01 " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantIPConfig: ipCfgNoParams,
+		},
+		"should pass - syncip with mac": {
+			cmdLine:      cmdBase + " " + argSyncIp + " -mac 01-02-03-04-05-06 " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantIPConfig: ipCfgNoParams,
+		},
+		"should pass - syncip with preferSubnet": {
+			cmdLine:      cmdBase + " " + argSyncIp + " -preferSubnet 192.168.1.0/24 " + argUrl + " " + argCurPw,
+			wantResult:   utils.Success,
+			wantIPConfig: ipCfgNoParams,
+		},
+		"should fail - syncip preferSubnet without host address": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -preferSubnet 10.0.0.0/8 " + argUrl + " " + argCurPw,
+			wantResult: utils.OSNetworkInterfacesLookupFailed,
+		},
+		"should fail - syncip preferSubnet not ipv4": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -preferSubnet 2001:db8::/32 " + argUrl + " " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - syncip preferSubnet with staticip": {
+			cmdLine:      cmdBase + " " + argSyncIp + " -preferSubnet 10.0.0.0/8 -staticip 10.20.30.40 " + argUrl + " " + argCurPw,
+			wantResult:   utils.InvalidParameterCombination,
+			wantIPConfig: IPConfiguration{IpAddress: "10.20.30.40"},
+		},
+		"should fail - syncip unknown ifname": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -ifname bond0 " + argUrl + " " + argCurPw,
+			wantResult: utils.OSNetworkInterfacesLookupFailed,
+		},
+		"should fail - syncip ifname and mac": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -ifname wlanTest01 -mac 01:02:03:04:05:06 " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - syncip bad mac": {
+			cmdLine:    cmdBase + " " + argSyncIp + " -mac 01:02:03 " + argUrl + " " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should pass - synchostname with ifname": {
+			cmdLine:    cmdBase + " " + argSyncHostname + " -ifname ethTest01 " + argUrl + " " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - synchostname unknown mac": {
+			cmdLine:    cmdBase + " " + argSyncHostname + " -mac 0a:0b:0c:00:00:00 " + argUrl + " " + argCurPw,
+			wantResult: utils.OSNetworkInterfacesLookupFailed,
+		},
 		"should fail - syncip bad param": {
 			cmdLine:    cmdBase + " " + argSyncIp + " -nope " + argUrl + " " + argCurPw,
 			wantResult: utils.IncorrectCommandLineParameters,
@@ -180,6 +358,46 @@ func TestParseFlagsMaintenance(t *testing.T) {
 			cmdLine:    cmdBase + " " + argChangePw + " " + argUrl + " " + argCurPw + " -static " + newPassword,
 			wantResult: utils.Success,
 		},
+		"should pass - changepassword generate locally": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate -length 20 -nosymbols -out amt.pwd " + argCurPw,
+			wantResult: utils.Success,
+		},
+		"should fail - changepassword policy without generate": {
+			cmdLine:    cmdBase + " " + argChangePw + " -length 20 " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - changepassword weak static value": {
+			cmdLine:    cmdBase + " " + argChangePw + " -static " + trickyPassword + " " + argUrl + " " + argCurPw,
+			wantResult: utils.MissingOrIncorrectPassword,
+		},
+		"should fail - changepassword generate with static": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate -static " + newPassword + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - changepassword vault without generate": {
+			cmdLine:    cmdBase + " " + argChangePw + " -vault vault://vault.example.com/secret/amt " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - changepassword vault with keyring": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate -keyring -vault vault://vault.example.com/secret/amt -vaultToken s.token " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - changepassword vault unsupported store": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate -vault s3://bucket/amt " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - changepassword generate with url": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"should fail - changepassword generate length out of range": {
+			cmdLine:    cmdBase + " " + argChangePw + " -generate -length 40 " + argCurPw,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - changepassword fips without generate": {
+			cmdLine:    cmdBase + " " + argChangePw + " -fips " + argUrl + " " + argCurPw,
+			wantResult: utils.InvalidParameterCombination,
+		},
 		"should fail - changepassword bad param": {
 			cmdLine:    cmdBase + " " + argChangePw + " -nope " + argUrl + " " + argCurPw,
 			wantResult: utils.IncorrectCommandLineParameters,
@@ -201,7 +419,10 @@ func TestParseFlagsMaintenance(t *testing.T) {
 			flags.amtCommand.PTHI = MockPTHICommands{}
 			flags.netEnumerator = testNetEnumerator
 			gotResult := flags.ParseFlags()
-			if strings.Contains(tc.cmdLine, argAddWiFiSettings) {
+			isLocalNtp := strings.Contains(tc.cmdLine, argNtp) && tc.wantResult == utils.Success
+			isLocalGenerate := strings.Contains(tc.cmdLine, "-generate") && tc.wantResult == utils.Success
+			isLocalClock := strings.Contains(tc.cmdLine, argSyncClock+" -local")
+			if strings.Contains(tc.cmdLine, argAddWiFiSettings) || isLocalNtp || isLocalGenerate || isLocalClock {
 				assert.Equal(t, flags.Local, true)
 			} else {
 				assert.Equal(t, flags.Local, false)
@@ -212,3 +433,287 @@ func TestParseFlagsMaintenance(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupIpConfigurationVLAN(t *testing.T) {
+	mac := net.HardwareAddr{0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
+	addrs := map[string][]string{
+		"eth0.100": {"192.168.100.5/24"},
+		"eth0":     {"10.0.0.5/24", "10.0.1.5/24"},
+	}
+	enumerator := func(names ...string) NetEnumerator {
+		return NetEnumerator{
+			Interfaces: func() ([]net.Interface, error) {
+				var ifaces []net.Interface
+				for _, name := range names {
+					ifaces = append(ifaces, net.Interface{Name: name, HardwareAddr: mac})
+				}
+				return ifaces, nil
+			},
+			InterfaceAddrs: func(i *net.Interface) ([]net.Addr, error) {
+				var result []net.Addr
+				for _, cidr := range addrs[i.Name] {
+					ip, ipnet, _ := net.ParseCIDR(cidr)
+					ipnet.IP = ip
+					result = append(result, ipnet)
+				}
+				return result, nil
+			},
+			VLANID: func(i *net.Interface) int { return vlanIDFromName(i.Name) },
+		}
+	}
+	tests := map[string]struct {
+		names        []string
+		preferSubnet string
+		wantIP       string
+	}{
+		"untagged interface wins over VLAN subinterface": {
+			names:  []string{"eth0.100", "eth0"},
+			wantIP: "10.0.0.5",
+		},
+		"preferSubnet picks a secondary address": {
+			names:        []string{"eth0.100", "eth0"},
+			preferSubnet: "10.0.1.0/24",
+			wantIP:       "10.0.1.5",
+		},
+		"preferSubnet picks the VLAN subinterface": {
+			names:        []string{"eth0", "eth0.100"},
+			preferSubnet: "192.168.100.0/24",
+			wantIP:       "192.168.100.5",
+		},
+		"VLAN subinterface is used when it is the only one": {
+			names:  []string{"eth0.100"},
+			wantIP: "192.168.100.5",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(nil)
+			flags.amtCommand.PTHI = MockPTHICommands{}
+			flags.netEnumerator = enumerator(tc.names...)
+			flags.InterfaceMAC = mac.String()
+			if tc.preferSubnet != "" {
+				_, flags.PreferSubnet, _ = net.ParseCIDR(tc.preferSubnet)
+			}
+			assert.Equal(t, utils.Success, flags.LookupIpConfiguration())
+			assert.Equal(t, tc.wantIP, flags.IpConfiguration.IpAddress)
+			assert.Equal(t, "255.255.255.0", flags.IpConfiguration.Netmask)
+		})
+	}
+}
+
+func TestVLANIDFromName(t *testing.T) {
+	assert.Equal(t, 100, vlanIDFromName("eth0.100"))
+	assert.Equal(t, 20, vlanIDFromName("vlan20"))
+	assert.Equal(t, 0, vlanIDFromName("eth0"))
+	assert.Equal(t, 0, vlanIDFromName("br.lan"))
+	assert.Equal(t, 0, vlanIDFromName("eth0.5000"))
+}
+
+func TestParseFlagsMaintenanceBatch(t *testing.T) {
+	cmdBase := "./rpc maintenance"
+	argUrl := "-u wss://localhost"
+	argCurPw := "-password " + trickyPassword
+	tests := map[string]struct {
+		cmdLine   string
+		wantTasks []string
+		wantIP    string
+		wantRC    utils.ReturnCode
+	}{
+		"should pass - task list": {
+			cmdLine:   cmdBase + " -task syncclock,syncdeviceinfo " + argUrl + " " + argCurPw,
+			wantTasks: []string{utils.SubCommandSyncClock, utils.SubCommandSyncDeviceInfo},
+			wantRC:    utils.Success,
+		},
+		"should pass - all tasks with host settings looked up": {
+			cmdLine:   cmdBase + " -all " + argUrl + " " + argCurPw,
+			wantTasks: maintenanceTasks,
+			wantIP:    "192.168.1.1",
+			wantRC:    utils.Success,
+		},
+		"should fail - neither task nor all": {
+			cmdLine: cmdBase + " " + argUrl + " " + argCurPw,
+			wantRC:  utils.IncorrectCommandLineParameters,
+		},
+		"should fail - task and all": {
+			cmdLine: cmdBase + " -all -task syncclock " + argUrl + " " + argCurPw,
+			wantRC:  utils.InvalidParameterCombination,
+		},
+		"should fail - unsupported task": {
+			cmdLine: cmdBase + " -task syncclock,syncdns " + argUrl + " " + argCurPw,
+			wantRC:  utils.IncorrectCommandLineParameters,
+		},
+		"should fail - task given twice": {
+			cmdLine: cmdBase + " -task syncclock,syncclock " + argUrl + " " + argCurPw,
+			wantRC:  utils.IncorrectCommandLineParameters,
+		},
+		"should fail - missing url": {
+			cmdLine: cmdBase + " -task syncclock " + argCurPw,
+			wantRC:  utils.MissingOrIncorrectURL,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			flags.amtCommand.PTHI = MockPTHICommands{}
+			flags.netEnumerator = testNetEnumerator
+			gotResult := flags.ParseFlags()
+			assert.Equal(t, tc.wantRC, gotResult)
+			assert.False(t, flags.Local)
+			if tc.wantRC == utils.Success {
+				assert.Equal(t, tc.wantTasks, flags.MaintenanceTasks)
+				assert.Equal(t, strings.Join(tc.wantTasks, ","), flags.SubCommand)
+				assert.Equal(t, tc.wantIP, flags.IpConfiguration.IpAddress)
+			}
+		})
+	}
+}
+
+func TestParseFlagsMaintenanceSyncDNS(t *testing.T) {
+	cmdBase := "./rpc maintenance syncdns -password " + trickyPassword
+	tests := map[string]struct {
+		cmdLine      string
+		wantResult   utils.ReturnCode
+		wantDNS      string
+		wantIPConfig IPConfiguration
+	}{
+		"should pass - syncdns with params": {
+			cmdLine:      cmdBase + " -dnssuffix corp.example.com -primarydns 8.8.8.8 -secondarydns 4.4.4.4",
+			wantResult:   utils.Success,
+			wantDNS:      "corp.example.com",
+			wantIPConfig: IPConfiguration{PrimaryDns: "8.8.8.8", SecondaryDns: "4.4.4.4"},
+		},
+		"should fail - syncdns bad primarydns": {
+			cmdLine:    cmdBase + " -primarydns 322.299.0.0",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail - syncdns with url": {
+			cmdLine:    cmdBase + " -dnssuffix corp.example.com -primarydns 8.8.8.8 -u wss://localhost",
+			wantResult: utils.InvalidParameterCombination,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			flags.amtCommand.PTHI = MockPTHICommands{}
+			gotResult := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, gotResult)
+			assert.Equal(t, utils.SubCommandSyncDNS, flags.SubCommand)
+			if tc.wantResult == utils.Success {
+				assert.True(t, flags.Local)
+				assert.Equal(t, tc.wantDNS, flags.DNS)
+				assert.Equal(t, tc.wantIPConfig, flags.IpConfiguration)
+			}
+		})
+	}
+}
+
+func TestParseFlagsMaintenanceSyncWifi(t *testing.T) {
+	cmdBase := "./rpc maintenance syncwifi -password " + trickyPassword
+	hostProfiles := []wlan.Profile{
+		{SSID: "office-5G", Authentication: wlan.AuthWPA2PSK, Encryption: wlan.EncryptionAES, Passphrase: "officeP@ss"},
+		{SSID: "lab", Authentication: wlan.AuthWPAPSK, Encryption: wlan.EncryptionTKIP, Passphrase: "labP@ss"},
+		{SSID: "corp", Authentication: wlan.AuthEnterprise, Encryption: wlan.EncryptionAES},
+		{SSID: "guest", Authentication: wlan.AuthOpen, Encryption: wlan.EncryptionNone},
+		{SSID: "office 5G", Authentication: wlan.AuthWPA2PSK, Encryption: wlan.EncryptionAES},
+		// the first profile of home is skipped, the second one is synced
+		{SSID: "home", Authentication: wlan.AuthWPA2PSK, Encryption: wlan.EncryptionAES},
+		{SSID: "home", Authentication: wlan.AuthWPA2PSK, Encryption: wlan.EncryptionAES, Passphrase: "homeP@ss"},
+	}
+	tests := map[string]struct {
+		cmdLine     string
+		wantResult  utils.ReturnCode
+		wantConfigs []config.WifiConfig
+	}{
+		"should pass - all supported profiles": {
+			cmdLine:    cmdBase,
+			wantResult: utils.Success,
+			wantConfigs: []config.WifiConfig{
+				{ProfileName: "office5G", SSID: "office-5G", Priority: 1, AuthenticationMethod: 6, EncryptionMethod: 4, PskPassphrase: "officeP@ss"},
+				{ProfileName: "lab", SSID: "lab", Priority: 2, AuthenticationMethod: 4, EncryptionMethod: 3, PskPassphrase: "labP@ss"},
+				{ProfileName: "home", SSID: "home", Priority: 3, AuthenticationMethod: 6, EncryptionMethod: 4, PskPassphrase: "homeP@ss"},
+			},
+		},
+		"should pass - selected ssid with a skipped profile": {
+			cmdLine:    cmdBase + " -ssid home",
+			wantResult: utils.Success,
+			wantConfigs: []config.WifiConfig{
+				{ProfileName: "home", SSID: "home", Priority: 1, AuthenticationMethod: 6, EncryptionMethod: 4, PskPassphrase: "homeP@ss"},
+			},
+		},
+		"should pass - selected ssid": {
+			cmdLine:    cmdBase + " -ssid lab",
+			wantResult: utils.Success,
+			wantConfigs: []config.WifiConfig{
+				{ProfileName: "lab", SSID: "lab", Priority: 1, AuthenticationMethod: 4, EncryptionMethod: 3, PskPassphrase: "labP@ss"},
+			},
+		},
+		"should fail - unknown ssid": {
+			cmdLine:    cmdBase + " -ssid lab,cafe",
+			wantResult: utils.SyncWifiFailed,
+		},
+		"should fail - selected ssid without a supported profile": {
+			cmdLine:    cmdBase + " -ssid lab,corp",
+			wantResult: utils.SyncWifiFailed,
+		},
+		"should fail - no supported profile selected": {
+			cmdLine:    cmdBase + " -ssid corp",
+			wantResult: utils.SyncWifiFailed,
+		},
+		"should fail - syncwifi with url": {
+			cmdLine:    cmdBase + " -u wss://localhost",
+			wantResult: utils.InvalidParameterCombination,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			flags.wlanProfiles = func() ([]wlan.Profile, error) { return hostProfiles, nil }
+			gotResult := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, gotResult)
+			assert.Equal(t, utils.SubCommandSyncWifi, flags.SubCommand)
+			if tc.wantResult == utils.Success {
+				assert.True(t, flags.Local)
+				assert.Equal(t, tc.wantConfigs, []config.WifiConfig(flags.LocalConfig.WifiConfigs))
+			}
+		})
+	}
+	t.Run("should fail - profiles can not be read", func(t *testing.T) {
+		flags := NewFlags(strings.Fields(cmdBase))
+		flags.wlanProfiles = func() ([]wlan.Profile, error) { return nil, wlan.ErrUnsupported }
+		assert.Equal(t, utils.SyncWifiFailed, flags.ParseFlags())
+	})
+}
+
+func TestWifiProfileName(t *testing.T) {
+	names := map[string]bool{}
+	assert.Equal(t, "office5G", wifiProfileName("office-5G", names))
+	assert.Equal(t, "office5G2", wifiProfileName("office 5G", names))
+	assert.Equal(t, "wifi", wifiProfileName("---", names))
+	assert.Len(t, wifiProfileName(strings.Repeat("a", 40), names), 30)
+}
+
+func TestSyncDeviceInfoExclude(t *testing.T) {
+	f := NewFlags([]string{"./rpc", "maintenance", "syncdeviceinfo", "-exclude", "hostname, ipaddress,hostname", "-u", "wss://localhost", "-password", "P@ssw0rd"})
+	assert.Equal(t, utils.Success, f.ParseFlags())
+	assert.Equal(t, []string{DeviceInfoHostname, DeviceInfoIPAddress}, f.SyncDeviceInfo.Exclude)
+	assert.True(t, f.SyncDeviceInfo.Excludes(DeviceInfoIPAddress))
+	assert.False(t, f.SyncDeviceInfo.Excludes(DeviceInfoHardware))
+}
+
+func TestChangePasswordVault(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "s.token", r.Header.Get("X-Vault-Token"))
+		if r.URL.Path == "/v1/sys/internal/ui/mounts/secret/amt/device1" {
+			_, _ = w.Write([]byte(`{"data":{"path":"secret/","type":"kv","options":{"version":"2"}}}`))
+			return
+		}
+		assert.Equal(t, "/v1/secret/data/amt/device1", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"Vault-P@ssw0rd"},"metadata":{"version":1}}}`))
+	}))
+	defer vault.Close()
+	storeURL := "vault+http://" + strings.TrimPrefix(vault.URL, "http://") + "/secret/amt/device1"
+	f := NewFlags([]string{"./rpc", "maintenance", "changepassword", "-generate", "-vault", storeURL, "-vaultToken", "s.token"})
+	assert.Equal(t, utils.Success, f.ParseFlags())
+	assert.Equal(t, "Vault-P@ssw0rd", f.Password, "the current password is read from the store")
+	assert.Contains(t, f.Secrets(), "s.token")
+}
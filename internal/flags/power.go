@@ -0,0 +1,66 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+)
+
+type PowerFlags struct {
+	// BootToBIOS and BootToPXE select the device used for the next boot only
+	BootToBIOS bool
+	BootToPXE  bool
+}
+
+func (f *Flags) printPowerUsage() string {
+	usage := powerUsage.text()
+	f.printText(usage)
+	return usage
+}
+
+func (f *Flags) handlePowerCommand() error {
+	if len(f.commandLineArgs) == 2 {
+		f.printPowerUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	f.SubCommand = f.commandLineArgs[2]
+	switch f.SubCommand {
+	case utils.SubCommandPowerOn, utils.SubCommandPowerOff, utils.SubCommandPowerReset, utils.SubCommandPowerCycle:
+	default:
+		f.printPowerUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+
+	f.amtPowerCommand.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(f.amtPowerCommand)
+	f.setupTimeoutFlag(f.amtPowerCommand)
+	f.setupTelemetryFlag(f.amtPowerCommand)
+	f.setupRemoteFlags(f.amtPowerCommand)
+	f.amtPowerCommand.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	f.amtPowerCommand.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	f.amtPowerCommand.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	f.amtPowerCommand.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	f.amtPowerCommand.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	f.amtPowerCommand.BoolVar(&f.DryRun, "dryrun", false, dryRunUsage)
+	f.amtPowerCommand.String(defaultsFlag, "", defaultsUsage)
+	f.amtPowerCommand.BoolVar(&f.Power.BootToBIOS, "bootToBIOS", false, "Boot into the BIOS setup on the next boot")
+	f.amtPowerCommand.BoolVar(&f.Power.BootToPXE, "bootToPXE", false, "Boot from the network (PXE) on the next boot")
+	if err := f.parseWithDefaults(f.amtPowerCommand, f.commandLineArgs[3:]); err != nil || f.amtPowerCommand.NArg() > 0 {
+		f.printPowerUsage()
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if f.Power.BootToBIOS && f.Power.BootToPXE {
+		return newError(utils.InvalidParameterCombination, "error.power.biosOrPXE")
+	}
+	if f.SubCommand == utils.SubCommandPowerOff && (f.Power.BootToBIOS || f.Power.BootToPXE) {
+		return newError(utils.InvalidParameterCombination, "error.power.bootOptionsWithOff")
+	}
+
+	// power actions are sent to AMT directly
+	f.Local = true
+	if f.Password == "" {
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlePowerCommand(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantPower  PowerFlags
+	}{
+		"should accept on": {
+			cmdLine:    "./rpc power on -password P@ssw0rd",
+			wantResult: utils.Success,
+		},
+		"should accept off": {
+			cmdLine:    "./rpc power off -password P@ssw0rd",
+			wantResult: utils.Success,
+		},
+		"should accept reset to BIOS": {
+			cmdLine:    "./rpc power reset -password P@ssw0rd -bootToBIOS",
+			wantResult: utils.Success,
+			wantPower:  PowerFlags{BootToBIOS: true},
+		},
+		"should accept cycle to PXE": {
+			cmdLine:    "./rpc power cycle -password P@ssw0rd -bootToPXE",
+			wantResult: utils.Success,
+			wantPower:  PowerFlags{BootToPXE: true},
+		},
+		"should fail with both boot options": {
+			cmdLine:    "./rpc power reset -password P@ssw0rd -bootToBIOS -bootToPXE",
+			wantResult: utils.InvalidParameterCombination,
+			wantPower:  PowerFlags{BootToBIOS: true, BootToPXE: true},
+		},
+		"should fail off with a boot option": {
+			cmdLine:    "./rpc power off -password P@ssw0rd -bootToPXE",
+			wantResult: utils.InvalidParameterCombination,
+			wantPower:  PowerFlags{BootToPXE: true},
+		},
+		"should fail on extra arguments": {
+			cmdLine:    "./rpc power on -password P@ssw0rd now",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on unknown subcommand": {
+			cmdLine:    "./rpc power hibernate -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail without subcommand": {
+			cmdLine:    "./rpc power",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandPower, flags.Command)
+			assert.Equal(t, tc.wantPower, flags.Power)
+			assert.Equal(t, tc.wantResult == utils.Success, flags.Local)
+		})
+	}
+}
+
+func TestPrintPowerUsage(t *testing.T) {
+	flags := NewFlags([]string{"./rpc", "power"})
+	usage := flags.printPowerUsage()
+	assert.Contains(t, usage, "power reset")
+	assert.Contains(t, usage, "-bootToBIOS and -bootToPXE")
+}
@@ -0,0 +1,230 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"rpc/internal/ntp"
+	"rpc/internal/output"
+	"rpc/internal/pki"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+)
+
+// PrecheckFlags select the checks of activate -precheck
+type PrecheckFlags struct {
+	Enabled bool
+	// MaxSkew is the clock difference to the server or NTP time above which the clock check fails
+	MaxSkew time.Duration
+}
+
+// ntpQuery reads the time of an NTP server, it is replaced in tests
+var ntpQuery = ntp.Query
+
+// PrecheckResult is one check of the activate -precheck report
+type PrecheckResult struct {
+	Name       string           `json:"name"`
+	Status     string           `json:"status"`
+	Detail     string           `json:"detail,omitempty"`
+	ReturnCode utils.ReturnCode `json:"returnCode,omitempty"`
+}
+
+func (c wizardCheck) result() PrecheckResult {
+	result := PrecheckResult{Name: c.name, Status: "PASS", Detail: c.detail}
+	if c.failed {
+		result.Status, result.ReturnCode = "FAIL", c.rc
+	} else if c.warn {
+		result.Status = "WARN"
+	}
+	return result
+}
+
+// activatePrecheck checks the device before anything is sent to the server and prints the
+// report. The first failed check stops the activation with its return code.
+func (f *Flags) activatePrecheck() error {
+	checks := f.precheckChecks()
+	results := make([]PrecheckResult, 0, len(checks))
+	var failed *wizardCheck
+	for i := range checks {
+		results = append(results, checks[i].result())
+		if checks[i].failed && failed == nil {
+			failed = &checks[i]
+		}
+	}
+	status := "PASS"
+	if failed != nil {
+		status = "FAIL"
+	}
+
+	w := output.NewWriter(output.FormatFromFlags(f.JsonOutput, f.YamlOutput))
+	w.Field("checks", "", results)
+	w.Field("status", "", status)
+	w.Println("Pre-activation checks:")
+	for _, check := range checks {
+		w.Println(check.String())
+	}
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	if failed != nil {
+		return newError(failed.rc, "error.activate.precheckFailed", failed.name)
+	}
+	return nil
+}
+
+// precheckChecks adds the clock, certificate hash and provisioning certificate checks to
+// the checks of the activation wizard
+func (f *Flags) precheckChecks() []wizardCheck {
+	checks := f.activateChecks()
+	if len(checks) == 1 && checks[0].failed {
+		// the MEI is not available
+		return checks
+	}
+	checks = append(checks, f.clockCheck())
+
+	var root *x509.Certificate
+	if f.Local && f.UseACM {
+		var check wizardCheck
+		root, check = f.provisioningCertCheck()
+		checks = append(checks, check)
+	}
+	return append(checks, f.certHashCheck(root))
+}
+
+// provisioningCertCheck checks the DNS suffix of the device is the domain of the provisioning
+// certificate, and returns the root certificate of its chain
+func (f *Flags) provisioningCertCheck() (*x509.Certificate, wizardCheck) {
+	check := wizardCheck{name: "Provisioning certificate domain"}
+	chain, err := pki.DecodePFX(f.LocalConfig.ACMSettings.ProvisioningCert, f.LocalConfig.ACMSettings.ProvisioningCertPwd)
+	if err != nil {
+		check.failed, check.detail, check.rc = true, err.Error(), utils.InvalidProvisioningCert
+		return nil, check
+	}
+	suffix := f.DNS
+	if suffix == "" {
+		suffix, _ = f.amtCommand.GetDNSSuffix()
+	}
+	if suffix == "" {
+		suffix, _ = f.amtCommand.GetOSDNSSuffix()
+	}
+	commonName := chain.Leaf().Subject.CommonName
+	check.detail = commonName
+	if !certDomainMatches(commonName, suffix) {
+		check.failed, check.rc = true, utils.DNSSuffixMismatch
+		check.detail = fmt.Sprintf("%s does not match the DNS suffix %q", commonName, suffix)
+	}
+	return chain.Root(), check
+}
+
+// certDomainMatches reports whether the DNS suffix is the domain of the certificate
+// common name, either the name itself or the domain below its host or wildcard label
+func certDomainMatches(commonName string, suffix string) bool {
+	if suffix == "" {
+		return false
+	}
+	commonName = strings.ToLower(strings.TrimPrefix(commonName, "*."))
+	suffix = strings.ToLower(strings.TrimSuffix(suffix, "."))
+	return commonName == suffix || strings.HasSuffix(commonName, "."+suffix)
+}
+
+// certHashCheck checks AMT has an active trusted root hash, the one of the root of the
+// provisioning certificate when it is known
+func (f *Flags) certHashCheck(root *x509.Certificate) wizardCheck {
+	check := wizardCheck{name: "Certificate hashes"}
+	hashes, err := f.amtCommand.GetCertificateHashes()
+	if err != nil {
+		check.failed, check.detail, check.rc = true, err.Error(), utils.AMTConnectionFailed
+		return check
+	}
+	if root != nil {
+		hash, err := pki.MatchHash(root, hashes)
+		if err != nil {
+			check.failed, check.detail, check.rc = true, err.Error(), utils.CertHashNotFound
+			return check
+		}
+		check.detail = hash.Name
+		return check
+	}
+	active := 0
+	for _, hash := range hashes {
+		if hash.IsActive {
+			active++
+		}
+	}
+	check.detail = fmt.Sprintf("%d active", active)
+	if active == 0 {
+		check.failed, check.rc = true, utils.CertHashNotFound
+		check.detail = "AMT has no active trusted root certificate hashes"
+	}
+	return check
+}
+
+// clockCheck compares the host clock with the NTP server given with -ntp, or the Date
+// header of the activation server. Certificates are only accepted within their validity.
+func (f *Flags) clockCheck() wizardCheck {
+	check := wizardCheck{name: "Clock skew"}
+	var reference time.Time
+	var source string
+	var err error
+	switch {
+	case f.NTPServer != "":
+		source = f.NTPServer
+		reference, err = ntpQuery(f.NTPServer, serverProbeTimeout)
+	case !f.Local && f.Proxy == "":
+		source = "the server"
+		// the servers of a failover list are expected to keep the same time
+		reference, err = serverDate(strings.TrimSpace(strings.Split(f.URL, ",")[0]))
+	default:
+		check.warn, check.detail = true, "not checked, -ntp selects the NTP server to compare with"
+		return check
+	}
+	if err != nil {
+		check.warn, check.detail = true, fmt.Sprintf("not checked, %s did not answer: %s", source, err)
+		return check
+	}
+	skew := time.Since(reference).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	check.detail = fmt.Sprintf("%s to %s", skew, source)
+	if skew > f.Precheck.MaxSkew {
+		check.failed, check.rc = true, utils.ClockSkewExceeded
+		check.detail = fmt.Sprintf("%s to %s is above %s", skew, source, f.Precheck.MaxSkew)
+	}
+	return check
+}
+
+// serverDate returns the time in the Date header of the server answering the websocket URL.
+// The certificate is not verified as only the date is read, the connection check verifies it.
+func serverDate(serverURL string) (time.Time, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	u.Scheme = strings.Replace(u.Scheme, "ws", "http", 1)
+	client := &http.Client{
+		Timeout: serverProbeTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	rsp, err := client.Head(u.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+	rsp.Body.Close()
+	date := rsp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, errors.New("no Date header")
+	}
+	return http.ParseTime(date)
+}
@@ -0,0 +1,99 @@
+package flags
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertDomainMatches(t *testing.T) {
+	tests := []struct {
+		commonName string
+		suffix     string
+		want       bool
+	}{
+		{"vprodemo.com", "vprodemo.com", true},
+		{"amt.vprodemo.com", "vprodemo.com", true},
+		{"*.vprodemo.com", "VProDemo.com.", true},
+		{"vprodemo.com", "", false},
+		{"notvprodemo.com", "vprodemo.com", false},
+		{"vprodemo.com", "other.com", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, certDomainMatches(tt.commonName, tt.suffix), tt.commonName+" "+tt.suffix)
+	}
+}
+
+func TestServerDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", "Wed, 21 Oct 2015 07:28:00 GMT")
+	}))
+	defer server.Close()
+	date, err := serverDate(strings.Replace(server.URL, "http", "ws", 1) + "/activate")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), date)
+}
+
+func TestClockCheck(t *testing.T) {
+	defer func(query func(string, time.Duration) (time.Time, error)) { ntpQuery = query }(ntpQuery)
+	f := NewFlags([]string{"./rpc", "activate"})
+	f.Local = true
+	f.Precheck.MaxSkew = time.Minute
+
+	check := f.clockCheck()
+	assert.True(t, check.warn)
+
+	f.NTPServer = "pool.ntp.org"
+	ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Now().Add(-30 * time.Second), nil }
+	check = f.clockCheck()
+	assert.False(t, check.failed)
+	assert.False(t, check.warn)
+
+	ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Now().Add(5 * time.Minute), nil }
+	check = f.clockCheck()
+	assert.True(t, check.failed)
+	assert.Equal(t, utils.ClockSkewExceeded, check.rc)
+
+	ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Time{}, errors.New("timeout") }
+	check = f.clockCheck()
+	assert.True(t, check.warn)
+	assert.False(t, check.failed)
+}
+
+func TestHandleActivateCommandPrecheck(t *testing.T) {
+	defer func(query func(string, time.Duration) (time.Time, error)) { ntpQuery = query }(ntpQuery)
+	ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Now(), nil }
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	newFlags := func(args ...string) *Flags {
+		args = append([]string{"./rpc", "activate", "-precheck", "-u", "wss://" + listener.Addr().String(), "-profile", "profile", "-ntp", "pool.ntp.org", "-json"}, args...)
+		f := NewFlags(args)
+		f.amtCommand.PTHI = MockPTHICommands{}
+		return f
+	}
+
+	t.Run("stops without active certificate hashes", func(t *testing.T) {
+		assert.Equal(t, utils.CertHashNotFound, newFlags().ParseFlags())
+	})
+	t.Run("stops when the clock is off", func(t *testing.T) {
+		ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Now().Add(time.Hour), nil }
+		defer func() { ntpQuery = func(string, time.Duration) (time.Time, error) { return time.Now(), nil } }()
+		assert.Equal(t, utils.ClockSkewExceeded, newFlags().ParseFlags())
+	})
+	t.Run("stops on an activated device", func(t *testing.T) {
+		mode = 1
+		defer func() { mode = 0 }()
+		assert.Equal(t, utils.UnableToActivate, newFlags().ParseFlags())
+	})
+	t.Run("rejects a negative -maxSkew", func(t *testing.T) {
+		assert.Equal(t, utils.IncorrectCommandLineParameters, newFlags("-maxSkew", "-1m").ParseFlags())
+	})
+}
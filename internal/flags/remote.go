@@ -0,0 +1,111 @@
+package flags
+
+import (
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AMTPort and AMTTLSPort are the ports AMT listens on for wsman over the network
+	AMTPort    = 16992
+	AMTTLSPort = 16993
+	// defaultAMTUser is the digest user with the AMT password
+	defaultAMTUser = "admin"
+)
+
+// RemoteFlags select an AMT device reached over the network instead of the local MEI and LMS
+type RemoteFlags struct {
+	Host string
+	// Port is the AMT port of Host, AMTPort or AMTTLSPort when 0
+	Port int
+	TLS  bool
+	User string
+	// CACertFile holds the PEM CA certificates that verify the TLS certificate of AMT, the
+	// system roots when empty
+	CACertFile    string
+	SkipCertCheck bool
+	// CACerts are read from CACertFile
+	CACerts *x509.CertPool
+}
+
+// setupRemoteFlags adds the flags selecting a remote AMT device, for the commands that only talk wsman
+func (f *Flags) setupRemoteFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.Remote.Host, "host", "", "host name or IP address of a remote AMT device, wsman is sent to it over the network instead of to the local LMS")
+	fs.IntVar(&f.Remote.Port, "amtPort", 0, fmt.Sprintf("AMT port of -host (default %d, %d with -tls)", AMTPort, AMTTLSPort))
+	fs.BoolVar(&f.Remote.TLS, "tls", false, "connect to -host with TLS")
+	fs.StringVar(&f.Remote.User, "user", defaultAMTUser, "digest user of -host")
+	fs.StringVar(&f.Remote.CACertFile, "amtCACert", "", "PEM file with the CA certificates that verify the TLS certificate of -host (default the system roots)")
+	fs.BoolVar(&f.Remote.SkipCertCheck, "skipAMTCertCheck", false, "do not verify the TLS certificate of -host")
+}
+
+// IsRemote reports whether the command targets an AMT device over the network
+func (f *Flags) IsRemote() bool {
+	return f.Remote.Host != ""
+}
+
+// RemoteURL returns the wsman URL of the remote AMT device
+func (f *Flags) RemoteURL() string {
+	scheme := "http"
+	if f.Remote.TLS {
+		scheme = "https"
+	}
+	return scheme + "://" + f.RemoteAddress() + "/wsman"
+}
+
+// RemoteAddress returns the host:port of the remote AMT device
+func (f *Flags) RemoteAddress() string {
+	port := AMTPort
+	if f.Remote.TLS {
+		port = AMTTLSPort
+	}
+	if f.Remote.Port != 0 {
+		port = f.Remote.Port
+	}
+	return net.JoinHostPort(f.Remote.Host, strconv.Itoa(port))
+}
+
+// validateRemote checks the remote device flags and reads the CA certificates of -amtCACert
+func (f *Flags) validateRemote() error {
+	remote := &f.Remote
+	if remote.Host == "" {
+		if remote.Port != 0 || remote.TLS || remote.CACertFile != "" || remote.SkipCertCheck {
+			return newError(utils.InvalidParameterCombination, "error.remote.hostRequired")
+		}
+		return nil
+	}
+	if strings.Contains(remote.Host, "/") {
+		return newError(utils.IncorrectCommandLineParameters, "error.remote.hostIsURL", remote.Host)
+	}
+	if remote.Port < 0 || remote.Port > 65535 {
+		return newError(utils.IncorrectCommandLineParameters, "error.remote.amtPort")
+	}
+	if remote.User == "" {
+		return newError(utils.IncorrectCommandLineParameters, "error.remote.emptyUser")
+	}
+	if !remote.TLS && (remote.CACertFile != "" || remote.SkipCertCheck) {
+		return newError(utils.InvalidParameterCombination, "error.remote.tlsRequired")
+	}
+	if remote.CACertFile != "" && remote.SkipCertCheck {
+		return newError(utils.InvalidParameterCombination, "error.remote.caCertWithSkipCheck")
+	}
+	if remote.CACertFile != "" {
+		pem, err := os.ReadFile(remote.CACertFile)
+		if err != nil {
+			return wrapError(utils.IncorrectCommandLineParameters, err, "error.remote.readCACert")
+		}
+		remote.CACerts = x509.NewCertPool()
+		if !remote.CACerts.AppendCertsFromPEM(pem) {
+			return newError(utils.IncorrectCommandLineParameters, "error.remote.noPEMCertificate", remote.CACertFile)
+		}
+	}
+	if !remote.TLS {
+		log.Warnf("wsman to %s is not encrypted, use -tls when TLS is configured in AMT", remote.Host)
+	}
+	return nil
+}
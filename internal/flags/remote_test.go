@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/internal/certtest"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteURL(t *testing.T) {
+	f := &Flags{Remote: RemoteFlags{Host: "amt.example.com"}}
+	assert.Equal(t, "http://amt.example.com:16992/wsman", f.RemoteURL())
+	f.Remote.TLS = true
+	assert.Equal(t, "https://amt.example.com:16993/wsman", f.RemoteURL())
+	f.Remote = RemoteFlags{Host: "fd00::10", Port: 8080}
+	assert.Equal(t, "http://[fd00::10]:8080/wsman", f.RemoteURL())
+}
+
+func TestValidateRemote(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, []byte(certtest.New("P@ssw0rd").CaPem), 0600))
+	cases := []struct {
+		description    string
+		remote         RemoteFlags
+		expectedResult utils.ReturnCode
+	}{
+		{description: "no remote device", expectedResult: utils.Success},
+		{description: "host", remote: RemoteFlags{Host: "amt.example.com", User: "admin"}, expectedResult: utils.Success},
+		{description: "tls with a CA certificate", remote: RemoteFlags{Host: "amt.example.com", User: "admin", TLS: true, CACertFile: caFile}, expectedResult: utils.Success},
+		{description: "port without host", remote: RemoteFlags{Port: 16993}, expectedResult: utils.InvalidParameterCombination},
+		{description: "url instead of host", remote: RemoteFlags{Host: "https://amt.example.com", User: "admin"}, expectedResult: utils.IncorrectCommandLineParameters},
+		{description: "port out of range", remote: RemoteFlags{Host: "amt.example.com", User: "admin", Port: 70000}, expectedResult: utils.IncorrectCommandLineParameters},
+		{description: "empty user", remote: RemoteFlags{Host: "amt.example.com"}, expectedResult: utils.IncorrectCommandLineParameters},
+		{description: "skip cert check without tls", remote: RemoteFlags{Host: "amt.example.com", User: "admin", SkipCertCheck: true}, expectedResult: utils.InvalidParameterCombination},
+		{description: "CA certificate and skip cert check", remote: RemoteFlags{Host: "amt.example.com", User: "admin", TLS: true, CACertFile: caFile, SkipCertCheck: true}, expectedResult: utils.InvalidParameterCombination},
+		{description: "missing CA certificate", remote: RemoteFlags{Host: "amt.example.com", User: "admin", TLS: true, CACertFile: caFile + ".missing"}, expectedResult: utils.IncorrectCommandLineParameters},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			f := &Flags{Remote: tc.remote}
+			assert.Equal(t, tc.expectedResult, rpcerr.ReturnCodeOf(f.validateRemote()))
+			if tc.remote.CACertFile != "" && tc.expectedResult == utils.Success {
+				assert.NotNil(t, f.Remote.CACerts)
+			}
+		})
+	}
+}
+
+func TestParseFlagsRemote(t *testing.T) {
+	t.Run("amtinfo shows the values a remote device reports", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc amtinfo -host amt.example.com -password P@ssw0rd -tls -skipAMTCertCheck"))
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.True(t, f.IsRemote())
+		assert.Equal(t, "admin", f.Remote.User)
+		assert.True(t, f.AmtInfo.Ver && f.AmtInfo.UUID && f.AmtInfo.Mode && f.AmtInfo.Ras && f.AmtInfo.RasDetails)
+		assert.False(t, f.AmtInfo.DNS || f.AmtInfo.Lan || f.AmtInfo.Hostname)
+	})
+	t.Run("amtinfo -cert reads the user certificates of a remote device", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc amtinfo -host=amt.example.com -password P@ssw0rd -cert -lan"))
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.False(t, f.AmtInfo.Cert)
+		assert.True(t, f.AmtInfo.UserCert)
+		assert.False(t, f.AmtInfo.Lan)
+		assert.False(t, f.AmtInfo.Ver, "selected values replace the defaults")
+	})
+	t.Run("amtinfo -sys checks a remote device", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc amtinfo -host amt.example.com -password P@ssw0rd -sys"))
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.True(t, f.AmtInfo.Sys)
+		assert.Equal(t, "amt.example.com:16992", f.RemoteAddress())
+	})
+	t.Run("power", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc power cycle -host 192.168.1.20 -amtPort 16995 -user operator -password P@ssw0rd"))
+		assert.Equal(t, utils.Success, f.ParseFlags())
+		assert.Equal(t, "http://192.168.1.20:16995/wsman", f.RemoteURL())
+		assert.Equal(t, "operator", f.Remote.User)
+	})
+	t.Run("configure dnssuffix is not available for a remote device", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc configure dnssuffix -value corp.example.com -host amt.example.com"))
+		assert.Equal(t, utils.IncorrectCommandLineParameters, f.ParseFlags())
+	})
+	t.Run("activate is not available for a remote device", func(t *testing.T) {
+		f := NewFlags(strings.Fields("./rpc activate -local -ccm -host amt.example.com -password P@ssw0rd"))
+		assert.Equal(t, utils.IncorrectCommandLineParameters, f.ParseFlags())
+	})
+}
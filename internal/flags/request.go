@@ -0,0 +1,142 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"rpc/internal/i18n"
+	"rpc/internal/lm"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"sort"
+)
+
+// NewRequest returns the flags of a command run in process by the rpc package, which
+// fills them in from its request instead of a command line. The flags of the command and
+// task have the defaults of the command line. Unlike the command line, the environment and
+// the defaults file are not read, usage texts are not printed and a missing value fails
+// instead of being prompted for. Validate checks the flags once they are filled in.
+func NewRequest(ctx context.Context, command, subCommand string) *Flags {
+	f := newFlags([]string{utils.ProjectName, command}, true)
+	f.Context = ctx
+	f.NonInteractive = true
+	f.Language = i18n.DefaultLanguage
+	f.Transport = lm.TransportAuto
+	f.Command, f.SubCommand = command, subCommand
+	switch command {
+	case utils.CommandActivate:
+		f.setupActivateFlags()
+	case utils.CommandDeactivate:
+		f.setupDeactivateFlags()
+	case utils.CommandMaintenance:
+		f.setupMaintenanceFlags(subCommand)
+	case utils.CommandAMTInfo:
+		f.setupAMTInfoFlags(f.amtInfoCommand)
+	}
+	for _, fs := range []*flag.FlagSet{
+		f.amtInfoCommand,
+		f.amtActivateCommand,
+		f.amtDeactivateCommand,
+		f.amtMaintenanceChangePasswordCommand,
+		f.amtMaintenanceSyncDeviceInfoCommand,
+		f.amtMaintenanceSyncClockCommand,
+		f.amtMaintenanceSyncHostnameCommand,
+		f.amtMaintenanceSyncIPCommand,
+		f.amtMaintenanceSyncDNSCommand,
+		f.amtMaintenanceSyncWifiCommand,
+		f.amtMaintenanceBatchCommand} {
+		fs.SetOutput(io.Discard)
+	}
+	return f
+}
+
+// SetConfigFile sets the configuration file or smb: share URL of a local ACM activation,
+// like -config
+func (f *Flags) SetConfigFile(path string) {
+	f.configContent = path
+}
+
+// Validate checks the flags of NewRequest with the rules of the command line and reads
+// what the command takes from the host OS, as Parse does after parsing the flags.
+// Maintenance runs the tasks of MaintenanceTasks when SubCommand is empty.
+func (f *Flags) Validate() error {
+	f.amtCommand.Context, f.amtCommand.Timeout = f.Context, f.Timeout
+	if f.ServerOrder != "" {
+		if err := f.setServerOrder(f.ServerOrder); err != nil {
+			return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+		}
+	}
+	if err := f.validateRequestTags(); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	var err error
+	switch f.Command {
+	case utils.CommandActivate:
+		err = f.validateActivate()
+	case utils.CommandDeactivate:
+		err = f.validateDeactivate()
+	case utils.CommandMaintenance:
+		if err = f.validateRequestAddresses(); err == nil {
+			err = f.validateMaintenanceTask()
+		}
+		if err == nil {
+			err = f.validateMaintenance()
+		}
+	case utils.CommandAMTInfo:
+		err = f.validateAMTInfo(f.amtInfoCommand, f.AmtInfo == AmtInfoFlags{})
+	default:
+		err = rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	if err != nil {
+		return err
+	}
+	return f.validateConnection()
+}
+
+// validateRequestTags checks the tags of a request like -tag, in the order of their keys
+func (f *Flags) validateRequestTags() error {
+	tags := f.Tags
+	f.Tags = nil
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := f.addTag(key + "=" + tags[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRequestAddresses checks the IP configuration of a maintenance request, the
+// command line checks it while the flags are parsed
+func (f *Flags) validateRequestAddresses() error {
+	addresses := []struct {
+		value string
+		check func(*string) func(string) error
+		rc    utils.ReturnCode
+	}{
+		{f.IpConfiguration.IpAddress, validateIPv4, utils.MissingOrIncorrectStaticIP},
+		{f.IpConfiguration.Netmask, validateIPv4, utils.MissingOrIncorrectNetworkMask},
+		{f.IpConfiguration.Gateway, validateIPv4, utils.MissingOrIncorrectGateway},
+		{f.IpConfiguration.PrimaryDns, validateIP, utils.MissingOrIncorrectPrimaryDNS},
+		{f.IpConfiguration.SecondaryDns, validateIP, utils.MissingOrIncorrectSecondaryDNS},
+	}
+	for _, address := range addresses {
+		if address.value == "" {
+			continue
+		}
+		var value string
+		if err := address.check(&value)(address.value); err != nil {
+			return rpcerr.Wrap(address.rc, fmt.Errorf("%s: %w", address.value, err), "")
+		}
+	}
+	return nil
+}
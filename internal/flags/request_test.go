@@ -0,0 +1,208 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"context"
+	"rpc/internal/lm"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequest(t *testing.T) {
+	t.Setenv("AMT_PASSWORD", "P@ssw0rd")
+	t.Setenv("RPC_TRANSPORT", "lme")
+	f := NewRequest(context.Background(), utils.CommandMaintenance, utils.SubCommandSyncDeviceInfo)
+	assert.Equal(t, utils.CommandMaintenance, f.Command)
+	assert.Equal(t, utils.SubCommandSyncDeviceInfo, f.SubCommand)
+	assert.True(t, f.NonInteractive)
+	assert.Equal(t, "", f.Password, "the environment is not read")
+	assert.Equal(t, lm.TransportAuto, f.Transport)
+	assert.Equal(t, 3, f.Retries)
+	assert.Equal(t, 15*time.Minute, f.SyncDeviceInfo.Interval, "the flags of the task have their defaults")
+
+	f = NewRequest(context.Background(), utils.CommandAMTInfo, "")
+	assert.True(t, f.Local)
+	assert.Equal(t, DefaultInfoCacheTTL, f.InfoCache.TTL)
+}
+
+func TestValidateRequest(t *testing.T) {
+	tests := map[string]struct {
+		command    string
+		subCommand string
+		fill       func(f *Flags)
+		wantResult utils.ReturnCode
+		check      func(t *testing.T, f *Flags)
+	}{
+		"remote activation": {
+			command: utils.CommandActivate,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Profile = "profile01"
+				f.Tags = map[string]string{"site": "berlin"}
+				f.ServerOrder = ServerOrderLatency
+			},
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.Equal(t, map[string]string{"site": "berlin"}, f.Tags)
+			},
+		},
+		"remote activation needs a profile": {
+			command:    utils.CommandActivate,
+			fill:       func(f *Flags) { f.URL = "wss://localhost" },
+			wantResult: utils.MissingOrIncorrectProfile,
+		},
+		"invalid server order": {
+			command: utils.CommandActivate,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Profile = "profile01"
+				f.ServerOrder = "random"
+			},
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"invalid tag": {
+			command: utils.CommandActivate,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Profile = "profile01"
+				f.Tags = map[string]string{"site name": "berlin"}
+			},
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"partial deactivation runs locally": {
+			command: utils.CommandDeactivate,
+			fill: func(f *Flags) {
+				f.Password = "P@ssw0rd"
+				f.PartialDeactivate = true
+			},
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.True(t, f.Local)
+			},
+		},
+		"partial deactivation with a server": {
+			command: utils.CommandDeactivate,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.PartialDeactivate = true
+			},
+			wantResult: utils.InvalidParameterCombination,
+		},
+		"remote deactivation needs the password": {
+			command:    utils.CommandDeactivate,
+			fill:       func(f *Flags) { f.URL = "wss://localhost" },
+			wantResult: utils.MissingOrIncorrectPassword,
+		},
+		"syncclock with -ntp runs locally": {
+			command:    utils.CommandMaintenance,
+			subCommand: utils.SubCommandSyncClock,
+			fill: func(f *Flags) {
+				f.Password = "P@ssw0rd"
+				f.NTPServer = "pool.ntp.org"
+			},
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.True(t, f.Local)
+				assert.Equal(t, time.Local, f.SyncClock.TimeZone)
+				assert.Equal(t, "P@ssw0rd", f.LocalConfig.Password)
+			},
+		},
+		"syncclock needs a server or -ntp": {
+			command:    utils.CommandMaintenance,
+			subCommand: utils.SubCommandSyncClock,
+			fill:       func(f *Flags) { f.Password = "P@ssw0rd" },
+			wantResult: utils.MissingOrIncorrectURL,
+		},
+		"invalid static ip": {
+			command:    utils.CommandMaintenance,
+			subCommand: utils.SubCommandSyncIP,
+			fill: func(f *Flags) {
+				f.Password = "P@ssw0rd"
+				f.IpConfiguration.IpAddress = "fd00::1"
+			},
+			wantResult: utils.MissingOrIncorrectStaticIP,
+		},
+		"invalid static password": {
+			command:    utils.CommandMaintenance,
+			subCommand: utils.SubCommandChangePassword,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Password = "P@ssw0rd"
+				f.StaticPassword = "weak"
+			},
+			wantResult: utils.MissingOrIncorrectPassword,
+		},
+		"unknown task": {
+			command:    utils.CommandMaintenance,
+			subCommand: "syncall",
+			fill:       func(f *Flags) { f.Password = "P@ssw0rd" },
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"tasks": {
+			command: utils.CommandMaintenance,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Password = "P@ssw0rd"
+				f.MaintenanceTasks = []string{utils.SubCommandSyncClock, utils.SubCommandSyncDeviceInfo}
+			},
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.Equal(t, "syncclock,syncdeviceinfo", f.SubCommand)
+			},
+		},
+		"task twice": {
+			command: utils.CommandMaintenance,
+			fill: func(f *Flags) {
+				f.URL = "wss://localhost"
+				f.Password = "P@ssw0rd"
+				f.MaintenanceTasks = []string{utils.SubCommandSyncClock, utils.SubCommandSyncClock}
+			},
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"amtinfo selects the defaults without a selection": {
+			command:    utils.CommandAMTInfo,
+			fill:       func(f *Flags) {},
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.Equal(t, AmtInfoFlags{Ver: true, Bld: true, Sku: true, UUID: true, Mode: true,
+					DNS: true, Ras: true, Lan: true, Hostname: true}, f.AmtInfo)
+			},
+		},
+		"amtinfo keeps the selection": {
+			command:    utils.CommandAMTInfo,
+			fill:       func(f *Flags) { f.AmtInfo.UUID = true },
+			wantResult: utils.Success,
+			check: func(t *testing.T, f *Flags) {
+				assert.Equal(t, AmtInfoFlags{UUID: true}, f.AmtInfo)
+			},
+		},
+		"amtinfo -clear needs -eventlog": {
+			command:    utils.CommandAMTInfo,
+			fill:       func(f *Flags) { f.AmtInfo.EventLogClear = true },
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"unknown command": {
+			command:    utils.CommandWSMAN,
+			fill:       func(f *Flags) {},
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := NewRequest(context.Background(), tc.command, tc.subCommand)
+			tc.fill(f)
+			err := f.Validate()
+			assert.Equal(t, tc.wantResult, rpcerr.ReturnCodeOf(err))
+			if tc.check != nil {
+				tc.check(t, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+)
+
+func (f *Flags) handleReturnCodesCommand() error {
+	if err := f.parse(f.returnCodesCommand, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	// runs locally
+	f.Local = true
+	return nil
+}
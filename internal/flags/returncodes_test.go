@@ -0,0 +1,22 @@
+package flags
+
+import (
+	"github.com/stretchr/testify/assert"
+	"rpc/pkg/utils"
+	"testing"
+)
+
+func TestHandleReturnCodesCommand(t *testing.T) {
+	t.Run("runs locally", func(t *testing.T) {
+		f := NewFlags([]string{"rpc", "returncodes", "-json"})
+		result := f.ParseFlags()
+		assert.Equal(t, utils.Success, result)
+		assert.Equal(t, true, f.Local)
+		assert.Equal(t, true, f.JsonOutput)
+	})
+	t.Run("rejects unknown flags", func(t *testing.T) {
+		f := NewFlags([]string{"rpc", "returncodes", "-nope"})
+		result := f.ParseFlags()
+		assert.Equal(t, utils.IncorrectCommandLineParameters, result)
+	})
+}
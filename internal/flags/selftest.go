@@ -0,0 +1,26 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+)
+
+func (f *Flags) handleSelfTestCommand() error {
+	fs := f.selfTestCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.LMSAddress, "lmsaddress", utils.LMSAddress, "LMS address to check")
+	fs.StringVar(&f.LMSPort, "lmsport", utils.LMSPort, "LMS port to check")
+	if err := f.parse(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	// the checks run on this host, a missing driver is reported rather than failing the command
+	f.Local = true
+	return nil
+}
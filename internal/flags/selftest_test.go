@@ -0,0 +1,48 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSelfTestCommand(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine     string
+		wantResult  utils.ReturnCode
+		wantLMSPort string
+	}{
+		"should accept no flags": {
+			cmdLine:     "./rpc selftest",
+			wantResult:  utils.Success,
+			wantLMSPort: utils.LMSPort,
+		},
+		"should accept json and lms port": {
+			cmdLine:     "./rpc selftest -json -lmsport 16993",
+			wantResult:  utils.Success,
+			wantLMSPort: "16993",
+		},
+		"should fail on unknown flag": {
+			cmdLine:     "./rpc selftest -password P@ssw0rd",
+			wantResult:  utils.IncorrectCommandLineParameters,
+			wantLMSPort: utils.LMSPort,
+		},
+		"should fail on extra arguments": {
+			cmdLine:     "./rpc selftest now",
+			wantResult:  utils.IncorrectCommandLineParameters,
+			wantLMSPort: utils.LMSPort,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandSelfTest, flags.Command)
+			assert.Equal(t, tc.wantLMSPort, flags.LMSPort)
+			assert.Equal(t, tc.wantResult == utils.Success, flags.Local)
+		})
+	}
+}
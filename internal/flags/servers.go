@@ -0,0 +1,68 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ServerOrderListed tries the servers of -u in the order they are given
+	ServerOrderListed = "listed"
+	// ServerOrderLatency tries the server that answers a TCP connection fastest first
+	ServerOrderLatency = "latency"
+
+	urlUsage         = "Websocket address of the server, a comma separated list or repeated -u for failover between servers"
+	serverOrderUsage = "Order in which the servers of -u are tried: listed or latency"
+)
+
+// urlListValue collects the servers of -u into a comma separated list. Servers from the
+// defaults file or the environment are replaced by the servers on the command line.
+type urlListValue struct {
+	url     *string
+	replace bool
+}
+
+func (v *urlListValue) String() string {
+	if v.url == nil {
+		return ""
+	}
+	return *v.url
+}
+
+func (v *urlListValue) Set(value string) error {
+	if v.replace || *v.url == "" {
+		*v.url = value
+		v.replace = false
+		return nil
+	}
+	*v.url += "," + value
+	return nil
+}
+
+// defaultsApplied makes the next value replace the servers set so far
+func (v *urlListValue) defaultsApplied() {
+	v.replace = true
+}
+
+func (f *Flags) setServerOrder(value string) error {
+	if value != ServerOrderListed && value != ServerOrderLatency {
+		return fmt.Errorf("%q is not a server order, use %s or %s", value, ServerOrderListed, ServerOrderLatency)
+	}
+	f.ServerOrder = value
+	return nil
+}
+
+// ServerURLs returns the servers given with -u in the order they were given
+func (f *Flags) ServerURLs() []string {
+	var urls []string
+	for _, url := range strings.Split(f.URL, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,34 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerURLs(t *testing.T) {
+	t.Run("comma separated list", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-u", "wss://east/activate, wss://west/activate", "-password", "P@ssw0rd"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, []string{"wss://east/activate", "wss://west/activate"}, flags.ServerURLs())
+	})
+	t.Run("repeated -u", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-u", "wss://east/activate", "-u", "wss://west/activate", "-password", "P@ssw0rd", "-serverOrder", "latency"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, []string{"wss://east/activate", "wss://west/activate"}, flags.ServerURLs())
+		assert.Equal(t, ServerOrderLatency, flags.ServerOrder)
+	})
+	t.Run("rejects an unknown order", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "deactivate", "-u", "wss://east/activate", "-password", "P@ssw0rd", "-serverOrder", "random"})
+		assert.Equal(t, utils.IncorrectCommandLineParameters, flags.ParseFlags())
+	})
+	t.Run("no server", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc"})
+		assert.Empty(t, flags.ServerURLs())
+	})
+}
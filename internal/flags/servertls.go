@@ -0,0 +1,70 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"flag"
+	"os"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+const pinSHA256Prefix = "sha256//"
+
+// ServerTLSFlags hold the trust settings of the wss:// connection to the server
+type ServerTLSFlags struct {
+	CACertFile string
+	PinSHA256  string
+	// RootCAs and Pins are loaded from CACertFile and PinSHA256 when the flags are parsed
+	RootCAs *x509.CertPool
+	Pins    [][]byte
+}
+
+func (f *Flags) setupServerTLSFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.SkipCertCheck, "insecure-skip-verify", false, "Skip Websocket server certificate verification. The connection can be intercepted, use -pin-sha256 instead")
+	fs.StringVar(&f.ServerTLS.CACertFile, "cacert", "", "PEM file with the CA certificates that verify the Websocket server certificate, in addition to the system roots")
+	fs.StringVar(&f.ServerTLS.PinSHA256, "pin-sha256", "", "Comma separated base64 SHA-256 hashes of the public key of the Websocket server or one of its CAs, ex. 'sha256//BASE64'")
+}
+
+// validateServerTLS loads the CA certificates and public key pins of the server connection
+func (f *Flags) validateServerTLS() utils.ReturnCode {
+	if f.ServerTLS.CACertFile != "" {
+		if f.SkipCertCheck {
+			log.Error("-cacert cannot be used when server certificate verification is skipped")
+			return utils.InvalidParameterCombination
+		}
+		data, err := os.ReadFile(f.ServerTLS.CACertFile)
+		if err != nil {
+			log.Error("unable to read -cacert: ", err)
+			return utils.MissingOrIncorrectCACert
+		}
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(data) {
+			log.Error("no PEM certificates found in ", f.ServerTLS.CACertFile)
+			return utils.MissingOrIncorrectCACert
+		}
+		f.ServerTLS.RootCAs = rootCAs
+	}
+	f.ServerTLS.Pins = nil
+	for _, pin := range strings.Split(f.ServerTLS.PinSHA256, ",") {
+		pin = strings.TrimPrefix(strings.TrimSpace(pin), pinSHA256Prefix)
+		if pin == "" {
+			continue
+		}
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			log.Error("-pin-sha256 must be base64 encoded SHA-256 hashes: ", pin)
+			return utils.MissingOrIncorrectCACert
+		}
+		f.ServerTLS.Pins = append(f.ServerTLS.Pins, hash)
+	}
+	return utils.Success
+}
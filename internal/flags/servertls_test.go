@@ -0,0 +1,68 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateServerTLS(t *testing.T) {
+	certPath := writeTestCertificate(t)
+	pin := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	cases := []struct {
+		description    string
+		cmdLine        string
+		expectedResult utils.ReturnCode
+		expectedPins   int
+	}{
+		{description: "-cacert with PEM certificate",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -cacert " + certPath,
+			expectedResult: utils.Success,
+		},
+		{description: "-pin-sha256 with prefix and several hashes",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -pin-sha256 sha256//" + pin + "," + pin,
+			expectedResult: utils.Success,
+			expectedPins:   2,
+		},
+		{description: "-pin-sha256 with -insecure-skip-verify",
+			cmdLine:        "rpc deactivate -u wss://localhost -password password -insecure-skip-verify -pin-sha256 " + pin,
+			expectedResult: utils.Success,
+			expectedPins:   1,
+		},
+		{description: "missing -cacert file",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -cacert " + filepath.Join(t.TempDir(), "missing.pem"),
+			expectedResult: utils.MissingOrIncorrectCACert,
+		},
+		{description: "-cacert without PEM certificate",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -cacert ../../config.yaml",
+			expectedResult: utils.MissingOrIncorrectCACert,
+		},
+		{description: "-pin-sha256 of the wrong length",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -pin-sha256 c2hvcnQ=",
+			expectedResult: utils.MissingOrIncorrectCACert,
+		},
+		{description: "-cacert with -n",
+			cmdLine:        "rpc activate -u wss://localhost -profile profileName -n -cacert " + certPath,
+			expectedResult: utils.InvalidParameterCombination,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			f := NewFlags(strings.Fields(tc.cmdLine))
+			gotResult := f.ParseFlags()
+			assert.Equal(t, tc.expectedResult, gotResult)
+			if gotResult == utils.Success {
+				assert.Len(t, f.ServerTLS.Pins, tc.expectedPins)
+				assert.Equal(t, strings.Contains(tc.cmdLine, "-cacert"), f.ServerTLS.RootCAs != nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package flags
+
+import (
+	"flag"
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+type ServiceFlags struct {
+	// AgentArgs are the arguments of the agent command run by the service
+	AgentArgs []string
+	// Secrets holds the secrets of the agent as NAME=value. They are kept out of the
+	// arguments and the environment of the service, the service installs them in a file only
+	// administrators can read and passes it to the agent with -secretsFile.
+	Secrets []string
+}
+
+// names of the secrets in the -secretsFile of the agent
+const (
+	secretAMTPassword   = "AMT_PASSWORD"
+	secretProxyPassword = "PROXY_PASSWORD"
+	secretToken         = "RPS_TOKEN"
+)
+
+func (f *Flags) printServiceUsage() string {
+	usage := serviceUsage.text()
+	f.printText(usage)
+	return usage
+}
+
+func (f *Flags) handleServiceCommand() error {
+	if len(f.commandLineArgs) == 2 {
+		f.printServiceUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	f.SubCommand = f.commandLineArgs[2]
+	switch f.SubCommand {
+	case utils.SubCommandServiceInstall:
+		if err := f.parseAgentFlags(f.commandLineArgs[3:]); err != nil {
+			return err
+		}
+		f.Service.AgentArgs = f.agentArgs()
+		f.Service.Secrets = []string{secretAMTPassword + "=" + f.Password}
+		if f.ProxyPassword != "" {
+			f.Service.Secrets = append(f.Service.Secrets, secretProxyPassword+"="+f.ProxyPassword)
+		}
+		if f.Token != "" {
+			f.Service.Secrets = append(f.Service.Secrets, secretToken+"="+f.Token)
+		}
+	case utils.SubCommandServiceUninstall, utils.SubCommandServiceStart, utils.SubCommandServiceStop:
+		fs := flag.NewFlagSet(f.SubCommand, flag.ContinueOnError)
+		if err := f.parse(fs, f.commandLineArgs[3:]); err != nil || fs.NArg() > 0 {
+			return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+		}
+	default:
+		f.printServiceUsage()
+		return rpcerr.New(utils.IncorrectCommandLineParameters, "")
+	}
+	return nil
+}
+
+// agentArgs rebuilds the agent command line from the parsed agent options, without the
+// passwords and the token
+func (f *Flags) agentArgs() []string {
+	args := []string{utils.CommandAgent,
+		"-u", f.URL,
+		"-interval", f.AgentInterval.String(),
+		"-tasks", strings.Join(f.AgentTasks, ","),
+	}
+	if f.SkipCertCheck {
+		args = append(args, "-n")
+	}
+	for _, option := range []struct{ name, value string }{
+		{"-serverOrder", f.ServerOrder},
+		{"-proxy", f.Proxy},
+		{"-proxyuser", f.ProxyUser},
+		{"-cacert", f.ServerTLS.CACertFile},
+		{"-pin-sha256", f.ServerTLS.PinSHA256},
+		{"-tenant", f.TenantID},
+		{"-l", f.LogLevel},
+		{"-loglevels", f.LogLevels},
+		{"-logfile", f.LogFile},
+	} {
+		if option.value != "" {
+			args = append(args, option.name, option.value)
+		}
+	}
+	return append(args, f.tagArgs()...)
+}
+
+// readSecretsFile sets the passwords and the token that are not given on the command line
+// from the NAME=value lines of the -secretsFile of the agent
+func (f *Flags) readSecretsFile() error {
+	content, err := os.ReadFile(f.AgentSecretsFile)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		name, value, ok := strings.Cut(strings.TrimRight(line, "\r"), "=")
+		if !ok {
+			continue
+		}
+		var target *string
+		switch name {
+		case secretAMTPassword:
+			target = &f.Password
+		case secretProxyPassword:
+			target = &f.ProxyPassword
+		case secretToken:
+			target = &f.Token
+		default:
+			continue
+		}
+		if *target == "" {
+			*target = value
+		}
+	}
+	return nil
+}
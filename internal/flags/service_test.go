@@ -0,0 +1,96 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleServiceCommand(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine       string
+		wantResult    utils.ReturnCode
+		wantAgentArgs []string
+		wantSecrets   []string
+	}{
+		"should install with agent options": {
+			cmdLine:    "./rpc service install -u wss://server/activate -password P@ssw0rd -interval 2h -tasks syncclock -n -proxypassword proxyP@ss",
+			wantResult: utils.Success,
+			wantAgentArgs: []string{"agent", "-u", "wss://server/activate", "-interval", "2h0m0s", "-tasks", "syncclock", "-n",
+				"-l", "info"},
+			wantSecrets: []string{"AMT_PASSWORD=P@ssw0rd", "PROXY_PASSWORD=proxyP@ss"},
+		},
+		"should install with tenant and tags": {
+			cmdLine:       "./rpc service install -u wss://server/activate -password P@ssw0rd -tenantId t1 -tag site=berlin -tag rack=r12 -token jwt",
+			wantResult:    utils.Success,
+			wantAgentArgs: []string{"agent", "-u", "wss://server/activate", "-interval", "1h0m0s", "-tasks", "syncclock,synchostname,syncip", "-tenant", "t1", "-l", "info", "-tag", "rack=r12", "-tag", "site=berlin"},
+			wantSecrets:   []string{"AMT_PASSWORD=P@ssw0rd", "RPS_TOKEN=jwt"},
+		},
+		"should fail install without url": {
+			cmdLine:    "./rpc service install -password P@ssw0rd",
+			wantResult: utils.MissingOrIncorrectURL,
+		},
+		"should fail install with bad task": {
+			cmdLine:    "./rpc service install -u wss://server/activate -password P@ssw0rd -tasks changepassword",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should accept uninstall": {
+			cmdLine:    "./rpc service uninstall",
+			wantResult: utils.Success,
+		},
+		"should accept start": {
+			cmdLine:    "./rpc service start",
+			wantResult: utils.Success,
+		},
+		"should accept stop": {
+			cmdLine:    "./rpc service stop",
+			wantResult: utils.Success,
+		},
+		"should fail on extra arguments": {
+			cmdLine:    "./rpc service start now",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on unknown subcommand": {
+			cmdLine:    "./rpc service restart",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail without subcommand": {
+			cmdLine:    "./rpc service",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandService, flags.Command)
+			assert.Equal(t, tc.wantAgentArgs, flags.Service.AgentArgs)
+			assert.Equal(t, tc.wantSecrets, flags.Service.Secrets)
+		})
+	}
+}
+
+func TestAgentSecretsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.secrets")
+	assert.NoError(t, os.WriteFile(path, []byte("AMT_PASSWORD=P@ss=w0rd\nPROXY_PASSWORD=proxyP@ss\r\nRPS_TOKEN=jwt\nOTHER=x\n"), 0600))
+	flags := NewFlags([]string{"./rpc", "agent", "-u", "wss://server/activate", "-secretsFile", path, "-proxypassword", "cmdline"})
+	assert.Equal(t, utils.Success, flags.ParseFlags())
+	assert.Equal(t, "P@ss=w0rd", flags.Password)
+	assert.Equal(t, "cmdline", flags.ProxyPassword, "the command line takes precedence")
+	assert.Equal(t, "jwt", flags.Token)
+
+	flags = NewFlags([]string{"./rpc", "agent", "-u", "wss://server/activate", "-secretsFile", path + ".missing"})
+	assert.Equal(t, utils.MissingOrIncorrectPassword, flags.ParseFlags())
+}
+
+func TestPrintServiceUsage(t *testing.T) {
+	flags := NewFlags([]string{"./rpc", "service"})
+	usage := flags.printServiceUsage()
+	assert.Contains(t, usage, "service install")
+	assert.Contains(t, usage, "service uninstall")
+}
@@ -0,0 +1,39 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+)
+
+type SOLFlags struct {
+	// SessionLog is the file the console output of the session is appended to
+	SessionLog string
+}
+
+// handleSOLCommand reads the options of the Serial-over-LAN session rpc sol opens to AMT
+func (f *Flags) handleSOLCommand() error {
+	fs := f.solCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&f.SOL.SessionLog, "sessionlog", "", "Append the console output of the session to this file")
+	fs.String(defaultsFlag, "", defaultsUsage)
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	// the session is opened to AMT directly with the admin credentials
+	f.Local = true
+	if f.Password == "" {
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	return nil
+}
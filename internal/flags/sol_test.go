@@ -0,0 +1,57 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSOLCommand(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+	}{
+		"should open a local session": {
+			cmdLine:    "./rpc sol -password P@ssw0rd",
+			wantResult: utils.Success,
+		},
+		"should log the session to a file": {
+			cmdLine:    "./rpc sol -password P@ssw0rd -sessionlog console.log",
+			wantResult: utils.Success,
+		},
+		"should open a session to -host": {
+			cmdLine:    "./rpc sol -password P@ssw0rd -host 192.168.1.20 -tls -skipAMTCertCheck",
+			wantResult: utils.Success,
+		},
+		"should fail on an argument": {
+			cmdLine:    "./rpc sol -password P@ssw0rd console",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on -tls without -host": {
+			cmdLine:    "./rpc sol -password P@ssw0rd -tls",
+			wantResult: utils.InvalidParameterCombination,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandSOL, flags.Command)
+			if rc == utils.Success {
+				assert.True(t, flags.Local)
+				assert.Equal(t, "P@ssw0rd", flags.Password)
+			}
+		})
+	}
+
+	t.Run("should prompt for the password", func(t *testing.T) {
+		defer userInput(t, "P@ssw0rd")()
+		flags := NewFlags([]string{"./rpc", "sol", "-sessionlog", "console.log"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "P@ssw0rd", flags.Password)
+		assert.Equal(t, "console.log", flags.SOL.SessionLog)
+	})
+}
@@ -0,0 +1,48 @@
+package flags
+
+import (
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"time"
+)
+
+type StatusFlags struct {
+	// MaxSkew is the difference between the AMT and host clocks above which the clock check warns
+	MaxSkew time.Duration
+	// CertWarnDays warns about AMT certificates that expire within this many days
+	CertWarnDays int
+}
+
+func (f *Flags) handleStatusCommand() error {
+	fs := f.amtStatusCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupMQTTFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password, the TLS, clock, hostname and certificate checks need it")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.DurationVar(&f.Status.MaxSkew, "maxSkew", 2*time.Minute, "Clock difference between AMT and the host above which the clock check warns")
+	fs.IntVar(&f.Status.CertWarnDays, "certWarnDays", 30, "Warn about certificates that expire within this many days")
+	fs.String(defaultsFlag, "", defaultsUsage)
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if f.Status.MaxSkew < 0 || f.Status.CertWarnDays < 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.status.negative")
+	}
+	// runs locally, and never prompts so monitoring checks do not hang
+	f.Local = true
+	if f.Password == "" && f.PasswordFromKeyring {
+		if _, rc := f.readPasswordFromKeyring(); rc != utils.Success {
+			return rpcerr.FromReturnCode(rc)
+		}
+	}
+	return nil
+}
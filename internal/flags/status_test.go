@@ -0,0 +1,50 @@
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleStatusCommand(t *testing.T) {
+	defaults := StatusFlags{MaxSkew: 2 * time.Minute, CertWarnDays: 30}
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantStatus StatusFlags
+	}{
+		"should accept no password": {
+			cmdLine:    "./rpc status",
+			wantResult: utils.Success,
+			wantStatus: defaults,
+		},
+		"should accept thresholds": {
+			cmdLine:    "./rpc status -password P@ssw0rd -maxSkew 30s -certWarnDays 60 -json",
+			wantResult: utils.Success,
+			wantStatus: StatusFlags{MaxSkew: 30 * time.Second, CertWarnDays: 60},
+		},
+		"should fail on negative days": {
+			cmdLine:    "./rpc status -certWarnDays -1",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantStatus: StatusFlags{MaxSkew: 2 * time.Minute, CertWarnDays: -1},
+		},
+		"should fail on extra arguments": {
+			cmdLine:    "./rpc status now",
+			wantResult: utils.IncorrectCommandLineParameters,
+			wantStatus: defaults,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandStatus, flags.Command)
+			assert.Equal(t, tc.wantStatus, flags.Status)
+			assert.Equal(t, tc.wantResult == utils.Success, flags.Local)
+		})
+	}
+}
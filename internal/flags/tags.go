@@ -0,0 +1,88 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxTags bounds the tags sent to the server with each request
+	maxTags           = 32
+	maxTagValueLength = 256
+	tagUsage          = "Metadata tag key=value sent to the server, repeat the flag for several tags"
+)
+
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// tagsValue collects the tags of -tag. A tag on the command line is taken whole, so its
+// value may contain commas. The defaults file and RPC_TAG give a comma separated list, in
+// which a tag with commas is quoted like a CSV field, ex. "note=rack 12, row 3",site=berlin.
+type tagsValue struct {
+	f *Flags
+}
+
+func (v tagsValue) String() string {
+	return ""
+}
+
+func (v tagsValue) Set(value string) error {
+	return v.f.addTag(value)
+}
+
+// setDefault adds the list of tags of the defaults file or the environment
+func (v tagsValue) setDefault(value string) error {
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.TrimLeadingSpace = true
+	tags, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("tags %q are not a comma separated list: %w", value, err)
+	}
+	for _, tag := range tags {
+		if err := v.f.addTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTag parses a key=value tag. A later value of a key replaces the earlier one, tags on
+// the command line override the defaults.
+func (f *Flags) addTag(tag string) error {
+	key, tagValue, ok := strings.Cut(tag, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !tagKeyPattern.MatchString(key) {
+		return fmt.Errorf("tag %q must be key=value with a key of up to 64 letters, digits, '_', '.' or '-'", tag)
+	}
+	if len(tagValue) > maxTagValueLength {
+		return fmt.Errorf("the value of tag %s is longer than %d characters", key, maxTagValueLength)
+	}
+	if f.Tags == nil {
+		f.Tags = map[string]string{}
+	}
+	f.Tags[key] = tagValue
+	if len(f.Tags) > maxTags {
+		return fmt.Errorf("at most %d tags are supported", maxTags)
+	}
+	return nil
+}
+
+// tagArgs returns the tags as -tag flags sorted by key
+func (f *Flags) tagArgs() []string {
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, key := range keys {
+		args = append(args, "-tag", key+"="+f.Tags[key])
+	}
+	return args
+}
@@ -0,0 +1,92 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+		wantTags   map[string]string
+	}{
+		"collects repeated tags": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag site=berlin -tag rack=r12",
+			wantResult: utils.Success,
+			wantTags:   map[string]string{"site": "berlin", "rack": "r12"},
+		},
+		"keeps the last value of a key": {
+			cmdLine:    "./rpc maintenance syncdeviceinfo -u wss://localhost -password P@ssw0rd -tag site=berlin -tag site=paris",
+			wantResult: utils.Success,
+			wantTags:   map[string]string{"site": "paris"},
+		},
+		"keeps the commas of a value": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag note=rack12,row3 -tag site=berlin",
+			wantResult: utils.Success,
+			wantTags:   map[string]string{"note": "rack12,row3", "site": "berlin"},
+		},
+		"accepts an empty value": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag owner=",
+			wantResult: utils.Success,
+			wantTags:   map[string]string{"owner": ""},
+		},
+		"rejects a tag without value": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag site",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"rejects an invalid key": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag s/te=berlin",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"rejects a long value": {
+			cmdLine:    "./rpc activate -u wss://localhost -profile profileName -tag site=" + strings.Repeat("x", maxTagValueLength+1),
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			flags.amtCommand.PTHI = MockPTHICommands{}
+			assert.Equal(t, tc.wantResult, flags.ParseFlags())
+			if tc.wantResult == utils.Success {
+				assert.Equal(t, tc.wantTags, flags.Tags)
+			}
+		})
+	}
+	t.Run("merges the defaults file with the command line", func(t *testing.T) {
+		file := writeDefaults(t, "rpc.yaml", "tag: [site=berlin, rack=r12]\n")
+		flags := NewFlags([]string{"./rpc", "activate", "-config", file, "-u", "wss://localhost", "-profile", "profileName", "-tag", "site=paris"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, map[string]string{"site": "paris", "rack": "r12"}, flags.Tags)
+	})
+	t.Run("keeps the commas of a value in the defaults file", func(t *testing.T) {
+		file := writeDefaults(t, "rpc.yaml", "tag: [\"note=rack 12, row 3\", site=berlin]\n")
+		flags := NewFlags([]string{"./rpc", "activate", "-config", file, "-u", "wss://localhost", "-profile", "profileName"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, map[string]string{"note": "rack 12, row 3", "site": "berlin"}, flags.Tags)
+	})
+	t.Run("reads a quoted tag of RPC_TAG", func(t *testing.T) {
+		t.Setenv("RPC_TAG", `"note=rack 12, row 3", site=berlin`)
+		flags := NewFlags([]string{"./rpc", "activate", "-u", "wss://localhost", "-profile", "profileName"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, map[string]string{"note": "rack 12, row 3", "site": "berlin"}, flags.Tags)
+	})
+	t.Run("rejects an unterminated quote in RPC_TAG", func(t *testing.T) {
+		t.Setenv("RPC_TAG", `"note=rack 12`)
+		flags := NewFlags([]string{"./rpc", "activate", "-u", "wss://localhost", "-profile", "profileName"})
+		assert.Equal(t, utils.IncorrectCommandLineParameters, flags.ParseFlags())
+	})
+	t.Run("accepts -tenantId for -tenant", func(t *testing.T) {
+		flags := NewFlags([]string{"./rpc", "activate", "-u", "wss://localhost", "-profile", "profileName", "-tenantId", "tenant01"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, "tenant01", flags.TenantID)
+	})
+}
@@ -0,0 +1,442 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"rpc/internal/i18n"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// usageLine is a line below a command in a usage text: an example run with the
+// executable, a translated note, or a blank line when both are empty
+type usageLine struct {
+	Example string
+	Note    string
+	// Arg is given to the note after the executable, ex. help COMMAND
+	Arg string
+}
+
+// commandUsage describes a command or a subcommand for the usage texts and help,
+// its options are read from the flag set registered when it is parsed
+type commandUsage struct {
+	Name        string
+	Description string
+	Lines       []usageLine
+	// ReturnCodes are the codes of the command besides Success and IncorrectCommandLineParameters
+	ReturnCodes []utils.ReturnCode
+}
+
+// usageGroup is the usage text of a command with subcommands, Command is empty for rpc itself
+type usageGroup struct {
+	Command  string
+	Heading  string
+	Commands []commandUsage
+	Footer   []usageLine
+}
+
+var passwordCodes = []utils.ReturnCode{utils.MissingOrIncorrectPassword, utils.AMTAuthenticationFailed, utils.AMTConnectionFailed}
+
+var topUsage = usageGroup{
+	Heading: "usage.commands",
+	Commands: []commandUsage{
+		{Name: utils.CommandActivate, Description: "usage.cmd.activate",
+			Lines: []usageLine{{Example: "activate -u wss://server/activate --profile acmprofile"}},
+			ReturnCodes: []utils.ReturnCode{utils.MissingOrIncorrectURL, utils.MissingOrIncorrectProfile, utils.MissingOrIncorrectPassword,
+				utils.MissingDNSSuffix, utils.DNSSuffixMismatch, utils.InvalidProvisioningCert, utils.CertHashNotFound, utils.RPSAuthenticationFailed,
+				utils.AMTConnectionFailed, utils.ActivationFailed, utils.UnableToActivate, utils.SetMEBxPasswordFailed, utils.ActivationInterrupted,
+				utils.NoActivationToResume, utils.UnsupportedBySKU}},
+		{Name: utils.CommandAgent, Description: "usage.cmd.agent",
+			Lines: []usageLine{
+				{Example: "agent -u wss://server/activate -interval 1h -tasks syncclock,synchostname,syncip"},
+				{Example: "agent -u wss://server/activate -control /run/rpc/control.sock"},
+			},
+			ReturnCodes: []utils.ReturnCode{utils.MissingOrIncorrectURL, utils.MissingOrIncorrectPassword, utils.RPSAuthenticationFailed, utils.AMTConnectionFailed, utils.ControlAPIFailed}},
+		{Name: utils.CommandAMTInfo, Description: "usage.cmd.amtinfo",
+			Lines: []usageLine{
+				{Example: "amtinfo"},
+				{Example: "amtinfo -all -json"},
+				{Example: "amtinfo -audit -count 20 -json"},
+				{Example: "amtinfo -eventlog -count 50 -password YourAMTPassword"},
+				{Example: "amtinfo -seccheck -json"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.AmtNotDetected, utils.AmtNotReady, utils.FailedReadingConfiguration}, passwordCodes...)},
+		{Name: utils.CommandApply, Description: "usage.cmd.apply",
+			Lines: []usageLine{{Example: "apply -f device.json -dryrun"}},
+			ReturnCodes: []utils.ReturnCode{utils.DryRunCompleted, utils.FailedReadingConfiguration, utils.MissingOrInvalidConfiguration,
+				utils.MissingOrIncorrectPassword, utils.AMTConnectionFailed, utils.UnableToActivate, utils.ActivationFailed,
+				utils.SyncHostnameFailed, utils.WiFiConfigurationFailed, utils.TLSConfigurationFailed, utils.CIRAConfigurationFailed,
+				utils.PartialConfigRolledBack, utils.CIRAConfigurationRemoved}},
+		{Name: utils.CommandBoot, Description: "usage.cmd.boot",
+			Lines:       []usageLine{{Example: "boot -source pxe -reset -password YourAMTPassword"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.DryRunCompleted, utils.BootConfigurationFailed, utils.PowerActionFailed}, passwordCodes...)},
+		{Name: utils.CommandBulk, Description: "usage.cmd.bulk",
+			Lines:       []usageLine{{Example: "bulk -file devices.csv -command amtinfo -password YourAMTPassword -report results.json"}},
+			ReturnCodes: []utils.ReturnCode{utils.FailedReadingConfiguration, utils.MissingOrInvalidConfiguration, utils.CancelledByUser}},
+		{Name: utils.CommandCheckCert, Description: "usage.cmd.checkcert",
+			Lines:       []usageLine{{Example: "checkcert -provisioningCert cert.pfx -provisioningCertPwd YourCertPassword"}},
+			ReturnCodes: []utils.ReturnCode{utils.InvalidProvisioningCert, utils.CertHashNotFound, utils.DNSSuffixMismatch}},
+		{Name: utils.CommandConfigure, Description: "usage.cmd.configure",
+			Lines: []usageLine{{Example: "configure addwifisettings ..."}}},
+		{Name: utils.CommandDeactivate, Description: "usage.cmd.deactivate",
+			Lines: []usageLine{{Example: "deactivate -u wss://server/activate"}},
+			ReturnCodes: []utils.ReturnCode{utils.MissingOrIncorrectURL, utils.MissingOrIncorrectPassword, utils.AMTConnectionFailed,
+				utils.CIRAConfigurationFailed, utils.TLSConfigurationFailed, utils.StorageWipeFailed,
+				utils.UnableToDeactivate, utils.DeactivationFailed, utils.DeactivationIncomplete}},
+		{Name: utils.CommandDiag, Description: "usage.cmd.diag",
+			Lines:       []usageLine{{Example: "diag -bundle -logs /var/log/rpc.log -password YourAMTPassword"}},
+			ReturnCodes: []utils.ReturnCode{utils.DiagBundleFailed}},
+		{Name: utils.CommandHelp, Description: "usage.cmd.help",
+			Lines: []usageLine{{Example: "help maintenance syncclock"}}},
+		{Name: utils.CommandMaintenance, Description: "usage.cmd.maintenance",
+			Lines: []usageLine{{Example: "maintenance syncclock -u wss://server/activate"}}},
+		{Name: utils.CommandPower, Description: "usage.cmd.power",
+			Lines: []usageLine{{Example: "power reset -password YourAMTPassword -bootToBIOS"}}},
+		{Name: utils.CommandSelfTest, Description: "usage.cmd.selftest",
+			Lines:       []usageLine{{Example: "selftest -json"}},
+			ReturnCodes: []utils.ReturnCode{utils.SelfTestFailed}},
+		{Name: utils.CommandService, Description: "usage.cmd.service",
+			Lines: []usageLine{{Example: "service install -u wss://server/activate -interval 1h"}}},
+		{Name: utils.CommandSOL, Description: "usage.cmd.sol",
+			Lines:       []usageLine{{Example: "sol -password YourAMTPassword -sessionlog console.log"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.SOLSessionFailed}, passwordCodes...)},
+		{Name: utils.CommandStatus, Description: "usage.cmd.status",
+			Lines:       []usageLine{{Example: "status -password YourAMTPassword -json"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.StatusCheckWarning, utils.StatusCheckFailed}, passwordCodes...)},
+		{Name: utils.CommandReturnCodes, Description: "usage.cmd.returncodes",
+			Lines: []usageLine{{Example: "returncodes -json"}}},
+		{Name: utils.CommandVersion, Description: "usage.cmd.version",
+			Lines: []usageLine{{Example: "version"}}},
+		{Name: utils.CommandWSMAN, Description: "usage.cmd.wsman",
+			Lines:       []usageLine{{Example: "wsman -xml envelope.xml -password YourAMTPassword"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.WSMANMessageError}, passwordCodes...)},
+	},
+	Footer: []usageLine{
+		{},
+		{Note: "usage.moreInfo", Arg: utils.CommandHelp + " COMMAND"},
+		{Note: "usage.language"},
+		{Note: "usage.nonInteractive"},
+		{Note: "usage.output"},
+		{Note: "usage.transport"},
+	},
+}
+
+var configureUsage = usageGroup{
+	Command: utils.CommandConfigure,
+	Heading: "usage.configure.commands",
+	Commands: []commandUsage{
+		{Name: utils.SubCommandAddWifiSettings, Description: "usage.configure.addwifisettings",
+			Lines: []usageLine{
+				{Example: "configure addwifisettings -password YourAMTPassword -config wificonfig.yaml"},
+				{Example: "configure addwifisettings -password YourAMTPassword -profileName wifiWPA2 -ssid MySSID -priority 1 -authenticationMethod 6 -encryptionMethod 4 -pskPassphrase YourPassphrase"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.WiFiConfigurationFailed, utils.WifiConfigurationWithWarnings, utils.MissingOrIncorrectWifiProfileName, utils.UnsupportedBySKU}, passwordCodes...)},
+		{Name: utils.SubCommandEnableWifiPort, Description: "usage.configure.enablewifiport",
+			Lines: []usageLine{
+				{Example: "configure enablewifiport -password YourAMTPassword"},
+				{Example: "configure enablewifiport -password YourAMTPassword -linkPreference me -linkPreferenceTimeout 300"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.WiFiConfigurationFailed, utils.UnsupportedBySKU}, passwordCodes...)},
+		{Name: utils.SubCommandConfigureTLS, Description: "usage.configure.tlssettings",
+			Lines: []usageLine{
+				{Example: "configure tlssettings -password YourAMTPassword -mode Server -csr amt.csr"},
+				{Example: "configure tlssettings -password YourAMTPassword -mode Server -cert amt.crt"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.TLSConfigurationFailed, utils.PartialConfigRolledBack}, passwordCodes...)},
+		{Name: utils.SubCommandConfigureCIRA, Description: "usage.configure.cira",
+			Lines:       []usageLine{{Example: "configure cira -password YourAMTPassword -mpsaddress mps.example.com -mpsuser admin -mpspassword MPSPassword -mpscert mps-root.crt -envdetection corp.example.com"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.CIRAConfigurationFailed, utils.PartialConfigRolledBack, utils.CIRAConfigurationRemoved}, passwordCodes...)},
+		{Name: utils.SubCommandConfigureMPS, Description: "usage.configure.mps",
+			Lines:       []usageLine{{Example: "configure mps -password YourAMTPassword -mpsaddress mps1.example.com -secondary mps2.example.com -mpsuser admin -mpspassword MPSPassword -mpscert mps-root.crt -envdetection corp.example.com,lab.example.com"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.CIRAConfigurationFailed, utils.PartialConfigRolledBack, utils.CIRAConfigurationRemoved}, passwordCodes...)},
+		{Name: utils.SubCommandWired8021x, Description: "usage.configure.wired8021x",
+			Lines: []usageLine{
+				{Example: "configure wired8021x -password YourAMTPassword -config wiredconfig.yaml -ieee8021xProfileName wired"},
+				{Example: "configure wired8021x -password YourAMTPassword -disable"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.Ieee8021xConfigurationFailed, utils.MissingIeee8021xConfiguration, utils.PartialConfigRolledBack}, passwordCodes...)},
+		{Name: utils.SubCommandAlarmClock, Description: "usage.configure.alarmclock",
+			Lines: []usageLine{
+				{Example: "configure alarmclock -password YourAMTPassword -json"},
+				{Example: "configure alarmclock -password YourAMTPassword -add nightly -start 02:00 -interval 24h"},
+				{Example: "configure alarmclock -password YourAMTPassword -delete nightly"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.AlarmClockConfigurationFailed}, passwordCodes...)},
+		{Name: utils.SubCommandRedirection, Description: "usage.configure.redirection",
+			Lines:       []usageLine{{Example: "configure redirection -password YourAMTPassword -enable kvm -disable sol,ider"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.RedirectionConfigurationFailed}, passwordCodes...)},
+		{Name: utils.SubCommandDNSSuffix, Description: "usage.configure.dnssuffix",
+			Lines:       []usageLine{{Example: "configure dnssuffix -value corp.example.com"}},
+			ReturnCodes: []utils.ReturnCode{utils.DNSSuffixConfigurationFailed}},
+		{Name: utils.SubCommandAMTFeatures, Description: "usage.configure.amtfeatures",
+			Lines:       []usageLine{{Example: "configure amtfeatures -amt enable"}},
+			ReturnCodes: []utils.ReturnCode{utils.AMTStateChangeNotAllowed, utils.AMTStateChangeFailed}},
+		{Name: utils.SubCommandCertHash, Description: "usage.configure.certhash",
+			Lines: []usageLine{
+				{Example: "configure certhash -password YourAMTPassword -json"},
+				{Example: "configure certhash -password YourAMTPassword -add corp-root.crt -alias CorpRoot"},
+				{Example: "configure certhash -password YourAMTPassword -delete CorpRoot"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.CertHashConfigurationFailed}, passwordCodes...)},
+	},
+	Footer: []usageLine{
+		{},
+		{Note: "usage.moreInfo", Arg: utils.CommandHelp + " configure COMMAND"},
+	},
+}
+
+var maintenanceUsage = usageGroup{
+	Command: utils.CommandMaintenance,
+	Heading: "usage.maintenance.commands",
+	Commands: []commandUsage{
+		{Name: utils.SubCommandChangePassword, Description: "usage.maintenance.changepassword",
+			Lines: []usageLine{
+				{Example: "maintenance changepassword -u wss://server/activate"},
+				{Note: "usage.maintenance.changepassword.local"},
+				{Example: "maintenance changepassword -generate -length 20 -nosymbols -out amt.pwd"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.ChangePasswordFailed}, passwordCodes...)},
+		{Name: utils.SubCommandSyncDeviceInfo, Description: "usage.maintenance.syncdeviceinfo",
+			Lines: []usageLine{
+				{Example: "maintenance syncdeviceinfo -u wss://server/activate"},
+				{Note: "usage.maintenance.syncdeviceinfo.show"},
+				{Example: "maintenance syncdeviceinfo -show -exclude hostname,ipaddress"},
+				{Note: "usage.maintenance.continuous"},
+				{Example: "maintenance syncdeviceinfo -u wss://server/activate -continuous -interval 15m"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncDeviceInfoFailed}, passwordCodes...)},
+		{Name: utils.SubCommandSyncClock, Description: "usage.maintenance.syncclock",
+			Lines: []usageLine{
+				{Example: "maintenance syncclock -u wss://server/activate"},
+				{Note: "usage.maintenance.syncclock.ntp"},
+				{Example: "maintenance syncclock -ntp pool.ntp.org"},
+				{Note: "usage.maintenance.syncclock.local"},
+				{Example: "maintenance syncclock -local -maxSkew 2s -tz Europe/Berlin -json"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncClockFailed, utils.ClockSkewExceeded}, passwordCodes...)},
+		{Name: utils.SubCommandSyncHostname, Description: "usage.maintenance.synchostname",
+			Lines: []usageLine{
+				{Example: "maintenance synchostname -u wss://server/activate"},
+				{Example: "maintenance synchostname -u wss://server/activate -fqdn -lowercase -truncate -template amt-{hostname}"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncHostnameFailed, utils.MissingHostname}, passwordCodes...)},
+		{Name: utils.SubCommandSyncIP, Description: "usage.maintenance.syncip",
+			Lines: []usageLine{
+				{Example: "maintenance syncip -staticip 192.168.1.7 -netmask 255.255.255.0 -gateway 192.168.1.1 -primarydns 8.8.8.8 -secondarydns 4.4.4.4 -u wss://server/activate"},
+				{Note: "usage.maintenance.syncip.static"},
+				{Note: "usage.maintenance.syncip.ipv6"},
+				{Note: "usage.maintenance.syncip.interface"},
+				{Note: "usage.maintenance.syncip.preferSubnet"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncIpFailed, utils.MissingOrIncorrectStaticIP, utils.MissingOrIncorrectNetworkMask,
+				utils.MissingOrIncorrectGateway, utils.MissingOrIncorrectPrimaryDNS, utils.MissingOrIncorrectSecondaryDNS,
+				utils.OSNetworkInterfacesLookupFailed}, passwordCodes...)},
+		{Name: utils.SubCommandSyncDNS, Description: "usage.maintenance.syncdns",
+			Lines: []usageLine{
+				{Example: "maintenance syncdns -dnssuffix corp.example.com"},
+				{Note: "usage.maintenance.syncdns.host"},
+			},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncDNSFailed}, passwordCodes...)},
+		{Name: utils.SubCommandSyncWifi, Description: "usage.maintenance.syncwifi",
+			Lines:       []usageLine{{Example: "maintenance syncwifi -ssid office,lab"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.SyncWifiFailed}, passwordCodes...)},
+	},
+	Footer: []usageLine{
+		{},
+		{Note: "usage.maintenance.tasks"},
+		{Example: "maintenance -task syncclock,synchostname,syncip -u wss://server/activate"},
+		{},
+		{Note: "usage.moreInfo", Arg: utils.CommandHelp + " maintenance COMMAND"},
+	},
+}
+
+var powerUsage = usageGroup{
+	Command: utils.CommandPower,
+	Heading: "usage.power.commands",
+	Commands: []commandUsage{
+		{Name: utils.SubCommandPowerOn, Description: "usage.power.on",
+			Lines:       []usageLine{{Example: "power on -password YourAMTPassword"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.PowerActionFailed}, passwordCodes...)},
+		{Name: utils.SubCommandPowerOff, Description: "usage.power.off",
+			Lines:       []usageLine{{Example: "power off -password YourAMTPassword"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.PowerActionFailed}, passwordCodes...)},
+		{Name: utils.SubCommandPowerReset, Description: "usage.power.reset",
+			Lines:       []usageLine{{Example: "power reset -password YourAMTPassword -bootToBIOS"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.PowerActionFailed}, passwordCodes...)},
+		{Name: utils.SubCommandPowerCycle, Description: "usage.power.cycle",
+			Lines:       []usageLine{{Example: "power cycle -password YourAMTPassword -bootToPXE"}},
+			ReturnCodes: append([]utils.ReturnCode{utils.PowerActionFailed}, passwordCodes...)},
+	},
+	Footer: []usageLine{
+		{},
+		{Note: "usage.power.local"},
+		{Note: "usage.power.nextBoot"},
+		{},
+		{Note: "usage.moreInfo", Arg: utils.CommandHelp + " power COMMAND"},
+	},
+}
+
+var serviceUsage = usageGroup{
+	Command: utils.CommandService,
+	Heading: "usage.service.commands",
+	Commands: []commandUsage{
+		{Name: utils.SubCommandServiceInstall, Description: "usage.service.install",
+			Lines:       []usageLine{{Example: "service install -u wss://server/activate -interval 1h -tasks syncclock,synchostname"}},
+			ReturnCodes: []utils.ReturnCode{utils.MissingOrIncorrectURL, utils.MissingOrIncorrectPassword, utils.ServiceCommandFailed}},
+		{Name: utils.SubCommandServiceUninstall, Description: "usage.service.uninstall",
+			Lines:       []usageLine{{Example: "service uninstall"}},
+			ReturnCodes: []utils.ReturnCode{utils.ServiceCommandFailed}},
+		{Name: utils.SubCommandServiceStart, Description: "usage.service.start",
+			Lines:       []usageLine{{Example: "service start"}},
+			ReturnCodes: []utils.ReturnCode{utils.ServiceCommandFailed}},
+		{Name: utils.SubCommandServiceStop, Description: "usage.service.stop",
+			Lines:       []usageLine{{Example: "service stop"}},
+			ReturnCodes: []utils.ReturnCode{utils.ServiceCommandFailed}},
+	},
+	Footer: []usageLine{
+		{},
+		{Note: "usage.service.platform"},
+		{},
+		{Note: "usage.moreInfo", Arg: utils.CommandHelp + " service COMMAND"},
+	},
+}
+
+// usageGroups are the commands taking a subcommand, help shows their usage text
+var usageGroups = map[string]*usageGroup{
+	utils.CommandConfigure:   &configureUsage,
+	utils.CommandMaintenance: &maintenanceUsage,
+	utils.CommandPower:       &powerUsage,
+	utils.CommandService:     &serviceUsage,
+}
+
+// printText prints a usage text unless the command is only parsed for help or
+// its errors are reported as JSON
+func (f *Flags) printText(text string) {
+	if !f.usageOnly && !f.jsonErrors && !f.request {
+		fmt.Println(text)
+	}
+}
+
+// parse parses the arguments of a command and keeps its flag set, help lists the options
+// of a command from the flag set registered by its handler
+func (f *Flags) parse(fs *flag.FlagSet, args []string) error {
+	f.parsedFlagSet = fs
+	translateUsage(fs)
+	if f.usageOnly || f.jsonErrors || f.request {
+		fs.SetOutput(io.Discard)
+	}
+	return fs.Parse(args)
+}
+
+// translateUsage replaces the usage of the flags with the one of the selected language,
+// the English usage registered with a flag is kept when the catalog has none
+func translateUsage(fs *flag.FlagSet) {
+	fs.VisitAll(func(fl *flag.Flag) {
+		if usage, ok := i18n.FlagUsage(fs.Name(), fl.Name); ok {
+			fl.Usage = usage
+		}
+	})
+}
+
+// text renders the usage text of rpc or of a command with subcommands, the
+// descriptions start one column after the longest command name
+func (g *usageGroup) text() string {
+	executable := filepath.Base(os.Args[0])
+	command := "COMMAND"
+	if g.Command != "" {
+		command = g.Command + " " + command
+	}
+	width := 0
+	for _, c := range g.Commands {
+		if len(c.Name)+1 > width {
+			width = len(c.Name) + 1
+		}
+	}
+	indent := strings.Repeat(" ", 2+width)
+	usage := "\n" + i18n.T("usage.title") + "\n\n"
+	usage = usage + i18n.T("usage.usage") + ": " + executable + " " + command + " [OPTIONS]\n\n"
+	usage = usage + i18n.T(g.Heading) + ":\n"
+	for _, c := range g.Commands {
+		usage = usage + "  " + c.Name + strings.Repeat(" ", width-len(c.Name)) + i18n.T(c.Description) + "\n"
+		for _, line := range c.Lines {
+			usage = usage + line.text(indent, indent)
+		}
+	}
+	for _, line := range g.Footer {
+		usage = usage + line.text(indent, "")
+	}
+	return usage
+}
+
+func (l usageLine) text(exampleIndent, noteIndent string) string {
+	executable := filepath.Base(os.Args[0])
+	switch {
+	case l.Example != "":
+		return exampleIndent + i18n.T("usage.example") + ": " + executable + " " + l.Example + "\n"
+	case l.Note != "" && l.Arg != "":
+		return noteIndent + i18n.T(l.Note, executable+" "+l.Arg) + "\n"
+	case l.Note != "":
+		return noteIndent + i18n.T(l.Note) + "\n"
+	}
+	return "\n"
+}
+
+// commandHelp renders the help of a single command: its examples, the options registered
+// by its handler and the return codes it can exit with
+func (f *Flags) commandHelp(path []string, c commandUsage) string {
+	executable := filepath.Base(os.Args[0])
+	help := "\n" + i18n.T("usage.title") + "\n\n"
+	help = help + i18n.T("usage.usage") + ": " + executable + " " + strings.Join(path, " ") + " [OPTIONS]\n\n"
+	help = help + i18n.T(c.Description) + "\n"
+	for _, line := range c.Lines {
+		help = help + line.text("  ", "  ")
+	}
+	if options := f.commandOptions(path); options != "" {
+		help = help + "\n" + i18n.T("usage.options") + ":\n" + options
+	}
+	help = help + "\n" + i18n.T("usage.returnCodes") + ":\n"
+	codes := append([]utils.ReturnCode{utils.Success, utils.IncorrectCommandLineParameters}, c.ReturnCodes...)
+	for _, info := range utils.ReturnCodes {
+		if !containsReturnCode(codes, info.Code) {
+			continue
+		}
+		description := info.Description
+		if translated, ok := i18n.Lookup("returncode." + info.Name); ok {
+			description = translated
+		}
+		help = help + fmt.Sprintf("  %-5d %-35s %s\n", info.Code, info.Name, description)
+	}
+	return help
+}
+
+// commandOptions parses the command with -h to have its handler register its flags, the
+// options in help are the ones the command accepts
+func (f *Flags) commandOptions(path []string) string {
+	args := append([]string{f.commandLineArgs[0], "-lang", f.Language}, path...)
+	parsed := NewFlags(append(args, "-h"))
+	parsed.usageOnly = true
+	_ = parsed.Parse()
+	if parsed.parsedFlagSet == nil {
+		return ""
+	}
+	options := &strings.Builder{}
+	parsed.parsedFlagSet.SetOutput(options)
+	parsed.parsedFlagSet.PrintDefaults()
+	return options.String()
+}
+
+func containsReturnCode(codes []utils.ReturnCode, code utils.ReturnCode) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,15 @@
 package flags
 
 import (
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
 )
 
-func (f *Flags) handleVersionCommand() utils.ReturnCode {
-	if err := f.versionCommand.Parse(f.commandLineArgs[2:]); err != nil {
-		return utils.IncorrectCommandLineParameters
+func (f *Flags) handleVersionCommand() error {
+	if err := f.parse(f.versionCommand, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
 	}
 	// runs locally
 	f.Local = true
-	return utils.Success
+	return nil
 }
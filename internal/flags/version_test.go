@@ -2,7 +2,6 @@ package flags
 
 import (
 	"github.com/stretchr/testify/assert"
-	"rpc/pkg/utils"
 	"testing"
 )
 
@@ -12,7 +11,7 @@ func TestHandleVersionCommand(t *testing.T) {
 		"version",
 	})
 
-	result := f.handleVersionCommand()
-	assert.Equal(t, utils.Success, result)
+	err := f.handleVersionCommand()
+	assert.NoError(t, err)
 	assert.Equal(t, true, f.Local)
 }
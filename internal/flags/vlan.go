@@ -0,0 +1,36 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// vlanID returns the VLAN tag of the interface, 0 when it is untagged or the
+// enumerator can not tell
+func (n NetEnumerator) vlanID(i *net.Interface) int {
+	if n.VLANID == nil {
+		return 0
+	}
+	return n.VLANID(i)
+}
+
+// vlanIDFromName recognizes VLAN subinterfaces by the common 'parent.tag' and
+// 'vlantag' naming schemes
+func vlanIDFromName(name string) int {
+	var tag string
+	if dot := strings.LastIndex(name, "."); dot > 0 {
+		tag = name[dot+1:]
+	} else if strings.HasPrefix(name, "vlan") {
+		tag = strings.TrimPrefix(name, "vlan")
+	}
+	id, err := strconv.Atoi(tag)
+	if err != nil || id < 1 || id > 4094 {
+		return 0
+	}
+	return id
+}
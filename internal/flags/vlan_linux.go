@@ -0,0 +1,41 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// vlanConfig lists the VLAN subinterfaces created by the 8021q module, it is replaced in tests
+var vlanConfig = "/proc/net/vlan/config"
+
+// hostVLANID looks the interface up in the kernel VLAN table, which also knows
+// subinterfaces with custom names. Without the table it falls back to the name.
+func hostVLANID(i *net.Interface) int {
+	file, err := os.Open(vlanConfig)
+	if err != nil {
+		return vlanIDFromName(i.Name)
+	}
+	defer file.Close()
+	// entries look like 'eth0.100 | 100 | eth0' after two header lines
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if len(fields) != 3 || strings.TrimSpace(fields[0]) != i.Name {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimSpace(fields[1])); err == nil {
+			return id
+		}
+	}
+	return 0
+}
@@ -0,0 +1,31 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostVLANID(t *testing.T) {
+	orig := vlanConfig
+	defer func() { vlanConfig = orig }()
+
+	vlanConfig = filepath.Join(t.TempDir(), "config")
+	content := "VLAN Dev name    | VLAN ID\nName-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD\nmgmt           | 42  | eth0\n"
+	assert.NoError(t, os.WriteFile(vlanConfig, []byte(content), 0600))
+	assert.Equal(t, 42, hostVLANID(&net.Interface{Name: "mgmt"}))
+	assert.Equal(t, 0, hostVLANID(&net.Interface{Name: "eth0.7"}))
+
+	vlanConfig = filepath.Join(t.TempDir(), "missing")
+	assert.Equal(t, 7, hostVLANID(&net.Interface{Name: "eth0.7"}))
+}
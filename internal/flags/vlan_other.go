@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import "net"
+
+// hostVLANID recognizes VLAN subinterfaces by name, the OS VLAN tables are not read
+func hostVLANID(i *net.Interface) int {
+	return vlanIDFromName(i.Name)
+}
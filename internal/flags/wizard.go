@@ -0,0 +1,345 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+)
+
+// maxWizardAttempts is how often an invalid answer is asked again before the wizard gives up
+const maxWizardAttempts = 3
+
+// serverProbeTimeout bounds the pre-flight connection to the activation server
+const serverProbeTimeout = 5 * time.Second
+
+const (
+	wizardRemote   = "1"
+	wizardLocalCCM = "2"
+	wizardLocalACM = "3"
+)
+
+// promptLine prints the prompt and reads one line from stdin
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := readLine()
+	return strings.TrimSpace(line), err
+}
+
+// readLine reads one line from stdin. Stdin is read one byte at a time so
+// later prompts of the command still see the remaining input.
+func readLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				break
+			}
+			line = append(line, b[0])
+			continue
+		}
+		if err == io.EOF && len(line) > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(line), nil
+}
+
+// promptValue asks until validate accepts the answer, an empty answer keeps current
+func promptValue(label string, current string, validate func(string) error) (string, error) {
+	prompt := label + ": "
+	if current != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", label, current)
+	}
+	for attempt := 0; attempt < maxWizardAttempts; attempt++ {
+		answer, err := promptLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = current
+		}
+		if err = validate(answer); err != nil {
+			fmt.Println("  " + err.Error())
+			continue
+		}
+		return answer, nil
+	}
+	return "", fmt.Errorf("no valid %s after %d attempts", strings.ToLower(label), maxWizardAttempts)
+}
+
+// promptNewPassword asks for a password twice and only accepts matching entries
+func promptNewPassword(label string, validate func(string) error) (string, error) {
+	for attempt := 0; attempt < maxWizardAttempts; attempt++ {
+		password, err := promptLine(label + ": ")
+		if err != nil {
+			return "", err
+		}
+		if err = validate(password); err != nil {
+			fmt.Println("  " + err.Error())
+			continue
+		}
+		confirmation, err := promptLine("Confirm " + strings.ToLower(label) + ": ")
+		if err != nil {
+			return "", err
+		}
+		if confirmation != password {
+			fmt.Println("  the passwords do not match")
+			continue
+		}
+		return password, nil
+	}
+	return "", fmt.Errorf("no valid %s after %d attempts", strings.ToLower(label), maxWizardAttempts)
+}
+
+func required(name string) func(string) error {
+	return func(value string) error {
+		if value == "" {
+			return errors.New(name + " is required")
+		}
+		return nil
+	}
+}
+
+func validateServerURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "wss" && u.Scheme != "ws") || u.Host == "" {
+		return errors.New("the server URL must look like wss://server/activate")
+	}
+	return nil
+}
+
+// validateAMTPassword checks the password against the rules AMT enforces, so the
+// wizard asks again instead of failing the activation
+func validateAMTPassword(password string) error {
+	if err := validateStrongPassword(password); err != nil {
+		return fmt.Errorf("the AMT password %w", err)
+	}
+	return nil
+}
+
+// promptActivateSettings walks through the activation settings. Values given
+// on the command line are offered as defaults.
+func (f *Flags) promptActivateSettings() error {
+	fmt.Println("Activation wizard, press Enter to keep the value in brackets.")
+	fmt.Println("How should AMT be activated?")
+	fmt.Println("  1) remotely with a server profile")
+	fmt.Println("  2) locally in client control mode (CCM)")
+	fmt.Println("  3) locally in admin control mode (ACM)")
+	choice := wizardRemote
+	if f.Local && f.UseACM {
+		choice = wizardLocalACM
+	} else if f.Local {
+		choice = wizardLocalCCM
+	}
+	choice, err := promptValue("Choice", choice, func(value string) error {
+		if value != wizardRemote && value != wizardLocalCCM && value != wizardLocalACM {
+			return errors.New("enter 1, 2 or 3")
+		}
+		return nil
+	})
+	if err != nil {
+		return rpcerr.Wrap(utils.InvalidUserInput, err, "")
+	}
+
+	if choice == wizardRemote {
+		f.Local, f.UseCCM, f.UseACM = false, false, false
+		if f.URL, err = promptValue("Server URL", f.URL, validateServerURL); err != nil {
+			return rpcerr.Wrap(utils.MissingOrIncorrectURL, err, "")
+		}
+		if f.Profile, err = promptValue("Profile name", f.Profile, required("the profile name")); err != nil {
+			return rpcerr.Wrap(utils.MissingOrIncorrectProfile, err, "")
+		}
+		if f.DNS, err = promptValue("DNS suffix, empty uses the AMT or OS suffix", f.DNS, func(string) error { return nil }); err != nil {
+			return rpcerr.Wrap(utils.InvalidUserInput, err, "")
+		}
+		return nil
+	}
+
+	f.Local = true
+	f.URL = ""
+	f.UseCCM = choice == wizardLocalCCM
+	f.UseACM = choice == wizardLocalACM
+	if f.UseACM {
+		acm := &f.LocalConfig.ACMSettings
+		acm.ProvisioningCert, err = promptValue("Provisioning certificate (.pfx file)", acm.ProvisioningCert, func(value string) error {
+			if _, err := os.Stat(value); err != nil {
+				return errors.New("the provisioning certificate file can not be read")
+			}
+			return nil
+		})
+		if err != nil {
+			return rpcerr.Wrap(utils.FailedReadingConfiguration, err, "")
+		}
+		if acm.ProvisioningCertPwd, err = promptLine("Provisioning certificate password: "); err != nil {
+			return rpcerr.Wrap(utils.InvalidUserInput, err, "")
+		}
+	}
+	password, err := promptNewPassword("AMT password", validateAMTPassword)
+	if err != nil {
+		return rpcerr.Wrap(utils.MissingOrIncorrectPassword, err, "")
+	}
+	f.Password = password
+	f.LocalConfig.ACMSettings.AMTPassword = password
+	return nil
+}
+
+// wizardCheck is the outcome of one pre-flight check, a failed check stops the activation
+type wizardCheck struct {
+	name   string
+	failed bool
+	warn   bool
+	detail string
+	rc     utils.ReturnCode
+}
+
+func (c wizardCheck) String() string {
+	status := "PASS"
+	if c.failed {
+		status = "FAIL"
+	} else if c.warn {
+		status = "WARN"
+	}
+	line := fmt.Sprintf("  [%s] %s", status, c.name)
+	if c.detail != "" {
+		line += ": " + c.detail
+	}
+	return line
+}
+
+// activatePreflight checks that AMT can be activated with the settings from the
+// wizard, prints a pass or fail line per check and asks before activating
+func (f *Flags) activatePreflight() error {
+	checks := f.activateChecks()
+	fmt.Println("Pre-flight checks:")
+	var failed *wizardCheck
+	for i := range checks {
+		fmt.Println(checks[i])
+		if checks[i].failed && failed == nil {
+			failed = &checks[i]
+		}
+	}
+	if failed != nil {
+		return newError(failed.rc, "error.activate.preflightFailed", failed.name)
+	}
+
+	fmt.Println("Activation settings:")
+	switch {
+	case !f.Local:
+		fmt.Printf("  remote activation with profile %s from %s\n", f.Profile, f.URL)
+	case f.UseACM:
+		fmt.Println("  local activation in admin control mode (ACM)")
+	default:
+		fmt.Println("  local activation in client control mode (CCM)")
+	}
+	if f.DryRun {
+		return nil
+	}
+	answer, err := promptLine("Activate now? [y/N]: ")
+	if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		return newError(utils.InvalidUserInput, "error.activate.cancelled")
+	}
+	return nil
+}
+
+func (f *Flags) activateChecks() []wizardCheck {
+	mei := wizardCheck{name: "MEI driver access"}
+	if err := f.amtCommand.Initialize(); err != nil {
+		mei.failed, mei.detail, mei.rc = true, err.Error(), utils.HECIDriverNotDetected
+		// the remaining checks need the MEI
+		return []wizardCheck{mei}
+	}
+	checks := []wizardCheck{mei}
+
+	mode := wizardCheck{name: "Control mode"}
+	if controlMode, err := f.amtCommand.GetControlMode(); err != nil {
+		mode.failed, mode.detail, mode.rc = true, err.Error(), utils.AMTConnectionFailed
+	} else {
+		mode.detail = utils.InterpretControlMode(controlMode)
+		if path, err := f.ActivationPath(controlMode); err != nil {
+			mode.failed, mode.rc = true, utils.UnableToActivate
+		} else if path != ActivationPathActivate {
+			mode.detail += ", " + path
+		}
+	}
+	checks = append(checks, mode)
+
+	dns := wizardCheck{name: "DNS suffix"}
+	suffix := f.DNS
+	if suffix == "" {
+		suffix, _ = f.amtCommand.GetDNSSuffix()
+	}
+	if suffix == "" {
+		suffix, _ = f.amtCommand.GetOSDNSSuffix()
+	}
+	dns.detail = suffix
+	if suffix == "" {
+		dns.detail = "none found, admin control mode needs the DNS suffix of the provisioning certificate"
+		if f.Local && f.UseACM {
+			dns.failed, dns.rc = true, utils.MissingDNSSuffix
+		} else {
+			dns.warn = true
+		}
+	}
+	checks = append(checks, dns)
+
+	link := wizardCheck{name: "Wired link", detail: "up"}
+	if settings, err := f.amtCommand.GetLANInterfaceSettings(false); err != nil || settings.LinkStatus != "up" {
+		link.warn, link.detail = true, "down, admin control mode needs the wired link"
+	}
+	checks = append(checks, link)
+
+	if !f.Local {
+		checks = append(checks, f.serverCheck())
+	}
+	return checks
+}
+
+// serverCheck connects to the activation servers without sending anything, one server
+// that can be reached is enough
+func (f *Flags) serverCheck() wizardCheck {
+	check := wizardCheck{name: "Server reachable"}
+	if f.Proxy != "" {
+		check.warn, check.detail = true, "not checked through the proxy"
+		return check
+	}
+	check.failed, check.detail, check.rc = true, "no server given", utils.MissingOrIncorrectURL
+	for _, server := range f.ServerURLs() {
+		u, err := url.Parse(server)
+		if err != nil {
+			check.detail = err.Error()
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			port = "443"
+			if u.Scheme == "ws" {
+				port = "80"
+			}
+		}
+		address := net.JoinHostPort(u.Hostname(), port)
+		conn, err := net.DialTimeout("tcp", address, serverProbeTimeout)
+		if err != nil {
+			check.detail = err.Error()
+			continue
+		}
+		conn.Close()
+		return wizardCheck{name: check.name, detail: address}
+	}
+	return check
+}
@@ -0,0 +1,94 @@
+package flags
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// xmlFromStdin as the value of -xml reads the envelope from stdin
+const xmlFromStdin = "-"
+
+type WSMANFlags struct {
+	// XML is the file with the envelope, or - for stdin
+	XML string
+	// Envelope is the WS-MAN envelope read from XML
+	Envelope string
+}
+
+// handleWSMANCommand reads the WS-MAN envelope rpc wsman sends to AMT as it is
+func (f *Flags) handleWSMANCommand() error {
+	fs := f.wsmanCommand
+	fs.BoolVar(&f.Verbose, "v", false, "Verbose output")
+	f.setupLogFlags(fs)
+	f.setupTimeoutFlag(fs)
+	f.setupTelemetryFlag(fs)
+	f.setupRemoteFlags(fs)
+	fs.BoolVar(&f.JsonOutput, "json", false, "JSON output")
+	fs.BoolVar(&f.YamlOutput, "yaml", false, "YAML output")
+	fs.StringVar(&f.Password, "password", f.lookupEnvOrString("AMT_PASSWORD", ""), "AMT password")
+	fs.BoolVar(&f.PasswordFromKeyring, "passwordFromKeyring", false, keyringUsage)
+	fs.StringVar(&f.PasswordFile, "passwordFile", "", passwordFileUsage)
+	fs.StringVar(&f.WSMAN.XML, "xml", "", "File with the WS-MAN envelope to send to AMT, - reads it from stdin")
+	fs.String(defaultsFlag, "", defaultsUsage)
+	if err := f.parseWithDefaults(fs, f.commandLineArgs[2:]); err != nil {
+		return rpcerr.Wrap(utils.IncorrectCommandLineParameters, err, "")
+	}
+	if fs.NArg() > 0 {
+		return newError(utils.IncorrectCommandLineParameters, "error.unexpectedArgument", fs.Arg(0))
+	}
+	if f.WSMAN.XML == "" {
+		fs.Usage()
+		return newError(utils.IncorrectCommandLineParameters, "error.wsman.xmlRequired")
+	}
+	var content []byte
+	var err error
+	if f.WSMAN.XML == xmlFromStdin {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(f.WSMAN.XML)
+	}
+	if err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.wsman.readEnvelope")
+	}
+	if err := validateEnvelope(content); err != nil {
+		return wrapError(utils.IncorrectCommandLineParameters, err, "error.wsman.invalidEnvelope")
+	}
+	f.WSMAN.Envelope = string(content)
+
+	// sent to AMT directly with the admin credentials
+	f.Local = true
+	if f.Password == "" {
+		// the prompt fails when the envelope was read from stdin
+		if _, rc := f.ReadPasswordFromUser(); rc != utils.Success {
+			return rpcerr.New(utils.MissingOrIncorrectPassword, "")
+		}
+	}
+	return nil
+}
+
+// validateEnvelope checks the content is well formed XML with a SOAP Envelope as its root
+func validateEnvelope(content []byte) error {
+	decoder := xml.NewDecoder(strings.NewReader(string(content)))
+	root := ""
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok && root == "" {
+			root = start.Name.Local
+		}
+	}
+	if root != "Envelope" {
+		return errors.New("the root element must be a SOAP Envelope")
+	}
+	return nil
+}
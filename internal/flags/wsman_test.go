@@ -0,0 +1,68 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testEnvelope = `<?xml version="1.0" encoding="utf-8"?><Envelope xmlns="http://www.w3.org/2003/05/soap-envelope"><Header></Header><Body></Body></Envelope>`
+
+func TestHandleWSMANCommand(t *testing.T) {
+	dir := t.TempDir()
+	envelope := filepath.Join(dir, "envelope.xml")
+	assert.NoError(t, os.WriteFile(envelope, []byte(testEnvelope), 0o600))
+	notEnvelope := filepath.Join(dir, "body.xml")
+	assert.NoError(t, os.WriteFile(notEnvelope, []byte(`<Body></Body>`), 0o600))
+	broken := filepath.Join(dir, "broken.xml")
+	assert.NoError(t, os.WriteFile(broken, []byte(`<Envelope><Body></Envelope>`), 0o600))
+
+	tests := map[string]struct {
+		cmdLine    string
+		wantResult utils.ReturnCode
+	}{
+		"should read the envelope file": {
+			cmdLine:    "./rpc wsman -password P@ssw0rd -xml " + envelope,
+			wantResult: utils.Success,
+		},
+		"should fail without -xml": {
+			cmdLine:    "./rpc wsman -password P@ssw0rd",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on a missing file": {
+			cmdLine:    "./rpc wsman -password P@ssw0rd -xml " + filepath.Join(dir, "missing.xml"),
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail without an envelope": {
+			cmdLine:    "./rpc wsman -password P@ssw0rd -xml " + notEnvelope,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+		"should fail on malformed xml": {
+			cmdLine:    "./rpc wsman -password P@ssw0rd -xml " + broken,
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := NewFlags(strings.Fields(tc.cmdLine))
+			rc := flags.ParseFlags()
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, utils.CommandWSMAN, flags.Command)
+			if rc == utils.Success {
+				assert.True(t, flags.Local)
+				assert.Equal(t, testEnvelope, flags.WSMAN.Envelope)
+			}
+		})
+	}
+
+	t.Run("should read the envelope from stdin", func(t *testing.T) {
+		defer userInput(t, testEnvelope)()
+		flags := NewFlags([]string{"./rpc", "wsman", "-password", "P@ssw0rd", "-xml", "-"})
+		assert.Equal(t, utils.Success, flags.ParseFlags())
+		assert.Equal(t, testEnvelope, flags.WSMAN.Envelope)
+	})
+}
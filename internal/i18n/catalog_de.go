@@ -0,0 +1,649 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package i18n
+
+var de = map[string]string{
+	"usage.title":          "Remote Provisioning Client (RPC) - zum Aktivieren, Deaktivieren, Warten und Abfragen des Status von AMT",
+	"usage.usage":          "Verwendung",
+	"usage.commands":       "Unterstützte Befehle",
+	"usage.example":        "Beispiel",
+	"usage.moreInfo":       "Führen Sie '%s' aus, um mehr über einen Befehl zu erfahren.",
+	"usage.language":       "Die Sprache der Ausgabe wird mit -lang en, es oder de oder mit der Umgebungsvariablen RPC_LANG gewählt.",
+	"usage.nonInteractive": "Mit -nonInteractive oder RPC_NON_INTERACTIVE=true wird nie gefragt, ein fehlendes Passwort oder eine fehlende Bestätigung lässt den Befehl fehlschlagen.",
+	"usage.transport":      "Mit -transport lms oder lme, oder mit RPC_TRANSPORT, wird das lokale AMT nur über LMS oder den LME-Treiber von rpc erreicht, auto verwendet LMS, wenn es läuft. lms-tls ist LMS auf Port 16993, sobald TLS auf der lokalen Schnittstelle aktiviert ist.",
+	"usage.output":         "Mit -output oder der Umgebungsvariable RPC_OUTPUT wird das Ergebnisdokument in eine Datei, nach syslog oder eventlog kopiert.",
+	"usage.options":        "Optionen",
+	"usage.returnCodes":    "Rückgabecodes",
+
+	"usage.cmd.activate":    "Aktiviert dieses Gerät mit dem angegebenen Profil",
+	"usage.cmd.agent":       "Läuft als dauerhafter Prozess und führt regelmäßig Wartungsaufgaben aus. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.amtinfo":     "Zeigt Informationen zu Status und Konfiguration von AMT an",
+	"usage.cmd.apply":       "Bringt dieses Gerät auf die Aktivierung, den Hostnamen, WLAN-, TLS- und CIRA-Einstellungen eines JSON-Dokuments und gibt zuerst den Plan der Änderungen aus",
+	"usage.cmd.boot":        "Legt das Gerät fest, von dem dieses Gerät beim nächsten Start startet, und setzt es mit -reset zurück. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.bulk":        "Führt amtinfo, power, configure oder wsman auf den entfernten AMT-Geräten einer CSV- oder JSON-Geräteliste aus und meldet das Ergebnis jedes Geräts",
+	"usage.cmd.checkcert":   "Prüft die Kette des Provisionierungszertifikats gegen die Hashes der vertrauenswürdigen Stammzertifikate von AMT",
+	"usage.cmd.configure":   "Lokale Konfiguration einer Funktion auf diesem Gerät. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.deactivate":  "Deaktiviert dieses Gerät. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.diag":        "Sammelt amtinfo, das AMT-Ereignisprotokoll, die rpc-Protokolle, die Netzwerkkonfiguration des BS und den LMS-Status mit entfernten Geheimnissen in einer ZIP-Datei für Support-Tickets",
+	"usage.cmd.help":        "Zeigt die Verwendung, die Optionen und die Rückgabecodes eines Befehls an",
+	"usage.cmd.maintenance": "Führt eine Wartungsaufgabe für das Gerät aus. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.power":       "Schaltet dieses Gerät über AMT ein oder aus, setzt es zurück oder schaltet es aus und wieder ein. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.selftest":    "Prüft den MEI-Treiber, eine PTHI-Abfrage, LMS, die Pfade, in die rpc schreibt, und die rpc-Programmdatei für die Fehleranalyse durch den Support",
+	"usage.cmd.service":     "Installiert, deinstalliert, startet oder stoppt rpc als Dienst, der den Agenten ausführt",
+	"usage.cmd.sol":         "Öffnet eine Serial-over-LAN-Sitzung zur BIOS- oder Betriebssystemkonsole dieses Geräts über AMT, Strg+] beendet sie. Das AMT-Passwort ist erforderlich",
+	"usage.cmd.status":      "Prüft Steuerungsmodus, CIRA, TLS, Uhr, Hostnamen und Ablauf der Zertifikate und gibt PASS, WARN oder FAIL aus",
+	"usage.cmd.returncodes": "Listet die Exit-Codes von RPC mit Namen und Beschreibung auf",
+	"usage.cmd.version":     "Zeigt die aktuelle Version von RPC und die Version des RPC-Protokolls an",
+	"usage.cmd.wsman":       "Sendet einen WS-MAN-Umschlag unverändert an AMT und gibt die Antwort aus. AMT-Passwort erforderlich",
+
+	"usage.maintenance.commands":             "Unterstützte Wartungsbefehle",
+	"usage.maintenance.changepassword":       "Ändert das AMT-Passwort. Standardmäßig wird ein zufälliges Passwort erzeugt. Mit -static wird es manuell gesetzt. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.changepassword.local": "Mit -generate wird das Passwort lokal erzeugt, ohne Cloud-Interaktion",
+	"usage.maintenance.syncdeviceinfo":       "Synchronisiert die Geräteinformationen. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.syncdeviceinfo.show":  "Geben Sie -show an, um die Geräteinformationen anzuzeigen, ohne sie zu senden, und -exclude, um Felder auszulassen",
+	"usage.maintenance.continuous":           "Geben Sie -continuous an, um weiterzulaufen und die Geräteinformationen alle -interval erneut zu senden",
+	"usage.maintenance.syncclock":            "Synchronisiert die Uhr des Betriebssystems mit AMT. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.syncclock.ntp":        "Mit -ntp wird AMT stattdessen mit einem NTP-Server synchronisiert, ohne Cloud-Interaktion",
+	"usage.maintenance.syncclock.local":      "Mit -local wird AMT mit der Uhr des Host-Betriebssystems synchronisiert, -maxSkew lässt eine Uhr innerhalb der Toleranz unverändert",
+	"usage.maintenance.synchostname":         "Synchronisiert den Hostnamen des Clients mit AMT. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.syncip":               "Überträgt die IP-Konfiguration des Betriebssystems in die Netzwerkeinstellungen von AMT. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.syncip.static":        "Ohne statische IP werden die IP-Adresse und die Netzmaske des Betriebssystems verwendet",
+	"usage.maintenance.syncip.ipv6":          "Mit -ipv6addr und -prefixlen wird zusätzlich eine IPv6-Adresse gesetzt, sonst eine globale IPv6-Adresse des Betriebssystems, falls vorhanden",
+	"usage.maintenance.syncip.interface":     "Mit -ifname oder -mac werden die Einstellungen einer gebündelten oder überbrückten Schnittstelle gelesen, auch für synchostname",
+	"usage.maintenance.syncip.preferSubnet":  "Mit -preferSubnet wird unter mehreren Adressen des Hosts gewählt, z. B. -preferSubnet 192.168.1.0/24",
+	"usage.maintenance.syncdns":              "Überträgt das DNS-Suffix und die DNS-Server des Betriebssystems in AMT, ohne Cloud-Interaktion. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.syncdns.host":         "Ohne Angabe werden das DNS-Suffix und die DNS-Server des Betriebssystems verwendet",
+	"usage.maintenance.syncwifi":             "Ersetzt die WLAN-Profile in AMT durch die WPA/WPA2-Personal-Profile des Betriebssystems, ohne Cloud-Interaktion. Das AMT-Passwort ist erforderlich",
+	"usage.maintenance.tasks":                "Mehrere Aufgaben über eine Serververbindung mit -task ausführen, oder alle mit -all:",
+	"usage.power.commands":                   "Unterstützte Energiebefehle",
+	"usage.power.on":                         "Schaltet das Gerät ein. Das AMT-Passwort ist erforderlich",
+	"usage.power.off":                        "Schaltet das Gerät aus, ohne das Betriebssystem herunterzufahren. Das AMT-Passwort ist erforderlich",
+	"usage.power.reset":                      "Setzt das Gerät zurück. Das AMT-Passwort ist erforderlich",
+	"usage.power.cycle":                      "Schaltet das Gerät aus und wieder ein. Das AMT-Passwort ist erforderlich",
+	"usage.power.local":                      "Die Energieaktionen werden direkt an AMT gesendet, ohne Cloud-Interaktion.",
+	"usage.power.nextBoot":                   "-bootToBIOS und -bootToPXE gelten nur für den nächsten Start und können nicht mit off verwendet werden.",
+	"usage.service.commands":                 "Unterstützte Dienstbefehle",
+	"usage.service.install":                  "Installiert rpc als Dienst, der den Agenten ausführt. Akzeptiert die Optionen des Agenten. Das AMT-Passwort ist erforderlich",
+	"usage.service.uninstall":                "Stoppt und entfernt den Dienst",
+	"usage.service.start":                    "Startet den Dienst",
+	"usage.service.stop":                     "Stoppt den Dienst",
+	"usage.service.platform":                 "Der Dienst ist ein Windows-Dienst oder unter Linux eine systemd-Unit.",
+	"usage.configure.commands":               "Unterstützte Konfigurationsbefehle",
+	"usage.configure.addwifisettings":        "Fügt WLAN-Einstellungen in AMT hinzu oder ändert sie. Das AMT-Passwort ist erforderlich. Alle Einstellungen müssen in einer config.yml, einer config.json oder mit Befehlszeilenoptionen angegeben werden. Dieser Befehl läuft ohne Cloud-Interaktion.",
+	"usage.configure.enablewifiport":         "Aktiviert den WLAN-Port und die lokale Profilsynchronisierung in AMT oder deaktiviert sie mit -disable. Das AMT-Passwort ist erforderlich.",
+	"usage.configure.tlssettings":            "Konfiguriert TLS in AMT. Das AMT-Passwort ist erforderlich. Zuerst ausführen, um in AMT ein Schlüsselpaar und eine CSR zu erzeugen, dann mit dem signierten Zertifikat, um TLS zu aktivieren.",
+	"usage.configure.cira":                   "Konfiguriert CIRA in AMT: den MPS-Server und sein Stammzertifikat, die Umgebungserkennung und die Richtlinien für den Fernzugriff. Das AMT-Passwort ist erforderlich.",
+	"usage.configure.mps":                    "Konfiguriert CIRA wie cira, mit einem sekundären MPS, auf den AMT ausweicht, wenn der primäre nicht erreichbar ist. Eine bereits passende Konfiguration bleibt erhalten. Das AMT-Passwort ist erforderlich.",
+	"usage.configure.wired8021x":             "Konfiguriert IEEE 802.1x auf der kabelgebundenen Schnittstelle von AMT mit EAP-TLS oder PEAPv0/EAP-MSCHAPv2 (authenticationProtocol 0 oder 2). Das AMT-Passwort ist erforderlich.",
+	"usage.configure.alarmclock":             "Listet die Weckalarme von AMT auf, fügt mit -add und -start einen hinzu oder löscht mit -delete einen. Das AMT-Kennwort ist erforderlich.",
+	"usage.configure.redirection":            "Aktiviert oder deaktiviert die KVM-, SOL- und IDE-R-Umleitung in AMT mit -enable und -disable. Das AMT-Kennwort ist erforderlich.",
+	"usage.configure.dnssuffix":              "Legt das PKI-DNS-Suffix fest, das AMT mit dem Bereitstellungszertifikat vergleicht. Nur vor der Aktivierung möglich, das AMT-Kennwort ist nicht erforderlich.",
+	"usage.configure.amtfeatures":            "Aktiviert oder deaktiviert AMT vom Betriebssystem aus, wenn das BIOS es erlaubt, oder zeigt, ob es erlaubt ist. Das AMT-Kennwort ist nicht erforderlich.",
+	"usage.configure.certhash":               "Listet die Hashes der vertrauenswürdigen Stammzertifikate von AMT auf, fügt mit -add einen hinzu oder löscht mit -delete einen, soweit die Firmware es erlaubt. Das AMT-Kennwort ist erforderlich.",
+
+	"info.version":                "Version",
+	"info.buildNumber":            "Build-Nummer",
+	"info.sku":                    "SKU",
+	"info.features":               "Funktionen",
+	"info.skuClass":               "SKU-Klasse",
+	"info.uuid":                   "UUID",
+	"info.controlMode":            "Steuerungsmodus",
+	"info.operationalState":       "Betriebszustand",
+	"info.enabled":                "aktiviert",
+	"info.disabled":               "deaktiviert",
+	"info.disabledInMEBx":         "in MEBx deaktiviert",
+	"info.provisioningState":      "Provisionierungsstatus",
+	"info.provisioningMode":       "Provisionierungsmodus",
+	"info.dnsSuffix":              "PKI-DNS-Suffix",
+	"info.dnsSuffixOS":            "DNS-Suffix (BS)",
+	"info.hostnameOS":             "Hostname (BS)",
+	"info.manufacturer":           "Hersteller",
+	"info.model":                  "Modell",
+	"info.serialNumber":           "Seriennummer",
+	"info.assetTag":               "Inventarnummer",
+	"info.cpu":                    "CPU",
+	"info.memory":                 "Arbeitsspeicher",
+	"info.biosVendor":             "BIOS-Hersteller",
+	"info.biosVersion":            "BIOS-Version",
+	"info.biosReleaseDate":        "BIOS-Datum",
+	"info.meFirmwareVersion":      "ME-Firmwareversion",
+	"info.meFirmwareBuild":        "ME-Firmware-Build",
+	"info.meRecoveryVersion":      "ME-Recovery-Version",
+	"info.meRecoveryBuild":        "ME-Recovery-Build",
+	"info.meFirmwareSVN":          "ME-Firmware-SVN",
+	"info.notReported":            "nicht gemeldet",
+	"info.securityAdvisories":     "Sicherheitshinweise",
+	"info.advisoryTable":          "Tabelle vom",
+	"info.affected":               "betroffen",
+	"info.notAffected":            "nicht betroffen",
+	"info.notListed":              "nicht aufgeführt, die Firmware ist neuer als die Tabelle",
+	"info.fixedIn":                "behoben in",
+	"info.advisoryUnknown":        "unbekannt, die Firmware-Version konnte nicht gelesen werden",
+	"info.osName":                 "BS-Name",
+	"info.osVersion":              "BS-Version",
+	"info.osBuild":                "BS-Build",
+	"info.architecture":           "Architektur",
+	"info.rpcVersion":             "RPC-Version",
+	"info.protocolVersion":        "RPC-Protokollversion",
+	"info.transport":              "Transport",
+	"info.wsmanAddress":           "WS-MAN-Adresse",
+	"info.reachable":              "erreichbar",
+	"info.notReachable":           "nicht erreichbar",
+	"info.redirectionListener":    "Umleitungs-Listener",
+	"info.kvm":                    "KVM",
+	"info.sol":                    "SOL",
+	"info.ider":                   "IDE-R",
+	"info.changeFromOS":           "Änderung vom BS",
+	"info.allowed":                "erlaubt",
+	"info.notAllowed":             "nicht erlaubt",
+	"info.remoteConfiguration":    "Fernkonfiguration",
+	"info.provisioningTLSMode":    "TLS-Modus der Bereitstellung",
+	"info.activeCertHashes":       "Aktive Stamm-Hashes",
+	"info.ccmActivation":          "CCM-Aktivierung",
+	"info.acmActivation":          "ACM-Aktivierung",
+	"info.rasNetwork":             "RAS-Netzwerk",
+	"info.rasRemoteStatus":        "RAS-Remotestatus",
+	"info.rasTrigger":             "RAS-Auslöser",
+	"info.rasMPSHostname":         "RAS-MPS-Hostname",
+	"info.dhcpEnabled":            "DHCP aktiviert",
+	"info.dhcpMode":               "DHCP-Modus",
+	"info.linkStatus":             "Verbindungsstatus",
+	"info.ipAddress":              "IP-Adresse",
+	"info.macAddress":             "MAC-Adresse",
+	"info.ipv6Address":            "IPv6-Adresse",
+	"info.wiredAdapter":           "---Kabelgebundener Adapter---",
+	"info.wirelessAdapter":        "---WLAN-Adapter---",
+	"info.certHashes":             "---Zertifikat-Hashes---",
+	"info.noCertHashes":           "---Keine Zertifikat-Hashes gefunden---",
+	"info.noDeprecatedCertHashes": "---Keine veralteten Zertifikat-Hashes gefunden---",
+	"info.publicKeyCerts":         "---Public-Key-Zertifikate---",
+	"info.noPublicKeyCerts":       "---Keine Public-Key-Zertifikate gefunden---",
+	"info.auditLog":               "---Audit-Protokoll (%d von %d Einträgen)---",
+	"info.eventLog":               "---Ereignisprotokoll (%d von %d Einträgen)---",
+	"info.eventLogCleared":        "Ereignisprotokoll gelöscht",
+
+	"error.activate.alreadyActivated":                "das Gerät ist bereits %s",
+	"error.activate.alreadyActivatedReprovision":     "das Gerät ist bereits %s, verwenden Sie -reprovision, um es erneut zu aktivieren",
+	"error.activate.alreadyCCM":                      "das Gerät ist bereits im Client-Control-Modus aktiviert, verwenden Sie -upgrade, um es in den Admin-Control-Modus zu bringen, oder -reprovision, um es erneut zu aktivieren",
+	"error.activate.cancelled":                       "Aktivierung abgebrochen",
+	"error.activate.ccmOrACM":                        "geben Sie -ccm oder -acm an, aber nicht beides",
+	"error.activate.fipsWithoutGenerate":             "-fips erfordert -generatePassword",
+	"error.activate.generateOnlyLocalCCM":            "-generatePassword wird nur bei lokaler CCM-Aktivierung ohne -upgrade oder -reprovision unterstützt",
+	"error.activate.generateWithPassword":            "-generatePassword kann nicht mit -password oder AMT_PASSWORD verwendet werden",
+	"error.activate.generateWithoutStore":            "-generatePassword erfordert -out, -keyring oder -vault",
+	"error.activate.interactiveOrNonInteractive":     "geben Sie -interactive oder -nonInteractive an, aber nicht beides",
+	"error.activate.interactiveWithJSON":             "-interactive kann nicht mit -json oder -yaml verwendet werden",
+	"error.activate.invalidAMTPassword":              "ungültiges -amtPassword, AMT lehnt es ab",
+	"error.activate.invalidUUID":                     "die angegebene UUID hat kein gültiges UUID-Format",
+	"error.activate.mebxOnlyLocalACM":                "-mebxPassword wird nur bei lokaler ACM-Aktivierung unterstützt",
+	"error.activate.missingField":                    "fehlender Wert für das Feld: %s",
+	"error.activate.precheckFailed":                  "Prüfung vor der Aktivierung fehlgeschlagen: %s",
+	"error.activate.preflightFailed":                 "Vorabprüfung fehlgeschlagen: %s",
+	"error.activate.resumeOnlyRPS":                   "-resume und -sessionFile werden nur bei der Aktivierung über RPS unterstützt",
+	"error.activate.rpsAPIOnlyRPS":                   "-rpsAPI wird nur bei der Aktivierung über RPS unterstützt",
+	"error.activate.rpsAPIURL":                       "-rpsAPI muss eine http://- oder https://-URL sein",
+	"error.activate.storeWithoutGenerate":            "-out, -keyring und -vault erfordern -generatePassword",
+	"error.activate.upgradeOnlyLocal":                "-upgrade und -reprovision werden nur bei lokaler Aktivierung unterstützt",
+	"error.activate.upgradeOrReprovision":            "geben Sie -upgrade oder -reprovision an, aber nicht beides",
+	"error.activate.upgradeWithoutACM":               "-upgrade erfordert -acm",
+	"error.activate.uuidLocal":                       "-uuid kann bei lokaler Aktivierung nicht verwendet werden",
+	"error.addWithDelete":                            "-add kann nicht mit -delete verwendet werden",
+	"error.agent.readSecretsFile":                    "-secretsFile kann nicht gelesen werden",
+	"error.agent.unsupportedTask":                    "nicht unterstützte Agentenaufgabe: %s",
+	"error.alarmclock.addRequired":                   "-start, -interval und -deleteOnCompletion erfordern -add",
+	"error.alarmclock.interval":                      "-interval muss eine ganze Zahl von Minuten und nicht negativ sein",
+	"error.alarmclock.invalidStart":                  "ungültiges -start",
+	"error.alarmclock.startRequired":                 "-add erfordert -start",
+	"error.amtfeatures.invalidAMT":                   "ungültiges -amt %s, verwenden Sie %s oder %s",
+	"error.apply.acmCertRequired":                    "die Aktivierung im acm-Modus benötigt provisioningCert und provisioningCertPwd",
+	"error.apply.activationMode":                     "der Aktivierungsmodus muss ccm oder acm sein, nicht %q",
+	"error.apply.ciraNegativePeriodic":               "periodicInterval von cira darf nicht negativ sein",
+	"error.apply.ciraPort":                           "mpsPort von cira muss zwischen 1 und 65535 liegen",
+	"error.apply.ciraRequired":                       "mpsAddress, mpsUser, mpsPassword und mpsCert von cira sind erforderlich",
+	"error.apply.documentRequired":                   "-f ist erforderlich",
+	"error.apply.emptyDocument":                      "das Dokument beschreibt keines von activation, hostname, wifiConfigs, tls oder cira",
+	"error.apply.hostnameTemplate":                   "die Vorlage des Hostnamens muss %s genau einmal enthalten",
+	"error.apply.invalidDocument":                    "ungültiges Dokument",
+	"error.apply.invalidSecondaryMPS":                "ungültiger sekundärer MPS von cira",
+	"error.apply.mebxOnlyACM":                        "mebxPassword wird nur bei der acm-Aktivierung unterstützt",
+	"error.apply.readDocument":                       "das Dokument kann nicht gelesen werden",
+	"error.apply.tlsCACertWithoutMutual":             "caCert und trustedCN von tls sind nur mit einem Modus der gegenseitigen Authentifizierung gültig",
+	"error.apply.tlsCertRequired":                    "tls benötigt das signierte Zertifikat in cert, erstellen Sie zuerst die CSR mit configure tlssettings",
+	"error.apply.tlsMutualWithoutCACert":             "die gegenseitige Authentifizierung von tls erfordert ein caCert",
+	"error.boot.once":                                "AMT wendet die Startquelle nur auf den nächsten Start an, -once=false wird nicht unterstützt",
+	"error.boot.source":                              "-source muss eines von %s sein",
+	"error.boot.usb":                                 "-source usb wird nicht unterstützt, AMT startet von USB nur über die USB-Umleitung eines Datenträgerabbilds, die rpc nicht bereitstellt",
+	"error.bulk.command":                             "bulk führt amtinfo, power, configure oder wsman aus, nicht %s",
+	"error.bulk.fileAndCommand":                      "-file und -command sind erforderlich",
+	"error.bulk.flagFromDeviceList":                  "-%s wird aus der Geräteliste übernommen, nicht aus -command",
+	"error.bulk.invalidCommand":                      "ungültiges -command für %s",
+	"error.bulk.noDevices":                           "%s enthält keine Geräte",
+	"error.bulk.readDeviceList":                      "die Geräteliste kann nicht gelesen werden",
+	"error.bulk.workers":                             "-workers muss mindestens 1 sein",
+	"error.bulk.xmlFromStdin":                        "bulk kann den WS-MAN-Umschlag nicht von stdin lesen, verwenden Sie eine Datei mit -xml",
+	"error.certhash.aliasRequired":                   "-add eines Hashes oder eines Zertifikats ohne allgemeinen Namen erfordert -alias",
+	"error.certhash.aliasWithoutAdd":                 "-alias erfordert -add",
+	"error.certhash.invalidAdd":                      "ungültiges -add",
+	"error.changepassword.generateRequired":          "-length, -nosymbols, -fips, -out, -keyring und -vault erfordern -generate",
+	"error.changepassword.generateWithStatic":        "-generate setzt das Passwort lokal und kann nicht mit -static oder -u kombiniert werden",
+	"error.changepassword.invalidStatic":             "ungültiges -static-Passwort, AMT lehnt es ab",
+	"error.changepassword.length":                    "-length muss zwischen %d und %d liegen",
+	"error.changepassword.oneStore":                  "geben Sie nur eines von -out, -keyring oder -vault an",
+	"error.checkcert.certRequired":                   "-provisioningCert oder eine -config mit dem Bereitstellungszertifikat ist erforderlich",
+	"error.cira.mpsport":                             "-mpsport muss zwischen 1 und 65535 liegen",
+	"error.cira.negativePeriodic":                    "-periodic darf nicht negativ sein",
+	"error.cira.required":                            "-mpsaddress, -mpsuser, -mpspassword und -mpscert sind erforderlich",
+	"error.configPasswordMismatch":                   "das Passwort stimmt nicht mit dem Passwort der Konfigurationsdatei überein",
+	"error.deactivate.urlOrPartial":                  "geben Sie 'url' oder 'partial' an, aber nicht beides",
+	"error.deactivate.urlOrWipe":                     "geben Sie 'url' oder 'wipe' an, aber nicht beides",
+	"error.deactivate.wipeOrPartial":                 "geben Sie 'wipe' oder 'partial' an, aber nicht beides",
+	"error.diag.bundleRequired":                      "diag erfordert -bundle",
+	"error.diag.notDirectory":                        "-dir %s ist kein Verzeichnis",
+	"error.dnssuffix.invalidValue":                   "ungültiges -value",
+	"error.dnssuffix.tooLong":                        "-value ist länger als %d Zeichen",
+	"error.dnssuffix.valueRequired":                  "-value ist erforderlich",
+	"error.enabledAndDisabled":                       "%s kann nicht zugleich aktiviert und deaktiviert werden",
+	"error.enablewifiport.linkPreference":            "-linkPreference muss %s oder %s sein",
+	"error.enablewifiport.linkPreferenceTimeout":     "-linkPreferenceTimeout muss zwischen 1 und 65535 Sekunden liegen",
+	"error.enablewifiport.linkPreferenceWithDisable": "-linkPreference kann nicht mit -disable verwendet werden",
+	"error.fips":                                     "-fips",
+	"error.help.unknownCommand":                      "keine Hilfe für %s",
+	"error.info.advisoriesWithoutSeccheck":           "-advisories erfordert -seccheck",
+	"error.info.clearWithCount":                      "-clear löscht alle Einträge und kann nicht mit -count oder -offset verwendet werden",
+	"error.info.clearWithoutEventlog":                "-clear erfordert -eventlog",
+	"error.info.negativeCacheTTL":                    "-cacheTTL darf nicht negativ sein",
+	"error.info.negativeCount":                       "-count und -offset dürfen nicht negativ sein",
+	"error.intervalTooShort":                         "-interval muss mindestens eine Minute betragen",
+	"error.invalidConfigJSON":                        "ungültiges -configJson",
+	"error.invalidMEBxPassword":                      "ungültiges MEBx-Passwort",
+	"error.invalidOTelEndpoint":                      "ungültiger -otel-endpoint",
+	"error.invalidOutput":                            "ungültiges -output",
+	"error.invalidPassword":                          "ungültiges AMT-Passwort, AMT lehnt es ab",
+	"error.invalidTransport":                         "ungültiger -transport",
+	"error.invalidVault":                             "ungültiges -vault",
+	"error.langValue":                                "-lang benötigt eine Sprache",
+	"error.maintenance.taskOrAll":                    "geben Sie eine 'task'-Liste oder 'all' an, aber nicht beides",
+	"error.maintenance.taskTwice":                    "Wartungsaufgabe doppelt angegeben: %s",
+	"error.maintenance.unsupportedTask":              "nicht unterstützte Wartungsaufgabe: %s",
+	"error.negativeMaxSkew":                          "-maxSkew darf nicht negativ sein",
+	"error.nonInteractiveValue":                      "ungültiger Wert %q für -nonInteractive",
+	"error.outputValue":                              "-output benötigt eine Datei, stdout, syslog oder eventlog",
+	"error.passwordOrPasswordFile":                   "geben Sie -password oder -passwordFile an, aber nicht beides",
+	"error.power.biosOrPXE":                          "geben Sie 'bootToBIOS' oder 'bootToPXE' an, aber nicht beides",
+	"error.power.bootOptionsWithOff":                 "Startoptionen gelten für den nächsten Start und können nicht mit 'off' verwendet werden",
+	"error.profileRequired":                          "die Option -profile ist erforderlich und darf nicht leer sein",
+	"error.readCACert":                               "das CA-Zertifikat kann nicht gelesen werden",
+	"error.readCert":                                 "das Zertifikat kann nicht gelesen werden",
+	"error.readMPSCert":                              "das Stammzertifikat des MPS kann nicht gelesen werden",
+	"error.readSecretsFile":                          "Fehler beim Lesen der Datei mit Geheimnissen",
+	"error.redirection.enableOrDisable":              "-enable oder -disable ist erforderlich",
+	"error.redirection.invalidDisable":               "ungültiges -disable",
+	"error.redirection.invalidEnable":                "ungültiges -enable",
+	"error.remote.amtPort":                           "-amtPort muss zwischen 1 und 65535 liegen",
+	"error.remote.caCertWithSkipCheck":               "-amtCACert kann nicht mit -skipAMTCertCheck verwendet werden",
+	"error.remote.emptyUser":                         "-user darf nicht leer sein",
+	"error.remote.hostIsURL":                         "-host %s muss ein Hostname oder eine IP-Adresse sein, keine URL",
+	"error.remote.hostRequired":                      "-amtPort, -tls, -amtCACert und -skipAMTCertCheck erfordern -host",
+	"error.remote.noPEMCertificate":                  "-amtCACert %s enthält kein PEM-Zertifikat",
+	"error.remote.readCACert":                        "-amtCACert kann nicht gelesen werden",
+	"error.remote.tlsRequired":                       "-amtCACert und -skipAMTCertCheck erfordern -tls",
+	"error.status.negative":                          "-maxSkew und -certWarnDays dürfen nicht negativ sein",
+	"error.syncclock.localOrNTP":                     "-maxSkew und -tz benötigen -local oder -ntp",
+	"error.syncclock.unknownTZ":                      "unbekanntes -tz %s",
+	"error.syncclock.urlOrLocal":                     "geben Sie 'url' oder 'local' an, aber nicht beides",
+	"error.syncclock.urlOrNTP":                       "geben Sie 'url' oder einen 'ntp'-Server an, aber nicht beides",
+	"error.syncdeviceinfo.continuousWithShow":        "-continuous kann nicht mit -show oder -dryrun verwendet werden",
+	"error.syncdns.url":                              "syncdns läuft lokal und verwendet die Option 'url' nicht",
+	"error.syncip.preferSubnetWithStaticIP":          "-preferSubnet wählt die Hostadresse aus und kann nicht mit -staticip verwendet werden",
+	"error.syncip.prefixlenWithoutIPv6":              "-prefixlen erfordert -ipv6addr",
+	"error.syncwifi.url":                             "syncwifi läuft lokal und verwendet die Option 'url' nicht",
+	"error.tls.caCertWithoutMutual":                  "'caCert' und 'trustedCN' sind nur mit einem Modus der gegenseitigen Authentifizierung gültig",
+	"error.tls.csrOrCert":                            "geben Sie 'csr' oder 'cert' an, aber nicht beides",
+	"error.tls.mutualWithoutCACert":                  "die gegenseitige Authentifizierung erfordert ein 'caCert'",
+	"error.transportValue":                           "-transport benötigt lms, lms-tls, lme oder auto",
+	"error.unexpectedArgument":                       "unerwartetes Argument %s",
+	"error.urlOrLocal":                               "geben Sie 'url' oder 'local' an, aber nicht beides",
+	"error.urlRequired":                              "die Option -u ist erforderlich und darf nicht leer sein",
+	"error.wifi.missingConfiguration":                "WLAN-Konfiguration fehlt",
+	"error.wired8021x.disableWithConfig":             "-disable nimmt keine 802.1x-Konfiguration an",
+	"error.wired8021x.profileRequired":               "geben Sie -ieee8021xProfileName mit -config oder -username und die Zertifikate an",
+	"error.wired8021x.pxeTimeout":                    "-pxeTimeout muss zwischen 0 und 86400 Sekunden liegen",
+	"error.wsman.invalidEnvelope":                    "ungültiger WS-MAN-Umschlag",
+	"error.wsman.readEnvelope":                       "der WS-MAN-Umschlag kann nicht gelesen werden",
+	"error.wsman.xmlRequired":                        "-xml ist erforderlich",
+
+	"returncode.Success":                            "der Befehl wurde erfolgreich ausgeführt",
+	"returncode.IncorrectPermissions":               "läuft nicht mit Administrator- oder Root-Rechten",
+	"returncode.HECIDriverNotDetected":              "der MEI/HECI-Treiber wurde nicht erkannt",
+	"returncode.AmtNotDetected":                     "Intel AMT wurde auf diesem Gerät nicht erkannt",
+	"returncode.AmtNotReady":                        "Intel AMT ist nicht bereit",
+	"returncode.DryRunCompleted":                    "der Befehl wurde mit -dryrun geprüft, es wurde nichts geändert",
+	"returncode.GenericFailure":                     "der Befehl ist ohne spezifischeren Rückgabecode fehlgeschlagen",
+	"returncode.UnsupportedPlatform":                "rpc unterstützt den MEI-Treiber für dieses Betriebssystem oder diese CPU-Architektur nicht",
+	"returncode.MEITimeout":                         "der MEI-Treiber hat nicht innerhalb von -timeout geantwortet, oder der Befehl wurde abgebrochen",
+	"returncode.NotAdministrator":                   "rpc benötigt Administrator- oder Root-Rechte, um das MEI-Gerät zu öffnen",
+	"returncode.MEIDriverMissing":                   "es wurde kein MEI-Gerät gefunden, der MEI-Treiber ist nicht installiert oder Intel ME ist deaktiviert",
+	"returncode.CancelledByUser":                    "rpc wurde vor Abschluss des Befehls mit Strg+C oder SIGTERM unterbrochen",
+	"returncode.MissingOrIncorrectURL":              "die Server-URL fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectProfile":          "das Profil fehlt oder ist ungültig",
+	"returncode.ServerCerificateVerificationFailed": "das Serverzertifikat konnte nicht überprüft werden",
+	"returncode.MissingOrIncorrectPassword":         "das AMT-Passwort fehlt oder ist falsch",
+	"returncode.MissingDNSSuffix":                   "das DNS-Suffix fehlt",
+	"returncode.MissingHostname":                    "der Hostname fehlt",
+	"returncode.MissingProxyAddressAndPort":         "die Adresse oder der Port des Proxys fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectStaticIP":         "die statische IP-Adresse fehlt oder ist ungültig",
+	"returncode.IncorrectCommandLineParameters":     "die Befehlszeilenparameter sind ungültig",
+	"returncode.MissingOrIncorrectNetworkMask":      "die Netzmaske fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectGateway":          "das Gateway fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectPrimaryDNS":       "der primäre DNS-Server fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectSecondaryDNS":     "der sekundäre DNS-Server fehlt oder ist ungültig",
+	"returncode.InvalidParameterCombination":        "diese Kombination von Befehlszeilenparametern ist nicht erlaubt",
+	"returncode.FailedReadingConfiguration":         "die Konfiguration konnte nicht gelesen werden",
+	"returncode.MissingOrInvalidConfiguration":      "die Konfiguration fehlt oder ist ungültig",
+	"returncode.InvalidUserInput":                   "die Benutzereingabe ist ungültig",
+	"returncode.InvalidUUID":                        "die UUID ist ungültig",
+	"returncode.MissingOrIncorrectMEBxPassword":     "das MEBx-Passwort fehlt oder erfüllt die Komplexitätsregeln nicht",
+	"returncode.MissingOrIncorrectMQTTBroker":       "die Adresse des MQTT-Brokers fehlt oder ist ungültig",
+	"returncode.MissingOrIncorrectCACert":           "die Datei -cacert oder die Hashes -pin-sha256 des Servers fehlen oder sind ungültig",
+	"returncode.DNSSuffixMismatch":                  "das DNS-Suffix passt nicht zur Domäne des Provisionierungszertifikats",
+	"returncode.InvalidProvisioningCert":            "das Provisionierungszertifikat kann nicht entschlüsselt werden oder seine Kette lässt sich nicht überprüfen",
+	"returncode.InputRequired":                      "eine Bestätigung oder andere Eingabe ist erforderlich, nach der -nonInteractive nicht fragt",
+	"returncode.RPSAuthenticationFailed":            "die Authentifizierung am Server ist fehlgeschlagen",
+	"returncode.AMTConnectionFailed":                "die Verbindung zu AMT ist fehlgeschlagen",
+	"returncode.OSNetworkInterfacesLookupFailed":    "die Netzwerkschnittstellen des Betriebssystems konnten nicht gelesen werden",
+	"returncode.ControlAPIFailed":                   "der Agent konnte die Steuerungs-API nicht an der -control-Adresse bereitstellen oder sein Token nicht lesen",
+	"returncode.AMTAuthenticationFailed":            "die Authentifizierung bei AMT ist fehlgeschlagen",
+	"returncode.WSMANMessageError":                  "eine WSMAN-Nachricht ist fehlgeschlagen",
+	"returncode.ActivationFailed":                   "die Aktivierung ist fehlgeschlagen",
+	"returncode.NetworkConfigurationFailed":         "die Netzwerkkonfiguration ist fehlgeschlagen",
+	"returncode.CIRAConfigurationFailed":            "die CIRA-Konfiguration ist fehlgeschlagen",
+	"returncode.TLSConfigurationFailed":             "die TLS-Konfiguration ist fehlgeschlagen",
+	"returncode.WiFiConfigurationFailed":            "die WLAN-Konfiguration ist fehlgeschlagen",
+	"returncode.AMTFeaturesConfigurationFailed":     "die Konfiguration der AMT-Funktionen ist fehlgeschlagen",
+	"returncode.Ieee8021xConfigurationFailed":       "die ieee8021x-Konfiguration ist fehlgeschlagen",
+	"returncode.UnableToDeactivate":                 "das Gerät konnte nicht deaktiviert werden",
+	"returncode.DeactivationFailed":                 "die Deaktivierung ist fehlgeschlagen",
+	"returncode.UnableToActivate":                   "das Gerät konnte nicht aktiviert werden",
+	"returncode.WifiConfigurationWithWarnings":      "die WLAN-Konfiguration wurde mit Warnungen abgeschlossen",
+	"returncode.UnmarshalMessageFailed":             "eine Antwortnachricht konnte nicht gelesen werden",
+	"returncode.DeleteWifiConfigFailed":             "eine vorhandene WLAN-Konfiguration konnte nicht gelöscht werden",
+	"returncode.MissingOrIncorrectWifiProfileName":  "der Name des WLAN-Profils fehlt oder ist ungültig",
+	"returncode.MissingIeee8021xConfiguration":      "die ieee8021x-Konfiguration fehlt",
+	"returncode.SetMEBxPasswordFailed":              "das Gerät wurde aktiviert, aber das Setzen des MEBx-Passworts ist fehlgeschlagen",
+	"returncode.ServiceCommandFailed":               "das Installieren, Entfernen, Starten oder Stoppen des rpc-Dienstes ist fehlgeschlagen",
+	"returncode.DeactivationIncomplete":             "AMT hat die Deprovisionierung angenommen, ist aber nicht in den Zustand vor der Provisionierung zurückgekehrt",
+	"returncode.PowerActionFailed":                  "AMT hat den Energiezustand oder die Startoptionen nicht geändert",
+	"returncode.StorageWipeFailed":                  "das Gerät wurde deaktiviert, aber deactivate -wipe konnte nicht die gesamte Konfiguration entfernen",
+	"returncode.StatusCheckWarning":                 "rpc status hat eine Prüfung gefunden, die Aufmerksamkeit erfordert (WARN)",
+	"returncode.StatusCheckFailed":                  "rpc status hat eine fehlgeschlagene Prüfung gefunden (FAIL)",
+	"returncode.ClockSkewExceeded":                  "die Uhr des Hosts weicht um mehr als -maxSkew von der Server- oder NTP-Zeit ab",
+	"returncode.CertHashNotFound":                   "AMT hat keinen aktiven Hash eines vertrauenswürdigen Stammzertifikats für das Provisionierungszertifikat",
+	"returncode.AlarmClockConfigurationFailed":      "AMT hat die Weckalarme nicht aufgelistet, hinzugefügt oder gelöscht",
+	"returncode.RedirectionConfigurationFailed":     "AMT hat den Zustand der KVM-, SOL- oder IDE-R-Umleitung nicht geändert",
+	"returncode.DNSSuffixConfigurationFailed":       "AMT hat das PKI-DNS-Suffix nicht festgelegt, es wird nur vor der Aktivierung festgelegt",
+	"returncode.AMTStateChangeNotAllowed":           "das BIOS erlaubt nicht, AMT vom Betriebssystem aus zu aktivieren oder zu deaktivieren",
+	"returncode.AMTStateChangeFailed":               "AMT hat nicht in den angeforderten Zustand gewechselt",
+	"returncode.ActivationInterrupted":              "die Verbindung zu RPS ging während der Aktivierung des Geräts verloren, führen Sie activate erneut aus",
+	"returncode.NoActivationToResume":               "activate -resume hat keine unterbrochene Aktivierung des Geräts gefunden",
+	"returncode.SelfTestFailed":                     "rpc selftest hat eine fehlgeschlagene Prüfung gefunden (FAIL)",
+	"returncode.SOLSessionFailed":                   "AMT hat die Serial-over-LAN-Sitzung abgelehnt oder die Sitzung wurde mit einem Fehler beendet",
+	"returncode.BootConfigurationFailed":            "AMT hat die Booteinstellungen, die Rolle der Bootkonfiguration oder die Bootreihenfolge nicht übernommen",
+	"returncode.CertHashConfigurationFailed":        "AMT hat die Hashes der vertrauenswürdigen Stammzertifikate nicht aufgelistet, hinzugefügt oder gelöscht",
+	"returncode.DiagBundleFailed":                   "rpc diag konnte das Diagnosepaket nicht schreiben",
+	"returncode.UnsupportedBySKU":                   "die SKU der Firmware, etwa Standard Manageability, hat die Funktion nicht oder kann nicht aktiviert werden",
+	"returncode.PartialConfigRolledBack":            "ein Konfigurationsschritt ist fehlgeschlagen und die vorher vorgenommenen Änderungen wurden zurückgesetzt",
+	"returncode.CIRAConfigurationRemoved":           "ein CIRA-Konfigurationsschritt ist fehlgeschlagen, die Änderungen wurden zurückgesetzt und die ersetzte CIRA-Konfiguration bleibt entfernt",
+	"returncode.SyncClockFailed":                    "die Synchronisierung der Uhr ist fehlgeschlagen",
+	"returncode.SyncHostnameFailed":                 "die Synchronisierung des Hostnamens ist fehlgeschlagen",
+	"returncode.SyncIpFailed":                       "die Synchronisierung der IP-Konfiguration ist fehlgeschlagen",
+	"returncode.ChangePasswordFailed":               "die Änderung des AMT-Passworts ist fehlgeschlagen",
+	"returncode.SyncDeviceInfoFailed":               "die Synchronisierung der Geräteinformationen ist fehlgeschlagen",
+	"returncode.SyncDNSFailed":                      "die Synchronisierung des DNS-Suffixes oder der DNS-Server ist fehlgeschlagen",
+	"returncode.SyncWifiFailed":                     "das Lesen der WLAN-Profile des Betriebssystems ist fehlgeschlagen oder keines kann synchronisiert werden",
+	"returncode.AmtPtStatusCodeBase":                "AMT hat einen PT-Statuscode zurückgegeben, der zu diesem Basiswert addiert wird",
+
+	"flag.acm":                    "Im Admin-Control-Modus (ACM) aktivieren",
+	"flag.activate.config":        "Konfigurationsdatei oder URL einer smb:-Dateifreigabe angeben",
+	"flag.activate.local":         "AMT lokal aktivieren",
+	"flag.activate.ntp":           "NTP-Server (Host oder Host:Port), mit dem -precheck die Uhr des Hosts vergleicht, ohne Angabe wird der Server verwendet",
+	"flag.activate.uuid":          "UUID des AMT-Geräts für Abläufe ohne CIRA überschreiben",
+	"flag.activate.vault":         "Das erzeugte Passwort in diesen Secret Store schreiben, z. B. 'vault://vault.example.com:8200/secret/amt/device1'",
+	"flag.add":                    "PEM- oder DER-Datei des Stammzertifikats oder sein SHA256-, SHA1- oder SHA512-Hash in Hex, der den vertrauenswürdigen Stamm-Hashes hinzugefügt wird",
+	"flag.addwifisettings.caCert": "CA-Zertifikat angeben",
+	"flag.addwifisettings.config": "Konfigurationsdatei oder URL einer smb:-Dateifreigabe angeben",
+	"flag.advisories":             "JSON-Datei mit der Tabelle der Sicherheitshinweise, die -seccheck statt der in rpc eingebauten Tabelle verwendet",
+	"flag.agent.interval":         "Zeit zwischen den Wartungsläufen (z. B. '1h' oder '30m')",
+	"flag.alarmclock.add":         "Name des hinzuzufügenden Weckalarms",
+	"flag.alarmclock.interval":    "Zeit zwischen den Weckvorgängen von -add in ganzen Minuten, z. B. 24h, 0 weckt einmal",
+	"flag.alias":                  "Name des Hashes von -add, standardmäßig der allgemeine Name des Zertifikats",
+	"flag.all":                    "Alle Informationen, einschließlich Zertifikat-Hashes, Betriebszustand, Bereitstellungsmodi, Hardwareinventar, BIOS und Host-Betriebssystem",
+	"flag.amt":                    "AMT aktivieren oder deaktivieren, das BIOS muss die Änderung vom Betriebssystem erlauben. Ohne -amt wird der Zustand angezeigt",
+	"flag.amtCACert":              "PEM-Datei mit den CA-Zertifikaten, die das TLS-Zertifikat von -host prüfen (standardmäßig die Stammzertifikate des Systems)",
+	"flag.amtinfo.cert":           "System-Zertifikat-Hashes (und Benutzerzertifikate, wenn das AMT-Passwort angegeben ist)",
+	"flag.amtinfo.json":           "JSON-Ausgabe",
+	"flag.amtinfo.password":       "AMT-Passwort",
+	"flag.amtinfo.yaml":           "YAML-Ausgabe",
+	"flag.amtPassword":            "AMT-Passwort",
+	"flag.amtPort":                "AMT-Port von -host (Standard 16992, 16993 mit -tls)",
+	"flag.apply.dryrun":           "Den Plan der Änderungen ausgeben, ohne ihn anzuwenden",
+	"flag.apply.f":                "JSON-Dokument mit dem gewünschten Zustand des Geräts, - liest es von stdin",
+	"flag.apply.password":         "AMT-Passwort, ohne Angabe wird das Passwort des Dokuments verwendet",
+	"flag.audit":                  "AMT-Überwachungsprotokoll. Das AMT-Passwort ist erforderlich",
+	"flag.authenticationMethod":   "Authentifizierungsmethode angeben",
+	"flag.authenticationProtocol": "Authentifizierungsprotokoll angeben",
+	"flag.bios":                   "BIOS-Hersteller, -Version und -Datum aus SMBIOS sowie die Firmware-, Recovery- und Sicherheitsversion der ME",
+	"flag.bld":                    "Build-Nummer",
+	"flag.bootToBIOS":             "Beim nächsten Start in das BIOS-Setup starten",
+	"flag.bootToPXE":              "Beim nächsten Start aus dem Netzwerk (PXE) starten",
+	"flag.bulk.amtCACert":         "PEM-Datei mit den CA-Zertifikaten, die die TLS-Zertifikate der Geräte prüfen (standardmäßig die Stammzertifikate des Systems)",
+	"flag.bulk.password":          "AMT-Passwort der Geräte, die kein password angeben",
+	"flag.bulk.skipAMTCertCheck":  "Die TLS-Zertifikate der Geräte nicht prüfen",
+	"flag.bulk.tls":               "Mit TLS zu den Geräten verbinden, die kein tls angeben",
+	"flag.bulk.user":              "Digest-Benutzer der Geräte, die kein user angeben",
+	"flag.bundle":                 "Amtinfo, das AMT-Ereignisprotokoll, die rpc-Protokolle, die Netzwerkkonfiguration des Betriebssystems und den LMS-Status in ein ZIP mit Zeitstempel schreiben, mit geschwärzten Geheimnissen",
+	"flag.cacert":                 "PEM-Datei mit den CA-Zertifikaten, die zusätzlich zu den Stammzertifikaten des Systems das Zertifikat des Websocket-Servers prüfen",
+	"flag.caCert":                 "CA-Zertifikat (PEM oder DER), dem bei der gegenseitigen Authentifizierung für Client-Zertifikate vertraut wird",
+	"flag.cacheTTL":               "Wie lange Version, Build, SKU, UUID und Zertifikat-Hashes aus der MEI zwischengespeichert werden (z. B. '1h' oder '10m'), 0 schaltet den Cache ab",
+	"flag.ccm":                    "Im Client-Control-Modus (CCM) aktivieren",
+	"flag.cert":                   "Signiertes TLS-Zertifikat (PEM oder DER) für das in AMT erzeugte Schlüsselpaar",
+	"flag.certhash.delete":        "Name oder Hex-Hash des zu löschenden vertrauenswürdigen Stamm-Hashes",
+	"flag.certWarnDays":           "Vor Zertifikaten warnen, die innerhalb dieser Anzahl von Tagen ablaufen",
+	"flag.checkcert.config":       "Konfigurationsdatei oder URL einer smb:-Dateifreigabe mit den acmactivate-Einstellungen angeben",
+	"flag.chunksize":              "Antworten, die größer als diese Anzahl Bytes sind, in Teile aufteilen, die der Server wieder zusammensetzt, 0 sendet sie vollständig",
+	"flag.clear":                  "Das AMT-Ereignisprotokoll löschen, nachdem alle Einträge angezeigt wurden, das AMT-Passwort muss erneut eingegeben werden. Erfordert -eventlog, nicht mit -count oder -offset erlaubt",
+	"flag.clientCert":             "Client-Zertifikat angeben",
+	"flag.command":                "Befehl, der auf jedem Gerät ausgeführt wird, z. B. 'amtinfo -ver -uuid' oder 'power cycle', Argumente nach den Optionen werden angehängt",
+	"flag.commonName":             "Allgemeiner Name der Zertifikatsignieranforderung (standardmäßig der Hostname des Betriebssystems)",
+	"flag.config":                 "Datei mit Standardwerten der Optionen (rpc.yaml oder rpc.json)",
+	"flag.configJson":             "Konfiguration als JSON-Zeichenkette",
+	"flag.continuous":             "Weiterlaufen und die Geräteinformationen alle -interval über dieselbe Serververbindung erneut senden",
+	"flag.control":                "Unix-Socket oder unter Windows localhost-Adresse, unter der die Steuerungs-API bereitgestellt wird, keine wenn leer",
+	"flag.controlToken":           "Datei mit dem Token, mit dem sich Clients der Steuerungs-API authentifizieren, wird bei Fehlen erstellt (standardmäßig control.token im rpc-Ordner des Cache-Verzeichnisses des Benutzers)",
+	"flag.count":                  "Höchstzahl der angezeigten Überwachungs- oder Ereignisprotokolleinträge, 0 zeigt alle an",
+	"flag.csr":                    "Datei, in die die Zertifikatsignieranforderung für das in AMT erzeugte Schlüsselpaar geschrieben wird",
+	"flag.d":                      "DNS-Suffix überschreiben",
+	"flag.deactivate.force":       "Wie -f, deaktiviert außerdem ohne Bestätigung, wenn AMT im Admin-Control-Modus ist oder CIRA verbunden ist",
+	"flag.delete":                 "Name des zu löschenden Weckalarms",
+	"flag.deleteOnCompletion":     "AMT löscht den Alarm von -add, sobald er ausgeführt wurde",
+	"flag.diag.lmsaddress":        "Zu prüfende LMS-Adresse",
+	"flag.diag.lmsport":           "Zu prüfender LMS-Port",
+	"flag.diag.password":          "AMT-Passwort, das Ereignisprotokoll wird nur damit hinzugefügt",
+	"flag.dir":                    "Verzeichnis, in das das Paket geschrieben wird (standardmäßig das aktuelle Verzeichnis)",
+	"flag.disable":                "802.1x an der kabelgebundenen Schnittstelle deaktivieren",
+	"flag.dns":                    "PKI-DNS-Suffix von AMT und DNS-Suffix des Betriebssystems",
+	"flag.dnssuffix":              "AMT zuzuweisendes DNS-Suffix - ohne Angabe wird das DNS-Suffix des Host-Betriebssystems verwendet",
+	"flag.dryrun":                 "Den Befehl prüfen und ausgeben, was an AMT oder den Server gesendet würde, ohne etwas zu ändern",
+	"flag.enable":                 "Durch Kommas getrennte Umleitungsfunktionen, die aktiviert werden: kvm, sol, ider",
+	"flag.enablewifiport.disable": "Den WLAN-Port und die Synchronisierung lokaler Profile deaktivieren",
+	"flag.encryptionMethod":       "Verschlüsselungsmethode angeben",
+	"flag.envdetection":           "Durch Kommas getrennte Intranet-Domänen, in denen AMT keine Verbindung zum MPS aufbaut",
+	"flag.eventlog":               "AMT-Ereignisprotokoll. Das AMT-Passwort ist erforderlich",
+	"flag.exclude":                "Durch Kommas getrennte Felder, die in den Geräteinformationen ausgelassen werden (hostname,fqdn,ipaddress,hardware,certhashes,friendlyname,tags)",
+	"flag.f":                      "Erzwingen, auch wenn das Gerät bei keinem Server registriert ist",
+	"flag.file":                   "CSV- oder JSON-Datei mit den Geräten, die Spalten oder Schlüssel sind host, amtPort, tls, user und password",
+	"flag.fips":                   "Das Passwort mit dem DRBG eines nach FIPS 140 validierten Kryptomoduls erzeugen, rpc schlägt fehl, wenn keines aktiviert ist",
+	"flag.force":                  "Die CIRA-Konfiguration ersetzen, auch wenn sie bereits übereinstimmt, nötig zum Ändern des MPS-Passworts",
+	"flag.fqdn":                   "Den vollqualifizierten Hostnamen mit dem DNS-Suffix des Betriebssystems synchronisieren",
+	"flag.gateway":                "AMT zuzuweisende Gateway-Adresse",
+	"flag.generate":               "Ein zufälliges Passwort erzeugen und ohne Cloud-Interaktion in AMT setzen",
+	"flag.generatePassword":       "Das AMT-Passwort der lokalen CCM-Aktivierung erzeugen statt es einzulesen, es wird vor dem Aktivieren mit -out, -keyring oder -vault gespeichert",
+	"flag.h":                      "Hostnamen überschreiben",
+	"flag.heartbeat":              "Intervall der Websocket-Pings, die die Serververbindung aufrechterhalten, 0 schaltet sie ab",
+	"flag.host":                   "Hostname oder IP-Adresse eines entfernten AMT-Geräts, wsman wird über das Netzwerk dorthin statt an den lokalen LMS gesendet",
+	"flag.hostname":               "Hostname des Betriebssystems",
+	"flag.hw":                     "Hardwareinventar aus SMBIOS: Hersteller, Modell, Seriennummer, Inventarnummer, CPU und Arbeitsspeicher",
+	"flag.ieee8021xPassword":      "802.1x-Passwort, wenn authenticationProtocol PEAPv0/EAP-MSCHAPv2(2) ist",
+	"flag.ieee8021xProfileName":   "Zu konfigurierender Eintrag von ieee8021xConfigs der Konfigurationsdatei",
+	"flag.ifname":                 "Name der Host-Schnittstelle, aus der die Einstellungen gelesen werden, statt der Schnittstelle mit der MAC-Adresse von AMT",
+	"flag.insecure-skip-verify":   "Prüfung des Websocket-Serverzertifikats überspringen. Die Verbindung kann abgefangen werden, verwenden Sie stattdessen -pin-sha256",
+	"flag.interactive":            "Die Aktivierungseinstellungen abfragen und vor dem Aktivieren Vorabprüfungen ausführen",
+	"flag.interval":               "Zeit zwischen den Aktualisierungen der Geräteinformationen von -continuous (z. B. '15m' oder '1h')",
+	"flag.ipv6addr":               "AMT zuzuweisende IPv6-Adresse - ohne Angabe wird die globale IPv6-Adresse der aktiven Netzwerkschnittstelle des Betriebssystems verwendet",
+	"flag.ipv6gateway":            "AMT zuzuweisende IPv6-Gateway-Adresse",
+	"flag.json":                   "JSON-Ausgabe",
+	"flag.keyring":                "Das erzeugte Passwort im Schlüsselbund des Betriebssystems speichern, es wird mit -passwordFromKeyring gelesen",
+	"flag.kvm":                    "Zustand der KVM-, SOL- und IDE-R-Umleitung und des Umleitungs-Listeners. Das AMT-Passwort ist erforderlich",
+	"flag.l":                      "Protokollstufe (panic,fatal,error,warn,info,debug,trace)",
+	"flag.lan":                    "LAN-Einstellungen",
+	"flag.length":                 "Länge des erzeugten Passworts (8-32)",
+	"flag.linkPreference":         "WLAN-Verbindungspräferenz: me oder host. Leer lassen, um die aktuelle Präferenz beizubehalten",
+	"flag.linkPreferenceTimeout":  "Sekunden, die AMT die WLAN-Verbindung mit -linkPreference me behält, bevor sie an den Host zurückgeht",
+	"flag.lmsaddress":             "LMS-Adresse. Damit lässt sich der Ort des LMS zur Fehlersuche ändern.",
+	"flag.lmsport":                "LMS-Port",
+	"flag.local":                  "Befehl direkt auf AMT ohne Cloud-Interaktion ausführen",
+	"flag.logfile":                "Das Protokoll in diese Datei statt nach stderr schreiben",
+	"flag.logjson":                "JSON-Protokollformat",
+	"flag.loglevels":              "Protokollstufe pro Modul, z. B. 'rps=debug,amt=trace' (Module: rpc,flags,amt,rps,lms,local,agent,info)",
+	"flag.logmaxsize":             "Größe in MB, ab der die Protokolldatei rotiert wird",
+	"flag.logs":                   "rpc-Protokolldateien, die dem Paket hinzugefügt werden, durch Kommas getrennt, ihre rotierten Dateien werden ebenfalls hinzugefügt",
+	"flag.lowercase":              "Den Hostnamen in Kleinbuchstaben synchronisieren",
+	"flag.mac":                    "MAC-Adresse der Host-Schnittstelle, aus der die Einstellungen gelesen werden, z. B. 'a4:bb:6d:01:02:03'",
+	"flag.maxSkew":                "Uhrzeitabweichung zum Server oder zur Zeit von -ntp, ab der -precheck fehlschlägt",
+	"flag.mebxPassword":           "MEBx-Passwort, das nach der lokalen ACM-Aktivierung gesetzt wird",
+	"flag.mode":                   "Aktueller Steuerungsmodus",
+	"flag.modes":                  "Ob die Firmware-Einstellungen die CCM- und ACM-Aktivierung erlauben: AMT-Zustand und Änderung vom Betriebssystem, Fernkonfiguration, TLS-Modus der Bereitstellung und aktive Stamm-Hashes",
+	"flag.mpsaddress":             "Hostname oder IP-Adresse des MPS",
+	"flag.mpscert":                "Stammzertifikat (PEM oder DER) des Serverzertifikats des MPS",
+	"flag.mpscn":                  "Allgemeiner Name des Serverzertifikats des MPS (standardmäßig -mpsaddress)",
+	"flag.mpspassword":            "Passwort, mit dem sich AMT beim MPS authentifiziert",
+	"flag.mpsport":                "Port des MPS",
+	"flag.mpsuser":                "Benutzername, mit dem sich AMT beim MPS authentifiziert",
+	"flag.mqttBroker":             "MQTT-Broker, an den der Status des Vorgangs veröffentlicht wird, z. B. 'tcp://broker:1883' oder 'ssl://broker:8883'",
+	"flag.mqttPassword":           "Passwort des MQTT-Brokers",
+	"flag.mqttTopic":              "MQTT-Topic der Statusereignisse",
+	"flag.mqttUser":               "Benutzer des MQTT-Brokers",
+	"flag.n":                      "Prüfung des Websocket-Serverzertifikats überspringen",
+	"flag.name":                   "Anzeigename, der diesem Gerät zugeordnet wird",
+	"flag.netmask":                "AMT zuzuweisende Netzmaske - ohne Angabe wird die Netzmaske der aktiven Netzwerkschnittstelle des Betriebssystems verwendet",
+	"flag.nocache":                "Version, Build, SKU, UUID und Zertifikat-Hashes aus der MEI statt aus dem Cache lesen und erneut zwischenspeichern",
+	"flag.nocompression":          "Keine permessage-deflate-Komprimierung der Websocket-Nachrichten aushandeln",
+	"flag.nosymbols":              "Das Passwort nur mit den Symbolen + - . / = erzeugen, die in Shells und XML keine Anführungszeichen brauchen",
+	"flag.ntp":                    "NTP-Server (Host oder Host:Port), der statt der Uhr des Host-Betriebssystems nach der Zeit gefragt wird",
+	"flag.offset":                 "Anzahl der übersprungenen Überwachungs- oder Ereignisprotokolleinträge",
+	"flag.once":                   "Nur beim nächsten Start von -source starten, der einzige Modus, den AMT unterstützt",
+	"flag.opstate":                "AMT-Betriebszustand (in MEBx aktiviert) und Bereitstellungszustand",
+	"flag.otel-endpoint":          "OpenTelemetry-Collector, an den Traces und Metriken mit OTLP über HTTP exportiert werden, z. B. 'http://collector:4318'",
+	"flag.out":                    "Das erzeugte Passwort in diese Datei schreiben, nur für den Besitzer lesbar",
+	"flag.p":                      "Adresse und Port des Proxys",
+	"flag.partial":                "CIRA-, TLS- und WLAN-Konfiguration entfernen, AMT aber aktiviert lassen. Läuft lokal",
+	"flag.password":               "AMT-Passwort",
+	"flag.passwordFile":           "Das AMT-Passwort aus der ersten Zeile dieser Datei lesen, sie darf für Gruppe und andere nicht lesbar sein",
+	"flag.passwordFromKeyring":    "Das AMT-Passwort aus dem Schlüsselbund des Betriebssystems (Dienst 'rpc', Konto 'admin') lesen statt danach zu fragen",
+	"flag.periodic":               "Sekunden zwischen den regelmäßigen Verbindungen zum MPS, 0 verbindet nur auf Benutzeranforderung und bei Alarmen",
+	"flag.pin-sha256":             "Durch Kommas getrennte Base64-SHA-256-Hashes des öffentlichen Schlüssels des Websocket-Servers oder einer seiner CAs, z. B. 'sha256//BASE64'",
+	"flag.precheck":               "Vor dem Aktivieren Steuerungsmodus, DNS-Suffix, Uhr, Zertifikat-Hashes und Erreichbarkeit des Servers prüfen und bei der ersten fehlgeschlagenen Prüfung anhalten",
+	"flag.preferSubnet":           "IPv4-Subnetz in CIDR-Notation der zu synchronisierenden Hostadresse, wenn die Host-Schnittstelle mehrere hat, z. B. '192.168.1.0/24'",
+	"flag.prefixlen":              "Präfixlänge der IPv6-Adresse (Standard 64)",
+	"flag.primarydns":             "AMT zuzuweisender primärer DNS - ohne Angabe werden die DNS-Server des Host-Betriebssystems verwendet",
+	"flag.priority":               "Priorität angeben",
+	"flag.privateKey":             "Privaten Schlüssel angeben",
+	"flag.probe":                  "Vom Host-Betriebssystem aus zu den MPS-Servern verbinden und Erreichbarkeit, TLS-Handshake und Latenz melden, impliziert -ras",
+	"flag.profile":                "Name des zu verwendenden Profils",
+	"flag.profileName":            "Name des WLAN-Profils angeben",
+	"flag.provisioningCert":       "Bereitstellungszertifikat, Base64-codiert oder der Pfad einer .pfx-Datei",
+	"flag.provisioningCertPwd":    "Passwort des Bereitstellungszertifikats",
+	"flag.proxy":                  "Proxy-URL (http:// oder socks5://). Ohne Angabe werden HTTPS_PROXY und NO_PROXY verwendet",
+	"flag.proxypassword":          "Passwort der Basic-Authentifizierung des Proxys",
+	"flag.proxyuser":              "Benutzer der Basic-Authentifizierung des Proxys",
+	"flag.pskPassphrase":          "PSK-Passphrase angeben",
+	"flag.pxeTimeout":             "Sekunden, die AMT einen PXE-Start vor der 802.1x-Authentifizierung erlaubt, 0 deaktiviert den PXE-Start",
+	"flag.ras":                    "Fernzugriffsstatus (und MPS-Server, Umgebungserkennung und Auslöser, wenn das AMT-Passwort angegeben ist)",
+	"flag.reason":                 "Grund der Deaktivierung, er wird protokolliert und an den Server gesendet",
+	"flag.redirection.disable":    "Durch Kommas getrennte Umleitungsfunktionen, die deaktiviert werden: kvm, sol, ider",
+	"flag.report":                 "Datei, in die die Ergebnisse aller Geräte als JSON geschrieben werden",
+	"flag.reprovision":            "Ein bereits aktiviertes Gerät deaktivieren und erneut aktivieren, mit -local",
+	"flag.reset":                  "Das Gerät zurücksetzen, sobald die Startquelle gesetzt ist",
+	"flag.resume":                 "Den gespeicherten Fortschritt einer unterbrochenen RPS-Aktivierung des Geräts mit demselben -profile an den Server senden, für einen Server, der Aktivierungen fortsetzt. RPS beginnt die Aktivierung von vorn",
+	"flag.retries":                "Anzahl der Wiederholungen einer fehlgeschlagenen Verbindung zum Server",
+	"flag.retryDelay":             "Wartezeit vor der ersten Wiederholung, bei jeder weiteren Wiederholung mit Zufallsanteil verdoppelt (z. B. '1s' oder '500ms')",
+	"flag.returncodes.json":       "JSON-Ausgabe",
+	"flag.returncodes.yaml":       "YAML-Ausgabe",
+	"flag.rpsAPI":                 "Basis-URL der REST-API von RPS, z. B. 'https://server/rps'. Steuerungsmodus, DNS-Suffix und Zertifikat-Hashes des Geräts werden vor dem Aktivieren mit dem daraus gelesenen Profil verglichen",
+	"flag.seccheck":               "Die Firmware-Version mit den Intel-Sicherheitshinweisen (INTEL-SA) vergleichen und melden, ob sie betroffen ist",
+	"flag.secondary":              "Hostname oder IP-Adresse des MPS, auf den AMT ausweicht, wenn der primäre nicht erreichbar ist",
+	"flag.secondarycn":            "Allgemeiner Name des Serverzertifikats des sekundären MPS (standardmäßig -secondary)",
+	"flag.secondarydns":           "AMT zuzuweisender sekundärer DNS",
+	"flag.secondaryport":          "Port des sekundären MPS (standardmäßig -mpsport)",
+	"flag.secrets":                "Datei mit Geheimnissen angeben",
+	"flag.secretsFile":            "Datei mit AMT_PASSWORD, PROXY_PASSWORD und RPS_TOKEN des Agenten als Zeilen NAME=Wert, geschrieben von service install",
+	"flag.selftest.lmsaddress":    "Zu prüfende LMS-Adresse",
+	"flag.selftest.lmsport":       "Zu prüfender LMS-Port",
+	"flag.serverOrder":            "Reihenfolge, in der die Server von -u versucht werden: listed oder latency",
+	"flag.sessionFile":            "Datei, in der der Zustand einer RPS-Aktivierung bis zu ihrem Abschluss gespeichert wird (standardmäßig activation.json im rpc-Ordner des Cache-Verzeichnisses des Benutzers)",
+	"flag.sessionlog":             "Die Konsolenausgabe der Sitzung an diese Datei anhängen",
+	"flag.short":                  "Den Hostnamen ohne seine Domäne synchronisieren",
+	"flag.show":                   "Die Geräteinformationen ausgeben, die an den Server gesendet würden, ohne eine Verbindung aufzubauen",
+	"flag.skipAMTCertCheck":       "Das TLS-Zertifikat von -host nicht prüfen",
+	"flag.sku":                    "Produkt-SKU",
+	"flag.source":                 "Gerät, von dem beim nächsten Start gestartet wird: pxe, hdd, cd",
+	"flag.ssid":                   "SSID angeben",
+	"flag.start":                  "Erste Weckzeit von -add, HH:MM der lokalen Uhr für das nächste Auftreten oder RFC3339",
+	"flag.static":                 "Neues Passwort für AMT angeben",
+	"flag.staticip":               "AMT zuzuweisende IP-Adresse - ohne Angabe wird die IP-Adresse der aktiven Netzwerkschnittstelle des Betriebssystems verwendet",
+	"flag.status.maxSkew":         "Uhrzeitabweichung zwischen AMT und dem Host, ab der die Uhrprüfung warnt",
+	"flag.status.password":        "AMT-Passwort, die Prüfungen von TLS, Uhr, Hostname und Zertifikaten benötigen es",
+	"flag.syncclock.local":        "AMT direkt ohne Cloud-Interaktion mit der Uhr des Host-Betriebssystems synchronisieren",
+	"flag.syncclock.maxSkew":      "Uhrzeitabweichung zwischen AMT und dem Host oder der Zeit von -ntp, innerhalb der die Uhr unverändert bleibt (z. B. '2s' oder '1m'), 0 synchronisiert immer",
+	"flag.syncip.primarydns":      "AMT zuzuweisender primärer DNS",
+	"flag.syncwifi.ssid":          "Durch Kommas getrennte SSIDs der zu synchronisierenden WLAN-Profile des Betriebssystems - ohne Angabe werden alle von AMT unterstützten Profile synchronisiert",
+	"flag.sys":                    "Name, Version und Build des Host-Betriebssystems, Architektur, rpc-Version und ob LMS oder das AMT von -host Verbindungen annimmt",
+	"flag.t":                      "AMT-Zeitlimit - Wartezeit, bis AMT bereit ist (z. B. '2m' oder '30s')",
+	"flag.tag":                    "Metadaten-Tag Schlüssel=Wert, das an den Server gesendet wird, die Option für mehrere Tags wiederholen",
+	"flag.tasks":                  "Durch Kommas getrennte auszuführende Wartungsaufgaben (syncclock,synchostname,syncip,syncdeviceinfo)",
+	"flag.template":               "Präfix und Suffix um den Hostnamen, z. B. 'amt-{hostname}-lab'",
+	"flag.tenant":                 "TenantID",
+	"flag.tenantId":               "TenantID, wie -tenant",
+	"flag.timeout":                "Wartezeit für jeden MEI-Befehl, bevor er mit MEITimeout fehlschlägt (z. B. '30s'), 0 wartet unbegrenzt",
+	"flag.tls":                    "Mit TLS zu -host verbinden",
+	"flag.tlssettings.local":      "TLS auch auf der lokalen (LMS-)Schnittstelle aktivieren, rpc erreicht AMT dann mit -transport lms-tls",
+	"flag.tlssettings.mode":       "TLS-Authentifizierungsmodus: Server, ServerAndNonTLS, Mutual, MutualAndNonTLS (Standard Server)",
+	"flag.token":                  "JWT-Token zur Autorisierung",
+	"flag.truncate":               "Hostnamen kürzen, die länger als die von AMT akzeptierten 63 Zeichen sind",
+	"flag.trustedCN":              "Allgemeiner Name, der bei der gegenseitigen Authentifizierung in Client-Zertifikaten verlangt wird",
+	"flag.tz":                     "IANA-Zeitzone (z. B. 'Europe/Berlin'), in der die Zeiten angegeben werden und gegen die die AMT-Uhr auf Ortszeit oder eine verpasste Sommerzeitumstellung geprüft wird (standardmäßig die Zeitzone des Hosts)",
+	"flag.u":                      "Websocket-Adresse des Servers, eine durch Kommas getrennte Liste oder wiederholtes -u für das Ausweichen zwischen Servern",
+	"flag.upgrade":                "Ein im Client-Control-Modus aktiviertes Gerät in den Admin-Control-Modus hochstufen, mit -local -acm",
+	"flag.uploadLogs":             "Die Protokolleinträge des Befehls an den Server senden, der sie zur Fehlersuche beim Gerät aufbewahrt",
+	"flag.user":                   "Digest-Benutzer von -host",
+	"flag.userCert":               "Nur Benutzerzertifikate. Das AMT-Passwort ist erforderlich",
+	"flag.username":               "Benutzernamen angeben",
+	"flag.uuid":                   "Eindeutige Kennung",
+	"flag.v":                      "Ausführliche Ausgabe",
+	"flag.validate":               "Bei Hostnamen fehlschlagen, die länger als die von AMT akzeptierten 63 Zeichen sind oder andere Zeichen als Buchstaben, Ziffern und '-' enthalten",
+	"flag.value":                  "PKI-DNS-Suffix, das mit der Domäne des Bereitstellungszertifikats verglichen wird, z. B. corp.example.com",
+	"flag.vault":                  "Das aktuelle Passwort aus diesem Secret Store lesen und das erzeugte hineinschreiben, z. B. 'vault://vault.example.com:8200/secret/amt/device1' oder 'cyberark://ccp.example.com/?appId=rpc&safe=AMT&object=device1&accountId=12_3'",
+	"flag.vaultToken":             "Token des mit -vault angegebenen Secret Stores",
+	"flag.ver":                    "BIOS-Version",
+	"flag.verbose-progress":       "Eine Fortschrittsanzeige einblenden, während der Server AMT konfiguriert",
+	"flag.version.json":           "JSON-Ausgabe",
+	"flag.version.yaml":           "YAML-Ausgabe",
+	"flag.warn-only":              "Nur die Zertifikat-Hashes veralteter (SHA1-)CAs, impliziert -cert",
+	"flag.wipe":                   "WLAN-Profile, Zertifikate, die CIRA-Konfiguration und das Überwachungsprotokoll entfernen und dann deaktivieren. Läuft lokal",
+	"flag.wired8021x.caCert":      "CA-Zertifikat des RADIUS-Servers angeben",
+	"flag.wired8021x.config":      "Konfigurationsdatei oder URL einer smb:-Dateifreigabe mit ieee8021xConfigs angeben",
+	"flag.workers":                "Anzahl der Geräte, auf denen der Befehl gleichzeitig läuft",
+	"flag.xml":                    "Datei mit dem WS-MAN-Umschlag, der an AMT gesendet wird, - liest ihn von stdin",
+	"flag.yaml":                   "YAML-Ausgabe",
+}
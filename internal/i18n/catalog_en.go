@@ -0,0 +1,318 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package i18n
+
+// en has every message. The descriptions of the return codes are kept in pkg/utils and
+// the usage of the flags with the flags.
+var en = map[string]string{
+	"usage.title":          "Remote Provisioning Client (RPC) - used for activation, deactivation, maintenance and status of AMT",
+	"usage.usage":          "Usage",
+	"usage.commands":       "Supported Commands",
+	"usage.example":        "Example",
+	"usage.moreInfo":       "Run '%s' for more information on a command.",
+	"usage.language":       "Select the language of the output with -lang en, es or de, or with the RPC_LANG environment variable.",
+	"usage.nonInteractive": "Never prompt with -nonInteractive or RPC_NON_INTERACTIVE=true, a missing password or confirmation fails instead.",
+	"usage.transport":      "Reach the local AMT through LMS or the LME driver of rpc only with -transport lms or lme, or with RPC_TRANSPORT, auto uses LMS when it is running. lms-tls is LMS on port 16993, once TLS is enabled on the local interface.",
+	"usage.output":         "Copy the result document to a file, syslog or eventlog with -output, or with the RPC_OUTPUT environment variable.",
+	"usage.options":        "Options",
+	"usage.returnCodes":    "Return codes",
+
+	"usage.cmd.activate":    "Activate this device with a specified profile",
+	"usage.cmd.agent":       "Runs as a long lived process and periodically executes maintenance tasks. AMT password is required",
+	"usage.cmd.amtinfo":     "Displays information about AMT status and configuration",
+	"usage.cmd.apply":       "Brings this device to the activation, host name, WiFi, TLS and CIRA settings of a JSON document, printing the plan of changes first",
+	"usage.cmd.boot":        "Sets the device this device boots from on the next boot, and resets it with -reset. AMT password is required",
+	"usage.cmd.bulk":        "Runs amtinfo, power, configure or wsman on the remote AMT devices of a CSV or JSON device list and reports the result of each",
+	"usage.cmd.checkcert":   "Checks the provisioning certificate chain against the trusted root certificate hashes of AMT",
+	"usage.cmd.configure":   "Local configuration of a feature on this device. AMT password is required",
+	"usage.cmd.deactivate":  "Deactivates this device. AMT password is required",
+	"usage.cmd.diag":        "Collects amtinfo, the AMT event log, the rpc logs, the OS network configuration and LMS status into a zip with the secrets redacted, for support tickets",
+	"usage.cmd.help":        "Shows the usage, the options and the return codes of a command",
+	"usage.cmd.maintenance": "Execute a maintenance task for the device. AMT password is required",
+	"usage.cmd.power":       "Power on, off, reset or cycle this device through AMT. AMT password is required",
+	"usage.cmd.selftest":    "Checks the MEI driver, a PTHI query, LMS, the paths rpc writes to and the rpc executable for support triage",
+	"usage.cmd.service":     "Install, uninstall, start or stop rpc as a service running the agent",
+	"usage.cmd.sol":         "Opens a Serial-over-LAN session to the BIOS or OS console of this device through AMT, Ctrl+] ends it. AMT password is required",
+	"usage.cmd.status":      "Checks control mode, CIRA, TLS, clock, hostname and certificate expiry and prints PASS, WARN or FAIL",
+	"usage.cmd.returncodes": "Lists the exit codes returned by RPC with their names and descriptions",
+	"usage.cmd.version":     "Displays the current version of RPC and the RPC Protocol version",
+	"usage.cmd.wsman":       "Sends a WS-MAN envelope to AMT as it is and prints the response. AMT password is required",
+
+	"usage.maintenance.commands":             "Supported Maintenance Commands",
+	"usage.maintenance.changepassword":       "Change the AMT password. A random password is generated by default. Specify -static to set manually. AMT password is required",
+	"usage.maintenance.changepassword.local": "Specify -generate to generate the password locally, without cloud interaction",
+	"usage.maintenance.syncdeviceinfo":       "Sync device information. AMT password is required",
+	"usage.maintenance.syncdeviceinfo.show":  "Specify -show to print the device info without sending it and -exclude to leave fields out",
+	"usage.maintenance.continuous":           "Specify -continuous to keep running and send the device info again every -interval",
+	"usage.maintenance.syncclock":            "Sync the host OS clock to AMT. AMT password is required",
+	"usage.maintenance.syncclock.ntp":        "Specify -ntp to sync AMT to an NTP server instead, without cloud interaction",
+	"usage.maintenance.syncclock.local":      "Specify -local to sync AMT to the host OS clock, -maxSkew leaves a clock within the tolerance as it is",
+	"usage.maintenance.synchostname":         "Sync the hostname of the client to AMT. AMT password is required",
+	"usage.maintenance.syncip":               "Sync the IP configuration of the host OS to AMT Network Settings. AMT password is required",
+	"usage.maintenance.syncip.static":        "If a static ip is not specified, the ip address and netmask of the host OS is used",
+	"usage.maintenance.syncip.ipv6":          "Specify -ipv6addr and -prefixlen to also set an IPv6 address, a global IPv6 address of the host OS is used if present",
+	"usage.maintenance.syncip.interface":     "Specify -ifname or -mac to read the host OS settings from a bonded or bridged interface, also for synchostname",
+	"usage.maintenance.syncip.preferSubnet":  "Specify -preferSubnet to choose among several host addresses, ex. -preferSubnet 192.168.1.0/24",
+	"usage.maintenance.syncdns":              "Sync the DNS suffix and DNS servers of the host OS to AMT, without cloud interaction. AMT password is required",
+	"usage.maintenance.syncdns.host":         "If not specified, the DNS suffix and DNS servers of the host OS are used",
+	"usage.maintenance.syncwifi":             "Replace the wifi profiles in AMT with the WPA/WPA2 personal profiles of the host OS, without cloud interaction. AMT password is required",
+	"usage.maintenance.tasks":                "Run several tasks over one server connection with -task, or all of them with -all:",
+	"usage.power.commands":                   "Supported Power Commands",
+	"usage.power.on":                         "Power on the device. AMT password is required",
+	"usage.power.off":                        "Power off the device without shutting down the OS. AMT password is required",
+	"usage.power.reset":                      "Reset the device. AMT password is required",
+	"usage.power.cycle":                      "Power the device off and on again. AMT password is required",
+	"usage.power.local":                      "The power actions are sent to AMT directly without cloud interaction.",
+	"usage.power.nextBoot":                   "-bootToBIOS and -bootToPXE apply to the next boot only and cannot be used with off.",
+	"usage.service.commands":                 "Supported Service Commands",
+	"usage.service.install":                  "Install rpc as a service running the agent. Takes the agent options. AMT password is required",
+	"usage.service.uninstall":                "Stop and remove the service",
+	"usage.service.start":                    "Start the service",
+	"usage.service.stop":                     "Stop the service",
+	"usage.service.platform":                 "The service is a Windows service or a systemd unit on Linux.",
+	"usage.configure.commands":               "Supported Configuration Commands",
+	"usage.configure.addwifisettings":        "Add or modify WiFi settings in AMT. AMT password is required. A config.yml, config.json or command line flags must be provided for all settings. This command runs without cloud interaction.",
+	"usage.configure.enablewifiport":         "Enables WiFi port and local profile synchronization settings in AMT, or disables them with -disable. AMT password is required.",
+	"usage.configure.tlssettings":            "Configures TLS in AMT. AMT password is required. Run first to generate a key pair in AMT and a CSR, then with the signed certificate to enable TLS.",
+	"usage.configure.cira":                   "Configures CIRA in AMT: the MPS server and its root certificate, environment detection and remote access policies. AMT password is required.",
+	"usage.configure.mps":                    "Configures CIRA like cira, with a secondary MPS AMT falls back to when the primary can not be reached. A configuration that already matches is kept. AMT password is required.",
+	"usage.configure.wired8021x":             "Configures IEEE 802.1x on the wired interface of AMT with EAP-TLS or PEAPv0/EAP-MSCHAPv2 (authenticationProtocol 0 or 2). AMT password is required.",
+	"usage.configure.alarmclock":             "Lists the wake alarms of AMT, or adds one with -add and -start, or deletes one with -delete. AMT password is required.",
+	"usage.configure.redirection":            "Enables or disables KVM, SOL and IDE-R redirection in AMT with -enable and -disable. AMT password is required.",
+	"usage.configure.dnssuffix":              "Sets the PKI DNS suffix AMT matches against the provisioning certificate. Only accepted before activation, no AMT password is required.",
+	"usage.configure.amtfeatures":            "Enables or disables AMT from the OS when the BIOS allows it, or shows whether it does. No AMT password is required.",
+	"usage.configure.certhash":               "Lists the trusted root certificate hashes of AMT, or adds one with -add, or deletes one with -delete, where the firmware allows it. AMT password is required.",
+
+	"info.version":                "Version",
+	"info.buildNumber":            "Build Number",
+	"info.sku":                    "SKU",
+	"info.features":               "Features",
+	"info.skuClass":               "SKU Class",
+	"info.uuid":                   "UUID",
+	"info.controlMode":            "Control Mode",
+	"info.operationalState":       "Operational State",
+	"info.enabled":                "enabled",
+	"info.disabled":               "disabled",
+	"info.disabledInMEBx":         "disabled in MEBx",
+	"info.provisioningState":      "Provisioning State",
+	"info.provisioningMode":       "Provisioning Mode",
+	"info.dnsSuffix":              "PKI DNS Suffix",
+	"info.dnsSuffixOS":            "DNS Suffix (OS)",
+	"info.hostnameOS":             "Hostname (OS)",
+	"info.manufacturer":           "Manufacturer",
+	"info.model":                  "Model",
+	"info.serialNumber":           "Serial Number",
+	"info.assetTag":               "Asset Tag",
+	"info.cpu":                    "CPU",
+	"info.memory":                 "Memory",
+	"info.biosVendor":             "BIOS Vendor",
+	"info.biosVersion":            "BIOS Version",
+	"info.biosReleaseDate":        "BIOS Release Date",
+	"info.meFirmwareVersion":      "ME Firmware Version",
+	"info.meFirmwareBuild":        "ME Firmware Build",
+	"info.meRecoveryVersion":      "ME Recovery Version",
+	"info.meRecoveryBuild":        "ME Recovery Build",
+	"info.meFirmwareSVN":          "ME Firmware SVN",
+	"info.notReported":            "not reported",
+	"info.securityAdvisories":     "Security Advisories",
+	"info.advisoryTable":          "table of",
+	"info.affected":               "affected",
+	"info.notAffected":            "not affected",
+	"info.notListed":              "not listed, the firmware is newer than the table",
+	"info.fixedIn":                "fixed in",
+	"info.advisoryUnknown":        "unknown, the firmware version could not be read",
+	"info.osName":                 "OS Name",
+	"info.osVersion":              "OS Version",
+	"info.osBuild":                "OS Build",
+	"info.architecture":           "Architecture",
+	"info.rpcVersion":             "RPC Version",
+	"info.protocolVersion":        "RPC Protocol Version",
+	"info.transport":              "Transport",
+	"info.wsmanAddress":           "WS-MAN address",
+	"info.reachable":              "reachable",
+	"info.notReachable":           "not reachable",
+	"info.redirectionListener":    "Redirection Listener",
+	"info.kvm":                    "KVM",
+	"info.sol":                    "SOL",
+	"info.ider":                   "IDE-R",
+	"info.changeFromOS":           "Change from OS",
+	"info.allowed":                "allowed",
+	"info.notAllowed":             "not allowed",
+	"info.remoteConfiguration":    "Remote Configuration",
+	"info.provisioningTLSMode":    "Provisioning TLS Mode",
+	"info.activeCertHashes":       "Active Root Hashes",
+	"info.ccmActivation":          "CCM Activation",
+	"info.acmActivation":          "ACM Activation",
+	"info.rasNetwork":             "RAS Network",
+	"info.rasRemoteStatus":        "RAS Remote Status",
+	"info.rasTrigger":             "RAS Trigger",
+	"info.rasMPSHostname":         "RAS MPS Hostname",
+	"info.dhcpEnabled":            "DHCP Enabled",
+	"info.dhcpMode":               "DHCP Mode",
+	"info.linkStatus":             "Link Status",
+	"info.ipAddress":              "IP Address",
+	"info.macAddress":             "MAC Address",
+	"info.ipv6Address":            "IPv6 Address",
+	"info.wiredAdapter":           "---Wired Adapter---",
+	"info.wirelessAdapter":        "---Wireless Adapter---",
+	"info.certHashes":             "---Certificate Hashes---",
+	"info.noCertHashes":           "---No Certificate Hashes Found---",
+	"info.noDeprecatedCertHashes": "---No Deprecated Certificate Hashes Found---",
+	"info.publicKeyCerts":         "---Public Key Certs---",
+	"info.noPublicKeyCerts":       "---No Public Key Certs Found---",
+	"info.auditLog":               "---Audit Log (%d of %d records)---",
+	"info.eventLog":               "---Event Log (%d of %d records)---",
+	"info.eventLogCleared":        "Event log cleared",
+
+	"error.activate.alreadyActivated":                "device is already %s",
+	"error.activate.alreadyActivatedReprovision":     "device is already %s, use -reprovision to activate it again",
+	"error.activate.alreadyCCM":                      "device is already activated in client control mode, use -upgrade to move it to admin control mode or -reprovision to activate it again",
+	"error.activate.cancelled":                       "activation cancelled",
+	"error.activate.ccmOrACM":                        "must specify -ccm or -acm, but not both",
+	"error.activate.fipsWithoutGenerate":             "-fips requires -generatePassword",
+	"error.activate.generateOnlyLocalCCM":            "-generatePassword is only supported with local CCM activation, without -upgrade or -reprovision",
+	"error.activate.generateWithPassword":            "-generatePassword can not be used with -password or AMT_PASSWORD",
+	"error.activate.generateWithoutStore":            "-generatePassword requires one of -out, -keyring or -vault",
+	"error.activate.interactiveOrNonInteractive":     "provide either -interactive or -nonInteractive, but not both",
+	"error.activate.interactiveWithJSON":             "-interactive can not be used with -json or -yaml",
+	"error.activate.invalidAMTPassword":              "invalid -amtPassword, AMT rejects it",
+	"error.activate.invalidUUID":                     "uuid provided does not follow proper uuid format",
+	"error.activate.mebxOnlyLocalACM":                "-mebxPassword is only supported with local ACM activation",
+	"error.activate.missingField":                    "Missing value for field: %s",
+	"error.activate.precheckFailed":                  "pre-activation check failed: %s",
+	"error.activate.preflightFailed":                 "pre-flight check failed: %s",
+	"error.activate.resumeOnlyRPS":                   "-resume and -sessionFile are only supported with RPS activation",
+	"error.activate.rpsAPIOnlyRPS":                   "-rpsAPI is only supported with RPS activation",
+	"error.activate.rpsAPIURL":                       "-rpsAPI must be an http:// or https:// URL",
+	"error.activate.storeWithoutGenerate":            "-out, -keyring and -vault require -generatePassword",
+	"error.activate.upgradeOnlyLocal":                "-upgrade and -reprovision are only supported with local activation",
+	"error.activate.upgradeOrReprovision":            "provide either -upgrade or -reprovision, but not both",
+	"error.activate.upgradeWithoutACM":               "-upgrade requires -acm",
+	"error.activate.uuidLocal":                       "-uuid cannot be use in local activation",
+	"error.addWithDelete":                            "-add cannot be used with -delete",
+	"error.agent.readSecretsFile":                    "unable to read -secretsFile",
+	"error.agent.unsupportedTask":                    "unsupported agent task: %s",
+	"error.alarmclock.addRequired":                   "-start, -interval and -deleteOnCompletion require -add",
+	"error.alarmclock.interval":                      "-interval must be a whole number of minutes and not negative",
+	"error.alarmclock.invalidStart":                  "invalid -start",
+	"error.alarmclock.startRequired":                 "-add requires -start",
+	"error.amtfeatures.invalidAMT":                   "invalid -amt %s, use %s or %s",
+	"error.apply.acmCertRequired":                    "activation in acm mode needs provisioningCert and provisioningCertPwd",
+	"error.apply.activationMode":                     "activation mode must be ccm or acm, not %q",
+	"error.apply.ciraNegativePeriodic":               "periodicInterval of cira must not be negative",
+	"error.apply.ciraPort":                           "mpsPort of cira must be between 1 and 65535",
+	"error.apply.ciraRequired":                       "mpsAddress, mpsUser, mpsPassword and mpsCert of cira are required",
+	"error.apply.documentRequired":                   "-f is required",
+	"error.apply.emptyDocument":                      "the document describes none of activation, hostname, wifiConfigs, tls or cira",
+	"error.apply.hostnameTemplate":                   "the hostname template must contain %s once",
+	"error.apply.invalidDocument":                    "invalid document",
+	"error.apply.invalidSecondaryMPS":                "invalid secondary MPS of cira",
+	"error.apply.mebxOnlyACM":                        "mebxPassword is only supported with acm activation",
+	"error.apply.readDocument":                       "unable to read the document",
+	"error.apply.tlsCACertWithoutMutual":             "caCert and trustedCN of tls are only valid with a mutual authentication mode",
+	"error.apply.tlsCertRequired":                    "tls needs the signed certificate in cert, create the CSR with configure tlssettings first",
+	"error.apply.tlsMutualWithoutCACert":             "mutual authentication of tls requires a caCert",
+	"error.boot.once":                                "AMT applies the boot source to the next boot only, -once=false is not supported",
+	"error.boot.source":                              "-source must be one of %s",
+	"error.boot.usb":                                 "-source usb is not supported, AMT boots from USB only through USB redirection of a disk image, which rpc does not serve",
+	"error.bulk.command":                             "bulk runs amtinfo, power, configure or wsman, not %s",
+	"error.bulk.fileAndCommand":                      "-file and -command are required",
+	"error.bulk.flagFromDeviceList":                  "-%s is taken from the device list, not from -command",
+	"error.bulk.invalidCommand":                      "invalid -command for %s",
+	"error.bulk.noDevices":                           "%s lists no devices",
+	"error.bulk.readDeviceList":                      "unable to read the device list",
+	"error.bulk.workers":                             "-workers must be at least 1",
+	"error.bulk.xmlFromStdin":                        "bulk can not read the WS-MAN envelope from stdin, use a file with -xml",
+	"error.certhash.aliasRequired":                   "-add of a hash or of a certificate without common name requires -alias",
+	"error.certhash.aliasWithoutAdd":                 "-alias requires -add",
+	"error.certhash.invalidAdd":                      "invalid -add",
+	"error.changepassword.generateRequired":          "-length, -nosymbols, -fips, -out, -keyring and -vault require -generate",
+	"error.changepassword.generateWithStatic":        "-generate sets the password locally and cannot be combined with -static or -u",
+	"error.changepassword.invalidStatic":             "invalid -static password, AMT rejects it",
+	"error.changepassword.length":                    "-length must be between %d and %d",
+	"error.changepassword.oneStore":                  "provide only one of -out, -keyring or -vault",
+	"error.checkcert.certRequired":                   "-provisioningCert or a -config with the provisioning certificate is required",
+	"error.cira.mpsport":                             "-mpsport must be between 1 and 65535",
+	"error.cira.negativePeriodic":                    "-periodic must not be negative",
+	"error.cira.required":                            "-mpsaddress, -mpsuser, -mpspassword and -mpscert are required",
+	"error.configPasswordMismatch":                   "password does not match config file password",
+	"error.deactivate.urlOrPartial":                  "provide either a 'url' or a 'partial', but not both",
+	"error.deactivate.urlOrWipe":                     "provide either a 'url' or a 'wipe', but not both",
+	"error.deactivate.wipeOrPartial":                 "provide either a 'wipe' or a 'partial', but not both",
+	"error.diag.bundleRequired":                      "diag requires -bundle",
+	"error.diag.notDirectory":                        "-dir %s is not a directory",
+	"error.dnssuffix.invalidValue":                   "invalid -value",
+	"error.dnssuffix.tooLong":                        "-value is longer than %d characters",
+	"error.dnssuffix.valueRequired":                  "-value is required",
+	"error.enabledAndDisabled":                       "%s cannot be enabled and disabled",
+	"error.enablewifiport.linkPreference":            "-linkPreference must be %s or %s",
+	"error.enablewifiport.linkPreferenceTimeout":     "-linkPreferenceTimeout must be between 1 and 65535 seconds",
+	"error.enablewifiport.linkPreferenceWithDisable": "-linkPreference cannot be used with -disable",
+	"error.fips":                                     "-fips",
+	"error.help.unknownCommand":                      "no help for %s",
+	"error.info.advisoriesWithoutSeccheck":           "-advisories requires -seccheck",
+	"error.info.clearWithCount":                      "-clear clears all records and can not be used with -count or -offset",
+	"error.info.clearWithoutEventlog":                "-clear requires -eventlog",
+	"error.info.negativeCacheTTL":                    "-cacheTTL must not be negative",
+	"error.info.negativeCount":                       "-count and -offset must not be negative",
+	"error.intervalTooShort":                         "-interval must be at least one minute",
+	"error.invalidConfigJSON":                        "invalid -configJson",
+	"error.invalidMEBxPassword":                      "invalid MEBx password",
+	"error.invalidOTelEndpoint":                      "invalid -otel-endpoint",
+	"error.invalidOutput":                            "invalid -output",
+	"error.invalidPassword":                          "invalid AMT password, AMT rejects it",
+	"error.invalidTransport":                         "invalid -transport",
+	"error.invalidVault":                             "invalid -vault",
+	"error.langValue":                                "-lang needs a language",
+	"error.maintenance.taskOrAll":                    "provide either a 'task' list or 'all', but not both",
+	"error.maintenance.taskTwice":                    "maintenance task given twice: %s",
+	"error.maintenance.unsupportedTask":              "unsupported maintenance task: %s",
+	"error.negativeMaxSkew":                          "-maxSkew must not be negative",
+	"error.nonInteractiveValue":                      "invalid value %q for -nonInteractive",
+	"error.outputValue":                              "-output needs a file, stdout, syslog or eventlog",
+	"error.passwordOrPasswordFile":                   "provide either -password or -passwordFile, but not both",
+	"error.power.biosOrPXE":                          "provide either 'bootToBIOS' or 'bootToPXE', but not both",
+	"error.power.bootOptionsWithOff":                 "boot options apply to the next boot and cannot be used with 'off'",
+	"error.profileRequired":                          "-profile flag is required and cannot be empty",
+	"error.readCACert":                               "unable to read CA certificate",
+	"error.readCert":                                 "unable to read certificate",
+	"error.readMPSCert":                              "unable to read MPS root certificate",
+	"error.readSecretsFile":                          "error reading secrets file",
+	"error.redirection.enableOrDisable":              "-enable or -disable is required",
+	"error.redirection.invalidDisable":               "invalid -disable",
+	"error.redirection.invalidEnable":                "invalid -enable",
+	"error.remote.amtPort":                           "-amtPort must be between 1 and 65535",
+	"error.remote.caCertWithSkipCheck":               "-amtCACert cannot be used with -skipAMTCertCheck",
+	"error.remote.emptyUser":                         "-user must not be empty",
+	"error.remote.hostIsURL":                         "-host %s must be a host name or IP address, not a URL",
+	"error.remote.hostRequired":                      "-amtPort, -tls, -amtCACert and -skipAMTCertCheck require -host",
+	"error.remote.noPEMCertificate":                  "-amtCACert %s holds no PEM certificate",
+	"error.remote.readCACert":                        "unable to read -amtCACert",
+	"error.remote.tlsRequired":                       "-amtCACert and -skipAMTCertCheck require -tls",
+	"error.status.negative":                          "-maxSkew and -certWarnDays must not be negative",
+	"error.syncclock.localOrNTP":                     "-maxSkew and -tz need -local or -ntp",
+	"error.syncclock.unknownTZ":                      "unknown -tz %s",
+	"error.syncclock.urlOrLocal":                     "provide either a 'url' or 'local', but not both",
+	"error.syncclock.urlOrNTP":                       "provide either a 'url' or an 'ntp' server, but not both",
+	"error.syncdeviceinfo.continuousWithShow":        "-continuous can not be used with -show or -dryrun",
+	"error.syncdns.url":                              "syncdns runs locally and does not use the 'url' flag",
+	"error.syncip.preferSubnetWithStaticIP":          "-preferSubnet selects the host address and cannot be used with -staticip",
+	"error.syncip.prefixlenWithoutIPv6":              "-prefixlen requires -ipv6addr",
+	"error.syncwifi.url":                             "syncwifi runs locally and does not use the 'url' flag",
+	"error.tls.caCertWithoutMutual":                  "'caCert' and 'trustedCN' are only valid with a mutual authentication mode",
+	"error.tls.csrOrCert":                            "provide either a 'csr' or a 'cert', but not both",
+	"error.tls.mutualWithoutCACert":                  "mutual authentication requires a 'caCert'",
+	"error.transportValue":                           "-transport needs lms, lms-tls, lme or auto",
+	"error.unexpectedArgument":                       "unexpected argument %s",
+	"error.urlOrLocal":                               "provide either a 'url' or a 'local', but not both",
+	"error.urlRequired":                              "-u flag is required and cannot be empty",
+	"error.wifi.missingConfiguration":                "missing wifi configuration",
+	"error.wired8021x.disableWithConfig":             "-disable does not take a 802.1x configuration",
+	"error.wired8021x.profileRequired":               "provide -ieee8021xProfileName with -config, or -username and the certificates",
+	"error.wired8021x.pxeTimeout":                    "-pxeTimeout must be between 0 and 86400 seconds",
+	"error.wsman.invalidEnvelope":                    "invalid WS-MAN envelope",
+	"error.wsman.readEnvelope":                       "unable to read the WS-MAN envelope",
+	"error.wsman.xmlRequired":                        "-xml is required",
+}
@@ -0,0 +1,649 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package i18n
+
+var es = map[string]string{
+	"usage.title":          "Remote Provisioning Client (RPC) - se usa para la activación, desactivación, mantenimiento y estado de AMT",
+	"usage.usage":          "Uso",
+	"usage.commands":       "Comandos disponibles",
+	"usage.example":        "Ejemplo",
+	"usage.moreInfo":       "Ejecute '%s' para obtener más información sobre un comando.",
+	"usage.language":       "Seleccione el idioma de la salida con -lang en, es o de, o con la variable de entorno RPC_LANG.",
+	"usage.nonInteractive": "Con -nonInteractive o RPC_NON_INTERACTIVE=true nunca se pregunta, una contraseña o confirmación que falta hace fallar el comando.",
+	"usage.transport":      "Con -transport lms o lme, o con RPC_TRANSPORT, se accede al AMT local solo a través de LMS o del controlador LME de rpc, auto usa LMS cuando está en ejecución. lms-tls es LMS en el puerto 16993, una vez habilitado TLS en la interfaz local.",
+	"usage.output":         "Con -output o la variable de entorno RPC_OUTPUT se copia el documento de resultado a un archivo, a syslog o a eventlog.",
+	"usage.options":        "Opciones",
+	"usage.returnCodes":    "Códigos de retorno",
+
+	"usage.cmd.activate":    "Activa este dispositivo con el perfil indicado",
+	"usage.cmd.agent":       "Se ejecuta como proceso de larga duración y realiza tareas de mantenimiento periódicamente. Se requiere la contraseña de AMT",
+	"usage.cmd.amtinfo":     "Muestra información sobre el estado y la configuración de AMT",
+	"usage.cmd.apply":       "Lleva este dispositivo a la activación, el nombre de host y la configuración WiFi, TLS y CIRA de un documento JSON, mostrando primero el plan de cambios",
+	"usage.cmd.boot":        "Establece el dispositivo desde el que arranca este dispositivo en el próximo arranque, y lo reinicia con -reset. Se requiere la contraseña de AMT",
+	"usage.cmd.bulk":        "Ejecuta amtinfo, power, configure o wsman en los dispositivos AMT remotos de una lista CSV o JSON e informa del resultado de cada uno",
+	"usage.cmd.checkcert":   "Comprueba la cadena del certificado de aprovisionamiento con los hashes de los certificados raíz de confianza de AMT",
+	"usage.cmd.configure":   "Configuración local de una función en este dispositivo. Se requiere la contraseña de AMT",
+	"usage.cmd.deactivate":  "Desactiva este dispositivo. Se requiere la contraseña de AMT",
+	"usage.cmd.diag":        "Reúne amtinfo, el registro de eventos de AMT, los registros de rpc, la configuración de red del SO y el estado de LMS en un zip con los secretos ocultos, para los tickets de soporte",
+	"usage.cmd.help":        "Muestra el uso, las opciones y los códigos de retorno de un comando",
+	"usage.cmd.maintenance": "Ejecuta una tarea de mantenimiento en el dispositivo. Se requiere la contraseña de AMT",
+	"usage.cmd.power":       "Enciende, apaga, reinicia o apaga y enciende este dispositivo mediante AMT. Se requiere la contraseña de AMT",
+	"usage.cmd.selftest":    "Comprueba el controlador MEI, una consulta PTHI, LMS, las rutas en las que escribe rpc y el ejecutable de rpc para el diagnóstico de soporte",
+	"usage.cmd.service":     "Instala, desinstala, inicia o detiene rpc como servicio que ejecuta el agente",
+	"usage.cmd.sol":         "Abre una sesión Serial-over-LAN con la consola del BIOS o del sistema operativo de este dispositivo a través de AMT, Ctrl+] la termina. Se requiere la contraseña de AMT",
+	"usage.cmd.status":      "Comprueba el modo de control, CIRA, TLS, el reloj, el nombre de host y la caducidad de los certificados e indica PASS, WARN o FAIL",
+	"usage.cmd.returncodes": "Enumera los códigos de salida de RPC con sus nombres y descripciones",
+	"usage.cmd.version":     "Muestra la versión actual de RPC y la versión del protocolo RPC",
+	"usage.cmd.wsman":       "Envía un sobre WS-MAN a AMT tal cual y muestra la respuesta. Se requiere la contraseña de AMT",
+
+	"usage.maintenance.commands":             "Comandos de mantenimiento disponibles",
+	"usage.maintenance.changepassword":       "Cambia la contraseña de AMT. De forma predeterminada se genera una contraseña aleatoria. Indique -static para establecerla manualmente. Se requiere la contraseña de AMT",
+	"usage.maintenance.changepassword.local": "Indique -generate para generar la contraseña localmente, sin interacción con la nube",
+	"usage.maintenance.syncdeviceinfo":       "Sincroniza la información del dispositivo. Se requiere la contraseña de AMT",
+	"usage.maintenance.syncdeviceinfo.show":  "Especifique -show para mostrar la información del dispositivo sin enviarla y -exclude para omitir campos",
+	"usage.maintenance.continuous":           "Especifique -continuous para seguir en ejecución y enviar la información del dispositivo de nuevo cada -interval",
+	"usage.maintenance.syncclock":            "Sincroniza el reloj del sistema operativo con AMT. Se requiere la contraseña de AMT",
+	"usage.maintenance.syncclock.ntp":        "Indique -ntp para sincronizar AMT con un servidor NTP, sin interacción con la nube",
+	"usage.maintenance.syncclock.local":      "Use -local para sincronizar AMT con el reloj del sistema operativo del host, -maxSkew deja sin cambios un reloj dentro de la tolerancia",
+	"usage.maintenance.synchostname":         "Sincroniza el nombre de host del cliente con AMT. Se requiere la contraseña de AMT",
+	"usage.maintenance.syncip":               "Sincroniza la configuración IP del sistema operativo con la configuración de red de AMT. Se requiere la contraseña de AMT",
+	"usage.maintenance.syncip.static":        "Si no se indica una IP estática, se usan la dirección IP y la máscara de red del sistema operativo",
+	"usage.maintenance.syncip.ipv6":          "Indique -ipv6addr y -prefixlen para establecer también una dirección IPv6; si existe, se usa una dirección IPv6 global del sistema operativo",
+	"usage.maintenance.syncip.interface":     "Indique -ifname o -mac para leer la configuración de una interfaz agregada o en puente del sistema operativo, también para synchostname",
+	"usage.maintenance.syncip.preferSubnet":  "Indique -preferSubnet para elegir entre varias direcciones del host, p. ej. -preferSubnet 192.168.1.0/24",
+	"usage.maintenance.syncdns":              "Sincroniza el sufijo DNS y los servidores DNS del sistema operativo con AMT, sin interacción con la nube. Se requiere la contraseña de AMT",
+	"usage.maintenance.syncdns.host":         "Si no se indican, se usan el sufijo DNS y los servidores DNS del sistema operativo",
+	"usage.maintenance.syncwifi":             "Reemplaza los perfiles wifi de AMT por los perfiles WPA/WPA2 personales del sistema operativo, sin interacción con la nube. Se requiere la contraseña de AMT",
+	"usage.maintenance.tasks":                "Ejecute varias tareas con una sola conexión al servidor mediante -task, o todas con -all:",
+	"usage.power.commands":                   "Comandos de energía disponibles",
+	"usage.power.on":                         "Enciende el dispositivo. Se requiere la contraseña de AMT",
+	"usage.power.off":                        "Apaga el dispositivo sin cerrar el sistema operativo. Se requiere la contraseña de AMT",
+	"usage.power.reset":                      "Reinicia el dispositivo. Se requiere la contraseña de AMT",
+	"usage.power.cycle":                      "Apaga el dispositivo y lo vuelve a encender. Se requiere la contraseña de AMT",
+	"usage.power.local":                      "Las acciones de energía se envían directamente a AMT, sin interacción con la nube.",
+	"usage.power.nextBoot":                   "-bootToBIOS y -bootToPXE solo se aplican al siguiente arranque y no se pueden usar con off.",
+	"usage.service.commands":                 "Comandos de servicio disponibles",
+	"usage.service.install":                  "Instala rpc como servicio que ejecuta el agente. Acepta las opciones del agente. Se requiere la contraseña de AMT",
+	"usage.service.uninstall":                "Detiene y elimina el servicio",
+	"usage.service.start":                    "Inicia el servicio",
+	"usage.service.stop":                     "Detiene el servicio",
+	"usage.service.platform":                 "El servicio es un servicio de Windows o una unidad de systemd en Linux.",
+	"usage.configure.commands":               "Comandos de configuración disponibles",
+	"usage.configure.addwifisettings":        "Agrega o modifica la configuración wifi en AMT. Se requiere la contraseña de AMT. Todos los ajustes deben indicarse en un config.yml, un config.json o con opciones de línea de comandos. Este comando se ejecuta sin interacción con la nube.",
+	"usage.configure.enablewifiport":         "Habilita el puerto wifi y la sincronización local de perfiles en AMT, o los deshabilita con -disable. Se requiere la contraseña de AMT.",
+	"usage.configure.tlssettings":            "Configura TLS en AMT. Se requiere la contraseña de AMT. Ejecútelo primero para generar un par de claves en AMT y una CSR, y después con el certificado firmado para habilitar TLS.",
+	"usage.configure.cira":                   "Configura CIRA en AMT: el servidor MPS y su certificado raíz, la detección del entorno y las directivas de acceso remoto. Se requiere la contraseña de AMT.",
+	"usage.configure.mps":                    "Configura CIRA como cira, con un MPS secundario al que AMT recurre cuando no alcanza el primario. Una configuración que ya coincide se conserva. Se requiere la contraseña de AMT.",
+	"usage.configure.wired8021x":             "Configura IEEE 802.1x en la interfaz cableada de AMT con EAP-TLS o PEAPv0/EAP-MSCHAPv2 (authenticationProtocol 0 o 2). Se requiere la contraseña de AMT.",
+	"usage.configure.alarmclock":             "Muestra las alarmas de encendido de AMT, añade una con -add y -start o elimina una con -delete. Se requiere la contraseña de AMT.",
+	"usage.configure.redirection":            "Habilita o deshabilita la redirección KVM, SOL e IDE-R en AMT con -enable y -disable. Se requiere la contraseña de AMT.",
+	"usage.configure.dnssuffix":              "Establece el sufijo DNS de PKI que AMT compara con el certificado de aprovisionamiento. Solo se acepta antes de la activación, no se requiere la contraseña de AMT.",
+	"usage.configure.amtfeatures":            "Habilita o deshabilita AMT desde el sistema operativo cuando la BIOS lo permite, o muestra si lo permite. No se requiere la contraseña de AMT.",
+	"usage.configure.certhash":               "Muestra los hashes de los certificados raíz de confianza de AMT, añade uno con -add o elimina uno con -delete, si el firmware lo permite. Se requiere la contraseña de AMT.",
+
+	"info.version":                "Versión",
+	"info.buildNumber":            "Compilación",
+	"info.sku":                    "SKU",
+	"info.features":               "Funciones",
+	"info.skuClass":               "Clase de SKU",
+	"info.uuid":                   "UUID",
+	"info.controlMode":            "Modo de control",
+	"info.operationalState":       "Estado operativo",
+	"info.enabled":                "habilitado",
+	"info.disabled":               "deshabilitado",
+	"info.disabledInMEBx":         "deshabilitado en MEBx",
+	"info.provisioningState":      "Estado de provisión",
+	"info.provisioningMode":       "Modo de provisión",
+	"info.dnsSuffix":              "Sufijo DNS de PKI",
+	"info.dnsSuffixOS":            "Sufijo DNS (SO)",
+	"info.hostnameOS":             "Nombre de host (SO)",
+	"info.manufacturer":           "Fabricante",
+	"info.model":                  "Modelo",
+	"info.serialNumber":           "Número de serie",
+	"info.assetTag":               "Etiqueta de activo",
+	"info.cpu":                    "CPU",
+	"info.memory":                 "Memoria",
+	"info.biosVendor":             "Proveedor del BIOS",
+	"info.biosVersion":            "Versión del BIOS",
+	"info.biosReleaseDate":        "Fecha del BIOS",
+	"info.meFirmwareVersion":      "Versión firmware ME",
+	"info.meFirmwareBuild":        "Compilación firmware ME",
+	"info.meRecoveryVersion":      "Versión recuperación ME",
+	"info.meRecoveryBuild":        "Compilación recup. ME",
+	"info.meFirmwareSVN":          "SVN firmware ME",
+	"info.notReported":            "no informado",
+	"info.securityAdvisories":     "Avisos de seguridad",
+	"info.advisoryTable":          "tabla del",
+	"info.affected":               "afectado",
+	"info.notAffected":            "no afectado",
+	"info.notListed":              "no listado, el firmware es más reciente que la tabla",
+	"info.fixedIn":                "corregido en",
+	"info.advisoryUnknown":        "desconocido, no se pudo leer la versión del firmware",
+	"info.osName":                 "Nombre del SO",
+	"info.osVersion":              "Versión del SO",
+	"info.osBuild":                "Compilación del SO",
+	"info.architecture":           "Arquitectura",
+	"info.rpcVersion":             "Versión de RPC",
+	"info.protocolVersion":        "Versión protocolo RPC",
+	"info.transport":              "Transporte",
+	"info.wsmanAddress":           "Dirección WS-MAN",
+	"info.reachable":              "accesible",
+	"info.notReachable":           "no accesible",
+	"info.redirectionListener":    "Escucha de redirección",
+	"info.kvm":                    "KVM",
+	"info.sol":                    "SOL",
+	"info.ider":                   "IDE-R",
+	"info.changeFromOS":           "Cambio desde el SO",
+	"info.allowed":                "permitido",
+	"info.notAllowed":             "no permitido",
+	"info.remoteConfiguration":    "Configuración remota",
+	"info.provisioningTLSMode":    "Modo TLS de aprovisionamiento",
+	"info.activeCertHashes":       "Hashes raíz activos",
+	"info.ccmActivation":          "Activación CCM",
+	"info.acmActivation":          "Activación ACM",
+	"info.rasNetwork":             "Red RAS",
+	"info.rasRemoteStatus":        "Estado remoto RAS",
+	"info.rasTrigger":             "Activador RAS",
+	"info.rasMPSHostname":         "Host MPS RAS",
+	"info.dhcpEnabled":            "DHCP habilitado",
+	"info.dhcpMode":               "Modo DHCP",
+	"info.linkStatus":             "Estado del enlace",
+	"info.ipAddress":              "Dirección IP",
+	"info.macAddress":             "Dirección MAC",
+	"info.ipv6Address":            "Dirección IPv6",
+	"info.wiredAdapter":           "---Adaptador cableado---",
+	"info.wirelessAdapter":        "---Adaptador inalámbrico---",
+	"info.certHashes":             "---Hashes de certificados---",
+	"info.noCertHashes":           "---No se encontraron hashes de certificados---",
+	"info.noDeprecatedCertHashes": "---No se encontraron hashes de certificados obsoletos---",
+	"info.publicKeyCerts":         "---Certificados de clave pública---",
+	"info.noPublicKeyCerts":       "---No se encontraron certificados de clave pública---",
+	"info.auditLog":               "---Registro de auditoría (%d de %d registros)---",
+	"info.eventLog":               "---Registro de eventos (%d de %d registros)---",
+	"info.eventLogCleared":        "Registro de eventos borrado",
+
+	"error.activate.alreadyActivated":                "el dispositivo ya está %s",
+	"error.activate.alreadyActivatedReprovision":     "el dispositivo ya está %s, use -reprovision para activarlo de nuevo",
+	"error.activate.alreadyCCM":                      "el dispositivo ya está activado en modo de control de cliente, use -upgrade para pasarlo al modo de control de administrador o -reprovision para activarlo de nuevo",
+	"error.activate.cancelled":                       "activación cancelada",
+	"error.activate.ccmOrACM":                        "debe indicar -ccm o -acm, pero no ambos",
+	"error.activate.fipsWithoutGenerate":             "-fips requiere -generatePassword",
+	"error.activate.generateOnlyLocalCCM":            "-generatePassword solo se admite con la activación CCM local, sin -upgrade ni -reprovision",
+	"error.activate.generateWithPassword":            "-generatePassword no se puede usar con -password ni AMT_PASSWORD",
+	"error.activate.generateWithoutStore":            "-generatePassword requiere -out, -keyring o -vault",
+	"error.activate.interactiveOrNonInteractive":     "indique -interactive o -nonInteractive, pero no ambos",
+	"error.activate.interactiveWithJSON":             "-interactive no se puede usar con -json ni -yaml",
+	"error.activate.invalidAMTPassword":              "-amtPassword no válido, AMT lo rechaza",
+	"error.activate.invalidUUID":                     "el uuid indicado no tiene un formato de uuid válido",
+	"error.activate.mebxOnlyLocalACM":                "-mebxPassword solo se admite con la activación ACM local",
+	"error.activate.missingField":                    "Falta el valor del campo: %s",
+	"error.activate.precheckFailed":                  "falló la comprobación previa a la activación: %s",
+	"error.activate.preflightFailed":                 "falló la comprobación previa: %s",
+	"error.activate.resumeOnlyRPS":                   "-resume y -sessionFile solo se admiten con la activación por RPS",
+	"error.activate.rpsAPIOnlyRPS":                   "-rpsAPI solo se admite con la activación por RPS",
+	"error.activate.rpsAPIURL":                       "-rpsAPI debe ser una URL http:// o https://",
+	"error.activate.storeWithoutGenerate":            "-out, -keyring y -vault requieren -generatePassword",
+	"error.activate.upgradeOnlyLocal":                "-upgrade y -reprovision solo se admiten con la activación local",
+	"error.activate.upgradeOrReprovision":            "indique -upgrade o -reprovision, pero no ambos",
+	"error.activate.upgradeWithoutACM":               "-upgrade requiere -acm",
+	"error.activate.uuidLocal":                       "-uuid no se puede usar en la activación local",
+	"error.addWithDelete":                            "-add no se puede usar con -delete",
+	"error.agent.readSecretsFile":                    "no se puede leer -secretsFile",
+	"error.agent.unsupportedTask":                    "tarea del agente no admitida: %s",
+	"error.alarmclock.addRequired":                   "-start, -interval y -deleteOnCompletion requieren -add",
+	"error.alarmclock.interval":                      "-interval debe ser un número entero de minutos y no negativo",
+	"error.alarmclock.invalidStart":                  "-start no válido",
+	"error.alarmclock.startRequired":                 "-add requiere -start",
+	"error.amtfeatures.invalidAMT":                   "-amt %s no válido, use %s o %s",
+	"error.apply.acmCertRequired":                    "la activación en modo acm necesita provisioningCert y provisioningCertPwd",
+	"error.apply.activationMode":                     "el modo de activación debe ser ccm o acm, no %q",
+	"error.apply.ciraNegativePeriodic":               "periodicInterval de cira no debe ser negativo",
+	"error.apply.ciraPort":                           "mpsPort de cira debe estar entre 1 y 65535",
+	"error.apply.ciraRequired":                       "se requieren mpsAddress, mpsUser, mpsPassword y mpsCert de cira",
+	"error.apply.documentRequired":                   "se requiere -f",
+	"error.apply.emptyDocument":                      "el documento no describe ninguno de activation, hostname, wifiConfigs, tls o cira",
+	"error.apply.hostnameTemplate":                   "la plantilla del nombre de host debe contener %s una vez",
+	"error.apply.invalidDocument":                    "documento no válido",
+	"error.apply.invalidSecondaryMPS":                "MPS secundario de cira no válido",
+	"error.apply.mebxOnlyACM":                        "mebxPassword solo se admite con la activación acm",
+	"error.apply.readDocument":                       "no se puede leer el documento",
+	"error.apply.tlsCACertWithoutMutual":             "caCert y trustedCN de tls solo son válidos con un modo de autenticación mutua",
+	"error.apply.tlsCertRequired":                    "tls necesita el certificado firmado en cert, cree primero la CSR con configure tlssettings",
+	"error.apply.tlsMutualWithoutCACert":             "la autenticación mutua de tls requiere un caCert",
+	"error.boot.once":                                "AMT aplica el origen de arranque solo al próximo arranque, -once=false no se admite",
+	"error.boot.source":                              "-source debe ser uno de %s",
+	"error.boot.usb":                                 "-source usb no se admite, AMT arranca desde USB solo mediante la redirección USB de una imagen de disco, que rpc no ofrece",
+	"error.bulk.command":                             "bulk ejecuta amtinfo, power, configure o wsman, no %s",
+	"error.bulk.fileAndCommand":                      "se requieren -file y -command",
+	"error.bulk.flagFromDeviceList":                  "-%s se toma de la lista de dispositivos, no de -command",
+	"error.bulk.invalidCommand":                      "-command no válido para %s",
+	"error.bulk.noDevices":                           "%s no contiene ningún dispositivo",
+	"error.bulk.readDeviceList":                      "no se puede leer la lista de dispositivos",
+	"error.bulk.workers":                             "-workers debe ser al menos 1",
+	"error.bulk.xmlFromStdin":                        "bulk no puede leer el sobre WS-MAN de stdin, use un archivo con -xml",
+	"error.certhash.aliasRequired":                   "-add de un hash o de un certificado sin nombre común requiere -alias",
+	"error.certhash.aliasWithoutAdd":                 "-alias requiere -add",
+	"error.certhash.invalidAdd":                      "-add no válido",
+	"error.changepassword.generateRequired":          "-length, -nosymbols, -fips, -out, -keyring y -vault requieren -generate",
+	"error.changepassword.generateWithStatic":        "-generate establece la contraseña localmente y no se puede combinar con -static ni -u",
+	"error.changepassword.invalidStatic":             "contraseña -static no válida, AMT la rechaza",
+	"error.changepassword.length":                    "-length debe estar entre %d y %d",
+	"error.changepassword.oneStore":                  "indique solo uno de -out, -keyring o -vault",
+	"error.checkcert.certRequired":                   "se requiere -provisioningCert o un -config con el certificado de aprovisionamiento",
+	"error.cira.mpsport":                             "-mpsport debe estar entre 1 y 65535",
+	"error.cira.negativePeriodic":                    "-periodic no debe ser negativo",
+	"error.cira.required":                            "se requieren -mpsaddress, -mpsuser, -mpspassword y -mpscert",
+	"error.configPasswordMismatch":                   "la contraseña no coincide con la del archivo de configuración",
+	"error.deactivate.urlOrPartial":                  "indique 'url' o 'partial', pero no ambos",
+	"error.deactivate.urlOrWipe":                     "indique 'url' o 'wipe', pero no ambos",
+	"error.deactivate.wipeOrPartial":                 "indique 'wipe' o 'partial', pero no ambos",
+	"error.diag.bundleRequired":                      "diag requiere -bundle",
+	"error.diag.notDirectory":                        "-dir %s no es un directorio",
+	"error.dnssuffix.invalidValue":                   "-value no válido",
+	"error.dnssuffix.tooLong":                        "-value tiene más de %d caracteres",
+	"error.dnssuffix.valueRequired":                  "se requiere -value",
+	"error.enabledAndDisabled":                       "%s no se puede habilitar y deshabilitar a la vez",
+	"error.enablewifiport.linkPreference":            "-linkPreference debe ser %s o %s",
+	"error.enablewifiport.linkPreferenceTimeout":     "-linkPreferenceTimeout debe estar entre 1 y 65535 segundos",
+	"error.enablewifiport.linkPreferenceWithDisable": "-linkPreference no se puede usar con -disable",
+	"error.fips":                                     "-fips",
+	"error.help.unknownCommand":                      "no hay ayuda para %s",
+	"error.info.advisoriesWithoutSeccheck":           "-advisories requiere -seccheck",
+	"error.info.clearWithCount":                      "-clear borra todos los registros y no se puede usar con -count ni -offset",
+	"error.info.clearWithoutEventlog":                "-clear requiere -eventlog",
+	"error.info.negativeCacheTTL":                    "-cacheTTL no debe ser negativo",
+	"error.info.negativeCount":                       "-count y -offset no deben ser negativos",
+	"error.intervalTooShort":                         "-interval debe ser de al menos un minuto",
+	"error.invalidConfigJSON":                        "-configJson no válido",
+	"error.invalidMEBxPassword":                      "contraseña de MEBx no válida",
+	"error.invalidOTelEndpoint":                      "-otel-endpoint no válido",
+	"error.invalidOutput":                            "-output no válido",
+	"error.invalidPassword":                          "contraseña de AMT no válida, AMT la rechaza",
+	"error.invalidTransport":                         "-transport no válido",
+	"error.invalidVault":                             "-vault no válido",
+	"error.langValue":                                "-lang necesita un idioma",
+	"error.maintenance.taskOrAll":                    "indique una lista 'task' o 'all', pero no ambos",
+	"error.maintenance.taskTwice":                    "tarea de mantenimiento indicada dos veces: %s",
+	"error.maintenance.unsupportedTask":              "tarea de mantenimiento no admitida: %s",
+	"error.negativeMaxSkew":                          "-maxSkew no debe ser negativo",
+	"error.nonInteractiveValue":                      "valor %q no válido para -nonInteractive",
+	"error.outputValue":                              "-output necesita un archivo, stdout, syslog o eventlog",
+	"error.passwordOrPasswordFile":                   "indique -password o -passwordFile, pero no ambos",
+	"error.power.biosOrPXE":                          "indique 'bootToBIOS' o 'bootToPXE', pero no ambos",
+	"error.power.bootOptionsWithOff":                 "las opciones de arranque se aplican al próximo arranque y no se pueden usar con 'off'",
+	"error.profileRequired":                          "la opción -profile es obligatoria y no puede estar vacía",
+	"error.readCACert":                               "no se puede leer el certificado de CA",
+	"error.readCert":                                 "no se puede leer el certificado",
+	"error.readMPSCert":                              "no se puede leer el certificado raíz del MPS",
+	"error.readSecretsFile":                          "error al leer el archivo de secretos",
+	"error.redirection.enableOrDisable":              "se requiere -enable o -disable",
+	"error.redirection.invalidDisable":               "-disable no válido",
+	"error.redirection.invalidEnable":                "-enable no válido",
+	"error.remote.amtPort":                           "-amtPort debe estar entre 1 y 65535",
+	"error.remote.caCertWithSkipCheck":               "-amtCACert no se puede usar con -skipAMTCertCheck",
+	"error.remote.emptyUser":                         "-user no debe estar vacío",
+	"error.remote.hostIsURL":                         "-host %s debe ser un nombre de host o una dirección IP, no una URL",
+	"error.remote.hostRequired":                      "-amtPort, -tls, -amtCACert y -skipAMTCertCheck requieren -host",
+	"error.remote.noPEMCertificate":                  "-amtCACert %s no contiene ningún certificado PEM",
+	"error.remote.readCACert":                        "no se puede leer -amtCACert",
+	"error.remote.tlsRequired":                       "-amtCACert y -skipAMTCertCheck requieren -tls",
+	"error.status.negative":                          "-maxSkew y -certWarnDays no deben ser negativos",
+	"error.syncclock.localOrNTP":                     "-maxSkew y -tz necesitan -local o -ntp",
+	"error.syncclock.unknownTZ":                      "-tz %s desconocido",
+	"error.syncclock.urlOrLocal":                     "indique 'url' o 'local', pero no ambos",
+	"error.syncclock.urlOrNTP":                       "indique 'url' o un servidor 'ntp', pero no ambos",
+	"error.syncdeviceinfo.continuousWithShow":        "-continuous no se puede usar con -show ni -dryrun",
+	"error.syncdns.url":                              "syncdns se ejecuta localmente y no usa la opción 'url'",
+	"error.syncip.preferSubnetWithStaticIP":          "-preferSubnet selecciona la dirección del host y no se puede usar con -staticip",
+	"error.syncip.prefixlenWithoutIPv6":              "-prefixlen requiere -ipv6addr",
+	"error.syncwifi.url":                             "syncwifi se ejecuta localmente y no usa la opción 'url'",
+	"error.tls.caCertWithoutMutual":                  "'caCert' y 'trustedCN' solo son válidos con un modo de autenticación mutua",
+	"error.tls.csrOrCert":                            "indique 'csr' o 'cert', pero no ambos",
+	"error.tls.mutualWithoutCACert":                  "la autenticación mutua requiere un 'caCert'",
+	"error.transportValue":                           "-transport necesita lms, lms-tls, lme o auto",
+	"error.unexpectedArgument":                       "argumento inesperado %s",
+	"error.urlOrLocal":                               "indique 'url' o 'local', pero no ambos",
+	"error.urlRequired":                              "la opción -u es obligatoria y no puede estar vacía",
+	"error.wifi.missingConfiguration":                "falta la configuración wifi",
+	"error.wired8021x.disableWithConfig":             "-disable no admite una configuración 802.1x",
+	"error.wired8021x.profileRequired":               "indique -ieee8021xProfileName con -config, o -username y los certificados",
+	"error.wired8021x.pxeTimeout":                    "-pxeTimeout debe estar entre 0 y 86400 segundos",
+	"error.wsman.invalidEnvelope":                    "sobre WS-MAN no válido",
+	"error.wsman.readEnvelope":                       "no se puede leer el sobre WS-MAN",
+	"error.wsman.xmlRequired":                        "se requiere -xml",
+
+	"returncode.Success":                            "el comando se completó correctamente",
+	"returncode.IncorrectPermissions":               "no se ejecuta con privilegios de administrador o root",
+	"returncode.HECIDriverNotDetected":              "no se detectó el controlador MEI/HECI",
+	"returncode.AmtNotDetected":                     "no se detectó Intel AMT en este dispositivo",
+	"returncode.AmtNotReady":                        "Intel AMT no está listo",
+	"returncode.DryRunCompleted":                    "el comando se comprobó con -dryrun, no se cambió nada",
+	"returncode.GenericFailure":                     "el comando falló sin un código de retorno más específico",
+	"returncode.UnsupportedPlatform":                "rpc no admite el controlador MEI en este sistema operativo o arquitectura de CPU",
+	"returncode.MEITimeout":                         "el controlador MEI no respondió dentro de -timeout, o el comando se canceló",
+	"returncode.NotAdministrator":                   "rpc necesita privilegios de administrador o root para abrir el dispositivo MEI",
+	"returncode.MEIDriverMissing":                   "no se encontró ningún dispositivo MEI, el controlador MEI no está instalado o Intel ME está deshabilitado",
+	"returncode.CancelledByUser":                    "rpc se interrumpió con Ctrl+C o SIGTERM antes de completar el comando",
+	"returncode.MissingOrIncorrectURL":              "falta la URL del servidor o no es válida",
+	"returncode.MissingOrIncorrectProfile":          "falta el perfil o no es válido",
+	"returncode.ServerCerificateVerificationFailed": "no se pudo verificar el certificado del servidor",
+	"returncode.MissingOrIncorrectPassword":         "falta la contraseña de AMT o es incorrecta",
+	"returncode.MissingDNSSuffix":                   "falta el sufijo DNS",
+	"returncode.MissingHostname":                    "falta el nombre de host",
+	"returncode.MissingProxyAddressAndPort":         "falta la dirección o el puerto del proxy, o no son válidos",
+	"returncode.MissingOrIncorrectStaticIP":         "falta la dirección IP estática o no es válida",
+	"returncode.IncorrectCommandLineParameters":     "los parámetros de la línea de comandos no son válidos",
+	"returncode.MissingOrIncorrectNetworkMask":      "falta la máscara de red o no es válida",
+	"returncode.MissingOrIncorrectGateway":          "falta la puerta de enlace o no es válida",
+	"returncode.MissingOrIncorrectPrimaryDNS":       "falta el DNS principal o no es válido",
+	"returncode.MissingOrIncorrectSecondaryDNS":     "falta el DNS secundario o no es válido",
+	"returncode.InvalidParameterCombination":        "la combinación de parámetros de la línea de comandos no está permitida",
+	"returncode.FailedReadingConfiguration":         "no se pudo leer la configuración",
+	"returncode.MissingOrInvalidConfiguration":      "falta la configuración o no es válida",
+	"returncode.InvalidUserInput":                   "la entrada del usuario no es válida",
+	"returncode.InvalidUUID":                        "el UUID no es válido",
+	"returncode.MissingOrIncorrectMEBxPassword":     "falta la contraseña de MEBx o no cumple las reglas de complejidad",
+	"returncode.MissingOrIncorrectMQTTBroker":       "falta la dirección del broker MQTT o no es válida",
+	"returncode.MissingOrIncorrectCACert":           "falta el archivo -cacert o los hashes -pin-sha256 del servidor, o no son válidos",
+	"returncode.DNSSuffixMismatch":                  "el sufijo DNS no coincide con el dominio del certificado de aprovisionamiento",
+	"returncode.InvalidProvisioningCert":            "el certificado de aprovisionamiento no se puede descifrar o su cadena no se verifica",
+	"returncode.InputRequired":                      "se requiere una confirmación u otra entrada, que -nonInteractive no solicita",
+	"returncode.RPSAuthenticationFailed":            "falló la autenticación con el servidor",
+	"returncode.AMTConnectionFailed":                "falló la conexión con AMT",
+	"returncode.OSNetworkInterfacesLookupFailed":    "no se pudieron leer las interfaces de red del sistema operativo",
+	"returncode.ControlAPIFailed":                   "el agente no pudo ofrecer la API de control en la dirección de -control o leer su token",
+	"returncode.AMTAuthenticationFailed":            "falló la autenticación con AMT",
+	"returncode.WSMANMessageError":                  "falló un mensaje WSMAN",
+	"returncode.ActivationFailed":                   "falló la activación",
+	"returncode.NetworkConfigurationFailed":         "falló la configuración de red",
+	"returncode.CIRAConfigurationFailed":            "falló la configuración de CIRA",
+	"returncode.TLSConfigurationFailed":             "falló la configuración de TLS",
+	"returncode.WiFiConfigurationFailed":            "falló la configuración wifi",
+	"returncode.AMTFeaturesConfigurationFailed":     "falló la configuración de las funciones de AMT",
+	"returncode.Ieee8021xConfigurationFailed":       "falló la configuración de ieee8021x",
+	"returncode.UnableToDeactivate":                 "no se pudo desactivar el dispositivo",
+	"returncode.DeactivationFailed":                 "falló la desactivación",
+	"returncode.UnableToActivate":                   "no se pudo activar el dispositivo",
+	"returncode.WifiConfigurationWithWarnings":      "la configuración wifi se completó con advertencias",
+	"returncode.UnmarshalMessageFailed":             "no se pudo analizar un mensaje de respuesta",
+	"returncode.DeleteWifiConfigFailed":             "no se pudo eliminar una configuración wifi existente",
+	"returncode.MissingOrIncorrectWifiProfileName":  "falta el nombre del perfil wifi o no es válido",
+	"returncode.MissingIeee8021xConfiguration":      "falta la configuración de ieee8021x",
+	"returncode.SetMEBxPasswordFailed":              "el dispositivo se activó, pero falló el establecimiento de la contraseña de MEBx",
+	"returncode.ServiceCommandFailed":               "falló la instalación, eliminación, inicio o detención del servicio rpc",
+	"returncode.DeactivationIncomplete":             "AMT aceptó la solicitud de desaprovisionamiento pero no volvió al estado de preaprovisionamiento",
+	"returncode.PowerActionFailed":                  "AMT no cambió el estado de energía ni las opciones de arranque",
+	"returncode.StorageWipeFailed":                  "el dispositivo se desactivó, pero deactivate -wipe no pudo eliminar toda la configuración",
+	"returncode.StatusCheckWarning":                 "rpc status encontró una comprobación que requiere atención (WARN)",
+	"returncode.StatusCheckFailed":                  "rpc status encontró una comprobación fallida (FAIL)",
+	"returncode.ClockSkewExceeded":                  "el reloj del host difiere de la hora del servidor o de NTP en más de -maxSkew",
+	"returncode.CertHashNotFound":                   "AMT no tiene ningún hash activo de certificado raíz de confianza para el certificado de aprovisionamiento",
+	"returncode.AlarmClockConfigurationFailed":      "AMT no listó, añadió ni eliminó las alarmas de encendido",
+	"returncode.RedirectionConfigurationFailed":     "AMT no cambió el estado de la redirección KVM, SOL o IDE-R",
+	"returncode.DNSSuffixConfigurationFailed":       "AMT no estableció el sufijo DNS de PKI, solo se establece antes de la activación",
+	"returncode.AMTStateChangeNotAllowed":           "la BIOS no permite habilitar o deshabilitar AMT desde el sistema operativo",
+	"returncode.AMTStateChangeFailed":               "AMT no cambió al estado solicitado",
+	"returncode.ActivationInterrupted":              "se perdió la conexión con RPS durante la activación del dispositivo, ejecute activate de nuevo",
+	"returncode.NoActivationToResume":               "activate -resume no encontró ninguna activación interrumpida del dispositivo",
+	"returncode.SelfTestFailed":                     "rpc selftest encontró una comprobación fallida (FAIL)",
+	"returncode.SOLSessionFailed":                   "AMT rechazó la sesión Serial-over-LAN o la sesión terminó con un error",
+	"returncode.BootConfigurationFailed":            "AMT no aceptó la configuración de arranque, el rol de la configuración de arranque o el orden de arranque",
+	"returncode.CertHashConfigurationFailed":        "AMT no listó, añadió ni eliminó los hashes de los certificados raíz de confianza",
+	"returncode.DiagBundleFailed":                   "rpc diag no pudo escribir el paquete de diagnóstico",
+	"returncode.UnsupportedBySKU":                   "el SKU del firmware, como Standard Manageability, no tiene la función o no se puede activar",
+	"returncode.PartialConfigRolledBack":            "un paso de la configuración falló y se revirtieron los cambios hechos antes",
+	"returncode.CIRAConfigurationRemoved":           "un paso de la configuración de CIRA falló, se revirtieron los cambios y la configuración de CIRA reemplazada sigue eliminada",
+	"returncode.SyncClockFailed":                    "falló la sincronización del reloj",
+	"returncode.SyncHostnameFailed":                 "falló la sincronización del nombre de host",
+	"returncode.SyncIpFailed":                       "falló la sincronización de la configuración IP",
+	"returncode.ChangePasswordFailed":               "falló el cambio de la contraseña de AMT",
+	"returncode.SyncDeviceInfoFailed":               "falló la sincronización de la información del dispositivo",
+	"returncode.SyncDNSFailed":                      "falló la sincronización del sufijo DNS o de los servidores DNS",
+	"returncode.SyncWifiFailed":                     "falló la lectura de los perfiles wifi del sistema operativo o no se puede sincronizar ninguno",
+	"returncode.AmtPtStatusCodeBase":                "AMT devolvió un código de estado PT, que se suma a este valor base",
+
+	"flag.acm":                    "Activar en el modo de control de administrador (ACM)",
+	"flag.activate.config":        "Especifique un archivo de configuración o una URL de recurso compartido smb:",
+	"flag.activate.local":         "Activar AMT localmente",
+	"flag.activate.ntp":           "Servidor NTP (host o host:puerto) con el que -precheck compara el reloj del host, se usa el servidor si no se indica",
+	"flag.activate.uuid":          "Reemplaza el uuid del dispositivo AMT para flujos de trabajo sin CIRA",
+	"flag.activate.vault":         "Escribe la contraseña generada en este almacén de secretos, p. ej. 'vault://vault.example.com:8200/secret/amt/device1'",
+	"flag.add":                    "Archivo PEM o DER del certificado raíz, o su hash SHA256, SHA1 o SHA512 en hexadecimal, que se añade a los hashes raíz de confianza",
+	"flag.addwifisettings.caCert": "Especifique el certificado de la CA",
+	"flag.addwifisettings.config": "Especifique un archivo de configuración o una URL de recurso compartido smb:",
+	"flag.advisories":             "Archivo JSON con la tabla de avisos que -seccheck usa en lugar de la tabla con la que se compiló rpc",
+	"flag.agent.interval":         "Tiempo entre ejecuciones de mantenimiento (p. ej. '1h' o '30m')",
+	"flag.alarmclock.add":         "Nombre de la alarma de encendido que se añade",
+	"flag.alarmclock.interval":    "Tiempo entre encendidos de -add en minutos enteros, p. ej. 24h, 0 enciende una vez",
+	"flag.alias":                  "Nombre del hash de -add, por defecto el nombre común del certificado",
+	"flag.all":                    "Toda la información, incluidos los hashes de certificados, el estado operativo, los modos de aprovisionamiento, el inventario de hardware, la BIOS y el sistema operativo del host",
+	"flag.amt":                    "Habilita o deshabilita AMT, la BIOS debe permitir el cambio desde el sistema operativo. Sin -amt se muestra el estado",
+	"flag.amtCACert":              "Archivo PEM con los certificados de CA que verifican el certificado TLS de -host (por defecto las raíces del sistema)",
+	"flag.amtinfo.cert":           "Hashes de certificados del sistema (y certificados de usuario si se indica la contraseña de AMT)",
+	"flag.amtinfo.json":           "Salida JSON",
+	"flag.amtinfo.password":       "Contraseña de AMT",
+	"flag.amtinfo.yaml":           "Salida YAML",
+	"flag.amtPassword":            "Contraseña de AMT",
+	"flag.amtPort":                "Puerto AMT de -host (por defecto 16992, 16993 con -tls)",
+	"flag.apply.dryrun":           "Muestra el plan de cambios sin aplicarlo",
+	"flag.apply.f":                "Documento JSON con el estado deseado del dispositivo, - lo lee de stdin",
+	"flag.apply.password":         "Contraseña de AMT, se usa la contraseña del documento si no se indica",
+	"flag.audit":                  "Registro de auditoría de AMT. Se requiere la contraseña de AMT",
+	"flag.authenticationMethod":   "Especifique el método de autenticación",
+	"flag.authenticationProtocol": "Especifique el protocolo de autenticación",
+	"flag.bios":                   "Fabricante, versión y fecha de la BIOS según SMBIOS, y las versiones de firmware, recuperación y seguridad del ME",
+	"flag.bld":                    "Número de compilación",
+	"flag.bootToBIOS":             "Arrancar en la configuración de la BIOS en el próximo arranque",
+	"flag.bootToPXE":              "Arrancar desde la red (PXE) en el próximo arranque",
+	"flag.bulk.amtCACert":         "Archivo PEM con los certificados de CA que verifican los certificados TLS de los dispositivos (por defecto las raíces del sistema)",
+	"flag.bulk.password":          "Contraseña de AMT de los dispositivos que no indican password",
+	"flag.bulk.skipAMTCertCheck":  "No verificar los certificados TLS de los dispositivos",
+	"flag.bulk.tls":               "Conectar con TLS a los dispositivos que no indican tls",
+	"flag.bulk.user":              "Usuario digest de los dispositivos que no indican user",
+	"flag.bundle":                 "Escribe amtinfo, el registro de eventos de AMT, los registros de rpc, la configuración de red del sistema operativo y el estado de LMS en un zip con marca de tiempo, con los secretos ocultos",
+	"flag.cacert":                 "Archivo PEM con los certificados de CA que verifican el certificado del servidor Websocket, además de las raíces del sistema",
+	"flag.caCert":                 "Certificado de CA (PEM o DER) de confianza para los certificados de cliente en la autenticación mutua",
+	"flag.cacheTTL":               "Cuánto tiempo se guardan en caché la versión, la compilación, el SKU, el UUID y los hashes de certificados leídos del MEI (p. ej. '1h' o '10m'), 0 desactiva la caché",
+	"flag.ccm":                    "Activar en el modo de control de cliente (CCM)",
+	"flag.cert":                   "Certificado TLS firmado (PEM o DER) para el par de claves generado en AMT",
+	"flag.certhash.delete":        "Nombre o hash hexadecimal del hash raíz de confianza que se elimina",
+	"flag.certWarnDays":           "Avisa de los certificados que caducan en este número de días",
+	"flag.checkcert.config":       "Especifique un archivo de configuración o una URL de recurso compartido smb: con los ajustes de acmactivate",
+	"flag.chunksize":              "Divide las respuestas de más de este número de bytes en fragmentos que el servidor vuelve a unir, 0 las envía enteras",
+	"flag.clear":                  "Borra el registro de eventos de AMT tras mostrar todos sus registros, hay que introducir de nuevo la contraseña de AMT. Requiere -eventlog, no se permite con -count ni -offset",
+	"flag.clientCert":             "Especifique el certificado de cliente",
+	"flag.command":                "Comando que se ejecuta en cada dispositivo, p. ej. 'amtinfo -ver -uuid' o 'power cycle', los argumentos tras las opciones se le añaden",
+	"flag.commonName":             "Nombre común de la solicitud de firma de certificado (por defecto el nombre de host del sistema operativo)",
+	"flag.config":                 "Archivo con valores por defecto de las opciones (rpc.yaml o rpc.json)",
+	"flag.configJson":             "Configuración como cadena JSON",
+	"flag.continuous":             "Sigue en ejecución y envía de nuevo la información del dispositivo cada -interval por la misma conexión con el servidor",
+	"flag.control":                "Socket Unix, o dirección localhost en Windows, en el que se sirve la API de control, ninguno si está vacío",
+	"flag.controlToken":           "Archivo con el token con el que se autentican los clientes de la API de control, se crea si no existe (por defecto control.token en la carpeta rpc del directorio de caché del usuario)",
+	"flag.count":                  "Número máximo de registros de auditoría o de eventos que se muestran, 0 muestra todos",
+	"flag.csr":                    "Archivo en el que se escribe la solicitud de firma de certificado del par de claves generado en AMT",
+	"flag.d":                      "Reemplaza el sufijo DNS",
+	"flag.deactivate.force":       "Igual que -f, además desactiva sin pedir confirmación cuando AMT está en modo de control de administrador o CIRA está conectado",
+	"flag.delete":                 "Nombre de la alarma de encendido que se elimina",
+	"flag.deleteOnCompletion":     "AMT elimina la alarma de -add una vez completada",
+	"flag.diag.lmsaddress":        "Dirección de LMS que se comprueba",
+	"flag.diag.lmsport":           "Puerto de LMS que se comprueba",
+	"flag.diag.password":          "Contraseña de AMT, el registro de eventos solo se añade con ella",
+	"flag.dir":                    "Directorio en el que se escribe el paquete (por defecto el directorio actual)",
+	"flag.disable":                "Deshabilita 802.1x en la interfaz cableada",
+	"flag.dns":                    "Sufijo DNS de PKI de AMT y sufijo DNS del sistema operativo",
+	"flag.dnssuffix":              "Sufijo DNS que se asigna a AMT - si no se indica, se usa el sufijo DNS del sistema operativo del host",
+	"flag.dryrun":                 "Comprueba el comando y muestra lo que se enviaría a AMT o al servidor sin cambiar nada",
+	"flag.enable":                 "Funciones de redirección separadas por comas que se habilitan: kvm, sol, ider",
+	"flag.enablewifiport.disable": "Deshabilita el puerto WiFi y la sincronización de perfiles locales",
+	"flag.encryptionMethod":       "Especifique el método de cifrado",
+	"flag.envdetection":           "Dominios de intranet separados por comas en los que AMT no se conecta al MPS",
+	"flag.eventlog":               "Registro de eventos de AMT. Se requiere la contraseña de AMT",
+	"flag.exclude":                "Campos separados por comas que se omiten de la información del dispositivo (hostname,fqdn,ipaddress,hardware,certhashes,friendlyname,tags)",
+	"flag.f":                      "Forzar aunque el dispositivo no esté registrado en un servidor",
+	"flag.file":                   "Archivo CSV o JSON con los dispositivos, las columnas o claves son host, amtPort, tls, user y password",
+	"flag.fips":                   "Genera la contraseña con el DRBG de un módulo criptográfico validado FIPS 140, rpc falla si no hay ninguno habilitado",
+	"flag.force":                  "Reemplaza la configuración de CIRA aunque ya coincida, necesario para cambiar la contraseña del MPS",
+	"flag.fqdn":                   "Sincroniza el nombre de host completo con el sufijo DNS del sistema operativo",
+	"flag.gateway":                "Dirección de la puerta de enlace que se asigna a AMT",
+	"flag.generate":               "Genera una contraseña aleatoria y la establece en AMT sin interacción con la nube",
+	"flag.generatePassword":       "Genera la contraseña de AMT de la activación CCM local en lugar de leerla, se guarda con -out, -keyring o -vault antes de activar",
+	"flag.h":                      "Reemplaza el nombre de host",
+	"flag.heartbeat":              "Intervalo de los pings de websocket que mantienen activa la conexión con el servidor, 0 los desactiva",
+	"flag.host":                   "Nombre de host o dirección IP de un dispositivo AMT remoto, wsman se le envía por la red en lugar de al LMS local",
+	"flag.hostname":               "Nombre de host del sistema operativo",
+	"flag.hw":                     "Inventario de hardware de SMBIOS: fabricante, modelo, número de serie, etiqueta de activo, CPU y memoria",
+	"flag.ieee8021xPassword":      "Contraseña 802.1x si authenticationProtocol es PEAPv0/EAP-MSCHAPv2(2)",
+	"flag.ieee8021xProfileName":   "Entrada de ieee8021xConfigs del archivo de configuración que se configura",
+	"flag.ifname":                 "Nombre de la interfaz del host de la que se leen los ajustes, en lugar de la interfaz con la dirección MAC de AMT",
+	"flag.insecure-skip-verify":   "Omite la verificación del certificado del servidor Websocket. La conexión puede ser interceptada, use -pin-sha256 en su lugar",
+	"flag.interactive":            "Pide los ajustes de activación y ejecuta comprobaciones previas antes de activar",
+	"flag.interval":               "Tiempo entre actualizaciones de la información del dispositivo de -continuous (p. ej. '15m' o '1h')",
+	"flag.ipv6addr":               "Dirección IPv6 que se asigna a AMT - si no se indica, se usa la dirección IPv6 global de la interfaz de red activa del sistema operativo",
+	"flag.ipv6gateway":            "Dirección de la puerta de enlace IPv6 que se asigna a AMT",
+	"flag.json":                   "Salida JSON",
+	"flag.keyring":                "Guarda la contraseña generada en el llavero del sistema operativo, se lee con -passwordFromKeyring",
+	"flag.kvm":                    "Estado de la redirección KVM, SOL e IDE-R y del listener de redirección. Se requiere la contraseña de AMT",
+	"flag.l":                      "Nivel de registro (panic,fatal,error,warn,info,debug,trace)",
+	"flag.lan":                    "Configuración de LAN",
+	"flag.length":                 "Longitud de la contraseña generada (8-32)",
+	"flag.linkPreference":         "Preferencia del enlace WiFi: me o host. Déjela vacía para mantener la preferencia actual",
+	"flag.linkPreferenceTimeout":  "Segundos que AMT mantiene el enlace WiFi con -linkPreference me antes de devolverlo al host",
+	"flag.lmsaddress":             "Dirección de LMS. Permite cambiar la ubicación de LMS para depuración.",
+	"flag.lmsport":                "Puerto de LMS",
+	"flag.local":                  "Ejecuta el comando directamente en AMT sin interacción con la nube",
+	"flag.logfile":                "Escribe el registro en este archivo en lugar de stderr",
+	"flag.logjson":                "Formato de registro JSON",
+	"flag.loglevels":              "Nivel de registro por módulo, p. ej. 'rps=debug,amt=trace' (módulos: rpc,flags,amt,rps,lms,local,agent,info)",
+	"flag.logmaxsize":             "Tamaño en MB a partir del cual se rota el archivo de registro",
+	"flag.logs":                   "Archivos de registro de rpc que se añaden al paquete, separados por comas, también se añaden sus archivos rotados",
+	"flag.lowercase":              "Sincroniza el nombre de host en minúsculas",
+	"flag.mac":                    "Dirección MAC de la interfaz del host de la que se leen los ajustes, p. ej. 'a4:bb:6d:01:02:03'",
+	"flag.maxSkew":                "Diferencia de reloj con el servidor o con la hora de -ntp a partir de la cual -precheck falla",
+	"flag.mebxPassword":           "Contraseña de MEBx que se establece tras la activación ACM local",
+	"flag.mode":                   "Modo de control actual",
+	"flag.modes":                  "Si los ajustes del firmware permiten la activación CCM y ACM: estado de AMT y cambio desde el sistema operativo, configuración remota, modo TLS de aprovisionamiento y hashes raíz activos",
+	"flag.mpsaddress":             "Nombre de host o dirección IP del MPS",
+	"flag.mpscert":                "Certificado raíz (PEM o DER) del certificado de servidor del MPS",
+	"flag.mpscn":                  "Nombre común del certificado de servidor del MPS (por defecto -mpsaddress)",
+	"flag.mpspassword":            "Contraseña con la que AMT se autentica ante el MPS",
+	"flag.mpsport":                "Puerto del MPS",
+	"flag.mpsuser":                "Nombre de usuario con el que AMT se autentica ante el MPS",
+	"flag.mqttBroker":             "Broker MQTT al que se publica el estado de la operación, p. ej. 'tcp://broker:1883' o 'ssl://broker:8883'",
+	"flag.mqttPassword":           "Contraseña del broker MQTT",
+	"flag.mqttTopic":              "Tema MQTT de los eventos de estado",
+	"flag.mqttUser":               "Usuario del broker MQTT",
+	"flag.n":                      "Omite la verificación del certificado del servidor Websocket",
+	"flag.name":                   "Nombre descriptivo que se asocia a este dispositivo",
+	"flag.netmask":                "Máscara de red que se asigna a AMT - si no se indica, se usa la máscara de red de la interfaz de red activa del sistema operativo",
+	"flag.nocache":                "Lee la versión, la compilación, el SKU, el UUID y los hashes de certificados del MEI en lugar de la caché, y los vuelve a guardar en caché",
+	"flag.nocompression":          "No negocia la compresión permessage-deflate de los mensajes websocket",
+	"flag.nosymbols":              "Genera la contraseña solo con los símbolos + - . / =, que no necesitan comillas en shells ni XML",
+	"flag.ntp":                    "Servidor NTP (host o host:puerto) al que se consulta la hora en lugar de usar el reloj del sistema operativo del host",
+	"flag.offset":                 "Número de registros de auditoría o de eventos que se omiten",
+	"flag.once":                   "Arranca desde -source solo en el próximo arranque, el único modo que admite AMT",
+	"flag.opstate":                "Estado operativo de AMT (habilitado en MEBx) y estado de aprovisionamiento",
+	"flag.otel-endpoint":          "Colector de OpenTelemetry al que se exportan trazas y métricas con OTLP sobre HTTP, p. ej. 'http://collector:4318'",
+	"flag.out":                    "Escribe la contraseña generada en este archivo, legible solo por el propietario",
+	"flag.p":                      "Dirección y puerto del proxy",
+	"flag.partial":                "Elimina la configuración de CIRA, TLS y wifi pero deja AMT activado. Se ejecuta localmente",
+	"flag.password":               "Contraseña de AMT",
+	"flag.passwordFile":           "Lee la contraseña de AMT de la primera línea de este archivo, no debe ser legible por el grupo ni por otros",
+	"flag.passwordFromKeyring":    "Lee la contraseña de AMT del llavero del sistema operativo (servicio 'rpc', cuenta 'admin') en lugar de pedirla",
+	"flag.periodic":               "Segundos entre las conexiones periódicas al MPS, 0 para conectar solo por solicitud del usuario y alertas",
+	"flag.pin-sha256":             "Hashes SHA-256 en base64, separados por comas, de la clave pública del servidor Websocket o de una de sus CA, p. ej. 'sha256//BASE64'",
+	"flag.precheck":               "Comprueba el modo de control, el sufijo DNS, el reloj, los hashes de certificados y la accesibilidad del servidor antes de activar, y se detiene en la primera comprobación fallida",
+	"flag.preferSubnet":           "Subred IPv4 en notación CIDR de la dirección del host que se sincroniza cuando la interfaz del host tiene varias, p. ej. '192.168.1.0/24'",
+	"flag.prefixlen":              "Longitud del prefijo de la dirección IPv6 (por defecto 64)",
+	"flag.primarydns":             "DNS principal que se asigna a AMT - si no se indica, se usan los servidores DNS del sistema operativo del host",
+	"flag.priority":               "Especifique la prioridad",
+	"flag.privateKey":             "Especifique la clave privada",
+	"flag.probe":                  "Se conecta a los servidores MPS desde el sistema operativo del host e informa de la accesibilidad, el protocolo de enlace TLS y la latencia, implica -ras",
+	"flag.profile":                "Nombre del perfil que se usa",
+	"flag.profileName":            "Especifique el nombre del perfil wifi",
+	"flag.provisioningCert":       "Certificado de aprovisionamiento, codificado en base64 o la ruta de un archivo .pfx",
+	"flag.provisioningCertPwd":    "Contraseña del certificado de aprovisionamiento",
+	"flag.proxy":                  "URL del proxy (http:// o socks5://). Se usan HTTPS_PROXY y NO_PROXY si no se indica",
+	"flag.proxypassword":          "Contraseña de la autenticación básica del proxy",
+	"flag.proxyuser":              "Usuario de la autenticación básica del proxy",
+	"flag.pskPassphrase":          "Especifique la frase de contraseña PSK",
+	"flag.pxeTimeout":             "Segundos que AMT permite un arranque PXE antes de la autenticación 802.1x, 0 deshabilita el arranque PXE",
+	"flag.ras":                    "Estado del acceso remoto (y servidores MPS, detección del entorno y desencadenantes si se indica la contraseña de AMT)",
+	"flag.reason":                 "Motivo de la desactivación, se registra y se envía al servidor",
+	"flag.redirection.disable":    "Funciones de redirección separadas por comas que se deshabilitan: kvm, sol, ider",
+	"flag.report":                 "Archivo en el que se escriben en JSON los resultados de todos los dispositivos",
+	"flag.reprovision":            "Desactiva un dispositivo ya activado y lo activa de nuevo, con -local",
+	"flag.reset":                  "Reinicia el dispositivo una vez establecido el origen de arranque",
+	"flag.resume":                 "Envía el progreso guardado de una activación RPS interrumpida del dispositivo al servidor con el mismo -profile, para un servidor que continúa activaciones. RPS empieza la activación de nuevo",
+	"flag.retries":                "Número de reintentos de una conexión fallida con el servidor",
+	"flag.retryDelay":             "Espera antes del primer reintento, se duplica con variación aleatoria en cada reintento siguiente (p. ej. '1s' o '500ms')",
+	"flag.returncodes.json":       "Salida JSON",
+	"flag.returncodes.yaml":       "Salida YAML",
+	"flag.rpsAPI":                 "URL base de la API REST de RPS, p. ej. 'https://server/rps'. El modo de control, el sufijo DNS y los hashes de certificados del dispositivo se comprueban con el perfil leído de ella antes de activar",
+	"flag.seccheck":               "Compara la versión del firmware con los avisos de seguridad de Intel (INTEL-SA) e informa de si está afectado",
+	"flag.secondary":              "Nombre de host o dirección IP del MPS al que AMT recurre cuando no llega al principal",
+	"flag.secondarycn":            "Nombre común del certificado de servidor del MPS secundario (por defecto -secondary)",
+	"flag.secondarydns":           "DNS secundario que se asigna a AMT",
+	"flag.secondaryport":          "Puerto del MPS secundario (por defecto -mpsport)",
+	"flag.secrets":                "Especifique un archivo de secretos",
+	"flag.secretsFile":            "Archivo con AMT_PASSWORD, PROXY_PASSWORD y RPS_TOKEN del agente como líneas NOMBRE=valor, lo escribe service install",
+	"flag.selftest.lmsaddress":    "Dirección de LMS que se comprueba",
+	"flag.selftest.lmsport":       "Puerto de LMS que se comprueba",
+	"flag.serverOrder":            "Orden en que se prueban los servidores de -u: listed o latency",
+	"flag.sessionFile":            "Archivo en el que se guarda el estado de una activación RPS hasta que se completa (por defecto activation.json en la carpeta rpc del directorio de caché del usuario)",
+	"flag.sessionlog":             "Añade la salida de la consola de la sesión a este archivo",
+	"flag.short":                  "Sincroniza el nombre de host sin su dominio",
+	"flag.show":                   "Muestra la información del dispositivo que se enviaría al servidor sin conectarse a él",
+	"flag.skipAMTCertCheck":       "No verificar el certificado TLS de -host",
+	"flag.sku":                    "SKU del producto",
+	"flag.source":                 "Dispositivo desde el que se arranca en el próximo arranque: pxe, hdd, cd",
+	"flag.ssid":                   "Especifique el SSID",
+	"flag.start":                  "Primera hora de encendido de -add, HH:MM del reloj local para la próxima vez o RFC3339",
+	"flag.static":                 "Especifique una nueva contraseña para AMT",
+	"flag.staticip":               "Dirección IP que se asigna a AMT - si no se indica, se usa la dirección IP de la interfaz de red activa del sistema operativo",
+	"flag.status.maxSkew":         "Diferencia de reloj entre AMT y el host a partir de la cual la comprobación del reloj avisa",
+	"flag.status.password":        "Contraseña de AMT, las comprobaciones de TLS, reloj, nombre de host y certificados la necesitan",
+	"flag.syncclock.local":        "Sincroniza AMT con el reloj del sistema operativo del host directamente sin interacción con la nube",
+	"flag.syncclock.maxSkew":      "Diferencia de reloj entre AMT y el host o la hora de -ntp dentro de la cual el reloj se deja como está (p. ej. '2s' o '1m'), 0 siempre sincroniza",
+	"flag.syncip.primarydns":      "DNS principal que se asigna a AMT",
+	"flag.syncwifi.ssid":          "SSID separados por comas de los perfiles wifi del sistema operativo que se sincronizan - si no se indica, se sincronizan todos los perfiles que admite AMT",
+	"flag.sys":                    "Nombre, versión y compilación del sistema operativo del host, arquitectura, versión de rpc y si LMS o el AMT de -host acepta conexiones",
+	"flag.t":                      "Tiempo de espera de AMT - tiempo hasta que AMT está listo (p. ej. '2m' o '30s')",
+	"flag.tag":                    "Etiqueta de metadatos clave=valor que se envía al servidor, repita la opción para varias etiquetas",
+	"flag.tasks":                  "Tareas de mantenimiento separadas por comas que se ejecutan (syncclock,synchostname,syncip,syncdeviceinfo)",
+	"flag.template":               "Prefijo y sufijo alrededor del nombre de host, p. ej. 'amt-{hostname}-lab'",
+	"flag.tenant":                 "TenantID",
+	"flag.tenantId":               "TenantID, igual que -tenant",
+	"flag.timeout":                "Tiempo de espera de cada comando MEI antes de fallar con MEITimeout (p. ej. '30s'), 0 espera sin límite",
+	"flag.tls":                    "Conectar con -host mediante TLS",
+	"flag.tlssettings.local":      "Habilita TLS también en la interfaz local (LMS), rpc accede entonces a AMT con -transport lms-tls",
+	"flag.tlssettings.mode":       "Modo de autenticación TLS: Server, ServerAndNonTLS, Mutual, MutualAndNonTLS (por defecto Server)",
+	"flag.token":                  "Token JWT de autorización",
+	"flag.truncate":               "Acorta los nombres de host de más de los 63 caracteres que acepta AMT",
+	"flag.trustedCN":              "Nombre común exigido en los certificados de cliente en la autenticación mutua",
+	"flag.tz":                     "Zona horaria IANA (p. ej. 'Europe/Berlin') en la que se indican las horas y con la que se comprueba el reloj de AMT por la hora local o un cambio de horario de verano omitido (por defecto la zona horaria del host)",
+	"flag.u":                      "Dirección Websocket del servidor, una lista separada por comas o -u repetido para la conmutación por error entre servidores",
+	"flag.upgrade":                "Cambia un dispositivo activado en modo de control de cliente al modo de control de administrador, con -local -acm",
+	"flag.uploadLogs":             "Envía las entradas de registro del comando al servidor, que las guarda con el dispositivo para la resolución de problemas",
+	"flag.user":                   "Usuario digest de -host",
+	"flag.userCert":               "Solo los certificados de usuario. Se requiere la contraseña de AMT",
+	"flag.username":               "Especifique el nombre de usuario",
+	"flag.uuid":                   "Identificador único",
+	"flag.v":                      "Salida detallada",
+	"flag.validate":               "Falla con nombres de host de más de los 63 caracteres que acepta AMT o con caracteres distintos de letras, dígitos y '-'",
+	"flag.value":                  "Sufijo DNS de PKI que se compara con el dominio del certificado de aprovisionamiento, p. ej. corp.example.com",
+	"flag.vault":                  "Lee la contraseña actual de este almacén de secretos y escribe en él la generada, p. ej. 'vault://vault.example.com:8200/secret/amt/device1' o 'cyberark://ccp.example.com/?appId=rpc&safe=AMT&object=device1&accountId=12_3'",
+	"flag.vaultToken":             "Token del almacén de secretos indicado con -vault",
+	"flag.ver":                    "Versión de la BIOS",
+	"flag.verbose-progress":       "Muestra un indicador de progreso mientras el servidor configura AMT",
+	"flag.version.json":           "Salida JSON",
+	"flag.version.yaml":           "Salida YAML",
+	"flag.warn-only":              "Solo los hashes de certificados de CA obsoletas (SHA1), implica -cert",
+	"flag.wipe":                   "Elimina perfiles wifi, certificados, la configuración de CIRA y el registro de auditoría, y después desactiva. Se ejecuta localmente",
+	"flag.wired8021x.caCert":      "Especifique el certificado de CA del servidor RADIUS",
+	"flag.wired8021x.config":      "Especifique un archivo de configuración o una URL de recurso compartido smb: con ieee8021xConfigs",
+	"flag.workers":                "Número de dispositivos en los que el comando se ejecuta a la vez",
+	"flag.xml":                    "Archivo con el sobre WS-MAN que se envía a AMT, - lo lee de stdin",
+	"flag.yaml":                   "Salida YAML",
+}
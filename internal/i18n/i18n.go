@@ -0,0 +1,130 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultLanguage is used when no language is selected, its catalog has every message
+const DefaultLanguage = "en"
+
+// labelWidth is the column, with tabs of 8, at which the values of text output start
+const labelWidth = 24
+
+// catalogs maps a language to its messages by id. A message missing in a catalog falls
+// back to the English one.
+var catalogs = map[string]map[string]string{
+	"en": en,
+	"es": es,
+	"de": de,
+}
+
+// mu guards the selected catalog, the agent control API and the library parse command
+// lines while other commands run
+var (
+	mu              sync.RWMutex
+	current         = catalogs[DefaultLanguage]
+	currentLanguage = DefaultLanguage
+)
+
+// Languages returns the languages that have a catalog
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// SetLanguage selects the catalog of the messages. Locale names such as de_DE.UTF-8 or
+// es-MX select the catalog of their language.
+func SetLanguage(language string) error {
+	name := strings.ToLower(language)
+	if i := strings.IndexAny(name, "_-."); i > 0 {
+		name = name[:i]
+	}
+	catalog, ok := catalogs[name]
+	if !ok {
+		return fmt.Errorf("unsupported language %q, supported are %s", language, strings.Join(Languages(), ", "))
+	}
+	mu.Lock()
+	current, currentLanguage = catalog, name
+	mu.Unlock()
+	return nil
+}
+
+// Language returns the selected language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return currentLanguage
+}
+
+func selected() map[string]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T returns the message of the selected language, formatted with the args when given.
+// An unknown id is returned as it is, so a missing message shows up in the output.
+func T(id string, args ...interface{}) string {
+	return format(selected(), id, args...)
+}
+
+// English returns the English message whatever the selected language, for the texts
+// that stay in English such as the JSON output and the log
+func English(id string, args ...interface{}) string {
+	return format(en, id, args...)
+}
+
+func format(catalog map[string]string, id string, args ...interface{}) string {
+	message, ok := catalog[id]
+	if !ok {
+		if message, ok = en[id]; !ok {
+			message = id
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Lookup returns the message of the selected language, ok is false when that catalog has
+// none. It is used for texts whose English version is kept next to the code.
+func Lookup(id string) (message string, ok bool) {
+	message, ok = selected()[id]
+	return message, ok
+}
+
+// FlagUsage returns the usage of the flag of a command in the selected language, ok is
+// false when the catalog has none and the English usage of the flag set is kept. A
+// flag whose usage differs between commands is translated as flag.COMMAND.NAME.
+func FlagUsage(command string, name string) (usage string, ok bool) {
+	catalog := selected()
+	if usage, ok = catalog["flag."+command+"."+name]; ok {
+		return usage, ok
+	}
+	usage, ok = catalog["flag."+name]
+	return usage, ok
+}
+
+// Label returns the message followed by the tabs that line up the values of text output,
+// which is printed as label + ": " + value
+func Label(id string) string {
+	label := T(id)
+	tabs := 1
+	if n := utf8.RuneCountInString(label); n < labelWidth {
+		tabs = (labelWidth - n + 7) / 8
+	}
+	return label + strings.Repeat("\t", tabs)
+}
@@ -0,0 +1,123 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package i18n
+
+import (
+	"regexp"
+	"rpc/pkg/utils"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	for _, language := range []string{"de", "DE", "de_DE.UTF-8", "de-AT"} {
+		assert.NoError(t, SetLanguage(language))
+		assert.Equal(t, "de", Language())
+	}
+	assert.ErrorContains(t, SetLanguage("fr"), "supported are de, en, es")
+	assert.Equal(t, "de", Language())
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	assert.Equal(t, "Usage", T("usage.usage"))
+	assert.Equal(t, "---Audit Log (2 of 5 records)---", T("info.auditLog", 2, 5))
+	assert.Equal(t, "no.such.message", T("no.such.message"))
+
+	assert.NoError(t, SetLanguage("es"))
+	assert.Equal(t, "Uso", T("usage.usage"))
+	delete(es, "usage.usage")
+	defer func() { es["usage.usage"] = "Uso" }()
+	assert.Equal(t, "Usage", T("usage.usage"))
+}
+
+func TestLookup(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	_, ok := Lookup("returncode.Success")
+	assert.False(t, ok)
+	assert.NoError(t, SetLanguage("es"))
+	message, ok := Lookup("returncode.Success")
+	assert.True(t, ok)
+	assert.Equal(t, "el comando se completó correctamente", message)
+}
+
+func TestEnglish(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	assert.NoError(t, SetLanguage("de"))
+	assert.Equal(t, "unerwartetes Argument x", T("error.unexpectedArgument", "x"))
+	assert.Equal(t, "unexpected argument x", English("error.unexpectedArgument", "x"))
+}
+
+func TestFlagUsage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	_, ok := FlagUsage("amtinfo", "json")
+	assert.False(t, ok, "the English usage is kept with the flag")
+	assert.NoError(t, SetLanguage("es"))
+	usage, ok := FlagUsage("activate", "v")
+	assert.True(t, ok)
+	assert.Equal(t, "Salida detallada", usage)
+	// a flag with another usage in a command is translated for that command
+	usage, _ = FlagUsage("syncclock", "local")
+	assert.Contains(t, usage, "reloj")
+	usage, _ = FlagUsage("deactivate", "local")
+	assert.Contains(t, usage, "directamente en AMT")
+	_, ok = FlagUsage("activate", "bogus")
+	assert.False(t, ok)
+}
+
+func TestConcurrentLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	// the agent control API and the library parse command lines while commands print
+	var wg sync.WaitGroup
+	for _, language := range []string{"en", "es", "de"} {
+		wg.Add(1)
+		go func(language string) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				assert.NoError(t, SetLanguage(language))
+				assert.NotEmpty(t, T("usage.usage"))
+				assert.NotEmpty(t, Language())
+			}
+		}(language)
+	}
+	wg.Wait()
+}
+
+func TestLabel(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+	assert.Equal(t, "UUID\t\t\t", Label("info.uuid"))
+	assert.Equal(t, "Build Number\t\t", Label("info.buildNumber"))
+	assert.Equal(t, "Operational State\t", Label("info.operationalState"))
+	assert.NoError(t, SetLanguage("es"))
+	// the accented characters count once
+	assert.Equal(t, "Versión\t\t\t", Label("info.version"))
+	assert.Equal(t, "Compilación firmware ME\t", Label("info.meFirmwareBuild"))
+}
+
+func TestCatalogs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[a-z]`)
+	for language, catalog := range catalogs {
+		for id, message := range catalog {
+			english, ok := en[id]
+			if !ok {
+				assert.Regexp(t, `^(returncode|flag)\.`, id, "%s has no English message for %s", language, id)
+				continue
+			}
+			assert.Equal(t, verbs.FindAllString(english, -1), verbs.FindAllString(message, -1), "%s %s", language, id)
+		}
+		if language == DefaultLanguage {
+			continue
+		}
+		for id := range en {
+			assert.Contains(t, catalog, id, "%s has no message for %s", language, id)
+		}
+		for _, info := range utils.ReturnCodes {
+			assert.Contains(t, catalog, "returncode."+info.Name, "%s has no description of %s", language, info.Name)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// embeddedAdvisories is the advisory table rpc was built with, a newer one is read with
+// amtinfo -advisories
+//
+//go:embed advisories.json
+var embeddedAdvisories []byte
+
+// AdvisoryTable lists the Intel security advisories that affect ME firmware versions
+type AdvisoryTable struct {
+	// Updated is the date of the table
+	Updated    string     `json:"updated"`
+	Advisories []Advisory `json:"advisories"`
+}
+
+// Advisory is an INTEL-SA advisory with the firmware versions it affects
+type Advisory struct {
+	ID       string          `json:"id"`
+	Title    string          `json:"title"`
+	CVEs     []string        `json:"cves"`
+	URL      string          `json:"url"`
+	Affected []AffectedRange `json:"affected"`
+}
+
+// AffectedRange is a firmware branch from Introduced up to, not including, Fixed. Without
+// Fixed every later version of the branch is affected.
+type AffectedRange struct {
+	Introduced string `json:"introduced"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// The status of the firmware for an advisory
+const (
+	AdvisoryAffected    = "affected"
+	AdvisoryNotAffected = "not affected"
+	// AdvisoryNotListed is firmware of a major version newer than every version in the
+	// table, the table predates it so advisories that affect it are missing
+	AdvisoryNotListed = "not listed"
+	// AdvisoryUnknown is firmware whose version could not be read
+	AdvisoryUnknown = "unknown"
+)
+
+// AdvisoryResult is the status of the firmware for an advisory, Fixed is the version that
+// fixes an affected firmware
+type AdvisoryResult struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	CVEs   []string `json:"cves"`
+	URL    string   `json:"url"`
+	Status string   `json:"status"`
+	Fixed  string   `json:"fixed,omitempty"`
+}
+
+// SecurityCheck is the exposure of the firmware to the advisories of the table
+type SecurityCheck struct {
+	FirmwareVersion string           `json:"firmwareVersion"`
+	TableUpdated    string           `json:"tableUpdated"`
+	Affected        int              `json:"affected"`
+	Advisories      []AdvisoryResult `json:"advisories"`
+}
+
+// LoadAdvisories reads the advisory table from path, or the embedded table when path is
+// empty. Unknown fields and versions that do not parse fail the table so a typo does not
+// hide an affected firmware.
+func LoadAdvisories(path string) (AdvisoryTable, error) {
+	content := embeddedAdvisories
+	if path != "" {
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return AdvisoryTable{}, err
+		}
+	}
+	table := AdvisoryTable{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&table); err != nil {
+		return AdvisoryTable{}, err
+	}
+	for _, advisory := range table.Advisories {
+		if advisory.ID == "" {
+			return AdvisoryTable{}, fmt.Errorf("advisory without id")
+		}
+		for _, affected := range advisory.Affected {
+			if _, err := parseFirmwareVersion(affected.Introduced); err != nil {
+				return AdvisoryTable{}, fmt.Errorf("%s: introduced: %w", advisory.ID, err)
+			}
+			if affected.Fixed == "" {
+				continue
+			}
+			if _, err := parseFirmwareVersion(affected.Fixed); err != nil {
+				return AdvisoryTable{}, fmt.Errorf("%s: fixed: %w", advisory.ID, err)
+			}
+		}
+	}
+	return table, nil
+}
+
+// CheckFirmware compares the firmware version and build number with the advisories
+func (table AdvisoryTable) CheckFirmware(fw FirmwareInfo) SecurityCheck {
+	version := fw.Version
+	if fw.BuildNumber != "" {
+		version += "." + fw.BuildNumber
+	}
+	check := SecurityCheck{FirmwareVersion: version, TableUpdated: table.Updated, Advisories: []AdvisoryResult{}}
+	parsed, err := parseFirmwareVersion(version)
+	notAffected := AdvisoryNotAffected
+	if err == nil && parsed[0] > table.newestMajor() {
+		notAffected = AdvisoryNotListed
+	}
+	for _, advisory := range table.Advisories {
+		result := AdvisoryResult{ID: advisory.ID, Title: advisory.Title, CVEs: advisory.CVEs, URL: advisory.URL, Status: notAffected}
+		if err != nil {
+			result.Status = AdvisoryUnknown
+		}
+		for _, affected := range advisory.Affected {
+			if err != nil {
+				break
+			}
+			if affected.contains(parsed) {
+				result.Status, result.Fixed = AdvisoryAffected, affected.Fixed
+				check.Affected++
+				break
+			}
+		}
+		check.Advisories = append(check.Advisories, result)
+	}
+	return check
+}
+
+// newestMajor is the newest major firmware version the advisories of the table list
+func (table AdvisoryTable) newestMajor() int {
+	newest := 0
+	for _, advisory := range table.Advisories {
+		for _, affected := range advisory.Affected {
+			for _, v := range []string{affected.Introduced, affected.Fixed} {
+				if parsed, err := parseFirmwareVersion(v); err == nil && parsed[0] > newest {
+					newest = parsed[0]
+				}
+			}
+		}
+	}
+	return newest
+}
+
+// contains tells whether the version is in the range, the versions were checked by
+// LoadAdvisories
+func (r AffectedRange) contains(version []int) bool {
+	introduced, _ := parseFirmwareVersion(r.Introduced)
+	if compareFirmwareVersions(version, introduced) < 0 {
+		return false
+	}
+	if r.Fixed == "" {
+		// the branch is the major and minor version of Introduced
+		return compareFirmwareVersions(firmwareBranch(version), firmwareBranch(introduced)) == 0
+	}
+	fixed, _ := parseFirmwareVersion(r.Fixed)
+	return compareFirmwareVersions(version, fixed) < 0
+}
+
+// firmwareBranch is the major and minor version
+func firmwareBranch(version []int) []int {
+	if len(version) > 2 {
+		return version[:2]
+	}
+	return version
+}
+
+// parseFirmwareVersion splits a version such as 16.1.25.2049 into its numbers
+func parseFirmwareVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	numbers := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid firmware version %q", version)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// compareFirmwareVersions compares the numbers of two versions, missing numbers are 0
+func compareFirmwareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAdvisories(t *testing.T) {
+	t.Run("embedded table", func(t *testing.T) {
+		table, err := LoadAdvisories("")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, table.Updated)
+		assert.NotEmpty(t, table.Advisories)
+	})
+	t.Run("table from a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "advisories.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"updated":"2030-01-01","advisories":[{"id":"INTEL-SA-99999","affected":[{"introduced":"16.1","fixed":"16.1.30.2300"}]}]}`), 0600))
+		table, err := LoadAdvisories(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "2030-01-01", table.Updated)
+		assert.Equal(t, "INTEL-SA-99999", table.Advisories[0].ID)
+	})
+	for name, content := range map[string]string{
+		"unknown field":   `{"advisories":[{"id":"INTEL-SA-99999","affects":[]}]}`,
+		"invalid version": `{"advisories":[{"id":"INTEL-SA-99999","affected":[{"introduced":"16.x"}]}]}`,
+		"missing id":      `{"advisories":[{"affected":[{"introduced":"16.1"}]}]}`,
+		"not json":        `advisories`,
+	} {
+		t.Run("fails on "+name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "advisories.json")
+			assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+			_, err := LoadAdvisories(path)
+			assert.Error(t, err)
+		})
+	}
+	t.Run("fails on a missing file", func(t *testing.T) {
+		_, err := LoadAdvisories(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckFirmware(t *testing.T) {
+	table := AdvisoryTable{
+		Updated: "2030-01-01",
+		Advisories: []Advisory{
+			{ID: "SA-1", Affected: []AffectedRange{{Introduced: "11.0", Fixed: "11.8.77"}, {Introduced: "12.0", Fixed: "12.0.64"}}},
+			{ID: "SA-2", Affected: []AffectedRange{{Introduced: "16.1"}}},
+		},
+	}
+	tests := map[string]struct {
+		fw         FirmwareInfo
+		wantStatus []string
+		wantFixed  string
+	}{
+		"affected below the fix": {
+			fw:         FirmwareInfo{Version: "11.8.50", BuildNumber: "3425"},
+			wantStatus: []string{AdvisoryAffected, AdvisoryNotAffected},
+			wantFixed:  "11.8.77",
+		},
+		"not affected at the fix": {
+			fw:         FirmwareInfo{Version: "12.0.64", BuildNumber: "1551"},
+			wantStatus: []string{AdvisoryNotAffected, AdvisoryNotAffected},
+		},
+		"not affected before the branch": {
+			fw:         FirmwareInfo{Version: "10.0.55", BuildNumber: "3000"},
+			wantStatus: []string{AdvisoryNotAffected, AdvisoryNotAffected},
+		},
+		"affected branch without fix": {
+			fw:         FirmwareInfo{Version: "16.1.25", BuildNumber: "2049"},
+			wantStatus: []string{AdvisoryNotAffected, AdvisoryAffected},
+		},
+		"later branch without fix": {
+			fw:         FirmwareInfo{Version: "16.5.10", BuildNumber: "1000"},
+			wantStatus: []string{AdvisoryNotAffected, AdvisoryNotAffected},
+		},
+		"not listed for a major version newer than the table": {
+			fw:         FirmwareInfo{Version: "18.0.5", BuildNumber: "2000"},
+			wantStatus: []string{AdvisoryNotListed, AdvisoryNotListed},
+		},
+		"unknown version": {
+			fw:         FirmwareInfo{},
+			wantStatus: []string{AdvisoryUnknown, AdvisoryUnknown},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			check := table.CheckFirmware(tc.fw)
+			affected := 0
+			for i, want := range tc.wantStatus {
+				assert.Equal(t, want, check.Advisories[i].Status)
+				if want == AdvisoryAffected {
+					affected++
+				}
+			}
+			assert.Equal(t, affected, check.Affected)
+			assert.Equal(t, tc.wantFixed, check.Advisories[0].Fixed)
+			assert.Equal(t, "2030-01-01", check.TableUpdated)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// cacheFileName and cacheKeyFileName are the files in the cache directory the cached
+	// values and the key they are signed with are saved to
+	cacheFileName    = "amtinfo.json"
+	cacheKeyFileName = "amtinfo.key"
+)
+
+// cachedQueries are the queries whose values only change with a firmware update or a
+// change of the certificate hashes, reading them takes a round trip to the MEI each
+var cachedQueries = []Query{QueryVersion, QueryBuild, QuerySKU, QueryUUID, QueryCertHashes}
+
+// now returns the current time, it is replaced in tests
+var now = time.Now
+
+// Cache keeps the values of the cachedQueries in a file signed with a key of the cache
+// directory, so monitoring agents running amtinfo often do not query the MEI each time.
+// The signature detects a modified or damaged file, the key is protected by the
+// permissions of the directory.
+type Cache struct {
+	// Dir is the directory of the cache file and of its key
+	Dir string
+	// TTL is how long cached values are used
+	TTL time.Duration
+	// Refresh reads every value from the MEI, the values read are cached again
+	Refresh bool
+}
+
+// cacheEntry is the content of the cache file, Saved is when the oldest value was read
+type cacheEntry struct {
+	Saved  time.Time                 `json:"saved"`
+	Values map[Query]json.RawMessage `json:"values"`
+}
+
+// cacheFile is the signed cache entry as saved
+type cacheFile struct {
+	Entry     json.RawMessage `json:"entry"`
+	Signature string          `json:"signature"`
+}
+
+// cachedValue returns the selection in req and the value in result of a cached query
+func cachedValue(query Query, req *InfoRequest, result *InfoResult) (*bool, interface{}) {
+	switch query {
+	case QueryVersion:
+		return &req.Version, &result.Version
+	case QueryBuild:
+		return &req.Build, &result.BuildNumber
+	case QuerySKU:
+		return &req.SKU, &result.SKU
+	case QueryUUID:
+		return &req.UUID, &result.UUID
+	case QueryCertHashes:
+		return &req.CertHashes, &result.CertHashes
+	}
+	return nil, nil
+}
+
+// apply sets the cached values selected in req in result and drops them from req, so they
+// are not queried
+func (e *cacheEntry) apply(req *InfoRequest, result *InfoResult) {
+	for _, query := range cachedQueries {
+		selected, value := cachedValue(query, req, result)
+		data, ok := e.Values[query]
+		if !*selected || !ok {
+			continue
+		}
+		if err := json.Unmarshal(data, value); err != nil {
+			log.Debugf("unable to read the cached %s: %s", query, err)
+			continue
+		}
+		*selected = false
+		result.CachedAt = e.Saved
+	}
+}
+
+// update adds the values queried without error to the entry, it returns whether one was added
+func (e *cacheEntry) update(req InfoRequest, result *InfoResult) bool {
+	updated := false
+	for _, query := range cachedQueries {
+		selected, value := cachedValue(query, &req, result)
+		if !*selected || result.Errors[query] != nil {
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		e.Values[query] = data
+		updated = true
+	}
+	return updated
+}
+
+// load returns the cached entry, a new entry when there is none, it is expired or its
+// signature does not match
+func (c *Cache) load() *cacheEntry {
+	fresh := &cacheEntry{Saved: now(), Values: map[Query]json.RawMessage{}}
+	if c.Refresh {
+		return fresh
+	}
+	entry, err := c.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh
+	}
+	if err != nil {
+		log.Debug("ignoring the amtinfo cache: ", err)
+		return fresh
+	}
+	if age := now().Sub(entry.Saved); age < 0 || age > c.TTL {
+		log.Debugf("the amtinfo cache of %s has expired", entry.Saved.Format(time.RFC3339))
+		return fresh
+	}
+	return entry
+}
+
+func (c *Cache) read() (*cacheEntry, error) {
+	content, err := os.ReadFile(filepath.Join(c.Dir, cacheFileName))
+	if err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(filepath.Join(c.Dir, cacheKeyFileName))
+	if err != nil {
+		return nil, err
+	}
+	var file cacheFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(file.Signature)
+	if err != nil || !hmac.Equal(signature, sign(key, file.Entry)) {
+		return nil, errors.New("the signature of the cache file does not match")
+	}
+	entry := &cacheEntry{}
+	if err = json.Unmarshal(file.Entry, entry); err != nil {
+		return nil, err
+	}
+	if entry.Values == nil {
+		entry.Values = map[Query]json.RawMessage{}
+	}
+	return entry, nil
+}
+
+// save signs the entry and replaces the cache file with it, the key is created with the first save
+func (c *Cache) save(entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	key, err := c.key()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(cacheFile{Entry: data, Signature: base64.StdEncoding.EncodeToString(sign(key, data))})
+	if err != nil {
+		return err
+	}
+	// concurrent runs each write their own file, the rename replaces the cache at once
+	tmp, err := os.CreateTemp(c.Dir, cacheFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.Dir, cacheFileName))
+}
+
+// key returns the signing key of the cache directory, it is created when missing
+func (c *Cache) key() ([]byte, error) {
+	path := filepath.Join(c.Dir, cacheKeyFileName)
+	key, err := os.ReadFile(path)
+	if err == nil && len(key) > 0 {
+		return key, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(path, key, 0600); err != nil {
+		return nil, fmt.Errorf("unable to save the cache key: %w", err)
+	}
+	return key, nil
+}
+
+// Clear removes the cached values, for when rpc changed one of them
+func (c *Cache) Clear() error {
+	err := os.Remove(filepath.Join(c.Dir, cacheFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func sign(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,100 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"os"
+	"path/filepath"
+	"rpc/internal/amt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCollector returns a collector using cache and the counter of its MEI connections
+func countingCollector(cache *Cache) (Collector, *int) {
+	calls := 0
+	var mu sync.Mutex
+	return Collector{Cache: cache, NewAMTCommand: func() amt.Interface {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return mockAMT{}
+	}}, &calls
+}
+
+func TestCollectCached(t *testing.T) {
+	req := InfoRequest{Version: true, UUID: true, CertHashes: true, Mode: true}
+	t.Run("reads the static values from the cache", func(t *testing.T) {
+		cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+		collector, calls := countingCollector(cache)
+		result := collector.Collect(req)
+		assert.Equal(t, 1, *calls)
+		assert.True(t, result.CachedAt.IsZero())
+
+		result = collector.Collect(req)
+		// the control mode is read again
+		assert.Equal(t, 2, *calls)
+		assert.False(t, result.CachedAt.IsZero())
+		assert.Equal(t, "16.1.25", result.Version)
+		assert.Equal(t, "123-456-789", result.UUID)
+		assert.Equal(t, 2, result.ControlMode)
+		assert.Len(t, result.CertHashes, 1)
+	})
+	t.Run("adds values not cached yet", func(t *testing.T) {
+		cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+		collector, calls := countingCollector(cache)
+		collector.Collect(InfoRequest{UUID: true})
+		result := collector.Collect(InfoRequest{UUID: true, SKU: true})
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, "16392", result.SKU)
+		collector.Collect(InfoRequest{UUID: true, SKU: true})
+		assert.Equal(t, 2, *calls)
+	})
+	t.Run("reads the MEI again once the cache expired", func(t *testing.T) {
+		defer func() { now = time.Now }()
+		cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+		collector, calls := countingCollector(cache)
+		collector.Collect(InfoRequest{UUID: true})
+		now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		result := collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 2, *calls)
+		assert.True(t, result.CachedAt.IsZero())
+	})
+	t.Run("reads the MEI again with Refresh", func(t *testing.T) {
+		dir := t.TempDir()
+		collector, calls := countingCollector(&Cache{Dir: dir, TTL: time.Hour})
+		collector.Collect(InfoRequest{UUID: true})
+		collector.Cache = &Cache{Dir: dir, TTL: time.Hour, Refresh: true}
+		collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 2, *calls)
+		collector.Cache = &Cache{Dir: dir, TTL: time.Hour}
+		collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 2, *calls)
+	})
+	t.Run("does not cache failed queries", func(t *testing.T) {
+		cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+		failing := Collector{Cache: cache, NewAMTCommand: func() amt.Interface { return mockAMT{failing: map[string]bool{"uuid": true}} }}
+		failing.Collect(InfoRequest{UUID: true})
+		collector, calls := countingCollector(cache)
+		collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 1, *calls)
+	})
+	t.Run("ignores a modified cache file", func(t *testing.T) {
+		cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+		collector, calls := countingCollector(cache)
+		collector.Collect(InfoRequest{UUID: true})
+		path := filepath.Join(cache.Dir, cacheFileName)
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "123-456-789", "987-654-321", 1)), 0600))
+		result := collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, "123-456-789", result.UUID)
+	})
+}
@@ -0,0 +1,165 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"rpc/internal/amt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CertHashInfo is a certificate hash stored in AMT, flagged when the CA
+// is only identified by a deprecated hash algorithm
+type CertHashInfo struct {
+	amt.CertHashEntry
+	Deprecated bool `json:"deprecated"`
+}
+
+// NewCertHashInfos sorts the certificate hashes by name. With warnOnly only
+// the deprecated hashes are returned.
+func NewCertHashInfos(entries []amt.CertHashEntry, warnOnly bool) []CertHashInfo {
+	infos := []CertHashInfo{}
+	for _, entry := range entries {
+		info := CertHashInfo{
+			CertHashEntry: entry,
+			Deprecated:    entry.Algorithm == "SHA1" || entry.Algorithm == "MD5",
+		}
+		if warnOnly && !info.Deprecated {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// The SKU classes of the firmware, the reduced Standard Manageability SKU lacks KVM and
+// the management of the WiFi port
+const (
+	SKUClassAMT                   = "amt"
+	SKUClassStandardManageability = "ism"
+	// SKUClassNone is firmware with neither, it can not be activated
+	SKUClassNone = "none"
+)
+
+// AMTFeatures reports the firmware capabilities implied by the AMT version and SKU
+type AMTFeatures struct {
+	SKU           string `json:"sku"`
+	Manageability string `json:"manageability"`
+	// SKUClass is one of the SKUClass constants, empty when the version or SKU can not be decoded
+	SKUClass      string `json:"skuClass"`
+	KVMAvailable  bool   `json:"kvmAvailable"`
+	WiFiSupported bool   `json:"wifiSupported"`
+	TLSSupported  bool   `json:"tlsSupported"`
+	CIRASupported bool   `json:"ciraSupported"`
+}
+
+func DecodeAMTFeatures(version, SKU string) AMTFeatures {
+	features := AMTFeatures{SKU: strings.TrimSpace(DecodeAMT(version, SKU))}
+	amtParts := strings.Split(version, ".")
+	amtVer, err := strconv.ParseFloat(amtParts[0], 64)
+	if err != nil {
+		return features
+	}
+	skuNum, err := strconv.ParseInt(SKU, 0, 64)
+	if err != nil {
+		return features
+	}
+	isAMT := skuNum&0x08 > 0
+	isISM := amtVer >= 5.0 && skuNum&0x10 > 0
+	switch {
+	case isAMT:
+		features.Manageability, features.SKUClass = "AMT", SKUClassAMT
+	case isISM:
+		features.Manageability, features.SKUClass = "Standard Manageability", SKUClassStandardManageability
+	default:
+		features.SKUClass = SKUClassNone
+	}
+	// TLS arrived with AMT 3, CIRA with AMT 4 and KVM with AMT 6 (AMT SKU only)
+	features.TLSSupported = (isAMT || isISM) && amtVer >= 3.0
+	features.CIRASupported = (isAMT || isISM) && amtVer >= 4.0
+	features.KVMAvailable = isAMT && amtVer >= 6.0
+	features.WiFiSupported = isAMT
+	return features
+}
+
+// SKUClassName is the name of the SKU class shown by amtinfo, empty when it is not known
+func (f AMTFeatures) SKUClassName() string {
+	if f.SKUClass == SKUClassNone {
+		return "none"
+	}
+	return f.Manageability
+}
+
+func DecodeAMT(version, SKU string) string {
+	amtParts := strings.Split(version, ".")
+	if len(amtParts) <= 1 {
+		return "Invalid AMT version format"
+	}
+	amtVer, err := strconv.ParseFloat(amtParts[0], 64)
+	if err != nil {
+		return "Invalid AMT version"
+	}
+	skuNum, err := strconv.ParseInt(SKU, 0, 64)
+	if err != nil {
+		return "Invalid SKU"
+	}
+	result := ""
+	if amtVer <= 2.2 {
+		switch skuNum {
+		case 0:
+			result += "AMT + ASF + iQST"
+		case 1:
+			result += "ASF + iQST"
+		case 2:
+			result += "iQST"
+		default:
+			result += "Unknown"
+		}
+	} else if amtVer < 5.0 {
+		if skuNum&0x02 > 0 {
+			result += "iQST "
+		}
+		if skuNum&0x04 > 0 {
+			result += "ASF "
+		}
+		if skuNum&0x08 > 0 {
+			result += "AMT"
+		}
+	} else {
+		if skuNum&0x02 > 0 && amtVer < 7.0 {
+			result += "iQST "
+		}
+		if skuNum&0x04 > 0 && amtVer < 6.0 {
+			result += "ASF "
+		}
+		if skuNum&0x08 > 0 {
+			result += "AMT Pro "
+		}
+		if skuNum&0x10 > 0 {
+			result += "Intel Standard Manageability "
+		}
+		if skuNum&0x20 > 0 && amtVer < 6.0 {
+			result += "TPM "
+		}
+		if skuNum&0x100 > 0 && amtVer < 6.0 {
+			result += "Home IT "
+		}
+		if skuNum&0x400 > 0 && amtVer < 6.0 {
+			result += "WOX "
+		}
+		if skuNum&0x2000 > 0 {
+			result += "AT-p "
+		}
+		if skuNum&0x4000 > 0 {
+			result += "Corporate "
+		}
+		if skuNum&0x8000 > 0 && amtVer < 8.0 {
+			result += "L3 Mgt Upgrade"
+		}
+	}
+	return result
+}
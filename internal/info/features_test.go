@@ -0,0 +1,98 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"rpc/internal/amt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAMT(t *testing.T) {
+	testCases := []struct {
+		version string
+		SKU     string
+		want    string
+	}{
+		{"200", "0", "Invalid AMT version format"},
+		{"ab.c", "0", "Invalid AMT version"},
+		{"2.0.0", "0", "AMT + ASF + iQST"},
+		{"2.1.0", "1", "ASF + iQST"},
+		{"2.2.0", "2", "iQST"},
+		{"1.1.0", "3", "Unknown"},
+		{"3.0.0", "008", "Invalid SKU"},
+		{"3.0.0", "8", "AMT"},
+		{"4.1.0", "2", "iQST "},
+		{"4.0.0", "4", "ASF "},
+		{"5.0.0", "288", "TPM Home IT "},
+		{"5.0.0", "1088", "WOX "},
+		{"5.0.0", "38", "iQST ASF TPM "},
+		{"5.0.0", "4", "ASF "},
+		{"6.0.0", "2", "iQST "},
+		{"7.0.0", "36864", "L3 Mgt Upgrade"},
+		{"8.0.0", "24584", "AMT Pro AT-p Corporate "},
+		{"10.0.0", "8", "AMT Pro "},
+		{"11.0.0", "16392", "AMT Pro Corporate "},
+		{"15.0.42", "16392", "AMT Pro Corporate "},
+		{"16.1.25", "16400", "Intel Standard Manageability Corporate "},
+	}
+
+	for _, tc := range testCases {
+		got := DecodeAMT(tc.version, tc.SKU)
+		if got != tc.want {
+			t.Errorf("DecodeAMT(%q, %q) = %v; want %v", tc.version, tc.SKU, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeAMTFeatures(t *testing.T) {
+	testCases := []struct {
+		version string
+		SKU     string
+		want    AMTFeatures
+	}{
+		{"ab.c", "0", AMTFeatures{SKU: "Invalid AMT version"}},
+		{"16.1.25", "nope", AMTFeatures{SKU: "Invalid SKU"}},
+		{"3.0.0", "8", AMTFeatures{SKU: "AMT", Manageability: "AMT", SKUClass: SKUClassAMT, WiFiSupported: true, TLSSupported: true}},
+		{"5.0.0", "38", AMTFeatures{SKU: "iQST ASF TPM", SKUClass: SKUClassNone}},
+		{"15.0.42", "16392", AMTFeatures{SKU: "AMT Pro Corporate", Manageability: "AMT", SKUClass: SKUClassAMT, KVMAvailable: true, WiFiSupported: true, TLSSupported: true, CIRASupported: true}},
+		{"16.1.25", "16400", AMTFeatures{SKU: "Intel Standard Manageability Corporate", Manageability: "Standard Manageability", SKUClass: SKUClassStandardManageability, TLSSupported: true, CIRASupported: true}},
+	}
+	for _, tc := range testCases {
+		got := DecodeAMTFeatures(tc.version, tc.SKU)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestSKUClassName(t *testing.T) {
+	assert.Equal(t, "Standard Manageability", DecodeAMTFeatures("16.1.25", "16400").SKUClassName())
+	assert.Equal(t, "none", DecodeAMTFeatures("5.0.0", "38").SKUClassName())
+	assert.Equal(t, "", DecodeAMTFeatures("16.1.25", "nope").SKUClassName())
+}
+
+func TestNewCertHashInfos(t *testing.T) {
+	entries := []amt.CertHashEntry{
+		{Name: "VeriSign Class 3", Algorithm: "SHA1", Hash: "AA"},
+		{Name: "DigiCert Global Root", Algorithm: "SHA256", Hash: "BB", IsActive: true},
+		{Name: "Baltimore CyberTrust", Algorithm: "SHA256", Hash: "CC"},
+	}
+	t.Run("sorts by name and flags deprecated hashes", func(t *testing.T) {
+		infos := NewCertHashInfos(entries, false)
+		assert.Len(t, infos, 3)
+		assert.Equal(t, "Baltimore CyberTrust", infos[0].Name)
+		assert.Equal(t, "VeriSign Class 3", infos[2].Name)
+		assert.True(t, infos[2].Deprecated)
+		assert.False(t, infos[1].Deprecated)
+	})
+	t.Run("returns only deprecated hashes with warnOnly", func(t *testing.T) {
+		infos := NewCertHashInfos(entries, true)
+		assert.Len(t, infos, 1)
+		assert.Equal(t, "SHA1", infos[0].Algorithm)
+	})
+	t.Run("returns an empty list rather than nil", func(t *testing.T) {
+		assert.NotNil(t, NewCertHashInfos(nil, false))
+	})
+}
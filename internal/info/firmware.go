@@ -0,0 +1,65 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"errors"
+	"rpc/internal/amt"
+	"time"
+)
+
+// FirmwareInfo is the ME firmware version detail needed to check for patched firmware.
+// The build number alone does not tell the firmware branch or its security version.
+type FirmwareInfo struct {
+	Version     string `json:"version"`
+	BuildNumber string `json:"buildNumber"`
+	// the recovery version and the SVN, the security version number, are empty when the
+	// firmware does not report them
+	RecoveryVersion     string `json:"recoveryVersion,omitempty"`
+	RecoveryBuildNumber string `json:"recoveryBuildNumber,omitempty"`
+	SVN                 string `json:"svn,omitempty"`
+}
+
+// code versions reported by the ME firmware
+const (
+	codeVersionFlash         = "Flash"
+	codeVersionBuild         = "Build Number"
+	codeVersionRecovery      = "Recovery Version"
+	codeVersionRecoveryBuild = "Recovery Build Num"
+	codeVersionSVN           = "SVN"
+)
+
+// MEFirmware reads the firmware version detail from the code versions of the ME,
+// the reads retry while the MEI is not ready for amtTimeout in total
+func MEFirmware(cmd amt.Interface, amtTimeout time.Duration) (FirmwareInfo, error) {
+	deadline := time.Now().Add(amtTimeout)
+	fw := FirmwareInfo{}
+	for _, v := range []struct {
+		key      string
+		value    *string
+		optional bool
+	}{
+		{codeVersionFlash, &fw.Version, false},
+		{codeVersionBuild, &fw.BuildNumber, false},
+		{codeVersionRecovery, &fw.RecoveryVersion, true},
+		{codeVersionRecoveryBuild, &fw.RecoveryBuildNumber, true},
+		{codeVersionSVN, &fw.SVN, true},
+	} {
+		var err error
+		*v.value, err = cmd.GetVersionDataFromME(v.key, remaining(deadline))
+		if err != nil && !(v.optional && errors.Is(err, amt.ErrCodeVersionNotFound)) {
+			return fw, err
+		}
+	}
+	return fw, nil
+}
+
+// remaining is the time left until deadline, zero once it has passed
+func remaining(deadline time.Time) time.Duration {
+	if left := time.Until(deadline); left > 0 {
+		return left
+	}
+	return 0
+}
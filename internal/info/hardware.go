@@ -0,0 +1,137 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// HardwareInfo is the system identity reported by the SMBIOS tables of the host
+type HardwareInfo struct {
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	SerialNumber string `json:"serialNumber"`
+	AssetTag     string `json:"assetTag"`
+	CPU          string `json:"cpu"`
+	// MemoryMB is the size of the installed memory devices
+	MemoryMB uint64   `json:"memoryMB"`
+	BIOS     BIOSInfo `json:"bios"`
+}
+
+// BIOSInfo is the BIOS information structure of the SMBIOS tables
+type BIOSInfo struct {
+	Vendor      string `json:"vendor"`
+	Version     string `json:"version"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+// SMBIOS structure types read for the hardware inventory
+const (
+	smbiosBIOS         = 0
+	smbiosSystem       = 1
+	smbiosChassis      = 3
+	smbiosProcessor    = 4
+	smbiosMemoryDevice = 17
+	smbiosEndOfTable   = 127
+)
+
+// readSMBIOS returns the raw SMBIOS structure table, it is replaced in tests
+var readSMBIOS = smbiosTable
+
+// HostHardware reads the hardware inventory from the SMBIOS tables
+func HostHardware() (HardwareInfo, error) {
+	table, err := readSMBIOS()
+	if err != nil {
+		return HardwareInfo{}, err
+	}
+	return parseSMBIOS(table)
+}
+
+// parseSMBIOS decodes the BIOS, system, chassis, processor and memory device
+// structures. Only the first BIOS, system, chassis and processor are used.
+func parseSMBIOS(table []byte) (HardwareInfo, error) {
+	hw := HardwareInfo{}
+	found := false
+	for len(table) >= 4 {
+		structType, length := table[0], int(table[1])
+		if length < 4 || length > len(table) {
+			break
+		}
+		formatted := table[:length]
+		// the strings follow the formatted area and end with two NUL bytes
+		end := length
+		for end+1 < len(table) && (table[end] != 0 || table[end+1] != 0) {
+			end++
+		}
+		stringsArea := table[length:end]
+		str := func(offset int) string {
+			if offset >= len(formatted) {
+				return ""
+			}
+			return smbiosString(stringsArea, formatted[offset])
+		}
+		switch structType {
+		case smbiosBIOS:
+			if hw.BIOS.Vendor == "" && hw.BIOS.Version == "" {
+				hw.BIOS = BIOSInfo{Vendor: str(0x04), Version: str(0x05), ReleaseDate: str(0x08)}
+			}
+		case smbiosSystem:
+			if hw.Manufacturer == "" && hw.Model == "" {
+				hw.Manufacturer, hw.Model, hw.SerialNumber = str(0x04), str(0x05), str(0x07)
+				found = true
+			}
+		case smbiosChassis:
+			if hw.AssetTag == "" {
+				hw.AssetTag = str(0x08)
+			}
+		case smbiosProcessor:
+			if hw.CPU == "" {
+				hw.CPU = str(0x10)
+			}
+		case smbiosMemoryDevice:
+			hw.MemoryMB += memoryDeviceSize(formatted)
+		}
+		if structType == smbiosEndOfTable || end+2 > len(table) {
+			break
+		}
+		table = table[end+2:]
+	}
+	if !found {
+		return hw, errors.New("SMBIOS system information not found")
+	}
+	return hw, nil
+}
+
+// smbiosString returns the string with the 1 based index from the strings area
+func smbiosString(area []byte, index byte) string {
+	if index == 0 {
+		return ""
+	}
+	values := strings.Split(string(area), "\x00")
+	if int(index) > len(values) {
+		return ""
+	}
+	return strings.TrimSpace(values[index-1])
+}
+
+// memoryDeviceSize returns the size in MB of a memory device structure, 0 when no module is installed
+func memoryDeviceSize(formatted []byte) uint64 {
+	if len(formatted) < 0x0E {
+		return 0
+	}
+	size := binary.LittleEndian.Uint16(formatted[0x0C:])
+	switch {
+	case size == 0 || size == 0xFFFF:
+		return 0
+	case size == 0x7FFF && len(formatted) >= 0x20:
+		return uint64(binary.LittleEndian.Uint32(formatted[0x1C:]) & 0x7FFFFFFF)
+	case size&0x8000 != 0:
+		// the size is in KB
+		return uint64(size&0x7FFF) / 1024
+	}
+	return uint64(size)
+}
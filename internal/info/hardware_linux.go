@@ -0,0 +1,17 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import "os"
+
+// smbiosTablePath is the raw SMBIOS table exported by the kernel, it is readable by root only
+var smbiosTablePath = "/sys/firmware/dmi/tables/DMI"
+
+func smbiosTable() ([]byte, error) {
+	return os.ReadFile(smbiosTablePath)
+}
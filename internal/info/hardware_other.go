@@ -0,0 +1,14 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import "errors"
+
+func smbiosTable() ([]byte, error) {
+	return nil, errors.New("reading the SMBIOS table is not supported on this OS")
+}
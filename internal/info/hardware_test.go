@@ -0,0 +1,97 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smbiosStructure assembles a structure from its formatted area, without the
+// 4 byte header, and its strings
+func smbiosStructure(structType byte, formatted []byte, values ...string) []byte {
+	s := []byte{structType, byte(4 + len(formatted)), 0, 0}
+	s = append(s, formatted...)
+	for _, v := range values {
+		s = append(append(s, v...), 0)
+	}
+	if len(values) == 0 {
+		s = append(s, 0)
+	}
+	return append(s, 0)
+}
+
+func memoryDevice(size uint16, extended uint32) []byte {
+	formatted := make([]byte, 0x20-4)
+	binary.LittleEndian.PutUint16(formatted[0x0C-4:], size)
+	binary.LittleEndian.PutUint32(formatted[0x1C-4:], extended)
+	return smbiosStructure(smbiosMemoryDevice, formatted)
+}
+
+func testSMBIOSTable() []byte {
+	system := make([]byte, 0x1B-4)
+	system[0x04-4], system[0x05-4], system[0x07-4] = 1, 2, 3
+	chassis := make([]byte, 0x09-4)
+	chassis[0x04-4], chassis[0x08-4] = 1, 2
+	processor := make([]byte, 0x1A-4)
+	processor[0x10-4] = 1
+	bios := make([]byte, 0x18-4)
+	bios[0x04-4], bios[0x05-4], bios[0x08-4] = 1, 2, 3
+	var table []byte
+	table = append(table, smbiosStructure(smbiosBIOS, bios, "Intel Corp.", "ANRPL357.0027.2023.0607.1754", "06/07/2023")...)
+	table = append(table, smbiosStructure(smbiosSystem, system, "Intel Corporation", "NUC13ANHi7 ", "G6AN1234")...)
+	table = append(table, smbiosStructure(smbiosChassis, chassis, "Intel Corporation", "ASSET-0042")...)
+	table = append(table, smbiosStructure(smbiosProcessor, processor, "13th Gen Intel(R) Core(TM) i7-1360P")...)
+	table = append(table, memoryDevice(16384, 0)...)
+	table = append(table, memoryDevice(0x7FFF, 65536)...)
+	table = append(table, memoryDevice(0, 0)...)
+	table = append(table, memoryDevice(0x8000|2048, 0)...)
+	return append(table, smbiosStructure(smbiosEndOfTable, nil)...)
+}
+
+func TestParseSMBIOS(t *testing.T) {
+	t.Run("decodes the system identity", func(t *testing.T) {
+		hw, err := parseSMBIOS(testSMBIOSTable())
+		assert.NoError(t, err)
+		assert.Equal(t, HardwareInfo{
+			Manufacturer: "Intel Corporation",
+			Model:        "NUC13ANHi7",
+			SerialNumber: "G6AN1234",
+			AssetTag:     "ASSET-0042",
+			CPU:          "13th Gen Intel(R) Core(TM) i7-1360P",
+			MemoryMB:     16384 + 65536 + 2,
+			BIOS: BIOSInfo{
+				Vendor:      "Intel Corp.",
+				Version:     "ANRPL357.0027.2023.0607.1754",
+				ReleaseDate: "06/07/2023",
+			},
+		}, hw)
+	})
+	t.Run("fails without system information", func(t *testing.T) {
+		_, err := parseSMBIOS(smbiosStructure(smbiosEndOfTable, nil))
+		assert.Error(t, err)
+	})
+	t.Run("stops at a truncated structure", func(t *testing.T) {
+		table := testSMBIOSTable()
+		_, err := parseSMBIOS(table[:30])
+		assert.Error(t, err)
+	})
+}
+
+func TestHostHardware(t *testing.T) {
+	orig := readSMBIOS
+	defer func() { readSMBIOS = orig }()
+	readSMBIOS = func() ([]byte, error) { return testSMBIOSTable(), nil }
+	hw, err := HostHardware()
+	assert.NoError(t, err)
+	assert.Equal(t, "ASSET-0042", hw.AssetTag)
+
+	readSMBIOS = func() ([]byte, error) { return nil, errors.New("permission denied") }
+	_, err = HostHardware()
+	assert.Error(t, err)
+}
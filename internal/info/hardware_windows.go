@@ -0,0 +1,45 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"encoding/binary"
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procGetSystemFirmwareTable = modkernel32.NewProc("GetSystemFirmwareTable")
+)
+
+// rsmb is the firmware table provider of the raw SMBIOS data
+const rsmb = 'R'<<24 | 'S'<<16 | 'M'<<8 | 'B'
+
+// rawSMBIOSHeader is the size of the RawSMBIOSData fields ahead of the table
+const rawSMBIOSHeader = 8
+
+func smbiosTable() ([]byte, error) {
+	size, _, err := procGetSystemFirmwareTable.Call(rsmb, 0, 0, 0)
+	if size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if n, _, err := procGetSystemFirmwareTable.Call(rsmb, 0, uintptr(unsafe.Pointer(&buf[0])), size); n == 0 {
+		return nil, err
+	}
+	if len(buf) < rawSMBIOSHeader {
+		return nil, errors.New("SMBIOS table is too short")
+	}
+	table := buf[rawSMBIOSHeader:]
+	if length := binary.LittleEndian.Uint32(buf[4:]); int(length) < len(table) {
+		table = table[:length]
+	}
+	return table, nil
+}
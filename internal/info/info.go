@@ -0,0 +1,343 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package info collects the device information read through the MEI and from
+// the host OS. It backs amtinfo, the payload sent to the server for activation
+// and maintenance, and the library API.
+package info
+
+import (
+	"net"
+	"os"
+	"rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/internal/logging"
+	"sync"
+	"time"
+)
+
+var log = logging.For(logging.ModuleInfo)
+
+// Query names a value read by the Collector, failed queries are reported by name
+type Query string
+
+const (
+	QueryVersion    Query = "version"
+	QueryBuild      Query = "build"
+	QuerySKU        Query = "sku"
+	QueryUUID       Query = "uuid"
+	QueryMode       Query = "mode"
+	QueryOpState    Query = "opstate"
+	QueryDNS        Query = "dns"
+	QueryDNSOS      Query = "dnsOS"
+	QueryHostname   Query = "hostname"
+	QueryRAS        Query = "ras"
+	QueryWired      Query = "wired"
+	QueryWireless   Query = "wireless"
+	QueryCertHashes Query = "certHashes"
+	QueryHardware   Query = "hardware"
+	QueryFirmware   Query = "firmware"
+	QuerySystem     Query = "system"
+	QueryModes      Query = "modes"
+)
+
+// InfoRequest selects the values to collect
+type InfoRequest struct {
+	Version bool
+	Build   bool
+	SKU     bool
+	UUID    bool
+	Mode    bool
+	OpState bool
+	// DNS reads the DNS suffix of AMT and of the host OS
+	DNS      bool
+	Hostname bool
+	RAS      bool
+	// LAN reads the wired and wireless settings of AMT with the IPv6 addresses of the host
+	LAN        bool
+	CertHashes bool
+	// Hardware reads the system identity from the SMBIOS tables of the host
+	Hardware bool
+	// BIOS reads the BIOS from the SMBIOS tables, into Hardware, and the ME firmware versions
+	BIOS bool
+	// System reads the host OS version, the rpc version and whether the WS-MAN interface
+	// of the Target of the Collector accepts connections
+	System bool
+	// Modes reads the ChangeEnabled state, remote configuration and provisioning TLS mode
+	// that DecodeProvisioningModes decides the allowed control modes with
+	Modes bool
+	// AMTTimeout is how long the version queries retry while the MEI is not ready,
+	// all of them together, a query that starts late gets what is left of it
+	AMTTimeout time.Duration
+}
+
+// InfoResult holds the values selected in the InfoRequest
+type InfoResult struct {
+	Version     string
+	BuildNumber string
+	SKU         string
+	UUID        string
+	ControlMode int
+	OpState     amt.OperationalState
+	DNSSuffix   string
+	DNSSuffixOS string
+	HostnameOS  string
+	RAS         amt.RemoteAccessStatus
+	Wired       amt.InterfaceSettings
+	Wireless    amt.InterfaceSettings
+	CertHashes  []amt.CertHashEntry
+	Hardware    HardwareInfo
+	Firmware    FirmwareInfo
+	System      SystemInfo
+	// ChangeEnabled, RemoteConfiguration and ProvisioningTLSMode are read with Modes
+	ChangeEnabled       amt.ChangeEnabled
+	RemoteConfiguration bool
+	ProvisioningTLSMode string
+	// CachedAt is when the values read from the Cache of the Collector were read from
+	// the MEI, it is zero when no value was read from the cache
+	CachedAt time.Time
+	// Errors holds the queries that failed
+	Errors map[Query]error
+}
+
+// Err returns the error of the first failed query in the order given
+func (r InfoResult) Err(queries ...Query) error {
+	for _, query := range queries {
+		if err := r.Errors[query]; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Collector runs the queries of an InfoRequest
+type Collector struct {
+	// NewAMTCommand returns the command of the MEI queries, they run one after
+	// the other on it as the MEI serves one PTHI client at a time
+	NewAMTCommand func() amt.Interface
+	// Workers bounds the host queries in flight, the MEI queries count as one
+	// of them, 1 runs everything one after the other
+	Workers int
+	// Cache, when set, keeps the values of the static MEI queries between runs
+	Cache *Cache
+	// Target is the WS-MAN interface the System query checks
+	Target Target
+}
+
+// Collect runs the selected queries. A failed query leaves its value empty
+// and is recorded in the Errors of the result.
+func (c Collector) Collect(req InfoRequest) InfoResult {
+	result := InfoResult{Errors: map[Query]error{}}
+	var cached *cacheEntry
+	if c.Cache != nil {
+		cached = c.Cache.load()
+		cached.apply(&req, &result)
+	}
+	deadline := time.Now().Add(req.AMTTimeout)
+	var mu sync.Mutex
+	record := func(query Query, err error) {
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		result.Errors[query] = err
+		mu.Unlock()
+	}
+	// the MEI queries share one connection and run one after the other, the
+	// host queries run alongside them
+	var meiTasks []func(cmd amt.Interface)
+	var hostTasks []func()
+	if req.Version {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.Version, err = cmd.GetVersionDataFromME("AMT", remaining(deadline))
+			record(QueryVersion, err)
+		})
+	}
+	if req.Build {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.BuildNumber, err = cmd.GetVersionDataFromME("Build Number", remaining(deadline))
+			record(QueryBuild, err)
+		})
+	}
+	if req.SKU {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.SKU, err = cmd.GetVersionDataFromME("Sku", remaining(deadline))
+			record(QuerySKU, err)
+		})
+	}
+	if req.UUID {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.UUID, err = cmd.GetUUID()
+			record(QueryUUID, err)
+		})
+	}
+	if req.Mode {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.ControlMode, err = cmd.GetControlMode()
+			record(QueryMode, err)
+		})
+	}
+	if req.OpState {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.OpState, err = cmd.GetOperationalState()
+			record(QueryOpState, err)
+		})
+	}
+	if req.DNS {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.DNSSuffix, err = cmd.GetDNSSuffix()
+			record(QueryDNS, err)
+			result.DNSSuffixOS, err = cmd.GetOSDNSSuffix()
+			record(QueryDNSOS, err)
+		})
+	}
+	if req.Hostname {
+		hostTasks = append(hostTasks, func() {
+			var err error
+			result.HostnameOS, err = os.Hostname()
+			record(QueryHostname, err)
+		})
+	}
+	if req.RAS {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.RAS, err = cmd.GetRemoteAccessConnectionStatus()
+			record(QueryRAS, err)
+		})
+	}
+	if req.LAN {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.Wired, err = cmd.GetLANInterfaceSettings(false)
+			record(QueryWired, err)
+			result.Wired.IPv6Addresses = hostIPv6Addresses(result.Wired.MACAddress)
+		}, func(cmd amt.Interface) {
+			var err error
+			result.Wireless, err = cmd.GetLANInterfaceSettings(true)
+			record(QueryWireless, err)
+			result.Wireless.IPv6Addresses = hostIPv6Addresses(result.Wireless.MACAddress)
+		})
+	}
+	if req.CertHashes {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.CertHashes, err = cmd.GetCertificateHashes()
+			record(QueryCertHashes, err)
+		})
+	}
+	if req.Hardware || req.BIOS {
+		hostTasks = append(hostTasks, func() {
+			var err error
+			result.Hardware, err = HostHardware()
+			record(QueryHardware, err)
+		})
+	}
+	if req.BIOS {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			result.Firmware, err = MEFirmware(cmd, remaining(deadline))
+			record(QueryFirmware, err)
+		})
+	}
+	if req.System {
+		hostTasks = append(hostTasks, func() {
+			var err error
+			result.System, err = HostSystem(c.Target)
+			record(QuerySystem, err)
+		})
+	}
+	if req.Modes {
+		meiTasks = append(meiTasks, func(cmd amt.Interface) {
+			var err error
+			if result.ChangeEnabled, err = cmd.GetChangeEnabled(); err != nil {
+				record(QueryModes, err)
+				return
+			}
+			if result.RemoteConfiguration, err = cmd.GetZeroTouchEnabled(); err != nil {
+				record(QueryModes, err)
+				return
+			}
+			result.ProvisioningTLSMode, err = cmd.GetProvisioningTLSMode()
+			record(QueryModes, err)
+		})
+	}
+	if len(meiTasks) > 0 {
+		hostTasks = append(hostTasks, func() {
+			cmd := c.NewAMTCommand()
+			for _, task := range meiTasks {
+				task(cmd)
+			}
+		})
+	}
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	RunConcurrently(workers, hostTasks)
+	if cached != nil && cached.update(req, &result) {
+		if err := c.Cache.save(cached); err != nil {
+			log.Debug("unable to save the amtinfo cache: ", err)
+		}
+	}
+	return result
+}
+
+// RunConcurrently runs the tasks with at most limit of them in flight and waits for all to finish
+func RunConcurrently(limit int, tasks []func()) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for _, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(task func()) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			task()
+		}(task)
+	}
+	wg.Wait()
+}
+
+// HostNet enumerates the host interfaces, it is replaced in tests
+var HostNet = flags.NetEnumerator{
+	Interfaces:     net.Interfaces,
+	InterfaceAddrs: (*net.Interface).Addrs,
+}
+
+// hostIPv6Addresses returns the IPv6 addresses of the host interface with the MAC
+// address of the AMT interface. The PTHI LAN settings only report IPv4.
+func hostIPv6Addresses(mac string) []string {
+	if mac == "" || mac == "00:00:00:00:00:00" {
+		return nil
+	}
+	ifaces, err := HostNet.Interfaces()
+	if err != nil {
+		log.Debug("unable to enumerate host interfaces: ", err)
+		return nil
+	}
+	var addresses []string
+	for i := range ifaces {
+		if ifaces[i].HardwareAddr.String() != mac {
+			continue
+		}
+		addrs, err := HostNet.InterfaceAddrs(&ifaces[i])
+		if err != nil {
+			continue
+		}
+		for _, address := range addrs {
+			if ipnet, ok := address.(*net.IPNet); ok && ipnet.IP.To4() == nil && !ipnet.IP.IsLoopback() {
+				addresses = append(addresses, ipnet.String())
+			}
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,273 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"rpc/internal/amt"
+	"rpc/internal/flags"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAMT answers every query with fixed values, a query listed in
+// failing returns errMock instead
+type mockAMT struct {
+	failing map[string]bool
+}
+
+var errMock = errors.New("yep, it failed")
+
+func (m mockAMT) err(query string) error {
+	if m.failing[query] {
+		return errMock
+	}
+	return nil
+}
+
+func (m mockAMT) Initialize() error { return nil }
+
+func (m mockAMT) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
+	values := map[string]string{"AMT": "16.1.25", "Build Number": "2049", "Sku": "16392",
+		"Flash": "16.1.25", "Recovery Version": "16.1.25", "Recovery Build Num": "2049"}
+	return values[key], m.err(key)
+}
+
+func (m mockAMT) GetUUID() (string, error) { return "123-456-789", m.err("uuid") }
+
+func (m mockAMT) GetControlMode() (int, error) { return 2, m.err("mode") }
+
+func (m mockAMT) GetOperationalState() (amt.OperationalState, error) {
+	return amt.OperationalState{AMTEnabled: true}, m.err("opstate")
+}
+
+func (m mockAMT) GetOSDNSSuffix() (string, error) { return "os.dns.org", m.err("dnsOS") }
+
+func (m mockAMT) GetOSDNSServers() ([]string, error) { return nil, nil }
+
+func (m mockAMT) GetDNSSuffix() (string, error) { return "dns.org", m.err("dns") }
+
+func (m mockAMT) GetCertificateHashes() ([]amt.CertHashEntry, error) {
+	return []amt.CertHashEntry{{Name: "Cert 01", Algorithm: "SHA256", Hash: "AA"}}, m.err("certHashes")
+}
+
+func (m mockAMT) GetRemoteAccessConnectionStatus() (amt.RemoteAccessStatus, error) {
+	return amt.RemoteAccessStatus{NetworkStatus: "direct"}, m.err("ras")
+}
+
+func (m mockAMT) GetLANInterfaceSettings(useWireless bool) (amt.InterfaceSettings, error) {
+	if useWireless {
+		return amt.InterfaceSettings{MACAddress: "00:00:00:00:00:00"}, m.err("wireless")
+	}
+	return amt.InterfaceSettings{MACAddress: "0a:0b:0c:0d:0e:0f", LinkStatus: "up"}, m.err("wired")
+}
+
+func (m mockAMT) GetLocalSystemAccount() (amt.LocalSystemAccount, error) {
+	return amt.LocalSystemAccount{}, nil
+}
+
+func (m mockAMT) Unprovision() (int, error) { return 0, nil }
+
+func (m mockAMT) SetDNSSuffix(suffix string) (int, error) { return 0, nil }
+
+func (m mockAMT) GetChangeEnabled() (amt.ChangeEnabled, error) { return amt.ChangeEnabled{}, nil }
+
+func (m mockAMT) GetZeroTouchEnabled() (bool, error) { return true, nil }
+
+func (m mockAMT) GetProvisioningTLSMode() (string, error) { return "PKI", nil }
+
+func (m mockAMT) SetAMTEnabled(enabled bool) (int, error) { return 0, nil }
+
+func TestCollect(t *testing.T) {
+	all := InfoRequest{Version: true, Build: true, SKU: true, UUID: true, Mode: true, OpState: true,
+		DNS: true, Hostname: true, RAS: true, LAN: true, CertHashes: true}
+	t.Run("collects the selected values", func(t *testing.T) {
+		collector := Collector{NewAMTCommand: func() amt.Interface { return mockAMT{} }, Workers: 4}
+		result := collector.Collect(all)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, "16.1.25", result.Version)
+		assert.Equal(t, "2049", result.BuildNumber)
+		assert.Equal(t, "16392", result.SKU)
+		assert.Equal(t, "123-456-789", result.UUID)
+		assert.Equal(t, 2, result.ControlMode)
+		assert.True(t, result.OpState.AMTEnabled)
+		assert.Equal(t, "dns.org", result.DNSSuffix)
+		assert.Equal(t, "os.dns.org", result.DNSSuffixOS)
+		assert.NotEmpty(t, result.HostnameOS)
+		assert.Equal(t, "direct", result.RAS.NetworkStatus)
+		assert.Equal(t, "up", result.Wired.LinkStatus)
+		assert.Len(t, result.CertHashes, 1)
+	})
+	t.Run("collects the firmware versions with -bios", func(t *testing.T) {
+		collector := Collector{NewAMTCommand: func() amt.Interface { return mockAMT{} }, Workers: 2}
+		result := collector.Collect(InfoRequest{BIOS: true})
+		assert.NoError(t, result.Err(QueryFirmware))
+		assert.Equal(t, FirmwareInfo{Version: "16.1.25", BuildNumber: "2049", RecoveryVersion: "16.1.25", RecoveryBuildNumber: "2049"}, result.Firmware)
+	})
+	t.Run("skips the values not selected", func(t *testing.T) {
+		calls := 0
+		var mu sync.Mutex
+		collector := Collector{NewAMTCommand: func() amt.Interface {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return mockAMT{}
+		}}
+		result := collector.Collect(InfoRequest{UUID: true})
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "123-456-789", result.UUID)
+		assert.Empty(t, result.Version)
+	})
+	t.Run("runs the MEI queries one after the other on one command", func(t *testing.T) {
+		var timeouts []time.Duration
+		calls, inFlight, maxInFlight := 0, 0, 0
+		var mu sync.Mutex
+		slow := slowVersionAMT{mu: &sync.Mutex{}, timeouts: &timeouts, started: func() {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+		}, finished: func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}}
+		collector := Collector{NewAMTCommand: func() amt.Interface {
+			calls++
+			return slow
+		}, Workers: 4}
+		result := collector.Collect(InfoRequest{Version: true, Build: true, SKU: true, BIOS: true, Hostname: true})
+		assert.NoError(t, result.Err(QueryVersion, QueryBuild, QuerySKU, QueryFirmware, QueryHostname))
+		assert.Equal(t, 1, calls)
+		assert.Len(t, timeouts, 8)
+		assert.Equal(t, 1, maxInFlight)
+	})
+	t.Run("records the failed queries", func(t *testing.T) {
+		failing := mockAMT{failing: map[string]bool{"Sku": true, "wired": true}}
+		collector := Collector{NewAMTCommand: func() amt.Interface { return failing }, Workers: 1}
+		result := collector.Collect(all)
+		assert.Len(t, result.Errors, 2)
+		assert.Equal(t, errMock, result.Errors[QuerySKU])
+		assert.Equal(t, errMock, result.Errors[QueryWired])
+		assert.NoError(t, result.Err(QueryVersion, QueryUUID))
+		assert.Equal(t, errMock, result.Err(QueryVersion, QuerySKU))
+		assert.Equal(t, "16.1.25", result.Version)
+	})
+}
+
+// slowVersionAMT takes a while for each version query and records the
+// timeout it was given, started and finished are called around the query
+// when set
+type slowVersionAMT struct {
+	mockAMT
+	mu       *sync.Mutex
+	timeouts *[]time.Duration
+	started  func()
+	finished func()
+}
+
+func (m slowVersionAMT) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
+	if m.started != nil {
+		m.started()
+		defer m.finished()
+	}
+	m.mu.Lock()
+	*m.timeouts = append(*m.timeouts, amtTimeout)
+	m.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	return m.mockAMT.GetVersionDataFromME(key, amtTimeout)
+}
+
+func TestCollectSharesTheAMTTimeout(t *testing.T) {
+	var timeouts []time.Duration
+	slow := slowVersionAMT{mu: &sync.Mutex{}, timeouts: &timeouts}
+	collector := Collector{NewAMTCommand: func() amt.Interface { return slow }, Workers: 1}
+	result := collector.Collect(InfoRequest{Version: true, Build: true, SKU: true, AMTTimeout: 30 * time.Millisecond})
+	assert.Empty(t, result.Errors)
+	assert.Len(t, timeouts, 3)
+	assert.LessOrEqual(t, timeouts[0], 30*time.Millisecond)
+	assert.Less(t, timeouts[1], timeouts[0])
+	assert.Equal(t, time.Duration(0), timeouts[2])
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, done := 0, 0, 0
+	var tasks []func()
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, func() {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			done++
+			mu.Unlock()
+		})
+	}
+	RunConcurrently(3, tasks)
+	assert.Equal(t, 10, done)
+	assert.LessOrEqual(t, maxInFlight, 3)
+}
+
+func TestHostIPv6Addresses(t *testing.T) {
+	orig := HostNet
+	defer func() { HostNet = orig }()
+	HostNet = flags.NetEnumerator{
+		Interfaces: func() ([]net.Interface, error) {
+			return []net.Interface{{Name: "eth0", HardwareAddr: net.HardwareAddr{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}}}, nil
+		},
+		InterfaceAddrs: func(i *net.Interface) ([]net.Addr, error) {
+			return []net.Addr{
+				&net.IPNet{IP: net.ParseIP("192.168.1.7"), Mask: net.CIDRMask(24, 32)},
+				&net.IPNet{IP: net.ParseIP("2001:db8::7"), Mask: net.CIDRMask(64, 128)},
+			}, nil
+		},
+	}
+	assert.Equal(t, []string{"2001:db8::7/64"}, hostIPv6Addresses("0a:0b:0c:0d:0e:0f"))
+	assert.Nil(t, hostIPv6Addresses("01:02:03:04:05:06"))
+	assert.Nil(t, hostIPv6Addresses("00:00:00:00:00:00"))
+}
+
+// svnlessAMT is firmware that does not report its SVN and, with noRecovery, its recovery version
+type svnlessAMT struct {
+	mockAMT
+	noRecovery bool
+}
+
+func (m svnlessAMT) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
+	if key == codeVersionSVN || (m.noRecovery && (key == codeVersionRecovery || key == codeVersionRecoveryBuild)) {
+		return "", fmt.Errorf("%w: %s", amt.ErrCodeVersionNotFound, key)
+	}
+	return m.mockAMT.GetVersionDataFromME(key, amtTimeout)
+}
+
+func TestMEFirmware(t *testing.T) {
+	fw, err := MEFirmware(svnlessAMT{}, time.Second)
+	assert.NoError(t, err, "older firmware does not report the SVN")
+	assert.Equal(t, "16.1.25", fw.Version)
+	assert.Empty(t, fw.SVN)
+
+	fw, err = MEFirmware(svnlessAMT{noRecovery: true}, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, FirmwareInfo{Version: "16.1.25", BuildNumber: "2049"}, fw)
+	data, _ := json.Marshal(fw)
+	assert.JSONEq(t, `{"version":"16.1.25","buildNumber":"2049"}`, string(data), "what is not reported is left out")
+
+	_, err = MEFirmware(mockAMT{failing: map[string]bool{codeVersionSVN: true}}, time.Second)
+	assert.ErrorIs(t, err, errMock, "other errors of the SVN read fail")
+}
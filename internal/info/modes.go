@@ -0,0 +1,86 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+// ProvisioningModes reports the control modes the firmware settings let the device be
+// activated in, so a provisioning server can pick the profile before activating
+type ProvisioningModes struct {
+	// AMTEnabled, TransitionAllowed and NewInterfaceVersion are the ChangeEnabled state
+	AMTEnabled          bool `json:"amtEnabled"`
+	TransitionAllowed   bool `json:"transitionAllowed"`
+	NewInterfaceVersion bool `json:"newInterfaceVersion"`
+	// RemoteConfiguration is the zero touch setting of the MEBx, ACM activation with a
+	// provisioning certificate needs it
+	RemoteConfiguration bool `json:"remoteConfiguration"`
+	// ProvisioningTLSMode is PKI or PSK, the TLS the firmware provisions with
+	ProvisioningTLSMode string `json:"provisioningTLSMode"`
+	// ActiveCertHashes is the size of the allowlist of trusted root hashes a provisioning
+	// certificate must chain to
+	ActiveCertHashes int  `json:"activeCertHashes"`
+	CCMAllowed       bool `json:"ccmAllowed"`
+	ACMAllowed       bool `json:"acmAllowed"`
+	// CCMReasons and ACMReasons tell why the mode is not allowed
+	CCMReasons []string `json:"ccmReasons,omitempty"`
+	ACMReasons []string `json:"acmReasons,omitempty"`
+}
+
+// hostBasedSetupVersion is the first AMT version activated from the OS, in CCM or ACM
+const hostBasedSetupVersion = 7
+
+// DecodeProvisioningModes decides from the values collected with the Modes, Version, SKU,
+// Mode and CertHashes queries whether the device can be activated in CCM and ACM. A
+// device in CCM is allowed ACM when it can be upgraded.
+func DecodeProvisioningModes(r InfoResult) ProvisioningModes {
+	modes := ProvisioningModes{
+		AMTEnabled:          r.ChangeEnabled.AMTEnabled,
+		TransitionAllowed:   r.ChangeEnabled.TransitionAllowed,
+		NewInterfaceVersion: r.ChangeEnabled.NewInterfaceVersion,
+		RemoteConfiguration: r.RemoteConfiguration,
+		ProvisioningTLSMode: r.ProvisioningTLSMode,
+	}
+	for _, hash := range r.CertHashes {
+		if hash.IsActive {
+			modes.ActiveCertHashes++
+		}
+	}
+	var reasons []string
+	if DecodeAMTFeatures(r.Version, r.SKU).Manageability == "" {
+		reasons = append(reasons, "the firmware has no AMT or Standard Manageability")
+	}
+	if major, err := strconv.Atoi(strings.Split(r.Version, ".")[0]); err == nil && major < hostBasedSetupVersion {
+		reasons = append(reasons, "AMT "+r.Version+" can not be activated from the OS")
+	}
+	if !modes.AMTEnabled && !modes.TransitionAllowed {
+		reasons = append(reasons, "AMT is disabled in the MEBx and the BIOS does not let the OS enable it")
+	}
+	modes.CCMReasons = append([]string(nil), reasons...)
+	modes.ACMReasons = append([]string(nil), reasons...)
+	if r.ControlMode != 0 {
+		modes.CCMReasons = append(modes.CCMReasons, "the device is "+utils.InterpretControlMode(r.ControlMode))
+	}
+	if r.ControlMode > 1 {
+		modes.ACMReasons = append(modes.ACMReasons, "the device is "+utils.InterpretControlMode(r.ControlMode))
+	}
+	if !modes.RemoteConfiguration {
+		modes.ACMReasons = append(modes.ACMReasons, "remote configuration is disabled in the MEBx")
+	}
+	if modes.ActiveCertHashes == 0 {
+		modes.ACMReasons = append(modes.ACMReasons, "no trusted root hash is active")
+	}
+	modes.CCMAllowed = len(modes.CCMReasons) == 0
+	modes.ACMAllowed = len(modes.ACMReasons) == 0
+	return modes
+}
+
+// ProvisioningModesErr returns the error of the first query DecodeProvisioningModes needs
+func (r InfoResult) ProvisioningModesErr() error {
+	return r.Err(QueryModes, QueryVersion, QuerySKU, QueryMode, QueryCertHashes)
+}
@@ -0,0 +1,62 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"errors"
+	"rpc/internal/amt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeProvisioningModes(t *testing.T) {
+	ready := InfoResult{
+		Version:             "16.1.25",
+		SKU:                 "16392",
+		ChangeEnabled:       amt.ChangeEnabled{AMTEnabled: true, TransitionAllowed: true, NewInterfaceVersion: true},
+		RemoteConfiguration: true,
+		ProvisioningTLSMode: "PKI",
+		CertHashes:          []amt.CertHashEntry{{Name: "root", IsActive: true}, {Name: "inactive"}},
+	}
+	tests := map[string]struct {
+		result  func(*InfoResult)
+		wantCCM bool
+		wantACM bool
+		reason  string
+	}{
+		"both modes allowed":             {wantCCM: true, wantACM: true},
+		"remote configuration off":       {result: func(r *InfoResult) { r.RemoteConfiguration = false }, wantCCM: true, reason: "remote configuration is disabled in the MEBx"},
+		"no active root hash":            {result: func(r *InfoResult) { r.CertHashes = nil }, wantCCM: true, reason: "no trusted root hash is active"},
+		"CCM device can upgrade":         {result: func(r *InfoResult) { r.ControlMode = 1 }, wantACM: true},
+		"ACM device":                     {result: func(r *InfoResult) { r.ControlMode = 2 }, reason: "the device is activated in admin control mode"},
+		"AMT disabled and locked":        {result: func(r *InfoResult) { r.ChangeEnabled = amt.ChangeEnabled{} }, reason: "AMT is disabled in the MEBx"},
+		"AMT disabled, OS may enable":    {result: func(r *InfoResult) { r.ChangeEnabled = amt.ChangeEnabled{TransitionAllowed: true} }, wantCCM: true, wantACM: true},
+		"firmware before host setup":     {result: func(r *InfoResult) { r.Version = "6.2.0" }, reason: "can not be activated from the OS"},
+		"firmware without manageability": {result: func(r *InfoResult) { r.SKU = "0" }, reason: "no AMT or Standard Manageability"},
+	}
+	assert.Equal(t, 1, DecodeProvisioningModes(ready).ActiveCertHashes)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := ready
+			if tc.result != nil {
+				tc.result(&result)
+			}
+			modes := DecodeProvisioningModes(result)
+			assert.Equal(t, tc.wantCCM, modes.CCMAllowed)
+			assert.Equal(t, tc.wantACM, modes.ACMAllowed)
+			if tc.reason != "" {
+				assert.Contains(t, strings.Join(append(modes.CCMReasons, modes.ACMReasons...), "\n"), tc.reason)
+			}
+		})
+	}
+}
+
+func TestProvisioningModesErr(t *testing.T) {
+	err := errors.New("MEI not ready")
+	assert.NoError(t, InfoResult{}.ProvisioningModesErr())
+	assert.Equal(t, err, InfoResult{Errors: map[Query]error{QuerySKU: err}}.ProvisioningModesErr())
+}
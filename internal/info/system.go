@@ -0,0 +1,107 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"bufio"
+	"net"
+	"rpc/internal/lm"
+	"rpc/pkg/utils"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SystemInfo describes the host OS and rpc, so that the amtinfo output describes the
+// whole endpoint for an inventory
+type SystemInfo struct {
+	OSName    string `json:"osName"`
+	OSVersion string `json:"osVersion"`
+	// OSBuild is the build number on Windows and the kernel release on Linux
+	OSBuild         string `json:"osBuild"`
+	Architecture    string `json:"architecture"`
+	RPCVersion      string `json:"rpcVersion"`
+	ProtocolVersion string `json:"protocolVersion"`
+	// Transport is the way rpc reaches AMT, lms or lme, or remote for the AMT of -host
+	Transport string `json:"transport"`
+	// Address is the WS-MAN address that was checked, LMS or the AMT of -host. It is
+	// empty with -transport lme, the LME driver of rpc reaches AMT through the MEI.
+	Address string `json:"address,omitempty"`
+	// Reachable reports whether Address accepts connections
+	Reachable bool `json:"reachable"`
+}
+
+// TransportRemote is the transport of the AMT of -host
+const TransportRemote lm.Transport = "remote"
+
+// Target is the WS-MAN interface rpc is configured to reach AMT through
+type Target struct {
+	// Transport is lms, lme or auto for the local AMT, TransportRemote for -host
+	Transport lm.Transport
+	// Address is the host:port of LMS, or of the AMT of -host
+	Address string
+}
+
+// dialTimeout bounds the check whether the WS-MAN address is listening
+const dialTimeout = time.Second
+
+// dialWSMAN connects to the WS-MAN address, it is replaced in tests
+var dialWSMAN = func(address string) (net.Conn, error) {
+	return net.DialTimeout("tcp", address, dialTimeout)
+}
+
+// readOSVersion returns the name, version and build of the host OS, it is replaced in tests
+var readOSVersion = osVersion
+
+// HostSystem reads the host OS version and checks whether the WS-MAN interface of the
+// target accepts connections. The rpc version and architecture are filled in when the OS
+// version can not be read. The OS of a remote device is not read, wsman does not report it.
+func HostSystem(target Target) (SystemInfo, error) {
+	system := SystemInfo{
+		RPCVersion:      utils.ProjectVersion,
+		ProtocolVersion: utils.ProtocolVersion,
+		Transport:       string(target.Transport),
+	}
+	if target.Transport != lm.TransportLME {
+		system.Address = target.Address
+		if conn, err := dialWSMAN(target.Address); err == nil {
+			conn.Close()
+			system.Reachable = true
+		}
+	}
+	if target.Transport == TransportRemote {
+		return system, nil
+	}
+	// auto takes LMS when it is running, like lm.Select
+	if target.Transport == lm.TransportAuto || target.Transport == "" {
+		system.Transport = string(lm.TransportLME)
+		if system.Reachable {
+			system.Transport = string(lm.TransportLMS)
+		}
+	}
+	system.Architecture = runtime.GOARCH
+	var err error
+	system.OSName, system.OSVersion, system.OSBuild, err = readOSVersion()
+	return system, err
+}
+
+// parseOSRelease reads the NAME and VERSION_ID of an os-release file
+func parseOSRelease(content string) (name string, version string) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "NAME":
+			name = value
+		case "VERSION_ID":
+			version = value
+		}
+	}
+	return name, version
+}
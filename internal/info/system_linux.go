@@ -0,0 +1,40 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"os"
+	"strings"
+)
+
+// osReleasePaths are tried in order, /usr/lib/os-release is the fallback of the spec
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// kernelReleasePath holds the release of the running kernel
+var kernelReleasePath = "/proc/sys/kernel/osrelease"
+
+func osVersion() (name string, version string, build string, err error) {
+	var content []byte
+	for _, path := range osReleasePaths {
+		if content, err = os.ReadFile(path); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "Linux", "", "", err
+	}
+	name, version = parseOSRelease(string(content))
+	if name == "" {
+		name = "Linux"
+	}
+	kernel, err := os.ReadFile(kernelReleasePath)
+	if err != nil {
+		return name, version, "", err
+	}
+	return name, version, strings.TrimSpace(string(kernel)), nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import "runtime"
+
+func osVersion() (name string, version string, build string, err error) {
+	return runtime.GOOS, "", "", nil
+}
@@ -0,0 +1,105 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"errors"
+	"net"
+	"rpc/internal/lm"
+	"rpc/pkg/utils"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	name, version := parseOSRelease(`PRETTY_NAME="Ubuntu 22.04.4 LTS"
+NAME="Ubuntu"
+VERSION_ID="22.04"
+ID=ubuntu
+`)
+	assert.Equal(t, "Ubuntu", name)
+	assert.Equal(t, "22.04", version)
+
+	name, version = parseOSRelease("NAME=Arch Linux\nBUILD_ID=rolling\n")
+	assert.Equal(t, "Arch Linux", name)
+	assert.Empty(t, version)
+}
+
+func TestHostSystem(t *testing.T) {
+	origDial, origRead := dialWSMAN, readOSVersion
+	defer func() { dialWSMAN, readOSVersion = origDial, origRead }()
+	readOSVersion = func() (string, string, string, error) { return "Ubuntu", "22.04", "6.5.0-28-generic", nil }
+	var dialed []string
+	listening := func(address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+	refused := func(address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		return nil, errors.New("connection refused")
+	}
+
+	t.Run("reports LMS when it accepts connections", func(t *testing.T) {
+		dialWSMAN = listening
+		system, err := HostSystem(Target{Transport: lm.TransportAuto, Address: "localhost:16992"})
+		assert.NoError(t, err)
+		assert.Equal(t, SystemInfo{
+			OSName:          "Ubuntu",
+			OSVersion:       "22.04",
+			OSBuild:         "6.5.0-28-generic",
+			Architecture:    runtime.GOARCH,
+			RPCVersion:      utils.ProjectVersion,
+			ProtocolVersion: utils.ProtocolVersion,
+			Transport:       "lms",
+			Address:         "localhost:16992",
+			Reachable:       true,
+		}, system)
+	})
+	t.Run("auto falls back to the LME driver when LMS is not running", func(t *testing.T) {
+		dialWSMAN = refused
+		system, err := HostSystem(Target{Transport: lm.TransportAuto, Address: "localhost:16992"})
+		assert.NoError(t, err)
+		assert.Equal(t, "lme", system.Transport)
+		assert.False(t, system.Reachable)
+	})
+	t.Run("checks the configured LMS address", func(t *testing.T) {
+		dialed, dialWSMAN = nil, refused
+		system, _ := HostSystem(Target{Transport: lm.TransportLMS, Address: "127.0.0.1:16993"})
+		assert.Equal(t, []string{"127.0.0.1:16993"}, dialed)
+		assert.Equal(t, "lms", system.Transport)
+		assert.False(t, system.Reachable)
+	})
+	t.Run("does not check LMS with the LME driver", func(t *testing.T) {
+		dialed, dialWSMAN = nil, listening
+		system, err := HostSystem(Target{Transport: lm.TransportLME, Address: "localhost:16992"})
+		assert.NoError(t, err)
+		assert.Empty(t, dialed)
+		assert.Equal(t, "lme", system.Transport)
+		assert.Empty(t, system.Address)
+	})
+	t.Run("checks the AMT of -host and not the local OS", func(t *testing.T) {
+		dialed, dialWSMAN = nil, listening
+		system, err := HostSystem(Target{Transport: TransportRemote, Address: "amt.example.com:16992"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"amt.example.com:16992"}, dialed)
+		assert.Equal(t, "remote", system.Transport)
+		assert.True(t, system.Reachable)
+		assert.Empty(t, system.OSName)
+		assert.Equal(t, utils.ProjectVersion, system.RPCVersion)
+	})
+	t.Run("keeps the rpc version when the OS version can not be read", func(t *testing.T) {
+		dialWSMAN = refused
+		readOSVersion = func() (string, string, string, error) { return "Linux", "", "", errors.New("permission denied") }
+		system, err := HostSystem(Target{Transport: lm.TransportAuto, Address: "localhost:16992"})
+		assert.Error(t, err)
+		assert.False(t, system.Reachable)
+		assert.Equal(t, "Linux", system.OSName)
+		assert.Equal(t, utils.ProjectVersion, system.RPCVersion)
+	})
+}
@@ -0,0 +1,46 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package info
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// windows11Build is the first build of Windows 11, whose ProductName still says Windows 10
+const windows11Build = 22000
+
+func osVersion() (name string, version string, build string, err error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows NT\CurrentVersion`, registry.QUERY_VALUE)
+	if err != nil {
+		return "Windows", "", "", err
+	}
+	defer key.Close()
+	name, _, err = key.GetStringValue("ProductName")
+	if err != nil {
+		return "Windows", "", "", err
+	}
+	version, _, err = key.GetStringValue("DisplayVersion")
+	if err != nil {
+		// releases before 20H2 only have the ReleaseId
+		version, _, _ = key.GetStringValue("ReleaseId")
+	}
+	build, _, err = key.GetStringValue("CurrentBuildNumber")
+	if err != nil {
+		return name, version, "", err
+	}
+	if number, _ := strconv.Atoi(build); number >= windows11Build {
+		name = strings.Replace(name, "Windows 10", "Windows 11", 1)
+	}
+	if revision, _, err := key.GetIntegerValue("UBR"); err == nil {
+		build += "." + strconv.FormatUint(revision, 10)
+	}
+	return name, version, build, nil
+}
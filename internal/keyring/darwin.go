@@ -0,0 +1,55 @@
+//go:build darwin
+// +build darwin
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// get reads the generic password from the login keychain
+func get(service string, account string) (string, error) {
+	out, err := command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// set adds the generic password to the login keychain, -U updates an existing item. security
+// -i reads the command from stdin, so the password is not in the arguments of the process
+// that other users can list. It does not fail on a failed command, the item is read back.
+func set(service string, account string, password string) error {
+	cmd := command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(service), securityQuote(account), securityQuote(password)))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	stored, err := get(service, account)
+	if err != nil || strings.TrimRight(stored, "\r\n") != password {
+		return fmt.Errorf("security did not store the password: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument of a command of security -i
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func del(service string, account string) error {
+	return command("security", "delete-generic-password", "-s", service, "-a", account).Run()
+}
@@ -0,0 +1,20 @@
+//go:build darwin
+// +build darwin
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityQuote(t *testing.T) {
+	assert.Equal(t, `"P@ssw0rd"`, securityQuote("P@ssw0rd"))
+	assert.Equal(t, `"a \"b\" \\ c"`, securityQuote(`a "b" \ c`))
+	assert.Equal(t, `""`, securityQuote(""))
+}
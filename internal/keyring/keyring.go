@@ -0,0 +1,56 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// Service and Account identify the AMT admin password in the OS keyring.
+//
+//	Windows: cmdkey /generic:rpc /user:admin /pass
+//	macOS:   security add-generic-password -s rpc -a admin -w
+//	Linux:   secret-tool store --label="rpc" service rpc account admin
+const (
+	Service = utils.ProjectName
+	Account = "admin"
+)
+
+var ErrNotFound = errors.New("password not found in keyring")
+
+// ErrUnsupported is returned on an OS without a keyring rpc can use
+var ErrUnsupported = errors.New("the OS keyring is only supported on Windows, macOS and Linux")
+
+// command builds the command of the keyring helper on Linux and macOS, it is replaced in
+// tests
+var command = exec.Command
+
+// Get returns the password stored in the OS keyring for the service and account
+func Get(service string, account string) (string, error) {
+	password, err := get(service, account)
+	if err != nil {
+		return "", err
+	}
+	// command line helpers terminate the secret with a newline
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return "", ErrNotFound
+	}
+	return password, nil
+}
+
+// Set stores the password in the OS keyring for the service and account, replacing any
+// password stored before
+func Set(service string, account string, password string) error {
+	return set(service, account, password)
+}
+
+// Delete removes the password of the service and account from the OS keyring
+func Delete(service string, account string) error {
+	return del(service, account)
+}
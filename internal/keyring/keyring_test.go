@@ -0,0 +1,169 @@
+//go:build linux || darwin
+// +build linux darwin
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHelper runs the test binary as secret-tool or security, with the keyring in a
+// directory. It returns the file the arguments of the commands are logged to.
+func fakeHelper(t *testing.T) string {
+	dir := t.TempDir()
+	orig := command
+	command = func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestHelperProcess", "--", name}, args...)...)
+		cmd.Env = append(os.Environ(), "KEYRING_HELPER_DIR="+dir)
+		return cmd
+	}
+	t.Cleanup(func() { command = orig })
+	return filepath.Join(dir, "args.log")
+}
+
+// TestHelperProcess is the fake keyring helper, it stores each password in a file
+func TestHelperProcess(t *testing.T) {
+	dir := os.Getenv("KEYRING_HELPER_DIR")
+	if dir == "" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	args = args[1:]
+	log, _ := os.OpenFile(filepath.Join(dir, "args.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	fmt.Fprintln(log, strings.Join(args, " "))
+	log.Close()
+	os.Exit(helper(dir, args))
+}
+
+func helper(dir string, args []string) int {
+	if args[0] == "security" && len(args) == 2 && args[1] == "-i" {
+		// security -i reads the command from stdin
+		input, _ := io.ReadAll(os.Stdin)
+		args = append([]string{"security"}, splitSecurityCommand(strings.TrimSpace(string(input)))...)
+	}
+	options := map[string]string{}
+	for i := 2; i+1 < len(args); i += 2 {
+		if args[i] == "-U" || strings.HasPrefix(args[i], "--label=") {
+			i--
+			continue
+		}
+		options[strings.TrimPrefix(args[i], "-")] = args[i+1]
+	}
+	service := options["service"] + options["s"]
+	account := options["account"] + options["a"]
+	file := filepath.Join(dir, url.PathEscape(service)+"."+url.PathEscape(account))
+	switch args[0] + " " + args[1] {
+	case "secret-tool lookup", "security find-generic-password":
+		password, err := os.ReadFile(file)
+		if err != nil {
+			return 44
+		}
+		fmt.Print(string(password))
+		if args[0] == "security" {
+			fmt.Println()
+		}
+	case "secret-tool store":
+		password, _ := io.ReadAll(os.Stdin)
+		os.WriteFile(file, password, 0600)
+	case "security add-generic-password":
+		os.WriteFile(file, []byte(options["w"]), 0600)
+	case "secret-tool clear", "security delete-generic-password":
+		if os.Remove(file) != nil && args[0] == "security" {
+			return 44
+		}
+	default:
+		return 2
+	}
+	return 0
+}
+
+// splitSecurityCommand splits a command of security -i into its quoted arguments
+func splitSecurityCommand(line string) []string {
+	var args []string
+	for line != "" {
+		if line[0] != '"' {
+			arg, rest, _ := strings.Cut(line, " ")
+			args = append(args, arg)
+			line = strings.TrimLeft(rest, " ")
+			continue
+		}
+		end := 1
+		for line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		arg, _ := strconv.Unquote(line[:end+1])
+		args = append(args, arg)
+		line = strings.TrimLeft(line[end+1:], " ")
+	}
+	return args
+}
+
+func TestSetGetDelete(t *testing.T) {
+	log := fakeHelper(t)
+	password := `P@ss "w0rd" \ with spaces`
+	assert.NoError(t, Set(Service, Account, password))
+	got, err := Get(Service, Account)
+	assert.NoError(t, err)
+	assert.Equal(t, password, got)
+
+	assert.NoError(t, Set(Service, Account, "N3wP@ss"))
+	got, err = Get(Service, Account)
+	assert.NoError(t, err)
+	assert.Equal(t, "N3wP@ss", got, "Set replaces the password")
+
+	_, err = Get(Service, "other")
+	assert.ErrorIs(t, err, ErrNotFound, "the password is stored for the account")
+
+	assert.NoError(t, Delete(Service, Account))
+	_, err = Get(Service, Account)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	args, err := os.ReadFile(log)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(args), "P@ss", "the password is not in the arguments of the helper")
+}
+
+func TestGetNotFound(t *testing.T) {
+	fakeHelper(t)
+	_, err := Get(Service, Account)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetEmptyPassword(t *testing.T) {
+	fakeHelper(t)
+	assert.NoError(t, Set(Service, Account, ""))
+	_, err := Get(Service, Account)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetHelperMissing(t *testing.T) {
+	orig := command
+	command = func(name string, args ...string) *exec.Cmd {
+		return exec.Command(filepath.Join(t.TempDir(), name), args...)
+	}
+	defer func() { command = orig }()
+	_, err := Get(Service, Account)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound), "a missing helper is not a missing password")
+}
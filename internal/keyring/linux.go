@@ -0,0 +1,38 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// get looks the secret up through the freedesktop secret-service using secret-tool
+func get(service string, account string) (string, error) {
+	out, err := command("secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// set stores the secret through secret-tool, which reads it from stdin
+func set(service string, account string, password string) error {
+	cmd := command("secret-tool", "store", "--label="+service, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(password)
+	return cmd.Run()
+}
+
+func del(service string, account string) error {
+	return command("secret-tool", "clear", "service", service, "account", account).Run()
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+func get(service string, account string) (string, error) {
+	return "", ErrUnsupported
+}
+
+func set(service string, account string, password string) error {
+	return ErrUnsupported
+}
+
+func del(service string, account string) error {
+	return ErrUnsupported
+}
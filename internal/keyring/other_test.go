@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsupported(t *testing.T) {
+	_, err := Get(Service, Account)
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.ErrorIs(t, Set(Service, Account, "P@ssw0rd"), ErrUnsupported)
+	assert.ErrorIs(t, Delete(Service, Account), ErrUnsupported)
+}
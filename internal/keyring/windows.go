@@ -0,0 +1,124 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package keyring
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// credential mirrors the CREDENTIALW structure from wincred.h
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+// get reads the generic credential named after the service from the Credential Manager
+func get(service string, account string) (string, error) {
+	target, err := windows.UTF16PtrFromString(service)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.UserName != nil && account != "" && windows.UTF16PtrToString(cred.UserName) != account {
+		return "", ErrNotFound
+	}
+	// cmdkey and the control panel store the blob as UTF-16
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	if len(blob)%2 != 0 {
+		return string(blob), nil
+	}
+	utf16 := make([]uint16, len(blob)/2)
+	for i := range utf16 {
+		utf16[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+	}
+	return windows.UTF16ToString(utf16), nil
+}
+
+// set writes the password as a UTF-16 blob, the way cmdkey stores it
+func set(service string, account string, password string) error {
+	target, err := windows.UTF16PtrFromString(service)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	utf16, err := windows.UTF16FromString(password)
+	if err != nil {
+		return err
+	}
+	// drop the terminating NUL
+	utf16 = utf16[:len(utf16)-1]
+	blob := make([]byte, 2*len(utf16))
+	for i, c := range utf16 {
+		blob[2*i] = byte(c)
+		blob[2*i+1] = byte(c >> 8)
+	}
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		UserName:           user,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func del(service string, account string) error {
+	target, err := windows.UTF16PtrFromString(service)
+	if err != nil {
+		return err
+	}
+	r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
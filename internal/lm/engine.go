@@ -7,13 +7,14 @@ package lm
 import (
 	"bytes"
 	"encoding/binary"
+	"rpc/internal/logging"
 	"rpc/pkg/apf"
 	"rpc/pkg/pthi"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
+var log = logging.For(logging.ModuleLMS)
+
 // LMConnection is struct for managing connection to LMS
 type LMEConnection struct {
 	Command    pthi.Command
@@ -32,6 +33,7 @@ func NewLMEConnection(data chan []byte, errors chan error, status chan bool) *LM
 		ErrorBuffer: errors,
 		Tempdata:    []byte{},
 		Status:      status,
+		Complete:    make(chan struct{}, 1),
 	}
 
 	return lme
@@ -66,6 +68,11 @@ func (lme *LMEConnection) Connect() error {
 		lme.ourChannel = channel
 	}
 
+	// a response that completed after it was delivered must not end the next one early
+	select {
+	case <-lme.Session.Complete:
+	default:
+	}
 	bin_buf := apf.ChannelOpen(lme.ourChannel)
 	err := lme.Command.Send(bin_buf.Bytes(), uint32(bin_buf.Len()))
 	if err != nil {
@@ -127,8 +134,14 @@ func (lme *LMEConnection) execute(bin_buf bytes.Buffer) error {
 // Listen reads data from the LMS socket connection
 func (lme *LMEConnection) Listen() {
 	go func() {
+		// the response is delivered as soon as it is complete, the timer delivers what
+		// was received when AMT stops sending without completing it
 		lme.Session.Timer = time.NewTimer(2 * time.Second)
-		<-lme.Session.Timer.C
+		select {
+		case <-lme.Session.Timer.C:
+		case <-lme.Session.Complete:
+			lme.Session.Timer.Stop()
+		}
 		lme.Session.DataBuffer <- lme.Session.Tempdata
 		lme.Session.Tempdata = []byte{}
 		var bin_buf bytes.Buffer
@@ -5,13 +5,12 @@
 package lm
 
 import (
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
 	"strings"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // LMConnection is struct for managing connection to LMS
@@ -21,6 +20,8 @@ type LMSConnection struct {
 	port       string
 	data       chan []byte
 	errors     chan error
+	// tlsConfig is set for the TLS port of LMS
+	tlsConfig *tls.Config
 }
 
 func NewLMSConnection(address string, port string, data chan []byte, errors chan error) *LMSConnection {
@@ -33,6 +34,22 @@ func NewLMSConnection(address string, port string, data chan []byte, errors chan
 	}
 	return lms
 }
+
+// NewLMSTLSConnection returns a connection to the TLS port of LMS, used once AMT requires
+// TLS on the local interface
+func NewLMSTLSConnection(address string, port string, data chan []byte, errors chan error) *LMSConnection {
+	lms := NewLMSConnection(address, port, data, errors)
+	lms.tlsConfig = LocalTLSConfig()
+	return lms
+}
+
+// LocalTLSConfig is the TLS configuration of the local interface of AMT. LMS listens on
+// the loopback interface only and AMT presents the certificate of its network name, so
+// the certificate is not verified.
+func LocalTLSConfig() *tls.Config {
+	return &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}
+}
+
 func (lms *LMSConnection) Initialize() error {
 	return errors.New("not implemented")
 }
@@ -41,6 +58,12 @@ func (lms *LMSConnection) Initialize() error {
 func (lms *LMSConnection) Connect() error {
 	log.Debug("connecting to lms")
 	var err error
+	if lms.Connection == nil && lms.tlsConfig != nil {
+		lms.Connection, err = tls.Dial("tcp4", lms.address+":"+lms.port, lms.tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
 	if lms.Connection == nil {
 		lms.Connection, err = net.Dial("tcp4", lms.address+":"+lms.port)
 		if err != nil {
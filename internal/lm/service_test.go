@@ -5,7 +5,10 @@
 package lm
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,6 +40,17 @@ func TestConnect(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestConnectTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	lms := NewLMSTLSConnection(host, port, make(chan []byte), make(chan error))
+	defer lms.Close()
+	assert.NoError(t, lms.Connect())
+	_, ok := lms.Connection.(*tls.Conn)
+	assert.True(t, ok, "the connection to the TLS port of LMS is TLS")
+}
+
 func TestSend(t *testing.T) {
 	server, client := net.Pipe()
 	defer server.Close()
@@ -0,0 +1,189 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package lm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"rpc/pkg/utils"
+	"sync"
+)
+
+// Transport names the path rpc takes to the WS-MAN interface of AMT
+type Transport string
+
+const (
+	// TransportLMS is the Local Manageability Service of the host OS, it may be shared
+	// with other management software
+	TransportLMS Transport = "lms"
+	// TransportLMSTLS is LMS on its TLS port, for an AMT with TLS on the local interface
+	TransportLMSTLS Transport = "lms-tls"
+	// TransportLME is the LME driver built into rpc, it opens APF channels through the MEI
+	TransportLME Transport = "lme"
+	// TransportAuto is LMS when it is running and the LME driver of rpc otherwise
+	TransportAuto Transport = "auto"
+)
+
+// ParseTransport returns the transport of a -transport value
+func ParseTransport(value string) (Transport, error) {
+	switch t := Transport(value); t {
+	case TransportLMS, TransportLMSTLS, TransportLME, TransportAuto:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown transport %q, use lms, lms-tls, lme or auto", value)
+}
+
+// Connection is the local manager chosen by Select with the channels it delivers the
+// responses of AMT on
+type Connection struct {
+	LocalMananger
+	Transport Transport
+	Data      chan []byte
+	Errors    chan error
+	// Status reports the opening and closing of LME channels, it is nil for LMS
+	Status chan bool
+}
+
+// newLMS, newLMSTLS and newLME create the local managers, they are replaced in tests
+var newLMS = func(data chan []byte, errors chan error) LocalMananger {
+	return NewLMSConnection(utils.LMSAddress, utils.LMSPort, data, errors)
+}
+
+var newLMSTLS = func(data chan []byte, errors chan error) LocalMananger {
+	return NewLMSTLSConnection(utils.LMSAddress, utils.LMSTLSPort, data, errors)
+}
+
+var newLME = func(data chan []byte, errors chan error, status chan bool) LocalMananger {
+	return NewLMEConnection(data, errors, status)
+}
+
+// Select returns the connection of the preferred transport. Auto, and an empty preference,
+// prefers LMS when it accepts connections and falls back to the LME driver of rpc
+// otherwise, so rpc behaves the same on hosts with and without LMS. LMS and LME use only
+// that transport, so a host can be diagnosed with each of them. LMSTLS is never chosen by
+// auto, AMT only answers on it once TLS is enabled on the local interface. The transport
+// used is logged at debug level and shows in verbose output. An error is returned when
+// LMS is not running with TransportLMS or TransportLMSTLS or the LME driver can not be
+// initialized, the connection is returned anyway.
+func Select(preferred Transport) (*Connection, error) {
+	c := &Connection{
+		Data:   make(chan []byte),
+		Errors: make(chan error),
+	}
+	if preferred == TransportLMSTLS {
+		return selectLMSTLS(c)
+	}
+	if preferred != TransportLME {
+		lms := newLMS(c.Data, c.Errors)
+		err := lms.Connect()
+		if err == nil {
+			// LMS opens a connection for each request
+			lms.Close()
+			c.LocalMananger, c.Transport = lms, TransportLMS
+			log.Debugf("transport: using LMS at %s:%s", utils.LMSAddress, utils.LMSPort)
+			return c, nil
+		}
+		if preferred == TransportLMS {
+			c.LocalMananger, c.Transport = lms, TransportLMS
+			return c, fmt.Errorf("LMS is not running at %s:%s: %w", utils.LMSAddress, utils.LMSPort, err)
+		}
+		log.Debugf("transport: LMS is not running (%v), using the LME driver of rpc", err)
+	}
+	return selectLME(c)
+}
+
+// LogSelectError logs the error of Select, the exchanges with AMT fail later without
+// showing its cause. A transport selected with -transport fails at error level, auto
+// only when the LME driver can not be used either, which is logged at warn level.
+func LogSelectError(preferred Transport, err error) {
+	if err == nil {
+		return
+	}
+	if preferred == TransportAuto || preferred == "" {
+		log.Warnf("transport: LMS is not running and the LME driver can not be used: %v", err)
+		return
+	}
+	log.Error(err)
+}
+
+// selectLMSTLS checks that LMS accepts TLS connections for the connection
+func selectLMSTLS(c *Connection) (*Connection, error) {
+	lms := newLMSTLS(c.Data, c.Errors)
+	c.LocalMananger, c.Transport = lms, TransportLMSTLS
+	if err := lms.Connect(); err != nil {
+		return c, fmt.Errorf("LMS is not accepting TLS at %s:%s: %w", utils.LMSAddress, utils.LMSTLSPort, err)
+	}
+	lms.Close()
+	log.Debugf("transport: using LMS over TLS at %s:%s", utils.LMSAddress, utils.LMSTLSPort)
+	return c, nil
+}
+
+// selectLME initializes the LME driver of rpc for the connection
+func selectLME(c *Connection) (*Connection, error) {
+	c.Status = make(chan bool)
+	c.LocalMananger, c.Transport = newLME(c.Data, c.Errors, c.Status), TransportLME
+	if err := c.Initialize(); err != nil {
+		return c, err
+	}
+	log.Debug("transport: using the LME driver of rpc through the MEI")
+	return c, nil
+}
+
+// Exchange sends a request to AMT on a new LMS connection or LME channel and returns the
+// response
+func (c *Connection) Exchange(request []byte) ([]byte, error) {
+	if err := c.Connect(); err != nil {
+		return nil, err
+	}
+	go c.Listen()
+	if c.Status != nil {
+		// wait for the channel open confirmation
+		<-c.Status
+		log.Trace("Channel open confirmation received")
+	} else {
+		defer c.Close()
+	}
+	if err := c.Send(request); err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case data := <-c.Data:
+			if c.Status != nil {
+				// the channel is closed once the response is read
+				<-c.Status
+			}
+			return data, nil
+		case err := <-c.Errors:
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// RoundTripper sends the HTTP requests of a wsman client through the connection, so local
+// commands reach AMT without LMS. The requests are sent one at a time.
+type RoundTripper struct {
+	Connection *Connection
+	mu         sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var request bytes.Buffer
+	if err := req.Write(&request); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	response, err := t.Connection.Exchange(request.Bytes())
+	t.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(response)), req)
+}
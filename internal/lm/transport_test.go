@@ -0,0 +1,169 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package lm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockManager answers every request with response, the way LMS does
+type mockManager struct {
+	connectErr error
+	initErr    error
+	data       chan []byte
+	response   string
+	sent       []byte
+	closed     bool
+}
+
+func (m *mockManager) Initialize() error { return m.initErr }
+func (m *mockManager) Connect() error    { return m.connectErr }
+func (m *mockManager) Listen()           { m.data <- []byte(m.response) }
+func (m *mockManager) Close() error      { m.closed = true; return nil }
+func (m *mockManager) Send(data []byte) error {
+	m.sent = append(m.sent, data...)
+	return nil
+}
+
+func mockManagers(t *testing.T, lms *mockManager, lme *mockManager) {
+	origLMS, origLMSTLS, origLME := newLMS, newLMSTLS, newLME
+	t.Cleanup(func() { newLMS, newLMSTLS, newLME = origLMS, origLMSTLS, origLME })
+	newLMS = func(data chan []byte, errors chan error) LocalMananger {
+		lms.data = data
+		return lms
+	}
+	newLMSTLS = newLMS
+	newLME = func(data chan []byte, errors chan error, status chan bool) LocalMananger {
+		lme.data = data
+		return lme
+	}
+}
+
+func TestSelect(t *testing.T) {
+	t.Run("prefers LMS", func(t *testing.T) {
+		lms := &mockManager{}
+		mockManagers(t, lms, &mockManager{})
+		c, err := Select(TransportAuto)
+		assert.NoError(t, err)
+		assert.Equal(t, TransportLMS, c.Transport)
+		assert.Equal(t, lms, c.LocalMananger)
+		assert.Nil(t, c.Status)
+		assert.True(t, lms.closed)
+	})
+	t.Run("falls back to LME", func(t *testing.T) {
+		lme := &mockManager{}
+		mockManagers(t, &mockManager{connectErr: errors.New("connection refused")}, lme)
+		c, err := Select(TransportAuto)
+		assert.NoError(t, err)
+		assert.Equal(t, TransportLME, c.Transport)
+		assert.Equal(t, lme, c.LocalMananger)
+		assert.NotNil(t, c.Status)
+	})
+	t.Run("LME can not be initialized", func(t *testing.T) {
+		mockManagers(t, &mockManager{connectErr: errors.New("connection refused")}, &mockManager{initErr: errors.New("no such device")})
+		c, err := Select(TransportAuto)
+		assert.Error(t, err)
+		assert.Equal(t, TransportLME, c.Transport)
+	})
+	t.Run("LMS only", func(t *testing.T) {
+		mockManagers(t, &mockManager{connectErr: errors.New("connection refused")}, &mockManager{})
+		c, err := Select(TransportLMS)
+		assert.ErrorContains(t, err, "LMS is not running")
+		assert.Equal(t, TransportLMS, c.Transport)
+	})
+	t.Run("LMS over TLS", func(t *testing.T) {
+		lms := &mockManager{}
+		mockManagers(t, lms, &mockManager{})
+		c, err := Select(TransportLMSTLS)
+		assert.NoError(t, err)
+		assert.Equal(t, TransportLMSTLS, c.Transport)
+		assert.Equal(t, lms, c.LocalMananger)
+		assert.Nil(t, c.Status)
+		assert.True(t, lms.closed)
+	})
+	t.Run("LMS over TLS is not accepting connections", func(t *testing.T) {
+		mockManagers(t, &mockManager{connectErr: errors.New("connection refused")}, &mockManager{})
+		c, err := Select(TransportLMSTLS)
+		assert.ErrorContains(t, err, "LMS is not accepting TLS")
+		assert.Equal(t, TransportLMSTLS, c.Transport)
+	})
+	t.Run("LME only", func(t *testing.T) {
+		lms, lme := &mockManager{}, &mockManager{}
+		mockManagers(t, lms, lme)
+		c, err := Select(TransportLME)
+		assert.NoError(t, err)
+		assert.Equal(t, TransportLME, c.Transport)
+		assert.Equal(t, lme, c.LocalMananger)
+		assert.False(t, lms.closed)
+	})
+}
+
+func TestLogSelectError(t *testing.T) {
+	var buf bytes.Buffer
+	out := log.Out
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(out) })
+
+	LogSelectError(TransportLMS, nil)
+	assert.Empty(t, buf.String())
+
+	LogSelectError(TransportAuto, errors.New("no such device"))
+	assert.Contains(t, buf.String(), "level=warning")
+	assert.Contains(t, buf.String(), "LME driver can not be used: no such device")
+
+	for _, transport := range []Transport{TransportLMS, TransportLMSTLS, TransportLME} {
+		buf.Reset()
+		LogSelectError(transport, errors.New("no such device"))
+		assert.Contains(t, buf.String(), "level=error", transport)
+		assert.Contains(t, buf.String(), "no such device", transport)
+	}
+}
+
+func TestParseTransport(t *testing.T) {
+	for _, value := range []string{"lms", "lms-tls", "lme", "auto"} {
+		transport, err := ParseTransport(value)
+		assert.NoError(t, err)
+		assert.Equal(t, Transport(value), transport)
+	}
+	_, err := ParseTransport("heci")
+	assert.ErrorContains(t, err, "lms, lms-tls, lme or auto")
+}
+
+func TestExchange(t *testing.T) {
+	lms := &mockManager{response: "response"}
+	c := &Connection{LocalMananger: lms, Transport: TransportLMS, Data: make(chan []byte), Errors: make(chan error)}
+	lms.data = c.Data
+	response, err := c.Exchange([]byte("request"))
+	assert.NoError(t, err)
+	assert.Equal(t, "response", string(response))
+	assert.Equal(t, "request", string(lms.sent))
+	assert.True(t, lms.closed)
+
+	lms.connectErr = errors.New("connection refused")
+	_, err = c.Exchange([]byte("request"))
+	assert.Error(t, err)
+}
+
+func TestRoundTripper(t *testing.T) {
+	lms := &mockManager{response: "HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\n<Body/>\n"}
+	c := &Connection{LocalMananger: lms, Transport: TransportLMS, Data: make(chan []byte), Errors: make(chan error)}
+	lms.data = c.Data
+	client := http.Client{Transport: &RoundTripper{Connection: c}}
+	res, err := client.Post("http://localhost:16992/wsman", "application/soap+xml", strings.NewReader("<Envelope/>"))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "<Body/>\n", string(body))
+	assert.True(t, strings.HasPrefix(string(lms.sent), "POST /wsman HTTP/1.1\r\n"))
+	assert.True(t, strings.HasSuffix(string(lms.sent), "\r\n\r\n<Envelope/>"))
+}
@@ -11,15 +11,37 @@ import (
 	"encoding/pem"
 	"encoding/xml"
 	"errors"
+	"rpc/internal/flags"
+	"rpc/internal/pki"
+	"rpc/pkg/rpcerr"
 	"rpc/pkg/utils"
 	"strings"
+	"time"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/ips/hostbasedsetup"
-	log "github.com/sirupsen/logrus"
 	pkcs12 "software.sslmate.com/src/go-pkcs12"
 )
 
+type SetMEBxPasswordResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetMEBxPassword_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// UpgradeClientToAdminResponse is the answer of IPS_HostBasedSetupService.UpgradeClientToAdmin
+type UpgradeClientToAdminResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"UpgradeClientToAdmin_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// Activate brings the device to the requested control mode. A device already in that mode
+// is left as it is, a device in another mode is upgraded or reprovisioned when asked to.
 func (service *ProvisioningService) Activate() utils.ReturnCode {
 
 	controlMode, err := service.amtCommand.GetControlMode()
@@ -27,9 +49,32 @@ func (service *ProvisioningService) Activate() utils.ReturnCode {
 		log.Error(err)
 		return utils.AMTConnectionFailed
 	}
-	if controlMode != 0 {
-		log.Error("Device is already activated")
-		return utils.UnableToActivate
+	path, err := service.flags.ActivationPath(controlMode)
+	if err != nil {
+		log.Error(err)
+		return rpcerr.ReturnCodeOf(err)
+	}
+	service.writeActivationPath(controlMode, path)
+	return service.activateByPath(controlMode, path)
+}
+
+// activateByPath takes the device in the control mode along the activation path
+func (service *ProvisioningService) activateByPath(controlMode int, path string) utils.ReturnCode {
+	if path != flags.ActivationPathNone {
+		if rc := service.checkActivationSKU(); rc != utils.Success {
+			return rc
+		}
+	}
+	switch path {
+	case flags.ActivationPathNone:
+		log.Info("Status: Device is already " + utils.InterpretControlMode(controlMode))
+		return utils.Success
+	case flags.ActivationPathUpgrade:
+		return service.UpgradeToACM()
+	case flags.ActivationPathReprovision:
+		if rc := service.unprovisionForReprovision(controlMode); rc != utils.Success {
+			return rc
+		}
 	}
 
 	// for local activation, wsman client needs local system account credentials
@@ -44,6 +89,9 @@ func (service *ProvisioningService) Activate() utils.ReturnCode {
 
 	if service.flags.UseACM {
 		rc = service.ActivateACM()
+		if rc == utils.Success && service.flags.MEBxPassword != "" {
+			rc = service.SetMEBxPassword()
+		}
 	} else if service.flags.UseCCM {
 		rc = service.ActivateCCM()
 	}
@@ -51,6 +99,69 @@ func (service *ProvisioningService) Activate() utils.ReturnCode {
 	return rc
 }
 
+// writeActivationPath reports the control mode the device is in and what activate does about it
+func (service *ProvisioningService) writeActivationPath(controlMode int, path string) {
+	w := service.newOutputWriter()
+	w.Field("controlMode", "Control mode", utils.InterpretControlMode(controlMode))
+	w.Field("activationPath", "Activation path", path)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+}
+
+// unprovisionForReprovision returns an activated device to pre-provisioning. The admin
+// password of an ACM device is the -password given, or the password of the activation.
+func (service *ProvisioningService) unprovisionForReprovision(controlMode int) utils.ReturnCode {
+	log.Infof("reprovisioning, deactivating the device %s", utils.InterpretControlMode(controlMode))
+	if controlMode == 1 {
+		return service.DeactivateCCM()
+	}
+	if service.flags.Password == "" {
+		service.flags.Password = service.config.ACMSettings.AMTPassword
+	}
+	if rc := service.unprovisionWsman(); rc != utils.Success {
+		return rc
+	}
+	return service.waitForPreProvisioning()
+}
+
+// UpgradeToACM moves a device from client to admin control mode with the provisioning
+// certificate. It needs the admin password set by the CCM activation, which is kept.
+func (service *ProvisioningService) UpgradeToACM() utils.ReturnCode {
+	service.setupWsmanClient("admin", service.config.ACMSettings.AMTPassword)
+	certObject, _, err := service.GetProvisioningCertObj()
+	if err != nil {
+		log.Error(err)
+		return utils.ActivationFailed
+	}
+	if _, err = service.CheckProvisioningCert(); err != nil {
+		log.Error(err)
+		return utils.ActivationFailed
+	}
+	nonce, signature, err := service.signAdminSetup(certObject)
+	if err != nil {
+		log.Error(err)
+		return utils.ActivationFailed
+	}
+	message := service.ipsMessages.HostBasedSetupService.UpgradeClientToAdmin(base64.StdEncoding.EncodeToString(nonce), hostbasedsetup.SigningAlgorithmRSASHA2256, signature)
+	var rsp UpgradeClientToAdminResponse
+	if rc := service.PostAndUnmarshal(message, &rsp); rc != utils.Success {
+		// AMT can drop the connection when it changes the control mode
+		if controlMode, err := service.amtCommand.GetControlMode(); err != nil || controlMode != 2 {
+			log.Error("unable to upgrade to admin control mode")
+			return utils.ActivationFailed
+		}
+	} else if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("UpgradeClientToAdmin_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.ActivationFailed
+	}
+	log.Info("Status: Device upgraded to Admin Control Mode")
+	if service.flags.MEBxPassword != "" {
+		return service.SetMEBxPassword()
+	}
+	return utils.Success
+}
+
 func (service *ProvisioningService) ActivateACM() utils.ReturnCode {
 	checkErrorAndLog := func(err error) bool {
 		if err != nil {
@@ -60,12 +171,12 @@ func (service *ProvisioningService) ActivateACM() utils.ReturnCode {
 		return false
 	}
 	// Extract the provisioning certificate
-	certObject, fingerPrint, err := service.GetProvisioningCertObj()
+	certObject, _, err := service.GetProvisioningCertObj()
 	if checkErrorAndLog(err) {
 		return utils.ActivationFailed
 	}
 	// Check provisioning certificate is accepted by AMT
-	if checkErrorAndLog(service.CompareCertHashes(fingerPrint)) {
+	if _, err = service.CheckProvisioningCert(); checkErrorAndLog(err) {
 		return utils.ActivationFailed
 	}
 
@@ -74,47 +185,96 @@ func (service *ProvisioningService) ActivateACM() utils.ReturnCode {
 		return utils.ActivationFailed
 	}
 
-	getHostBasedSetupResponse, err := service.GetHostBasedSetupService()
+	nonce, signedSignature, err := service.signAdminSetup(certObject)
 	if checkErrorAndLog(err) {
 		return utils.ActivationFailed
 	}
-	decodedNonce := getHostBasedSetupResponse.Body.IPS_HostBasedSetupService.ConfigurationNonce
-	fwNonce, err := base64.StdEncoding.DecodeString(decodedNonce)
+
+	_, err = service.sendAdminSetup(generalSettings.Body.AMTGeneralSettings.DigestRealm, nonce, signedSignature)
 	if checkErrorAndLog(err) {
-		log.Error("Error decoding fwNonce:", err)
 		return utils.ActivationFailed
 	}
+	return utils.Success
+}
 
-	if checkErrorAndLog(service.injectCertificate(certObject.certChain)) {
-		return utils.ActivationFailed
+// signAdminSetup adds the provisioning certificate chain to AMT and returns a new nonce and
+// its signature over the nonce of the firmware, as AdminSetup and UpgradeClientToAdmin expect
+func (service *ProvisioningService) signAdminSetup(certObject ProvisioningCertObj) ([]byte, string, error) {
+	getHostBasedSetupResponse, err := service.GetHostBasedSetupService()
+	if err != nil {
+		return nil, "", err
+	}
+	fwNonce, err := base64.StdEncoding.DecodeString(getHostBasedSetupResponse.Body.IPS_HostBasedSetupService.ConfigurationNonce)
+	if err != nil {
+		log.Error("Error decoding fwNonce:", err)
+		return nil, "", err
+	}
+	if err = service.injectCertificate(certObject.certChain); err != nil {
+		return nil, "", err
 	}
-
 	nonce, err := service.generateNonce()
-	if checkErrorAndLog(err) {
-		return utils.ActivationFailed
+	if err != nil {
+		return nil, "", err
 	}
-
-	signedSignature, err := service.createSignedString(nonce, fwNonce, certObject.privateKey)
-	if checkErrorAndLog(err) {
-		return utils.ActivationFailed
+	signature, err := service.createSignedString(nonce, fwNonce, certObject.privateKey)
+	if err != nil {
+		return nil, "", err
 	}
+	return nonce, signature, nil
+}
 
-	_, err = service.sendAdminSetup(generalSettings.Body.AMTGeneralSettings.DigestRealm, nonce, signedSignature)
-	if checkErrorAndLog(err) {
-		return utils.ActivationFailed
-	}
+// SetMEBxPassword sets the MEBx password using the admin credentials set by ACM activation
+func (service *ProvisioningService) SetMEBxPassword() utils.ReturnCode {
+	service.setupWsmanClient("admin", service.config.ACMSettings.AMTPassword)
+	// the message is formatted without escaping the password
+	var password strings.Builder
+	_ = xml.EscapeText(&password, []byte(service.flags.MEBxPassword))
+	var rsp SetMEBxPasswordResponse
+	rc := service.PostAndUnmarshal(service.amtMessages.SetupAndConfigurationService.SetMEBXPassword(password.String()), &rsp)
+	if rc == utils.Success && rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("SetMEBxPassword_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		rc = utils.SetMEBxPasswordFailed
+	}
+	if rc != utils.Success {
+		log.Error("device is activated but the MEBx password was not set")
+		return utils.SetMEBxPasswordFailed
+	}
+	log.Info("Status: MEBx password set")
 	return utils.Success
 }
 
+// ActivateCCM activates the device in client control mode with the -password, or a generated
+// password with -generatePassword. The generated password is saved to the output file, keyring
+// or secret store before the device is activated and the saved copy is reverted if the
+// activation fails, it is never printed.
 func (service *ProvisioningService) ActivateCCM() utils.ReturnCode {
 	generalSettings, err := service.GetGeneralSettings()
 	if err != nil {
 		log.Error(err)
 		return utils.ActivationFailed
 	}
-	_, err = service.HostBasedSetup(generalSettings.Body.AMTGeneralSettings.DigestRealm, service.config.Password)
+	revert := func() error { return nil }
+	if service.flags.Activate.GeneratePassword {
+		password, err := service.generatePassword(utils.DefaultGeneratedPasswordLength, false)
+		if err != nil {
+			log.Error("unable to generate password: ", err)
+			return utils.ActivationFailed
+		}
+		if revert, err = service.savePassword(password); err != nil {
+			log.Error("unable to save the generated password: ", err)
+			return utils.ActivationFailed
+		}
+		service.config.Password = password
+	}
+	rc, err := service.HostBasedSetup(generalSettings.Body.AMTGeneralSettings.DigestRealm, service.config.Password)
 	if err != nil {
 		log.Error(err)
+		// without a response the device may be activated, the saved password is kept
+		if rc != utils.AMTConnectionFailed {
+			if err := revert(); err != nil {
+				log.Warn("unable to revert the saved password: ", err)
+			}
+		}
 		return utils.ActivationFailed
 	}
 	log.Info("Status: Device activated in Client Control Mode")
@@ -258,17 +418,19 @@ func (service *ProvisioningService) GetProvisioningCertObj() (ProvisioningCertOb
 	return result, fingerprint, nil
 }
 
-func (service *ProvisioningService) CompareCertHashes(fingerPrint string) error {
-	result, err := service.amtCommand.GetCertificateHashes()
+// CheckProvisioningCert verifies the chain of the provisioning certificate and matches its
+// root with the trusted root certificate hashes of AMT
+func (service *ProvisioningService) CheckProvisioningCert() (pki.Report, error) {
+	hashes, err := service.amtCommand.GetCertificateHashes()
 	if err != nil {
 		log.Error(err)
 	}
-	for _, v := range result {
-		if v.Hash == fingerPrint {
-			return nil
-		}
+	config := service.config.ACMSettings
+	report, err := pki.Check(config.ProvisioningCert, config.ProvisioningCertPwd, hashes, time.Now())
+	if err == nil {
+		log.Infof("the root of the provisioning certificate matches the AMT hash %q", report.MatchedHash.Name)
 	}
-	return errors.New("The root of the provisioning certificate does not match any of the trusted roots in AMT.")
+	return report, err
 }
 
 func (service *ProvisioningService) injectCertificate(certChain []string) error {
@@ -1,13 +1,18 @@
 package local
 
 import (
+	"bytes"
 	"crypto/x509"
 	"errors"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	amt2 "rpc/internal/amt"
 	"rpc/internal/certtest"
 	"rpc/internal/flags"
 	"rpc/pkg/utils"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -44,6 +49,39 @@ func TestActivation(t *testing.T) {
 		mockControlMode = 0
 	})
 
+	t.Run("returns Success when already in the requested control mode", func(t *testing.T) {
+		mockControlMode = 1
+		lps.flags.Local, lps.flags.UseCCM = true, true
+		var out bytes.Buffer
+		lps.out = &out
+		rc := lps.Activate()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, out.String(), "Activation path: none")
+		lps.flags.Local, lps.flags.UseCCM = false, false
+		lps.out = io.Discard
+		mockControlMode = 0
+	})
+
+	t.Run("returns UnableToActivate for ACM to CCM without reprovision", func(t *testing.T) {
+		mockControlMode = 2
+		lps.flags.Local, lps.flags.UseCCM = true, true
+		rc := lps.Activate()
+		assert.Equal(t, utils.UnableToActivate, rc)
+		lps.flags.Local, lps.flags.UseCCM = false, false
+		mockControlMode = 0
+	})
+
+	t.Run("deactivates before activating again with reprovision", func(t *testing.T) {
+		mockControlMode = 1
+		mockUnprovisionCode = 1
+		lps.flags.Local, lps.flags.UseCCM, lps.flags.Activate.Reprovision = true, true, true
+		rc := lps.Activate()
+		assert.Equal(t, utils.DeactivationFailed, rc)
+		lps.flags.Local, lps.flags.UseCCM, lps.flags.Activate.Reprovision = false, false, false
+		mockUnprovisionCode = 0
+		mockControlMode = 0
+	})
+
 	t.Run("returns AMTConnectionFailed when GetLocalSystemAccount fails", func(t *testing.T) {
 		mockLocalSystemAccountErr = errors.New("yep it failed")
 		rc := lps.Activate()
@@ -148,6 +186,78 @@ func TestActivateCCM(t *testing.T) {
 		rc := lps.ActivateCCM()
 		assert.Equal(t, utils.Success, rc)
 	})
+
+	t.Run("saves the generated password before activating and does not print it", func(t *testing.T) {
+		gf := &flags.Flags{}
+		gf.Activate.GeneratePassword = true
+		gf.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		calls := 0
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				respondGeneralSettings(t, w)
+			} else if calls == 2 {
+				data, err := os.ReadFile(gf.ChangePassword.OutFile)
+				assert.NoError(t, err)
+				assert.Equal(t, "G3ner@tedPassw0rd\n", string(data))
+				respondHostBasedSetup(t, w)
+			}
+		})
+		lps := setupWithWsmanClient(gf, handler)
+		lps.passwordGenerator = stubPasswordGenerator{password: "G3ner@tedPassw0rd"}
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ActivateCCM())
+		assert.Equal(t, "G3ner@tedPassw0rd", lps.config.Password)
+		assert.NotContains(t, buf.String(), "G3ner@tedPassw0rd")
+	})
+
+	t.Run("removes the saved password when AMT rejects the activation", func(t *testing.T) {
+		gf := &flags.Flags{}
+		gf.Activate.GeneratePassword = true
+		gf.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		calls := 0
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				respondGeneralSettings(t, w)
+			} else if calls == 2 {
+				mockHostBasedSetupResponse.Body.Setup_OUTPUT.ReturnValue = 1
+				respondHostBasedSetup(t, w)
+				mockHostBasedSetupResponse.Body.Setup_OUTPUT.ReturnValue = 0
+			}
+		})
+		lps := setupWithWsmanClient(gf, handler)
+		lps.passwordGenerator = stubPasswordGenerator{password: "G3ner@tedPassw0rd"}
+		assert.Equal(t, utils.ActivationFailed, lps.ActivateCCM())
+		_, err := os.Stat(gf.ChangePassword.OutFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("returns ActivationFailed when the password can not be generated", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respondGeneralSettings(t, w)
+		})
+		gf := &flags.Flags{}
+		gf.Activate.GeneratePassword = true
+		lps := setupWithWsmanClient(gf, handler)
+		lps.passwordGenerator = stubPasswordGenerator{err: utils.ErrFIPSUnavailable}
+		assert.Equal(t, utils.ActivationFailed, lps.ActivateCCM())
+	})
+}
+
+// stubPasswordGenerator generates the same password, or fails with err
+type stubPasswordGenerator struct {
+	password string
+	err      error
+}
+
+func (g stubPasswordGenerator) Generate(length int, noSymbols bool) (string, error) {
+	return g.password, g.err
+}
+
+func (g stubPasswordGenerator) RNG() string {
+	return "stub"
 }
 
 func TestGetHostBasedSetupService(t *testing.T) {
@@ -253,6 +363,76 @@ func TestActivateACM(t *testing.T) {
 	assert.Equal(t, utils.Success, rc)
 }
 
+func TestUpgradeToACM(t *testing.T) {
+	f := &flags.Flags{}
+	f.LocalConfig.ACMSettings.AMTPassword = "P@ssw0rd"
+	testCerts := getTestCerts()
+	f.LocalConfig.ACMSettings.ProvisioningCert = testCerts.Pfxb64
+	f.LocalConfig.ACMSettings.ProvisioningCertPwd = testCerts.PfxPassword
+	mockCertHashes = []amt2.CertHashEntry{{Hash: testCerts.CaFingerprint, IsActive: true, IsDefault: true}}
+
+	// the host based setup service answers everything but the upgrade
+	responses := func(upgrade http.HandlerFunc) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(b), "UpgradeClientToAdmin_INPUT") {
+				upgrade(w, r)
+				return
+			}
+			respondHostBasedSetup(t, w)
+		})
+	}
+	t.Run("upgrades with the provisioning certificate", func(t *testing.T) {
+		lps := setupWithWsmanClient(f, responses(respondMsgFunc(t, UpgradeClientToAdminResponse{})))
+		assert.Equal(t, utils.Success, lps.UpgradeToACM())
+	})
+	t.Run("fails on ReturnValue", func(t *testing.T) {
+		rsp := UpgradeClientToAdminResponse{}
+		rsp.Body.Output.ReturnValue = 2
+		lps := setupWithWsmanClient(f, responses(respondMsgFunc(t, rsp)))
+		assert.Equal(t, utils.ActivationFailed, lps.UpgradeToACM())
+	})
+	t.Run("succeeds when AMT drops the connection after the upgrade", func(t *testing.T) {
+		mockControlMode = 2
+		defer func() { mockControlMode = 0 }()
+		lps := setupWithWsmanClient(f, responses(respondServerErrFunc()))
+		assert.Equal(t, utils.Success, lps.UpgradeToACM())
+	})
+}
+
+func TestSetMEBxPassword(t *testing.T) {
+	f := &flags.Flags{}
+	f.MEBxPassword = "Mebx&Passw0rd"
+	f.LocalConfig.ACMSettings.AMTPassword = "P@ssw0rd"
+
+	t.Run("sets the password", func(t *testing.T) {
+		var body string
+		rfa := ResponseFuncArray{
+			func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				body = string(b)
+				respondMsgFunc(t, SetMEBxPasswordResponse{})(w, r)
+			},
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.SetMEBxPassword()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, body, "<h:Password>Mebx&amp;Passw0rd</h:Password>")
+	})
+	t.Run("fails on ReturnValue", func(t *testing.T) {
+		rsp := SetMEBxPasswordResponse{}
+		rsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, rsp)})
+		rc := lps.SetMEBxPassword()
+		assert.Equal(t, utils.SetMEBxPasswordFailed, rc)
+	})
+	t.Run("fails on server error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		rc := lps.SetMEBxPassword()
+		assert.Equal(t, utils.SetMEBxPasswordFailed, rc)
+	})
+}
+
 func TestInjectCertsErrors(t *testing.T) {
 	f := &flags.Flags{}
 	testCerts := getTestCerts()
@@ -0,0 +1,207 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"fmt"
+	"regexp"
+	"rpc/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/alarmclock"
+	ipsalarmclock "github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/ips/alarmclock"
+)
+
+type AlarmClockOccurrencePullResponse struct {
+	Body struct {
+		PullResponse struct {
+			Items []struct {
+				ElementName        string `xml:"ElementName"`
+				InstanceID         string `xml:"InstanceID"`
+				StartTime          string `xml:"StartTime>Datetime"`
+				Interval           string `xml:"Interval>Interval"`
+				DeleteOnCompletion bool   `xml:"DeleteOnCompletion"`
+			} `xml:"Items>IPS_AlarmClockOccurrence"`
+		} `xml:"PullResponse"`
+	} `xml:"Body"`
+}
+
+type AddAlarmResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"AddAlarm_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// Alarm is a wake alarm of AMT, Interval is 0 for an alarm that wakes the device once
+type Alarm struct {
+	Name               string        `json:"name"`
+	InstanceID         string        `json:"instanceId"`
+	StartTime          time.Time     `json:"startTime"`
+	Interval           time.Duration `json:"-"`
+	IntervalMinutes    int           `json:"intervalMinutes"`
+	DeleteOnCompletion bool          `json:"deleteOnCompletion"`
+}
+
+// alarmInterval matches the xs:duration of an alarm interval as AMT returns it, ex. P1DT0H0M
+var alarmInterval = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ConfigureAlarmClock adds or deletes a wake alarm and writes the alarms of AMT
+func (service *ProvisioningService) ConfigureAlarmClock() utils.ReturnCode {
+	opts := service.flags.AlarmClock
+	var rc utils.ReturnCode
+	switch {
+	case opts.Add != "":
+		rc = service.AddAlarm(Alarm{
+			Name:               opts.Add,
+			InstanceID:         opts.Add,
+			StartTime:          opts.Start,
+			Interval:           opts.Interval,
+			DeleteOnCompletion: opts.DeleteOnCompletion,
+		})
+	case opts.Delete != "":
+		rc = service.DeleteAlarm(opts.Delete)
+	}
+	if rc != utils.Success {
+		return rc
+	}
+	alarms, rc := service.GetAlarms()
+	if rc != utils.Success {
+		return rc
+	}
+	service.writeAlarms(alarms)
+	return utils.Success
+}
+
+// GetAlarms returns the wake alarms of AMT
+func (service *ProvisioningService) GetAlarms() ([]Alarm, utils.ReturnCode) {
+	var rsp AlarmClockOccurrencePullResponse
+	rc := service.EnumPullUnmarshal(
+		service.ipsMessages.AlarmClockOccurrence.Enumerate,
+		service.ipsMessages.AlarmClockOccurrence.Pull,
+		&rsp,
+	)
+	if rc != utils.Success {
+		return nil, utils.AlarmClockConfigurationFailed
+	}
+	alarms := []Alarm{}
+	for _, item := range rsp.Body.PullResponse.Items {
+		alarm := Alarm{
+			Name:               item.ElementName,
+			InstanceID:         item.InstanceID,
+			DeleteOnCompletion: item.DeleteOnCompletion,
+		}
+		if alarm.Name == "" {
+			alarm.Name = item.InstanceID
+		}
+		var err error
+		if alarm.StartTime, err = time.Parse(time.RFC3339, item.StartTime); err != nil {
+			log.Errorf("unable to parse the start time of alarm %s: %s", alarm.Name, err)
+			return nil, utils.AlarmClockConfigurationFailed
+		}
+		if alarm.Interval, err = parseAlarmInterval(item.Interval); err != nil {
+			log.Errorf("unable to parse the interval of alarm %s: %s", alarm.Name, err)
+			return nil, utils.AlarmClockConfigurationFailed
+		}
+		alarm.IntervalMinutes = int(alarm.Interval / time.Minute)
+		alarms = append(alarms, alarm)
+	}
+	return alarms, utils.Success
+}
+
+// AddAlarm adds a wake alarm, AMT refuses an alarm with the name of an existing one
+func (service *ProvisioningService) AddAlarm(alarm Alarm) utils.ReturnCode {
+	xmlMsg := service.amtMessages.AlarmClockService.AddAlarm(alarmclock.AlarmClockOccurrence{
+		ElementName:        alarm.Name,
+		InstanceID:         alarm.InstanceID,
+		StartTime:          alarm.StartTime,
+		Interval:           int(alarm.Interval / time.Minute),
+		DeleteOnCompletion: alarm.DeleteOnCompletion,
+	})
+	var rsp AddAlarmResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return utils.AlarmClockConfigurationFailed
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("AddAlarm_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.AlarmClockConfigurationFailed
+	}
+	log.Infof("Status: alarm %s added, first wake up at %s", alarm.Name, alarm.StartTime.Local().Format(time.RFC3339))
+	return utils.Success
+}
+
+// DeleteAlarm deletes the wake alarm with the name
+func (service *ProvisioningService) DeleteAlarm(name string) utils.ReturnCode {
+	alarms, rc := service.GetAlarms()
+	if rc != utils.Success {
+		return rc
+	}
+	for _, alarm := range alarms {
+		if alarm.Name != name && alarm.InstanceID != name {
+			continue
+		}
+		// InstanceID is the key of IPS_AlarmClockOccurrence, the Delete of go-wsman-messages selects by Name
+		xmlMsg, err := deleteMessage(ipsResourceURIBase+ipsalarmclock.IPS_AlarmClockOccurrence, wsmanSelector{Name: "InstanceID", Value: alarm.InstanceID})
+		if err != nil {
+			log.Error("unable to create the Delete message: ", err)
+			return utils.AlarmClockConfigurationFailed
+		}
+		if _, err := service.client.Post(xmlMsg); err != nil {
+			log.Errorf("unable to delete alarm %s: %s", name, err)
+			return utils.AlarmClockConfigurationFailed
+		}
+		log.Infof("Status: alarm %s deleted", name)
+		return utils.Success
+	}
+	log.Errorf("AMT has no alarm %s", name)
+	return utils.AlarmClockConfigurationFailed
+}
+
+func (service *ProvisioningService) writeAlarms(alarms []Alarm) {
+	w := service.newOutputWriter()
+	w.Field("alarms", "", alarms)
+	if len(alarms) == 0 {
+		w.Println("No alarms")
+	}
+	for _, alarm := range alarms {
+		w.Printf("Alarm            : %s\n", alarm.Name)
+		w.Printf("  Start          : %s\n", alarm.StartTime.Local().Format(time.RFC3339))
+		if alarm.Interval > 0 {
+			w.Printf("  Interval       : %s\n", alarm.Interval)
+		} else {
+			w.Println("  Interval       : once")
+		}
+		w.Printf("  Auto delete    : %t\n", alarm.DeleteOnCompletion)
+	}
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+}
+
+// parseAlarmInterval converts the xs:duration of an alarm interval, empty for an alarm without interval
+func parseAlarmInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parts := alarmInterval.FindStringSubmatch(value)
+	if parts == nil {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var interval time.Duration
+	for i, unit := range units {
+		if parts[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(parts[i+1])
+		if err != nil {
+			return 0, err
+		}
+		interval += time.Duration(n) * unit
+	}
+	return interval, nil
+}
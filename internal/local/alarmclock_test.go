@@ -0,0 +1,127 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const alarmsXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://intel.com/wbem/wscim/1/ips-schema/1/IPS_AlarmClockOccurrence" xmlns:h="http://schemas.dmtf.org/wbem/wscim/1/common"><a:Body><g:PullResponse><g:Items><g:IPS_AlarmClockOccurrence><g:DeleteOnCompletion>false</g:DeleteOnCompletion><g:ElementName>nightly</g:ElementName><g:InstanceID>nightly</g:InstanceID><g:Interval><h:Interval>P1DT0H0M</h:Interval></g:Interval><g:StartTime><h:Datetime>2030-01-01T02:00:00Z</h:Datetime></g:StartTime></g:IPS_AlarmClockOccurrence></g:Items></g:PullResponse></a:Body></a:Envelope>`
+
+const addAlarmXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_AlarmClockService"><a:Body><g:AddAlarm_OUTPUT><g:ReturnValue>%d</g:ReturnValue></g:AddAlarm_OUTPUT></a:Body></a:Envelope>`
+
+func TestConfigureAlarmClock(t *testing.T) {
+	f := &flags.Flags{}
+
+	t.Run("lists the alarms as JSON", func(t *testing.T) {
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, alarmsXMLResponse),
+		})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ConfigureAlarmClock())
+		var out struct {
+			Alarms []Alarm `json:"alarms"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, []Alarm{{
+			Name:            "nightly",
+			InstanceID:      "nightly",
+			StartTime:       time.Date(2030, 1, 1, 2, 0, 0, 0, time.UTC),
+			IntervalMinutes: 1440,
+		}}, out.Alarms)
+	})
+	t.Run("adds an alarm", func(t *testing.T) {
+		f.AlarmClock = flags.AlarmClockFlags{Add: "nightly", Start: time.Date(2030, 1, 1, 2, 0, 0, 0, time.UTC), Interval: 24 * time.Hour}
+		defer func() { f.AlarmClock = flags.AlarmClockFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondCheckBodyFunc(t, fmt.Sprintf(addAlarmXMLResponse, 0),
+				`>nightly</s:InstanceID>`,
+				`>2030-01-01T02:00:00Z</p:Datetime>`,
+				`>P1DT0H0M</p:Interval>`),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, alarmsXMLResponse),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureAlarmClock())
+	})
+	t.Run("fails when AMT refuses the alarm", func(t *testing.T) {
+		f.AlarmClock = flags.AlarmClockFlags{Add: "nightly", Start: time.Date(2030, 1, 1, 2, 0, 0, 0, time.UTC)}
+		defer func() { f.AlarmClock = flags.AlarmClockFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondStringFunc(t, fmt.Sprintf(addAlarmXMLResponse, 1)),
+		})
+		assert.Equal(t, utils.AlarmClockConfigurationFailed, lps.ConfigureAlarmClock())
+	})
+	t.Run("deletes an alarm by its instance id", func(t *testing.T) {
+		f.AlarmClock = flags.AlarmClockFlags{Delete: "nightly"}
+		defer func() { f.AlarmClock = flags.AlarmClockFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, alarmsXMLResponse),
+			respondCheckBodyFunc(t, "", `<w:Selector Name="InstanceID">nightly</w:Selector>`),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, AlarmClockOccurrencePullResponse{}),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureAlarmClock())
+	})
+	t.Run("escapes the instance id of the alarm it deletes", func(t *testing.T) {
+		f.AlarmClock = flags.AlarmClockFlags{Delete: "nightly"}
+		defer func() { f.AlarmClock = flags.AlarmClockFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, strings.Replace(alarmsXMLResponse, "<g:InstanceID>nightly<", "<g:InstanceID>a&amp;b&lt;c<", 1)),
+			respondCheckBodyFunc(t, "", `<w:Selector Name="InstanceID">a&amp;b&lt;c</w:Selector>`),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, AlarmClockOccurrencePullResponse{}),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureAlarmClock())
+	})
+	t.Run("fails to delete an unknown alarm", func(t *testing.T) {
+		f.AlarmClock = flags.AlarmClockFlags{Delete: "weekly"}
+		defer func() { f.AlarmClock = flags.AlarmClockFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, alarmsXMLResponse),
+		})
+		assert.Equal(t, utils.AlarmClockConfigurationFailed, lps.ConfigureAlarmClock())
+	})
+	t.Run("fails when the alarms can not be read", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.AlarmClockConfigurationFailed, lps.ConfigureAlarmClock())
+	})
+}
+
+func TestParseAlarmInterval(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":          0,
+		"P1DT0H0M":  24 * time.Hour,
+		"PT1H30M":   90 * time.Minute,
+		"P7D":       7 * 24 * time.Hour,
+		"P0DT0H15M": 15 * time.Minute,
+	}
+	for value, expected := range cases {
+		interval, err := parseAlarmInterval(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, interval, value)
+	}
+	_, err := parseAlarmInterval("every day")
+	assert.Error(t, err)
+}
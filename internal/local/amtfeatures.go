@@ -0,0 +1,89 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	internalAMT "rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/internal/i18n"
+	"rpc/pkg/pthi"
+	"rpc/pkg/utils"
+)
+
+// ConfigureAMTFeatures enables or disables AMT over the MEI as -amt asks and reads the state
+// back to verify it. The BIOS decides whether the OS may change it at all.
+func (service *ProvisioningService) ConfigureAMTFeatures() utils.ReturnCode {
+	state, err := service.amtCommand.GetChangeEnabled()
+	if err != nil {
+		log.Error(err)
+		return utils.AMTConnectionFailed
+	}
+	requested := service.flags.AMTFeatures.AMT
+	enable := requested == flags.AMTStateEnable
+	switch {
+	case requested == "":
+	case state.AMTEnabled == enable:
+		log.Infof("AMT is already %sd", requested)
+	default:
+		if rc := checkAMTStateChange(state); rc != utils.Success {
+			return rc
+		}
+		status, err := service.amtCommand.SetAMTEnabled(enable)
+		if err != nil {
+			log.Error(err)
+			return utils.AMTConnectionFailed
+		}
+		switch status {
+		case pthi.AMT_STATUS_SUCCESS:
+		case pthi.AMT_STATUS_NOT_PERMITTED:
+			log.Error("AMT does not allow changing its state from the OS")
+			return utils.AMTStateChangeNotAllowed
+		default:
+			log.Errorf("AMT did not %s AMT, status %d", requested, status)
+			return utils.AMTStateChangeFailed
+		}
+		if state, err = service.amtCommand.GetChangeEnabled(); err != nil {
+			log.Error(err)
+			return utils.AMTConnectionFailed
+		}
+		if state.AMTEnabled != enable {
+			log.Errorf("AMT accepted the change but reports it is still %s", amtStateName(state.AMTEnabled))
+			return utils.AMTStateChangeFailed
+		}
+		log.Infof("AMT is %sd", requested)
+	}
+	w := service.newOutputWriter()
+	w.Field("amtFeatures", "", state)
+	w.Println(i18n.Label("info.operationalState") + ": " + amtStateName(state.AMTEnabled))
+	allowed := i18n.T("info.notAllowed")
+	if state.TransitionAllowed && state.NewInterfaceVersion {
+		allowed = i18n.T("info.allowed")
+	}
+	w.Println(i18n.Label("info.changeFromOS") + ": " + allowed)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
+
+// checkAMTStateChange fails when the BIOS or the firmware do not let the OS change the AMT state
+func checkAMTStateChange(state internalAMT.ChangeEnabled) utils.ReturnCode {
+	if !state.TransitionAllowed {
+		log.Error("the BIOS does not allow changing the AMT state from the OS, change it in the BIOS or MEBx")
+		return utils.AMTStateChangeNotAllowed
+	}
+	if !state.NewInterfaceVersion {
+		log.Error("the firmware does not support changing the AMT state from the OS")
+		return utils.AMTStateChangeNotAllowed
+	}
+	return utils.Success
+}
+
+func amtStateName(enabled bool) string {
+	if enabled {
+		return i18n.T("info.enabled")
+	}
+	return i18n.T("info.disabled")
+}
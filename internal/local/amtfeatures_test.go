@@ -0,0 +1,76 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"errors"
+	"rpc/internal/flags"
+	"rpc/pkg/pthi"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureAMTFeatures(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandConfigure
+	f.SubCommand = utils.SubCommandAMTFeatures
+	f.AMTFeatures.AMT = flags.AMTStateDisable
+	origChangeEnabled := mockChangeEnabled
+	defer func() {
+		mockChangeEnabled = origChangeEnabled
+		mockChangeEnabledErr = nil
+		mockSetAMTEnabledStatus = 0
+	}()
+
+	t.Run("disables AMT and verifies the state", func(t *testing.T) {
+		mockChangeEnabled = origChangeEnabled
+		var out bytes.Buffer
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		lps := setupService(f)
+		lps.out = &out
+		assert.Equal(t, utils.Success, lps.Configure())
+		assert.False(t, mockChangeEnabled.AMTEnabled)
+		assert.Contains(t, out.String(), `"amtEnabled": false`)
+	})
+	t.Run("leaves a matching state alone", func(t *testing.T) {
+		mockChangeEnabled = origChangeEnabled
+		mockChangeEnabled.AMTEnabled = false
+		mockSetAMTEnabledStatus = pthi.AMT_STATUS_NOT_PERMITTED
+		defer func() { mockSetAMTEnabledStatus = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.Success, lps.ConfigureAMTFeatures())
+	})
+	t.Run("returns AMTStateChangeNotAllowed when the BIOS locks the state", func(t *testing.T) {
+		mockChangeEnabled = origChangeEnabled
+		mockChangeEnabled.TransitionAllowed = false
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTStateChangeNotAllowed, lps.ConfigureAMTFeatures())
+		assert.True(t, mockChangeEnabled.AMTEnabled)
+	})
+	t.Run("returns AMTStateChangeNotAllowed when AMT refuses the change", func(t *testing.T) {
+		mockChangeEnabled = origChangeEnabled
+		mockSetAMTEnabledStatus = pthi.AMT_STATUS_NOT_PERMITTED
+		defer func() { mockSetAMTEnabledStatus = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTStateChangeNotAllowed, lps.ConfigureAMTFeatures())
+	})
+	t.Run("returns AMTStateChangeFailed when the state does not change", func(t *testing.T) {
+		mockChangeEnabled = origChangeEnabled
+		mockSetAMTEnabledStatus = 1
+		defer func() { mockSetAMTEnabledStatus = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTStateChangeFailed, lps.ConfigureAMTFeatures())
+	})
+	t.Run("returns AMTConnectionFailed when the MEI fails", func(t *testing.T) {
+		mockChangeEnabledErr = errors.New("test error")
+		defer func() { mockChangeEnabledErr = nil }()
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTConnectionFailed, lps.ConfigureAMTFeatures())
+	})
+}
@@ -0,0 +1,393 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"fmt"
+	"rpc/internal/flags"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"sort"
+	"strings"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
+)
+
+// Results of the changes of apply
+const (
+	ApplyUnchanged = "unchanged"
+	ApplyApplied   = "applied"
+	ApplyFailed    = "failed"
+	ApplySkipped   = "skipped"
+)
+
+// notActivated is the current state of the settings that can not be read before activation
+const notActivated = "unknown, AMT is not activated"
+
+// ApplyChange is a section of the apply document with the current and desired state
+type ApplyChange struct {
+	Section string `json:"section"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+	Result  string `json:"result"`
+	// path is how the activation section reaches the desired control mode
+	path string
+	// tlsEnabled tells whether TLS only needs other settings, not a certificate
+	tlsEnabled bool
+}
+
+// Changed reports whether the section is not in the desired state
+func (c ApplyChange) Changed() bool {
+	return c.Result != ApplyUnchanged
+}
+
+// String returns the change as a line of the plan, ~ marks the sections that change
+func (c ApplyChange) String() string {
+	if !c.Changed() {
+		return fmt.Sprintf("= %-10s %s", c.Section, c.Desired)
+	}
+	return fmt.Sprintf("~ %-10s %s -> %s", c.Section, c.Current, c.Desired)
+}
+
+// Apply brings the device to the desired state of the apply document. It prints the plan
+// first and then changes only the sections that are not in the desired state, in the order
+// of the plan. A failed change stops the changes after it.
+func (service *ProvisioningService) Apply() utils.ReturnCode {
+	plan, err := service.ApplyPlan()
+	if err != nil {
+		log.Error(err)
+		return rpcerr.ReturnCodeOf(err)
+	}
+	w := service.newOutputWriter()
+	w.Println("Plan:")
+	for _, change := range plan {
+		w.Printf("  %s\n", change)
+	}
+
+	rc := utils.Success
+	for i := range plan {
+		change := &plan[i]
+		if !change.Changed() {
+			continue
+		}
+		if rc != utils.Success {
+			change.Result = ApplySkipped
+			continue
+		}
+		log.Infof("applying %s", change.Section)
+		if rc = service.applyChange(*change); rc != utils.Success {
+			change.Result = ApplyFailed
+			continue
+		}
+		change.Result = ApplyApplied
+	}
+
+	w.Println("Result:")
+	for _, change := range plan {
+		w.Printf("  %-10s %s\n", change.Section, change.Result)
+	}
+	w.Field("changes", "", plan)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return rc
+}
+
+// dryRunApply returns the plan lines of the sections apply would change
+func (service *ProvisioningService) dryRunApply() ([]string, utils.ReturnCode) {
+	plan, err := service.ApplyPlan()
+	if err != nil {
+		log.Error(err)
+		return nil, rpcerr.ReturnCodeOf(err)
+	}
+	actions := []string{}
+	for _, change := range plan {
+		if change.Changed() {
+			actions = append(actions, change.String())
+		}
+	}
+	return actions, utils.Success
+}
+
+// ApplyPlan reads the current state of each section of the document and compares it to the
+// desired state. The settings of a device that is not activated yet are not read, they change.
+func (service *ProvisioningService) ApplyPlan() ([]ApplyChange, error) {
+	sections := service.flags.Apply
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		return nil, rpcerr.Wrap(utils.AMTConnectionFailed, err, "")
+	}
+	var plan []ApplyChange
+	if sections.Has(flags.ApplySectionActivation) {
+		change, err := service.activationChange(controlMode)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, change)
+	} else if controlMode == 0 {
+		return nil, rpcerr.New(utils.UnableToActivate, "AMT is not activated, add an activation section to the document")
+	}
+
+	changes := []struct {
+		section string
+		desired func() string
+		current func(*ApplyChange) utils.ReturnCode
+	}{
+		{flags.ApplySectionHostname, service.desiredHostname, service.currentHostname},
+		{flags.ApplySectionWifi, service.desiredWifi, service.currentWifi},
+		{flags.ApplySectionTLS, service.desiredTLS, service.currentTLS},
+		{flags.ApplySectionCIRA, service.desiredCIRA, service.currentCIRA},
+	}
+	if controlMode != 0 {
+		service.setupWsmanClient("admin", service.flags.Password)
+	}
+	for _, c := range changes {
+		if !sections.Has(c.section) {
+			continue
+		}
+		change := ApplyChange{Section: c.section, Desired: c.desired(), Current: notActivated}
+		if controlMode != 0 {
+			if rc := c.current(&change); rc != utils.Success {
+				return nil, rpcerr.Newf(rc, "unable to read the current %s settings", c.section)
+			}
+		}
+		change.Result = ApplyUnchanged
+		if change.Current != change.Desired {
+			change.Result = ""
+		}
+		plan = append(plan, change)
+	}
+	return plan, nil
+}
+
+func (service *ProvisioningService) activationChange(controlMode int) (ApplyChange, error) {
+	desired := 1
+	if service.flags.UseACM {
+		desired = 2
+	}
+	change := ApplyChange{
+		Section: flags.ApplySectionActivation,
+		Current: utils.InterpretControlMode(controlMode),
+		Desired: utils.InterpretControlMode(desired),
+	}
+	if controlMode == 2 && desired == 1 {
+		return change, rpcerr.New(utils.UnableToActivate, "apply does not move a device from admin to client control mode, deactivate it first")
+	}
+	path, err := service.flags.ActivationPath(controlMode)
+	if err != nil {
+		return change, err
+	}
+	change.path = path
+	if path == flags.ActivationPathNone {
+		change.Result = ApplyUnchanged
+	}
+	return change, nil
+}
+
+func (service *ProvisioningService) desiredHostname() string {
+	return service.flags.HostnameInfo.Hostname
+}
+
+func (service *ProvisioningService) currentHostname(change *ApplyChange) utils.ReturnCode {
+	settings, err := service.GetGeneralSettings()
+	if err != nil {
+		log.Error(err)
+		return utils.AMTConnectionFailed
+	}
+	change.Current = settings.Body.AMTGeneralSettings.HostName
+	return utils.Success
+}
+
+// wifiProfileSummary describes a WiFi profile by the settings AMT reports, the passphrase
+// can not be read back so a changed passphrase alone is not detected
+func wifiProfileSummary(name string, ssid string, priority int, authentication int, encryption int) string {
+	return fmt.Sprintf("%s(ssid %s, priority %d, auth %d, enc %d)", name, ssid, priority, authentication, encryption)
+}
+
+func (service *ProvisioningService) desiredWifi() string {
+	var profiles []string
+	for _, cfg := range service.flags.LocalConfig.WifiConfigs {
+		profiles = append(profiles, wifiProfileSummary(cfg.ProfileName, cfg.SSID, cfg.Priority, cfg.AuthenticationMethod, cfg.EncryptionMethod))
+	}
+	return joinSorted(profiles, "no profiles")
+}
+
+func (service *ProvisioningService) currentWifi(change *ApplyChange) utils.ReturnCode {
+	var pullRspEnv wifi.PullResponseEnvelope
+	rc := service.EnumPullUnmarshal(
+		service.cimMessages.WiFiEndpointSettings.Enumerate,
+		service.cimMessages.WiFiEndpointSettings.Pull,
+		&pullRspEnv,
+	)
+	if rc != utils.Success {
+		return rc
+	}
+	var profiles []string
+	for _, item := range pullRspEnv.Body.PullResponse.Items {
+		if item.InstanceID == "" {
+			continue
+		}
+		profiles = append(profiles, wifiProfileSummary(item.ElementName, item.SSID, item.Priority, item.AuthenticationMethod, item.EncryptionMethod))
+	}
+	change.Current = joinSorted(profiles, "no profiles")
+	return utils.Success
+}
+
+func (service *ProvisioningService) desiredTLS() string {
+	settings := service.flags.TLSSettings
+	desired := "enabled, " + settings.Mode.String()
+	if settings.Local {
+		desired += ", local"
+	}
+	return desired
+}
+
+func (service *ProvisioningService) currentTLS(change *ApplyChange) utils.ReturnCode {
+	var settings TLSSettingDataPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.TLSSettingData.Enumerate,
+		service.amtMessages.TLSSettingData.Pull,
+		&settings,
+	)
+	if rc != utils.Success {
+		return rc
+	}
+	change.Current = "disabled"
+	var local bool
+	for _, item := range settings.Body.PullResponse.Items {
+		if item.InstanceID == localTLSInstanceID {
+			local = item.Enabled
+		}
+	}
+	for _, item := range settings.Body.PullResponse.Items {
+		if item.InstanceID != remoteTLSInstanceID || !item.Enabled {
+			continue
+		}
+		mode := flags.TLSModeServer
+		switch {
+		case item.MutualAuthentication && item.AcceptNonSecureConnections:
+			mode = flags.TLSModeMutualAndNonTLS
+		case item.MutualAuthentication:
+			mode = flags.TLSModeMutual
+		case item.AcceptNonSecureConnections:
+			mode = flags.TLSModeServerAndNonTLS
+		}
+		change.Current = "enabled, " + mode.String()
+		// the local interface is left as it is unless the document enables it
+		if local && service.flags.TLSSettings.Local {
+			change.Current += ", local"
+		}
+		change.tlsEnabled = true
+	}
+	return utils.Success
+}
+
+func (service *ProvisioningService) desiredCIRA() string {
+	cira := service.flags.CIRASettings
+	var servers []string
+	for _, mps := range desiredMPSServers(cira) {
+		servers = append(servers, fmt.Sprintf("%s:%d", mps.Hostname, mps.Port))
+	}
+	return ciraSummary(strings.Join(servers, ", "), cira.PeriodicInterval, cira.EnvironmentDetection)
+}
+
+func (service *ProvisioningService) currentCIRA(change *ApplyChange) utils.ReturnCode {
+	var info RemoteAccessInfo
+	if rc := service.GetCIRAConfiguration(&info); rc != utils.Success {
+		return rc
+	}
+	if len(info.MPSServers) == 0 {
+		change.Current = "not configured"
+		return utils.Success
+	}
+	var servers []string
+	for _, mps := range info.MPSServers {
+		servers = append(servers, fmt.Sprintf("%s:%d", mps.Hostname, mps.Port))
+	}
+	periodic := 0
+	for _, trigger := range info.Triggers {
+		if trigger.PeriodicInterval > 0 {
+			periodic = trigger.PeriodicInterval
+		}
+	}
+	change.Current = ciraSummary(strings.Join(servers, ", "), periodic, info.EnvironmentDetection)
+	return utils.Success
+}
+
+func ciraSummary(servers string, periodic int, domains []string) string {
+	return fmt.Sprintf("%s, periodic %ds, environment detection %s", servers, periodic, joinSorted(domains, "none"))
+}
+
+// joinSorted joins the values in order, so the current and desired states compare equal
+// whatever order AMT reports them in
+func joinSorted(values []string, empty string) string {
+	if len(values) == 0 {
+		return empty
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// applyChange brings one section of the document to the desired state
+func (service *ProvisioningService) applyChange(change ApplyChange) utils.ReturnCode {
+	switch change.Section {
+	case flags.ApplySectionActivation:
+		controlMode, err := service.amtCommand.GetControlMode()
+		if err != nil {
+			log.Error(err)
+			return utils.AMTConnectionFailed
+		}
+		rc := service.activateByPath(controlMode, change.path)
+		// the other sections are configured with the admin password set by the activation
+		service.setupWsmanClient("admin", service.flags.Password)
+		return rc
+	case flags.ApplySectionHostname:
+		return service.SetHostname(service.flags.HostnameInfo.Hostname)
+	case flags.ApplySectionWifi:
+		if service.lacksWiFi(log.Warnf) {
+			log.Info("Status: the wifi section is skipped")
+			return utils.Success
+		}
+		return service.AddWifiSettings()
+	case flags.ApplySectionTLS:
+		if !change.tlsEnabled {
+			return service.EnableTLS()
+		}
+		// TLS already has its certificate, only the mode and the trusted CA change
+		if ca := service.flags.TLSSettings.CACert; ca != "" {
+			service.handlesWithCerts = make(map[string]string)
+			if _, rc := service.AddTrustedRootCert(ca); rc != utils.Success {
+				return rc
+			}
+		}
+		return service.PutTLSSettings()
+	case flags.ApplySectionCIRA:
+		return service.ConfigureCIRA()
+	}
+	return utils.IncorrectCommandLineParameters
+}
+
+// SetHostname sets the host name of AMT
+func (service *ProvisioningService) SetHostname(hostname string) utils.ReturnCode {
+	generalSettings, err := service.GetGeneralSettings()
+	if err != nil {
+		log.Error("unable to read general settings: ", err)
+		return utils.SyncHostnameFailed
+	}
+	settings := generalSettings.Body.AMTGeneralSettings
+	log.Infof("updating AMT host name from '%s' to '%s'", settings.HostName, hostname)
+	settings.HostName = hostname
+	var putRsp general.Response
+	if rc := service.PostAndUnmarshal(service.amtMessages.GeneralSettings.Put(settings), &putRsp); rc != utils.Success {
+		return utils.SyncHostnameFailed
+	}
+	if putRsp.Body.AMTGeneralSettings.HostName != hostname {
+		log.Error("AMT did not accept the host name")
+		return utils.SyncHostnameFailed
+	}
+	return utils.Success
+}
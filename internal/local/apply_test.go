@@ -0,0 +1,121 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandApply
+	f.JsonOutput = true
+	f.Password = "P@ssw0rd"
+	f.Apply.Sections = []string{flags.ApplySectionHostname, flags.ApplySectionTLS}
+	f.HostnameInfo.Hostname = "amt-device"
+	f.TLSSettings.Mode = flags.TLSModeServer
+	origMode := mockControlMode
+	defer func() { mockControlMode = origMode }()
+
+	current := general.Response{}
+	current.Body.AMTGeneralSettings.HostName = "old-name"
+	updated := general.Response{}
+	updated.Body.AMTGeneralSettings.HostName = "amt-device"
+	tlsSettings := TLSSettingDataPullResponse{}
+	tlsSettings.Body.PullResponse.Items = []TLSSettingDataItem{{InstanceID: remoteTLSInstanceID, Enabled: true}}
+
+	run := func(rfa ResponseFuncArray) (utils.ReturnCode, []ApplyChange) {
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.Apply()
+		var result struct {
+			Changes []ApplyChange `json:"changes"`
+		}
+		if buf.Len() > 0 {
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		}
+		return rc, result.Changes
+	}
+
+	t.Run("changes only the sections not in the desired state", func(t *testing.T) {
+		mockControlMode = 2
+		rc, changes := run(ResponseFuncArray{
+			respondMsgFunc(t, current),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondMsgFunc(t, current),
+			respondMsgFunc(t, updated),
+		})
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, []ApplyChange{
+			{Section: flags.ApplySectionHostname, Current: "old-name", Desired: "amt-device", Result: ApplyApplied},
+			{Section: flags.ApplySectionTLS, Current: "enabled, Server", Desired: "enabled, Server", Result: ApplyUnchanged},
+		}, changes)
+	})
+	t.Run("fails when AMT rejects a change", func(t *testing.T) {
+		mockControlMode = 2
+		rc, changes := run(ResponseFuncArray{
+			respondMsgFunc(t, current),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, TLSSettingDataPullResponse{}),
+			respondMsgFunc(t, current),
+			respondMsgFunc(t, current),
+		})
+		assert.Equal(t, utils.SyncHostnameFailed, rc)
+		assert.Equal(t, ApplyFailed, changes[0].Result)
+		assert.Equal(t, "disabled", changes[1].Current)
+		assert.Equal(t, ApplySkipped, changes[1].Result)
+	})
+	t.Run("needs an activation section when AMT is not activated", func(t *testing.T) {
+		mockControlMode = 0
+		rc, changes := run(ResponseFuncArray{})
+		assert.Equal(t, utils.UnableToActivate, rc)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestApplyPlan(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandApply
+	f.Local = true
+	f.Password = "P@ssw0rd"
+	f.UseCCM = true
+	f.Apply.Sections = []string{flags.ApplySectionActivation, flags.ApplySectionHostname}
+	f.HostnameInfo.Hostname = "amt-device"
+	origMode := mockControlMode
+	defer func() { mockControlMode = origMode }()
+
+	t.Run("plans the activation and the settings after it", func(t *testing.T) {
+		mockControlMode = 0
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		plan, err := lps.ApplyPlan()
+		assert.NoError(t, err)
+		assert.Len(t, plan, 2)
+		assert.True(t, plan[0].Changed())
+		assert.Equal(t, flags.ActivationPathActivate, plan[0].path)
+		assert.Equal(t, notActivated, plan[1].Current)
+		assert.True(t, plan[1].Changed())
+	})
+	t.Run("does not move admin to client control mode", func(t *testing.T) {
+		mockControlMode = 2
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		_, err := lps.ApplyPlan()
+		assert.Error(t, err)
+	})
+	t.Run("dry run lists the changes", func(t *testing.T) {
+		mockControlMode = 1
+		current := general.Response{}
+		current.Body.AMTGeneralSettings.HostName = "amt-device"
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, current)})
+		actions, rc := lps.dryRunApply()
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, []string{}, actions)
+	})
+}
@@ -0,0 +1,188 @@
+package local
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+)
+
+type ReadRecordsResponse struct {
+	Body struct {
+		Output struct {
+			TotalRecordCount int      `xml:"TotalRecordCount"`
+			RecordsReturned  int      `xml:"RecordsReturned"`
+			EventRecords     []string `xml:"EventRecords"`
+			ReturnValue      int      `xml:"ReturnValue"`
+		} `xml:"ReadRecords_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// AuditLog holds the audit log records selected with -count and -offset
+type AuditLog struct {
+	TotalRecords int              `json:"totalRecords"`
+	Records      []AuditLogRecord `json:"records"`
+}
+
+type AuditLogRecord struct {
+	AuditAppID    int       `json:"auditAppId"`
+	AuditApp      string    `json:"auditApp"`
+	EventID       int       `json:"eventId"`
+	InitiatorType string    `json:"initiatorType"`
+	Initiator     string    `json:"initiator"`
+	Time          time.Time `json:"time"`
+	NetAddress    string    `json:"netAddress,omitempty"`
+	ExtendedData  string    `json:"extendedData,omitempty"`
+}
+
+var auditAppNames = map[int]string{
+	16: "Security Admin",
+	17: "RCO",
+	18: "Redirection Manager",
+	19: "Firmware Update Manager",
+	20: "Security Audit Log",
+	21: "Network Time",
+	22: "Network Administration",
+	23: "Storage Administration",
+	24: "Event Manager",
+	25: "System Defense Manager",
+	26: "Agent Presence Manager",
+	27: "Wireless Configuration",
+	28: "EAC",
+	29: "KVM",
+	30: "User Opt-In",
+	32: "Screen Blanking",
+	33: "Watchdog",
+}
+
+const (
+	initiatorHTTPDigest     = 0
+	initiatorKerberos       = 1
+	initiatorLocal          = 2
+	initiatorKVMDefaultPort = 3
+)
+
+var errShortAuditRecord = errors.New("audit log record is truncated")
+
+// GetAuditLog reads count audit log records after skipping offset records, a count of 0 reads all records
+func (service *ProvisioningService) GetAuditLog(offset int, count int) (AuditLog, utils.ReturnCode) {
+	auditLog := AuditLog{Records: []AuditLogRecord{}}
+	// AMT numbers the records from 1
+	startIndex := offset + 1
+	for count == 0 || len(auditLog.Records) < count {
+		var rsp ReadRecordsResponse
+		rc := service.PostAndUnmarshal(service.amtMessages.AuditLog.ReadRecords(startIndex), &rsp)
+		if rc != utils.Success {
+			return auditLog, rc
+		}
+		if rsp.Body.Output.ReturnValue != 0 {
+			log.Errorf("ReadRecords_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+			return auditLog, utils.AmtPtStatusCodeBase + utils.ReturnCode(rsp.Body.Output.ReturnValue)
+		}
+		auditLog.TotalRecords = rsp.Body.Output.TotalRecordCount
+		for _, eventRecord := range rsp.Body.Output.EventRecords {
+			record, err := decodeAuditLogRecord(eventRecord)
+			if err != nil {
+				log.Error("unable to decode audit log record: ", err)
+				return auditLog, utils.UnmarshalMessageFailed
+			}
+			auditLog.Records = append(auditLog.Records, record)
+		}
+		startIndex += rsp.Body.Output.RecordsReturned
+		if rsp.Body.Output.RecordsReturned == 0 || startIndex > auditLog.TotalRecords {
+			break
+		}
+	}
+	if count > 0 && len(auditLog.Records) > count {
+		auditLog.Records = auditLog.Records[:count]
+	}
+	return auditLog, utils.Success
+}
+
+// decodeAuditLogRecord decodes the base64 encoded binary audit record returned by AMT_AuditLog.ReadRecords
+func decodeAuditLogRecord(eventRecord string) (AuditLogRecord, error) {
+	record := AuditLogRecord{}
+	data, err := base64.StdEncoding.DecodeString(eventRecord)
+	if err != nil {
+		return record, err
+	}
+	if len(data) < 5 {
+		return record, errShortAuditRecord
+	}
+	record.AuditAppID = int(binary.BigEndian.Uint16(data[0:2]))
+	record.EventID = int(binary.BigEndian.Uint16(data[2:4]))
+	record.AuditApp = auditAppNames[record.AuditAppID]
+	if record.AuditApp == "" {
+		record.AuditApp = fmt.Sprintf("App(%d)", record.AuditAppID)
+	}
+
+	ptr := 5
+	switch data[4] {
+	case initiatorHTTPDigest:
+		record.InitiatorType = "HTTP Digest"
+		if len(data) < 6 || len(data) < 6+int(data[5]) {
+			return record, errShortAuditRecord
+		}
+		record.Initiator = string(data[6 : 6+int(data[5])])
+		ptr = 6 + int(data[5])
+	case initiatorKerberos:
+		record.InitiatorType = "Kerberos"
+		if len(data) < 10 || len(data) < 10+int(data[9]) {
+			return record, errShortAuditRecord
+		}
+		record.Initiator = sidString(data[10 : 10+int(data[9])])
+		ptr = 10 + int(data[9])
+	case initiatorLocal:
+		record.InitiatorType = "Local"
+		record.Initiator = "Local"
+	case initiatorKVMDefaultPort:
+		record.InitiatorType = "KVM Default Port"
+		record.Initiator = "KVM Default Port"
+	default:
+		record.InitiatorType = fmt.Sprintf("Unknown(%d)", data[4])
+	}
+
+	// timestamp, location type and network address length
+	if len(data) < ptr+6 {
+		return record, errShortAuditRecord
+	}
+	record.Time = time.Unix(int64(binary.BigEndian.Uint32(data[ptr:ptr+4])), 0).UTC()
+	ptr += 5
+	netLen := int(data[ptr])
+	ptr++
+	if len(data) < ptr+netLen {
+		return record, errShortAuditRecord
+	}
+	record.NetAddress = strings.TrimRight(string(data[ptr:ptr+netLen]), "\x00")
+	ptr += netLen
+	if len(data) > ptr {
+		exLen := int(data[ptr])
+		ptr++
+		if len(data) < ptr+exLen {
+			return record, errShortAuditRecord
+		}
+		if exLen > 0 {
+			record.ExtendedData = base64.StdEncoding.EncodeToString(data[ptr : ptr+exLen])
+		}
+	}
+	return record, nil
+}
+
+// sidString formats a binary Windows security identifier as S-R-A-S1-S2...
+func sidString(sid []byte) string {
+	if len(sid) < 8 {
+		return ""
+	}
+	var authority uint64
+	for _, b := range sid[2:8] {
+		authority = authority<<8 | uint64(b)
+	}
+	s := fmt.Sprintf("S-%d-%d", sid[0], authority)
+	for i := 8; i+4 <= len(sid) && (i-8)/4 < int(sid[1]); i += 4 {
+		s += fmt.Sprintf("-%d", binary.LittleEndian.Uint32(sid[i:i+4]))
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package local
+
+import (
+	"encoding/base64"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// auditRecord builds an audit record as returned by AMT with a HTTP digest initiator
+func auditRecord(appID byte, eventID byte, user string, timestamp uint32, netAddress string) string {
+	data := []byte{0, appID, 0, eventID, initiatorHTTPDigest, byte(len(user))}
+	data = append(data, user...)
+	data = append(data, byte(timestamp>>24), byte(timestamp>>16), byte(timestamp>>8), byte(timestamp))
+	data = append(data, 0, byte(len(netAddress)))
+	data = append(data, netAddress...)
+	data = append(data, 0)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func readRecordsResponse(total int, records ...string) ReadRecordsResponse {
+	rsp := ReadRecordsResponse{}
+	rsp.Body.Output.TotalRecordCount = total
+	rsp.Body.Output.RecordsReturned = len(records)
+	rsp.Body.Output.EventRecords = records
+	return rsp
+}
+
+func TestDecodeAuditLogRecord(t *testing.T) {
+	t.Run("decodes http digest initiator", func(t *testing.T) {
+		record, err := decodeAuditLogRecord(auditRecord(16, 1, "admin", 1700000000, "192.168.1.10"))
+		assert.NoError(t, err)
+		assert.Equal(t, AuditLogRecord{
+			AuditAppID:    16,
+			AuditApp:      "Security Admin",
+			EventID:       1,
+			InitiatorType: "HTTP Digest",
+			Initiator:     "admin",
+			Time:          time.Unix(1700000000, 0).UTC(),
+			NetAddress:    "192.168.1.10",
+		}, record)
+	})
+	t.Run("decodes local initiator", func(t *testing.T) {
+		data := []byte{0, 99, 0, 2, initiatorLocal, 0, 0, 0, 1, 2, 0, 0}
+		record, err := decodeAuditLogRecord(base64.StdEncoding.EncodeToString(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "App(99)", record.AuditApp)
+		assert.Equal(t, "Local", record.Initiator)
+		assert.Equal(t, time.Unix(1, 0).UTC(), record.Time)
+	})
+	t.Run("decodes kerberos sid", func(t *testing.T) {
+		sid := []byte{1, 2, 0, 0, 0, 0, 0, 5, 21, 0, 0, 0, 0xe9, 0x03, 0, 0}
+		data := append([]byte{0, 16, 0, 1, initiatorKerberos, 0, 0, 0, 0, byte(len(sid))}, sid...)
+		data = append(data, 0, 0, 0, 0, 2, 0)
+		record, err := decodeAuditLogRecord(base64.StdEncoding.EncodeToString(data))
+		assert.NoError(t, err)
+		assert.Equal(t, "S-1-5-21-1001", record.Initiator)
+	})
+	t.Run("fails on truncated record", func(t *testing.T) {
+		_, err := decodeAuditLogRecord(base64.StdEncoding.EncodeToString([]byte{0, 16, 0, 1, initiatorHTTPDigest, 10, 'a'}))
+		assert.Equal(t, errShortAuditRecord, err)
+	})
+	t.Run("fails on invalid base64", func(t *testing.T) {
+		_, err := decodeAuditLogRecord("not base64!")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetAuditLog(t *testing.T) {
+	f := &flags.Flags{}
+	first := auditRecord(16, 1, "admin", 1700000000, "192.168.1.10")
+	second := auditRecord(19, 0, "admin", 1700000100, "192.168.1.10")
+
+	t.Run("reads all pages", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, readRecordsResponse(3, first, second)),
+			respondMsgFunc(t, readRecordsResponse(3, second)),
+		})
+		auditLog, rc := lps.GetAuditLog(0, 0)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, 3, auditLog.TotalRecords)
+		assert.Len(t, auditLog.Records, 3)
+	})
+	t.Run("limits to count", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, readRecordsResponse(3, first, second)),
+		})
+		auditLog, rc := lps.GetAuditLog(1, 1)
+		assert.Equal(t, utils.Success, rc)
+		assert.Len(t, auditLog.Records, 1)
+		assert.Equal(t, "Security Admin", auditLog.Records[0].AuditApp)
+	})
+	t.Run("returns PT status on ReturnValue", func(t *testing.T) {
+		rsp := readRecordsResponse(0)
+		rsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, rsp)})
+		_, rc := lps.GetAuditLog(0, 0)
+		assert.Equal(t, utils.AmtPtStatusCodeBase+1, rc)
+	})
+	t.Run("returns UnmarshalMessageFailed on bad record", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, readRecordsResponse(1, "AAAA")),
+		})
+		_, rc := lps.GetAuditLog(0, 0)
+		assert.Equal(t, utils.UnmarshalMessageFailed, rc)
+	})
+	t.Run("returns WSMANMessageError on server error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		_, rc := lps.GetAuditLog(0, 0)
+		assert.Equal(t, utils.WSMANMessageError, rc)
+	})
+}
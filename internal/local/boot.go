@@ -0,0 +1,55 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"rpc/pkg/utils"
+
+	cimBoot "github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/boot"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/power"
+)
+
+// bootSources maps the -source values of boot to the CIM_BootSourceSetting of AMT
+var bootSources = map[string]string{
+	"pxe": bootSourcePXE,
+	"hdd": bootSourceHDD,
+	"cd":  bootSourceCD,
+}
+
+// Boot has the device boot from -source on the next boot, and resets it with -reset. AMT
+// applies the boot settings to one boot only, the boot after it uses the BIOS boot order.
+func (service *ProvisioningService) Boot() utils.ReturnCode {
+	boot := service.flags.Boot
+	source, ok := bootSources[boot.Source]
+	if !ok {
+		return utils.IncorrectCommandLineParameters
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	settings := bootSettingDataInput{InstanceID: bootSettingDataInstanceID}
+	if err := service.setBootConfiguration(settings, cimBoot.IsNextSingleUse, source); err != nil {
+		log.Error("unable to set the boot source: ", err)
+		return utils.BootConfigurationFailed
+	}
+	log.Infof("Status: boot from %s on the next boot", boot.Source)
+	if !boot.Reset {
+		return utils.Success
+	}
+	return service.requestPowerState(power.MasterBusReset, utils.SubCommandPowerReset)
+}
+
+func (service *ProvisioningService) dryRunBoot() ([]string, utils.ReturnCode) {
+	boot := service.flags.Boot
+	if _, ok := bootSources[boot.Source]; !ok {
+		return nil, utils.IncorrectCommandLineParameters
+	}
+	if _, rc := service.dryRunLogin(); rc != utils.Success {
+		return nil, rc
+	}
+	actions := []string{"boot from " + boot.Source + " on the next boot"}
+	if boot.Reset {
+		actions = append(actions, "power reset the device")
+	}
+	return actions, utils.Success
+}
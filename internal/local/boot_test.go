@@ -0,0 +1,83 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/stretchr/testify/assert"
+)
+
+// requestContains answers with msg after checking the request holds want
+func requestContains(t *testing.T, want string, msg any) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), want)
+		respondMsgFunc(t, msg)(w, r)
+	}
+}
+
+func TestBoot(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandBoot
+	f.Password = "P@ssw0rd"
+
+	t.Run("boots from PXE on the next boot and resets", func(t *testing.T) {
+		f.Boot = flags.BootFlags{Source: "pxe", Reset: true}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			requestContains(t, "<h:Role>1</h:Role>", SetBootConfigRoleResponse{}),
+			requestContains(t, bootSourcePXE, ChangeBootOrderResponse{}),
+			respondMsgFunc(t, powerResponse(0)),
+		})
+		assert.Equal(t, utils.Success, lps.Boot())
+	})
+	t.Run("boots from the CD on the next boot", func(t *testing.T) {
+		f.Boot = flags.BootFlags{Source: "cd"}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			requestContains(t, "<h:Role>1</h:Role>", SetBootConfigRoleResponse{}),
+			requestContains(t, bootSourceCD, ChangeBootOrderResponse{}),
+		})
+		assert.Equal(t, utils.Success, lps.Boot())
+	})
+	t.Run("returns BootConfigurationFailed when AMT refuses the boot order", func(t *testing.T) {
+		f.Boot = flags.BootFlags{Source: "hdd", Reset: true}
+		orderRsp := ChangeBootOrderResponse{}
+		orderRsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			respondMsgFunc(t, SetBootConfigRoleResponse{}),
+			respondMsgFunc(t, orderRsp),
+		})
+		assert.Equal(t, utils.BootConfigurationFailed, lps.Boot())
+	})
+	t.Run("returns PowerActionFailed when the reset fails", func(t *testing.T) {
+		f.Boot = flags.BootFlags{Source: "pxe", Reset: true}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			respondMsgFunc(t, SetBootConfigRoleResponse{}),
+			respondMsgFunc(t, ChangeBootOrderResponse{}),
+			respondMsgFunc(t, powerResponse(2)),
+		})
+		assert.Equal(t, utils.PowerActionFailed, lps.Boot())
+	})
+}
+
+func TestDryRunBoot(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandBoot
+	f.DryRun = true
+	f.Password = "P@ssw0rd"
+	f.Boot = flags.BootFlags{Source: "pxe", Reset: true}
+	var out bytes.Buffer
+	lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+	lps.out = &out
+	assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+	assert.Contains(t, out.String(), "boot from pxe on the next boot")
+	assert.Contains(t, out.String(), "power reset the device")
+}
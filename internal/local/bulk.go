@@ -0,0 +1,119 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// BulkResult is the outcome of the command on one device of the device list
+type BulkResult struct {
+	Host       string           `json:"host"`
+	ReturnCode utils.ReturnCode `json:"returnCode"`
+	Result     string           `json:"result"`
+	// Output is the JSON output of the command, or its text when it is not JSON
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// Bulk runs the command of -command on the devices of the device list, -workers of them
+// at the same time. Every device is tried even if others failed, the return code of the
+// first failed device in the list is returned.
+func (service *ProvisioningService) Bulk() utils.ReturnCode {
+	bulk := service.flags.Bulk
+	log.Infof("running '%s' on %d devices", strings.Join(bulk.Command, " "), len(bulk.Devices))
+	results := make([]BulkResult, len(bulk.Devices))
+	tasks := make([]func(), len(bulk.Devices))
+	for i := range bulk.Devices {
+		i := i
+		tasks[i] = func() { results[i] = service.runBulkDevice(i) }
+	}
+	info.RunConcurrently(bulk.Workers, tasks)
+
+	rc := utils.Success
+	for _, result := range results {
+		if rc == utils.Success {
+			rc = result.ReturnCode
+		}
+	}
+	if rc != utils.Success && service.cancelled() {
+		rc = utils.CancelledByUser
+	}
+	if bulk.Report != "" {
+		if err := writeBulkReport(bulk.Report, results); err != nil {
+			log.Error("unable to write the bulk report: ", err)
+		}
+	}
+	if err := writeBulkResults(service.out, results, service.flags.JsonOutput); err != nil {
+		log.Error(err)
+	}
+	return rc
+}
+
+// runBulkDevice runs the command on the device with its own flags, the devices left when
+// rpc is cancelled are not run
+func (service *ProvisioningService) runBulkDevice(i int) BulkResult {
+	device := service.flags.Bulk.Devices[i]
+	result := BulkResult{Host: device.Host, ReturnCode: utils.CancelledByUser, Result: "not run"}
+	if service.cancelled() {
+		return result
+	}
+	device.Flags.Context = service.flags.Context
+	var out bytes.Buffer
+	result.ReturnCode = ExecuteCommandTo(device.Flags, &out)
+	result.Result = "success"
+	if result.ReturnCode != utils.Success {
+		result.Result = "failed"
+		log.Errorf("%s failed with return code %d (%s)", device.Host, result.ReturnCode, result.ReturnCode)
+	}
+	output := bytes.TrimSpace(out.Bytes())
+	switch {
+	case len(output) == 0:
+	case json.Valid(output):
+		result.Output = output
+	default:
+		result.Output, _ = json.Marshal(string(output))
+	}
+	return result
+}
+
+func writeBulkReport(path string, results []BulkResult) error {
+	content, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0600)
+}
+
+// writeBulkResults prints the result of each device as a table or as a JSON array
+func writeBulkResults(w io.Writer, results []BulkResult, jsonOutput bool) error {
+	if jsonOutput {
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		line := fmt.Sprintf("%-24s: %s", result.Host, result.Result)
+		if result.ReturnCode != utils.Success {
+			failed++
+			line += fmt.Sprintf(" (%d %s)", result.ReturnCode, result.ReturnCode)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d devices succeeded\n", len(results)-failed, len(results))
+	return err
+}
@@ -0,0 +1,72 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(hostBasedSetupXMLResponse))
+	}))
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	serverURL, _ := url.Parse(server.URL)
+	failingURL, _ := url.Parse(failing.URL)
+	devices := fmt.Sprintf(`[{"host": "127.0.0.1", "amtPort": %s}, {"host": "127.0.0.1", "amtPort": %s, "password": "Other-P@ssw0rd"}]`, serverURL.Port(), failingURL.Port())
+	dir := t.TempDir()
+	file := filepath.Join(dir, "devices.json")
+	envelope := filepath.Join(dir, "envelope.xml")
+	report := filepath.Join(dir, "report.json")
+	assert.NoError(t, os.WriteFile(file, []byte(devices), 0600))
+	assert.NoError(t, os.WriteFile(envelope, []byte(`<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Header/><s:Body/></s:Envelope>`), 0600))
+
+	f := flags.NewFlags([]string{"rpc", "bulk", "-file", file, "-password", "P@ssw0rd", "-workers", "2", "-report", report, "wsman", "-xml", envelope})
+	assert.Equal(t, utils.Success, f.ParseFlags())
+	var out bytes.Buffer
+	rc := ExecuteCommandTo(f, &out)
+	assert.Equal(t, utils.WSMANMessageError, rc, "the return code of the failed device is returned")
+	assert.Contains(t, out.String(), "1 of 2 devices succeeded")
+
+	content, err := os.ReadFile(report)
+	assert.NoError(t, err)
+	var results []BulkResult
+	assert.NoError(t, json.Unmarshal(content, &results))
+	assert.Len(t, results, 2)
+	assert.Equal(t, utils.Success, results[0].ReturnCode)
+	assert.Equal(t, "success", results[0].Result)
+	assert.Contains(t, string(results[0].Output), "CurrentControlMode")
+	assert.Equal(t, rc, results[1].ReturnCode)
+	assert.Equal(t, "failed", results[1].Result)
+}
+
+func TestWriteBulkResults(t *testing.T) {
+	results := []BulkResult{
+		{Host: "amt1.example.com", ReturnCode: utils.Success, Result: "success"},
+		{Host: "amt2.example.com", ReturnCode: utils.CancelledByUser, Result: "not run"},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, writeBulkResults(&out, results, false))
+	assert.Equal(t, fmt.Sprintf("amt1.example.com        : success\namt2.example.com        : not run (%d CancelledByUser)\n1 of 2 devices succeeded\n", utils.CancelledByUser), out.String())
+	out.Reset()
+	assert.NoError(t, writeBulkResults(&out, results, true))
+	assert.Contains(t, out.String(), `"host": "amt2.example.com"`)
+}
@@ -0,0 +1,229 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"rpc/internal/flags"
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+)
+
+// AMT_ProvisioningCertificateHash is the class of the trusted root certificate hashes, which
+// go-wsman-messages does not have, its messages are built with wsmanMessage
+const (
+	provisioningCertificateHash    = "AMT_ProvisioningCertificateHash"
+	provisioningCertificateHashURI = amtResourceURIBase + provisioningCertificateHash
+)
+
+type ProvisioningCertificateHashPullResponse struct {
+	Body struct {
+		PullResponse struct {
+			Items []struct {
+				InstanceID  string `xml:"InstanceID"`
+				ElementName string `xml:"ElementName"`
+				Description string `xml:"Description"`
+				HashData    string `xml:"HashData"`
+				HashType    int    `xml:"HashType"`
+				IsDefault   bool   `xml:"IsDefault"`
+				IsActive    bool   `xml:"IsActive"`
+			} `xml:"Items>AMT_ProvisioningCertificateHash"`
+		} `xml:"PullResponse"`
+	} `xml:"Body"`
+}
+
+type CreateResponse struct {
+	Body struct {
+		ResourceCreated struct {
+			InstanceID string `xml:"ReferenceParameters>SelectorSet>Selector"`
+		} `xml:"ResourceCreated"`
+	} `xml:"Body"`
+}
+
+// provisioningCertificateHashInput is the body of the Create of a trusted root hash
+type provisioningCertificateHashInput struct {
+	XMLName     xml.Name `xml:"h:AMT_ProvisioningCertificateHash"`
+	H           string   `xml:"xmlns:h,attr"`
+	Description string   `xml:"h:Description"`
+	HashData    string   `xml:"h:HashData"`
+	HashType    int      `xml:"h:HashType"`
+	IsActive    bool     `xml:"h:IsActive"`
+	IsDefault   bool     `xml:"h:IsDefault"`
+}
+
+// CertHash is a trusted root certificate hash of AMT, the default hashes come with the firmware
+type CertHash struct {
+	Name       string `json:"name"`
+	InstanceID string `json:"instanceId"`
+	Algorithm  string `json:"algorithm"`
+	Hash       string `json:"hash"`
+	IsDefault  bool   `json:"isDefault"`
+	IsActive   bool   `json:"isActive"`
+}
+
+// ConfigureCertHash adds or deletes a trusted root certificate hash and writes the hashes of AMT
+func (service *ProvisioningService) ConfigureCertHash() utils.ReturnCode {
+	opts := service.flags.CertHash
+	var rc utils.ReturnCode
+	switch {
+	case opts.Add != "":
+		rc = service.AddCertHash(opts.Alias, opts.Add, opts.HashType)
+	case opts.Delete != "":
+		rc = service.DeleteCertHash(opts.Delete)
+	}
+	if rc != utils.Success {
+		return rc
+	}
+	if (opts.Add != "" || opts.Delete != "") && !service.flags.IsRemote() {
+		service.clearInfoCache()
+	}
+	hashes, rc := service.GetCertHashes()
+	if rc != utils.Success {
+		return rc
+	}
+	service.writeCertHashes(hashes)
+	return utils.Success
+}
+
+// GetCertHashes returns the trusted root certificate hashes of AMT
+func (service *ProvisioningService) GetCertHashes() ([]CertHash, utils.ReturnCode) {
+	enumerate, err := enumerateMessage(provisioningCertificateHashURI)
+	if err != nil {
+		log.Error("unable to create the Enumerate message: ", err)
+		return nil, utils.CertHashConfigurationFailed
+	}
+	var pullErr error
+	var rsp ProvisioningCertificateHashPullResponse
+	rc := service.EnumPullUnmarshal(
+		func() string { return enumerate },
+		func(context string) string {
+			var xmlMsg string
+			xmlMsg, pullErr = pullMessage(provisioningCertificateHashURI, context)
+			return xmlMsg
+		},
+		&rsp,
+	)
+	if pullErr != nil {
+		log.Error("unable to create the Pull message: ", pullErr)
+		return nil, utils.CertHashConfigurationFailed
+	}
+	if rc != utils.Success {
+		return nil, utils.CertHashConfigurationFailed
+	}
+	hashes := []CertHash{}
+	for _, item := range rsp.Body.PullResponse.Items {
+		_, algorithm := utils.InterpretHashAlgorithm(item.HashType)
+		hash := CertHash{
+			Name:       item.Description,
+			InstanceID: item.InstanceID,
+			Algorithm:  algorithm,
+			IsDefault:  item.IsDefault,
+			IsActive:   item.IsActive,
+		}
+		if hash.Name == "" {
+			hash.Name = item.ElementName
+		}
+		data, err := base64.StdEncoding.DecodeString(item.HashData)
+		if err != nil {
+			log.Errorf("unable to decode the hash of %s: %s", hash.Name, err)
+			return nil, utils.CertHashConfigurationFailed
+		}
+		hash.Hash = hex.EncodeToString(data)
+		hashes = append(hashes, hash)
+	}
+	return hashes, utils.Success
+}
+
+// AddCertHash adds an active trusted root certificate hash, AMT refuses it when the
+// firmware does not allow more hashes or already has the hash
+func (service *ProvisioningService) AddCertHash(name string, hash string, hashType int) utils.ReturnCode {
+	data, err := hex.DecodeString(hash)
+	if err != nil {
+		log.Error("invalid hash: ", err)
+		return utils.CertHashConfigurationFailed
+	}
+	xmlMsg, err := createMessage(provisioningCertificateHashURI, provisioningCertificateHashInput{
+		H:           provisioningCertificateHashURI,
+		Description: name,
+		HashData:    base64.StdEncoding.EncodeToString(data),
+		HashType:    hashType,
+		IsActive:    true,
+	})
+	if err != nil {
+		log.Error("unable to create the Create message: ", err)
+		return utils.CertHashConfigurationFailed
+	}
+	var rsp CreateResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		log.Errorf("AMT did not add the hash %s, the firmware may not allow more trusted root hashes", name)
+		return utils.CertHashConfigurationFailed
+	}
+	log.Infof("Status: trusted root hash %s added as %s", name, rsp.Body.ResourceCreated.InstanceID)
+	return utils.Success
+}
+
+// DeleteCertHash deletes the trusted root certificate hash with the name or the hash
+func (service *ProvisioningService) DeleteCertHash(nameOrHash string) utils.ReturnCode {
+	hashes, rc := service.GetCertHashes()
+	if rc != utils.Success {
+		return rc
+	}
+	wanted, _ := flags.NormalizeCertHash(nameOrHash)
+	for _, hash := range hashes {
+		if hash.Name != nameOrHash && (wanted == "" || hash.Hash != wanted) {
+			continue
+		}
+		xmlMsg, err := deleteMessage(provisioningCertificateHashURI, wsmanSelector{Name: "InstanceID", Value: hash.InstanceID})
+		if err != nil {
+			log.Error("unable to create the Delete message: ", err)
+			return utils.CertHashConfigurationFailed
+		}
+		if _, err := service.client.Post(xmlMsg); err != nil {
+			log.Errorf("AMT did not delete the hash %s: %s", hash.Name, err)
+			return utils.CertHashConfigurationFailed
+		}
+		log.Infof("Status: trusted root hash %s deleted", hash.Name)
+		return utils.Success
+	}
+	log.Errorf("AMT has no trusted root hash %s", nameOrHash)
+	return utils.CertHashConfigurationFailed
+}
+
+// clearInfoCache drops the certificate hashes amtinfo cached, they changed
+func (service *ProvisioningService) clearInfoCache() {
+	dir, err := infoCacheDir()
+	if err != nil {
+		return
+	}
+	cache := info.Cache{Dir: dir}
+	if err = cache.Clear(); err != nil {
+		log.Warn("unable to clear the amtinfo cache: ", err)
+	}
+}
+
+func (service *ProvisioningService) writeCertHashes(hashes []CertHash) {
+	w := service.newOutputWriter()
+	w.Field("certificateHashes", "", hashes)
+	if len(hashes) == 0 {
+		w.Println("No trusted root hashes")
+	}
+	for _, hash := range hashes {
+		w.Printf("%s", hash.Name)
+		if hash.IsDefault && hash.IsActive {
+			w.Printf("  (Default, Active)")
+		} else if hash.IsDefault {
+			w.Printf("  (Default)")
+		} else if hash.IsActive {
+			w.Printf("  (Active)")
+		}
+		w.Println("")
+		w.Println("   " + hash.Algorithm + ": " + hash.Hash)
+	}
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+}
@@ -0,0 +1,102 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const certHashesXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_ProvisioningCertificateHash"><a:Body><g:PullResponse><g:Items>` +
+	`<g:AMT_ProvisioningCertificateHash><g:Description>VeriSign Class 3 Primary CA-G5</g:Description><g:ElementName>Intel(r) AMT Certificate Hash</g:ElementName><g:HashData>mkHxOfw/DKz8gp/5MzaO7NxnFOJFVuvnvX+LxUX9HbA=</g:HashData><g:HashType>2</g:HashType><g:InstanceID>Intel(r) AMT Certificate Hash 0</g:InstanceID><g:IsActive>true</g:IsActive><g:IsDefault>true</g:IsDefault></g:AMT_ProvisioningCertificateHash>` +
+	`<g:AMT_ProvisioningCertificateHash><g:Description>CorpRoot</g:Description><g:ElementName>Intel(r) AMT Certificate Hash</g:ElementName><g:HashData>q6urq6urq6urq6urq6urq6urq6s=</g:HashData><g:HashType>1</g:HashType><g:InstanceID>Intel(r) AMT Certificate Hash 24</g:InstanceID><g:IsActive>true</g:IsActive><g:IsDefault>false</g:IsDefault></g:AMT_ProvisioningCertificateHash>` +
+	`</g:Items></g:PullResponse></a:Body></a:Envelope>`
+
+func TestConfigureCertHash(t *testing.T) {
+	f := &flags.Flags{}
+
+	t.Run("lists the hashes as JSON", func(t *testing.T) {
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondCheckBodyFunc(t, `<Envelope><Body><EnumerateResponse><EnumerationContext>ctx</EnumerationContext></EnumerateResponse></Body></Envelope>`,
+				`/enumeration/Enumerate</a:Action>`, `/AMT_ProvisioningCertificateHash</w:ResourceURI>`),
+			respondCheckBodyFunc(t, certHashesXMLResponse,
+				`/enumeration/Pull</a:Action>`, `/AMT_ProvisioningCertificateHash</w:ResourceURI>`, `<EnumerationContext>ctx</EnumerationContext>`),
+		})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ConfigureCertHash())
+		var out struct {
+			CertificateHashes []CertHash `json:"certificateHashes"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, []CertHash{
+			{Name: "VeriSign Class 3 Primary CA-G5", InstanceID: "Intel(r) AMT Certificate Hash 0", Algorithm: "SHA256",
+				Hash: "9a41f139fc3f0cacfc829ff933368eecdc6714e24556ebe7bd7f8bc545fd1db0", IsDefault: true, IsActive: true},
+			{Name: "CorpRoot", InstanceID: "Intel(r) AMT Certificate Hash 24", Algorithm: "SHA1",
+				Hash: "abababababababababababababababababababab", IsActive: true},
+		}, out.CertificateHashes)
+	})
+	t.Run("adds a hash and clears the amtinfo cache", func(t *testing.T) {
+		dir := t.TempDir()
+		orig := infoCacheDir
+		defer func() { infoCacheDir = orig }()
+		infoCacheDir = func() (string, error) { return dir, nil }
+		cached := filepath.Join(dir, "amtinfo.json")
+		assert.NoError(t, os.WriteFile(cached, []byte("{}"), 0600))
+
+		f.CertHash = flags.CertHashFlags{Add: "abababababababababababababababababababab", HashType: flags.CertHashTypeSHA1, Alias: "CorpRoot"}
+		defer func() { f.CertHash = flags.CertHashFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondCheckBodyFunc(t, `<Envelope><Body><ResourceCreated></ResourceCreated></Body></Envelope>`,
+				`/transfer/Create</a:Action>`,
+				`/AMT_ProvisioningCertificateHash</w:ResourceURI>`,
+				`<h:Description>CorpRoot</h:Description><h:HashData>q6urq6urq6urq6urq6urq6urq6s=</h:HashData><h:HashType>1</h:HashType><h:IsActive>true</h:IsActive>`),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, certHashesXMLResponse),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureCertHash())
+		assert.NoFileExists(t, cached)
+	})
+	t.Run("fails when AMT refuses the hash", func(t *testing.T) {
+		f.CertHash = flags.CertHashFlags{Add: "abababababababababababababababababababab", HashType: flags.CertHashTypeSHA1, Alias: "CorpRoot"}
+		defer func() { f.CertHash = flags.CertHashFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.CertHashConfigurationFailed, lps.ConfigureCertHash())
+	})
+	t.Run("deletes a hash by its hash", func(t *testing.T) {
+		f.CertHash = flags.CertHashFlags{Delete: "AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB:AB"}
+		defer func() { f.CertHash = flags.CertHashFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, certHashesXMLResponse),
+			respondCheckBodyFunc(t, "", `/transfer/Delete</a:Action>`, `/AMT_ProvisioningCertificateHash</w:ResourceURI>`,
+				`<w:Selector Name="InstanceID">Intel(r) AMT Certificate Hash 24</w:Selector>`),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, ProvisioningCertificateHashPullResponse{}),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureCertHash())
+	})
+	t.Run("fails to delete a hash AMT does not have", func(t *testing.T) {
+		f.CertHash = flags.CertHashFlags{Delete: "Unknown"}
+		defer func() { f.CertHash = flags.CertHashFlags{} }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, certHashesXMLResponse),
+		})
+		assert.Equal(t, utils.CertHashConfigurationFailed, lps.ConfigureCertHash())
+	})
+}
@@ -0,0 +1,44 @@
+package local
+
+import (
+	"errors"
+	"rpc/internal/pki"
+	"rpc/pkg/utils"
+)
+
+// CheckCert verifies the provisioning certificate chain and prints which trusted root
+// certificate hash of AMT its root matches, or why none does
+func (service *ProvisioningService) CheckCert() utils.ReturnCode {
+	report, err := service.CheckProvisioningCert()
+
+	w := service.newOutputWriter()
+	w.Field("chain", "", report.Chain)
+	w.Field("fingerprint", "", report.Fingerprint)
+	w.Field("matchedHash", "", report.MatchedHash)
+	w.Field("error", "", report.Error)
+	for i, cert := range report.Chain {
+		w.Printf("Certificate %d    : %s\n", i, cert.Subject)
+		w.Printf("  Issuer         : %s\n", cert.Issuer)
+		w.Printf("  Expires        : %s\n", cert.NotAfter.Format("2006-01-02"))
+	}
+	if report.Fingerprint != "" {
+		w.Println("Root SHA256      : " + report.Fingerprint)
+	}
+	if report.MatchedHash != nil {
+		w.Printf("Matched AMT hash : %s (%s)\n", report.MatchedHash.Name, report.MatchedHash.Algorithm)
+	}
+	if err != nil {
+		w.Println("Error            : " + err.Error())
+	}
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+
+	switch {
+	case errors.Is(err, pki.ErrInvalidChain):
+		return utils.InvalidProvisioningCert
+	case errors.Is(err, pki.ErrHashNotFound):
+		return utils.CertHashNotFound
+	}
+	return utils.Success
+}
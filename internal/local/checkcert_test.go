@@ -0,0 +1,63 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	amt2 "rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCert(t *testing.T) {
+	testCerts := getTestCerts()
+	f := &flags.Flags{}
+	f.Command = utils.CommandCheckCert
+	f.LocalConfig.ACMSettings.ProvisioningCert = testCerts.Pfxb64
+	f.LocalConfig.ACMSettings.ProvisioningCertPwd = testCerts.PfxPassword
+	origHashes := mockCertHashes
+	defer func() { mockCertHashes = origHashes }()
+
+	run := func() (utils.ReturnCode, string) {
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		return lps.CheckCert(), buf.String()
+	}
+
+	t.Run("reports the matched hash", func(t *testing.T) {
+		mockCertHashes = []amt2.CertHashEntry{{Name: "Test Root", Algorithm: "SHA256", Hash: testCerts.CaFingerprint, IsActive: true}}
+		rc, out := run()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, out, "Matched AMT hash : Test Root (SHA256)")
+		assert.Contains(t, out, "Root SHA256      : "+testCerts.CaFingerprint)
+	})
+	t.Run("reports why no hash matched", func(t *testing.T) {
+		mockCertHashes = []amt2.CertHashEntry{{Name: "Test Root", Hash: testCerts.CaFingerprint}}
+		rc, out := run()
+		assert.Equal(t, utils.CertHashNotFound, rc)
+		assert.Contains(t, out, `matches the AMT hash "Test Root", which is not active`)
+	})
+	t.Run("fails on the wrong password", func(t *testing.T) {
+		f.LocalConfig.ACMSettings.ProvisioningCertPwd = "wrong"
+		defer func() { f.LocalConfig.ACMSettings.ProvisioningCertPwd = testCerts.PfxPassword }()
+		rc, _ := run()
+		assert.Equal(t, utils.InvalidProvisioningCert, rc)
+	})
+	t.Run("writes the report as JSON", func(t *testing.T) {
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		mockCertHashes = []amt2.CertHashEntry{{Name: "Test Root", Hash: testCerts.CaFingerprint, IsActive: true}}
+		rc, out := run()
+		assert.Equal(t, utils.Success, rc)
+		var result struct {
+			Chain       []interface{}      `json:"chain"`
+			MatchedHash amt2.CertHashEntry `json:"matchedHash"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(out), &result))
+		assert.Len(t, result.Chain, 3)
+		assert.Equal(t, "Test Root", result.MatchedHash.Name)
+	})
+}
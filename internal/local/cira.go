@@ -0,0 +1,596 @@
+package local
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"regexp"
+	"rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/environmentdetection"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/remoteaccess"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/userinitiatedconnection"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
+)
+
+const (
+	environmentDetectionInstanceID = "Intel(r) AMT Environment Detection Settings"
+	// defaultMPSPort is probed when the port of the MPS is unknown, the MEI only reports its hostname
+	defaultMPSPort  = 4433
+	mpsProbeTimeout = 5 * time.Second
+)
+
+type AddMpServerResponse struct {
+	Body struct {
+		Output struct {
+			MpServer struct {
+				ReferenceParameters models.ReferenceParameters_OUTPUT `xml:"ReferenceParameters"`
+			} `xml:"MpServer"`
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"AddMpServer_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type AddRemoteAccessPolicyRuleResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"AddRemoteAccessPolicyRule_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type RemoteAccessPolicyRuleDetailsPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				PolicyRuleName string
+				Trigger        int
+				TunnelLifeTime int
+				ExtendedData   string
+			} `xml:"Items>AMT_RemoteAccessPolicyRule"`
+		}
+	}
+}
+
+type ManagementPresenceRemoteSAPDetailsPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				Name       string
+				AccessInfo string
+				InfoFormat int
+				Port       int
+				CN         string
+			} `xml:"Items>AMT_ManagementPresenceRemoteSAP"`
+		}
+	}
+}
+
+type EnvironmentDetectionSettingDataPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				DetectionStrings []string
+			} `xml:"Items>AMT_EnvironmentDetectionSettingData"`
+		}
+	}
+}
+
+// RemoteAccessInfo adds the CIRA configuration read over wsman to the
+// connection status reported by the MEI
+type RemoteAccessInfo struct {
+	amt.RemoteAccessStatus
+	MPSServers           []MPSServer           `json:"mpsServers,omitempty"`
+	EnvironmentDetection []string              `json:"environmentDetection,omitempty"`
+	Triggers             []RemoteAccessTrigger `json:"triggers,omitempty"`
+	Probes               []MPSProbe            `json:"probes,omitempty"`
+}
+
+type MPSServer struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	CN       string `json:"cn,omitempty"`
+}
+
+type RemoteAccessTrigger struct {
+	Name           string `json:"name"`
+	Trigger        string `json:"trigger"`
+	TunnelLifeTime int    `json:"tunnelLifeTime"`
+	// PeriodicInterval is the seconds between connections of a periodic trigger
+	PeriodicInterval int `json:"periodicInterval,omitempty"`
+}
+
+var remoteAccessTriggers = map[int]string{
+	0: "User Initiated",
+	1: "Alert",
+	2: "Periodic",
+	3: "Home Provisioning",
+}
+
+// GetCIRAConfiguration reads the MPS servers, environment detection domains and
+// remote access policies configured in AMT
+func (service *ProvisioningService) GetCIRAConfiguration(info *RemoteAccessInfo) utils.ReturnCode {
+	var mpsServers ManagementPresenceRemoteSAPDetailsPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.ManagementPresenceRemoteSAP.Enumerate,
+		service.amtMessages.ManagementPresenceRemoteSAP.Pull,
+		&mpsServers,
+	)
+	if rc != utils.Success {
+		return rc
+	}
+	for _, mps := range mpsServers.Body.PullResponse.Items {
+		info.MPSServers = append(info.MPSServers, MPSServer{
+			Name:     mps.Name,
+			Hostname: mps.AccessInfo,
+			Port:     mps.Port,
+			CN:       mps.CN,
+		})
+	}
+
+	var envDetection EnvironmentDetectionSettingDataPullResponse
+	rc = service.EnumPullUnmarshal(
+		service.amtMessages.EnvironmentDetectionSettingData.Enumerate,
+		service.amtMessages.EnvironmentDetectionSettingData.Pull,
+		&envDetection,
+	)
+	if rc != utils.Success {
+		return rc
+	}
+	for _, item := range envDetection.Body.PullResponse.Items {
+		info.EnvironmentDetection = append(info.EnvironmentDetection, item.DetectionStrings...)
+	}
+
+	var policyRules RemoteAccessPolicyRuleDetailsPullResponse
+	rc = service.EnumPullUnmarshal(
+		service.amtMessages.RemoteAccessPolicyRule.Enumerate,
+		service.amtMessages.RemoteAccessPolicyRule.Pull,
+		&policyRules,
+	)
+	if rc != utils.Success {
+		return rc
+	}
+	for _, rule := range policyRules.Body.PullResponse.Items {
+		trigger := RemoteAccessTrigger{
+			Name:           rule.PolicyRuleName,
+			Trigger:        remoteAccessTriggers[rule.Trigger],
+			TunnelLifeTime: rule.TunnelLifeTime,
+		}
+		if trigger.Trigger == "" {
+			trigger.Trigger = fmt.Sprintf("Unknown(%d)", rule.Trigger)
+		}
+		if rule.Trigger == 2 {
+			trigger.PeriodicInterval = periodicInterval(rule.ExtendedData)
+		}
+		info.Triggers = append(info.Triggers, trigger)
+	}
+	return utils.Success
+}
+
+// periodicInterval decodes the extended data of a periodic policy rule. It holds the
+// periodic type followed by the interval in seconds, both in network byte order.
+func periodicInterval(extendedData string) int {
+	data, err := base64.StdEncoding.DecodeString(extendedData)
+	if err != nil || len(data) < 8 || binary.BigEndian.Uint32(data[0:4]) != 0 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(data[4:8]))
+}
+
+// ConfigureCIRA replaces the CIRA configuration in AMT with the MPS servers given on the
+// command line and enables the connection to them. AMT connects to the primary MPS and
+// falls back to the secondary when the primary can not be reached. A configuration that
+// already matches is kept, so applying it again does not drop the CIRA connection. A
+// failed step rolls back the certificate, servers and policies added before it, which
+// leaves CIRA not configured: the MPS password of the replaced configuration can not be
+// read from AMT to restore it. That rollback returns CIRAConfigurationRemoved instead of
+// PartialConfigRolledBack when a configuration was replaced, since AMT is not as before.
+func (service *ProvisioningService) ConfigureCIRA() utils.ReturnCode {
+	cira := service.flags.CIRASettings
+	// the root certificate stays in AMT when CIRA is removed, reuse it when configuring again
+	service.handlesWithCerts = make(map[string]string)
+	var publicCerts []publickey.PublicKeyCertificate
+	if rc := service.GetPublicKeyCerts(&publicCerts); rc != utils.Success {
+		return utils.CIRAConfigurationFailed
+	}
+	rootTrusted := false
+	for _, cert := range publicCerts {
+		if cert.TrustedRootCertficate {
+			service.handlesWithCerts[cert.InstanceID] = cert.X509Certificate
+			rootTrusted = rootTrusted || cert.X509Certificate == cira.MPSRootCert
+		}
+	}
+	if rootTrusted && !cira.Force {
+		configured, rc := service.ciraConfigured()
+		if rc != utils.Success {
+			return utils.CIRAConfigurationFailed
+		}
+		if configured {
+			log.Info("CIRA is already configured with these MPS servers, use -force to replace the configuration")
+			return utils.Success
+		}
+	}
+	replaced, rc := service.removeCIRAConfiguration()
+	if rc != utils.Success {
+		return rc
+	}
+	tx := service.beginConfig("CIRA")
+	rollback := func(rc utils.ReturnCode) utils.ReturnCode {
+		rc = tx.rollback(rc)
+		if rc == utils.PartialConfigRolledBack && replaced {
+			log.Warn("the replaced CIRA configuration was removed and is not restored, configure CIRA again with its MPS password")
+			return utils.CIRAConfigurationRemoved
+		}
+		return rc
+	}
+	if _, rc := tx.addCertificate("MPS root certificate", cira.MPSRootCert, service.AddTrustedRootCert, service.amtMessages.PublicKeyCertificate.Delete); rc != utils.Success {
+		return rollback(rc)
+	}
+	var mpsNames []string
+	for _, mps := range desiredMPSServers(cira) {
+		name, rc := service.AddMPS(mps)
+		if rc != utils.Success {
+			return rollback(rc)
+		}
+		log.Infof("added management presence server: %s", name)
+		tx.appliedPost("management presence server "+name, service.amtMessages.ManagementPresenceRemoteSAP.Delete(name))
+		mpsNames = append(mpsNames, name)
+	}
+
+	rules := []remoteaccess.RemoteAccessPolicyRule{
+		{Trigger: remoteaccess.UserInitiated},
+		{Trigger: remoteaccess.Alert},
+	}
+	if cira.PeriodicInterval > 0 {
+		rules = append(rules, remoteaccess.RemoteAccessPolicyRule{
+			Trigger:      remoteaccess.Periodic,
+			ExtendedData: periodicExtendedData(cira.PeriodicInterval),
+		})
+	}
+	for _, rule := range rules {
+		if rc := service.AddRemoteAccessPolicyRule(rule, mpsNames...); rc != utils.Success {
+			return rollback(rc)
+		}
+		// AMT names the rules after their trigger
+		name := remoteAccessTriggers[int(rule.Trigger)]
+		tx.appliedPost(name+" policy rule", service.amtMessages.RemoteAccessPolicyRule.Delete(name))
+	}
+	// the domains are not rolled back, they have no effect without a MPS
+	if rc := service.SetEnvironmentDetection(cira.EnvironmentDetection); rc != utils.Success {
+		return rollback(rc)
+	}
+	xmlMsg := service.amtMessages.UserInitiatedConnectionService.RequestStateChange(userinitiatedconnection.BIOSandOSInterfacesEnabled)
+	if _, err := service.client.Post(xmlMsg); err != nil {
+		log.Error("unable to enable user initiated connections: ", err)
+		return rollback(utils.CIRAConfigurationFailed)
+	}
+	log.Infof("CIRA configured, AMT connects to %s:%d when outside of the intranet", cira.MPSAddress, cira.MPSPort)
+	if cira.SecondaryAddress != "" {
+		log.Infof("AMT falls back to %s:%d when %s can not be reached", cira.SecondaryAddress, cira.SecondaryPort, cira.MPSAddress)
+	}
+	return utils.Success
+}
+
+// desiredMPSServers returns the MPS servers of the flags in the order AMT tries them
+func desiredMPSServers(cira flags.CIRASettingsFlags) []MPSServer {
+	servers := []MPSServer{{Hostname: cira.MPSAddress, Port: cira.MPSPort, CN: cira.MPSCommonName}}
+	if cira.SecondaryAddress != "" {
+		servers = append(servers, MPSServer{Hostname: cira.SecondaryAddress, Port: cira.SecondaryPort, CN: cira.SecondaryCommonName})
+	}
+	return servers
+}
+
+// ciraConfigured tells whether the CIRA configuration in AMT matches the flags. The MPS
+// servers must match in the order AMT tries them, the policy rules and environment
+// detection domains in any order. The MPS password can not be read from AMT and is not
+// compared.
+func (service *ProvisioningService) ciraConfigured() (bool, utils.ReturnCode) {
+	cira := service.flags.CIRASettings
+	var info RemoteAccessInfo
+	if rc := service.GetCIRAConfiguration(&info); rc != utils.Success {
+		return false, rc
+	}
+	desired := desiredMPSServers(cira)
+	if len(info.MPSServers) != len(desired) {
+		return false, utils.Success
+	}
+	var triggers []string
+	for _, trigger := range info.Triggers {
+		triggers = append(triggers, triggerSummary(trigger.Trigger, trigger.PeriodicInterval))
+	}
+	desiredTriggers := []string{triggerSummary("User Initiated", 0), triggerSummary("Alert", 0)}
+	if cira.PeriodicInterval > 0 {
+		desiredTriggers = append(desiredTriggers, triggerSummary("Periodic", cira.PeriodicInterval))
+	}
+	if joinSorted(triggers, "") != joinSorted(desiredTriggers, "") {
+		return false, utils.Success
+	}
+	if len(cira.EnvironmentDetection) == 0 {
+		// the random domain set without domains matches when it is the only one
+		if len(info.EnvironmentDetection) != 1 || !randomDetectionDomain.MatchString(info.EnvironmentDetection[0]) {
+			return false, utils.Success
+		}
+	} else if joinSorted(lowerAll(info.EnvironmentDetection), "") != joinSorted(lowerAll(cira.EnvironmentDetection), "") {
+		return false, utils.Success
+	}
+
+	current := info.MPSServers
+	if len(current) > 1 {
+		order, rc := service.mpsAccessOrder()
+		if rc != utils.Success {
+			return false, rc
+		}
+		sort.SliceStable(current, func(i, j int) bool { return order[current[i].Name] < order[current[j].Name] })
+	}
+	for i, mps := range current {
+		if !strings.EqualFold(mps.Hostname, desired[i].Hostname) || mps.Port != desired[i].Port || mps.CN != desired[i].CN {
+			return false, utils.Success
+		}
+	}
+	return true, utils.Success
+}
+
+// randomDetectionDomain matches the domain SetEnvironmentDetection sets without domains
+var randomDetectionDomain = regexp.MustCompile(`^[0-9a-f]{16}\.com$`)
+
+func triggerSummary(trigger string, periodicInterval int) string {
+	if periodicInterval > 0 {
+		return fmt.Sprintf("%s every %ds", trigger, periodicInterval)
+	}
+	return trigger
+}
+
+func lowerAll(values []string) []string {
+	lower := make([]string, len(values))
+	for i, value := range values {
+		lower[i] = strings.ToLower(value)
+	}
+	return lower
+}
+
+type RemoteAccessPolicyAppliesToMPSPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				ManagedElement struct {
+					ReferenceParameters models.ReferenceParameters_OUTPUT `xml:"ReferenceParameters"`
+				} `xml:"ManagedElement"`
+				OrderOfAccess int
+			} `xml:"Items>AMT_RemoteAccessPolicyAppliesToMPS"`
+		}
+	}
+}
+
+// mpsAccessOrder returns the position of each MPS server in the order AMT tries them, by
+// the name of the server. A server referenced by several policy rules gets its first position.
+func (service *ProvisioningService) mpsAccessOrder() (map[string]int, utils.ReturnCode) {
+	var appliesTo RemoteAccessPolicyAppliesToMPSPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.RemoteAccessPolicyAppliesToMPS.Enumerate,
+		service.amtMessages.RemoteAccessPolicyAppliesToMPS.Pull,
+		&appliesTo,
+	)
+	if rc != utils.Success {
+		return nil, rc
+	}
+	order := make(map[string]int)
+	for _, item := range appliesTo.Body.PullResponse.Items {
+		for _, selector := range item.ManagedElement.ReferenceParameters.SelectorSet.Selector {
+			if position, ok := order[selector.Value]; selector.Name == "Name" && (!ok || item.OrderOfAccess < position) {
+				order[selector.Value] = item.OrderOfAccess
+			}
+		}
+	}
+	return order, utils.Success
+}
+
+// AddMPS adds the MPS server with username and password authentication and returns
+// the name AMT gave it
+func (service *ProvisioningService) AddMPS(mps MPSServer) (string, utils.ReturnCode) {
+	cira := service.flags.CIRASettings
+	infoFormat := remoteaccess.FQDN
+	if ip := net.ParseIP(mps.Hostname); ip != nil {
+		infoFormat = remoteaccess.IPv6Address
+		if ip.To4() != nil {
+			infoFormat = remoteaccess.IPv4Address
+		}
+	}
+	xmlMsg := service.amtMessages.RemoteAccessService.AddMPS(remoteaccess.MPServer{
+		AccessInfo: mps.Hostname,
+		InfoFormat: infoFormat,
+		Port:       mps.Port,
+		AuthMethod: remoteaccess.UsernamePasswordAuthentication,
+		Username:   cira.MPSUser,
+		Password:   cira.MPSPassword,
+		CommonName: mps.CN,
+	})
+	var rsp AddMpServerResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return "", utils.CIRAConfigurationFailed
+	}
+	if rc := checkReturnValue(utils.ReturnCode(rsp.Body.Output.ReturnValue), "management presence server"); rc != utils.Success {
+		return "", rc
+	}
+	for _, selector := range rsp.Body.Output.MpServer.ReferenceParameters.SelectorSet.Selector {
+		if selector.Name == "Name" {
+			return selector.Value, utils.Success
+		}
+	}
+	log.Error("AMT did not return the name of the management presence server")
+	return "", utils.CIRAConfigurationFailed
+}
+
+// AddRemoteAccessPolicyRule adds a rule that connects AMT to the named MPS servers, in
+// the order they are given
+func (service *ProvisioningService) AddRemoteAccessPolicyRule(rule remoteaccess.RemoteAccessPolicyRule, mpsNames ...string) utils.ReturnCode {
+	// the selector type lives in an internal package of the wsman library,
+	// a struct with the same fields is assignable to it
+	selector := struct {
+		XMLName xml.Name `xml:"w:Selector,omitempty"`
+		Name    string   `xml:"Name,attr"`
+		Value   string   `xml:",chardata"`
+	}{Name: "Name", Value: mpsNames[0]}
+	xmlMsg := service.amtMessages.RemoteAccessService.AddRemoteAccessPolicyRule(rule, selector)
+	xmlMsg = addMpServerReferences(xmlMsg, mpsNames[1:])
+	var rsp AddRemoteAccessPolicyRuleResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return utils.CIRAConfigurationFailed
+	}
+	trigger := remoteAccessTriggers[int(rule.Trigger)]
+	if rc := checkReturnValue(utils.ReturnCode(rsp.Body.Output.ReturnValue), trigger+" policy rule"); rc != utils.Success {
+		return rc
+	}
+	log.Infof("added remote access policy rule: %s", trigger)
+	return utils.Success
+}
+
+// addMpServerReferences repeats the MpServer reference of a policy rule message for each
+// of the names, the wsman library only builds the message for one server
+func addMpServerReferences(xmlMsg string, mpsNames []string) string {
+	const nameSelector, endSelector, endReference = `<Selector Name="Name">`, `</Selector>`, `</h:MpServer>`
+	start := strings.Index(xmlMsg, "<h:MpServer>")
+	end := strings.Index(xmlMsg, endReference)
+	if start < 0 || end < 0 || len(mpsNames) == 0 {
+		return xmlMsg
+	}
+	end += len(endReference)
+	reference := xmlMsg[start:end]
+	valueStart := strings.Index(reference, nameSelector)
+	if valueStart < 0 {
+		return xmlMsg
+	}
+	valueStart += len(nameSelector)
+	valueEnd := valueStart + strings.Index(reference[valueStart:], endSelector)
+	var references strings.Builder
+	for _, name := range mpsNames {
+		references.WriteString(reference[:valueStart] + name + reference[valueEnd:])
+	}
+	return xmlMsg[:end] + references.String() + xmlMsg[end:]
+}
+
+// SetEnvironmentDetection sets the intranet domains where AMT does not connect to the MPS.
+// Without domains AMT would never consider itself outside the intranet, so a random
+// domain is used that no network matches.
+func (service *ProvisioningService) SetEnvironmentDetection(domains []string) utils.ReturnCode {
+	if len(domains) == 0 {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			log.Error("unable to generate environment detection domain: ", err)
+			return utils.CIRAConfigurationFailed
+		}
+		domains = []string{hex.EncodeToString(random) + ".com"}
+	}
+	settings := environmentdetection.EnvironmentDetectionSettingData{
+		DetectionAlgorithm: environmentdetection.LocalDomains,
+		DetectionStrings:   domains,
+	}
+	settings.ElementName = environmentDetectionInstanceID
+	settings.InstanceID = environmentDetectionInstanceID
+	xmlMsg := service.amtMessages.EnvironmentDetectionSettingData.Put(settings)
+	if _, err := service.client.Post(xmlMsg); err != nil {
+		log.Error("unable to set environment detection: ", err)
+		return utils.CIRAConfigurationFailed
+	}
+	return utils.Success
+}
+
+// periodicExtendedData encodes the interval of a periodic policy rule in the format
+// read by periodicInterval
+func periodicExtendedData(seconds int) string {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data[4:8], uint32(seconds))
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// MPSProbe is the result of connecting to a MPS server from the host OS
+type MPSProbe struct {
+	Server    string `json:"server"`
+	Reachable bool   `json:"reachable"`
+	// LatencyMs is the time the TCP connection took to establish
+	LatencyMs    int64  `json:"latencyMs"`
+	TLSHandshake bool   `json:"tlsHandshake"`
+	TLSVersion   string `json:"tlsVersion,omitempty"`
+	// CertificateCN is the common name of the server certificate, AMT expects the CN configured for the MPS
+	CertificateCN string `json:"certificateCN,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ProbeMPSServers connects to the configured MPS servers, or to the MPS hostname
+// reported by the MEI when the configuration was not read, and reports whether
+// the host OS reaches them. AMT shares the network of the host, so a server that
+// the host can not reach explains a CIRA connection that does not come up.
+func ProbeMPSServers(ras RemoteAccessInfo) []MPSProbe {
+	var servers []string
+	for _, mps := range ras.MPSServers {
+		servers = append(servers, net.JoinHostPort(mps.Hostname, strconv.Itoa(mps.Port)))
+	}
+	if len(servers) == 0 && ras.MPSHostname != "" {
+		servers = append(servers, net.JoinHostPort(ras.MPSHostname, strconv.Itoa(defaultMPSPort)))
+	}
+	probes := make([]MPSProbe, len(servers))
+	tasks := make([]func(), len(servers))
+	for i := range servers {
+		i := i
+		tasks[i] = func() { probes[i] = probeMPS(servers[i], mpsProbeTimeout) }
+	}
+	info.RunConcurrently(maxHostWorkers, tasks)
+	return probes
+}
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+func probeMPS(server string, timeout time.Duration) MPSProbe {
+	probe := MPSProbe{Server: server}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", server, timeout)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	defer conn.Close()
+	probe.Reachable = true
+	probe.LatencyMs = time.Since(start).Milliseconds()
+
+	// AMT validates the certificate against the MPS root certificate it was given,
+	// the host OS may not trust that root so only the handshake itself is checked
+	host, _, _ := net.SplitHostPort(server)
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tlsConn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		probe.Error = "TLS handshake failed: " + err.Error()
+		return probe
+	}
+	state := tlsConn.ConnectionState()
+	probe.TLSHandshake = true
+	probe.TLSVersion = tlsVersions[state.Version]
+	if len(state.PeerCertificates) > 0 {
+		probe.CertificateCN = state.PeerCertificates[0].Subject.CommonName
+	}
+	return probe
+}
@@ -0,0 +1,449 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"rpc/internal/flags"
+	"rpc/internal/output"
+	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/remoteaccess"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func ciraConfigurationResponses(t *testing.T) ResponseFuncArray {
+	var mpsRsp ManagementPresenceRemoteSAPDetailsPullResponse
+	mpsRsp.Body.PullResponse.Items = append(mpsRsp.Body.PullResponse.Items, struct {
+		Name       string
+		AccessInfo string
+		InfoFormat int
+		Port       int
+		CN         string
+	}{Name: "Intel(r) AMT:Management Presence Server 0", AccessInfo: "mps.example.com", InfoFormat: 201, Port: 4433, CN: "mps.example.com"})
+	var envRsp EnvironmentDetectionSettingDataPullResponse
+	envRsp.Body.PullResponse.Items = append(envRsp.Body.PullResponse.Items, struct {
+		DetectionStrings []string
+	}{DetectionStrings: []string{"corp.example.com", "lab.example.com"}})
+	var ruleRsp RemoteAccessPolicyRuleDetailsPullResponse
+	ruleRsp.Body.PullResponse.Items = append(ruleRsp.Body.PullResponse.Items, struct {
+		PolicyRuleName string
+		Trigger        int
+		TunnelLifeTime int
+		ExtendedData   string
+	}{PolicyRuleName: "User Initiated", Trigger: 0, TunnelLifeTime: 0}, struct {
+		PolicyRuleName string
+		Trigger        int
+		TunnelLifeTime int
+		ExtendedData   string
+	}{PolicyRuleName: "Periodic", Trigger: 2, TunnelLifeTime: 0, ExtendedData: "AAAAAAAAABk="})
+	return ResponseFuncArray{
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, mpsRsp),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, envRsp),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, ruleRsp),
+	}
+}
+
+func TestGetCIRAConfiguration(t *testing.T) {
+	f := &flags.Flags{}
+
+	t.Run("returns the configuration", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ciraConfigurationResponses(t))
+		info := RemoteAccessInfo{}
+		rc := lps.GetCIRAConfiguration(&info)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, []MPSServer{{Name: "Intel(r) AMT:Management Presence Server 0", Hostname: "mps.example.com", Port: 4433, CN: "mps.example.com"}}, info.MPSServers)
+		assert.Equal(t, []string{"corp.example.com", "lab.example.com"}, info.EnvironmentDetection)
+		assert.Equal(t, []RemoteAccessTrigger{
+			{Name: "User Initiated", Trigger: "User Initiated"},
+			{Name: "Periodic", Trigger: "Periodic", PeriodicInterval: 25},
+		}, info.Triggers)
+	})
+	t.Run("fails on server error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		rc := lps.GetCIRAConfiguration(&RemoteAccessInfo{})
+		assert.Equal(t, utils.WSMANMessageError, rc)
+	})
+}
+
+func TestDisplayAMTInfoRASDetails(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.Ras = true
+	f.AmtInfo.RasDetails = true
+	f.Password = "P@ssw0rd"
+	f.JsonOutput = true
+	lps := setupWsmanResponses(t, f, ciraConfigurationResponses(t))
+	var buf bytes.Buffer
+	lps.out = &buf
+	rc := lps.DisplayAMTInfo()
+	assert.Equal(t, utils.Success, rc)
+	var got struct {
+		RAS map[string]any `json:"ras"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Contains(t, got.RAS, "networkStatus")
+	assert.Contains(t, got.RAS, "mpsServers")
+	assert.Equal(t, []any{"corp.example.com", "lab.example.com"}, got.RAS["environmentDetection"])
+}
+
+func TestProbeMPSServers(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+	host, port, _ := net.SplitHostPort(address)
+	portNumber, _ := strconv.Atoi(port)
+
+	t.Run("reports the TLS handshake of a configured server", func(t *testing.T) {
+		probes := ProbeMPSServers(RemoteAccessInfo{MPSServers: []MPSServer{{Hostname: host, Port: portNumber}}})
+		assert.Len(t, probes, 1)
+		assert.Equal(t, address, probes[0].Server)
+		assert.True(t, probes[0].Reachable)
+		assert.True(t, probes[0].TLSHandshake)
+		assert.NotEmpty(t, probes[0].TLSVersion)
+		assert.Empty(t, probes[0].Error)
+	})
+	t.Run("reports an unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		closed := listener.Addr().String()
+		listener.Close()
+		probe := probeMPS(closed, time.Second)
+		assert.False(t, probe.Reachable)
+		assert.NotEmpty(t, probe.Error)
+	})
+	t.Run("probes the MEI hostname on the default port", func(t *testing.T) {
+		ras := RemoteAccessInfo{}
+		ras.MPSHostname = "127.0.0.1"
+		probes := ProbeMPSServers(ras)
+		assert.Len(t, probes, 1)
+		assert.Equal(t, "127.0.0.1:4433", probes[0].Server)
+	})
+	t.Run("probes nothing without a MPS", func(t *testing.T) {
+		assert.Empty(t, ProbeMPSServers(RemoteAccessInfo{}))
+	})
+}
+
+func TestWriteMPSProbes(t *testing.T) {
+	var buf bytes.Buffer
+	w := output.NewWriterTo(output.Text, &buf)
+	writeMPSProbes(w, []MPSProbe{
+		{Server: "mps.example.com:4433", Reachable: true, LatencyMs: 12, TLSHandshake: true, TLSVersion: "TLS 1.2", CertificateCN: "mps.example.com"},
+		{Server: "10.0.0.1:4433", Error: "i/o timeout"},
+	})
+	assert.NoError(t, w.Flush())
+	assert.Contains(t, buf.String(), "mps.example.com:4433 reachable in 12ms, TLS 1.2 handshake ok, certificate CN mps.example.com")
+	assert.Contains(t, buf.String(), "10.0.0.1:4433 unreachable: i/o timeout")
+}
+
+func TestPeriodicInterval(t *testing.T) {
+	assert.Equal(t, 25, periodicInterval("AAAAAAAAABk="))
+	assert.Equal(t, 0, periodicInterval("not base64"))
+	assert.Equal(t, 0, periodicInterval("AAAA"))
+	assert.Equal(t, "AAAAAAAAABk=", periodicExtendedData(25))
+}
+
+const addMpServerXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope"><a:Body><g:AddMpServer_OUTPUT><g:MpServer><b:Address>default</b:Address><b:ReferenceParameters><c:ResourceURI>http://intel.com/wbem/wscim/1/amt-schema/1/AMT_ManagementPresenceRemoteSAP</c:ResourceURI><c:SelectorSet><c:Selector Name="CreationClassName">AMT_ManagementPresenceRemoteSAP</c:Selector><c:Selector Name="Name">Intel(r) AMT:Management Presence Server 0</c:Selector></c:SelectorSet></b:ReferenceParameters></g:MpServer><g:ReturnValue>0</g:ReturnValue></g:AddMpServer_OUTPUT></a:Body></a:Envelope>`
+
+const addPolicyRuleXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope"><a:Body><g:AddRemoteAccessPolicyRule_OUTPUT><g:ReturnValue>0</g:ReturnValue></g:AddRemoteAccessPolicyRule_OUTPUT></a:Body></a:Envelope>`
+
+func TestConfigureCIRA(t *testing.T) {
+	f := &flags.Flags{}
+	f.CIRASettings = flags.CIRASettingsFlags{
+		MPSAddress:       "mps.example.com",
+		MPSPort:          4433,
+		MPSUser:          "admin",
+		MPSPassword:      "MPSPassw0rd!",
+		MPSRootCert:      "AABBCCDD",
+		MPSCommonName:    "mps.example.com",
+		PeriodicInterval: 60,
+	}
+	noConfiguration := ResponseFuncArray{
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, ManagementPresenceRemoteSAPDetailsPullResponse{}),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, EnvironmentDetectionSettingDataPullResponse{}),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, RemoteAccessPolicyRuleDetailsPullResponse{}),
+	}
+	removeResponses := ResponseFuncArray{
+		respondStringFunc(t, "state changed"),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, RemoteAccessPolicyRulePullResponse{}),
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondMsgFunc(t, ManagementPresenceRemoteSAPPullResponse{}),
+	}
+	existingRoot := publickey.PullResponseEnvelope{}
+	existingRoot.Body.PullResponse.Items = []publickey.PublicKeyCertificate{
+		{InstanceID: "Intel(r) AMT Certificate: Handle: 1", X509Certificate: "AABBCCDD", TrustedRootCertficate: true},
+	}
+
+	t.Run("reuses the root certificate and adds the MPS and policies", func(t *testing.T) {
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, existingRoot)}
+		rfa = append(rfa, noConfiguration...)
+		rfa = append(rfa, removeResponses...)
+		rfa = append(rfa,
+			respondStringFunc(t, addMpServerXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, "environment detection set"),
+			respondStringFunc(t, "state changed"),
+		)
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureCIRA())
+	})
+	t.Run("adds the secondary MPS after the primary to every policy rule", func(t *testing.T) {
+		secondary := &flags.Flags{}
+		secondary.CIRASettings = f.CIRASettings
+		secondary.CIRASettings.SecondaryAddress = "mps2.example.com"
+		secondary.CIRASettings.SecondaryPort = 4433
+		secondary.CIRASettings.SecondaryCommonName = "mps2.example.com"
+		secondServer := strings.Replace(addMpServerXMLResponse, "Presence Server 0", "Presence Server 1", 1)
+		var rules []string
+		recordRule := func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			rules = append(rules, string(body))
+			respondStringFunc(t, addPolicyRuleXMLResponse)(w, r)
+		}
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, publickey.PullResponseEnvelope{})}
+		rfa = append(rfa, removeResponses...)
+		rfa = append(rfa,
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, addMpServerXMLResponse),
+			respondStringFunc(t, secondServer),
+			recordRule,
+			recordRule,
+			recordRule,
+			respondStringFunc(t, "environment detection set"),
+			respondStringFunc(t, "state changed"),
+		)
+		lps := setupWsmanResponses(t, secondary, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureCIRA())
+		assert.Len(t, rules, 3)
+		for _, rule := range rules {
+			primary := strings.Index(rule, ">Intel(r) AMT:Management Presence Server 0<")
+			fallback := strings.Index(rule, ">Intel(r) AMT:Management Presence Server 1<")
+			assert.True(t, primary > 0 && fallback > primary, "the primary MPS is referenced before the secondary")
+		}
+	})
+	t.Run("keeps a configuration that already matches", func(t *testing.T) {
+		matching := &flags.Flags{}
+		matching.CIRASettings = f.CIRASettings
+		matching.CIRASettings.PeriodicInterval = 25
+		matching.CIRASettings.EnvironmentDetection = []string{"LAB.example.com", "corp.example.com"}
+		var ruleRsp RemoteAccessPolicyRuleDetailsPullResponse
+		for _, rule := range []struct {
+			trigger      int
+			extendedData string
+		}{{0, ""}, {1, ""}, {2, "AAAAAAAAABk="}} {
+			ruleRsp.Body.PullResponse.Items = append(ruleRsp.Body.PullResponse.Items, struct {
+				PolicyRuleName string
+				Trigger        int
+				TunnelLifeTime int
+				ExtendedData   string
+			}{PolicyRuleName: remoteAccessTriggers[rule.trigger], Trigger: rule.trigger, ExtendedData: rule.extendedData})
+		}
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, existingRoot)}
+		rfa = append(rfa, ciraConfigurationResponses(t)[:5]...)
+		rfa = append(rfa, respondMsgFunc(t, ruleRsp))
+		lps := setupWsmanResponses(t, matching, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureCIRA())
+	})
+	t.Run("replaces the configuration with -force without reading it", func(t *testing.T) {
+		forced := &flags.Flags{}
+		forced.CIRASettings = f.CIRASettings
+		forced.CIRASettings.Force = true
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, existingRoot)}
+		rfa = append(rfa, removeResponses...)
+		rfa = append(rfa,
+			respondStringFunc(t, addMpServerXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, "environment detection set"),
+			respondStringFunc(t, "state changed"),
+		)
+		lps := setupWsmanResponses(t, forced, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureCIRA())
+	})
+	t.Run("returns CIRAConfigurationFailed when removing the old configuration fails", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, publickey.PullResponseEnvelope{}),
+			respondServerErrFunc(),
+		})
+		assert.Equal(t, utils.CIRAConfigurationFailed, lps.ConfigureCIRA())
+	})
+	t.Run("returns the AMT status when a policy rule is rejected", func(t *testing.T) {
+		rejected := strings.Replace(addPolicyRuleXMLResponse, `<g:ReturnValue>0</g:ReturnValue>`, `<g:ReturnValue>1</g:ReturnValue>`, 1)
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, existingRoot)}
+		rfa = append(rfa, noConfiguration...)
+		rfa = append(rfa, removeResponses...)
+		rfa = append(rfa,
+			respondStringFunc(t, addMpServerXMLResponse),
+			respondStringFunc(t, rejected),
+		)
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.AmtPtStatusCodeBase+1, lps.ConfigureCIRA())
+	})
+	t.Run("rolls back the policies and MPS when enabling the connection fails", func(t *testing.T) {
+		rfa := ResponseFuncArray{respondMsgFunc(t, common.EnumerationResponse{}), respondMsgFunc(t, publickey.PullResponseEnvelope{})}
+		rfa = append(rfa, removeResponses...)
+		rfa = append(rfa,
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, addMpServerXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, addPolicyRuleXMLResponse),
+			respondStringFunc(t, "environment detection set"),
+			respondServerErrFunc(),
+			respondCheckBodyFunc(t, "deleted", "AMT_RemoteAccessPolicyRule", ">Periodic<"),
+			respondCheckBodyFunc(t, "deleted", "AMT_RemoteAccessPolicyRule", ">Alert<"),
+			respondCheckBodyFunc(t, "deleted", "AMT_RemoteAccessPolicyRule", ">User Initiated<"),
+			respondCheckBodyFunc(t, "deleted", "AMT_ManagementPresenceRemoteSAP", ">Intel(r) AMT:Management Presence Server 0<"),
+			respondCheckBodyFunc(t, "deleted", "AMT_PublicKeyCertificate", ">Intel(r) AMT Certificate: Handle: 2<"),
+		)
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.PartialConfigRolledBack, lps.ConfigureCIRA())
+	})
+	t.Run("returns CIRAConfigurationRemoved when the rollback leaves the replaced configuration removed", func(t *testing.T) {
+		var oldServers ManagementPresenceRemoteSAPPullResponse
+		oldServers.Body.PullResponse.Items = append(oldServers.Body.PullResponse.Items, struct {
+			Name       string
+			AccessInfo string
+		}{Name: "Intel(r) AMT:Management Presence Server 0", AccessInfo: "old-mps.example.com"})
+		forced := &flags.Flags{}
+		forced.CIRASettings = f.CIRASettings
+		forced.CIRASettings.Force = true
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, publickey.PullResponseEnvelope{}),
+			respondStringFunc(t, "state changed"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, RemoteAccessPolicyRulePullResponse{}),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, oldServers),
+			respondCheckBodyFunc(t, "deleted", "AMT_ManagementPresenceRemoteSAP", ">Intel(r) AMT:Management Presence Server 0<"),
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondServerErrFunc(),
+			respondCheckBodyFunc(t, "deleted", "AMT_PublicKeyCertificate", ">Intel(r) AMT Certificate: Handle: 2<"),
+		}
+		lps := setupWsmanResponses(t, forced, rfa)
+		assert.Equal(t, utils.CIRAConfigurationRemoved, lps.ConfigureCIRA())
+	})
+}
+
+func TestCIRAConfiguredOrder(t *testing.T) {
+	f := &flags.Flags{}
+	f.CIRASettings = flags.CIRASettingsFlags{
+		MPSAddress: "mps.example.com", MPSPort: 4433, MPSCommonName: "mps.example.com",
+		SecondaryAddress: "mps2.example.com", SecondaryPort: 4433, SecondaryCommonName: "mps2.example.com",
+	}
+	var mpsRsp ManagementPresenceRemoteSAPDetailsPullResponse
+	for i, host := range []string{"mps.example.com", "mps2.example.com"} {
+		mpsRsp.Body.PullResponse.Items = append(mpsRsp.Body.PullResponse.Items, struct {
+			Name       string
+			AccessInfo string
+			InfoFormat int
+			Port       int
+			CN         string
+		}{Name: "Intel(r) AMT:Management Presence Server " + strconv.Itoa(i), AccessInfo: host, InfoFormat: 201, Port: 4433, CN: host})
+	}
+	var envRsp EnvironmentDetectionSettingDataPullResponse
+	envRsp.Body.PullResponse.Items = append(envRsp.Body.PullResponse.Items, struct {
+		DetectionStrings []string
+	}{DetectionStrings: []string{"0123456789abcdef.com"}})
+	var ruleRsp RemoteAccessPolicyRuleDetailsPullResponse
+	for i, name := range []string{"User Initiated", "Alert"} {
+		ruleRsp.Body.PullResponse.Items = append(ruleRsp.Body.PullResponse.Items, struct {
+			PolicyRuleName string
+			Trigger        int
+			TunnelLifeTime int
+			ExtendedData   string
+		}{PolicyRuleName: name, Trigger: i})
+	}
+	appliesTo := func(first, second string) string {
+		item := `<h:AMT_RemoteAccessPolicyAppliesToMPS><h:ManagedElement><b:ReferenceParameters><c:SelectorSet><c:Selector Name="Name">Intel(r) AMT:Management Presence Server %s</c:Selector></c:SelectorSet></b:ReferenceParameters></h:ManagedElement><h:OrderOfAccess>%d</h:OrderOfAccess></h:AMT_RemoteAccessPolicyAppliesToMPS>`
+		return `<a:Envelope><a:Body><g:PullResponse><g:Items>` + fmt.Sprintf(item, first, 0) + fmt.Sprintf(item, second, 1) + `</g:Items></g:PullResponse></a:Body></a:Envelope>`
+	}
+	responses := func(order string) ResponseFuncArray {
+		return ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, mpsRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, envRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, ruleRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondStringFunc(t, order),
+		}
+	}
+
+	t.Run("matches when the primary is tried first", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, responses(appliesTo("0", "1")))
+		configured, rc := lps.ciraConfigured()
+		assert.Equal(t, utils.Success, rc)
+		assert.True(t, configured)
+	})
+	t.Run("does not match when the secondary is tried first", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, responses(appliesTo("1", "0")))
+		configured, rc := lps.ciraConfigured()
+		assert.Equal(t, utils.Success, rc)
+		assert.False(t, configured)
+	})
+}
+
+func TestAddMpServerReferences(t *testing.T) {
+	f := &flags.Flags{}
+	lps := setupService(f)
+	lps.setupWsmanClient("admin", "password")
+	selector := struct {
+		XMLName xml.Name `xml:"w:Selector,omitempty"`
+		Name    string   `xml:"Name,attr"`
+		Value   string   `xml:",chardata"`
+	}{Name: "Name", Value: "primary"}
+	xmlMsg := lps.amtMessages.RemoteAccessService.AddRemoteAccessPolicyRule(remoteaccess.RemoteAccessPolicyRule{}, selector)
+
+	assert.Equal(t, xmlMsg, addMpServerReferences(xmlMsg, nil))
+	both := addMpServerReferences(xmlMsg, []string{"secondary"})
+	assert.Equal(t, 2, strings.Count(both, "<h:MpServer>"))
+	assert.Less(t, strings.Index(both, `<Selector Name="Name">primary</Selector>`), strings.Index(both, `<Selector Name="Name">secondary</Selector>`))
+	assert.Less(t, strings.LastIndex(both, "</h:MpServer>"), strings.Index(both, "</h:AddRemoteAccessPolicyRule_INPUT>"))
+}
+
+func TestAddMPS(t *testing.T) {
+	f := &flags.Flags{}
+	mps := MPSServer{Hostname: "192.168.1.10", Port: 4433}
+
+	t.Run("returns the name of the added server", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondStringFunc(t, addMpServerXMLResponse)})
+		name, rc := lps.AddMPS(mps)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, "Intel(r) AMT:Management Presence Server 0", name)
+	})
+	t.Run("returns CIRAConfigurationFailed without a name", func(t *testing.T) {
+		noName := strings.Replace(addMpServerXMLResponse, `Name="Name"`, `Name="Other"`, 1)
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondStringFunc(t, noName)})
+		_, rc := lps.AddMPS(mps)
+		assert.Equal(t, utils.CIRAConfigurationFailed, rc)
+	})
+	t.Run("returns CIRAConfigurationFailed on server error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		_, rc := lps.AddMPS(mps)
+		assert.Equal(t, utils.CIRAConfigurationFailed, rc)
+	})
+}
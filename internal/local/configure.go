@@ -1,11 +1,14 @@
 package local
 
 import (
+	"encoding/xml"
 	"fmt"
 	"regexp"
 	"rpc/internal/config"
+	"rpc/internal/flags"
 	"rpc/pkg/utils"
 
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/ethernetport"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
@@ -13,7 +16,6 @@ import (
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
-	log "github.com/sirupsen/logrus"
 )
 
 func (service *ProvisioningService) Configure() utils.ReturnCode {
@@ -23,22 +25,62 @@ func (service *ProvisioningService) Configure() utils.ReturnCode {
 		return service.AddWifiSettings()
 	case utils.SubCommandEnableWifiPort:
 		return service.EnableWifiPort()
+	case utils.SubCommandConfigureTLS:
+		return service.ConfigureTLS()
+	case utils.SubCommandConfigureCIRA:
+		return service.ConfigureCIRA()
+	case utils.SubCommandWired8021x:
+		return service.ConfigureWired8021x()
+	case utils.SubCommandAlarmClock:
+		return service.ConfigureAlarmClock()
+	case utils.SubCommandRedirection:
+		return service.ConfigureRedirection()
+	case utils.SubCommandDNSSuffix:
+		return service.ConfigureDNSSuffix()
+	case utils.SubCommandAMTFeatures:
+		return service.ConfigureAMTFeatures()
+	case utils.SubCommandCertHash:
+		return service.ConfigureCertHash()
 	default:
 	}
 	return utils.IncorrectCommandLineParameters
 }
 
 func (service *ProvisioningService) EnableWifiPort() utils.ReturnCode {
+	if service.lacksWiFi(log.Errorf) {
+		return utils.UnsupportedBySKU
+	}
+	if service.flags.WifiPort.Disable {
+		rc := service.DisableWifi()
+		if rc != utils.Success {
+			log.Error("Failed to disable wifi port and local profile synchronization.")
+		} else {
+			log.Info("Successfully disabled wifi port and local profile synchronization.")
+		}
+		return rc
+	}
 	rc := service.EnableWifi()
 	if rc != utils.Success {
 		log.Error("Failed to enable wifi port and local profile synchronization.")
+		return rc
+	}
+	log.Info("Successfully enabled wifi port and local profile synchronization.")
+	if service.flags.WifiPort.LinkPreference == "" {
+		return utils.Success
+	}
+	rc = service.SetWifiLinkPreference(service.flags.WifiPort.LinkPreference, service.flags.WifiPort.LinkPreferenceTimeout)
+	if rc != utils.Success {
+		log.Error("Failed to set the wifi link preference.")
 	} else {
-		log.Info("Successfully enabled wifi port and local profile synchronization.")
+		log.Infof("Successfully set the wifi link preference to %s.", service.flags.WifiPort.LinkPreference)
 	}
 	return rc
 }
 
 func (service *ProvisioningService) AddWifiSettings() utils.ReturnCode {
+	if service.lacksWiFi(log.Errorf) {
+		return utils.UnsupportedBySKU
+	}
 	// start with fresh map
 	service.handlesWithCerts = make(map[string]string)
 
@@ -335,6 +377,95 @@ func (service *ProvisioningService) EnableWifi() utils.ReturnCode {
 	return utils.Success
 }
 
+// DisableWifi turns off local profile synchronization and the wifi port, wifi profiles
+// are kept but AMT is no longer reachable over wifi
+func (service *ProvisioningService) DisableWifi() utils.ReturnCode {
+	xmlMsg := service.amtMessages.WiFiPortConfigurationService.Get()
+	var portCfgRsp wifiportconfiguration.Response
+	rc := service.PostAndUnmarshal(xmlMsg, &portCfgRsp)
+	if rc != utils.Success {
+		return rc
+	}
+
+	if portCfgRsp.Body.WiFiPortConfigurationService.LocalProfileSynchronizationEnabled != wifiportconfiguration.LocalSyncDisabled {
+		portCfgRsp.Body.WiFiPortConfigurationService.LocalProfileSynchronizationEnabled = wifiportconfiguration.LocalSyncDisabled
+		xmlMsg = service.amtMessages.WiFiPortConfigurationService.Put(portCfgRsp.Body.WiFiPortConfigurationService)
+		rc = service.PostAndUnmarshal(xmlMsg, &portCfgRsp)
+		if rc != utils.Success {
+			return rc
+		}
+		if portCfgRsp.Body.WiFiPortConfigurationService.LocalProfileSynchronizationEnabled != wifiportconfiguration.LocalSyncDisabled {
+			log.Errorf("failed to disable wifi local profile synchronization")
+			return utils.WiFiConfigurationFailed
+		}
+	}
+
+	//   Enumeration 3 - WiFi is disabled
+	xmlMsg = service.cimMessages.WiFiPort.RequestStateChange(3)
+	var stateChangeRsp wifi.RequestStateChangeResponse
+	rc = service.PostAndUnmarshal(xmlMsg, &stateChangeRsp)
+	if rc != utils.Success {
+		return rc
+	}
+	rc = utils.ReturnCode(stateChangeRsp.Body.RequestStateChange_OUTPUT.ReturnValue)
+	if rc != utils.Success {
+		log.Errorf("RequestStateChange_OUTPUT.ReturnValue: %d", rc)
+		return utils.AmtPtStatusCodeBase + rc
+	}
+	return utils.Success
+}
+
+// wirelessPortSettingsInstanceID selects the wifi port, the wired port is instance 0
+const wirelessPortSettingsInstanceID = "Intel(r) AMT Ethernet Port Settings 1"
+
+var linkPreferences = map[string]ethernetport.LinkPreference{
+	flags.LinkPreferenceME:   ethernetport.LinkPreferenceME,
+	flags.LinkPreferenceHost: ethernetport.LinkPreferenceHOST,
+}
+
+type setLinkPreferenceInput struct {
+	XMLName        xml.Name `xml:"h:SetLinkPreference_INPUT"`
+	H              string   `xml:"xmlns:h,attr"`
+	LinkPreference int      `xml:"h:LinkPreference"`
+	Timeout        int      `xml:"h:Timeout"`
+}
+
+type SetLinkPreferenceResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetLinkPreference_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// setLinkPreferenceMessage builds AMT_EthernetPortSettings.SetLinkPreference for the wifi port,
+// go-wsman-messages has no such action
+func setLinkPreferenceMessage(preference ethernetport.LinkPreference, timeout int) (string, error) {
+	resourceURI := amtResourceURIBase + ethernetport.AMT_EthernetPortSettings
+	return wsmanMessage(resourceURI+"/SetLinkPreference", resourceURI,
+		[]wsmanSelector{{Name: "InstanceID", Value: wirelessPortSettingsInstanceID}},
+		setLinkPreferenceInput{H: resourceURI, LinkPreference: int(preference), Timeout: timeout})
+}
+
+// SetWifiLinkPreference gives the wifi link to AMT or the host OS. AMT returns the link
+// to the host when the timeout expires after a me preference.
+func (service *ProvisioningService) SetWifiLinkPreference(preference string, timeout int) utils.ReturnCode {
+	xmlMsg, err := setLinkPreferenceMessage(linkPreferences[preference], timeout)
+	if err != nil {
+		log.Error("unable to create the SetLinkPreference message: ", err)
+		return utils.WiFiConfigurationFailed
+	}
+	var rsp SetLinkPreferenceResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return rc
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("SetLinkPreference_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.AmtPtStatusCodeBase + utils.ReturnCode(rsp.Body.Output.ReturnValue)
+	}
+	return utils.Success
+}
+
 type Handles struct {
 	privateKeyHandle string
 	clientCertHandle string
@@ -10,6 +10,7 @@ import (
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/credential"
 
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/ethernetport"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/wifiportconfiguration"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
@@ -516,6 +517,46 @@ func TestEnableWifiErrors(t *testing.T) {
 	})
 }
 
+func TestDisableWifiErrors(t *testing.T) {
+	f := &flags.Flags{}
+	pcsRsp := wifiportconfiguration.Response{}
+	pcsRsp.Body.WiFiPortConfigurationService.LocalProfileSynchronizationEnabled = 1
+	t.Run("expect WSMANMessageError for WiFiPortConfigurationService.Get()", func(t *testing.T) {
+		rfa := ResponseFuncArray{respondServerErrFunc()}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DisableWifi()
+		assert.Equal(t, utils.WSMANMessageError, rc)
+	})
+	t.Run("expect WiFiConfigurationFailed when disable is unsuccessful", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, pcsRsp),
+			respondMsgFunc(t, pcsRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DisableWifi()
+		assert.Equal(t, utils.WiFiConfigurationFailed, rc)
+	})
+	t.Run("expect non-zero error for RequestStateChange()", func(t *testing.T) {
+		stateChangeResponse := wifi.RequestStateChangeResponse{}
+		stateChangeResponse.Body.RequestStateChange_OUTPUT.ReturnValue = 1
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, wifiportconfiguration.Response{}),
+			respondMsgFunc(t, stateChangeResponse),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DisableWifi()
+		assert.Equal(t, utils.AmtPtStatusCodeBase+1, rc)
+	})
+}
+
+func TestSetLinkPreferenceMessage(t *testing.T) {
+	xmlMsg, err := setLinkPreferenceMessage(ethernetport.LinkPreferenceME, 300)
+	assert.NoError(t, err)
+	assert.Contains(t, xmlMsg, "<a:Action>http://intel.com/wbem/wscim/1/amt-schema/1/AMT_EthernetPortSettings/SetLinkPreference</a:Action>")
+	assert.Contains(t, xmlMsg, `<w:Selector Name="InstanceID">Intel(r) AMT Ethernet Port Settings 1</w:Selector>`)
+	assert.Contains(t, xmlMsg, "<h:LinkPreference>1</h:LinkPreference><h:Timeout>300</h:Timeout>")
+}
+
 func TestRollbackAddedItems(t *testing.T) {
 	f := &flags.Flags{}
 	handles := Handles{
@@ -694,4 +735,42 @@ func TestEnableWifiPort(t *testing.T) {
 		rc := lps.EnableWifiPort()
 		assert.Equal(t, utils.WSMANMessageError, rc)
 	})
+	t.Run("expect Success with -linkPreference", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.WifiPort = flags.WifiPortFlags{LinkPreference: flags.LinkPreferenceME, LinkPreferenceTimeout: 300}
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, pcsRsp),
+			respondMsgFunc(t, wifi.RequestStateChangeResponse{}),
+			respondMsgFunc(t, SetLinkPreferenceResponse{}),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.EnableWifiPort()
+		assert.Equal(t, utils.Success, rc)
+	})
+	t.Run("expect AMT status code when SetLinkPreference fails", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.WifiPort = flags.WifiPortFlags{LinkPreference: flags.LinkPreferenceHost, LinkPreferenceTimeout: 60}
+		linkRsp := SetLinkPreferenceResponse{}
+		linkRsp.Body.Output.ReturnValue = 1
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, pcsRsp),
+			respondMsgFunc(t, wifi.RequestStateChangeResponse{}),
+			respondMsgFunc(t, linkRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.EnableWifiPort()
+		assert.Equal(t, utils.AmtPtStatusCodeBase+1, rc)
+	})
+	t.Run("expect Success with -disable", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.WifiPort.Disable = true
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, pcsRsp),
+			respondMsgFunc(t, wifiportconfiguration.Response{}),
+			respondMsgFunc(t, wifi.RequestStateChangeResponse{}),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.EnableWifiPort()
+		assert.Equal(t, utils.Success, rc)
+	})
 }
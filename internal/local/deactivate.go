@@ -2,12 +2,71 @@ package local
 
 import (
 	"encoding/xml"
+	internalAMT "rpc/internal/amt"
+	"rpc/internal/logging"
+	"rpc/pkg/pthi"
 	"rpc/pkg/utils"
+	"time"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/setupandconfiguration"
-	log "github.com/sirupsen/logrus"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/tls"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/userinitiatedconnection"
 )
 
+// AMT takes a few seconds to return to pre-provisioning after it accepts an unprovision
+var (
+	unprovisionTimeout      = 30 * time.Second
+	unprovisionPollInterval = time.Second
+)
+
+var (
+	provisioningStatePre  = utils.InterpretProvisioningState(0)
+	provisioningStateIn   = utils.InterpretProvisioningState(1)
+	provisioningStatePost = utils.InterpretProvisioningState(2)
+)
+
+type RemoteAccessPolicyRulePullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				PolicyRuleName string
+			} `xml:"Items>AMT_RemoteAccessPolicyRule"`
+		}
+	}
+}
+
+type ManagementPresenceRemoteSAPPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []struct {
+				Name       string
+				AccessInfo string
+			} `xml:"Items>AMT_ManagementPresenceRemoteSAP"`
+		}
+	}
+}
+
+type TLSSettingDataPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []TLSSettingDataItem `xml:"Items>AMT_TLSSettingData"`
+		}
+	}
+}
+
+type TLSSettingDataItem struct {
+	ElementName                   string
+	InstanceID                    string
+	MutualAuthentication          bool
+	Enabled                       bool
+	TrustedCN                     string
+	AcceptNonSecureConnections    bool
+	NonSecureConnectionsSupported bool
+}
+
 func (service *ProvisioningService) Deactivate() utils.ReturnCode {
 
 	controlMode, err := service.amtCommand.GetControlMode()
@@ -15,11 +74,32 @@ func (service *ProvisioningService) Deactivate() utils.ReturnCode {
 		log.Error(err)
 		return utils.AMTConnectionFailed
 	}
+	if service.flags.PartialDeactivate && (controlMode == 1 || controlMode == 2) {
+		return service.DeactivatePartial()
+	}
+	if controlMode == 1 || controlMode == 2 {
+		if rc := service.flags.ConfirmDeactivation(service.amtCommand); rc != utils.Success {
+			return rc
+		}
+		log.WithFields(logging.Fields{
+			"controlMode": utils.InterpretControlMode(controlMode),
+			"reason":      service.flags.Deactivate.Reason,
+		}).Info("deactivating AMT")
+	}
+	if service.flags.WipeStorage && (controlMode == 1 || controlMode == 2) {
+		return service.DeactivateWipe(controlMode)
+	}
 	if controlMode == 1 {
 		return service.DeactivateCCM()
 	} else if controlMode == 2 {
 		return service.DeactivateACM()
 	}
+	// an interrupted unprovision can leave AMT in-provisioning without a control mode,
+	// unprovision again to get back to pre-provisioning
+	if state, err := service.amtCommand.GetOperationalState(); err == nil && state.ProvisioningState == provisioningStateIn {
+		log.Warn("AMT is in-provisioning, completing the unprovision")
+		return service.unprovisionMEI()
+	}
 	log.Error("Deactivation failed. Device control mode: " + utils.InterpretControlMode(controlMode))
 	return utils.UnableToDeactivate
 }
@@ -30,6 +110,15 @@ func (service *ProvisioningService) DeactivateACM() utils.ReturnCode {
 			return rc
 		}
 	}
+	if rc := service.unprovisionWsman(); rc != utils.Success {
+		return rc
+	}
+	log.Info("Status: Device deactivated in ACM.")
+	return utils.Success
+}
+
+// unprovisionWsman unprovisions AMT with the admin password, which works in both control modes
+func (service *ProvisioningService) unprovisionWsman() utils.ReturnCode {
 	service.setupWsmanClient("admin", service.flags.Password)
 	msg := service.amtMessages.SetupAndConfigurationService.Unprovision(1)
 	response, err := service.client.Post(msg)
@@ -47,19 +136,192 @@ func (service *ProvisioningService) DeactivateACM() utils.ReturnCode {
 		log.Error("Status: Failed to deactivate. ReturnValue: ", setupResponse.Body.Unprovision_OUTPUT.ReturnValue)
 		return utils.DeactivationFailed
 	}
-	log.Info("Status: Device deactivated in ACM.")
 	return utils.Success
 }
 
+// DeactivateCCM unprovisions a device in client control mode over the MEI. Firmware that
+// does not permit this is unprovisioned over wsman with the admin password instead.
 func (service *ProvisioningService) DeactivateCCM() utils.ReturnCode {
-	if service.flags.Password != "" {
+	status, err := service.amtCommand.Unprovision()
+	if err == nil && status == pthi.AMT_STATUS_NOT_PERMITTED {
+		log.Warn("unprovision over the MEI is not permitted, unprovisioning with the admin password")
+		if service.flags.Password == "" {
+			if _, rc := service.flags.ReadPasswordFromUser(); rc != utils.Success {
+				return rc
+			}
+		}
+		if rc := service.unprovisionWsman(); rc != utils.Success {
+			return rc
+		}
+		return service.waitForPreProvisioning()
+	}
+	if service.flags.Password != "" && !service.flags.WipeStorage {
 		log.Warn("Password not required for CCM deactivation")
 	}
+	if err != nil || status != 0 {
+		log.Errorf("Status: Failed to deactivate, status: %d %v", status, err)
+		return utils.DeactivationFailed
+	}
+	return service.waitForPreProvisioning()
+}
+
+func (service *ProvisioningService) unprovisionMEI() utils.ReturnCode {
 	status, err := service.amtCommand.Unprovision()
 	if err != nil || status != 0 {
-		log.Error("Status: Failed to deactivate ", err)
+		log.Errorf("Status: Failed to deactivate, status: %d %v", status, err)
+		return utils.DeactivationFailed
+	}
+	return service.waitForPreProvisioning()
+}
+
+// waitForPreProvisioning waits for AMT to finish the unprovision it accepted. AMT
+// left in-provisioning or unreachable is reported as DeactivationIncomplete.
+func (service *ProvisioningService) waitForPreProvisioning() utils.ReturnCode {
+	deadline := time.Now().Add(unprovisionTimeout)
+	var state internalAMT.OperationalState
+	var err error
+	for {
+		state, err = service.amtCommand.GetOperationalState()
+		if err == nil && state.ProvisioningState == provisioningStatePre {
+			log.Info("Status: Device deactivated.")
+			return utils.Success
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(unprovisionPollInterval)
+	}
+	if err != nil {
+		log.Error("unable to read the provisioning state after unprovision: ", err)
+		return utils.DeactivationIncomplete
+	}
+	if state.ProvisioningState == provisioningStatePost {
+		log.Error("Status: Failed to deactivate, AMT is still activated")
 		return utils.DeactivationFailed
 	}
-	log.Info("Status: Device deactivated.")
+	log.Errorf("Status: Deactivation incomplete, AMT is %s. Run deactivate -local again or reboot the device", state.ProvisioningState)
+	return utils.DeactivationIncomplete
+}
+
+// DeactivatePartial removes the remote access (CIRA), TLS and wifi configuration
+// but leaves the device activated in its current control mode
+func (service *ProvisioningService) DeactivatePartial() utils.ReturnCode {
+	if service.flags.Password == "" {
+		if _, rc := service.flags.ReadPasswordFromUser(); rc != utils.Success {
+			return rc
+		}
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	// each step is attempted even if an earlier one fails
+	// the first failure determines the return code
+	result := utils.Success
+	for _, step := range []func() utils.ReturnCode{
+		service.RemoveCIRAConfiguration,
+		service.DisableTLS,
+		service.PruneWifiConfigs,
+	} {
+		if rc := step(); rc != utils.Success && result == utils.Success {
+			result = rc
+		}
+	}
+	if result != utils.Success {
+		log.Error("Status: Partial deactivation did not complete")
+		return result
+	}
+	log.Info("Status: CIRA, TLS and wifi configuration removed. Device remains activated.")
+	return utils.Success
+}
+
+func (service *ProvisioningService) RemoveCIRAConfiguration() utils.ReturnCode {
+	_, rc := service.removeCIRAConfiguration()
+	return rc
+}
+
+// removeCIRAConfiguration disables the CIRA connection and deletes the policy rules and
+// MPS servers, removed tells whether AMT had a MPS server configured
+func (service *ProvisioningService) removeCIRAConfiguration() (removed bool, rc utils.ReturnCode) {
+	xmlMsg := service.amtMessages.UserInitiatedConnectionService.RequestStateChange(userinitiatedconnection.AllInterfacesDisabled)
+	if _, err := service.client.Post(xmlMsg); err != nil {
+		log.Error("unable to disable user initiated connections: ", err)
+		return removed, utils.CIRAConfigurationFailed
+	}
+
+	var policyRules RemoteAccessPolicyRulePullResponse
+	rc = service.EnumPullUnmarshal(
+		service.amtMessages.RemoteAccessPolicyRule.Enumerate,
+		service.amtMessages.RemoteAccessPolicyRule.Pull,
+		&policyRules,
+	)
+	if rc != utils.Success {
+		return removed, utils.CIRAConfigurationFailed
+	}
+	for _, rule := range policyRules.Body.PullResponse.Items {
+		log.Infof("deleting remote access policy rule: %s", rule.PolicyRuleName)
+		xmlMsg = service.amtMessages.RemoteAccessPolicyRule.Delete(rule.PolicyRuleName)
+		if _, err := service.client.Post(xmlMsg); err != nil {
+			log.Errorf("unable to delete: %s %s", rule.PolicyRuleName, err)
+			return removed, utils.CIRAConfigurationFailed
+		}
+	}
+
+	var mpsServers ManagementPresenceRemoteSAPPullResponse
+	rc = service.EnumPullUnmarshal(
+		service.amtMessages.ManagementPresenceRemoteSAP.Enumerate,
+		service.amtMessages.ManagementPresenceRemoteSAP.Pull,
+		&mpsServers,
+	)
+	if rc != utils.Success {
+		return removed, utils.CIRAConfigurationFailed
+	}
+	for _, mps := range mpsServers.Body.PullResponse.Items {
+		log.Infof("deleting management presence server: %s", mps.AccessInfo)
+		xmlMsg = service.amtMessages.ManagementPresenceRemoteSAP.Delete(mps.Name)
+		if _, err := service.client.Post(xmlMsg); err != nil {
+			log.Errorf("unable to delete: %s %s", mps.Name, err)
+			return removed, utils.CIRAConfigurationFailed
+		}
+		removed = true
+	}
+	return removed, utils.Success
+}
+
+func (service *ProvisioningService) DisableTLS() utils.ReturnCode {
+	var settings TLSSettingDataPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.TLSSettingData.Enumerate,
+		service.amtMessages.TLSSettingData.Pull,
+		&settings,
+	)
+	if rc != utils.Success {
+		return utils.TLSConfigurationFailed
+	}
+	changed := false
+	for _, item := range settings.Body.PullResponse.Items {
+		if !item.Enabled {
+			continue
+		}
+		log.Infof("disabling TLS: %s", item.InstanceID)
+		tlsSettingData := tls.TLSSettingData{
+			MutualAuthentication:       false,
+			Enabled:                    false,
+			AcceptNonSecureConnections: item.AcceptNonSecureConnections,
+		}
+		tlsSettingData.ElementName = item.ElementName
+		tlsSettingData.InstanceID = item.InstanceID
+		xmlMsg := service.amtMessages.TLSSettingData.Put(tlsSettingData)
+		if _, err := service.client.Post(xmlMsg); err != nil {
+			log.Errorf("unable to disable TLS: %s %s", item.InstanceID, err)
+			return utils.TLSConfigurationFailed
+		}
+		changed = true
+	}
+	if !changed {
+		return utils.Success
+	}
+	// TLS changes only take effect after they are committed
+	if _, err := service.client.Post(service.amtMessages.SetupAndConfigurationService.CommitChanges()); err != nil {
+		log.Error("unable to commit TLS changes: ", err)
+		return utils.TLSConfigurationFailed
+	}
 	return utils.Success
 }
@@ -4,9 +4,13 @@ import (
 	"errors"
 	"net/http"
 	"rpc/internal/flags"
+	"rpc/pkg/pthi"
 	"rpc/pkg/utils"
 	"testing"
+	"time"
 
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,6 +42,14 @@ func TestDeactivateCCM(t *testing.T) {
 	f.Command = utils.CommandDeactivate
 	f.LocalConfig.Password = "P@ssw0rd"
 	mockControlMode = 1
+	origState := mockOperationalState
+	mockOperationalState.ProvisioningState = "pre-provisioning"
+	origTimeout, origInterval := unprovisionTimeout, unprovisionPollInterval
+	unprovisionTimeout, unprovisionPollInterval = 10*time.Millisecond, time.Millisecond
+	defer func() {
+		mockOperationalState = origState
+		unprovisionTimeout, unprovisionPollInterval = origTimeout, origInterval
+	}()
 
 	t.Run("returns Success without password", func(t *testing.T) {
 		f.Password = ""
@@ -65,11 +77,70 @@ func TestDeactivateCCM(t *testing.T) {
 		assert.Equal(t, utils.DeactivationFailed, rc)
 		mockUnprovisionCode = 0
 	})
+	t.Run("returns DeactivationIncomplete when AMT stays in-provisioning", func(t *testing.T) {
+		mockOperationalState.ProvisioningState = "in-provisioning"
+		defer func() { mockOperationalState.ProvisioningState = "pre-provisioning" }()
+		lps := setupService(f)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.DeactivationIncomplete, rc)
+	})
+	t.Run("returns DeactivationIncomplete when the provisioning state cannot be read", func(t *testing.T) {
+		mockOperationalStateErr = errors.New("test error")
+		defer func() { mockOperationalStateErr = nil }()
+		lps := setupService(f)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.DeactivationIncomplete, rc)
+	})
+	t.Run("returns DeactivationFailed when AMT stays activated", func(t *testing.T) {
+		mockOperationalState.ProvisioningState = "post-provisioning"
+		defer func() { mockOperationalState.ProvisioningState = "pre-provisioning" }()
+		lps := setupService(f)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.DeactivationFailed, rc)
+	})
+	t.Run("returns Success unprovisioning over wsman when the MEI is not permitted", func(t *testing.T) {
+		mockUnprovisionCode = pthi.AMT_STATUS_NOT_PERMITTED
+		defer func() { mockUnprovisionCode = 0 }()
+		f.Password = "P@ssw0rd"
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respondUnprovision(t, w)
+		})
+		lps := setupWithWsmanClient(f, handler)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.Success, rc)
+	})
+	t.Run("returns UnableToDeactivate when wsman unprovision fails after the MEI is not permitted", func(t *testing.T) {
+		mockUnprovisionCode = pthi.AMT_STATUS_NOT_PERMITTED
+		defer func() { mockUnprovisionCode = 0 }()
+		f.Password = "P@ssw0rd"
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respondServerError(w)
+		})
+		lps := setupWithWsmanClient(f, handler)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.UnableToDeactivate, rc)
+	})
+	t.Run("unprovisions again when an earlier unprovision left AMT in-provisioning", func(t *testing.T) {
+		mockControlMode = 0
+		mockOperationalState.ProvisioningState = "in-provisioning"
+		defer func() {
+			mockControlMode = 1
+			mockOperationalState.ProvisioningState = "pre-provisioning"
+		}()
+		lps := setupService(f)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.DeactivationIncomplete, rc)
+		mockUnprovisionErr = errors.New("test error")
+		defer func() { mockUnprovisionErr = nil }()
+		rc = lps.Deactivate()
+		assert.Equal(t, utils.DeactivationFailed, rc)
+	})
 }
 
 func TestDeactivateACM(t *testing.T) {
 	f := &flags.Flags{}
 	f.Command = utils.CommandDeactivate
+	f.Force = true
 	f.LocalConfig.Password = "P@ssw0rd"
 	mockControlMode = 2
 
@@ -122,3 +193,91 @@ func TestDeactivateACM(t *testing.T) {
 		assert.Equal(t, utils.DeactivationFailed, rc)
 	})
 }
+
+func TestDeactivatePartial(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandDeactivate
+	f.PartialDeactivate = true
+	f.Password = "P@ssw0rd"
+	orig := mockControlMode
+	mockControlMode = 2
+	defer func() { mockControlMode = orig }()
+
+	policyRules := RemoteAccessPolicyRulePullResponse{}
+	policyRules.Body.PullResponse.Items = append(policyRules.Body.PullResponse.Items, struct{ PolicyRuleName string }{"Periodic"})
+	mpsServers := ManagementPresenceRemoteSAPPullResponse{}
+	mpsServers.Body.PullResponse.Items = append(mpsServers.Body.PullResponse.Items, struct {
+		Name       string
+		AccessInfo string
+	}{"Intel(r) AMT:Management Presence Server 0", "mps.example.com"})
+	tlsSettings := TLSSettingDataPullResponse{}
+	tlsSettings.Body.PullResponse.Items = []TLSSettingDataItem{
+		{InstanceID: "Intel(r) AMT 802.3 TLS Settings", Enabled: true},
+		{InstanceID: "Intel(r) AMT LMS TLS Settings", Enabled: false},
+	}
+	ciraResponses := func() ResponseFuncArray {
+		return ResponseFuncArray{
+			respondStringFunc(t, "state changed"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, policyRules),
+			respondStringFunc(t, "rule deleted"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, mpsServers),
+			respondStringFunc(t, "mps deleted"),
+		}
+	}
+	tlsResponses := func() ResponseFuncArray {
+		return ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "tls disabled"),
+			respondStringFunc(t, "changes committed"),
+		}
+	}
+	wifiResponses := func() ResponseFuncArray {
+		return append(
+			emptyGetWifiIeee8021xCerts(t),
+			ResponseFuncArray{
+				respondMsgFunc(t, common.EnumerationResponse{}),
+				respondMsgFunc(t, wifi.PullResponseEnvelope{}),
+			}...,
+		)
+	}
+
+	t.Run("returns Success for happy path", func(t *testing.T) {
+		rfa := append(ciraResponses(), tlsResponses()...)
+		rfa = append(rfa, wifiResponses()...)
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.Deactivate()
+		assert.Equal(t, utils.Success, rc)
+	})
+	t.Run("returns CIRAConfigurationFailed but continues with TLS and wifi", func(t *testing.T) {
+		rfa := ResponseFuncArray{respondServerErrFunc()}
+		rfa = append(rfa, tlsResponses()...)
+		rfa = append(rfa, wifiResponses()...)
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DeactivatePartial()
+		assert.Equal(t, utils.CIRAConfigurationFailed, rc)
+	})
+	t.Run("returns TLSConfigurationFailed when commit fails", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "tls disabled"),
+			respondServerErrFunc(),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DisableTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+	})
+	t.Run("returns Success without commit when TLS is not enabled", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, TLSSettingDataPullResponse{}),
+			respondServerErrFunc(), // this one should NOT get called
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.DisableTLS()
+		assert.Equal(t, utils.Success, rc)
+	})
+}
@@ -0,0 +1,223 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/internal/logging"
+	"rpc/pkg/utils"
+	"strconv"
+	"time"
+)
+
+// DiagManifest describes the diagnostics bundle, it is the manifest.json of the zip
+type DiagManifest struct {
+	Version string      `json:"version"`
+	Created time.Time   `json:"created"`
+	Host    string      `json:"host"`
+	Files   []DiagEntry `json:"files"`
+}
+
+// DiagEntry is a file of the diagnostics bundle, Error tells why it is missing or incomplete
+type DiagEntry struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// DiagInterface is a network interface of the host in network.json
+type DiagInterface struct {
+	Name         string   `json:"name"`
+	HardwareAddr string   `json:"hardwareAddr,omitempty"`
+	MTU          int      `json:"mtu"`
+	Flags        string   `json:"flags"`
+	Addresses    []string `json:"addresses"`
+}
+
+// diagBundle writes the files of the bundle to the zip with the secrets redacted
+type diagBundle struct {
+	zip      *zip.Writer
+	manifest DiagManifest
+}
+
+// add writes the content to the bundle and notes err in the manifest, content that was
+// read before the error is kept
+func (b *diagBundle) add(name string, content []byte, err error) error {
+	entry := DiagEntry{Name: name}
+	if err != nil {
+		entry.Error = logging.RedactString(err.Error())
+	}
+	if content != nil {
+		w, zipErr := b.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.manifest.Created})
+		if zipErr != nil {
+			return zipErr
+		}
+		if _, zipErr = w.Write([]byte(logging.RedactString(string(content)))); zipErr != nil {
+			return zipErr
+		}
+	}
+	b.manifest.Files = append(b.manifest.Files, entry)
+	return nil
+}
+
+// Diag writes the values support asks for on an air-gapped host to a timestamped zip, so
+// they can be carried off the host in one file: amtinfo, the AMT event log, the self-test
+// with the LMS status, the network configuration of the OS and the rpc logs. The AMT
+// password and the secrets matched by name are redacted in every file. What can not be
+// collected is noted in manifest.json and does not fail the bundle.
+func (service *ProvisioningService) Diag() utils.ReturnCode {
+	logging.Redact(service.flags.Password)
+	host, _ := os.Hostname()
+	created := time.Now().UTC()
+	path := filepath.Join(service.flags.Diag.Dir, fmt.Sprintf("rpc-diag-%s-%s.zip", host, created.Format("20060102T150405Z")))
+	// the bundle is only readable by its owner, a file of the same name is not replaced
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		log.Error("unable to create the diagnostics bundle: ", err)
+		return utils.DiagBundleFailed
+	}
+	bundle := &diagBundle{zip: zip.NewWriter(file), manifest: DiagManifest{Version: utils.ProjectVersion, Created: created, Host: host}}
+	err = service.writeDiagBundle(bundle)
+	if err == nil {
+		err = bundle.zip.Close()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Error("unable to write the diagnostics bundle: ", err)
+		os.Remove(path)
+		return utils.DiagBundleFailed
+	}
+
+	w := service.newOutputWriter()
+	w.Field("bundle", "", path)
+	w.Field("files", "", bundle.manifest.Files)
+	w.Printf("Diagnostics bundle written to %s\n", path)
+	for _, entry := range bundle.manifest.Files {
+		if entry.Error != "" {
+			w.Printf("  %s: %s\n", entry.Name, entry.Error)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
+
+func (service *ProvisioningService) writeDiagBundle(bundle *diagBundle) error {
+	content, err := service.diagAMTInfo()
+	if err := bundle.add("amtinfo.json", content, err); err != nil {
+		return err
+	}
+	content, err = service.diagEventLog()
+	if err := bundle.add("eventlog.json", content, err); err != nil {
+		return err
+	}
+	content, err = json.MarshalIndent(service.SelfTestChecks(), "", "  ")
+	if err := bundle.add("selftest.json", content, err); err != nil {
+		return err
+	}
+	content, err = diagNetwork()
+	if err := bundle.add("network.json", content, err); err != nil {
+		return err
+	}
+	name, content, err := osNetworkConfig()
+	if err := bundle.add(name, content, err); err != nil {
+		return err
+	}
+	for i, logFile := range service.flags.Diag.Logs {
+		files := logging.LogFiles(logFile)
+		if len(files) == 0 {
+			if err := bundle.add(diagLogName(i, logFile), nil, errors.New("no such log file")); err != nil {
+				return err
+			}
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err := bundle.add(diagLogName(i, file), content, err); err != nil {
+				return err
+			}
+		}
+	}
+	content, err = json.MarshalIndent(bundle.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return bundle.add("manifest.json", content, nil)
+}
+
+// diagLogName is the name of a log file in the bundle, prefixed with the position of its
+// -logs entry as log files of different folders may have the same name
+func diagLogName(i int, path string) string {
+	return "logs/" + strconv.Itoa(i+1) + "-" + filepath.Base(path)
+}
+
+// diagAMTInfo returns the JSON of amtinfo -all, with the user certificates, redirection
+// and CIRA details when the AMT password is given. amtinfo runs on a copy of the service
+// that shares the transport with the event log query.
+func (service *ProvisioningService) diagAMTInfo() ([]byte, error) {
+	infoFlags := *service.flags
+	infoFlags.JsonOutput, infoFlags.YamlOutput = true, false
+	withPassword := infoFlags.Password != ""
+	infoFlags.AmtInfo = flags.AmtInfoFlags{
+		Ver: true, Bld: true, Sku: true, UUID: true, Mode: true, DNS: true, Cert: true,
+		Ras: true, Lan: true, Hostname: true, OpState: true, Hardware: true, BIOS: true,
+		Sys: true, Modes: true,
+		UserCert: withPassword, Redirection: withPassword, RasDetails: withPassword,
+	}
+	var out bytes.Buffer
+	infoService := *service
+	infoService.flags = &infoFlags
+	infoService.out = &out
+	rc := infoService.DisplayAMTInfo()
+	service.client, service.lme, service.selectTransport = infoService.client, infoService.lme, infoService.selectTransport
+	if rc != utils.Success {
+		return out.Bytes(), fmt.Errorf("amtinfo failed with return code %d (%s)", rc, rc)
+	}
+	return out.Bytes(), nil
+}
+
+// diagEventLog returns the JSON of all records of the AMT event log, reading it needs the
+// AMT password
+func (service *ProvisioningService) diagEventLog() ([]byte, error) {
+	if service.flags.Password == "" {
+		return nil, errors.New("not collected, reading the event log needs -password")
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	eventLog, rc := service.GetEventLog(0, 0)
+	if rc != utils.Success {
+		return nil, fmt.Errorf("reading the event log failed with return code %d (%s)", rc, rc)
+	}
+	return json.MarshalIndent(eventLog, "", "  ")
+}
+
+// diagNetwork returns the JSON of the network interfaces of the host and their addresses
+func diagNetwork() ([]byte, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]DiagInterface, 0, len(interfaces))
+	for _, i := range interfaces {
+		entry := DiagInterface{Name: i.Name, HardwareAddr: i.HardwareAddr.String(), MTU: i.MTU, Flags: i.Flags.String(), Addresses: []string{}}
+		addresses, err := i.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range addresses {
+			entry.Addresses = append(entry.Addresses, address.String())
+		}
+		result = append(result, entry)
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"os"
+)
+
+// osNetworkFiles are the network configuration files of the host added to the bundle,
+// replaced in tests
+var osNetworkFiles = []string{"/etc/resolv.conf", "/etc/hosts", "/proc/net/route"}
+
+// osNetworkConfig returns the name of the OS network configuration in the bundle and its
+// content, the files that can not be read are noted in it
+func osNetworkConfig() (string, []byte, error) {
+	var b bytes.Buffer
+	for _, path := range osNetworkFiles {
+		b.WriteString("# " + path + "\n")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			b.WriteString(err.Error() + "\n\n")
+			continue
+		}
+		b.Write(content)
+		b.WriteString("\n")
+	}
+	return "network-linux.txt", b.Bytes(), nil
+}
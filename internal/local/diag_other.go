@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import "errors"
+
+// osNetworkConfig is not implemented on this OS, the interfaces are in network.json
+func osNetworkConfig() (string, []byte, error) {
+	return "network.txt", nil, errors.New("the OS network configuration is not collected on this OS")
+}
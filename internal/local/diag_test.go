@@ -0,0 +1,84 @@
+package local
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiag(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "rpc.log")
+	// a log of an earlier run, its secrets are not registered in this run
+	earlierRun := "level=info msg=\"activate -password P@ssw0rd1\"\n" +
+		"level=info msg=\"configure cira -mpspassword MPSPassw0rd! -token eyJhbGciOi\"\n" +
+		"level=debug msg=\"{\\\"mqttPassword\\\":\\\"MQTTSecret\\\"}\" vaultToken=hvs.VaultSecret\n" +
+		"level=debug msg=\"environment\" env=\"MQTT_PASSWORD=MQTTSecret VAULT_TOKEN=hvs.VaultSecret\"\n"
+	assert.NoError(t, os.WriteFile(logFile, []byte(earlierRun), 0600))
+	assert.NoError(t, os.WriteFile(logFile+".1", []byte("level=info msg=\"older\"\n"), 0600))
+
+	f := &flags.Flags{}
+	f.Command = utils.CommandDiag
+	f.JsonOutput = true
+	f.Diag = flags.DiagFlags{Bundle: true, Dir: dir, Logs: []string{logFile, filepath.Join(dir, "missing.log")}}
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.Diag())
+
+	var result struct {
+		Bundle string      `json:"bundle"`
+		Files  []DiagEntry `json:"files"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, dir, filepath.Dir(result.Bundle))
+	info, err := os.Stat(result.Bundle)
+	assert.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	reader, err := zip.OpenReader(result.Bundle)
+	assert.NoError(t, err)
+	defer reader.Close()
+	contents := map[string]string{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		contents[file.Name] = string(content)
+	}
+	for _, name := range []string{"amtinfo.json", "selftest.json", "network.json", "logs/1-rpc.log", "logs/1-rpc.log.1", "manifest.json"} {
+		assert.Contains(t, contents, name)
+	}
+	// the event log needs the password and a missing log file is noted in the manifest
+	assert.NotContains(t, contents, "eventlog.json")
+	assert.NotContains(t, contents, "logs/2-missing.log")
+	errs := map[string]string{}
+	for _, entry := range result.Files {
+		errs[entry.Name] = entry.Error
+	}
+	assert.Contains(t, errs["eventlog.json"], "-password")
+	assert.Equal(t, "no such log file", errs["logs/2-missing.log"])
+	for _, secret := range []string{"P@ssw0rd1", "MPSPassw0rd!", "eyJhbGciOi", "MQTTSecret", "hvs.VaultSecret"} {
+		assert.NotContains(t, contents["logs/1-rpc.log"], secret)
+	}
+	assert.Contains(t, contents["manifest.json"], utils.ProjectVersion)
+}
+
+func TestDiagBundleNotWritable(t *testing.T) {
+	f := &flags.Flags{}
+	f.Diag = flags.DiagFlags{Bundle: true, Dir: filepath.Join(t.TempDir(), "missing")}
+	lps := setupService(f)
+	assert.Equal(t, utils.DiagBundleFailed, lps.Diag())
+}
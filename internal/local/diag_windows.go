@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import "os/exec"
+
+// osNetworkConfig returns the name of the OS network configuration in the bundle and the
+// output of ipconfig /all, which also lists the DNS servers and suffixes
+func osNetworkConfig() (string, []byte, error) {
+	content, err := exec.Command("ipconfig", "/all").Output()
+	return "network-windows.txt", content, err
+}
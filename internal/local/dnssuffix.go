@@ -0,0 +1,47 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"rpc/internal/i18n"
+	"rpc/pkg/pthi"
+	"rpc/pkg/utils"
+)
+
+// ConfigureDNSSuffix sets the PKI DNS suffix of -value over the MEI. AMT matches it against
+// the domain of the provisioning certificate and only accepts it before activation.
+func (service *ProvisioningService) ConfigureDNSSuffix() utils.ReturnCode {
+	suffix := service.flags.DNSSuffix.Value
+	current, err := service.amtCommand.GetDNSSuffix()
+	if err != nil {
+		log.Error(err)
+		return utils.AMTConnectionFailed
+	}
+	if current != suffix {
+		status, err := service.amtCommand.SetDNSSuffix(suffix)
+		if err != nil {
+			log.Error(err)
+			return utils.AMTConnectionFailed
+		}
+		switch status {
+		case pthi.AMT_STATUS_SUCCESS:
+		case pthi.AMT_STATUS_INVALID_PT_MODE, pthi.AMT_STATUS_NOT_PERMITTED:
+			log.Error("AMT is activated, the PKI DNS suffix can only be set before activation")
+			return utils.DNSSuffixConfigurationFailed
+		default:
+			log.Errorf("AMT did not set the PKI DNS suffix, status %d", status)
+			return utils.DNSSuffixConfigurationFailed
+		}
+		log.Infof("changed the PKI DNS suffix from '%s' to '%s'", current, suffix)
+	} else {
+		log.Info("the PKI DNS suffix is already ", suffix)
+	}
+	w := service.newOutputWriter()
+	w.Field("dnsSuffix", i18n.Label("info.dnsSuffix"), suffix)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
@@ -0,0 +1,54 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"errors"
+	"rpc/internal/flags"
+	"rpc/pkg/pthi"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureDNSSuffix(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandConfigure
+	f.SubCommand = utils.SubCommandDNSSuffix
+	f.DNSSuffix.Value = "corp.example.com"
+	defer func() {
+		mockSetDNSSuffixStatus = 0
+		mockSetDNSSuffixErr = nil
+		mockSetDNSSuffixValue = ""
+		mockDNSSuffixErr = nil
+	}()
+
+	t.Run("sets the suffix", func(t *testing.T) {
+		lps := setupService(f)
+		assert.Equal(t, utils.Success, lps.Configure())
+		assert.Equal(t, "corp.example.com", mockSetDNSSuffixValue)
+	})
+	t.Run("leaves a matching suffix alone", func(t *testing.T) {
+		mockSetDNSSuffixValue = ""
+		f.DNSSuffix.Value = mockDNSSuffix
+		defer func() { f.DNSSuffix.Value = "corp.example.com" }()
+		lps := setupService(f)
+		assert.Equal(t, utils.Success, lps.ConfigureDNSSuffix())
+		assert.Empty(t, mockSetDNSSuffixValue)
+	})
+	t.Run("returns DNSSuffixConfigurationFailed when AMT is activated", func(t *testing.T) {
+		mockSetDNSSuffixStatus = pthi.AMT_STATUS_INVALID_PT_MODE
+		defer func() { mockSetDNSSuffixStatus = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.DNSSuffixConfigurationFailed, lps.ConfigureDNSSuffix())
+	})
+	t.Run("returns AMTConnectionFailed when the MEI fails", func(t *testing.T) {
+		mockSetDNSSuffixErr = errors.New("test error")
+		defer func() { mockSetDNSSuffixErr = nil }()
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTConnectionFailed, lps.ConfigureDNSSuffix())
+	})
+}
@@ -0,0 +1,405 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"fmt"
+	"os"
+	"rpc/internal/flags"
+	"rpc/internal/secretstore"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+)
+
+// DryRun runs the checks of the command against AMT and prints the changes
+// it would make. Only read requests are sent to AMT.
+func (service *ProvisioningService) DryRun() utils.ReturnCode {
+	var actions []string
+	var rc utils.ReturnCode
+	switch service.flags.Command {
+	case utils.CommandActivate:
+		actions, rc = service.dryRunActivate()
+	case utils.CommandDeactivate:
+		actions, rc = service.dryRunDeactivate()
+	case utils.CommandConfigure, utils.CommandMaintenance:
+		if service.flags.SubCommand == utils.SubCommandDNSSuffix {
+			// the PKI DNS suffix is set over the MEI, there is no AMT login to check
+			actions, rc = service.dryRunDNSSuffix()
+			break
+		}
+		if service.flags.SubCommand == utils.SubCommandAMTFeatures {
+			actions, rc = service.dryRunAMTFeatures()
+			break
+		}
+		actions, rc = service.dryRunSettings()
+	case utils.CommandPower:
+		actions, rc = service.dryRunPower()
+	case utils.CommandBoot:
+		actions, rc = service.dryRunBoot()
+	case utils.CommandApply:
+		actions, rc = service.dryRunApply()
+	default:
+		return utils.IncorrectCommandLineParameters
+	}
+	if rc != utils.Success {
+		return rc
+	}
+	out := service.newOutputWriter()
+	out.Println("Dry run, nothing was changed. These changes would be made to AMT:")
+	for _, action := range actions {
+		out.Printf("  - %s\n", action)
+	}
+	out.Field("dryRun", "", true)
+	out.Field("actions", "", actions)
+	if err := out.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.DryRunCompleted
+}
+
+// localOnly fails what reads the MEI of this host when the command targets a remote
+// device with -host, as the MEI would describe this host instead of the device
+func (service *ProvisioningService) localOnly(what string) utils.ReturnCode {
+	if service.flags.IsRemote() {
+		log.Errorf("%s reads the MEI of this host and can not run with -host %s", what, service.flags.Remote.Host)
+		return utils.InvalidParameterCombination
+	}
+	return utils.Success
+}
+
+func (service *ProvisioningService) dryRunActivate() ([]string, utils.ReturnCode) {
+	if rc := service.localOnly("the dry run of activate"); rc != utils.Success {
+		return nil, rc
+	}
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	path, err := service.flags.ActivationPath(controlMode)
+	if err != nil {
+		log.Error(err)
+		return nil, rpcerr.ReturnCodeOf(err)
+	}
+	var actions []string
+	switch path {
+	case flags.ActivationPathNone:
+		return []string{}, utils.Success
+	case flags.ActivationPathUpgrade:
+		report, err := service.CheckProvisioningCert()
+		if err != nil {
+			log.Error(err)
+			return nil, utils.ActivationFailed
+		}
+		actions = []string{"upgrade client control mode to admin control mode with the provisioning certificate rooted in " + report.Fingerprint}
+		if service.flags.MEBxPassword != "" {
+			actions = append(actions, "set the MEBx password")
+		}
+		return actions, utils.Success
+	case flags.ActivationPathReprovision:
+		if controlMode == 1 {
+			actions = append(actions, "unprovision client control mode through the MEI driver")
+		} else {
+			actions = append(actions, "unprovision admin control mode with the admin password")
+		}
+	}
+	if _, err = service.amtCommand.GetLocalSystemAccount(); err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	if !service.flags.UseACM {
+		return append(actions, "activate in client control mode"), utils.Success
+	}
+	report, err := service.CheckProvisioningCert()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.ActivationFailed
+	}
+	actions = append(actions, "activate in admin control mode with the provisioning certificate rooted in "+report.Fingerprint)
+	if service.flags.MEBxPassword != "" {
+		actions = append(actions, "set the MEBx password")
+	}
+	return actions, utils.Success
+}
+
+func (service *ProvisioningService) dryRunDeactivate() ([]string, utils.ReturnCode) {
+	if rc := service.localOnly("the dry run of deactivate"); rc != utils.Success {
+		return nil, rc
+	}
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	if controlMode == 0 {
+		if state, err := service.amtCommand.GetOperationalState(); err == nil && state.ProvisioningState == provisioningStateIn {
+			return []string{"complete the interrupted unprovision through the MEI driver"}, utils.Success
+		}
+		log.Error("Deactivation failed. Device control mode: " + utils.InterpretControlMode(controlMode))
+		return nil, utils.UnableToDeactivate
+	}
+	if controlMode == 1 && !service.flags.PartialDeactivate && !service.flags.WipeStorage {
+		return []string{"unprovision client control mode through the MEI driver"}, utils.Success
+	}
+	if service.flags.Password == "" {
+		if _, rc := service.flags.ReadPasswordFromUser(); rc != utils.Success {
+			return nil, rc
+		}
+	}
+	if _, rc := service.dryRunLogin(); rc != utils.Success {
+		return nil, rc
+	}
+	if service.flags.PartialDeactivate {
+		return []string{
+			"remove the CIRA configuration",
+			"disable TLS",
+			"remove the wifi profiles",
+		}, utils.Success
+	}
+	if service.flags.WipeStorage {
+		unprovision := "unprovision admin control mode with the admin password"
+		if controlMode == 1 {
+			unprovision = "unprovision client control mode through the MEI driver"
+		}
+		return []string{
+			"remove the CIRA configuration",
+			"disable TLS",
+			"remove the wifi profiles",
+			"delete the certificates and key pairs",
+			"clear the audit log",
+			unprovision,
+		}, utils.Success
+	}
+	return []string{"unprovision admin control mode with the admin password"}, utils.Success
+}
+
+// dryRunSettings checks the admin password and the inputs of the configure and maintenance subcommands
+func (service *ProvisioningService) dryRunSettings() ([]string, utils.ReturnCode) {
+	generalSettings, rc := service.dryRunLogin()
+	if rc != utils.Success {
+		return nil, rc
+	}
+	var actions []string
+	switch service.flags.SubCommand {
+	case utils.SubCommandSyncClock:
+		source, offset, err := service.clockReference()
+		if err != nil {
+			log.Errorf("unable to query ntp server %s: %s", service.flags.NTPServer, err)
+			return nil, utils.SyncClockFailed
+		}
+		if service.flags.NTPServer != "" {
+			source = "ntp server " + source
+		}
+		action := fmt.Sprintf("set the AMT clock to %s from %s", time.Now().Add(offset).UTC().Format(time.RFC3339), source)
+		if service.flags.SyncClock.MaxSkew > 0 {
+			action += fmt.Sprintf(" unless it is within %s", service.flags.SyncClock.MaxSkew)
+		}
+		actions = append(actions, action)
+	case utils.SubCommandSyncDNS:
+		if domain := generalSettings.Body.AMTGeneralSettings.DomainName; domain != service.flags.DNS {
+			actions = append(actions, fmt.Sprintf("change the AMT DNS suffix from '%s' to '%s'", domain, service.flags.DNS))
+		}
+		if service.flags.IpConfiguration.PrimaryDns != "" {
+			actions = append(actions, fmt.Sprintf("set the AMT DNS servers to %s %s", service.flags.IpConfiguration.PrimaryDns, service.flags.IpConfiguration.SecondaryDns))
+		}
+	case utils.SubCommandChangePassword:
+		opts := service.flags.ChangePassword
+		actions = append(actions, fmt.Sprintf("set a generated %d character admin password", opts.Length))
+		if opts.OutFile != "" {
+			if _, err := os.Stat(opts.OutFile); err == nil {
+				log.Errorf("%s already exists", opts.OutFile)
+				return nil, utils.ChangePasswordFailed
+			}
+			actions = append(actions, "save the password to "+opts.OutFile)
+		} else if opts.Keyring {
+			actions = append(actions, "save the password to the OS keyring")
+		} else if opts.Vault != "" {
+			actions = append(actions, "save the password to "+secretstore.Redact(opts.Vault))
+		}
+	case utils.SubCommandAddWifiSettings, utils.SubCommandSyncWifi:
+		actions = append(actions, "remove the wifi profiles in AMT")
+		for _, wifiConfig := range service.config.WifiConfigs {
+			action := fmt.Sprintf("add wifi profile %s for ssid %s with priority %d", wifiConfig.ProfileName, wifiConfig.SSID, wifiConfig.Priority)
+			if wifiConfig.Ieee8021xProfileName != "" {
+				action += " using 802.1x profile " + wifiConfig.Ieee8021xProfileName
+			}
+			actions = append(actions, action)
+		}
+	case utils.SubCommandEnableWifiPort:
+		wifiPort := service.flags.WifiPort
+		if wifiPort.Disable {
+			actions = append(actions, "disable local profile synchronization and the wifi port")
+			break
+		}
+		actions = append(actions, "enable the wifi port and local profile synchronization")
+		switch wifiPort.LinkPreference {
+		case flags.LinkPreferenceME:
+			actions = append(actions, fmt.Sprintf("give the wifi link to AMT for %d seconds", wifiPort.LinkPreferenceTimeout))
+		case flags.LinkPreferenceHost:
+			actions = append(actions, "give the wifi link to the host")
+		}
+	case utils.SubCommandConfigureTLS:
+		tls := service.flags.TLSSettings
+		if tls.Cert == "" {
+			destination := "stdout"
+			if tls.CSRFile != "" {
+				destination = tls.CSRFile
+			}
+			actions = append(actions, "generate a RSA 2048 bit key pair in AMT and write its CSR to "+destination)
+			break
+		}
+		if tls.CACert != "" {
+			actions = append(actions, "add the trusted root certificate")
+		}
+		actions = append(actions, "add the TLS certificate", fmt.Sprintf("enable TLS in %s mode", tls.Mode))
+	case utils.SubCommandConfigureCIRA:
+		cira := service.flags.CIRASettings
+		actions = append(actions,
+			"remove the existing CIRA configuration unless it matches",
+			"add the MPS root certificate",
+			fmt.Sprintf("add MPS %s:%d with user %s", cira.MPSAddress, cira.MPSPort, cira.MPSUser),
+		)
+		if cira.SecondaryAddress != "" {
+			actions = append(actions, fmt.Sprintf("add secondary MPS %s:%d with user %s", cira.SecondaryAddress, cira.SecondaryPort, cira.MPSUser))
+		}
+		actions = append(actions, "add the User Initiated and Alert remote access policies")
+		if cira.PeriodicInterval > 0 {
+			actions = append(actions, fmt.Sprintf("add a Periodic remote access policy every %d seconds", cira.PeriodicInterval))
+		}
+		if len(cira.EnvironmentDetection) > 0 {
+			actions = append(actions, "set environment detection domains "+strings.Join(cira.EnvironmentDetection, ", "))
+		} else {
+			actions = append(actions, "set a random environment detection domain")
+		}
+		actions = append(actions, "enable user initiated connections")
+	case utils.SubCommandWired8021x:
+		if service.flags.Wired8021x.Disable {
+			actions = append(actions, "disable 802.1x on the wired interface")
+			break
+		}
+		for _, cfg := range service.config.Ieee8021xConfigs {
+			if cfg.ProfileName != service.flags.Wired8021x.ProfileName {
+				continue
+			}
+			if cfg.PrivateKey != "" {
+				actions = append(actions, "add the private key")
+			}
+			if cfg.ClientCert != "" {
+				actions = append(actions, "add the client certificate")
+			}
+			actions = append(actions,
+				"add the CA certificate of the RADIUS server",
+				fmt.Sprintf("enable 802.1x on the wired interface for user %s with PXE timeout %d seconds", cfg.Username, service.flags.Wired8021x.PxeTimeout),
+			)
+		}
+	case utils.SubCommandAlarmClock:
+		alarm := service.flags.AlarmClock
+		switch {
+		case alarm.Add != "":
+			action := fmt.Sprintf("add alarm %s waking the device at %s", alarm.Add, alarm.Start.Format(time.RFC3339))
+			if alarm.Interval > 0 {
+				action += " and every " + alarm.Interval.String()
+			}
+			actions = append(actions, action)
+		case alarm.Delete != "":
+			actions = append(actions, "delete alarm "+alarm.Delete)
+		}
+	case utils.SubCommandRedirection:
+		redirection := service.flags.Redirection
+		if len(redirection.Enable) > 0 {
+			actions = append(actions, "enable "+strings.Join(redirection.Enable, ", ")+" redirection")
+		}
+		if len(redirection.Disable) > 0 {
+			actions = append(actions, "disable "+strings.Join(redirection.Disable, ", ")+" redirection")
+		}
+		actions = append(actions, "enable the redirection listener while a redirection feature is enabled")
+	case utils.SubCommandCertHash:
+		certHash := service.flags.CertHash
+		switch {
+		case certHash.Add != "":
+			_, algorithm := utils.InterpretHashAlgorithm(certHash.HashType)
+			actions = append(actions, fmt.Sprintf("add the trusted root %s hash %s as %s", algorithm, certHash.Add, certHash.Alias))
+		case certHash.Delete != "":
+			actions = append(actions, "delete the trusted root hash "+certHash.Delete)
+		}
+	default:
+		return nil, utils.IncorrectCommandLineParameters
+	}
+	return actions, utils.Success
+}
+
+func (service *ProvisioningService) dryRunDNSSuffix() ([]string, utils.ReturnCode) {
+	if rc := service.localOnly("the dry run of configure dnssuffix"); rc != utils.Success {
+		return nil, rc
+	}
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	if controlMode != 0 {
+		log.Error("AMT is activated, the PKI DNS suffix can only be set before activation")
+		return nil, utils.DNSSuffixConfigurationFailed
+	}
+	suffix, err := service.amtCommand.GetDNSSuffix()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	if suffix == service.flags.DNSSuffix.Value {
+		return nil, utils.Success
+	}
+	return []string{fmt.Sprintf("change the PKI DNS suffix from '%s' to '%s'", suffix, service.flags.DNSSuffix.Value)}, utils.Success
+}
+
+func (service *ProvisioningService) dryRunAMTFeatures() ([]string, utils.ReturnCode) {
+	if rc := service.localOnly("the dry run of configure amtfeatures"); rc != utils.Success {
+		return nil, rc
+	}
+	state, err := service.amtCommand.GetChangeEnabled()
+	if err != nil {
+		log.Error(err)
+		return nil, utils.AMTConnectionFailed
+	}
+	requested := service.flags.AMTFeatures.AMT
+	if requested == "" || state.AMTEnabled == (requested == flags.AMTStateEnable) {
+		return nil, utils.Success
+	}
+	if rc := checkAMTStateChange(state); rc != utils.Success {
+		return nil, rc
+	}
+	return []string{requested + " AMT"}, utils.Success
+}
+
+func (service *ProvisioningService) dryRunPower() ([]string, utils.ReturnCode) {
+	if _, ok := powerStates[service.flags.SubCommand]; !ok {
+		return nil, utils.IncorrectCommandLineParameters
+	}
+	if _, rc := service.dryRunLogin(); rc != utils.Success {
+		return nil, rc
+	}
+	var actions []string
+	if service.flags.Power.BootToBIOS {
+		actions = append(actions, "boot into the BIOS setup on the next boot")
+	} else if service.flags.Power.BootToPXE {
+		actions = append(actions, "boot from PXE on the next boot")
+	}
+	return append(actions, "power "+service.flags.SubCommand+" the device"), utils.Success
+}
+
+// dryRunLogin checks that AMT accepts the admin password the command would use
+func (service *ProvisioningService) dryRunLogin() (general.Response, utils.ReturnCode) {
+	service.setupWsmanClient("admin", service.flags.Password)
+	generalSettings, err := service.GetGeneralSettings()
+	if err != nil {
+		log.Error("unable to connect to AMT with the admin password: ", err)
+		return general.Response{}, utils.AMTConnectionFailed
+	}
+	return generalSettings, utils.Success
+}
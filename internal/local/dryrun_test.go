@@ -0,0 +1,234 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunActivate(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandActivate
+	f.DryRun = true
+	origControlMode := mockControlMode
+	mockControlMode = 0
+	defer func() { mockControlMode = origControlMode }()
+
+	t.Run("returns DryRunCompleted for CCM without changing AMT", func(t *testing.T) {
+		f.UseCCM = true
+		defer func() { f.UseCCM = false }()
+		var out bytes.Buffer
+		lps := setupService(f)
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "activate in client control mode")
+	})
+	t.Run("returns UnableToActivate when already activated", func(t *testing.T) {
+		mockControlMode = 1
+		defer func() { mockControlMode = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.UnableToActivate, lps.DryRun())
+	})
+	t.Run("lists the unprovision before the activation with reprovision", func(t *testing.T) {
+		mockControlMode = 2
+		f.Local, f.UseCCM, f.Activate.Reprovision = true, true, true
+		defer func() {
+			mockControlMode = 0
+			f.Local, f.UseCCM, f.Activate.Reprovision = false, false, false
+		}()
+		var out bytes.Buffer
+		lps := setupService(f)
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "  - unprovision admin control mode with the admin password\n  - activate in client control mode")
+	})
+	t.Run("returns ActivationFailed when the provisioning cert can not be read", func(t *testing.T) {
+		f.UseACM = true
+		defer func() { f.UseACM = false }()
+		lps := setupService(f)
+		assert.Equal(t, utils.ActivationFailed, lps.DryRun())
+	})
+	t.Run("returns InvalidParameterCombination for a remote device", func(t *testing.T) {
+		f.UseCCM, f.Remote.Host = true, "amt.example.com"
+		defer func() { f.UseCCM, f.Remote.Host = false, "" }()
+		lps := setupService(f)
+		assert.Equal(t, utils.InvalidParameterCombination, lps.DryRun())
+	})
+}
+
+func TestDryRunDeactivate(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandDeactivate
+	f.DryRun = true
+	f.Password = "P@ssw0rd"
+	origControlMode := mockControlMode
+	defer func() { mockControlMode = origControlMode }()
+
+	t.Run("returns DryRunCompleted for CCM", func(t *testing.T) {
+		mockControlMode = 1
+		defer func() { mockControlMode = 0 }()
+		lps := setupService(f)
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+	})
+	t.Run("returns DryRunCompleted for ACM when AMT accepts the password", func(t *testing.T) {
+		mockControlMode = 2
+		defer func() { mockControlMode = 0 }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+	})
+	t.Run("returns AMTConnectionFailed for ACM when AMT rejects the password", func(t *testing.T) {
+		mockControlMode = 2
+		defer func() { mockControlMode = 0 }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.AMTConnectionFailed, lps.DryRun())
+	})
+	t.Run("checks the password for CCM with -wipe", func(t *testing.T) {
+		mockControlMode = 1
+		f.WipeStorage = true
+		defer func() { mockControlMode, f.WipeStorage = 0, false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.AMTConnectionFailed, lps.DryRun())
+	})
+	t.Run("returns UnableToDeactivate when not activated", func(t *testing.T) {
+		lps := setupService(f)
+		assert.Equal(t, utils.UnableToDeactivate, lps.DryRun())
+	})
+}
+
+func TestDryRunDNSSuffix(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandConfigure
+	f.SubCommand = utils.SubCommandDNSSuffix
+	f.DryRun = true
+	f.DNSSuffix.Value = "corp.example.com"
+	origControlMode := mockControlMode
+	mockControlMode = 0
+	defer func() { mockControlMode = origControlMode }()
+
+	t.Run("lists the suffix change without a login", func(t *testing.T) {
+		var out bytes.Buffer
+		lps := setupService(f)
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "change the PKI DNS suffix from 'dns.org' to 'corp.example.com'")
+	})
+	t.Run("returns DNSSuffixConfigurationFailed when AMT is activated", func(t *testing.T) {
+		mockControlMode = 1
+		defer func() { mockControlMode = 0 }()
+		lps := setupService(f)
+		assert.Equal(t, utils.DNSSuffixConfigurationFailed, lps.DryRun())
+	})
+	t.Run("returns InvalidParameterCombination for a remote device", func(t *testing.T) {
+		f.Remote.Host = "amt.example.com"
+		defer func() { f.Remote.Host = "" }()
+		lps := setupService(f)
+		assert.Equal(t, utils.InvalidParameterCombination, lps.DryRun())
+	})
+}
+
+func TestDryRunAMTFeatures(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandConfigure
+	f.SubCommand = utils.SubCommandAMTFeatures
+	f.DryRun = true
+	f.AMTFeatures.AMT = flags.AMTStateDisable
+	origChangeEnabled := mockChangeEnabled
+	defer func() { mockChangeEnabled = origChangeEnabled }()
+
+	t.Run("lists the state change", func(t *testing.T) {
+		var out bytes.Buffer
+		lps := setupService(f)
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "disable AMT")
+		assert.True(t, mockChangeEnabled.AMTEnabled, "the dry run does not change the state")
+	})
+	t.Run("returns AMTStateChangeNotAllowed when the BIOS locks the state", func(t *testing.T) {
+		mockChangeEnabled.TransitionAllowed = false
+		defer func() { mockChangeEnabled = origChangeEnabled }()
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTStateChangeNotAllowed, lps.DryRun())
+	})
+}
+
+func TestDryRunSettings(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandMaintenance
+	f.DryRun = true
+	f.Password = "P@ssw0rd"
+	f.JsonOutput = true
+	defer func() { f.JsonOutput = false }()
+
+	t.Run("lists the DNS changes of syncdns", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandSyncDNS
+		f.DNS = "corp.example.com"
+		generalRsp := general.Response{}
+		generalRsp.Body.AMTGeneralSettings.DomainName = "old.example.com"
+		var out bytes.Buffer
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, generalRsp)})
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		var result struct {
+			DryRun  bool     `json:"dryRun"`
+			Actions []string `json:"actions"`
+		}
+		assert.Nil(t, json.Unmarshal(out.Bytes(), &result))
+		assert.True(t, result.DryRun)
+		assert.Equal(t, []string{"change the AMT DNS suffix from 'old.example.com' to 'corp.example.com'"}, result.Actions)
+	})
+	t.Run("lists the trusted root hash configure certhash adds", func(t *testing.T) {
+		f.Command, f.SubCommand = utils.CommandConfigure, utils.SubCommandCertHash
+		f.CertHash = flags.CertHashFlags{Add: "abab", HashType: flags.CertHashTypeSHA256, Alias: "CorpRoot"}
+		defer func() { f.Command, f.CertHash = utils.CommandMaintenance, flags.CertHashFlags{} }()
+		var out bytes.Buffer
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "add the trusted root SHA256 hash abab as CorpRoot")
+	})
+	t.Run("plans a clock sync from the host clock", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandSyncClock
+		f.SyncClock.MaxSkew = 2 * time.Second
+		defer func() { f.SyncClock.MaxSkew = 0 }()
+		var out bytes.Buffer
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "from host unless it is within 2s")
+	})
+	t.Run("returns SyncClockFailed when the ntp server does not answer", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandSyncClock
+		f.NTPServer = "pool.ntp.org"
+		defer func() { f.NTPServer = "" }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		lps.ntpQuery = func(server string, timeout time.Duration) (time.Time, error) {
+			return time.Time{}, errors.New("timeout")
+		}
+		assert.Equal(t, utils.SyncClockFailed, lps.DryRun())
+	})
+	t.Run("returns ChangePasswordFailed when the password file exists", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandChangePassword
+		f.ChangePassword.Length = 16
+		f.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		defer func() { f.ChangePassword.OutFile = "" }()
+		assert.Nil(t, os.WriteFile(f.ChangePassword.OutFile, []byte("x"), 0600))
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		assert.Equal(t, utils.ChangePasswordFailed, lps.DryRun())
+	})
+	t.Run("returns AMTConnectionFailed when AMT can not be reached", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandEnableWifiPort
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.AMTConnectionFailed, lps.DryRun())
+	})
+}
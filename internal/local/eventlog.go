@@ -0,0 +1,305 @@
+package local
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"rpc/pkg/utils"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/messagelog"
+)
+
+type GetRecordsResponse struct {
+	Body struct {
+		Output struct {
+			IterationIdentifier int      `xml:"IterationIdentifier"`
+			NoMoreRecords       bool     `xml:"NoMoreRecords"`
+			RecordArray         []string `xml:"RecordArray"`
+			ReturnValue         int      `xml:"ReturnValue"`
+		} `xml:"GetRecords_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type ClearLogResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"ClearLog_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// EventLog holds the event log records selected with -count and -offset
+type EventLog struct {
+	TotalRecords int              `json:"totalRecords"`
+	Records      []EventLogRecord `json:"records"`
+	Cleared      bool             `json:"cleared,omitempty"`
+}
+
+// EventLogRecord is a platform event trap record of the AMT event log
+type EventLogRecord struct {
+	Time            time.Time `json:"time"`
+	DeviceAddress   int       `json:"deviceAddress"`
+	SensorTypeID    int       `json:"sensorTypeId"`
+	SensorType      string    `json:"sensorType"`
+	EventType       int       `json:"eventType"`
+	EventOffset     int       `json:"eventOffset"`
+	EventSourceType int       `json:"eventSourceType"`
+	Severity        string    `json:"severity"`
+	SensorNumber    int       `json:"sensorNumber"`
+	EntityID        int       `json:"entityId"`
+	Entity          string    `json:"entity"`
+	EntityInstance  int       `json:"entityInstance"`
+	EventData       string    `json:"eventData"`
+	Description     string    `json:"description"`
+}
+
+// eventRecordLength is the size of the records returned by AMT_MessageLog.GetRecords
+const eventRecordLength = 21
+
+const (
+	sensorTypeFirmwareProgress = 0x0F
+	firmwareProgressError      = 0x00
+	firmwareProgressProgress   = 0x02
+)
+
+var errShortEventRecord = errors.New("event log record is truncated")
+
+// sensorTypeNames are the IPMI sensor types
+var sensorTypeNames = map[int]string{
+	0x01: "Temperature",
+	0x02: "Voltage",
+	0x03: "Current",
+	0x04: "Fan",
+	0x05: "Physical Security",
+	0x06: "Platform Security",
+	0x07: "Processor",
+	0x08: "Power Supply",
+	0x09: "Power Unit",
+	0x0A: "Cooling Device",
+	0x0B: "Other Units",
+	0x0C: "Memory",
+	0x0D: "Drive Slot",
+	0x0E: "POST Memory Resize",
+	0x0F: "System Firmware Progress",
+	0x10: "Event Logging Disabled",
+	0x11: "Watchdog",
+	0x12: "System Event",
+	0x13: "Critical Interrupt",
+	0x14: "Button/Switch",
+	0x15: "Module/Board",
+	0x16: "Microcontroller",
+	0x17: "Add-in Card",
+	0x18: "Chassis",
+	0x19: "Chip Set",
+	0x1A: "Other FRU",
+	0x1B: "Cable/Interconnect",
+	0x1C: "Terminator",
+	0x1D: "System Boot Initiated",
+	0x1E: "Boot Error",
+	0x1F: "OS Boot",
+	0x20: "OS Critical Stop",
+	0x21: "Slot/Connector",
+	0x22: "System ACPI Power State",
+	0x23: "Watchdog",
+	0x24: "Platform Alert",
+	0x25: "Entity Presence",
+	0x26: "Monitor ASIC",
+	0x27: "LAN",
+	0x28: "Management Subsystem Health",
+	0x29: "Battery",
+	0x2A: "Session Audit",
+	0x2B: "Version Change",
+	0x2C: "FRU State",
+}
+
+var eventSeverityNames = map[int]string{
+	0x00: "Unspecified",
+	0x01: "Monitor",
+	0x02: "Information",
+	0x04: "OK",
+	0x08: "Non-critical",
+	0x10: "Critical",
+	0x20: "Non-recoverable",
+}
+
+// entityNames are the IPMI entity IDs AMT reports
+var entityNames = map[int]string{
+	0:  "Unspecified",
+	1:  "Other",
+	2:  "Unknown",
+	3:  "Processor",
+	4:  "Disk",
+	5:  "Peripheral",
+	6:  "System management module",
+	7:  "System board",
+	8:  "Memory module",
+	9:  "Processor module",
+	10: "Power supply",
+	11: "Add-in card",
+	23: "System chassis",
+	29: "Fan",
+	30: "Cooling unit",
+	32: "Memory device",
+	34: "BIOS",
+	35: "Operating system",
+}
+
+// firmwareErrors and firmwareProgress decode the second event data byte of System Firmware Progress events
+var firmwareErrors = map[byte]string{
+	0x00: "Unspecified",
+	0x01: "No system memory is physically installed",
+	0x02: "No usable system memory",
+	0x03: "Unrecoverable hard-disk failure",
+	0x04: "Unrecoverable system-board failure",
+	0x05: "Unrecoverable diskette failure",
+	0x06: "Unrecoverable hard-disk controller failure",
+	0x07: "Unrecoverable keyboard failure",
+	0x08: "Removable boot media not found",
+	0x09: "Unrecoverable video controller failure",
+	0x0A: "No video device detected",
+	0x0B: "Firmware ROM corruption detected",
+	0x0C: "CPU voltage mismatch",
+	0x0D: "CPU speed matching failure",
+}
+
+var firmwareProgress = map[byte]string{
+	0x00: "Unspecified",
+	0x01: "Memory initialization",
+	0x02: "Hard-disk initialization",
+	0x03: "Secondary processor initialization",
+	0x04: "User authentication",
+	0x05: "User-initiated system setup",
+	0x06: "USB resource configuration",
+	0x07: "PCI resource configuration",
+	0x08: "Option ROM initialization",
+	0x09: "Video initialization",
+	0x0A: "Cache initialization",
+	0x0B: "SMBus initialization",
+	0x0C: "Keyboard controller initialization",
+	0x0D: "Management controller initialization",
+	0x0E: "Docking station attachment",
+	0x0F: "Enabling docking station",
+	0x10: "Docking station ejection",
+	0x11: "Disabling docking station",
+	0x12: "Calling operating system wake-up vector",
+	0x13: "Starting operating system boot process",
+	0x14: "Baseboard initialization",
+	0x16: "Floppy initialization",
+	0x17: "Keyboard test",
+	0x18: "Pointing device test",
+	0x19: "Primary processor initialization",
+}
+
+// GetEventLog reads the AMT event log and returns count records after skipping offset records,
+// a count of 0 returns all records. AMT does not report the number of records, so all are read.
+func (service *ProvisioningService) GetEventLog(offset int, count int) (EventLog, utils.ReturnCode) {
+	eventLog := EventLog{Records: []EventLogRecord{}}
+	identifier := 1
+	for {
+		var rsp GetRecordsResponse
+		rc := service.PostAndUnmarshal(service.amtMessages.MessageLog.GetRecords(identifier), &rsp)
+		if rc != utils.Success {
+			return eventLog, rc
+		}
+		if rsp.Body.Output.ReturnValue != 0 {
+			log.Errorf("GetRecords_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+			return eventLog, utils.AmtPtStatusCodeBase + utils.ReturnCode(rsp.Body.Output.ReturnValue)
+		}
+		for _, eventRecord := range rsp.Body.Output.RecordArray {
+			record, err := decodeEventLogRecord(eventRecord)
+			if err != nil {
+				log.Error("unable to decode event log record: ", err)
+				return eventLog, utils.UnmarshalMessageFailed
+			}
+			eventLog.Records = append(eventLog.Records, record)
+		}
+		if rsp.Body.Output.NoMoreRecords || len(rsp.Body.Output.RecordArray) == 0 {
+			break
+		}
+		identifier = rsp.Body.Output.IterationIdentifier
+	}
+	eventLog.TotalRecords = len(eventLog.Records)
+	if offset >= len(eventLog.Records) {
+		eventLog.Records = []EventLogRecord{}
+	} else {
+		eventLog.Records = eventLog.Records[offset:]
+	}
+	if count > 0 && len(eventLog.Records) > count {
+		eventLog.Records = eventLog.Records[:count]
+	}
+	return eventLog, utils.Success
+}
+
+// ClearEventLog removes all records of the AMT event log
+func (service *ProvisioningService) ClearEventLog() utils.ReturnCode {
+	xmlMsg, err := invokeMessage(messagelog.AMT_MessageLog, "ClearLog")
+	if err != nil {
+		log.Error("unable to create the ClearLog message: ", err)
+		return utils.WSMANMessageError
+	}
+	var rsp ClearLogResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return rc
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("ClearLog_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.AmtPtStatusCodeBase + utils.ReturnCode(rsp.Body.Output.ReturnValue)
+	}
+	return utils.Success
+}
+
+// decodeEventLogRecord decodes the base64 encoded platform event trap record returned by AMT_MessageLog.GetRecords
+func decodeEventLogRecord(eventRecord string) (EventLogRecord, error) {
+	record := EventLogRecord{}
+	data, err := base64.StdEncoding.DecodeString(eventRecord)
+	if err != nil {
+		return record, err
+	}
+	if len(data) < eventRecordLength {
+		return record, errShortEventRecord
+	}
+	record.Time = time.Unix(int64(binary.LittleEndian.Uint32(data[0:4])), 0).UTC()
+	record.DeviceAddress = int(data[4])
+	record.SensorTypeID = int(data[5])
+	record.EventType = int(data[6])
+	record.EventOffset = int(data[7])
+	record.EventSourceType = int(data[8])
+	record.SensorNumber = int(data[10])
+	record.EntityID = int(data[11])
+	record.EntityInstance = int(data[12])
+	record.EventData = hex.EncodeToString(data[13:21])
+
+	record.SensorType = lookupName(sensorTypeNames, record.SensorTypeID, "SensorType")
+	record.Severity = lookupName(eventSeverityNames, int(data[9]), "Severity")
+	record.Entity = lookupName(entityNames, record.EntityID, "Entity")
+	record.Description = describeEvent(record.SensorTypeID, record.EventOffset, data[13:21])
+	return record, nil
+}
+
+// describeEvent returns a readable description of an event, the firmware progress codes are decoded
+func describeEvent(sensorType int, eventOffset int, eventData []byte) string {
+	if sensorType == sensorTypeFirmwareProgress {
+		// the low nibble of the event offset selects error, hang or progress
+		switch eventOffset & 0x0F {
+		case firmwareProgressError:
+			if desc, ok := firmwareErrors[eventData[1]]; ok {
+				return "BIOS error: " + desc
+			}
+		case firmwareProgressProgress:
+			if desc, ok := firmwareProgress[eventData[1]]; ok {
+				return "BIOS progress: " + desc
+			}
+		}
+	}
+	return fmt.Sprintf("%s event, offset %d", lookupName(sensorTypeNames, sensorType, "SensorType"), eventOffset)
+}
+
+func lookupName(names map[int]string, id int, kind string) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s(%d)", kind, id)
+}
@@ -0,0 +1,215 @@
+package local
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"os"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecord builds a platform event trap record as returned by AMT_MessageLog.GetRecords
+func eventRecord(timestamp uint32, sensorType byte, eventOffset byte, severity byte, entity byte, eventData ...byte) string {
+	data := make([]byte, eventRecordLength)
+	binary.LittleEndian.PutUint32(data[0:4], timestamp)
+	data[5] = sensorType
+	data[6] = 0x6F
+	data[7] = eventOffset
+	data[9] = severity
+	data[11] = entity
+	copy(data[13:], eventData)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func getRecordsResponse(identifier int, noMoreRecords bool, records ...string) GetRecordsResponse {
+	rsp := GetRecordsResponse{}
+	rsp.Body.Output.IterationIdentifier = identifier
+	rsp.Body.Output.NoMoreRecords = noMoreRecords
+	rsp.Body.Output.RecordArray = records
+	return rsp
+}
+
+// stdinInput has the next reads of os.Stdin return input
+func stdinInput(t *testing.T, input string) func() {
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(input + "\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	stdin := os.Stdin
+	os.Stdin = r
+	return func() {
+		os.Stdin = stdin
+	}
+}
+
+func TestDecodeEventLogRecord(t *testing.T) {
+	t.Run("decodes a BIOS progress event", func(t *testing.T) {
+		record, err := decodeEventLogRecord(eventRecord(1700000000, sensorTypeFirmwareProgress, 0x02, 0x02, 34, 0x40, 0x09))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Unix(1700000000, 0).UTC(), record.Time)
+		assert.Equal(t, "System Firmware Progress", record.SensorType)
+		assert.Equal(t, "Information", record.Severity)
+		assert.Equal(t, "BIOS", record.Entity)
+		assert.Equal(t, "BIOS progress: Video initialization", record.Description)
+		assert.Equal(t, "4009000000000000", record.EventData)
+	})
+	t.Run("decodes a BIOS error event", func(t *testing.T) {
+		record, err := decodeEventLogRecord(eventRecord(1700000000, sensorTypeFirmwareProgress, 0x00, 0x10, 34, 0x40, 0x01))
+		assert.NoError(t, err)
+		assert.Equal(t, "Critical", record.Severity)
+		assert.Equal(t, "BIOS error: No system memory is physically installed", record.Description)
+	})
+	t.Run("describes other events by sensor type", func(t *testing.T) {
+		record, err := decodeEventLogRecord(eventRecord(1700000000, 0x05, 0x00, 0x08, 23))
+		assert.NoError(t, err)
+		assert.Equal(t, "Physical Security event, offset 0", record.Description)
+		assert.Equal(t, "System chassis", record.Entity)
+	})
+	t.Run("names unknown values by id", func(t *testing.T) {
+		record, err := decodeEventLogRecord(eventRecord(1700000000, 0xC0, 0x01, 0x03, 200))
+		assert.NoError(t, err)
+		assert.Equal(t, "SensorType(192)", record.SensorType)
+		assert.Equal(t, "Severity(3)", record.Severity)
+		assert.Equal(t, "Entity(200)", record.Entity)
+	})
+	t.Run("returns error on truncated record", func(t *testing.T) {
+		_, err := decodeEventLogRecord(base64.StdEncoding.EncodeToString([]byte{1, 2, 3}))
+		assert.Equal(t, errShortEventRecord, err)
+	})
+	t.Run("returns error on invalid base64", func(t *testing.T) {
+		_, err := decodeEventLogRecord("not base64!")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetEventLog(t *testing.T) {
+	f := &flags.Flags{}
+	first := eventRecord(1700000000, 0x0F, 0x02, 0x02, 34, 0x40, 0x01)
+	second := eventRecord(1700000100, 0x0F, 0x02, 0x02, 34, 0x40, 0x13)
+
+	t.Run("reads all pages", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(3, false, first, second)),
+			respondMsgFunc(t, getRecordsResponse(4, true, first)),
+		})
+		eventLog, rc := lps.GetEventLog(0, 0)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, 3, eventLog.TotalRecords)
+		assert.Len(t, eventLog.Records, 3)
+	})
+	t.Run("applies offset and count", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(4, true, first, second, first)),
+		})
+		eventLog, rc := lps.GetEventLog(1, 1)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, 3, eventLog.TotalRecords)
+		assert.Len(t, eventLog.Records, 1)
+		assert.Equal(t, "BIOS progress: Starting operating system boot process", eventLog.Records[0].Description)
+	})
+	t.Run("returns no records for an offset past the end", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(2, true, first)),
+		})
+		eventLog, rc := lps.GetEventLog(5, 0)
+		assert.Equal(t, utils.Success, rc)
+		assert.Empty(t, eventLog.Records)
+	})
+	t.Run("returns PT status on ReturnValue error", func(t *testing.T) {
+		rsp := getRecordsResponse(0, true)
+		rsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, rsp)})
+		_, rc := lps.GetEventLog(0, 0)
+		assert.Equal(t, utils.AmtPtStatusCodeBase+1, rc)
+	})
+	t.Run("returns UnmarshalMessageFailed on bad record", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(2, true, "not base64!")),
+		})
+		_, rc := lps.GetEventLog(0, 0)
+		assert.Equal(t, utils.UnmarshalMessageFailed, rc)
+	})
+	t.Run("returns error on server error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		_, rc := lps.GetEventLog(0, 0)
+		assert.NotEqual(t, utils.Success, rc)
+	})
+}
+
+func TestClearEventLog(t *testing.T) {
+	f := &flags.Flags{}
+	t.Run("sends ClearLog", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.Nil(t, err)
+				assert.Contains(t, string(body), "<a:Action>http://intel.com/wbem/wscim/1/amt-schema/1/AMT_MessageLog/ClearLog</a:Action>")
+				assert.Contains(t, string(body), `<h:ClearLog_INPUT xmlns:h="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_MessageLog"></h:ClearLog_INPUT>`)
+				assert.NotContains(t, string(body), "PositionToFirstRecord")
+				respondMsgFunc(t, ClearLogResponse{})(w, r)
+			},
+		})
+		assert.Equal(t, utils.Success, lps.ClearEventLog())
+	})
+	t.Run("returns PT status on ReturnValue error", func(t *testing.T) {
+		rsp := ClearLogResponse{}
+		rsp.Body.Output.ReturnValue = 2
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, rsp)})
+		assert.Equal(t, utils.AmtPtStatusCodeBase+2, lps.ClearEventLog())
+	})
+}
+
+func TestDisplayEventLog(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.EventLog = true
+	f.Password = "testPassword"
+	f.JsonOutput = true
+	record := eventRecord(1700000000, 0x0F, 0x02, 0x02, 34, 0x40, 0x01)
+
+	t.Run("returns Success with event log", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, getRecordsResponse(2, true, record))})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		assert.Contains(t, buf.String(), `"description": "BIOS progress: Memory initialization"`)
+	})
+	t.Run("clears the event log after confirming the password", func(t *testing.T) {
+		f.AmtInfo.EventLogClear = true
+		defer func() { f.AmtInfo.EventLogClear = false }()
+		defer stdinInput(t, "testPassword")()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(2, true, record)),
+			respondMsgFunc(t, ClearLogResponse{}),
+		})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		assert.Contains(t, buf.String(), `"cleared": true`)
+	})
+	t.Run("returns MissingOrIncorrectPassword when the password is not confirmed", func(t *testing.T) {
+		f.AmtInfo.EventLogClear = true
+		defer func() { f.AmtInfo.EventLogClear = false }()
+		defer stdinInput(t, "otherPassword")()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		assert.Equal(t, utils.MissingOrIncorrectPassword, lps.DisplayAMTInfo())
+	})
+	t.Run("returns the error when the event log is not cleared", func(t *testing.T) {
+		f.AmtInfo.EventLogClear = true
+		defer func() { f.AmtInfo.EventLogClear = false }()
+		defer stdinInput(t, "testPassword")()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, getRecordsResponse(2, true, record)),
+			respondServerErrFunc(),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.NotEqual(t, utils.Success, lps.DisplayAMTInfo())
+	})
+}
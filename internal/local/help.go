@@ -0,0 +1,15 @@
+package local
+
+import (
+	"rpc/pkg/utils"
+)
+
+func (service *ProvisioningService) DisplayHelp() utils.ReturnCode {
+	w := service.newOutputWriter()
+	w.Field("help", "", service.flags.Help.Text)
+	w.Println(service.flags.Help.Text)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
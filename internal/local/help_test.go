@@ -0,0 +1,35 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayHelp(t *testing.T) {
+	t.Run("should write the text", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.Help.Text = "Usage: rpc version [OPTIONS]\n"
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayHelp())
+		assert.Contains(t, buf.String(), "Usage: rpc version [OPTIONS]")
+	})
+	t.Run("should write json output", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.JsonOutput = true
+		f.Help.Text = "Usage: rpc version [OPTIONS]\n"
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayHelp())
+		var result map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, f.Help.Text, result["help"])
+	})
+}
@@ -1,17 +1,20 @@
 package local
 
 import (
-	"encoding/json"
+	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
-	"os"
 	"rpc/internal/amt"
+	"rpc/internal/i18n"
+	"rpc/internal/info"
+	"rpc/internal/lm"
+	"rpc/internal/output"
 	"rpc/pkg/utils"
 	"strconv"
 	"strings"
-
-	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 type PrivateKeyPairReference struct {
@@ -19,8 +22,34 @@ type PrivateKeyPairReference struct {
 	AssociatedCerts []string
 }
 
+// maxHostWorkers bounds the amtinfo host queries and MPS probes run at the same
+// time, the MEI queries run one after the other on a single connection
+const maxHostWorkers = 4
+
+// infoCacheDir returns the directory of the amtinfo cache, it is replaced in tests
+var infoCacheDir = utils.CacheDir
+
+// amtInfoResult holds the values collected for amtinfo, the MEI and host values
+// come from the info package and the wsman values are added here. Each query
+// writes its own fields so the queries can run concurrently without further
+// synchronization.
+type amtInfoResult struct {
+	info.InfoResult
+	ras             RemoteAccessInfo
+	rasResult       utils.ReturnCode
+	userCerts       []publickey.PublicKeyCertificate
+	userCertsResult utils.ReturnCode
+	auditLog        AuditLog
+	auditLogResult  utils.ReturnCode
+	eventLog        EventLog
+	eventLogResult  utils.ReturnCode
+	eventLogCleared utils.ReturnCode
+	kvm             RedirectionState
+	kvmResult       utils.ReturnCode
+}
+
 func (service *ProvisioningService) DisplayAMTInfo() utils.ReturnCode {
-	dataStruct := make(map[string]interface{})
+	w := service.newOutputWriter()
 	cmd := service.amtCommand
 
 	// UserCert precheck for provisioning mode and missing password
@@ -28,14 +57,31 @@ func (service *ProvisioningService) DisplayAMTInfo() utils.ReturnCode {
 	// has not been provisioned yet, then asking for the password is confusing
 	// do this check first so prompts and errors messages happen before
 	// any other displayed info
-	if service.flags.AmtInfo.UserCert && service.flags.Password == "" {
+	if (service.flags.AmtInfo.UserCert || service.flags.AmtInfo.Audit || service.flags.AmtInfo.EventLog || service.flags.AmtInfo.Redirection) && service.flags.Password == "" {
 		result, err := cmd.GetControlMode()
 		if err != nil {
 			log.Error(err)
 			service.flags.AmtInfo.UserCert = false
+			service.flags.AmtInfo.Audit = false
+			service.flags.AmtInfo.EventLog = false
+			service.flags.AmtInfo.Redirection = false
 		} else if result == 0 {
-			fmt.Println("Device is in pre-provisioning mode. User certificates are not available")
+			if service.flags.AmtInfo.UserCert {
+				fmt.Println("Device is in pre-provisioning mode. User certificates are not available")
+			}
+			if service.flags.AmtInfo.Audit {
+				fmt.Println("Device is in pre-provisioning mode. The audit log is not available")
+			}
+			if service.flags.AmtInfo.EventLog {
+				fmt.Println("Device is in pre-provisioning mode. The event log is not available")
+			}
+			if service.flags.AmtInfo.Redirection {
+				fmt.Println("Device is in pre-provisioning mode. The redirection state is not available")
+			}
 			service.flags.AmtInfo.UserCert = false
+			service.flags.AmtInfo.Audit = false
+			service.flags.AmtInfo.EventLog = false
+			service.flags.AmtInfo.Redirection = false
 		} else {
 			if _, rc := service.flags.ReadPasswordFromUser(); rc != 0 {
 				fmt.Println("Invalid Entry")
@@ -43,288 +89,521 @@ func (service *ProvisioningService) DisplayAMTInfo() utils.ReturnCode {
 			}
 		}
 	}
-
-	if service.flags.AmtInfo.Ver {
-		result, err := cmd.GetVersionDataFromME("AMT", service.flags.AMTTimeoutDuration)
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["amt"] = result
-		if !service.flags.JsonOutput {
-			println("Version			: " + result)
+	// clearing can not be undone, confirm before any other displayed info
+	if service.flags.AmtInfo.EventLog && service.flags.AmtInfo.EventLogClear {
+		if rc := service.flags.ConfirmPassword("clear the AMT event log"); rc != utils.Success {
+			return rc
 		}
 	}
-	if service.flags.AmtInfo.Bld {
-		result, err := cmd.GetVersionDataFromME("Build Number", service.flags.AMTTimeoutDuration)
-		if err != nil {
-			log.Error(err)
+	// a table that can not be read fails before any other displayed info
+	var advisories info.AdvisoryTable
+	if service.flags.AmtInfo.SecCheck {
+		var err error
+		if advisories, err = info.LoadAdvisories(service.flags.AmtInfo.Advisories); err != nil {
+			log.Error("unable to read the advisory table: ", err)
+			return utils.FailedReadingConfiguration
 		}
-		dataStruct["buildNumber"] = result
+	}
 
-		if !service.flags.JsonOutput {
-			println("Build Number		: " + result)
-		}
+	result := service.collectAMTInfo()
+
+	// scripts tell from the age whether the static values were read from the MEI
+	if !result.CachedAt.IsZero() {
+		w.Field("cacheAge", "", int(time.Since(result.CachedAt).Seconds()))
+	}
+	if service.flags.AmtInfo.Ver {
+		w.Field("amt", i18n.Label("info.version"), result.Version)
+	}
+	if service.flags.AmtInfo.Bld {
+		w.Field("buildNumber", i18n.Label("info.buildNumber"), result.BuildNumber)
 	}
 	if service.flags.AmtInfo.Sku {
-		result, err := cmd.GetVersionDataFromME("Sku", service.flags.AMTTimeoutDuration)
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["sku"] = result
-
-		if !service.flags.JsonOutput {
-			println("SKU			: " + result)
-		}
+		w.Field("sku", i18n.Label("info.sku"), result.SKU)
 	}
 	if service.flags.AmtInfo.Ver && service.flags.AmtInfo.Sku {
-		result := DecodeAMT(dataStruct["amt"].(string), dataStruct["sku"].(string))
-		dataStruct["features"] = strings.TrimSpace(result)
-		if !service.flags.JsonOutput {
-			println("Features		: " + result)
+		features := info.DecodeAMTFeatures(result.Version, result.SKU)
+		w.Field("features", "", features)
+		w.Println(i18n.Label("info.features") + ": " + info.DecodeAMT(result.Version, result.SKU))
+		if name := features.SKUClassName(); name != "" {
+			w.Println(i18n.Label("info.skuClass") + ": " + name)
 		}
 	}
 	if service.flags.AmtInfo.UUID {
-		result, err := cmd.GetUUID()
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["uuid"] = result
-
-		if !service.flags.JsonOutput {
-			println("UUID			: " + result)
-		}
+		w.Field("uuid", i18n.Label("info.uuid"), result.UUID)
 	}
 	if service.flags.AmtInfo.Mode {
-		result, err := cmd.GetControlMode()
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["controlMode"] = utils.InterpretControlMode(result)
-
-		if !service.flags.JsonOutput {
-			println("Control Mode		: " + string(utils.InterpretControlMode(result)))
+		w.Field("controlMode", i18n.Label("info.controlMode"), utils.InterpretControlMode(result.ControlMode))
+	}
+	if service.flags.AmtInfo.OpState {
+		w.Field("operationalState", "", result.OpState)
+		if result.OpState.AMTEnabled {
+			w.Println(i18n.Label("info.operationalState") + ": " + i18n.T("info.enabled"))
+			w.Println(i18n.Label("info.provisioningState") + ": " + result.OpState.ProvisioningState)
+			w.Println(i18n.Label("info.provisioningMode") + ": " + result.OpState.ProvisioningMode)
+		} else if result.Err(info.QueryOpState) == nil {
+			w.Println(i18n.Label("info.operationalState") + ": " + i18n.T("info.disabledInMEBx"))
 		}
 	}
+	if service.flags.AmtInfo.Modes {
+		writeProvisioningModes(w, result.InfoResult)
+	}
 	if service.flags.AmtInfo.DNS {
-		result, err := cmd.GetDNSSuffix()
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["dnsSuffix"] = result
-
-		if !service.flags.JsonOutput {
-			println("DNS Suffix		: " + string(result))
-		}
-		result, err = cmd.GetOSDNSSuffix()
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["dnsSuffixOS"] = result
-
-		if !service.flags.JsonOutput {
-			fmt.Println("DNS Suffix (OS)		: " + result)
+		w.Field("dnsSuffix", i18n.Label("info.dnsSuffix"), result.DNSSuffix)
+		w.Field("dnsSuffixOS", i18n.Label("info.dnsSuffixOS"), result.DNSSuffixOS)
+		// provisioning with a certificate of the OS domain fails until the suffixes match
+		if result.DNSSuffix != "" && result.DNSSuffixOS != "" && !strings.EqualFold(result.DNSSuffix, result.DNSSuffixOS) {
+			log.Warnf("the PKI DNS suffix %s differs from the DNS suffix %s of the OS, set it with configure dnssuffix -value %s before activation", result.DNSSuffix, result.DNSSuffixOS, result.DNSSuffixOS)
 		}
 	}
 	if service.flags.AmtInfo.Hostname {
-		result, err := os.Hostname()
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["hostnameOS"] = result
-		if !service.flags.JsonOutput {
-			println("Hostname (OS)		: " + string(result))
+		w.Field("hostnameOS", i18n.Label("info.hostnameOS"), result.HostnameOS)
+	}
+	if service.flags.AmtInfo.Hardware {
+		hw := result.Hardware
+		w.Field("hardware", "", hw)
+		w.Println(i18n.Label("info.manufacturer") + ": " + hw.Manufacturer)
+		w.Println(i18n.Label("info.model") + ": " + hw.Model)
+		w.Println(i18n.Label("info.serialNumber") + ": " + hw.SerialNumber)
+		w.Println(i18n.Label("info.assetTag") + ": " + hw.AssetTag)
+		w.Println(i18n.Label("info.cpu") + ": " + hw.CPU)
+		w.Printf("%s: %d MB\n", i18n.Label("info.memory"), hw.MemoryMB)
+	}
+	if service.flags.AmtInfo.BIOS {
+		bios, fw := result.Hardware.BIOS, result.Firmware
+		w.Field("bios", "", bios)
+		w.Println(i18n.Label("info.biosVendor") + ": " + bios.Vendor)
+		w.Println(i18n.Label("info.biosVersion") + ": " + bios.Version)
+		w.Println(i18n.Label("info.biosReleaseDate") + ": " + bios.ReleaseDate)
+		w.Field("firmware", "", fw)
+		w.Println(i18n.Label("info.meFirmwareVersion") + ": " + fw.Version)
+		w.Println(i18n.Label("info.meFirmwareBuild") + ": " + fw.BuildNumber)
+		w.Println(i18n.Label("info.meRecoveryVersion") + ": " + orNotReported(fw.RecoveryVersion))
+		w.Println(i18n.Label("info.meRecoveryBuild") + ": " + orNotReported(fw.RecoveryBuildNumber))
+		w.Println(i18n.Label("info.meFirmwareSVN") + ": " + orNotReported(fw.SVN))
+	}
+	if service.flags.AmtInfo.SecCheck {
+		writeSecurityCheck(w, advisories.CheckFirmware(info.FirmwareInfo{Version: result.Version, BuildNumber: result.BuildNumber}))
+	}
+	if service.flags.AmtInfo.Sys {
+		system := result.System
+		if err := result.Err(info.QuerySystem); err != nil {
+			log.Warn("unable to read the host OS version: ", err)
+		}
+		w.Field("system", "", system)
+		if !service.flags.IsRemote() {
+			w.Println(i18n.Label("info.osName") + ": " + system.OSName)
+			w.Println(i18n.Label("info.osVersion") + ": " + system.OSVersion)
+			w.Println(i18n.Label("info.osBuild") + ": " + system.OSBuild)
+			w.Println(i18n.Label("info.architecture") + ": " + system.Architecture)
+		}
+		w.Println(i18n.Label("info.rpcVersion") + ": " + system.RPCVersion)
+		w.Println(i18n.Label("info.protocolVersion") + ": " + system.ProtocolVersion)
+		w.Println(i18n.Label("info.transport") + ": " + system.Transport)
+		if system.Address != "" {
+			reachable := i18n.T("info.notReachable")
+			if system.Reachable {
+				reachable = i18n.T("info.reachable")
+			}
+			w.Println(i18n.Label("info.wsmanAddress") + ": " + system.Address + " (" + reachable + ")")
 		}
 	}
 
 	if service.flags.AmtInfo.Ras {
-		result, err := cmd.GetRemoteAccessConnectionStatus()
-		if err != nil {
-			log.Error(err)
+		w.Field("ras", "", result.ras)
+		// the connection status is read from the MEI, a remote device only has its configuration
+		if !service.flags.IsRemote() {
+			w.Println(i18n.Label("info.rasNetwork") + ": " + result.ras.NetworkStatus)
+			w.Println(i18n.Label("info.rasRemoteStatus") + ": " + result.ras.RemoteStatus)
+			w.Println(i18n.Label("info.rasTrigger") + ": " + result.ras.RemoteTrigger)
+			w.Println(i18n.Label("info.rasMPSHostname") + ": " + result.ras.MPSHostname)
+		}
+		if service.flags.AmtInfo.RasDetails {
+			if result.rasResult != utils.Success {
+				log.Error("unable to retrieve CIRA configuration")
+			}
+			writeCIRAConfiguration(w, result.ras)
 		}
-		dataStruct["ras"] = result
-
-		if !service.flags.JsonOutput {
-			println("RAS Network      	: " + result.NetworkStatus)
-			println("RAS Remote Status	: " + result.RemoteStatus)
-			println("RAS Trigger      	: " + result.RemoteTrigger)
-			println("RAS MPS Hostname 	: " + result.MPSHostname)
+		if service.flags.AmtInfo.RasProbe {
+			writeMPSProbes(w, result.ras.Probes)
 		}
 	}
 	if service.flags.AmtInfo.Lan {
-		wired, err := cmd.GetLANInterfaceSettings(false)
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["wiredAdapter"] = wired
-
-		if !service.flags.JsonOutput && wired.MACAddress != "00:00:00:00:00:00" {
-			println("---Wired Adapter---")
-			println("DHCP Enabled 		: " + strconv.FormatBool(wired.DHCPEnabled))
-			println("DHCP Mode    		: " + wired.DHCPMode)
-			println("Link Status  		: " + wired.LinkStatus)
-			println("IP Address   		: " + wired.IPAddress)
-			println("MAC Address  		: " + wired.MACAddress)
+		w.Field("wiredAdapter", "", result.Wired)
+		if result.Wired.MACAddress != "00:00:00:00:00:00" {
+			w.Println(i18n.T("info.wiredAdapter"))
+			writeInterfaceSettings(w, result.Wired)
 		}
 
-		wireless, err := cmd.GetLANInterfaceSettings(true)
-		if err != nil {
-			log.Error(err)
-		}
-		dataStruct["wirelessAdapter"] = wireless
-
-		if !service.flags.JsonOutput {
-			println("---Wireless Adapter---")
-			println("DHCP Enabled 		: " + strconv.FormatBool(wireless.DHCPEnabled))
-			println("DHCP Mode    		: " + wireless.DHCPMode)
-			println("Link Status  		: " + wireless.LinkStatus)
-			println("IP Address   		: " + wireless.IPAddress)
-			println("MAC Address  		: " + wireless.MACAddress)
-		}
+		w.Field("wirelessAdapter", "", result.Wireless)
+		w.Println(i18n.T("info.wirelessAdapter"))
+		writeInterfaceSettings(w, result.Wireless)
 	}
 	if service.flags.AmtInfo.Cert {
-		result, err := cmd.GetCertificateHashes()
-		if err != nil {
-			log.Error(err)
-		}
-		sysCertMap := map[string]amt.CertHashEntry{}
-		for _, v := range result {
-			sysCertMap[v.Name] = v
-		}
-		dataStruct["certificateHashes"] = sysCertMap
-		if !service.flags.JsonOutput {
-			if len(result) == 0 {
-				fmt.Println("---No Certificate Hashes Found---")
-			} else {
-				fmt.Println("---Certificate Hashes---")
+		certHashes := info.NewCertHashInfos(result.CertHashes, service.flags.AmtInfo.CertWarnOnly)
+		w.Field("certificateHashes", "", certHashes)
+		if len(certHashes) == 0 && service.flags.AmtInfo.CertWarnOnly {
+			w.Println(i18n.T("info.noDeprecatedCertHashes"))
+		} else if len(certHashes) == 0 {
+			w.Println(i18n.T("info.noCertHashes"))
+		} else {
+			w.Println(i18n.T("info.certHashes"))
+		}
+		deprecated := 0
+		for _, v := range certHashes {
+			w.Printf("%s", v.Name)
+			if v.IsDefault && v.IsActive {
+				w.Printf("  (Default, Active)")
+			} else if v.IsDefault {
+				w.Printf("  (Default)")
+			} else if v.IsActive {
+				w.Printf("  (Active)")
 			}
-			for k, v := range sysCertMap {
-				fmt.Printf("%s", k)
-				if v.IsDefault && v.IsActive {
-					fmt.Printf("  (Default, Active)")
-				} else if v.IsDefault {
-					fmt.Printf("  (Default)")
-				} else if v.IsActive {
-					fmt.Printf("  (Active)")
-				}
-				fmt.Println()
-				fmt.Println("   " + v.Algorithm + ": " + v.Hash)
+			w.Println("")
+			w.Println("   " + v.Algorithm + ": " + v.Hash)
+			if v.Deprecated {
+				w.Println("   WARNING: " + v.Algorithm + " hash of a deprecated CA")
+				deprecated++
 			}
 		}
+		if deprecated > 0 {
+			log.Warnf("%d certificate hashes belong to CAs with a deprecated hash algorithm", deprecated)
+		}
 	}
 	if service.flags.AmtInfo.UserCert {
-		service.setupWsmanClient("admin", service.flags.Password)
-		var userCerts []publickey.PublicKeyCertificate
-		service.GetPublicKeyCerts(&userCerts)
-		userCertMap := map[string]publickey.PublicKeyCertificate{}
-		for i := range userCerts {
-			c := userCerts[i]
+		if result.userCertsResult != utils.Success {
+			log.Error("unable to retrieve public key certificates")
+		}
+		userCertMap := map[string]PublicKeyCertInfo{}
+		for i := range result.userCerts {
+			c := result.userCerts[i]
 			name := GetTokenFromKeyValuePairs(c.Subject, "CN")
 			// CN is not required by spec, but should work
 			// just in case, provide something accurate
 			if name == "" {
 				name = c.InstanceID
 			}
-			userCertMap[name] = c
+			userCertMap[name] = NewPublicKeyCertInfo(c)
 		}
-		dataStruct["publicKeyCerts"] = userCertMap
-
-		if !service.flags.JsonOutput {
-			if len(userCertMap) == 0 {
-				fmt.Println("---No Public Key Certs Found---")
-			} else {
-				fmt.Println("---Public Key Certs---")
+		w.Field("publicKeyCerts", "", userCertMap)
+		if len(userCertMap) == 0 {
+			w.Println(i18n.T("info.noPublicKeyCerts"))
+		} else {
+			w.Println(i18n.T("info.publicKeyCerts"))
+		}
+		for k, c := range userCertMap {
+			w.Printf("%s", k)
+			if c.TrustedRootCertficate && c.ReadOnlyCertificate {
+				w.Printf("  (TrustedRoot, ReadOnly)")
+			} else if c.TrustedRootCertficate {
+				w.Printf("  (TrustedRoot)")
+			} else if c.ReadOnlyCertificate {
+				w.Printf("  (ReadOnly)")
 			}
-			for k, c := range userCertMap {
-				fmt.Printf("%s", k)
-				if c.TrustedRootCertficate && c.ReadOnlyCertificate {
-					fmt.Printf("  (TrustedRoot, ReadOnly)")
-				} else if c.TrustedRootCertficate {
-					fmt.Printf("  (TrustedRoot)")
-				} else if c.ReadOnlyCertificate {
-					fmt.Printf("  (ReadOnly)")
-				}
-				fmt.Println()
+			w.Println("")
+			w.Println("   Subject: " + c.Subject)
+			w.Println("   Issuer : " + c.Issuer)
+			if !c.NotAfter.IsZero() {
+				w.Println("   Expires: " + c.NotAfter.Format(time.RFC3339))
 			}
 		}
 	}
 
-	if service.flags.JsonOutput {
-		outBytes, err := json.MarshalIndent(dataStruct, "", "  ")
-		output := string(outBytes)
-		if err != nil {
-			output = err.Error()
+	if service.flags.AmtInfo.Redirection {
+		if result.kvmResult != utils.Success {
+			log.Error("unable to retrieve redirection state")
 		}
-		println(output)
+		writeRedirectionState(w, result.kvm)
 	}
-	return utils.Success
-}
 
-func DecodeAMT(version, SKU string) string {
-	amtParts := strings.Split(version, ".")
-	if len(amtParts) <= 1 {
-		return "Invalid AMT version format"
-	}
-	amtVer, err := strconv.ParseFloat(amtParts[0], 64)
-	if err != nil {
-		return "Invalid AMT version"
+	if service.flags.AmtInfo.Audit {
+		if result.auditLogResult != utils.Success {
+			log.Error("unable to retrieve audit log")
+		}
+		w.Field("auditLog", "", result.auditLog)
+		w.Println(i18n.T("info.auditLog", len(result.auditLog.Records), result.auditLog.TotalRecords))
+		for _, r := range result.auditLog.Records {
+			w.Printf("%s  %s  Event %d  Initiator %s (%s)", r.Time.Format(time.RFC3339), r.AuditApp, r.EventID, r.Initiator, r.InitiatorType)
+			if r.NetAddress != "" {
+				w.Printf("  From %s", r.NetAddress)
+			}
+			w.Println("")
+		}
 	}
-	skuNum, err := strconv.ParseInt(SKU, 0, 64)
-	if err != nil {
-		return "Invalid SKU"
-	}
-	result := ""
-	if amtVer <= 2.2 {
-		switch skuNum {
-		case 0:
-			result += "AMT + ASF + iQST"
-		case 1:
-			result += "ASF + iQST"
-		case 2:
-			result += "iQST"
-		default:
-			result += "Unknown"
+
+	if service.flags.AmtInfo.EventLog {
+		if result.eventLogResult != utils.Success {
+			log.Error("unable to retrieve event log")
 		}
-	} else if amtVer < 5.0 {
-		if skuNum&0x02 > 0 {
-			result += "iQST "
+		w.Field("eventLog", "", result.eventLog)
+		w.Println(i18n.T("info.eventLog", len(result.eventLog.Records), result.eventLog.TotalRecords))
+		for _, r := range result.eventLog.Records {
+			w.Printf("%s  %s  %s  %s\n", r.Time.Format(time.RFC3339), r.Severity, r.Entity, r.Description)
 		}
-		if skuNum&0x04 > 0 {
-			result += "ASF "
+		if result.eventLog.Cleared {
+			w.Println(i18n.T("info.eventLogCleared"))
 		}
-		if skuNum&0x08 > 0 {
-			result += "AMT"
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	if service.flags.AmtInfo.EventLogClear && result.eventLogCleared != utils.Success {
+		log.Error("unable to clear event log")
+		return result.eventLogCleared
+	}
+	return utils.Success
+}
+
+// wsmanTarget returns the WS-MAN interface rpc reaches AMT through, the AMT of -host or
+// the local AMT with -transport
+func (service *ProvisioningService) wsmanTarget() info.Target {
+	if service.flags.IsRemote() {
+		return info.Target{Transport: info.TransportRemote, Address: service.flags.RemoteAddress()}
+	}
+	transport := service.flags.Transport
+	if transport == "" {
+		transport = lm.TransportAuto
+	}
+	return info.Target{Transport: transport, Address: service.lmsHostPort()}
+}
+
+// collectAMTInfo runs the queries for the selected amtinfo flags concurrently.
+// The MEI queries share one connection opened through newAMTCommand.
+func (service *ProvisioningService) collectAMTInfo() amtInfoResult {
+	result := amtInfoResult{}
+	amtInfo := service.flags.AmtInfo
+	collector := info.Collector{
+		NewAMTCommand: service.newAMTCommand,
+		Workers:       maxHostWorkers,
+		Cache:         service.infoCache(),
+		Target:        service.wsmanTarget(),
+	}
+	var tasks []func()
+	if service.flags.IsRemote() {
+		// a remote device reports the values with wsman, before the other wsman queries as they share the client
+		service.setupWsmanClient("admin", service.flags.Password)
+		service.collectRemoteInfo(&result.InfoResult)
+		if amtInfo.Sys {
+			result.System, _ = info.HostSystem(collector.Target)
 		}
 	} else {
-		if skuNum&0x02 > 0 && amtVer < 7.0 {
-			result += "iQST "
-		}
-		if skuNum&0x04 > 0 && amtVer < 6.0 {
-			result += "ASF "
-		}
-		if skuNum&0x08 > 0 {
-			result += "AMT Pro "
-		}
-		if skuNum&0x10 > 0 {
-			result += "Intel Standard Manageability "
-		}
-		if skuNum&0x20 > 0 && amtVer < 6.0 {
-			result += "TPM "
-		}
-		if skuNum&0x100 > 0 && amtVer < 6.0 {
-			result += "Home IT "
-		}
-		if skuNum&0x400 > 0 && amtVer < 6.0 {
-			result += "WOX "
+		tasks = append(tasks, func() {
+			result.InfoResult = collector.Collect(info.InfoRequest{
+				Version:    amtInfo.Ver || amtInfo.Modes || amtInfo.SecCheck,
+				Build:      amtInfo.Bld || amtInfo.SecCheck,
+				SKU:        amtInfo.Sku || amtInfo.Modes,
+				UUID:       amtInfo.UUID,
+				Mode:       amtInfo.Mode || amtInfo.Modes,
+				OpState:    amtInfo.OpState,
+				DNS:        amtInfo.DNS,
+				Hostname:   amtInfo.Hostname,
+				RAS:        amtInfo.Ras,
+				LAN:        amtInfo.Lan,
+				CertHashes: amtInfo.Cert || amtInfo.Modes,
+				Hardware:   amtInfo.Hardware,
+				BIOS:       amtInfo.BIOS,
+				System:     amtInfo.Sys,
+				Modes:      amtInfo.Modes,
+				AMTTimeout: service.flags.AMTTimeoutDuration,
+			})
+		})
+	}
+	if amtInfo.UserCert || amtInfo.Audit || amtInfo.EventLog || amtInfo.Redirection || amtInfo.RasDetails {
+		service.setupWsmanClient("admin", service.flags.Password)
+		// one task for all wsman queries as they share the client
+		tasks = append(tasks, func() {
+			if amtInfo.UserCert {
+				result.userCertsResult = service.GetPublicKeyCerts(&result.userCerts)
+			}
+			if amtInfo.Audit {
+				result.auditLog, result.auditLogResult = service.GetAuditLog(amtInfo.AuditOffset, amtInfo.AuditCount)
+			}
+			if amtInfo.EventLog {
+				result.eventLog, result.eventLogResult = service.GetEventLog(amtInfo.AuditOffset, amtInfo.AuditCount)
+				// only records that were read are cleared
+				if amtInfo.EventLogClear {
+					result.eventLogCleared = result.eventLogResult
+					if result.eventLogResult == utils.Success {
+						result.eventLogCleared = service.ClearEventLog()
+					}
+					result.eventLog.Cleared = result.eventLogCleared == utils.Success
+				}
+			}
+			if amtInfo.Redirection {
+				result.kvm, result.kvmResult = service.GetRedirectionState()
+			}
+			if amtInfo.RasDetails {
+				result.rasResult = service.GetCIRAConfiguration(&result.ras)
+			}
+		})
+	}
+	info.RunConcurrently(len(tasks), tasks)
+	for _, err := range result.Errors {
+		log.Error(err)
+	}
+	result.ras.RemoteAccessStatus = result.RAS
+	// the servers to probe come from the queries above
+	if amtInfo.RasProbe {
+		result.ras.Probes = ProbeMPSServers(result.ras)
+	}
+	return result
+}
+
+// infoCache returns the cache of the static MEI values, nil when it is turned off or there
+// is no cache directory
+func (service *ProvisioningService) infoCache() *info.Cache {
+	if service.flags.InfoCache.TTL <= 0 {
+		return nil
+	}
+	dir, err := infoCacheDir()
+	if err != nil {
+		log.Debug("the amtinfo values are not cached: ", err)
+		return nil
+	}
+	return &info.Cache{Dir: dir, TTL: service.flags.InfoCache.TTL, Refresh: service.flags.InfoCache.NoCache}
+}
+
+func writeCIRAConfiguration(w output.OutputWriter, ras RemoteAccessInfo) {
+	if len(ras.MPSServers) == 0 {
+		w.Println("RAS MPS Servers  \t: none")
+	}
+	for _, mps := range ras.MPSServers {
+		server := fmt.Sprintf("%s:%d", mps.Hostname, mps.Port)
+		if mps.CN != "" && mps.CN != mps.Hostname {
+			server += " (CN " + mps.CN + ")"
 		}
-		if skuNum&0x2000 > 0 {
-			result += "AT-p "
+		w.Println("RAS MPS Server   \t: " + server)
+	}
+	w.Println("RAS Env Detection\t: " + strings.Join(ras.EnvironmentDetection, ", "))
+	for _, trigger := range ras.Triggers {
+		w.Printf("RAS Policy       \t: %s, %s, tunnel lifetime %ds", trigger.Name, trigger.Trigger, trigger.TunnelLifeTime)
+		if trigger.PeriodicInterval > 0 {
+			w.Printf(", every %ds", trigger.PeriodicInterval)
 		}
-		if skuNum&0x4000 > 0 {
-			result += "Corporate "
+		w.Println("")
+	}
+}
+
+func writeMPSProbes(w output.OutputWriter, probes []MPSProbe) {
+	if len(probes) == 0 {
+		w.Println("RAS MPS Probe    \t: no MPS server configured")
+	}
+	for _, probe := range probes {
+		result := probe.Server
+		switch {
+		case !probe.Reachable:
+			result += " unreachable: " + probe.Error
+		case !probe.TLSHandshake:
+			result += fmt.Sprintf(" reachable in %dms, %s", probe.LatencyMs, probe.Error)
+		default:
+			result += fmt.Sprintf(" reachable in %dms, %s handshake ok", probe.LatencyMs, probe.TLSVersion)
+			if probe.CertificateCN != "" {
+				result += ", certificate CN " + probe.CertificateCN
+			}
 		}
-		if skuNum&0x8000 > 0 && amtVer < 8.0 {
-			result += "L3 Mgt Upgrade"
+		w.Println("RAS MPS Probe    \t: " + result)
+	}
+}
+
+func writeInterfaceSettings(w output.OutputWriter, settings amt.InterfaceSettings) {
+	w.Println(i18n.Label("info.dhcpEnabled") + ": " + strconv.FormatBool(settings.DHCPEnabled))
+	w.Println(i18n.Label("info.dhcpMode") + ": " + settings.DHCPMode)
+	w.Println(i18n.Label("info.linkStatus") + ": " + settings.LinkStatus)
+	w.Println(i18n.Label("info.ipAddress") + ": " + settings.IPAddress)
+	w.Println(i18n.Label("info.macAddress") + ": " + settings.MACAddress)
+	for _, address := range settings.IPv6Addresses {
+		w.Println(i18n.Label("info.ipv6Address") + ": " + address)
+	}
+}
+
+// PublicKeyCertInfo adds the validity period parsed from the
+// X509 blob to the certificate properties reported by AMT
+type PublicKeyCertInfo struct {
+	publickey.PublicKeyCertificate
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+func NewPublicKeyCertInfo(c publickey.PublicKeyCertificate) PublicKeyCertInfo {
+	info := PublicKeyCertInfo{PublicKeyCertificate: c}
+	der, err := base64.StdEncoding.DecodeString(c.X509Certificate)
+	if err != nil {
+		log.Debugf("unable to decode certificate %s: %s", c.InstanceID, err)
+		return info
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		log.Debugf("unable to parse certificate %s: %s", c.InstanceID, err)
+		return info
+	}
+	info.NotBefore = cert.NotBefore
+	info.NotAfter = cert.NotAfter
+	return info
+}
+
+// writeProvisioningModes writes whether the firmware settings allow CCM and ACM activation,
+// with the reasons of a mode that is not allowed
+func writeProvisioningModes(w output.OutputWriter, result info.InfoResult) {
+	if err := result.ProvisioningModesErr(); err != nil {
+		log.Error("unable to read the provisioning modes: ", err)
+		return
+	}
+	modes := info.DecodeProvisioningModes(result)
+	w.Field("provisioningModes", "", modes)
+	state := i18n.T("info.disabled")
+	if modes.AMTEnabled {
+		state = i18n.T("info.enabled")
+	}
+	w.Println(i18n.Label("info.operationalState") + ": " + state)
+	w.Println(i18n.Label("info.changeFromOS") + ": " + allowedText(modes.TransitionAllowed, nil))
+	remote := i18n.T("info.disabled")
+	if modes.RemoteConfiguration {
+		remote = i18n.T("info.enabled")
+	}
+	w.Println(i18n.Label("info.remoteConfiguration") + ": " + remote)
+	w.Println(i18n.Label("info.provisioningTLSMode") + ": " + modes.ProvisioningTLSMode)
+	w.Printf("%s: %d\n", i18n.Label("info.activeCertHashes"), modes.ActiveCertHashes)
+	w.Println(i18n.Label("info.ccmActivation") + ": " + allowedText(modes.CCMAllowed, modes.CCMReasons))
+	w.Println(i18n.Label("info.acmActivation") + ": " + allowedText(modes.ACMAllowed, modes.ACMReasons))
+}
+
+// writeSecurityCheck writes the status of the firmware for each advisory, with the version
+// that fixes an affected firmware
+func writeSecurityCheck(w output.OutputWriter, check info.SecurityCheck) {
+	w.Field("securityCheck", "", check)
+	w.Printf("%s (%s %s): %d %s\n", i18n.Label("info.securityAdvisories"), i18n.T("info.advisoryTable"), check.TableUpdated, check.Affected, i18n.T("info.affected"))
+	for _, advisory := range check.Advisories {
+		status := i18n.T("info.notAffected")
+		switch advisory.Status {
+		case info.AdvisoryAffected:
+			status = i18n.T("info.affected")
+			if advisory.Fixed != "" {
+				status += ", " + i18n.T("info.fixedIn") + " " + advisory.Fixed
+			}
+		case info.AdvisoryNotListed:
+			status = i18n.T("info.notListed")
+		case info.AdvisoryUnknown:
+			status = i18n.T("info.advisoryUnknown")
 		}
+		w.Printf("  %s: %s\n", advisory.ID, status)
 	}
-	return result
+}
+
+// orNotReported is the value, or not reported for a value the firmware does not report
+func orNotReported(value string) string {
+	if value == "" {
+		return i18n.T("info.notReported")
+	}
+	return value
+}
+
+// allowedText is allowed, or not allowed followed by the reasons
+func allowedText(allowed bool, reasons []string) string {
+	if allowed {
+		return i18n.T("info.allowed")
+	}
+	if len(reasons) == 0 {
+		return i18n.T("info.notAllowed")
+	}
+	return i18n.T("info.notAllowed") + " (" + strings.Join(reasons, "; ") + ")"
 }
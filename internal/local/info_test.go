@@ -1,12 +1,22 @@
 package local
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
 	"github.com/stretchr/testify/assert"
+	"net"
+	"path/filepath"
+	amt2 "rpc/internal/amt"
 	"rpc/internal/flags"
+	"rpc/internal/info"
+	"rpc/internal/lm"
 	"rpc/pkg/utils"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestDisplayAMTInfo(t *testing.T) {
@@ -40,6 +50,58 @@ func TestDisplayAMTInfo(t *testing.T) {
 		assert.Equal(t, utils.Success, resultCode)
 	})
 
+	t.Run("returns Success with yaml output", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.AmtInfo = defaultFlags
+		f.YamlOutput = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		resultCode := lps.DisplayAMTInfo()
+		assert.Equal(t, utils.Success, resultCode)
+		assert.Contains(t, buf.String(), "controlMode: ")
+		assert.Contains(t, buf.String(), "wiredAdapter:\n")
+		assert.NotContains(t, buf.String(), "---Wired Adapter---")
+	})
+
+	t.Run("writes text output through the output writer", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.AmtInfo.UUID = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		resultCode := lps.DisplayAMTInfo()
+		assert.Equal(t, utils.Success, resultCode)
+		assert.True(t, strings.HasPrefix(buf.String(), "UUID\t\t\t: "))
+	})
+
+	t.Run("reports operational state", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.AmtInfo.OpState = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		resultCode := lps.DisplayAMTInfo()
+		assert.Equal(t, utils.Success, resultCode)
+		assert.Contains(t, buf.String(), "Operational State\t: enabled\n")
+		assert.Contains(t, buf.String(), "Provisioning State\t: post-provisioning\n")
+	})
+
+	t.Run("reports AMT disabled in MEBx", func(t *testing.T) {
+		orig := mockOperationalState
+		mockOperationalState = amt2.OperationalState{}
+		defer func() { mockOperationalState = orig }()
+		f := &flags.Flags{}
+		f.AmtInfo.OpState = true
+		f.JsonOutput = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		resultCode := lps.DisplayAMTInfo()
+		assert.Equal(t, utils.Success, resultCode)
+		assert.Contains(t, buf.String(), `"amtEnabled": false`)
+	})
+
 	t.Run("returns Success with certs", func(t *testing.T) {
 		f := &flags.Flags{}
 		f.AmtInfo.Cert = true
@@ -61,6 +123,22 @@ func TestDisplayAMTInfo(t *testing.T) {
 		assert.Equal(t, utils.Success, resultCode)
 	})
 
+	t.Run("returns Success with audit log", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.AmtInfo.Audit = true
+		f.Password = "testPassword"
+		f.JsonOutput = true
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, readRecordsResponse(1, auditRecord(16, 1, "admin", 1700000000, "192.168.1.10"))),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		resultCode := lps.DisplayAMTInfo()
+		assert.Equal(t, utils.Success, resultCode)
+		assert.Contains(t, buf.String(), `"initiator": "admin"`)
+	})
+
 	t.Run("returns Success but logs errors on error conditions", func(t *testing.T) {
 		mockUUIDErr = mockStandardErr
 		mockVersionDataErr = mockStandardErr
@@ -127,39 +205,230 @@ func TestDisplayAMTInfo(t *testing.T) {
 	})
 }
 
-func TestDecodeAMT(t *testing.T) {
-	testCases := []struct {
-		version string
-		SKU     string
-		want    string
-	}{
-		{"200", "0", "Invalid AMT version format"},
-		{"ab.c", "0", "Invalid AMT version"},
-		{"2.0.0", "0", "AMT + ASF + iQST"},
-		{"2.1.0", "1", "ASF + iQST"},
-		{"2.2.0", "2", "iQST"},
-		{"1.1.0", "3", "Unknown"},
-		{"3.0.0", "008", "Invalid SKU"},
-		{"3.0.0", "8", "AMT"},
-		{"4.1.0", "2", "iQST "},
-		{"4.0.0", "4", "ASF "},
-		{"5.0.0", "288", "TPM Home IT "},
-		{"5.0.0", "1088", "WOX "},
-		{"5.0.0", "38", "iQST ASF TPM "},
-		{"5.0.0", "4", "ASF "},
-		{"6.0.0", "2", "iQST "},
-		{"7.0.0", "36864", "L3 Mgt Upgrade"},
-		{"8.0.0", "24584", "AMT Pro AT-p Corporate "},
-		{"10.0.0", "8", "AMT Pro "},
-		{"11.0.0", "16392", "AMT Pro Corporate "},
-		{"15.0.42", "16392", "AMT Pro Corporate "},
-		{"16.1.25", "16400", "Intel Standard Manageability Corporate "},
+func TestDisplayAMTInfoHardware(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.Hardware = true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	// the SMBIOS table is not readable on every test host, the inventory is reported either way
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "Manufacturer\t\t: ")
+	assert.Contains(t, buf.String(), "Serial Number\t\t: ")
+	assert.Contains(t, buf.String(), " MB\n")
+}
+
+func TestDisplayAMTInfoBIOS(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.BIOS = true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "BIOS Version\t\t: ")
+	assert.Contains(t, buf.String(), "ME Firmware Version\t: Version\n")
+	assert.Contains(t, buf.String(), "ME Recovery Version\t: Version\n")
+	assert.Contains(t, buf.String(), "ME Firmware SVN\t\t: Version\n")
+
+	t.Cleanup(func() { mockVersionData = map[string]string{} })
+	mockVersionData = map[string]string{"SVN": ""}
+	buf.Reset()
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "ME Firmware SVN\t\t: not reported\n")
+}
+
+func TestDisplayAMTInfoSecCheck(t *testing.T) {
+	t.Cleanup(func() { mockVersionData = map[string]string{} })
+	mockVersionData = map[string]string{"AMT": "11.8.50", "Build Number": "3425"}
+	f := &flags.Flags{}
+	f.AmtInfo.SecCheck = true
+	t.Run("reports the affected advisories", func(t *testing.T) {
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		assert.Contains(t, buf.String(), "INTEL-SA-00075: not affected\n")
+		assert.Contains(t, buf.String(), "INTEL-SA-00295: affected, fixed in 11.8.77\n")
+	})
+	t.Run("returns FailedReadingConfiguration for an unreadable table", func(t *testing.T) {
+		f.AmtInfo.Advisories = filepath.Join(t.TempDir(), "missing.json")
+		defer func() { f.AmtInfo.Advisories = "" }()
+		lps := setupService(f)
+		assert.Equal(t, utils.FailedReadingConfiguration, lps.DisplayAMTInfo())
+	})
+}
+
+func TestDisplayAMTInfoSystem(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.Sys = true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "OS Name			: ")
+	assert.Contains(t, buf.String(), "RPC Version		: "+utils.ProjectVersion+"\n")
+	assert.Contains(t, buf.String(), "WS-MAN address		: localhost:16992 (")
+
+	t.Run("does not check LMS with the LME driver", func(t *testing.T) {
+		f.Transport = lm.TransportLME
+		defer func() { f.Transport = "" }()
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		assert.Contains(t, buf.String(), "Transport		: lme\n")
+		assert.NotContains(t, buf.String(), "WS-MAN address")
+	})
+}
+
+func TestWsmanTarget(t *testing.T) {
+	f := &flags.Flags{}
+	lps := setupService(f)
+	assert.Equal(t, info.Target{Transport: lm.TransportAuto, Address: "localhost:16992"}, lps.wsmanTarget())
+	f.Transport = lm.TransportLMSTLS
+	assert.Equal(t, info.Target{Transport: lm.TransportLMSTLS, Address: "localhost:16993"}, lps.wsmanTarget())
+
+	f.Transport = lm.TransportLMS
+	f.LMSAddress, f.LMSPort = "127.0.0.1", "16993"
+	assert.Equal(t, info.Target{Transport: lm.TransportLMS, Address: "127.0.0.1:16993"}, lps.wsmanTarget())
+
+	f.Remote = flags.RemoteFlags{Host: "amt.example.com", TLS: true}
+	assert.Equal(t, info.Target{Transport: info.TransportRemote, Address: "amt.example.com:16993"}, lps.wsmanTarget())
+}
+
+func TestDisplayAMTInfoModes(t *testing.T) {
+	f := &flags.Flags{}
+	f.AmtInfo.Modes = true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "Remote Configuration\t: enabled\n")
+	assert.Contains(t, buf.String(), "Provisioning TLS Mode\t: PKI\n")
+	// the mock reports no AMT SKU and an activated device
+	assert.Contains(t, buf.String(), "CCM Activation\t\t: not allowed (the firmware has no AMT or Standard Manageability; the device is activated in admin control mode)\n")
+	assert.NotContains(t, buf.String(), "Version\t")
+}
+
+func TestDisplayAMTInfoCache(t *testing.T) {
+	dir := t.TempDir()
+	orig := infoCacheDir
+	defer func() { infoCacheDir = orig }()
+	infoCacheDir = func() (string, error) { return dir, nil }
+
+	run := func(cache flags.InfoCacheFlags) map[string]interface{} {
+		f := &flags.Flags{}
+		f.AmtInfo.UUID = true
+		f.InfoCache = cache
+		f.JsonOutput = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		var document map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+		return document
 	}
+	t.Run("the first run reads the MEI", func(t *testing.T) {
+		assert.NotContains(t, run(flags.InfoCacheFlags{TTL: time.Hour}), "cacheAge")
+	})
+	t.Run("the next run reports the cache age", func(t *testing.T) {
+		document := run(flags.InfoCacheFlags{TTL: time.Hour})
+		assert.Equal(t, float64(0), document["cacheAge"])
+		assert.Equal(t, mockUUID, document["uuid"])
+	})
+	t.Run("-nocache reads the MEI", func(t *testing.T) {
+		assert.NotContains(t, run(flags.InfoCacheFlags{NoCache: true, TTL: time.Hour}), "cacheAge")
+	})
+	t.Run("a TTL of 0 turns the cache off", func(t *testing.T) {
+		assert.NotContains(t, run(flags.InfoCacheFlags{}), "cacheAge")
+	})
+}
 
-	for _, tc := range testCases {
-		got := DecodeAMT(tc.version, tc.SKU)
-		if got != tc.want {
-			t.Errorf("DecodeAMT(%q, %q) = %v; want %v", tc.version, tc.SKU, got, tc.want)
-		}
+func TestDisplayAMTInfoIPv6(t *testing.T) {
+	origSettings, origNet := mockLANInterfaceSettings, info.HostNet
+	defer func() { mockLANInterfaceSettings, info.HostNet = origSettings, origNet }()
+	mockLANInterfaceSettings = amt2.InterfaceSettings{MACAddress: "0a:0b:0c:0d:0e:0f", IPAddress: "192.168.1.7"}
+	info.HostNet = flags.NetEnumerator{
+		Interfaces: func() ([]net.Interface, error) {
+			return []net.Interface{
+				{Name: "eth0", HardwareAddr: net.HardwareAddr{0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}},
+				{Name: "eth1", HardwareAddr: net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}},
+			}, nil
+		},
+		InterfaceAddrs: func(i *net.Interface) ([]net.Addr, error) {
+			if i.Name != "eth0" {
+				return []net.Addr{&net.IPNet{IP: net.ParseIP("2001:db8::99"), Mask: net.CIDRMask(64, 128)}}, nil
+			}
+			return []net.Addr{
+				&net.IPNet{IP: net.ParseIP("192.168.1.7"), Mask: net.CIDRMask(24, 32)},
+				&net.IPNet{IP: net.ParseIP("2001:db8::7"), Mask: net.CIDRMask(64, 128)},
+				&net.IPNet{IP: net.ParseIP("fe80::7"), Mask: net.CIDRMask(64, 128)},
+			}, nil
+		},
 	}
+	f := &flags.Flags{}
+	f.AmtInfo.Lan = true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Contains(t, buf.String(), "IPv6 Address\t\t: 2001:db8::7/64\n")
+	assert.Contains(t, buf.String(), "IPv6 Address\t\t: fe80::7/64\n")
+	assert.NotContains(t, buf.String(), "2001:db8::99")
+}
+
+func TestDisplayAMTInfoCertHashes(t *testing.T) {
+	orig := mockCertHashes
+	defer func() { mockCertHashes = orig }()
+	mockCertHashes = append(append([]amt2.CertHashEntry{}, mockCertHashesDefault...),
+		amt2.CertHashEntry{Name: "Cert 00 Old CA", Algorithm: "SHA1", Hash: "0123"})
+	f := &flags.Flags{}
+	f.AmtInfo.Cert = true
+
+	t.Run("flags deprecated hashes in text", func(t *testing.T) {
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		assert.Contains(t, buf.String(), "Cert 00 Old CA\n   SHA1: 0123\n   WARNING: SHA1 hash of a deprecated CA\n")
+		assert.Less(t, strings.Index(buf.String(), "Cert 00"), strings.Index(buf.String(), "Cert 01"))
+	})
+	t.Run("returns a typed list in json", func(t *testing.T) {
+		f.JsonOutput = true
+		f.AmtInfo.CertWarnOnly = true
+		defer func() { f.JsonOutput, f.AmtInfo.CertWarnOnly = false, false }()
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+		var result struct {
+			CertificateHashes []info.CertHashInfo `json:"certificateHashes"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, []info.CertHashInfo{{
+			CertHashEntry: amt2.CertHashEntry{Name: "Cert 00 Old CA", Algorithm: "SHA1", Hash: "0123"},
+			Deprecated:    true,
+		}}, result.CertificateHashes)
+	})
+}
+
+func TestNewPublicKeyCertInfo(t *testing.T) {
+	t.Run("parses validity period from X509 blob", func(t *testing.T) {
+		tc := getTestCerts()
+		c := publickey.PublicKeyCertificate{
+			InstanceID:      "Intel(r) AMT Certificate: Handle: 5",
+			X509Certificate: base64.StdEncoding.EncodeToString(tc.CaCert.Raw),
+		}
+		info := NewPublicKeyCertInfo(c)
+		assert.Equal(t, c, info.PublicKeyCertificate)
+		assert.WithinDuration(t, tc.CaCert.NotAfter, info.NotAfter, time.Second)
+		assert.WithinDuration(t, tc.CaCert.NotBefore, info.NotBefore, time.Second)
+	})
+	t.Run("leaves validity period empty on bad X509 blob", func(t *testing.T) {
+		info := NewPublicKeyCertInfo(clientCert)
+		assert.True(t, info.NotAfter.IsZero())
+		info = NewPublicKeyCertInfo(publickey.PublicKeyCertificate{X509Certificate: "bm90IGEgY2VydA=="})
+		assert.True(t, info.NotAfter.IsZero())
+	})
 }
@@ -1,10 +1,19 @@
 package local
 
 import (
+	"io"
+	"net"
+	"net/http"
+	"os"
 	internalAMT "rpc/internal/amt"
 	"rpc/internal/config"
 	"rpc/internal/flags"
+	"rpc/internal/lm"
+	"rpc/internal/logging"
+	"rpc/internal/ntp"
+	"rpc/internal/output"
 	"rpc/pkg/utils"
+	"time"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim"
@@ -12,37 +21,82 @@ import (
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/wsman"
 )
 
+var log = logging.For(logging.ModuleLocal)
+
 type ProvisioningService struct {
 	flags            *flags.Flags
 	serverURL        string
 	client           *wsman.Client
 	config           *config.Config
 	amtCommand       internalAMT.Interface
+	newAMTCommand    func() internalAMT.Interface
 	amtMessages      amt.Messages
 	cimMessages      cim.Messages
 	ipsMessages      ips.Messages
 	handlesWithCerts map[string]string
+	ntpQuery         func(server string, timeout time.Duration) (time.Time, error)
+	out              io.Writer
+	// selectTransport picks LMS or the LME driver of rpc for the wsman client, nil keeps
+	// the HTTP transport to serverURL
+	selectTransport func() (*lm.Connection, error)
+	lme             *lm.Connection
+	// username and password are the credentials of the wsman client, it is set up again
+	// with them when rpc switches to the TLS port of LMS
+	username string
+	password string
+	// passwordGenerator generates the passwords of changepassword -generate and activate -generatePassword
+	passwordGenerator utils.PasswordGenerator
 }
 
 func NewProvisioningService(flags *flags.Flags) ProvisioningService {
 	// supports unit testing
 	serverURL := "http://" + utils.LMSAddress + ":" + utils.LMSPort + "/wsman"
+	selectTransport := func() (*lm.Connection, error) { return lm.Select(flags.Transport) }
+	if flags.Transport == lm.TransportLMSTLS {
+		serverURL = localTLSURL
+		selectTransport = nil
+	}
+	if flags.IsRemote() {
+		serverURL = flags.RemoteURL()
+		selectTransport = nil
+	}
 	return ProvisioningService{
-		flags:            flags,
-		client:           nil,
-		serverURL:        serverURL,
-		config:           &flags.LocalConfig,
-		amtCommand:       internalAMT.NewAMTCommand(),
-		amtMessages:      amt.NewMessages(),
-		cimMessages:      cim.NewMessages(),
-		ipsMessages:      ips.NewMessages(),
-		handlesWithCerts: make(map[string]string),
+		flags:             flags,
+		client:            nil,
+		serverURL:         serverURL,
+		config:            &flags.LocalConfig,
+		amtCommand:        internalAMT.NewAMTCommandContext(flags.Context, flags.Timeout),
+		newAMTCommand:     func() internalAMT.Interface { return internalAMT.NewAMTCommandContext(flags.Context, flags.Timeout) },
+		amtMessages:       amt.NewMessages(),
+		cimMessages:       cim.NewMessages(),
+		ipsMessages:       ips.NewMessages(),
+		handlesWithCerts:  make(map[string]string),
+		ntpQuery:          ntp.Query,
+		out:               os.Stdout,
+		selectTransport:   selectTransport,
+		passwordGenerator: utils.NewPasswordGenerator(flags.FIPS),
 	}
 }
 
+// newOutputWriter returns the writer matching the output format selected on the command line
+func (service *ProvisioningService) newOutputWriter() output.OutputWriter {
+	format := output.FormatFromFlags(service.flags.JsonOutput, service.flags.YamlOutput)
+	return output.NewWriterTo(format, service.out)
+}
+
 func ExecuteCommand(flags *flags.Flags) utils.ReturnCode {
+	return ExecuteCommandTo(flags, os.Stdout)
+}
+
+// ExecuteCommandTo runs the command writing its output to out
+func ExecuteCommandTo(flags *flags.Flags, out io.Writer) utils.ReturnCode {
 	rc := utils.Success
 	service := NewProvisioningService(flags)
+	service.out = out
+	defer service.closeTransport()
+	if flags.DryRun {
+		return service.DryRun()
+	}
 	switch flags.Command {
 	case utils.CommandActivate:
 		rc = service.Activate()
@@ -56,13 +110,104 @@ func ExecuteCommand(flags *flags.Flags) utils.ReturnCode {
 	case utils.CommandConfigure:
 		rc = service.Configure()
 		break
+	case utils.CommandMaintenance:
+		rc = service.Maintenance()
+		break
+	case utils.CommandPower:
+		rc = service.Power()
+		break
+	case utils.CommandStatus:
+		rc = service.Status()
+		break
+	case utils.CommandCheckCert:
+		rc = service.CheckCert()
+		break
+	case utils.CommandWSMAN:
+		rc = service.WSMAN()
+		break
+	case utils.CommandBulk:
+		rc = service.Bulk()
+		break
+	case utils.CommandSelfTest:
+		rc = service.SelfTest()
+		break
+	case utils.CommandDiag:
+		rc = service.Diag()
+		break
+	case utils.CommandApply:
+		rc = service.Apply()
+		break
+	case utils.CommandReturnCodes:
+		rc = service.DisplayReturnCodes()
+		break
 	case utils.CommandVersion:
 		rc = service.DisplayVersion()
 		break
+	case utils.CommandHelp:
+		rc = service.DisplayHelp()
+		break
+	case utils.CommandSOL:
+		rc = service.SOL()
+		break
+	case utils.CommandBoot:
+		rc = service.Boot()
+		break
+	}
+	// MEI commands fail with MEITimeout once cancelled, the return code tells why
+	if rc != utils.Success && service.cancelled() {
+		rc = utils.CancelledByUser
 	}
 	return rc
 }
 
 func (service *ProvisioningService) setupWsmanClient(username string, password string) {
+	if service.flags.IsRemote() {
+		service.setupRemoteWsmanClient(password)
+		return
+	}
+	service.username, service.password = username, password
 	service.client = wsman.NewClient(service.serverURL, username, password, true)
+	if service.flags.Transport == lm.TransportLMSTLS {
+		service.client.Transport = &http.Transport{TLSClientConfig: lm.LocalTLSConfig()}
+		return
+	}
+	if service.selectTransport == nil {
+		return
+	}
+	// the transport is selected once, the client is set up again with other credentials
+	if service.lme == nil {
+		connection, err := service.selectTransport()
+		lm.LogSelectError(service.flags.Transport, err)
+		if err != nil && service.flags.Transport != lm.TransportLME || connection.Transport == lm.TransportLMS {
+			// the client keeps its HTTP transport to LMS, also when the LME driver can not
+			// be initialized. With -transport lme the requests fail on the LME driver instead.
+			service.selectTransport = nil
+			return
+		}
+		service.lme = connection
+	}
+	service.client.Transport = &lm.RoundTripper{Connection: service.lme}
+}
+
+// localTLSURL is the WS-MAN interface of the local AMT once TLS is enabled on its local
+// interface
+var localTLSURL = "https://" + net.JoinHostPort(utils.LMSAddress, utils.LMSTLSPort) + "/wsman"
+
+// useLocalTLS switches the wsman client to the TLS port of LMS, AMT no longer answers on
+// the plain port once TLS is enabled on its local interface
+func (service *ProvisioningService) useLocalTLS() {
+	service.closeTransport()
+	service.flags.Transport = lm.TransportLMSTLS
+	service.serverURL = localTLSURL
+	service.selectTransport = nil
+	service.setupWsmanClient(service.username, service.password)
+	log.Infof("TLS is enabled on the local interface, later commands need -transport %s", lm.TransportLMSTLS)
+}
+
+// closeTransport releases the MEI when the wsman client used the LME driver
+func (service *ProvisioningService) closeTransport() {
+	if service.lme != nil {
+		service.lme.Close()
+		service.lme = nil
+	}
 }
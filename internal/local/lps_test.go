@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	amt2 "rpc/internal/amt"
 	"rpc/internal/flags"
+	"rpc/internal/lm"
 	"rpc/pkg/utils"
 	"testing"
 	"time"
@@ -22,13 +23,19 @@ type MockAMT struct{}
 
 var mockStandardErr error = errors.New("yep, it failed")
 
-func (c MockAMT) Initialize() (utils.ReturnCode, error) {
-	return utils.Success, nil
+func (c MockAMT) Initialize() error {
+	return nil
 }
 
 var mockVersionDataErr error = nil
 
+// mockVersionData replaces the value of the keys it has, the others are "Version"
+var mockVersionData = map[string]string{}
+
 func (c MockAMT) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
+	if value, ok := mockVersionData[key]; ok {
+		return value, mockVersionDataErr
+	}
 	return "Version", mockVersionDataErr
 }
 
@@ -42,6 +49,13 @@ var mockControlModeErr error = nil
 
 func (c MockAMT) GetControlMode() (int, error) { return mockControlMode, mockControlModeErr }
 
+var mockOperationalState = amt2.OperationalState{AMTEnabled: true, ProvisioningState: "post-provisioning", ProvisioningMode: "enterprise"}
+var mockOperationalStateErr error = nil
+
+func (c MockAMT) GetOperationalState() (amt2.OperationalState, error) {
+	return mockOperationalState, mockOperationalStateErr
+}
+
 var mockDNSSuffix = "dns.org"
 var mockDNSSuffixErr error = nil
 
@@ -52,6 +66,11 @@ var mockOSDNSSuffixErr error = nil
 
 func (c MockAMT) GetOSDNSSuffix() (string, error) { return mockOSDNSSuffix, mockOSDNSSuffixErr }
 
+var mockOSDNSServers = []string{"8.8.8.8", "4.4.4.4"}
+var mockOSDNSServersErr error = nil
+
+func (c MockAMT) GetOSDNSServers() ([]string, error) { return mockOSDNSServers, mockOSDNSServersErr }
+
 var mockCertHashesDefault = []amt2.CertHashEntry{
 	{
 		Hash:      "ABCDEFG",
@@ -105,6 +124,42 @@ var mockUnprovisionErr error = nil
 
 func (c MockAMT) Unprovision() (int, error) { return mockUnprovisionCode, mockUnprovisionErr }
 
+var mockSetDNSSuffixStatus = 0
+var mockSetDNSSuffixErr error = nil
+var mockSetDNSSuffixValue = ""
+
+func (c MockAMT) SetDNSSuffix(suffix string) (int, error) {
+	mockSetDNSSuffixValue = suffix
+	return mockSetDNSSuffixStatus, mockSetDNSSuffixErr
+}
+
+var mockChangeEnabled = amt2.ChangeEnabled{AMTEnabled: true, TransitionAllowed: true, NewInterfaceVersion: true}
+var mockChangeEnabledErr error = nil
+var mockSetAMTEnabledStatus = 0
+
+func (c MockAMT) GetChangeEnabled() (amt2.ChangeEnabled, error) {
+	return mockChangeEnabled, mockChangeEnabledErr
+}
+
+var mockZeroTouchEnabled = true
+var mockProvisioningTLSMode = "PKI"
+
+func (c MockAMT) GetZeroTouchEnabled() (bool, error) {
+	return mockZeroTouchEnabled, nil
+}
+
+func (c MockAMT) GetProvisioningTLSMode() (string, error) {
+	return mockProvisioningTLSMode, nil
+}
+
+// SetAMTEnabled changes the state read back by GetChangeEnabled when AMT accepts it
+func (c MockAMT) SetAMTEnabled(enabled bool) (int, error) {
+	if mockSetAMTEnabledStatus == 0 {
+		mockChangeEnabled.AMTEnabled = enabled
+	}
+	return mockSetAMTEnabledStatus, nil
+}
+
 type ResponseFuncArray []func(w http.ResponseWriter, r *http.Request)
 
 func setupWsmanResponses(t *testing.T, f *flags.Flags, responses ResponseFuncArray) ProvisioningService {
@@ -152,6 +207,8 @@ func respondStringFunc(t *testing.T, msg string) func(w http.ResponseWriter, r *
 func setupService(f *flags.Flags) ProvisioningService {
 	service := NewProvisioningService(f)
 	service.amtCommand = MockAMT{}
+	service.newAMTCommand = func() amt2.Interface { return MockAMT{} }
+	service.selectTransport = nil
 	return service
 }
 
@@ -199,6 +256,66 @@ func respondBadXML(t *testing.T, w http.ResponseWriter) {
 	assert.Nil(t, err)
 }
 
+type mockLocalManager struct{ closed bool }
+
+func (m *mockLocalManager) Initialize() error      { return nil }
+func (m *mockLocalManager) Connect() error         { return nil }
+func (m *mockLocalManager) Listen()                {}
+func (m *mockLocalManager) Send(data []byte) error { return nil }
+func (m *mockLocalManager) Close() error           { m.closed = true; return nil }
+
+func TestSetupWsmanClientTransport(t *testing.T) {
+	t.Run("LMS keeps the HTTP transport", func(t *testing.T) {
+		lps := setupService(&flags.Flags{})
+		lps.selectTransport = func() (*lm.Connection, error) {
+			return &lm.Connection{Transport: lm.TransportLMS}, nil
+		}
+		lps.setupWsmanClient("admin", "password")
+		assert.IsType(t, &http.Transport{}, lps.client.Transport)
+		assert.Nil(t, lps.selectTransport)
+	})
+	t.Run("LME driver without LMS", func(t *testing.T) {
+		lps := setupService(&flags.Flags{})
+		manager := &mockLocalManager{}
+		selected := 0
+		lps.selectTransport = func() (*lm.Connection, error) {
+			selected++
+			return &lm.Connection{LocalMananger: manager, Transport: lm.TransportLME}, nil
+		}
+		lps.setupWsmanClient("admin", "password")
+		lps.setupWsmanClient("admin", "other")
+		assert.IsType(t, &lm.RoundTripper{}, lps.client.Transport)
+		assert.Equal(t, 1, selected)
+		lps.closeTransport()
+		assert.True(t, manager.closed)
+	})
+	t.Run("LME driver can not be initialized", func(t *testing.T) {
+		lps := setupService(&flags.Flags{})
+		lps.selectTransport = func() (*lm.Connection, error) {
+			return &lm.Connection{Transport: lm.TransportLME}, errors.New("no such device")
+		}
+		lps.setupWsmanClient("admin", "password")
+		assert.IsType(t, &http.Transport{}, lps.client.Transport)
+	})
+	t.Run("-transport lme does not fall back to LMS", func(t *testing.T) {
+		lps := setupService(&flags.Flags{Transport: lm.TransportLME})
+		lps.selectTransport = func() (*lm.Connection, error) {
+			return &lm.Connection{LocalMananger: &mockLocalManager{}, Transport: lm.TransportLME}, errors.New("no such device")
+		}
+		lps.setupWsmanClient("admin", "password")
+		assert.IsType(t, &lm.RoundTripper{}, lps.client.Transport)
+	})
+	t.Run("-transport lms-tls connects to the TLS port of LMS", func(t *testing.T) {
+		lps := NewProvisioningService(&flags.Flags{Transport: lm.TransportLMSTLS})
+		assert.Equal(t, "https://localhost:16993/wsman", lps.serverURL)
+		assert.Nil(t, lps.selectTransport)
+		lps.setupWsmanClient("admin", "password")
+		transport, ok := lps.client.Transport.(*http.Transport)
+		assert.True(t, ok)
+		assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	})
+}
+
 var mockGenerlSettingsResponse = general.Response{}
 
 func respondGeneralSettings(t *testing.T, w http.ResponseWriter) {
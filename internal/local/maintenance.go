@@ -0,0 +1,454 @@
+package local
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"rpc/internal/keyring"
+	"rpc/internal/logging"
+	"rpc/internal/secretstore"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/ethernetport"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+)
+
+// timeout for the NTP query done before pushing time into AMT
+const ntpQueryTimeout = 5 * time.Second
+
+type GetLowAccuracyTimeSynchResponse struct {
+	Body struct {
+		Output struct {
+			Ta0         int64 `xml:"Ta0"`
+			ReturnValue int   `xml:"ReturnValue"`
+		} `xml:"GetLowAccuracyTimeSynch_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type SetHighAccuracyTimeSynchResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetHighAccuracyTimeSynch_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type SetAdminACLEntryExResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetAdminAclEntryEx_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type EthernetPortSettingsPullResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		PullResponse struct {
+			Items []EthernetPortSettingsItem `xml:"Items>AMT_EthernetPortSettings"`
+		}
+	}
+}
+
+type EthernetPortSettingsPutResponse struct {
+	XMLName xml.Name `xml:"Envelope"`
+	Body    struct {
+		Settings EthernetPortSettingsItem `xml:"AMT_EthernetPortSettings"`
+	}
+}
+
+type EthernetPortSettingsItem struct {
+	ElementName    string
+	InstanceID     string
+	SharedMAC      bool
+	MACAddress     string
+	LinkIsUp       bool
+	SharedStaticIp bool
+	IpSyncEnabled  bool
+	DHCPEnabled    bool
+	IPAddress      string
+	SubnetMask     string
+	DefaultGateway string
+	PrimaryDNS     string
+	SecondaryDNS   string
+}
+
+func (service *ProvisioningService) Maintenance() utils.ReturnCode {
+	service.setupWsmanClient("admin", service.flags.Password)
+	switch service.flags.SubCommand {
+	case utils.SubCommandSyncClock:
+		return service.SyncClock()
+	case utils.SubCommandSyncDNS:
+		return service.SyncDNS()
+	case utils.SubCommandSyncWifi:
+		return service.SyncWifi()
+	case utils.SubCommandChangePassword:
+		return service.ChangePassword()
+	default:
+	}
+	return utils.IncorrectCommandLineParameters
+}
+
+// SyncClock sets the AMT clock from the host OS clock, or from an NTP server with -ntp.
+// The difference between the clocks is reported before and after the sync, a clock within
+// -maxSkew is left as it is. AMT keeps UTC, a difference of the UTC offset or DST shift of
+// the -tz time zone is reported as a clock that was set to local time.
+func (service *ProvisioningService) SyncClock() utils.ReturnCode {
+	source, offset, err := service.clockReference()
+	if err != nil {
+		log.Errorf("unable to query ntp server %s: %s", service.flags.NTPServer, err)
+		return utils.SyncClockFailed
+	}
+	zone := service.flags.SyncClock.TimeZone
+	if zone == nil {
+		zone = time.Local
+	}
+
+	ta0, rc := service.amtClock()
+	if rc != utils.Success {
+		return utils.SyncClockFailed
+	}
+	amtTime := time.Unix(ta0, 0)
+	reference := time.Now().Add(offset)
+	skew := clockSkew(amtTime, reference)
+	log.Debugf("AMT time %s", amtTime.UTC().Format(time.RFC3339))
+	log.Infof("AMT clock differs from %s by %s", source, skew)
+	warning := clockZoneWarning(skew, zone, reference)
+	if warning != "" {
+		log.Warn(warning)
+	}
+
+	w := service.newOutputWriter()
+	w.Field("source", "Time source", source)
+	w.Field("timeZone", "Time zone", zone.String())
+	w.Field("amtTime", "AMT time", amtTime.In(zone).Format(time.RFC3339))
+	w.Field("referenceTime", "Reference time", reference.In(zone).Format(time.RFC3339))
+	w.Field("skewSeconds", "Skew (s)", int64(skew/time.Second))
+	if warning != "" {
+		w.Field("warning", "Warning", warning)
+	}
+	if maxSkew := service.flags.SyncClock.MaxSkew; maxSkew > 0 && absDuration(skew) <= maxSkew {
+		w.Field("synced", "Synced", false)
+		if err := w.Flush(); err != nil {
+			log.Error(err)
+		}
+		log.Infof("Status: AMT clock is within %s of %s, not synced", maxSkew, source)
+		return utils.Success
+	}
+
+	tm1 := time.Now().Add(offset).Unix()
+	var highAccuracyRsp SetHighAccuracyTimeSynchResponse
+	tm2 := time.Now().Add(offset).Unix()
+	rc = service.PostAndUnmarshal(service.amtMessages.TimeSynchronizationService.SetHighAccuracyTimeSynch(ta0, tm1, tm2), &highAccuracyRsp)
+	if rc != utils.Success {
+		return utils.SyncClockFailed
+	}
+	if highAccuracyRsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("SetHighAccuracyTimeSynch_OUTPUT.ReturnValue: %d", highAccuracyRsp.Body.Output.ReturnValue)
+		return utils.SyncClockFailed
+	}
+
+	// the clock is read back to report the correction AMT applied
+	ta0, rc = service.amtClock()
+	if rc != utils.Success {
+		return utils.SyncClockFailed
+	}
+	skewAfter := clockSkew(time.Unix(ta0, 0), time.Now().Add(offset))
+	w.Field("synced", "Synced", true)
+	w.Field("correctionSeconds", "Correction (s)", int64((skew-skewAfter)/time.Second))
+	w.Field("skewAfterSeconds", "Skew after sync (s)", int64(skewAfter/time.Second))
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	if absDuration(skewAfter) > syncedClockTolerance {
+		log.Errorf("AMT clock still differs from %s by %s after the sync", source, skewAfter)
+		return utils.SyncClockFailed
+	}
+	log.Info("Status: AMT clock synchronized with ", source)
+	return utils.Success
+}
+
+// syncedClockTolerance is the difference to the reference a synced AMT clock may keep, AMT
+// reports its clock in whole seconds
+const syncedClockTolerance = 2 * time.Second
+
+// clockReference returns the source of the time AMT is synced to and its offset to the
+// host clock, the NTP time is tracked with the monotonic host clock
+func (service *ProvisioningService) clockReference() (string, time.Duration, error) {
+	if service.flags.NTPServer == "" {
+		return "host", 0, nil
+	}
+	ntpTime, err := service.ntpQuery(service.flags.NTPServer, ntpQueryTimeout)
+	if err != nil {
+		return "", 0, err
+	}
+	offset := time.Until(ntpTime)
+	log.Infof("ntp server %s reports %s (host clock offset %s)", service.flags.NTPServer, ntpTime.UTC().Format(time.RFC3339), offset)
+	return service.flags.NTPServer, offset, nil
+}
+
+// amtClock returns the AMT clock in seconds since the epoch
+func (service *ProvisioningService) amtClock() (int64, utils.ReturnCode) {
+	var lowAccuracyRsp GetLowAccuracyTimeSynchResponse
+	rc := service.PostAndUnmarshal(service.amtMessages.TimeSynchronizationService.GetLowAccuracyTimeSynch(), &lowAccuracyRsp)
+	if rc != utils.Success {
+		return 0, rc
+	}
+	if lowAccuracyRsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("GetLowAccuracyTimeSynch_OUTPUT.ReturnValue: %d", lowAccuracyRsp.Body.Output.ReturnValue)
+		return 0, utils.SyncClockFailed
+	}
+	return lowAccuracyRsp.Body.Output.Ta0, utils.Success
+}
+
+// clockSkew is how far the AMT clock is ahead of the reference, in the whole seconds AMT
+// keeps
+func clockSkew(amtTime time.Time, reference time.Time) time.Duration {
+	return amtTime.Sub(reference).Round(time.Second)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// clockZoneWarning tells when the skew matches the UTC offset of the zone, an AMT clock
+// that was set to local time, or its DST shift, a DST change applied to the AMT clock
+func clockZoneWarning(skew time.Duration, zone *time.Location, now time.Time) string {
+	const tolerance = time.Minute
+	if absDuration(skew) < tolerance {
+		return ""
+	}
+	_, offset := now.In(zone).Zone()
+	utcOffset := time.Duration(offset) * time.Second
+	if utcOffset != 0 && absDuration(skew-utcOffset) <= tolerance {
+		return fmt.Sprintf("the AMT clock is off by %s, the UTC offset of %s: it was likely set to local time, AMT keeps UTC", skew, zone)
+	}
+	// the zone observes DST when its offsets in January and July differ
+	_, january := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, zone).Zone()
+	_, july := time.Date(now.Year(), time.July, 1, 0, 0, 0, 0, zone).Zone()
+	dstShift := absDuration(time.Duration(july-january) * time.Second)
+	if dstShift != 0 && absDuration(absDuration(skew)-dstShift) <= tolerance {
+		return fmt.Sprintf("the AMT clock is off by %s, the DST shift of %s: a DST change was likely applied to it, AMT keeps UTC", skew, zone)
+	}
+	return ""
+}
+
+// SyncWifi replaces the wifi profiles in AMT with the profiles of the host OS, read into
+// the wifi configurations by the flags
+func (service *ProvisioningService) SyncWifi() utils.ReturnCode {
+	// the agent syncs the profiles on every run, there is nothing to sync without WiFi
+	if service.lacksWiFi(log.Warnf) {
+		log.Info("Status: wifi profiles not synced")
+		return utils.Success
+	}
+	for _, wifiConfig := range service.flags.LocalConfig.WifiConfigs {
+		log.Debugf("syncing wifi profile %s for ssid %s", wifiConfig.ProfileName, wifiConfig.SSID)
+	}
+	rc := service.AddWifiSettings()
+	if rc == utils.Success {
+		log.Infof("Status: %d wifi profiles synced to AMT", len(service.flags.LocalConfig.WifiConfigs))
+	}
+	return rc
+}
+
+// SyncDNS sets the AMT domain name to the DNS suffix and, when the wired
+// port uses a static IP, the DNS servers of the wired port
+func (service *ProvisioningService) SyncDNS() utils.ReturnCode {
+	generalSettings, err := service.GetGeneralSettings()
+	if err != nil {
+		log.Error("unable to read general settings: ", err)
+		return utils.SyncDNSFailed
+	}
+	settings := generalSettings.Body.AMTGeneralSettings
+	if settings.DomainName != service.flags.DNS {
+		log.Infof("updating AMT DNS suffix from '%s' to '%s'", settings.DomainName, service.flags.DNS)
+		settings.DomainName = service.flags.DNS
+		var putRsp general.Response
+		if rc := service.PostAndUnmarshal(service.amtMessages.GeneralSettings.Put(settings), &putRsp); rc != utils.Success {
+			return utils.SyncDNSFailed
+		}
+		if putRsp.Body.AMTGeneralSettings.DomainName != service.flags.DNS {
+			log.Error("AMT did not accept the DNS suffix")
+			return utils.SyncDNSFailed
+		}
+	}
+
+	if service.flags.IpConfiguration.PrimaryDns == "" {
+		log.Info("Status: AMT DNS suffix synchronized")
+		return utils.Success
+	}
+	var pullRsp EthernetPortSettingsPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.EthernetPortSettings.Enumerate,
+		service.amtMessages.EthernetPortSettings.Pull,
+		&pullRsp,
+	)
+	if rc != utils.Success {
+		return utils.SyncDNSFailed
+	}
+	for _, port := range pullRsp.Body.PullResponse.Items {
+		// the wired port is instance 0, wireless DNS settings come from the wifi profiles
+		if !strings.HasSuffix(port.InstanceID, " 0") {
+			continue
+		}
+		if port.DHCPEnabled {
+			log.Info("wired port uses DHCP, DNS servers are provided by the DHCP server")
+			break
+		}
+		if port.PrimaryDNS == service.flags.IpConfiguration.PrimaryDns &&
+			port.SecondaryDNS == service.flags.IpConfiguration.SecondaryDns {
+			break
+		}
+		settings := ethernetport.EthernetPortSettings{
+			SharedMAC:      port.SharedMAC,
+			SharedStaticIp: port.SharedStaticIp,
+			IpSyncEnabled:  port.IpSyncEnabled,
+			DHCPEnabled:    port.DHCPEnabled,
+			IPAddress:      port.IPAddress,
+			SubnetMask:     port.SubnetMask,
+			DefaultGateway: port.DefaultGateway,
+			PrimaryDNS:     service.flags.IpConfiguration.PrimaryDns,
+			SecondaryDNS:   service.flags.IpConfiguration.SecondaryDns,
+		}
+		settings.ElementName = port.ElementName
+		settings.InstanceID = port.InstanceID
+		var putRsp EthernetPortSettingsPutResponse
+		if rc := service.PostAndUnmarshal(service.amtMessages.EthernetPortSettings.Put(settings), &putRsp); rc != utils.Success {
+			return utils.SyncDNSFailed
+		}
+		if putRsp.Body.Settings.PrimaryDNS != settings.PrimaryDNS {
+			log.Error("AMT did not accept the DNS servers")
+			return utils.SyncDNSFailed
+		}
+		break
+	}
+	log.Info("Status: AMT DNS suffix and DNS servers synchronized")
+	return utils.Success
+}
+
+// ChangePassword sets a generated admin password in AMT. The password is saved to the
+// output file, keyring or secret store before AMT is changed, so it is not lost if rpc is interrupted,
+// and the saved copy is reverted if AMT rejects it.
+func (service *ProvisioningService) ChangePassword() utils.ReturnCode {
+	opts := service.flags.ChangePassword
+	password, err := service.generatePassword(opts.Length, opts.NoSymbols)
+	if err != nil {
+		log.Error("unable to generate password: ", err)
+		return utils.ChangePasswordFailed
+	}
+	generalSettings, err := service.GetGeneralSettings()
+	if err != nil {
+		log.Error("unable to read general settings: ", err)
+		return utils.ChangePasswordFailed
+	}
+	realm := generalSettings.Body.AMTGeneralSettings.DigestRealm
+
+	revert, err := service.savePassword(password)
+	if err != nil {
+		log.Error("unable to save the new password: ", err)
+		return utils.ChangePasswordFailed
+	}
+	// AMT stores the password as the HTTP digest A1 hash of the admin user
+	digest := md5.Sum([]byte(fmt.Sprintf("admin:%s:%s", realm, password)))
+	var rsp SetAdminACLEntryExResponse
+	rc := service.PostAndUnmarshal(
+		service.amtMessages.AuthorizationService.SetAdminACLEntryEx("admin", base64.StdEncoding.EncodeToString(digest[:])),
+		&rsp)
+	if rc == utils.Success && rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("SetAdminAclEntryEx_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		rc = utils.ChangePasswordFailed
+	}
+	if rc != utils.Success {
+		if err := revert(); err != nil {
+			log.Warn("unable to revert the saved password: ", err)
+		}
+		return utils.ChangePasswordFailed
+	}
+	service.flags.Password = password
+	if opts.OutFile == "" && !opts.Keyring && opts.Vault == "" {
+		w := service.newOutputWriter()
+		w.Field("password", "New AMT Password", password)
+		w.Flush()
+	}
+	log.Warn("the new password is only set in AMT, update the device password on the server")
+	log.Info("Status: AMT password changed")
+	return utils.Success
+}
+
+// generatePassword returns a password of the passwordGenerator, it is redacted in the log
+func (service *ProvisioningService) generatePassword(length int, noSymbols bool) (string, error) {
+	log.Info("generating the AMT password with ", service.passwordGenerator.RNG())
+	password, err := service.passwordGenerator.Generate(length, noSymbols)
+	if err != nil {
+		return "", err
+	}
+	logging.Redact(password)
+	return password, nil
+}
+
+// savePassword writes the password to the output file or keyring and returns
+// the function that restores what was there before
+func (service *ProvisioningService) savePassword(password string) (func() error, error) {
+	opts := service.flags.ChangePassword
+	if opts.OutFile != "" {
+		if _, err := os.Stat(opts.OutFile); err == nil {
+			return nil, fmt.Errorf("%s already exists", opts.OutFile)
+		}
+		if err := os.WriteFile(opts.OutFile, []byte(password+"\n"), 0600); err != nil {
+			return nil, err
+		}
+		return func() error { return os.Remove(opts.OutFile) }, nil
+	}
+	if opts.Vault != "" {
+		return savePasswordToStore(opts.Vault, opts.VaultToken, password)
+	}
+	if opts.Keyring {
+		previous, err := keyring.Get(keyring.Service, keyring.Account)
+		if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return nil, err
+		}
+		logging.Redact(previous)
+		if err := keyring.Set(keyring.Service, keyring.Account, password); err != nil {
+			return nil, err
+		}
+		return func() error {
+			if previous == "" {
+				return keyring.Delete(keyring.Service, keyring.Account)
+			}
+			return keyring.Set(keyring.Service, keyring.Account, previous)
+		}, nil
+	}
+	return func() error { return nil }, nil
+}
+
+// savePasswordToStore writes the password to the secret store. The store checks that the
+// password read before is still the current one, the revert writes it back the same way.
+func savePasswordToStore(storeURL string, token string, password string) (func() error, error) {
+	store, err := secretstore.Open(storeURL, token)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := store.Read()
+	if err != nil && !errors.Is(err, secretstore.ErrNotFound) {
+		return nil, err
+	}
+	logging.Redact(previous)
+	if err := store.Write(password); err != nil {
+		return nil, err
+	}
+	log.Info("the new password is saved to ", secretstore.Redact(storeURL))
+	return func() error {
+		if previous == "" {
+			return store.Delete()
+		}
+		return store.Write(previous)
+	}, nil
+}
@@ -0,0 +1,417 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/wifiportconfiguration"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockNtpQuery(t time.Time, err error) func(string, time.Duration) (time.Time, error) {
+	return func(string, time.Duration) (time.Time, error) {
+		return t, err
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	f := &flags.Flags{}
+	t.Run("returns IncorrectCommandLineParameters for unknown subcommand", func(t *testing.T) {
+		f.SubCommand = "nope"
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		assert.Equal(t, utils.IncorrectCommandLineParameters, lps.Maintenance())
+	})
+}
+
+func TestSyncClock(t *testing.T) {
+	f := &flags.Flags{}
+	f.SubCommand = utils.SubCommandSyncClock
+	f.NTPServer = "pool.ntp.org"
+	lowAccuracyRsp := GetLowAccuracyTimeSynchResponse{}
+	lowAccuracyRsp.Body.Output.Ta0 = time.Now().Add(-time.Hour).Unix()
+
+	syncedRsp := GetLowAccuracyTimeSynchResponse{}
+	syncedRsp.Body.Output.Ta0 = time.Now().Unix()
+
+	t.Run("returns Success on happy path", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondMsgFunc(t, SetHighAccuracyTimeSynchResponse{}),
+			respondMsgFunc(t, syncedRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.Success, lps.Maintenance())
+	})
+	t.Run("reports the skew and the correction", func(t *testing.T) {
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondMsgFunc(t, SetHighAccuracyTimeSynchResponse{}),
+			respondMsgFunc(t, syncedRsp),
+		}
+		var out bytes.Buffer
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.out = &out
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.Success, lps.SyncClock())
+		var result map[string]interface{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		assert.Equal(t, "pool.ntp.org", result["source"])
+		assert.InDelta(t, -3600, result["skewSeconds"], 2)
+		assert.InDelta(t, -3600, result["correctionSeconds"], 2)
+		assert.InDelta(t, 0, result["skewAfterSeconds"], 2)
+		assert.Equal(t, true, result["synced"])
+	})
+	t.Run("leaves a clock within maxSkew as it is", func(t *testing.T) {
+		f.SyncClock.MaxSkew = 2 * time.Hour
+		defer func() { f.SyncClock.MaxSkew = 0 }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, lowAccuracyRsp)})
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.Success, lps.SyncClock())
+	})
+	t.Run("syncs to the host clock without an ntp server", func(t *testing.T) {
+		f.NTPServer = ""
+		defer func() { f.NTPServer = "pool.ntp.org" }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondMsgFunc(t, SetHighAccuracyTimeSynchResponse{}),
+			respondMsgFunc(t, syncedRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.ntpQuery = mockNtpQuery(time.Time{}, errors.New("not queried"))
+		assert.Equal(t, utils.Success, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed when the clock is still off after the sync", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondMsgFunc(t, SetHighAccuracyTimeSynchResponse{}),
+			respondMsgFunc(t, lowAccuracyRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed on ntp failure", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		lps.ntpQuery = mockNtpQuery(time.Time{}, errors.New("timeout"))
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed on GetLowAccuracyTimeSynch failure", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed on GetLowAccuracyTimeSynch ReturnValue", func(t *testing.T) {
+		badRsp := lowAccuracyRsp
+		badRsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, badRsp)})
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed on SetHighAccuracyTimeSynch failure", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondServerErrFunc(),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+	t.Run("returns SyncClockFailed on SetHighAccuracyTimeSynch ReturnValue", func(t *testing.T) {
+		highAccuracyRsp := SetHighAccuracyTimeSynchResponse{}
+		highAccuracyRsp.Body.Output.ReturnValue = 2
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, lowAccuracyRsp),
+			respondMsgFunc(t, highAccuracyRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.ntpQuery = mockNtpQuery(time.Now(), nil)
+		assert.Equal(t, utils.SyncClockFailed, lps.SyncClock())
+	})
+}
+
+func TestClockZoneWarning(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	summer := time.Date(2024, time.July, 15, 12, 0, 0, 0, time.UTC)
+	winter := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "", clockZoneWarning(30*time.Second, berlin, summer))
+	assert.Equal(t, "", clockZoneWarning(5*time.Hour, berlin, summer))
+	assert.Contains(t, clockZoneWarning(2*time.Hour, berlin, summer), "set to local time")
+	assert.Contains(t, clockZoneWarning(time.Hour, berlin, winter), "set to local time")
+	assert.Contains(t, clockZoneWarning(-time.Hour, berlin, summer), "DST change")
+	assert.Equal(t, "", clockZoneWarning(time.Hour, time.UTC, summer))
+}
+
+func TestSyncDNS(t *testing.T) {
+	f := &flags.Flags{}
+	f.SubCommand = utils.SubCommandSyncDNS
+	f.DNS = "corp.example.com"
+
+	generalRsp := general.Response{}
+	generalRsp.Body.AMTGeneralSettings.DomainName = "old.example.com"
+	updatedRsp := general.Response{}
+	updatedRsp.Body.AMTGeneralSettings.DomainName = f.DNS
+
+	staticPort := EthernetPortSettingsItem{
+		InstanceID: "Intel(r) AMT Ethernet Port Settings 0",
+		PrimaryDNS: "1.1.1.1",
+	}
+	pullRsp := EthernetPortSettingsPullResponse{}
+	pullRsp.Body.PullResponse.Items = []EthernetPortSettingsItem{staticPort}
+	putRsp := EthernetPortSettingsPutResponse{}
+	putRsp.Body.Settings.PrimaryDNS = "8.8.8.8"
+
+	t.Run("returns Success updating the DNS suffix only", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, updatedRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.Maintenance())
+	})
+	t.Run("returns Success updating DNS servers of a static wired port", func(t *testing.T) {
+		f.IpConfiguration.PrimaryDns = "8.8.8.8"
+		defer func() { f.IpConfiguration.PrimaryDns = "" }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, updatedRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, pullRsp),
+			respondMsgFunc(t, putRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.SyncDNS())
+	})
+	t.Run("returns Success leaving DNS servers of a DHCP wired port", func(t *testing.T) {
+		f.IpConfiguration.PrimaryDns = "8.8.8.8"
+		defer func() { f.IpConfiguration.PrimaryDns = "" }()
+		dhcpRsp := pullRsp
+		dhcpRsp.Body.PullResponse.Items = []EthernetPortSettingsItem{staticPort}
+		dhcpRsp.Body.PullResponse.Items[0].DHCPEnabled = true
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, updatedRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, dhcpRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.SyncDNS())
+	})
+	t.Run("returns SyncDNSFailed on GetGeneralSettings failure", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.SyncDNSFailed, lps.SyncDNS())
+	})
+	t.Run("returns SyncDNSFailed when AMT rejects the DNS suffix", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, generalRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.SyncDNSFailed, lps.SyncDNS())
+	})
+	t.Run("returns SyncDNSFailed when AMT rejects the DNS servers", func(t *testing.T) {
+		f.IpConfiguration.PrimaryDns = "8.8.8.8"
+		defer func() { f.IpConfiguration.PrimaryDns = "" }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, updatedRsp),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, pullRsp),
+			respondMsgFunc(t, EthernetPortSettingsPutResponse{}),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.SyncDNSFailed, lps.SyncDNS())
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	f := &flags.Flags{}
+	f.SubCommand = utils.SubCommandChangePassword
+	f.Password = "P@ssw0rd"
+	f.ChangePassword = flags.ChangePasswordFlags{Generate: true, Length: 12, NoSymbols: true}
+
+	generalRsp := general.Response{}
+	generalRsp.Body.AMTGeneralSettings.DigestRealm = "Digest:A3829B3827DE4D33D4449B366831FD01"
+	okRsp := SetAdminACLEntryExResponse{}
+	failRsp := SetAdminACLEntryExResponse{}
+	failRsp.Body.Output.ReturnValue = 1
+
+	t.Run("returns Success and prints the generated password", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, okRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.Maintenance())
+		assert.Contains(t, buf.String(), "New AMT Password")
+		assert.Len(t, lps.flags.Password, 12)
+		f.Password = "P@ssw0rd"
+	})
+	t.Run("writes the generated password to the output file", func(t *testing.T) {
+		f.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		defer func() { f.ChangePassword.OutFile = "" }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, okRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ChangePassword())
+		assert.Empty(t, buf.String())
+		data, err := os.ReadFile(f.ChangePassword.OutFile)
+		assert.NoError(t, err)
+		assert.Equal(t, f.Password+"\n", string(data))
+		info, err := os.Stat(f.ChangePassword.OutFile)
+		assert.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+		f.Password = "P@ssw0rd"
+	})
+	t.Run("removes the output file when AMT rejects the password", func(t *testing.T) {
+		f.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		defer func() { f.ChangePassword.OutFile = "" }()
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, failRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.ChangePasswordFailed, lps.ChangePassword())
+		_, err := os.Stat(f.ChangePassword.OutFile)
+		assert.True(t, os.IsNotExist(err))
+		assert.Equal(t, "P@ssw0rd", f.Password)
+	})
+	t.Run("returns ChangePasswordFailed when the output file exists", func(t *testing.T) {
+		f.ChangePassword.OutFile = filepath.Join(t.TempDir(), "amt.pwd")
+		defer func() { f.ChangePassword.OutFile = "" }()
+		assert.NoError(t, os.WriteFile(f.ChangePassword.OutFile, []byte("old"), 0600))
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.ChangePasswordFailed, lps.ChangePassword())
+		data, _ := os.ReadFile(f.ChangePassword.OutFile)
+		assert.Equal(t, "old", string(data))
+	})
+	t.Run("writes the generated password to vault and reverts it when AMT rejects it", func(t *testing.T) {
+		var passwords []string
+		vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/v1/sys/internal/ui/mounts/"):
+				fmt.Fprint(w, `{"data":{"path":"secret/","type":"kv","options":{"version":"2"}}}`)
+			case r.Method == http.MethodGet:
+				fmt.Fprintf(w, `{"data":{"data":{"password":%q},"metadata":{"version":%d}}}`, passwords[len(passwords)-1], len(passwords))
+			case r.Method == http.MethodPost:
+				var request struct {
+					Data map[string]string `json:"data"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&request)
+				passwords = append(passwords, request.Data["password"])
+				fmt.Fprintf(w, `{"data":{"version":%d}}`, len(passwords))
+			}
+		}))
+		defer vault.Close()
+		f.ChangePassword.Vault = "vault+http://" + strings.TrimPrefix(vault.URL, "http://") + "/secret/amt/device1"
+		f.ChangePassword.VaultToken = "s.token"
+		defer func() { f.ChangePassword.Vault, f.ChangePassword.VaultToken = "", "" }()
+
+		passwords = []string{"P@ssw0rd"}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, okRsp),
+		})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ChangePassword())
+		assert.Empty(t, buf.String())
+		assert.Equal(t, []string{"P@ssw0rd", f.Password}, passwords)
+		f.Password = "P@ssw0rd"
+
+		passwords = []string{"P@ssw0rd"}
+		lps = setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, failRsp),
+		})
+		assert.Equal(t, utils.ChangePasswordFailed, lps.ChangePassword())
+		assert.Len(t, passwords, 3)
+		assert.Equal(t, "P@ssw0rd", passwords[2])
+	})
+	t.Run("sets the password of the injected generator", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondMsgFunc(t, generalRsp),
+			respondMsgFunc(t, okRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		lps.passwordGenerator = stubPasswordGenerator{password: "Inj3cted!Passw0rd"}
+		lps.out = io.Discard
+		assert.Equal(t, utils.Success, lps.ChangePassword())
+		assert.Equal(t, "Inj3cted!Passw0rd", lps.flags.Password)
+		f.Password = "P@ssw0rd"
+	})
+	t.Run("returns ChangePasswordFailed when the password can not be generated", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, generalRsp)})
+		lps.passwordGenerator = stubPasswordGenerator{err: utils.ErrFIPSUnavailable}
+		assert.Equal(t, utils.ChangePasswordFailed, lps.ChangePassword())
+		assert.Equal(t, "P@ssw0rd", lps.flags.Password)
+	})
+	t.Run("returns ChangePasswordFailed on general settings error", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.ChangePasswordFailed, lps.ChangePassword())
+	})
+}
+
+func TestSyncWifi(t *testing.T) {
+	f := &flags.Flags{}
+	f.SubCommand = utils.SubCommandSyncWifi
+	f.LocalConfig.WifiConfigs = append(f.LocalConfig.WifiConfigs, wifiCfgWPA, wifiCfgWPA2)
+	pcsRsp := wifiportconfiguration.Response{}
+	pcsRsp.Body.WiFiPortConfigurationService.LocalProfileSynchronizationEnabled = 1
+
+	t.Run("returns Success replacing the AMT profiles", func(t *testing.T) {
+		var bodies []string
+		addFunc := func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			respondMsgFunc(t, wifiportconfiguration.AddWiFiSettingsResponse{})(w, r)
+		}
+		rfa := append(
+			emptyGetWifiIeee8021xCerts(t),
+			ResponseFuncArray{
+				respondMsgFunc(t, common.EnumerationResponse{}),
+				respondMsgFunc(t, wifi.PullResponseEnvelope{}),
+				respondMsgFunc(t, pcsRsp),
+				respondMsgFunc(t, wifi.RequestStateChangeResponse{}),
+				addFunc,
+				addFunc,
+			}...,
+		)
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.Maintenance())
+		assert.Len(t, bodies, 2)
+		assert.Contains(t, bodies[0], "<q:ElementName>"+wifiCfgWPA.ProfileName+"</q:ElementName>")
+		assert.Contains(t, bodies[1], "<q:ElementName>"+wifiCfgWPA2.ProfileName+"</q:ElementName>")
+	})
+	t.Run("returns the error of the wifi configuration", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.NotEqual(t, utils.Success, lps.SyncWifi())
+	})
+}
@@ -0,0 +1,144 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"encoding/xml"
+	"sync/atomic"
+)
+
+// the resource URIs of the AMT and IPS classes without the class name
+const (
+	amtResourceURIBase = "http://intel.com/wbem/wscim/1/amt-schema/1/"
+	ipsResourceURIBase = "http://intel.com/wbem/wscim/1/ips-schema/1/"
+)
+
+// the WS-Enumeration and WS-Transfer actions of the messages built here
+const (
+	enumerateAction = enumerationNamespace + "/Enumerate"
+	pullAction      = enumerationNamespace + "/Pull"
+	createAction    = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Create"
+	deleteAction    = "http://schemas.xmlsoap.org/ws/2004/09/transfer/Delete"
+)
+
+const enumerationNamespace = "http://schemas.xmlsoap.org/ws/2004/09/enumeration"
+
+// maxPullElements and maxPullCharacters bound a Pull response like go-wsman-messages does
+const (
+	maxPullElements   = 999
+	maxPullCharacters = 99999
+)
+
+// wsmanMessageID numbers the messages built by wsmanMessage
+var wsmanMessageID atomic.Int64
+
+// wsmanEnvelope is a WS-MAN request in the form go-wsman-messages builds it. It is
+// marshaled, so the selectors and the body are escaped.
+type wsmanEnvelope struct {
+	XMLName xml.Name    `xml:"Envelope"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	A       string      `xml:"xmlns:a,attr"`
+	W       string      `xml:"xmlns:w,attr"`
+	Header  wsmanHeader `xml:"Header"`
+	Body    struct {
+		Input interface{}
+	} `xml:"Body"`
+}
+
+type wsmanHeader struct {
+	Action           string          `xml:"a:Action"`
+	To               string          `xml:"a:To"`
+	ResourceURI      string          `xml:"w:ResourceURI"`
+	MessageID        int64           `xml:"a:MessageID"`
+	ReplyTo          string          `xml:"a:ReplyTo>a:Address"`
+	OperationTimeout string          `xml:"w:OperationTimeout"`
+	Selectors        []wsmanSelector `xml:"w:SelectorSet>w:Selector,omitempty"`
+}
+
+// wsmanSelector selects the instance of the class a message is for
+type wsmanSelector struct {
+	Name  string `xml:"Name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// methodInput is the input of a method that takes no parameters
+type methodInput struct {
+	XMLName xml.Name
+	H       string `xml:"xmlns:h,attr"`
+}
+
+type enumerateInput struct {
+	XMLName xml.Name `xml:"Enumerate"`
+	XMLNS   string   `xml:"xmlns,attr"`
+}
+
+type pullInput struct {
+	XMLName            xml.Name `xml:"Pull"`
+	XMLNS              string   `xml:"xmlns,attr"`
+	EnumerationContext string
+	MaxElements        int
+	MaxCharacters      int
+}
+
+// wsmanMessage builds a request for the actions go-wsman-messages has no message for.
+// input is the body, nil leaves it empty.
+func wsmanMessage(action string, resourceURI string, selectors []wsmanSelector, input interface{}) (string, error) {
+	envelope := wsmanEnvelope{
+		XMLNS: "http://www.w3.org/2003/05/soap-envelope",
+		A:     "http://schemas.xmlsoap.org/ws/2004/08/addressing",
+		W:     "http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd",
+		Header: wsmanHeader{
+			Action:           action,
+			To:               "/wsman",
+			ResourceURI:      resourceURI,
+			MessageID:        wsmanMessageID.Add(1),
+			ReplyTo:          "http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous",
+			OperationTimeout: "PT60S",
+			Selectors:        selectors,
+		},
+	}
+	envelope.Body.Input = input
+	data, err := xml.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
+
+// invokeMessage builds the invocation of method of the AMT class, for a method without parameters
+func invokeMessage(class string, method string) (string, error) {
+	resourceURI := amtResourceURIBase + class
+	return wsmanMessage(resourceURI+"/"+method, resourceURI, nil, methodInput{
+		XMLName: xml.Name{Local: "h:" + method + "_INPUT"},
+		H:       resourceURI,
+	})
+}
+
+// deleteMessage builds the Delete of the instance of the class at resourceURI with the
+// selector, for classes whose key go-wsman-messages does not select by
+func deleteMessage(resourceURI string, selector wsmanSelector) (string, error) {
+	return wsmanMessage(deleteAction, resourceURI, []wsmanSelector{selector}, nil)
+}
+
+// enumerateMessage builds the Enumerate of the instances of the class at resourceURI
+func enumerateMessage(resourceURI string) (string, error) {
+	return wsmanMessage(enumerateAction, resourceURI, nil, enumerateInput{XMLNS: enumerationNamespace})
+}
+
+// pullMessage builds the Pull of the instances enumerated with the context
+func pullMessage(resourceURI string, context string) (string, error) {
+	return wsmanMessage(pullAction, resourceURI, nil, pullInput{
+		XMLNS:              enumerationNamespace,
+		EnumerationContext: context,
+		MaxElements:        maxPullElements,
+		MaxCharacters:      maxPullCharacters,
+	})
+}
+
+// createMessage builds the Create of an instance of the class at resourceURI, input is the
+// instance
+func createMessage(resourceURI string, input interface{}) (string, error) {
+	return wsmanMessage(createAction, resourceURI, nil, input)
+}
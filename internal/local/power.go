@@ -0,0 +1,174 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"encoding/xml"
+	"fmt"
+	"rpc/pkg/utils"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/boot"
+	cimBoot "github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/boot"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/power"
+)
+
+const (
+	bootSettingDataInstanceID = "Intel(r) AMT:BootSettingData 0"
+	bootConfigInstanceID      = "Intel(r) AMT: Boot Configuration 0"
+	bootSourcePXE             = "Intel(r) AMT: Force PXE Boot"
+	bootSourceHDD             = "Intel(r) AMT: Force Hard-drive Boot"
+	bootSourceCD              = "Intel(r) AMT: Force CD/DVD Boot"
+)
+
+// powerStates maps the power subcommands to the CIM power states AMT supports
+var powerStates = map[string]power.PowerState{
+	utils.SubCommandPowerOn:    power.PowerOn,
+	utils.SubCommandPowerOff:   power.PowerOffSoft,
+	utils.SubCommandPowerReset: power.MasterBusReset,
+	utils.SubCommandPowerCycle: power.PowerCycleOffSoft,
+}
+
+type RequestPowerStateChangeResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"RequestPowerStateChange_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// bootSettingDataInput holds the boot options set for the next boot. The Put of
+// go-wsman-messages does not namespace AMT_BootSettingData, which AMT rejects.
+type bootSettingDataInput struct {
+	XMLName                xml.Name `xml:"h:AMT_BootSettingData"`
+	H                      string   `xml:"xmlns:h,attr"`
+	BIOSPause              bool     `xml:"h:BIOSPause"`
+	BIOSSetup              bool     `xml:"h:BIOSSetup"`
+	BootMediaIndex         int      `xml:"h:BootMediaIndex"`
+	ConfigurationDataReset bool     `xml:"h:ConfigurationDataReset"`
+	FirmwareVerbosity      int      `xml:"h:FirmwareVerbosity"`
+	ForcedProgressEvents   bool     `xml:"h:ForcedProgressEvents"`
+	IDERBootDevice         int      `xml:"h:IDERBootDevice"`
+	InstanceID             string   `xml:"h:InstanceID"`
+	LockKeyboard           bool     `xml:"h:LockKeyboard"`
+	LockPowerButton        bool     `xml:"h:LockPowerButton"`
+	LockResetButton        bool     `xml:"h:LockResetButton"`
+	LockSleepButton        bool     `xml:"h:LockSleepButton"`
+	ReflashBIOS            bool     `xml:"h:ReflashBIOS"`
+	UseIDER                bool     `xml:"h:UseIDER"`
+	UseSOL                 bool     `xml:"h:UseSOL"`
+	UseSafeMode            bool     `xml:"h:UseSafeMode"`
+	UserPasswordBypass     bool     `xml:"h:UserPasswordBypass"`
+	SecureErase            bool     `xml:"h:SecureErase"`
+}
+
+type BootSettingDataResponse struct {
+	Body struct {
+		BootSettingData boot.BootSettingData `xml:"AMT_BootSettingData"`
+	} `xml:"Body"`
+}
+
+type SetBootConfigRoleResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetBootConfigRole_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type ChangeBootOrderResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"ChangeBootOrder_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// Power changes the power state of the device, setting the boot options of the next boot first
+func (service *ProvisioningService) Power() utils.ReturnCode {
+	state, ok := powerStates[service.flags.SubCommand]
+	if !ok {
+		return utils.IncorrectCommandLineParameters
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	if service.flags.Power.BootToBIOS || service.flags.Power.BootToPXE {
+		if rc := service.setNextBoot(); rc != utils.Success {
+			return rc
+		}
+	}
+	return service.requestPowerState(state, service.flags.SubCommand)
+}
+
+// requestPowerState has AMT change the power state, action names it in the log
+func (service *ProvisioningService) requestPowerState(state power.PowerState, action string) utils.ReturnCode {
+	var rsp RequestPowerStateChangeResponse
+	if rc := service.PostAndUnmarshal(service.cimMessages.PowerManagementService.RequestPowerStateChange(state), &rsp); rc != utils.Success {
+		return utils.PowerActionFailed
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("AMT refused power %s, RequestPowerStateChange_OUTPUT.ReturnValue: %d", action, rsp.Body.Output.ReturnValue)
+		return utils.PowerActionFailed
+	}
+	log.Infof("Status: power %s sent to AMT", action)
+	return utils.Success
+}
+
+// setNextBoot has the next boot, and only the next one, go to the BIOS setup or to PXE
+func (service *ProvisioningService) setNextBoot() utils.ReturnCode {
+	settings := bootSettingDataInput{
+		InstanceID: bootSettingDataInstanceID,
+		BIOSSetup:  service.flags.Power.BootToBIOS,
+	}
+	// the BIOS setup is selected by the boot settings, the boot order stays as is
+	source := ""
+	if service.flags.Power.BootToPXE {
+		source = bootSourcePXE
+	}
+	if err := service.setBootConfiguration(settings, cimBoot.IsNextSingleUse, source); err != nil {
+		log.Error(err)
+		return utils.PowerActionFailed
+	}
+	return utils.Success
+}
+
+// setBootConfiguration puts the boot settings, selects the boot configuration for role and
+// moves source to the top of its boot order, the boot order is kept when source is empty
+func (service *ProvisioningService) setBootConfiguration(settings bootSettingDataInput, role cimBoot.BootServiceRole, source string) error {
+	xmlMsg, err := service.bootSettingDataPut(settings)
+	if err != nil {
+		return fmt.Errorf("unable to create the boot settings: %w", err)
+	}
+	var settingsRsp BootSettingDataResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &settingsRsp); rc != utils.Success {
+		return fmt.Errorf("unable to put the boot settings: %s", rc)
+	}
+	var roleRsp SetBootConfigRoleResponse
+	if rc := service.PostAndUnmarshal(service.cimMessages.BootService.SetBootConfigRole(bootConfigInstanceID, role), &roleRsp); rc != utils.Success {
+		return fmt.Errorf("unable to select the boot configuration: %s", rc)
+	}
+	if roleRsp.Body.Output.ReturnValue != 0 {
+		return fmt.Errorf("SetBootConfigRole_OUTPUT.ReturnValue: %d", roleRsp.Body.Output.ReturnValue)
+	}
+	if source == "" {
+		return nil
+	}
+	var orderRsp ChangeBootOrderResponse
+	if rc := service.PostAndUnmarshal(service.cimMessages.BootConfigSetting.ChangeBootOrder(source), &orderRsp); rc != utils.Success {
+		return fmt.Errorf("unable to change the boot order: %s", rc)
+	}
+	if orderRsp.Body.Output.ReturnValue != 0 {
+		return fmt.Errorf("ChangeBootOrder_OUTPUT.ReturnValue: %d", orderRsp.Body.Output.ReturnValue)
+	}
+	return nil
+}
+
+// bootSettingDataPut returns the Put of the boot settings, reusing the header of the go-wsman-messages Put
+func (service *ProvisioningService) bootSettingDataPut(settings bootSettingDataInput) (string, error) {
+	settings.H = "http://intel.com/wbem/wscim/1/amt-schema/1/" + boot.AMT_BootSettingData
+	body, err := xml.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return replaceMessageBody(service.amtMessages.BootSettingData.Put(boot.BootSettingData{}), body)
+}
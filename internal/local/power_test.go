@@ -0,0 +1,108 @@
+package local
+
+import (
+	"bytes"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/stretchr/testify/assert"
+)
+
+func powerResponse(returnValue int) RequestPowerStateChangeResponse {
+	rsp := RequestPowerStateChangeResponse{}
+	rsp.Body.Output.ReturnValue = returnValue
+	return rsp
+}
+
+func TestPower(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandPower
+	f.Password = "P@ssw0rd"
+
+	t.Run("returns Success for power on", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerOn
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, powerResponse(0))})
+		assert.Equal(t, utils.Success, lps.Power())
+	})
+	t.Run("returns PowerActionFailed when AMT refuses the power state", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerOff
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, powerResponse(2))})
+		assert.Equal(t, utils.PowerActionFailed, lps.Power())
+	})
+	t.Run("returns PowerActionFailed on server error", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerCycle
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.PowerActionFailed, lps.Power())
+	})
+	t.Run("returns Success for reset into the BIOS setup", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerReset
+		f.Power.BootToBIOS = true
+		defer func() { f.Power.BootToBIOS = false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			respondMsgFunc(t, SetBootConfigRoleResponse{}),
+			respondMsgFunc(t, powerResponse(0)),
+		})
+		assert.Equal(t, utils.Success, lps.Power())
+	})
+	t.Run("returns Success for reset to PXE", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerReset
+		f.Power.BootToPXE = true
+		defer func() { f.Power.BootToPXE = false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			respondMsgFunc(t, SetBootConfigRoleResponse{}),
+			respondMsgFunc(t, ChangeBootOrderResponse{}),
+			respondMsgFunc(t, powerResponse(0)),
+		})
+		assert.Equal(t, utils.Success, lps.Power())
+	})
+	t.Run("returns PowerActionFailed when the boot order can not be changed", func(t *testing.T) {
+		f.SubCommand = utils.SubCommandPowerCycle
+		f.Power.BootToPXE = true
+		defer func() { f.Power.BootToPXE = false }()
+		orderRsp := ChangeBootOrderResponse{}
+		orderRsp.Body.Output.ReturnValue = 1
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondMsgFunc(t, BootSettingDataResponse{}),
+			respondMsgFunc(t, SetBootConfigRoleResponse{}),
+			respondMsgFunc(t, orderRsp),
+		})
+		assert.Equal(t, utils.PowerActionFailed, lps.Power())
+	})
+}
+
+func TestBootSettingDataPut(t *testing.T) {
+	f := &flags.Flags{}
+	lps := setupService(f)
+	xmlMsg, err := lps.bootSettingDataPut(bootSettingDataInput{InstanceID: bootSettingDataInstanceID, BIOSSetup: true})
+	assert.Nil(t, err)
+	assert.Contains(t, xmlMsg, `<h:AMT_BootSettingData xmlns:h="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_BootSettingData">`)
+	assert.Contains(t, xmlMsg, "<h:BIOSSetup>true</h:BIOSSetup>")
+	assert.Contains(t, xmlMsg, "<h:InstanceID>Intel(r) AMT:BootSettingData 0</h:InstanceID>")
+	assert.NotContains(t, xmlMsg, "ManagedElement")
+}
+
+func TestDryRunPower(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandPower
+	f.SubCommand = utils.SubCommandPowerReset
+	f.DryRun = true
+	f.Password = "P@ssw0rd"
+	f.Power.BootToPXE = true
+
+	t.Run("lists the boot and power actions", func(t *testing.T) {
+		var out bytes.Buffer
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondMsgFunc(t, general.Response{})})
+		lps.out = &out
+		assert.Equal(t, utils.DryRunCompleted, lps.DryRun())
+		assert.Contains(t, out.String(), "boot from PXE on the next boot")
+		assert.Contains(t, out.String(), "power reset the device")
+	})
+	t.Run("returns AMTConnectionFailed when AMT can not be reached", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.AMTConnectionFailed, lps.DryRun())
+	})
+}
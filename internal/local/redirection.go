@@ -0,0 +1,234 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"encoding/xml"
+	"rpc/internal/flags"
+	"rpc/internal/i18n"
+	"rpc/internal/output"
+	"rpc/pkg/utils"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/redirection"
+)
+
+const (
+	redirectionServiceNamespace = "http://intel.com/wbem/wscim/1/amt-schema/1/" + redirection.AMT_RedirectionService
+
+	// the enabled states of CIM_KVMRedirectionSAP, enabled but offline is a KVM without a session
+	kvmEnabled           = 2
+	kvmDisabled          = 3
+	kvmEnabledButOffline = 6
+
+	// the enabled states of AMT_RedirectionService add IDE-R as bit 0 and SOL as bit 1
+	redirectionIDERBit = 1
+	redirectionSOLBit  = 2
+)
+
+type RedirectionServiceResponse struct {
+	Body struct {
+		Service struct {
+			Name                    string `xml:"Name"`
+			CreationClassName       string `xml:"CreationClassName"`
+			SystemName              string `xml:"SystemName"`
+			SystemCreationClassName string `xml:"SystemCreationClassName"`
+			ElementName             string `xml:"ElementName"`
+			ListenerEnabled         bool   `xml:"ListenerEnabled"`
+			EnabledState            int    `xml:"EnabledState"`
+		} `xml:"AMT_RedirectionService"`
+	} `xml:"Body"`
+}
+
+type KVMRedirectionSAPResponse struct {
+	Body struct {
+		SAP struct {
+			EnabledState int `xml:"EnabledState"`
+		} `xml:"CIM_KVMRedirectionSAP"`
+	} `xml:"Body"`
+}
+
+type RequestStateChangeResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"RequestStateChange_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// redirectionServiceInput is the body of the AMT_RedirectionService Put. The Put of
+// go-wsman-messages leaves out the namespace AMT requires.
+type redirectionServiceInput struct {
+	XMLName                 xml.Name `xml:"h:AMT_RedirectionService"`
+	H                       string   `xml:"xmlns:h,attr"`
+	CreationClassName       string   `xml:"h:CreationClassName"`
+	ElementName             string   `xml:"h:ElementName"`
+	Name                    string   `xml:"h:Name"`
+	SystemCreationClassName string   `xml:"h:SystemCreationClassName"`
+	SystemName              string   `xml:"h:SystemName"`
+	ListenerEnabled         bool     `xml:"h:ListenerEnabled"`
+}
+
+// RedirectionState tells which redirection features are enabled and whether AMT
+// listens for redirection sessions
+type RedirectionState struct {
+	Listener bool `json:"listener"`
+	KVM      bool `json:"kvm"`
+	SOL      bool `json:"sol"`
+	IDER     bool `json:"ider"`
+}
+
+// GetRedirectionState reads the redirection service and the KVM service access point
+func (service *ProvisioningService) GetRedirectionState() (RedirectionState, utils.ReturnCode) {
+	state := RedirectionState{}
+	var redirectionRsp RedirectionServiceResponse
+	if rc := service.PostAndUnmarshal(service.amtMessages.RedirectionService.Get(), &redirectionRsp); rc != utils.Success {
+		return state, rc
+	}
+	var kvmRsp KVMRedirectionSAPResponse
+	if rc := service.PostAndUnmarshal(service.cimMessages.KVMRedirectionSAP.Get(), &kvmRsp); rc != utils.Success {
+		return state, rc
+	}
+	enabledState := redirectionRsp.Body.Service.EnabledState
+	state.Listener = redirectionRsp.Body.Service.ListenerEnabled
+	state.IDER = enabledState&redirectionIDERBit != 0
+	state.SOL = enabledState&redirectionSOLBit != 0
+	state.KVM = kvmRsp.Body.SAP.EnabledState == kvmEnabled || kvmRsp.Body.SAP.EnabledState == kvmEnabledButOffline
+	return state, utils.Success
+}
+
+// ConfigureRedirection enables and disables the redirection features of -enable and
+// -disable. The listener is enabled while any feature is enabled.
+func (service *ProvisioningService) ConfigureRedirection() utils.ReturnCode {
+	state, rc := service.GetRedirectionState()
+	if rc != utils.Success {
+		return utils.RedirectionConfigurationFailed
+	}
+	requested := service.requestedRedirectionState(state)
+	if requested.KVM && !state.KVM {
+		// SOL and IDE-R are still configured on a SKU without KVM
+		if features := service.skuFeatures(); features.SKUClass != "" && !features.KVMAvailable {
+			log.Warnf("the %s firmware has no KVM, it is not enabled", features.SKUClassName())
+			requested.KVM = false
+			requested.Listener = requested.SOL || requested.IDER
+		}
+	}
+	if requested.SOL != state.SOL || requested.IDER != state.IDER {
+		enabledState := int(redirection.DisableIDERAndSOL)
+		if requested.IDER {
+			enabledState |= redirectionIDERBit
+		}
+		if requested.SOL {
+			enabledState |= redirectionSOLBit
+		}
+		xmlMsg := service.amtMessages.RedirectionService.RequestStateChange(redirection.RequestedState(enabledState))
+		if rc = service.requestStateChange(xmlMsg); rc != utils.Success {
+			return rc
+		}
+	}
+	if requested.KVM != state.KVM {
+		kvmState := kvmDisabled
+		if requested.KVM {
+			kvmState = kvmEnabled
+		}
+		if rc = service.requestStateChange(service.cimMessages.KVMRedirectionSAP.RequestStateChange(kvmState)); rc != utils.Success {
+			return rc
+		}
+	}
+	if requested.Listener != state.Listener {
+		if rc = service.setRedirectionListener(requested.Listener); rc != utils.Success {
+			return rc
+		}
+	}
+	state, rc = service.GetRedirectionState()
+	if rc != utils.Success {
+		return utils.RedirectionConfigurationFailed
+	}
+	w := service.newOutputWriter()
+	writeRedirectionState(w, state)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	if state != requested {
+		log.Error("AMT did not apply the requested redirection state")
+		return utils.RedirectionConfigurationFailed
+	}
+	return utils.Success
+}
+
+// requestedRedirectionState applies -enable and -disable to the current state
+func (service *ProvisioningService) requestedRedirectionState(state RedirectionState) RedirectionState {
+	set := func(features []string, enabled bool) {
+		for _, feature := range features {
+			switch feature {
+			case flags.RedirectionKVM:
+				state.KVM = enabled
+			case flags.RedirectionSOL:
+				state.SOL = enabled
+			case flags.RedirectionIDER:
+				state.IDER = enabled
+			}
+		}
+	}
+	set(service.flags.Redirection.Enable, true)
+	set(service.flags.Redirection.Disable, false)
+	state.Listener = state.KVM || state.SOL || state.IDER
+	return state
+}
+
+func (service *ProvisioningService) requestStateChange(xmlMsg string) utils.ReturnCode {
+	var rsp RequestStateChangeResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return utils.RedirectionConfigurationFailed
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("RequestStateChange_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.RedirectionConfigurationFailed
+	}
+	return utils.Success
+}
+
+func (service *ProvisioningService) setRedirectionListener(enabled bool) utils.ReturnCode {
+	var current RedirectionServiceResponse
+	if rc := service.PostAndUnmarshal(service.amtMessages.RedirectionService.Get(), &current); rc != utils.Success {
+		return utils.RedirectionConfigurationFailed
+	}
+	body, err := xml.Marshal(redirectionServiceInput{
+		H:                       redirectionServiceNamespace,
+		CreationClassName:       current.Body.Service.CreationClassName,
+		ElementName:             current.Body.Service.ElementName,
+		Name:                    current.Body.Service.Name,
+		SystemCreationClassName: current.Body.Service.SystemCreationClassName,
+		SystemName:              current.Body.Service.SystemName,
+		ListenerEnabled:         enabled,
+	})
+	if err != nil {
+		log.Error("unable to create the redirection service settings: ", err)
+		return utils.RedirectionConfigurationFailed
+	}
+	xmlMsg, err := replaceBody(service.amtMessages.RedirectionService.Put(redirection.RedirectionService{}), "", string(body))
+	if err != nil {
+		log.Error("unable to create the redirection service settings: ", err)
+		return utils.RedirectionConfigurationFailed
+	}
+	var rsp RedirectionServiceResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return utils.RedirectionConfigurationFailed
+	}
+	return utils.Success
+}
+
+func writeRedirectionState(w output.OutputWriter, state RedirectionState) {
+	w.Field("redirection", "", state)
+	enabled := func(on bool) string {
+		if on {
+			return i18n.T("info.enabled")
+		}
+		return i18n.T("info.disabled")
+	}
+	w.Println(i18n.Label("info.redirectionListener") + ": " + enabled(state.Listener))
+	w.Println(i18n.Label("info.kvm") + ": " + enabled(state.KVM))
+	w.Println(i18n.Label("info.sol") + ": " + enabled(state.SOL))
+	w.Println(i18n.Label("info.ider") + ": " + enabled(state.IDER))
+}
@@ -0,0 +1,80 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectionServiceXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:h="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_RedirectionService"><a:Body><h:AMT_RedirectionService><h:CreationClassName>AMT_RedirectionService</h:CreationClassName><h:ElementName>Intel(r) AMT Redirection Service</h:ElementName><h:EnabledState>%d</h:EnabledState><h:ListenerEnabled>%t</h:ListenerEnabled><h:Name>Intel(r) AMT Redirection Service</h:Name><h:SystemCreationClassName>CIM_ComputerSystem</h:SystemCreationClassName><h:SystemName>Intel(r) AMT</h:SystemName></h:AMT_RedirectionService></a:Body></a:Envelope>`
+
+const kvmRedirectionSAPXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:h="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_KVMRedirectionSAP"><a:Body><h:CIM_KVMRedirectionSAP><h:EnabledState>%d</h:EnabledState></h:CIM_KVMRedirectionSAP></a:Body></a:Envelope>`
+
+const requestStateChangeXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:h="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_KVMRedirectionSAP"><a:Body><h:RequestStateChange_OUTPUT><h:ReturnValue>%d</h:ReturnValue></h:RequestStateChange_OUTPUT></a:Body></a:Envelope>`
+
+func TestGetRedirectionState(t *testing.T) {
+	f := &flags.Flags{}
+	lps := setupWsmanResponses(t, f, ResponseFuncArray{
+		respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32770, true)),
+		respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmEnabledButOffline)),
+	})
+	state, rc := lps.GetRedirectionState()
+	assert.Equal(t, utils.Success, rc)
+	assert.Equal(t, RedirectionState{Listener: true, KVM: true, SOL: true}, state)
+}
+
+func TestConfigureRedirection(t *testing.T) {
+	f := &flags.Flags{}
+
+	t.Run("enables KVM and the listener", func(t *testing.T) {
+		f.Redirection = flags.RedirectionFlags{Enable: []string{flags.RedirectionKVM}}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, false)),
+			respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmDisabled)),
+			respondCheckBodyFunc(t, fmt.Sprintf(requestStateChangeXMLResponse, 0), `<h:RequestedState>2</h:RequestedState>`),
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, false)),
+			respondCheckBodyFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, true),
+				`xmlns:h="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_RedirectionService"`,
+				`<h:ListenerEnabled>true</h:ListenerEnabled>`),
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, true)),
+			respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmEnabledButOffline)),
+		})
+		var buf bytes.Buffer
+		lps.out = &buf
+		assert.Equal(t, utils.Success, lps.ConfigureRedirection())
+		assert.Contains(t, buf.String(), "KVM")
+	})
+	t.Run("disables SOL and IDE-R", func(t *testing.T) {
+		f.Redirection = flags.RedirectionFlags{Disable: []string{flags.RedirectionSOL, flags.RedirectionIDER}}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32771, true)),
+			respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmEnabledButOffline)),
+			respondCheckBodyFunc(t, fmt.Sprintf(requestStateChangeXMLResponse, 0), `<h:RequestedState>32768</h:RequestedState>`),
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, true)),
+			respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmEnabledButOffline)),
+		})
+		lps.out = &bytes.Buffer{}
+		assert.Equal(t, utils.Success, lps.ConfigureRedirection())
+	})
+	t.Run("fails when AMT refuses the state change", func(t *testing.T) {
+		f.Redirection = flags.RedirectionFlags{Enable: []string{flags.RedirectionSOL}}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{
+			respondStringFunc(t, fmt.Sprintf(redirectionServiceXMLResponse, 32768, true)),
+			respondStringFunc(t, fmt.Sprintf(kvmRedirectionSAPXMLResponse, kvmEnabledButOffline)),
+			respondStringFunc(t, fmt.Sprintf(requestStateChangeXMLResponse, 2)),
+		})
+		assert.Equal(t, utils.RedirectionConfigurationFailed, lps.ConfigureRedirection())
+	})
+	t.Run("fails when the state can not be read", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.RedirectionConfigurationFailed, lps.ConfigureRedirection())
+	})
+}
@@ -0,0 +1,94 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	internalAMT "rpc/internal/amt"
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/wsman"
+)
+
+type SoftwareIdentityPullResponse struct {
+	Body struct {
+		PullResponse struct {
+			Items []struct {
+				InstanceID    string `xml:"InstanceID"`
+				VersionString string `xml:"VersionString"`
+			} `xml:"Items>CIM_SoftwareIdentity"`
+		} `xml:"PullResponse"`
+	} `xml:"Body"`
+}
+
+type ComputerSystemPackageResponse struct {
+	Body struct {
+		Package struct {
+			PlatformGUID string `xml:"PlatformGUID"`
+		} `xml:"CIM_ComputerSystemPackage"`
+	} `xml:"Body"`
+}
+
+// setupRemoteWsmanClient connects the wsman client to the AMT device of -host with the
+// digest user of -user, over TLS with -tls
+func (service *ProvisioningService) setupRemoteWsmanClient(password string) {
+	remote := service.flags.Remote
+	service.client = wsman.NewClient(service.serverURL, remote.User, password, true)
+	if remote.TLS {
+		service.client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:            remote.CACerts,
+				InsecureSkipVerify: remote.SkipCertCheck,
+			},
+		}
+	}
+}
+
+// collectRemoteInfo reads the amtinfo values of a remote device with wsman, the values
+// the MEI reports of a local device. A failed query is recorded in the Errors of the result.
+func (service *ProvisioningService) collectRemoteInfo(result *info.InfoResult) {
+	amtInfo := service.flags.AmtInfo
+	if result.Errors == nil {
+		result.Errors = map[info.Query]error{}
+	}
+	if amtInfo.Ver || amtInfo.Bld || amtInfo.Sku || amtInfo.SecCheck {
+		var rsp SoftwareIdentityPullResponse
+		rc := service.EnumPullUnmarshal(service.cimMessages.SoftwareIdentity.Enumerate, service.cimMessages.SoftwareIdentity.Pull, &rsp)
+		if rc != utils.Success {
+			result.Errors[info.QueryVersion] = fmt.Errorf("unable to read the AMT version of %s: %s", service.flags.Remote.Host, rc)
+		}
+		for _, item := range rsp.Body.PullResponse.Items {
+			switch item.InstanceID {
+			case "AMT":
+				result.Version = item.VersionString
+			case "Build Number":
+				result.BuildNumber = item.VersionString
+			case "Sku":
+				result.SKU = item.VersionString
+			}
+		}
+	}
+	if amtInfo.UUID {
+		var rsp ComputerSystemPackageResponse
+		rc := service.PostAndUnmarshal(service.cimMessages.ComputerSystemPackage.Get(), &rsp)
+		raw, err := hex.DecodeString(rsp.Body.Package.PlatformGUID)
+		if rc != utils.Success || err != nil || len(raw) != 16 {
+			result.Errors[info.QueryUUID] = fmt.Errorf("unable to read the UUID of %s", service.flags.Remote.Host)
+		} else {
+			result.UUID = internalAMT.FormatUUID(raw)
+		}
+	}
+	if amtInfo.Mode {
+		rsp, err := service.GetHostBasedSetupService()
+		if err != nil {
+			result.Errors[info.QueryMode] = err
+		}
+		result.ControlMode = rsp.Body.IPS_HostBasedSetupService.CurrentControlMode
+	}
+}
@@ -0,0 +1,97 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"rpc/internal/flags"
+	"rpc/internal/info"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const softwareIdentityXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_SoftwareIdentity"><a:Body><g:PullResponse><g:Items><g:CIM_SoftwareIdentity><g:InstanceID>AMT</g:InstanceID><g:VersionString>16.1.25</g:VersionString></g:CIM_SoftwareIdentity><g:CIM_SoftwareIdentity><g:InstanceID>Build Number</g:InstanceID><g:VersionString>2049</g:VersionString></g:CIM_SoftwareIdentity><g:CIM_SoftwareIdentity><g:InstanceID>Sku</g:InstanceID><g:VersionString>16392</g:VersionString></g:CIM_SoftwareIdentity></g:Items></g:PullResponse></a:Body></a:Envelope>`
+
+const computerSystemPackageXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ComputerSystemPackage"><a:Body><g:CIM_ComputerSystemPackage><g:PlatformGUID>44454C4C4A00104E8052B3C04F565931</g:PlatformGUID></g:CIM_ComputerSystemPackage></a:Body></a:Envelope>`
+
+const hostBasedSetupXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:g="http://intel.com/wbem/wscim/1/ips-schema/1/IPS_HostBasedSetupService"><a:Body><g:IPS_HostBasedSetupService><g:CurrentControlMode>2</g:CurrentControlMode></g:IPS_HostBasedSetupService></a:Body></a:Envelope>`
+
+func TestNewProvisioningServiceRemote(t *testing.T) {
+	f := &flags.Flags{}
+	f.Remote = flags.RemoteFlags{Host: "amt.example.com", TLS: true, User: "admin"}
+	service := NewProvisioningService(f)
+	assert.Equal(t, "https://amt.example.com:16993/wsman", service.serverURL)
+	assert.Nil(t, service.selectTransport, "a remote device is not reached through LMS or the LME driver")
+}
+
+func TestCollectRemoteInfo(t *testing.T) {
+	f := &flags.Flags{}
+	f.Remote = flags.RemoteFlags{Host: "amt.example.com", User: "admin"}
+	f.AmtInfo = flags.AmtInfoFlags{Ver: true, Bld: true, Sku: true, UUID: true, Mode: true}
+	lps := setupWsmanResponses(t, f, ResponseFuncArray{
+		respondMsgFunc(t, common.EnumerationResponse{}),
+		respondStringFunc(t, softwareIdentityXMLResponse),
+		respondStringFunc(t, computerSystemPackageXMLResponse),
+		respondStringFunc(t, hostBasedSetupXMLResponse),
+	})
+	var result info.InfoResult
+	lps.collectRemoteInfo(&result)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, "16.1.25", result.Version)
+	assert.Equal(t, "2049", result.BuildNumber)
+	assert.Equal(t, "16392", result.SKU)
+	assert.Equal(t, "4c4c4544-004a-4e10-8052-b3c04f565931", result.UUID)
+	assert.Equal(t, 2, result.ControlMode)
+
+	t.Run("records the failed queries", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		var result info.InfoResult
+		lps.collectRemoteInfo(&result)
+		assert.Error(t, result.Err(info.QueryVersion))
+		assert.Error(t, result.Err(info.QueryUUID))
+		assert.Error(t, result.Err(info.QueryMode))
+	})
+}
+
+func TestSetupRemoteWsmanClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(hostBasedSetupXMLResponse))
+	}))
+	defer server.Close()
+	f := &flags.Flags{}
+	f.Remote = flags.RemoteFlags{Host: "amt.example.com", TLS: true, User: "admin"}
+
+	t.Run("fails on a certificate that does not verify", func(t *testing.T) {
+		lps := setupService(f)
+		lps.serverURL = server.URL
+		lps.setupWsmanClient("admin", "P@ssw0rd")
+		_, err := lps.GetHostBasedSetupService()
+		assert.Error(t, err)
+	})
+	t.Run("verifies the certificate with -amtCACert", func(t *testing.T) {
+		f.Remote.CACerts = x509.NewCertPool()
+		f.Remote.CACerts.AddCert(server.Certificate())
+		defer func() { f.Remote.CACerts = nil }()
+		lps := setupService(f)
+		lps.serverURL = server.URL
+		lps.setupWsmanClient("admin", "P@ssw0rd")
+		rsp, err := lps.GetHostBasedSetupService()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, rsp.Body.IPS_HostBasedSetupService.CurrentControlMode)
+	})
+	t.Run("skips the verification with -skipAMTCertCheck", func(t *testing.T) {
+		f.Remote.SkipCertCheck = true
+		defer func() { f.Remote.SkipCertCheck = false }()
+		lps := setupService(f)
+		lps.serverURL = server.URL
+		lps.setupWsmanClient("admin", "P@ssw0rd")
+		_, err := lps.GetHostBasedSetupService()
+		assert.NoError(t, err)
+	})
+}
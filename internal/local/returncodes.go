@@ -0,0 +1,25 @@
+package local
+
+import (
+	"rpc/internal/i18n"
+	"rpc/pkg/utils"
+)
+
+func (service *ProvisioningService) DisplayReturnCodes() utils.ReturnCode {
+	w := service.newOutputWriter()
+
+	w.Field("returnCodes", "", utils.ReturnCodes)
+	// the structured output keeps the English descriptions for scripts
+	for _, info := range utils.ReturnCodes {
+		description := info.Description
+		if translated, ok := i18n.Lookup("returncode." + info.Name); ok {
+			description = translated
+		}
+		w.Printf("%-5d %-35s %s\n", info.Code, info.Name, description)
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
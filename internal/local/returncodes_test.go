@@ -0,0 +1,51 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"rpc/internal/flags"
+	"rpc/internal/i18n"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplayReturnCodes(t *testing.T) {
+	t.Run("should write text output", func(t *testing.T) {
+		f := &flags.Flags{}
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.DisplayReturnCodes()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, buf.String(), "23    MissingOrIncorrectPassword")
+	})
+
+	t.Run("should translate the text output", func(t *testing.T) {
+		assert.NoError(t, i18n.SetLanguage("de"))
+		defer i18n.SetLanguage(i18n.DefaultLanguage)
+		f := &flags.Flags{}
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.DisplayReturnCodes()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, buf.String(), "das AMT-Passwort fehlt oder ist falsch")
+	})
+
+	t.Run("should write json output", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.JsonOutput = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.DisplayReturnCodes()
+		assert.Equal(t, utils.Success, rc)
+		var result struct {
+			ReturnCodes []utils.ReturnCodeInfo `json:"returnCodes"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, utils.ReturnCodes, result.ReturnCodes)
+	})
+}
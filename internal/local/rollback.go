@@ -0,0 +1,101 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"rpc/pkg/utils"
+	"strings"
+)
+
+// configTransaction records the changes a configuration of several wsman steps made in
+// AMT, so a failed step does not leave the device half configured. The changes are undone
+// in the reverse order they were made.
+type configTransaction struct {
+	service *ProvisioningService
+	// name is the configuration in the log, such as TLS
+	name  string
+	steps []configStep
+}
+
+type configStep struct {
+	description string
+	undo        func() error
+}
+
+func (service *ProvisioningService) beginConfig(name string) *configTransaction {
+	return &configTransaction{service: service, name: name}
+}
+
+// applied records a change made in AMT with the function that undoes it
+func (tx *configTransaction) applied(description string, undo func() error) {
+	tx.steps = append(tx.steps, configStep{description: description, undo: undo})
+}
+
+// appliedPost records a change that is undone by posting xmlMsg, such as the Delete of an
+// added instance
+func (tx *configTransaction) appliedPost(description string, xmlMsg string) {
+	tx.applied(description, func() error {
+		_, err := tx.service.client.Post(xmlMsg)
+		return err
+	})
+}
+
+// addCertificate adds the certificate or key with add and records its removal with the
+// Delete message of remove. One that the service already knows the handle of is reused
+// and kept on rollback.
+func (tx *configTransaction) addCertificate(description string, cert string, add func(string) (string, utils.ReturnCode), remove func(string) string) (string, utils.ReturnCode) {
+	for handle, known := range tx.service.handlesWithCerts {
+		if known == cert {
+			return handle, utils.Success
+		}
+	}
+	handle, rc := add(cert)
+	if rc != utils.Success {
+		return handle, rc
+	}
+	tx.appliedPost(description+" "+handle, remove(handle))
+	return handle, utils.Success
+}
+
+// appliedHandles records the removal of the key and certificates that
+// ProcessIeee8012xConfig added, they are removed in the order of RollbackAddedItems
+func (tx *configTransaction) appliedHandles(handles *Handles) {
+	if handles.rootCertHandle != "" {
+		tx.appliedPost("root certificate "+handles.rootCertHandle, tx.service.amtMessages.PublicKeyCertificate.Delete(handles.rootCertHandle))
+	}
+	if handles.clientCertHandle != "" {
+		tx.appliedPost("client certificate "+handles.clientCertHandle, tx.service.amtMessages.PublicKeyCertificate.Delete(handles.clientCertHandle))
+	}
+	if handles.privateKeyHandle != "" {
+		tx.appliedPost("private key "+handles.privateKeyHandle, tx.service.amtMessages.PublicPrivateKeyPair.Delete(handles.privateKeyHandle))
+	}
+}
+
+// rollback undoes the recorded changes after a step failed with rc. It returns
+// PartialConfigRolledBack when all changes were undone. When nothing was changed rc is
+// returned, as it is when a change can not be undone, which is logged so it can be
+// removed by hand.
+func (tx *configTransaction) rollback(rc utils.ReturnCode) utils.ReturnCode {
+	if len(tx.steps) == 0 {
+		return rc
+	}
+	log.Errorf("%s configuration failed with return code %d (%s), rolling back %d changes", tx.name, rc, rc, len(tx.steps))
+	var remaining []string
+	for i := len(tx.steps) - 1; i >= 0; i-- {
+		step := tx.steps[i]
+		if err := step.undo(); err != nil {
+			log.Errorf("unable to roll back %s: %s", step.description, err)
+			remaining = append(remaining, step.description)
+			continue
+		}
+		log.Infof("rolled back %s", step.description)
+	}
+	tx.steps = nil
+	if len(remaining) > 0 {
+		log.Errorf("%s configuration is incomplete, these changes remain in AMT: %s", tx.name, strings.Join(remaining, ", "))
+		return rc
+	}
+	return utils.PartialConfigRolledBack
+}
@@ -0,0 +1,80 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"errors"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigTransactionRollback(t *testing.T) {
+	f := &flags.Flags{}
+
+	t.Run("returns the failure when nothing was changed", func(t *testing.T) {
+		lps := setupService(f)
+		tx := lps.beginConfig("TLS")
+		assert.Equal(t, utils.TLSConfigurationFailed, tx.rollback(utils.TLSConfigurationFailed))
+	})
+	t.Run("undoes the changes in reverse order", func(t *testing.T) {
+		lps := setupService(f)
+		tx := lps.beginConfig("TLS")
+		var undone []string
+		for _, step := range []string{"first", "second", "third"} {
+			step := step
+			tx.applied(step, func() error {
+				undone = append(undone, step)
+				return nil
+			})
+		}
+		assert.Equal(t, utils.PartialConfigRolledBack, tx.rollback(utils.TLSConfigurationFailed))
+		assert.Equal(t, []string{"third", "second", "first"}, undone)
+		assert.Empty(t, tx.steps)
+	})
+	t.Run("returns the failure when a change can not be undone", func(t *testing.T) {
+		lps := setupService(f)
+		tx := lps.beginConfig("CIRA")
+		undone := false
+		tx.applied("first", func() error {
+			undone = true
+			return nil
+		})
+		tx.applied("second", func() error { return errors.New("not found") })
+		assert.Equal(t, utils.CIRAConfigurationFailed, tx.rollback(utils.CIRAConfigurationFailed))
+		assert.True(t, undone)
+	})
+	t.Run("undoes a change by posting its message", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondStringFunc(t, "deleted")})
+		tx := lps.beginConfig("CIRA")
+		tx.appliedPost("management presence server", lps.amtMessages.ManagementPresenceRemoteSAP.Delete("Intel(r) AMT:Management Presence Server 0"))
+		assert.Equal(t, utils.PartialConfigRolledBack, tx.rollback(utils.CIRAConfigurationFailed))
+	})
+	t.Run("keeps a certificate AMT already has", func(t *testing.T) {
+		lps := setupService(f)
+		lps.handlesWithCerts = map[string]string{"Intel(r) AMT Certificate: Handle: 1": "AABBCCDD"}
+		tx := lps.beginConfig("CIRA")
+		handle, rc := tx.addCertificate("root certificate", "AABBCCDD", func(string) (string, utils.ReturnCode) {
+			t.Error("the certificate is added again")
+			return "", utils.Success
+		}, lps.amtMessages.PublicKeyCertificate.Delete)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, "Intel(r) AMT Certificate: Handle: 1", handle)
+		assert.Empty(t, tx.steps)
+	})
+	t.Run("records the removal of an added certificate", func(t *testing.T) {
+		lps := setupService(f)
+		lps.handlesWithCerts = map[string]string{}
+		tx := lps.beginConfig("TLS")
+		_, rc := tx.addCertificate("TLS certificate", "AABBCCDD", func(string) (string, utils.ReturnCode) {
+			return "Intel(r) AMT Certificate: Handle: 2", utils.Success
+		}, lps.amtMessages.PublicKeyCertificate.Delete)
+		assert.Equal(t, utils.Success, rc)
+		assert.Len(t, tx.steps, 1)
+		assert.Equal(t, "TLS certificate Intel(r) AMT Certificate: Handle: 2", tx.steps[0].description)
+	})
+}
@@ -0,0 +1,235 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"rpc/internal/lm"
+	"rpc/internal/output"
+	"rpc/pkg/heci"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+)
+
+// selfTestDialTimeout bounds the check whether LMS is listening
+const selfTestDialTimeout = time.Second
+
+// driverVersion and dialLMS are replaced in tests
+var (
+	driverVersion = heci.DriverVersion
+	dialLMS       = net.DialTimeout
+)
+
+// SelfTest checks what rpc needs on this host, the MEI driver, a PTHI query, LMS, the
+// transport -transport selects, the paths it writes to and its executable, and prints a
+// PASS, WARN or FAIL line per check. It is meant for support to triage a host before
+// looking at AMT itself.
+func (service *ProvisioningService) SelfTest() utils.ReturnCode {
+	checks := service.SelfTestChecks()
+	status := StatusPass
+	for _, check := range checks {
+		if check.Status == StatusFail || check.Status == StatusWarn && status == StatusPass {
+			status = check.Status
+		}
+	}
+
+	w := service.newOutputWriter()
+	w.Field("checks", "", checks)
+	w.Field("status", "", status)
+	for _, check := range checks {
+		w.Printf("%-4s %-12s %s\n", check.Status, check.Name, check.Detail)
+	}
+	w.Printf("%-4s %s\n", status, "overall")
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+
+	// LMS is optional, only a failed check fails the self-test
+	if status == StatusFail {
+		return utils.SelfTestFailed
+	}
+	return utils.Success
+}
+
+// SelfTestChecks runs every check of the self-test, a failed check does not stop the others
+func (service *ProvisioningService) SelfTestChecks() []StatusCheck {
+	mei, lms := service.meiDriverCheck(), service.lmsCheck()
+	return []StatusCheck{
+		mei,
+		service.pthiCheck(),
+		lms,
+		service.transportCheck(mei, lms),
+		service.pathsCheck(),
+		service.executableCheck(),
+	}
+}
+
+func (service *ProvisioningService) meiDriverCheck() StatusCheck {
+	check := StatusCheck{Name: "meiDriver"}
+	version, err := driverVersion()
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	check.Status, check.Detail = StatusPass, "version "+version
+	return check
+}
+
+func (service *ProvisioningService) pthiCheck() StatusCheck {
+	check := StatusCheck{Name: "pthi"}
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	check.Status, check.Detail = StatusPass, "control mode "+utils.InterpretControlMode(controlMode)
+	return check
+}
+
+func (service *ProvisioningService) lmsCheck() StatusCheck {
+	check := StatusCheck{Name: "lms"}
+	hostPort := service.lmsHostPort()
+	conn, err := dialLMS("tcp", hostPort, selfTestDialTimeout)
+	if err != nil {
+		// rpc talks to AMT through its LME driver without LMS, the transport check tells
+		// whether it may
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("not listening on %s", hostPort)
+		return check
+	}
+	conn.Close()
+	check.Status, check.Detail = StatusPass, "listening on "+hostPort
+	return check
+}
+
+// lmsHostPort returns the LMS address of -lmsaddress and -lmsport, the default LMS
+// address for the commands without them. The default port is the TLS port of LMS with
+// -transport lms-tls.
+func (service *ProvisioningService) lmsHostPort() string {
+	address, port := service.flags.LMSAddress, service.flags.LMSPort
+	if address == "" {
+		address = utils.LMSAddress
+	}
+	if port == "" && service.flags.Transport == lm.TransportLMSTLS {
+		port = utils.LMSTLSPort
+	}
+	if port == "" {
+		port = utils.LMSPort
+	}
+	return net.JoinHostPort(address, port)
+}
+
+// transportCheck tells which way the local WS-MAN requests take to AMT with -transport,
+// from the results of the MEI driver and LMS checks
+func (service *ProvisioningService) transportCheck(mei, lms StatusCheck) StatusCheck {
+	check := StatusCheck{Name: "transport"}
+	transport := service.flags.Transport
+	if transport == "" {
+		transport = lm.TransportAuto
+	}
+	switch {
+	case transport != lm.TransportLME && lms.Status == StatusPass:
+		check.Status, check.Detail = StatusPass, fmt.Sprintf("LMS with -transport %s", transport)
+	case transport == lm.TransportLMS || transport == lm.TransportLMSTLS:
+		check.Status, check.Detail = StatusFail, fmt.Sprintf("LMS is not listening, -transport %s does not use the LME driver of rpc", transport)
+	case mei.Status == StatusPass:
+		check.Status, check.Detail = StatusPass, fmt.Sprintf("the LME driver of rpc with -transport %s", transport)
+	default:
+		check.Status, check.Detail = StatusFail, fmt.Sprintf("the LME driver of rpc needs the MEI driver with -transport %s", transport)
+	}
+	return check
+}
+
+// selfTestPaths returns the directories rpc writes to with the flags given, its cache
+// directory, the folder of -logfile and the folders of the -output files
+func (service *ProvisioningService) selfTestPaths() ([]string, error) {
+	cache, err := utils.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{cache}
+	if service.flags.LogFile != "" {
+		paths = append(paths, filepath.Dir(service.flags.LogFile))
+	}
+	for _, destination := range service.flags.Output {
+		switch destination {
+		case output.Stdout, output.Syslog, output.EventLog:
+		default:
+			paths = append(paths, filepath.Dir(destination))
+		}
+	}
+	return paths, nil
+}
+
+func (service *ProvisioningService) pathsCheck() StatusCheck {
+	check := StatusCheck{Name: "paths"}
+	paths, err := service.selfTestPaths()
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	var failed []string
+	for _, path := range paths {
+		if err := checkWritable(path); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		check.Status, check.Detail = StatusFail, strings.Join(failed, "; ")
+		return check
+	}
+	check.Status, check.Detail = StatusPass, "writable: "+strings.Join(paths, ", ")
+	return check
+}
+
+// checkWritable creates the directory like rpc does and writes a temporary file to it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".rpc-selftest-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// executableCheck reports the SHA-256 of the rpc executable, support compares it with the
+// checksum of the release to rule out a damaged or replaced binary
+func (service *ProvisioningService) executableCheck() StatusCheck {
+	check := StatusCheck{Name: "executable"}
+	path, err := os.Executable()
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	sum, err := fileSHA256(path)
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	check.Status, check.Detail = StatusPass, fmt.Sprintf("%s %s sha256 %s", utils.ProjectVersion, path, sum)
+	return check
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
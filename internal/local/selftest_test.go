@@ -0,0 +1,128 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/internal/lm"
+	"rpc/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfTest(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandSelfTest
+	f.JsonOutput = true
+	// the cache directory is taken from the environment
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	origVersion, origDial := driverVersion, dialLMS
+	origMode, origModeErr := mockControlMode, mockControlModeErr
+	defer func() {
+		driverVersion, dialLMS = origVersion, origDial
+		mockControlMode, mockControlModeErr = origMode, origModeErr
+	}()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	dialLMS = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout(network, listener.Addr().String(), timeout)
+	}
+
+	run := func() (utils.ReturnCode, map[string]string, string) {
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.SelfTest()
+		var result struct {
+			Checks []StatusCheck `json:"checks"`
+			Status string        `json:"status"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		statuses := map[string]string{}
+		for _, check := range result.Checks {
+			statuses[check.Name] = check.Status
+		}
+		return rc, statuses, result.Status
+	}
+
+	t.Run("passes when all checks pass", func(t *testing.T) {
+		driverVersion = func() (string, error) { return "2.2.0.0", nil }
+		mockControlMode, mockControlModeErr = 1, nil
+		rc, statuses, status := run()
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, StatusPass, status)
+		assert.Equal(t, map[string]string{
+			"meiDriver": StatusPass, "pthi": StatusPass, "lms": StatusPass, "transport": StatusPass,
+			"paths": StatusPass, "executable": StatusPass,
+		}, statuses)
+	})
+	t.Run("warns without LMS", func(t *testing.T) {
+		dial := dialLMS
+		defer func() { dialLMS = dial }()
+		dialLMS = func(network, address string, timeout time.Duration) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		}
+		rc, statuses, status := run()
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, StatusWarn, status)
+		assert.Equal(t, StatusWarn, statuses["lms"])
+		assert.Equal(t, StatusPass, statuses["transport"])
+
+		f.Transport = lm.TransportLMS
+		defer func() { f.Transport = "" }()
+		rc, statuses, _ = run()
+		assert.Equal(t, utils.SelfTestFailed, rc)
+		assert.Equal(t, StatusFail, statuses["transport"])
+
+		f.Transport = lm.TransportLMSTLS
+		_, statuses, _ = run()
+		assert.Equal(t, StatusFail, statuses["transport"])
+	})
+	t.Run("fails without the MEI driver", func(t *testing.T) {
+		driverVersion = func() (string, error) { return "", errors.New("no MEI device") }
+		mockControlModeErr = errors.New("no MEI device")
+		rc, statuses, status := run()
+		assert.Equal(t, utils.SelfTestFailed, rc)
+		assert.Equal(t, StatusFail, status)
+		assert.Equal(t, StatusFail, statuses["meiDriver"])
+		assert.Equal(t, StatusFail, statuses["pthi"])
+		// LMS is still used without the MEI driver, unless -transport lme
+		assert.Equal(t, StatusPass, statuses["transport"])
+		f.Transport = lm.TransportLME
+		defer func() { f.Transport = "" }()
+		_, statuses, _ = run()
+		assert.Equal(t, StatusFail, statuses["transport"])
+		assert.Equal(t, StatusPass, statuses["paths"])
+	})
+}
+
+func TestSelfTestPathsCheck(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	f := &flags.Flags{LogFile: filepath.Join(dir, "logs", "rpc.log"), Output: []string{"stdout", filepath.Join(dir, "result.json")}}
+	service := setupService(f)
+
+	check := service.pathsCheck()
+	assert.Equal(t, StatusPass, check.Status)
+	assert.Contains(t, check.Detail, filepath.Join(dir, "logs"))
+	assert.DirExists(t, filepath.Join(dir, "logs"))
+
+	if os.Geteuid() == 0 {
+		t.Skip("root writes to read-only folders")
+	}
+	readOnly := filepath.Join(dir, "readonly")
+	assert.NoError(t, os.Mkdir(readOnly, 0500))
+	f.LogFile = filepath.Join(readOnly, "rpc.log")
+	check = service.pathsCheck()
+	assert.Equal(t, StatusFail, check.Status)
+	assert.Contains(t, check.Detail, readOnly)
+}
@@ -0,0 +1,54 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+)
+
+// skuFeatures reads the features of the firmware SKU from the MEI. The features are
+// unknown, with an empty SKU class, for a remote device or when the MEI can not be read,
+// the commands then run as they would on the AMT SKU.
+func (service *ProvisioningService) skuFeatures() info.AMTFeatures {
+	if service.flags.IsRemote() {
+		return info.AMTFeatures{}
+	}
+	version, err := service.amtCommand.GetVersionDataFromME("AMT", service.flags.AMTTimeoutDuration)
+	if err != nil {
+		log.Debug("unable to read the AMT version for the SKU class: ", err)
+		return info.AMTFeatures{}
+	}
+	sku, err := service.amtCommand.GetVersionDataFromME("Sku", service.flags.AMTTimeoutDuration)
+	if err != nil {
+		log.Debug("unable to read the SKU for the SKU class: ", err)
+		return info.AMTFeatures{}
+	}
+	return info.DecodeAMTFeatures(version, sku)
+}
+
+// lacksWiFi tells whether the firmware SKU is known not to manage the WiFi port, it logs
+// the reason at the level of the logger given
+func (service *ProvisioningService) lacksWiFi(logf func(format string, args ...interface{})) bool {
+	features := service.skuFeatures()
+	if features.SKUClass == "" || features.WiFiSupported {
+		return false
+	}
+	logf("the %s firmware does not manage the WiFi port", features.SKUClassName())
+	return true
+}
+
+// checkActivationSKU fails the activation of firmware without AMT or Standard
+// Manageability before anything is changed, as AMT would reject it with a generic error
+func (service *ProvisioningService) checkActivationSKU() utils.ReturnCode {
+	switch features := service.skuFeatures(); features.SKUClass {
+	case info.SKUClassNone:
+		log.Errorf("the firmware SKU '%s' has neither AMT nor Standard Manageability, it can not be activated", features.SKU)
+		return utils.UnsupportedBySKU
+	case info.SKUClassStandardManageability:
+		log.Info("Status: activating Standard Manageability, KVM and the WiFi port are not available")
+	}
+	return utils.Success
+}
@@ -0,0 +1,54 @@
+package local
+
+import (
+	"bytes"
+	"rpc/internal/flags"
+	"rpc/internal/info"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSKU makes the mock report the version and SKU until the test ends
+func mockSKU(t *testing.T, version, sku string) {
+	t.Cleanup(func() { mockVersionData = map[string]string{} })
+	mockVersionData = map[string]string{"AMT": version, "Sku": sku}
+}
+
+func TestSKUFeatures(t *testing.T) {
+	lps := setupService(&flags.Flags{})
+	assert.Empty(t, lps.skuFeatures().SKUClass, "the version of the mock can not be decoded")
+
+	mockSKU(t, "16.1.25", "16400")
+	assert.Equal(t, info.SKUClassStandardManageability, lps.skuFeatures().SKUClass)
+	assert.Equal(t, utils.Success, lps.checkActivationSKU())
+
+	mockSKU(t, "16.1.25", "16392")
+	assert.Equal(t, info.SKUClassAMT, lps.skuFeatures().SKUClass)
+
+	mockSKU(t, "16.1.25", "16384")
+	assert.Equal(t, utils.UnsupportedBySKU, lps.checkActivationSKU())
+}
+
+func TestStandardManageabilityWiFi(t *testing.T) {
+	mockSKU(t, "16.1.25", "16400")
+	f := &flags.Flags{}
+	f.LocalConfig.WifiConfigs = append(f.LocalConfig.WifiConfigs, wifiCfgWPA)
+	// no WS-MAN request is sent, the client of setupService has no server
+	lps := setupService(f)
+	assert.Equal(t, utils.UnsupportedBySKU, lps.AddWifiSettings())
+	assert.Equal(t, utils.UnsupportedBySKU, lps.EnableWifiPort())
+	assert.Equal(t, utils.Success, lps.SyncWifi())
+}
+
+func TestDisplayAMTInfoSKUClass(t *testing.T) {
+	mockSKU(t, "16.1.25", "16400")
+	f := &flags.Flags{}
+	f.AmtInfo.Ver, f.AmtInfo.Sku = true, true
+	lps := setupService(f)
+	var buf bytes.Buffer
+	lps.out = &buf
+	assert.Equal(t, utils.Success, lps.DisplayAMTInfo())
+	assert.Regexp(t, `SKU Class\t+: Standard Manageability\n`, buf.String())
+}
@@ -0,0 +1,136 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"rpc/internal/sol"
+	"rpc/pkg/utils"
+	"strconv"
+)
+
+// solEscape ends the session, Ctrl+] as in telnet
+const solEscape = 0x1d
+
+// dialRedirection connects to the redirection port of AMT, TLS is used when tlsConfig is
+// not nil. It is replaced in tests.
+var dialRedirection = func(address string, tlsConfig *tls.Config) (net.Conn, error) {
+	if tlsConfig != nil {
+		return tls.Dial("tcp", address, tlsConfig)
+	}
+	return net.Dial("tcp", address)
+}
+
+// solInput is where the keystrokes of the session are read from, it is replaced in tests
+var solInput io.Reader = os.Stdin
+
+// SOL opens a Serial-over-LAN session to the console of the device through LMS, or to the
+// device of -host, until Ctrl+] is pressed or AMT ends the session
+func (service *ProvisioningService) SOL() utils.ReturnCode {
+	console := service.out
+	if path := service.flags.SOL.SessionLog; path != "" {
+		sessionLog, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Error("unable to open the session log: ", err)
+			return utils.SOLSessionFailed
+		}
+		defer sessionLog.Close()
+		console = io.MultiWriter(service.out, sessionLog)
+	}
+
+	host, port, user := utils.LMSAddress, sol.RedirectionPort, "admin"
+	var tlsConfig *tls.Config
+	if service.flags.IsRemote() {
+		remote := service.flags.Remote
+		host, user = remote.Host, remote.User
+		if remote.TLS {
+			port = sol.RedirectionTLSPort
+			tlsConfig = &tls.Config{RootCAs: remote.CACerts, InsecureSkipVerify: remote.SkipCertCheck}
+		}
+		// -amtPort is the redirection port of the device for sol
+		if remote.Port != 0 {
+			port = remote.Port
+		}
+	}
+	conn, err := dialRedirection(net.JoinHostPort(host, strconv.Itoa(port)), tlsConfig)
+	if err != nil {
+		log.Error("unable to connect to the redirection port of AMT: ", err)
+		return utils.AMTConnectionFailed
+	}
+	session, err := sol.Open(conn, user, service.flags.Password)
+	if errors.Is(err, sol.ErrAuthentication) {
+		log.Error(err)
+		return utils.AMTAuthenticationFailed
+	}
+	if err != nil {
+		log.Error("unable to start the SOL session: ", err)
+		return utils.SOLSessionFailed
+	}
+	defer session.Close()
+	if service.flags.Context != nil {
+		// SIGTERM ends the session, Ctrl+C is sent to the device in raw mode
+		go func() {
+			<-service.flags.Context.Done()
+			session.Close()
+		}()
+	}
+
+	if terminal, ok := solInput.(*os.File); ok {
+		restore, err := sol.MakeRaw(terminal)
+		if err != nil {
+			log.Debug("the terminal stays in line mode: ", err)
+		} else {
+			defer restore()
+		}
+	}
+	fmt.Fprintf(service.out, "Connected to the SOL console of %s, press Ctrl+] to quit.\r\n", host)
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(console, session)
+		done <- err
+	}()
+	go func() {
+		done <- copyUntilEscape(session, solInput)
+	}()
+	err = <-done
+	switch {
+	case service.cancelled():
+		return utils.CancelledByUser
+	case err != nil:
+		log.Error("the SOL session ended with an error: ", err)
+		return utils.SOLSessionFailed
+	}
+	fmt.Fprint(service.out, "\r\nThe SOL session is closed.\r\n")
+	return utils.Success
+}
+
+// copyUntilEscape sends the keystrokes of input to the session until solEscape or the end of input
+func copyUntilEscape(session io.Writer, input io.Reader) error {
+	buf := make([]byte, 1024)
+	for {
+		n, err := input.Read(buf)
+		data := buf[:n]
+		escape := bytes.IndexByte(data, solEscape)
+		if escape >= 0 {
+			data = data[:escape]
+		}
+		if _, writeErr := session.Write(data); writeErr != nil {
+			return writeErr
+		}
+		if escape >= 0 || errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package local
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSOL(t *testing.T) {
+	defer func(dial func(string, *tls.Config) (net.Conn, error)) { dialRedirection = dial }(dialRedirection)
+
+	t.Run("connects to LMS", func(t *testing.T) {
+		var address string
+		dialRedirection = func(a string, tlsConfig *tls.Config) (net.Conn, error) {
+			address = a
+			assert.Nil(t, tlsConfig)
+			return nil, errors.New("connection refused")
+		}
+		f := &flags.Flags{}
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTConnectionFailed, lps.SOL())
+		assert.Equal(t, "localhost:16994", address)
+	})
+	t.Run("connects to the redirection TLS port of -host", func(t *testing.T) {
+		var address string
+		dialRedirection = func(a string, tlsConfig *tls.Config) (net.Conn, error) {
+			address = a
+			assert.True(t, tlsConfig.InsecureSkipVerify)
+			return nil, errors.New("connection refused")
+		}
+		f := &flags.Flags{}
+		f.Remote.Host, f.Remote.TLS, f.Remote.SkipCertCheck = "192.168.1.20", true, true
+		lps := setupService(f)
+		assert.Equal(t, utils.AMTConnectionFailed, lps.SOL())
+		assert.Equal(t, "192.168.1.20:16995", address)
+	})
+	t.Run("a refused session fails", func(t *testing.T) {
+		dialRedirection = func(string, *tls.Config) (net.Conn, error) {
+			client, server := net.Pipe()
+			go func() {
+				io.ReadFull(server, make([]byte, 8))
+				server.Write([]byte{0x11, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+				server.Close()
+			}()
+			return client, nil
+		}
+		f := &flags.Flags{}
+		lps := setupService(f)
+		assert.Equal(t, utils.SOLSessionFailed, lps.SOL())
+	})
+	t.Run("a session log that can not be opened fails", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.SOL.SessionLog = filepath.Join(t.TempDir(), "missing", "console.log")
+		lps := setupService(f)
+		assert.Equal(t, utils.SOLSessionFailed, lps.SOL())
+	})
+}
+
+func TestCopyUntilEscape(t *testing.T) {
+	t.Run("stops at Ctrl+]", func(t *testing.T) {
+		var session bytes.Buffer
+		assert.NoError(t, copyUntilEscape(&session, strings.NewReader("dir\r\x1dexit\r")))
+		assert.Equal(t, "dir\r", session.String())
+	})
+	t.Run("stops at the end of input", func(t *testing.T) {
+		var session bytes.Buffer
+		assert.NoError(t, copyUntilEscape(&session, strings.NewReader("dir\r")))
+		assert.Equal(t, "dir\r", session.String())
+	})
+}
@@ -0,0 +1,217 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"fmt"
+	"os"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+)
+
+const (
+	StatusPass = "PASS"
+	StatusWarn = "WARN"
+	StatusFail = "FAIL"
+)
+
+// StatusCheck is the outcome of one health check of rpc status
+type StatusCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+const statusNeedsPassword = "skipped, needs the AMT password"
+
+// Status checks the provisioning health of the device and prints a PASS, WARN or FAIL
+// line per check. The return code is the worst result, so it can be used by monitoring.
+func (service *ProvisioningService) Status() utils.ReturnCode {
+	if rc := service.localOnly("status"); rc != utils.Success {
+		return rc
+	}
+	checks := service.StatusChecks()
+	status := StatusPass
+	for _, check := range checks {
+		if check.Status == StatusFail || check.Status == StatusWarn && status == StatusPass {
+			status = check.Status
+		}
+	}
+
+	w := service.newOutputWriter()
+	w.Field("checks", "", checks)
+	w.Field("status", "", status)
+	for _, check := range checks {
+		w.Printf("%-4s %-16s %s\n", check.Status, check.Name, check.Detail)
+	}
+	w.Printf("%-4s %s\n", status, "overall")
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+
+	switch status {
+	case StatusFail:
+		return utils.StatusCheckFailed
+	case StatusWarn:
+		return utils.StatusCheckWarning
+	}
+	return utils.Success
+}
+
+// StatusChecks runs the health checks of this host. The checks through the MEI always
+// run, those through WS-MAN are skipped with a warning when no AMT password was given.
+func (service *ProvisioningService) StatusChecks() []StatusCheck {
+	mode := StatusCheck{Name: "controlMode"}
+	controlMode, err := service.amtCommand.GetControlMode()
+	if err != nil {
+		mode.Status, mode.Detail = StatusFail, err.Error()
+		// nothing else can be checked without the MEI
+		return []StatusCheck{mode}
+	}
+	mode.Status, mode.Detail = StatusPass, utils.InterpretControlMode(controlMode)
+	if controlMode == 0 {
+		mode.Status = StatusFail
+	}
+	checks := []StatusCheck{mode, service.ciraCheck()}
+
+	names := []string{"tls", "clock", "hostname", "certificates"}
+	if controlMode == 0 || service.flags.Password == "" {
+		detail := statusNeedsPassword
+		if controlMode == 0 {
+			detail = "skipped, AMT is not activated"
+		}
+		for _, name := range names {
+			checks = append(checks, StatusCheck{Name: name, Status: StatusWarn, Detail: detail})
+		}
+		return checks
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	return append(checks,
+		service.tlsCheck(),
+		service.clockCheck(),
+		service.hostnameCheck(),
+		service.certificatesCheck(),
+	)
+}
+
+func (service *ProvisioningService) ciraCheck() StatusCheck {
+	check := StatusCheck{Name: "cira"}
+	ras, err := service.amtCommand.GetRemoteAccessConnectionStatus()
+	switch {
+	case err != nil:
+		check.Status, check.Detail = StatusFail, err.Error()
+	case ras.RemoteStatus == "connected":
+		check.Status, check.Detail = StatusPass, "connected to "+ras.MPSHostname
+	case ras.MPSHostname == "":
+		check.Status, check.Detail = StatusPass, "not configured"
+	default:
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("%s to %s", ras.RemoteStatus, ras.MPSHostname)
+	}
+	return check
+}
+
+func (service *ProvisioningService) tlsCheck() StatusCheck {
+	check := StatusCheck{Name: "tls"}
+	var settings TLSSettingDataPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.TLSSettingData.Enumerate,
+		service.amtMessages.TLSSettingData.Pull,
+		&settings,
+	)
+	if rc != utils.Success {
+		check.Status, check.Detail = StatusFail, rc.String()
+		return check
+	}
+	for _, item := range settings.Body.PullResponse.Items {
+		if item.InstanceID == remoteTLSInstanceID && item.Enabled {
+			check.Status, check.Detail = StatusPass, "enabled"
+			if item.MutualAuthentication {
+				check.Detail = "enabled, mutual authentication"
+			}
+			return check
+		}
+	}
+	check.Status, check.Detail = StatusWarn, "not enabled"
+	return check
+}
+
+func (service *ProvisioningService) clockCheck() StatusCheck {
+	check := StatusCheck{Name: "clock"}
+	var rsp GetLowAccuracyTimeSynchResponse
+	if rc := service.PostAndUnmarshal(service.amtMessages.TimeSynchronizationService.GetLowAccuracyTimeSynch(), &rsp); rc != utils.Success {
+		check.Status, check.Detail = StatusFail, rc.String()
+		return check
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		check.Status, check.Detail = StatusFail, fmt.Sprintf("GetLowAccuracyTimeSynch_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return check
+	}
+	skew := time.Since(time.Unix(rsp.Body.Output.Ta0, 0)).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	check.Status, check.Detail = StatusPass, fmt.Sprintf("skew %s", skew)
+	if skew > service.flags.Status.MaxSkew {
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("skew %s is above %s", skew, service.flags.Status.MaxSkew)
+	}
+	return check
+}
+
+func (service *ProvisioningService) hostnameCheck() StatusCheck {
+	check := StatusCheck{Name: "hostname"}
+	settings, err := service.GetGeneralSettings()
+	if err != nil {
+		check.Status, check.Detail = StatusFail, err.Error()
+		return check
+	}
+	osHostname, err := os.Hostname()
+	if err != nil {
+		check.Status, check.Detail = StatusWarn, err.Error()
+		return check
+	}
+	// AMT keeps the short hostname, the DNS suffix is a separate setting
+	osHostname, _, _ = strings.Cut(osHostname, ".")
+	amtHostname := settings.Body.AMTGeneralSettings.HostName
+	if !strings.EqualFold(amtHostname, osHostname) {
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("AMT has %q, the OS has %q", amtHostname, osHostname)
+		return check
+	}
+	check.Status, check.Detail = StatusPass, amtHostname
+	return check
+}
+
+func (service *ProvisioningService) certificatesCheck() StatusCheck {
+	check := StatusCheck{Name: "certificates"}
+	var certs []publickey.PublicKeyCertificate
+	if rc := service.GetPublicKeyCerts(&certs); rc != utils.Success {
+		check.Status, check.Detail = StatusFail, rc.String()
+		return check
+	}
+	now := time.Now()
+	warnBefore := now.AddDate(0, 0, service.flags.Status.CertWarnDays)
+	var expired, expiring []string
+	for _, cert := range certs {
+		info := NewPublicKeyCertInfo(cert)
+		switch {
+		case info.NotAfter.IsZero():
+		case info.NotAfter.Before(now):
+			expired = append(expired, cert.InstanceID)
+		case info.NotAfter.Before(warnBefore):
+			expiring = append(expiring, cert.InstanceID)
+		}
+	}
+	switch {
+	case len(expired) > 0:
+		check.Status, check.Detail = StatusFail, "expired: "+strings.Join(expired, ", ")
+	case len(expiring) > 0:
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("expire within %d days: %s", service.flags.Status.CertWarnDays, strings.Join(expiring, ", "))
+	default:
+		check.Status, check.Detail = StatusPass, fmt.Sprintf("%d valid", len(certs))
+	}
+	return check
+}
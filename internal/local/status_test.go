@@ -0,0 +1,139 @@
+package local
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	amt2 "rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/general"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandStatus
+	f.JsonOutput = true
+	f.Status = flags.StatusFlags{MaxSkew: 2 * time.Minute, CertWarnDays: 30}
+	origMode, origRAS := mockControlMode, mockRemoteAcessConnectionStatus
+	mockControlMode = 1
+	defer func() { mockControlMode, mockRemoteAcessConnectionStatus = origMode, origRAS }()
+
+	hostname, _ := os.Hostname()
+	hostname, _, _ = strings.Cut(hostname, ".")
+	generalSettings := general.Response{}
+	generalSettings.Body.AMTGeneralSettings.HostName = hostname
+	tlsSettings := TLSSettingDataPullResponse{}
+	tlsSettings.Body.PullResponse.Items = []TLSSettingDataItem{{InstanceID: remoteTLSInstanceID, Enabled: true}}
+	clock := GetLowAccuracyTimeSynchResponse{}
+	clock.Body.Output.Ta0 = time.Now().Unix()
+	certs := publickey.PullResponseEnvelope{}
+	certs.Body.PullResponse.Items = []publickey.PublicKeyCertificate{{
+		InstanceID:      "Intel(r) AMT Certificate: Handle: 1",
+		X509Certificate: base64.StdEncoding.EncodeToString(getTestCerts().CaCert.Raw),
+	}}
+	healthy := func() ResponseFuncArray {
+		return ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondMsgFunc(t, clock),
+			respondMsgFunc(t, generalSettings),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, certs),
+		}
+	}
+
+	run := func(rfa ResponseFuncArray) (utils.ReturnCode, map[string]string, string) {
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.Status()
+		var result struct {
+			Checks []StatusCheck `json:"checks"`
+			Status string        `json:"status"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		statuses := map[string]string{}
+		for _, check := range result.Checks {
+			statuses[check.Name] = check.Status
+		}
+		return rc, statuses, result.Status
+	}
+
+	t.Run("passes when all checks pass", func(t *testing.T) {
+		f.Password = "P@ssw0rd"
+		defer func() { f.Password = "" }()
+		rc, statuses, status := run(healthy())
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, StatusPass, status)
+		assert.Equal(t, map[string]string{
+			"controlMode": StatusPass, "cira": StatusPass, "tls": StatusPass,
+			"clock": StatusPass, "hostname": StatusPass, "certificates": StatusPass,
+		}, statuses)
+	})
+	t.Run("warns about expiring certificates and a disconnected MPS", func(t *testing.T) {
+		f.Password = "P@ssw0rd"
+		f.Status.CertWarnDays = 60
+		mockRemoteAcessConnectionStatus = amt2.RemoteAccessStatus{RemoteStatus: "not connected", MPSHostname: "mps.example.com"}
+		defer func() {
+			f.Password, f.Status.CertWarnDays = "", 30
+			mockRemoteAcessConnectionStatus = origRAS
+		}()
+		rc, statuses, status := run(healthy())
+		assert.Equal(t, utils.StatusCheckWarning, rc)
+		assert.Equal(t, StatusWarn, status)
+		assert.Equal(t, StatusWarn, statuses["cira"])
+		assert.Equal(t, StatusWarn, statuses["certificates"])
+		assert.Equal(t, StatusPass, statuses["tls"])
+	})
+	t.Run("warns about clock skew", func(t *testing.T) {
+		f.Password = "P@ssw0rd"
+		defer func() { f.Password = "" }()
+		rfa := healthy()
+		skewed := clock
+		skewed.Body.Output.Ta0 = time.Now().Add(-time.Hour).Unix()
+		rfa[2] = respondMsgFunc(t, skewed)
+		rc, statuses, _ := run(rfa)
+		assert.Equal(t, utils.StatusCheckWarning, rc)
+		assert.Equal(t, StatusWarn, statuses["clock"])
+	})
+	t.Run("skips the WS-MAN checks without a password", func(t *testing.T) {
+		rc, statuses, status := run(ResponseFuncArray{})
+		assert.Equal(t, utils.StatusCheckWarning, rc)
+		assert.Equal(t, StatusWarn, status)
+		assert.Equal(t, StatusPass, statuses["controlMode"])
+		assert.Equal(t, StatusWarn, statuses["tls"])
+		assert.Equal(t, StatusWarn, statuses["certificates"])
+	})
+	t.Run("fails when AMT is not activated", func(t *testing.T) {
+		mockControlMode = 0
+		defer func() { mockControlMode = 1 }()
+		rc, statuses, status := run(ResponseFuncArray{})
+		assert.Equal(t, utils.StatusCheckFailed, rc)
+		assert.Equal(t, StatusFail, status)
+		assert.Equal(t, StatusFail, statuses["controlMode"])
+	})
+	t.Run("fails when AMT does not answer", func(t *testing.T) {
+		f.Password = "P@ssw0rd"
+		defer func() { f.Password = "" }()
+		rfa := healthy()
+		rfa[3] = respondServerErrFunc()
+		rc, statuses, _ := run(rfa)
+		assert.Equal(t, utils.StatusCheckFailed, rc)
+		assert.Equal(t, StatusFail, statuses["hostname"])
+	})
+	t.Run("returns InvalidParameterCombination for a remote device", func(t *testing.T) {
+		f.Remote.Host = "amt.example.com"
+		defer func() { f.Remote.Host = "" }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{})
+		assert.Equal(t, utils.InvalidParameterCombination, lps.Status())
+	})
+}
@@ -0,0 +1,299 @@
+package local
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"rpc/pkg/utils"
+	"strings"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/tls"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
+)
+
+const (
+	keyPairResourceURI  = "http://intel.com/wbem/wscim/1/amt-schema/1/AMT_PublicPrivateKeyPair"
+	remoteTLSInstanceID = "Intel(r) AMT 802.3 TLS Settings"
+	localTLSInstanceID  = "Intel(r) AMT LMS TLS Settings"
+)
+
+type GenerateKeyPairResponse struct {
+	Body struct {
+		Output struct {
+			KeyPair struct {
+				ReferenceParameters models.ReferenceParameters_OUTPUT `xml:"ReferenceParameters"`
+			} `xml:"KeyPair"`
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"GenerateKeyPair_OUTPUT"`
+	} `xml:"Body"`
+}
+
+type GeneratePKCS10RequestExResponse struct {
+	Body struct {
+		Output struct {
+			SignedCertificateRequest string `xml:"SignedCertificateRequest"`
+			ReturnValue              int    `xml:"ReturnValue"`
+		} `xml:"GeneratePKCS10RequestEx_OUTPUT"`
+	} `xml:"Body"`
+}
+
+// ConfigureTLS generates a key pair and CSR in AMT or, when a signed certificate is
+// provided, installs the certificate and enables TLS
+func (service *ProvisioningService) ConfigureTLS() utils.ReturnCode {
+	if service.flags.TLSSettings.Cert == "" {
+		return service.GenerateTLSCSR()
+	}
+	return service.EnableTLS()
+}
+
+// GenerateTLSCSR generates a RSA key pair in AMT and has AMT sign a CSR for it.
+// The private key never leaves AMT.
+func (service *ProvisioningService) GenerateTLSCSR() utils.ReturnCode {
+	var keyPairRsp GenerateKeyPairResponse
+	xmlMsg := service.amtMessages.PublicKeyManagementService.GenerateKeyPair(publickey.GenerateKeyPair_INPUT{
+		KeyAlgorithm: publickey.RSA,
+		KeyLength:    publickey.KeyLength2048,
+	})
+	if rc := service.PostAndUnmarshal(xmlMsg, &keyPairRsp); rc != utils.Success {
+		return utils.TLSConfigurationFailed
+	}
+	if rc := checkReturnValue(utils.ReturnCode(keyPairRsp.Body.Output.ReturnValue), "key pair"); rc != utils.Success {
+		return rc
+	}
+	var handle string
+	if len(keyPairRsp.Body.Output.KeyPair.ReferenceParameters.SelectorSet.Selector) > 0 {
+		handle = keyPairRsp.Body.Output.KeyPair.ReferenceParameters.SelectorSet.Selector[0].Value
+	}
+	log.Infof("generated key pair: %s", handle)
+
+	csr, rc := service.signCSR(handle)
+	if rc != utils.Success {
+		log.Infof("rolling back key pair %s", handle)
+		service.DeletePublicPrivateKeyPair(handle)
+		return rc
+	}
+	csrPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+	if service.flags.TLSSettings.CSRFile != "" {
+		if err := os.WriteFile(service.flags.TLSSettings.CSRFile, []byte(csrPEM), 0644); err != nil {
+			log.Error("unable to write CSR: ", err)
+			return utils.TLSConfigurationFailed
+		}
+		log.Infof("CSR written to %s", service.flags.TLSSettings.CSRFile)
+	} else {
+		w := service.newOutputWriter()
+		w.Field("csr", "", csrPEM)
+		w.Printf("%s", csrPEM)
+		if err := w.Flush(); err != nil {
+			log.Error(err)
+		}
+	}
+	log.Info("Status: CSR generated. Sign it with your CA and run again with -cert to enable TLS")
+	return utils.Success
+}
+
+// signCSR builds a null signed CSR for the public key of the key pair and has AMT sign it
+func (service *ProvisioningService) signCSR(keyPairHandle string) ([]byte, utils.ReturnCode) {
+	var keyPairs []publicprivate.PublicPrivateKeyPair
+	if rc := service.GetPublicPrivateKeyPairs(&keyPairs); rc != utils.Success {
+		return nil, utils.TLSConfigurationFailed
+	}
+	var derKey string
+	for _, keyPair := range keyPairs {
+		if keyPair.InstanceID == keyPairHandle {
+			derKey = keyPair.DERKey
+		}
+	}
+	if derKey == "" {
+		log.Errorf("key pair %s not found", keyPairHandle)
+		return nil, utils.TLSConfigurationFailed
+	}
+	commonName := service.flags.TLSSettings.CommonName
+	if commonName == "" {
+		commonName, _ = os.Hostname()
+	}
+	nullSignedCSR, err := newNullSignedCSR(derKey, commonName)
+	if err != nil {
+		log.Error("unable to create CSR: ", err)
+		return nil, utils.TLSConfigurationFailed
+	}
+
+	var csrRsp GeneratePKCS10RequestExResponse
+	if rc := service.PostAndUnmarshal(service.generatePKCS10RequestEx(keyPairHandle, nullSignedCSR), &csrRsp); rc != utils.Success {
+		return nil, utils.TLSConfigurationFailed
+	}
+	if rc := checkReturnValue(utils.ReturnCode(csrRsp.Body.Output.ReturnValue), "certificate signing request"); rc != utils.Success {
+		return nil, rc
+	}
+	csr, err := base64.StdEncoding.DecodeString(csrRsp.Body.Output.SignedCertificateRequest)
+	if err != nil {
+		log.Error("unable to decode signed CSR: ", err)
+		return nil, utils.TLSConfigurationFailed
+	}
+	return csr, utils.Success
+}
+
+// generatePKCS10RequestEx creates the GeneratePKCS10RequestEx message. The library
+// escapes the key pair reference as text, AMT expects it as XML.
+func (service *ProvisioningService) generatePKCS10RequestEx(keyPairHandle string, nullSignedCSR string) string {
+	keyPair := fmt.Sprintf(`<a:Address>/wsman</a:Address><a:ReferenceParameters><w:ResourceURI>%s</w:ResourceURI><w:SelectorSet><w:Selector Name="InstanceID">%s</w:Selector></w:SelectorSet></a:ReferenceParameters>`, keyPairResourceURI, keyPairHandle)
+	xmlMsg := service.amtMessages.PublicKeyManagementService.GeneratePKCS10RequestEx(publickey.PKCS10Request{
+		KeyPair:                      keyPair,
+		NullSignedCertificateRequest: nullSignedCSR,
+		SigningAlgorithm:             publickey.SHA256RSA,
+	})
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(keyPair))
+	return strings.Replace(xmlMsg, escaped.String(), keyPair, 1)
+}
+
+type certificationRequestInfo struct {
+	Version       int
+	Subject       asn1.RawValue
+	PublicKey     asn1.RawValue
+	RawAttributes []asn1.RawValue `asn1:"tag:0"`
+}
+
+type certificationRequest struct {
+	Info               certificationRequestInfo
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+}
+
+// newNullSignedCSR returns a base64 encoded CSR for the PKCS#1 public key with an all zero
+// signature. AMT replaces the signature using the private key it holds.
+func newNullSignedCSR(derKey string, commonName string) (string, error) {
+	pkcs1, err := base64.StdEncoding.DecodeString(derKey)
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := x509.ParsePKCS1PublicKey(pkcs1)
+	if err != nil {
+		return "", err
+	}
+	publicKeyInfo, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	subject, err := asn1.Marshal(pkix.Name{CommonName: commonName}.ToRDNSequence())
+	if err != nil {
+		return "", err
+	}
+	csr, err := asn1.Marshal(certificationRequest{
+		Info: certificationRequestInfo{
+			Subject:       asn1.RawValue{FullBytes: subject},
+			PublicKey:     asn1.RawValue{FullBytes: publicKeyInfo},
+			RawAttributes: []asn1.RawValue{},
+		},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{
+			// sha256WithRSAEncryption
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11},
+			Parameters: asn1.NullRawValue,
+		},
+		Signature: nullSignature(publicKey),
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(csr), nil
+}
+
+func nullSignature(publicKey *rsa.PublicKey) asn1.BitString {
+	return asn1.BitString{Bytes: make([]byte, publicKey.Size()), BitLength: publicKey.Size() * 8}
+}
+
+// EnableTLS installs the signed certificate and enables TLS on the remote, and optionally
+// the local, interface. A failed step rolls back the steps made before it.
+func (service *ProvisioningService) EnableTLS() utils.ReturnCode {
+	// start with fresh map
+	service.handlesWithCerts = make(map[string]string)
+	settings := service.flags.TLSSettings
+	tx := service.beginConfig("TLS")
+
+	if settings.CACert != "" {
+		if _, rc := tx.addCertificate("root certificate", settings.CACert, service.AddTrustedRootCert, service.amtMessages.PublicKeyCertificate.Delete); rc != utils.Success {
+			return tx.rollback(rc)
+		}
+	}
+	certHandle, rc := tx.addCertificate("TLS certificate", settings.Cert, service.AddClientCert, service.amtMessages.PublicKeyCertificate.Delete)
+	if rc != utils.Success {
+		return tx.rollback(rc)
+	}
+	if _, err := service.client.Post(service.amtMessages.TLSCredentialContext.Create(certHandle)); err != nil {
+		log.Error("unable to use the certificate for TLS, an existing TLS certificate must be removed first: ", err)
+		return tx.rollback(utils.TLSConfigurationFailed)
+	}
+	tx.appliedPost("TLS credential context "+certHandle, service.amtMessages.TLSCredentialContext.Delete(certHandle))
+	return service.putTLSSettings(tx)
+}
+
+// PutTLSSettings enables TLS in the mode of the flags with the certificate AMT already
+// uses for TLS and commits the change
+func (service *ProvisioningService) PutTLSSettings() utils.ReturnCode {
+	return service.putTLSSettings(service.beginConfig("TLS"))
+}
+
+func (service *ProvisioningService) putTLSSettings(tx *configTransaction) utils.ReturnCode {
+	settings := service.flags.TLSSettings
+	var tlsSettings TLSSettingDataPullResponse
+	rc := service.EnumPullUnmarshal(
+		service.amtMessages.TLSSettingData.Enumerate,
+		service.amtMessages.TLSSettingData.Pull,
+		&tlsSettings,
+	)
+	if rc != utils.Success {
+		return tx.rollback(utils.TLSConfigurationFailed)
+	}
+	for _, item := range tlsSettings.Body.PullResponse.Items {
+		tlsSettingData := tls.TLSSettingData{
+			Enabled:                    true,
+			AcceptNonSecureConnections: item.AcceptNonSecureConnections,
+		}
+		switch item.InstanceID {
+		case remoteTLSInstanceID:
+			tlsSettingData.MutualAuthentication = settings.Mode.IsMutual()
+			tlsSettingData.AcceptNonSecureConnections = settings.Mode.AllowsNonTLS()
+			tlsSettingData.TrustedCN = settings.TrustedCN
+		case localTLSInstanceID:
+			if !settings.Local {
+				continue
+			}
+		default:
+			continue
+		}
+		tlsSettingData.ElementName = item.ElementName
+		tlsSettingData.InstanceID = item.InstanceID
+		log.Infof("enabling TLS: %s", item.InstanceID)
+		if _, err := service.client.Post(service.amtMessages.TLSSettingData.Put(tlsSettingData)); err != nil {
+			log.Errorf("unable to enable TLS: %s %s", item.InstanceID, err)
+			return tx.rollback(utils.TLSConfigurationFailed)
+		}
+		// the settings are put back as they were, they take effect with the commit only
+		previous := tls.TLSSettingData{
+			Enabled:                    item.Enabled,
+			MutualAuthentication:       item.MutualAuthentication,
+			AcceptNonSecureConnections: item.AcceptNonSecureConnections,
+			TrustedCN:                  item.TrustedCN,
+		}
+		previous.ElementName = item.ElementName
+		previous.InstanceID = item.InstanceID
+		tx.appliedPost("TLS settings "+item.InstanceID, service.amtMessages.TLSSettingData.Put(previous))
+	}
+	// TLS changes only take effect after they are committed
+	if _, err := service.client.Post(service.amtMessages.SetupAndConfigurationService.CommitChanges()); err != nil {
+		log.Error("unable to commit TLS changes: ", err)
+		return tx.rollback(utils.TLSConfigurationFailed)
+	}
+	log.Infof("Status: TLS enabled in %s mode", settings.Mode)
+	if settings.Local && !service.flags.IsRemote() {
+		service.useLocalTLS()
+	}
+	return utils.Success
+}
@@ -0,0 +1,223 @@
+package local
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/internal/lm"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const generateKeyPairXMLResponse = `<?xml version="1.0" encoding="UTF-8"?><a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:b="http://schemas.xmlsoap.org/ws/2004/08/addressing" xmlns:c="http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd" xmlns:g="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_PublicKeyManagementService"><a:Body><g:GenerateKeyPair_OUTPUT><g:KeyPair><b:Address>http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</b:Address><b:ReferenceParameters><c:ResourceURI>http://intel.com/wbem/wscim/1/amt-schema/1/AMT_PublicPrivateKeyPair</c:ResourceURI><c:SelectorSet><c:Selector Name="InstanceID">Intel(r) AMT Key: Handle: 0</c:Selector></c:SelectorSet></b:ReferenceParameters></g:KeyPair><g:ReturnValue>0</g:ReturnValue></g:GenerateKeyPair_OUTPUT></a:Body></a:Envelope>`
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key, base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(&key.PublicKey))
+}
+
+func TestNewNullSignedCSR(t *testing.T) {
+	key, derKey := newTestKey(t)
+	csr, err := newNullSignedCSR(derKey, "device.example.com")
+	assert.NoError(t, err)
+	der, err := base64.StdEncoding.DecodeString(csr)
+	assert.NoError(t, err)
+	req, err := x509.ParseCertificateRequest(der)
+	assert.NoError(t, err)
+	assert.Equal(t, "device.example.com", req.Subject.CommonName)
+	assert.Equal(t, &key.PublicKey, req.PublicKey)
+	assert.Equal(t, x509.SHA256WithRSA, req.SignatureAlgorithm)
+
+	_, err = newNullSignedCSR("not a key", "device.example.com")
+	assert.Error(t, err)
+}
+
+func TestGeneratePKCS10RequestEx(t *testing.T) {
+	lps := setupService(&flags.Flags{})
+	xmlMsg := lps.generatePKCS10RequestEx("Intel(r) AMT Key: Handle: 0", "Q1NS")
+	assert.Contains(t, xmlMsg, `<h:KeyPair><a:Address>/wsman</a:Address><a:ReferenceParameters>`)
+	assert.Contains(t, xmlMsg, `<w:Selector Name="InstanceID">Intel(r) AMT Key: Handle: 0</w:Selector>`)
+	assert.NotContains(t, xmlMsg, "&lt;")
+}
+
+func TestGenerateTLSCSR(t *testing.T) {
+	key, derKey := newTestKey(t)
+	keyPairs := publicprivate.PullResponseEnvelope{}
+	keyPairs.Body.PullResponse.Items = []publicprivate.PublicPrivateKeyPair{
+		{InstanceID: "Intel(r) AMT Key: Handle: 0", DERKey: derKey},
+	}
+	signed, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}, key)
+	assert.NoError(t, err)
+	csrRsp := GeneratePKCS10RequestExResponse{}
+	csrRsp.Body.Output.SignedCertificateRequest = base64.StdEncoding.EncodeToString(signed)
+
+	t.Run("writes the signed CSR", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.CSRFile = filepath.Join(t.TempDir(), "amt.csr")
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, generateKeyPairXMLResponse),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, keyPairs),
+			respondMsgFunc(t, csrRsp),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.Success, rc)
+		content, err := os.ReadFile(f.TLSSettings.CSRFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "-----BEGIN CERTIFICATE REQUEST-----")
+	})
+	t.Run("rolls back the key pair when signing fails", func(t *testing.T) {
+		f := &flags.Flags{}
+		deleted := false
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, generateKeyPairXMLResponse),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, keyPairs),
+			respondServerErrFunc(),
+			func(w http.ResponseWriter, r *http.Request) { deleted = true },
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+		assert.True(t, deleted)
+	})
+	t.Run("fails when key pair is not found", func(t *testing.T) {
+		f := &flags.Flags{}
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, generateKeyPairXMLResponse),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, publicprivate.PullResponseEnvelope{}),
+			respondStringFunc(t, ""),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+	})
+	t.Run("fails on GenerateKeyPair error", func(t *testing.T) {
+		f := &flags.Flags{}
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+	})
+}
+
+func TestEnableTLS(t *testing.T) {
+	tlsSettings := TLSSettingDataPullResponse{}
+	tlsSettings.Body.PullResponse.Items = []TLSSettingDataItem{
+		{InstanceID: remoteTLSInstanceID},
+		{InstanceID: localTLSInstanceID},
+	}
+
+	t.Run("enables server authentication", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Mode = flags.TLSModeServer
+		f.TLSSettings.Cert = "cert"
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, "context created"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "remote enabled"),
+			respondStringFunc(t, "committed"),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.Success, rc)
+	})
+	t.Run("enables mutual authentication on both interfaces", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Mode = flags.TLSModeMutual
+		f.TLSSettings.Cert = "cert"
+		f.TLSSettings.CACert = "caCert"
+		f.TLSSettings.Local = true
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, "context created"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "remote enabled"),
+			respondStringFunc(t, "local enabled"),
+			respondStringFunc(t, "committed"),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.Success, rc)
+		// AMT answers on the TLS port of LMS only from now on
+		assert.Equal(t, lm.TransportLMSTLS, f.Transport)
+		assert.Equal(t, localTLSURL, lps.serverURL)
+		assert.IsType(t, &http.Transport{}, lps.client.Transport)
+	})
+	t.Run("fails when credential context cannot be created", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Cert = "cert"
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, clientCertXMLResponse),
+			respondServerErrFunc(),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+	})
+	t.Run("fails when commit fails", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Cert = "cert"
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, "context created"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "remote enabled"),
+			respondServerErrFunc(),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.TLSConfigurationFailed, rc)
+	})
+	t.Run("rolls back the certificate when credential context cannot be created", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Cert = "cert"
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, clientCertXMLResponse),
+			respondServerErrFunc(),
+			respondCheckBodyFunc(t, "deleted", "transfer/Delete", "Intel(r) AMT Certificate: Handle: 1"),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.PartialConfigRolledBack, rc)
+	})
+	t.Run("rolls back the settings, context and certificates when commit fails", func(t *testing.T) {
+		f := &flags.Flags{}
+		f.TLSSettings.Mode = flags.TLSModeServer
+		f.TLSSettings.Cert = "cert"
+		f.TLSSettings.CACert = "caCert"
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, "context created"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, tlsSettings),
+			respondStringFunc(t, "remote enabled"),
+			respondServerErrFunc(),
+			respondCheckBodyFunc(t, "remote restored", "<Enabled>false</Enabled>", remoteTLSInstanceID),
+			respondCheckBodyFunc(t, "context deleted", "AMT_TLSCredentialContext", "transfer/Delete"),
+			respondCheckBodyFunc(t, "deleted", "transfer/Delete", "Intel(r) AMT Certificate: Handle: 1"),
+			respondCheckBodyFunc(t, "deleted", "transfer/Delete", "Intel(r) AMT Certificate: Handle: 2"),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		rc := lps.ConfigureTLS()
+		assert.Equal(t, utils.PartialConfigRolledBack, rc)
+	})
+}
@@ -2,13 +2,13 @@ package local
 
 import (
 	"encoding/xml"
+	"errors"
 
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/concrete"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/credential"
 	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
-	log "github.com/sirupsen/logrus"
 	"reflect"
 	"rpc/pkg/utils"
 	"strings"
@@ -27,7 +27,17 @@ func reflectObjectName(v any) string {
 type EnumMessageFunc func() string
 type PullMessageFunc func(string) string
 
+// cancelled reports whether rpc was interrupted with SIGINT or SIGTERM. The WSMAN messages
+// of the command are not sent after that, the rollback of what was added still is.
+func (service *ProvisioningService) cancelled() bool {
+	return service.flags.Context != nil && service.flags.Context.Err() != nil
+}
+
 func (service *ProvisioningService) EnumPullUnmarshal(enumFn EnumMessageFunc, pullFn PullMessageFunc, outObj any) utils.ReturnCode {
+	if service.cancelled() {
+		log.Errorf("enumerate call for %s: cancelled by user", reflectObjectName(outObj))
+		return utils.CancelledByUser
+	}
 	xmlMsg := enumFn()
 	log.Trace(xmlMsg)
 	xmlRsp, err := service.client.Post(xmlMsg)
@@ -46,6 +56,10 @@ func (service *ProvisioningService) EnumPullUnmarshal(enumFn EnumMessageFunc, pu
 }
 
 func (service *ProvisioningService) PostAndUnmarshal(xmlMsg string, outObj any) utils.ReturnCode {
+	if service.cancelled() {
+		log.Errorf("post call for %s: cancelled by user", reflectObjectName(outObj))
+		return utils.CancelledByUser
+	}
 	log.Trace(xmlMsg)
 	xmlRsp, err := service.client.Post(xmlMsg)
 	log.Trace(string(xmlRsp))
@@ -60,6 +74,17 @@ func (service *ProvisioningService) PostAndUnmarshal(xmlMsg string, outObj any)
 	return utils.Success
 }
 
+// replaceMessageBody returns xmlMsg with body in place of its body, the header of a
+// go-wsman-messages message is reused for a body it does not create
+func replaceMessageBody(xmlMsg string, body []byte) (string, error) {
+	start := strings.Index(xmlMsg, "<Body>")
+	end := strings.LastIndex(xmlMsg, "</Body>")
+	if start < 0 || end < start {
+		return "", errors.New("unexpected message without body")
+	}
+	return xmlMsg[:start] + "<Body>" + string(body) + xmlMsg[end:], nil
+}
+
 func GetTokenFromKeyValuePairs(kvList string, token string) string {
 	attributes := strings.Split(kvList, ",")
 	tokenMap := make(map[string]string)
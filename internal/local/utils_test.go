@@ -1,6 +1,8 @@
 package local
 
 import (
+	"context"
+	"net/http"
 	"regexp"
 	"rpc/internal/flags"
 	"rpc/pkg/utils"
@@ -402,3 +404,24 @@ var concreteDependencyPullRspString = `
   </a:Body>
 </a:Envelope>
 `
+
+func TestPostAndUnmarshalCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := &flags.Flags{Context: ctx}
+	posts := 0
+	lps := setupWithWsmanClient(f, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Run("expect CancelledByUser without sending the message", func(t *testing.T) {
+		var certs []publickey.PublicKeyCertificate
+		rc := lps.GetPublicKeyCerts(&certs)
+		assert.Equal(t, utils.CancelledByUser, rc)
+		assert.Equal(t, 0, posts)
+	})
+	t.Run("expect the rollback to still be sent", func(t *testing.T) {
+		lps.RollbackAddedItems(&Handles{rootCertHandle: "handle 1"})
+		assert.Equal(t, 1, posts)
+	})
+}
@@ -1,41 +1,32 @@
 package local
 
 import (
-	"encoding/json"
 	"rpc/pkg/utils"
+	"runtime"
 	"strings"
 )
 
 func (service *ProvisioningService) DisplayVersion() utils.ReturnCode {
-	output := ""
-
-	if !service.flags.JsonOutput {
-		output += strings.ToUpper(utils.ProjectName) + "\n"
-		output += "Version " + utils.ProjectVersion + "\n"
-		output += "Protocol " + utils.ProtocolVersion + "\n"
-
-		println(output)
+	w := service.newOutputWriter()
+	commit, buildDate := utils.BuildInfo()
+
+	w.Field("app", "", strings.ToUpper(utils.ProjectName))
+	w.Field("version", "", utils.ProjectVersion)
+	w.Field("protocol", "", utils.ProtocolVersion)
+	w.Field("commit", "", commit)
+	w.Field("buildDate", "", buildDate)
+	w.Field("goVersion", "", runtime.Version())
+	w.Field("minAMTVersion", "", utils.MinAMTVersion)
+
+	w.Println(strings.ToUpper(utils.ProjectName))
+	w.Println("Version " + utils.ProjectVersion)
+	w.Println("Protocol " + utils.ProtocolVersion)
+	w.Println("Commit " + commit)
+	w.Println("Built " + buildDate + " with " + runtime.Version())
+	w.Println("Minimum AMT version " + utils.MinAMTVersion)
+
+	if err := w.Flush(); err != nil {
+		log.Error(err)
 	}
-
-	if service.flags.JsonOutput {
-		dataStruct := make(map[string]interface{})
-
-		projectName := strings.ToUpper(utils.ProjectName)
-		dataStruct["app"] = projectName
-
-		projectVersion := utils.ProjectVersion
-		dataStruct["version"] = projectVersion
-
-		protocolVersion := utils.ProtocolVersion
-		dataStruct["protocol"] = protocolVersion
-
-		outBytes, err := json.MarshalIndent(dataStruct, "", "  ")
-		output = string(outBytes)
-		if err != nil {
-			output = err.Error()
-		}
-		println(output)
-	}
-
 	return utils.Success
 }
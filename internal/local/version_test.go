@@ -1,6 +1,8 @@
 package local
 
 import (
+	"bytes"
+	"encoding/json"
 	"rpc/internal/flags"
 	"rpc/pkg/utils"
 	"testing"
@@ -21,9 +23,28 @@ func TestDisplayVersion(t *testing.T) {
 	t.Run("should return Success with json output", func(t *testing.T) {
 		f.JsonOutput = true
 		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
 		rc := lps.DisplayVersion()
 		assert.Equal(t, utils.Success, rc)
+		var result map[string]string
+		assert.Nil(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, utils.ProtocolVersion, result["protocol"])
+		assert.Equal(t, utils.MinAMTVersion, result["minAMTVersion"])
+		assert.NotEmpty(t, result["commit"])
+		assert.NotEmpty(t, result["buildDate"])
 		f.JsonOutput = false
 	})
 
+	t.Run("should write yaml output", func(t *testing.T) {
+		f.YamlOutput = true
+		lps := setupService(f)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.DisplayVersion()
+		assert.Equal(t, utils.Success, rc)
+		assert.Contains(t, buf.String(), "version: "+utils.ProjectVersion+"\n")
+		f.YamlOutput = false
+	})
+
 }
@@ -0,0 +1,142 @@
+package local
+
+import (
+	"fmt"
+	"rpc/pkg/utils"
+	"strings"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/auditlog"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
+)
+
+// WipeItem is the outcome of removing one kind of configuration with deactivate -wipe
+type WipeItem struct {
+	Item    string `json:"item"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DeactivateWipe removes the stored configuration and credentials, then unprovisions.
+// AMT no longer accepts the admin password once it is unprovisioned, so the wipe runs first.
+func (service *ProvisioningService) DeactivateWipe(controlMode int) utils.ReturnCode {
+	if service.flags.Password == "" {
+		if _, rc := service.flags.ReadPasswordFromUser(); rc != utils.Success {
+			return rc
+		}
+	}
+	service.setupWsmanClient("admin", service.flags.Password)
+	items := service.WipeStorage()
+
+	var rc utils.ReturnCode
+	if controlMode == 2 {
+		rc = service.DeactivateACM()
+	} else {
+		rc = service.DeactivateCCM()
+	}
+
+	w := service.newOutputWriter()
+	w.Field("wipe", "", items)
+	w.Field("deactivated", "", rc == utils.Success)
+	for _, item := range items {
+		if item.Success {
+			w.Printf("%-14s: removed\n", item.Item)
+		} else {
+			w.Printf("%-14s: failed, %s\n", item.Item, item.Error)
+		}
+	}
+	w.Printf("%-14s: %t\n", "deactivated", rc == utils.Success)
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+
+	if rc != utils.Success {
+		return rc
+	}
+	for _, item := range items {
+		if !item.Success {
+			log.Error("Status: Device deactivated, but not all configuration was removed")
+			return utils.StorageWipeFailed
+		}
+	}
+	return utils.Success
+}
+
+// WipeStorage removes the CIRA, TLS and wifi configuration, the certificates and key pairs,
+// and clears the audit log. Each item is attempted even if an earlier one fails.
+func (service *ProvisioningService) WipeStorage() []WipeItem {
+	steps := []struct {
+		item string
+		run  func() (utils.ReturnCode, string)
+	}{
+		{"cira", func() (utils.ReturnCode, string) { return service.RemoveCIRAConfiguration(), "" }},
+		{"tls", func() (utils.ReturnCode, string) { return service.DisableTLS(), "" }},
+		{"wifi", func() (utils.ReturnCode, string) { return service.PruneWifiConfigs(), "" }},
+		// certificates in use by the TLS or wifi configuration can only be deleted after it
+		{"certificates", service.deleteCertificates},
+		{"auditLog", service.clearAuditLog},
+	}
+	items := make([]WipeItem, 0, len(steps))
+	for _, step := range steps {
+		rc, detail := step.run()
+		item := WipeItem{Item: step.item, Success: rc == utils.Success}
+		if rc != utils.Success {
+			item.Error = rc.String()
+			if detail != "" {
+				item.Error = detail
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// deleteCertificates deletes all key pairs and the certificates that are not read-only
+func (service *ProvisioningService) deleteCertificates() (utils.ReturnCode, string) {
+	var certs []publickey.PublicKeyCertificate
+	if rc := service.GetPublicKeyCerts(&certs); rc != utils.Success {
+		return rc, ""
+	}
+	var keyPairs []publicprivate.PublicPrivateKeyPair
+	if rc := service.GetPublicPrivateKeyPairs(&keyPairs); rc != utils.Success {
+		return rc, ""
+	}
+	var failures []string
+	for _, cert := range certs {
+		if cert.ReadOnlyCertificate {
+			continue
+		}
+		if rc := service.DeletePublicCert(cert.InstanceID); rc != utils.Success {
+			failures = append(failures, cert.InstanceID)
+		}
+	}
+	for _, keyPair := range keyPairs {
+		if rc := service.DeletePublicPrivateKeyPair(keyPair.InstanceID); rc != utils.Success {
+			failures = append(failures, keyPair.InstanceID)
+		}
+	}
+	if len(failures) > 0 {
+		return utils.StorageWipeFailed, "unable to delete " + strings.Join(failures, ", ")
+	}
+	return utils.Success, ""
+}
+
+// clearAuditLog clears the audit log, firmware refuses it while the log is locked
+// or when the admin is not the auditor
+func (service *ProvisioningService) clearAuditLog() (utils.ReturnCode, string) {
+	xmlMsg, err := invokeMessage(auditlog.AMT_AuditLog, "ClearLog")
+	if err != nil {
+		log.Error("unable to create the ClearLog message: ", err)
+		return utils.WSMANMessageError, ""
+	}
+	var rsp ClearLogResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return rc, ""
+	}
+	if rsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("ClearLog_OUTPUT.ReturnValue: %d", rsp.Body.Output.ReturnValue)
+		return utils.AmtPtStatusCodeBase + utils.ReturnCode(rsp.Body.Output.ReturnValue),
+			fmt.Sprintf("AMT refused to clear the audit log, ReturnValue %d", rsp.Body.Output.ReturnValue)
+	}
+	return utils.Success, ""
+}
@@ -0,0 +1,147 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publicprivate"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/wifi"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeactivateWipe(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandDeactivate
+	f.WipeStorage = true
+	f.Password = "P@ssw0rd"
+	f.JsonOutput = true
+	f.Force = true
+	orig := mockControlMode
+	mockControlMode = 2
+	defer func() { mockControlMode = orig }()
+
+	emptyConfig := func() ResponseFuncArray {
+		rfa := ResponseFuncArray{
+			// cira
+			respondStringFunc(t, "state changed"),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, RemoteAccessPolicyRulePullResponse{}),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, ManagementPresenceRemoteSAPPullResponse{}),
+			// tls
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, TLSSettingDataPullResponse{}),
+		}
+		// wifi
+		rfa = append(rfa, emptyGetWifiIeee8021xCerts(t)...)
+		return append(rfa,
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, wifi.PullResponseEnvelope{}),
+		)
+	}
+	certs := publickey.PullResponseEnvelope{}
+	certs.Body.PullResponse.Items = []publickey.PublicKeyCertificate{
+		{InstanceID: "Intel(r) AMT Certificate: Handle: 0", ReadOnlyCertificate: true},
+		{InstanceID: "Intel(r) AMT Certificate: Handle: 1"},
+	}
+	keyPairs := publicprivate.PullResponseEnvelope{}
+	keyPairs.Body.PullResponse.Items = []publicprivate.PublicPrivateKeyPair{
+		{InstanceID: "Intel(r) AMT Key: Handle: 0"},
+	}
+	unprovision := func(w http.ResponseWriter, r *http.Request) { respondUnprovision(t, w) }
+
+	run := func(rfa ResponseFuncArray) (utils.ReturnCode, []WipeItem, bool) {
+		lps := setupWsmanResponses(t, f, rfa)
+		var buf bytes.Buffer
+		lps.out = &buf
+		rc := lps.Deactivate()
+		var result struct {
+			Wipe        []WipeItem `json:"wipe"`
+			Deactivated bool       `json:"deactivated"`
+		}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		return rc, result.Wipe, result.Deactivated
+	}
+
+	t.Run("removes all items before unprovisioning", func(t *testing.T) {
+		rfa := append(emptyConfig(),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, certs),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, keyPairs),
+			respondStringFunc(t, "cert deleted"),
+			respondStringFunc(t, "key deleted"),
+			respondMsgFunc(t, ClearLogResponse{}),
+			unprovision,
+		)
+		rc, items, deactivated := run(rfa)
+		assert.Equal(t, utils.Success, rc)
+		assert.True(t, deactivated)
+		assert.Len(t, items, 5)
+		for _, item := range items {
+			assert.True(t, item.Success, item.Item)
+		}
+	})
+	t.Run("sends ClearLog of the audit log", func(t *testing.T) {
+		rfa := append(emptyConfig(), emptyPublicPrivateCertsResponsers(t)...)
+		rfa = append(rfa, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Contains(t, string(body), "<a:Action>http://intel.com/wbem/wscim/1/amt-schema/1/AMT_AuditLog/ClearLog</a:Action>")
+			assert.Contains(t, string(body), `<h:ClearLog_INPUT xmlns:h="http://intel.com/wbem/wscim/1/amt-schema/1/AMT_AuditLog"></h:ClearLog_INPUT>`)
+			assert.NotContains(t, string(body), "ReadRecords")
+			respondMsgFunc(t, ClearLogResponse{})(w, r)
+		}, unprovision)
+		rc, items, _ := run(rfa)
+		assert.Equal(t, utils.Success, rc)
+		assert.True(t, items[4].Success)
+	})
+	t.Run("returns StorageWipeFailed when the firmware refuses to clear the audit log", func(t *testing.T) {
+		refused := ClearLogResponse{}
+		refused.Body.Output.ReturnValue = 16
+		rfa := append(emptyConfig(), emptyPublicPrivateCertsResponsers(t)...)
+		rfa = append(rfa, respondMsgFunc(t, refused), unprovision)
+		rc, items, deactivated := run(rfa)
+		assert.Equal(t, utils.StorageWipeFailed, rc)
+		assert.True(t, deactivated)
+		assert.Equal(t, WipeItem{Item: "auditLog", Error: "AMT refused to clear the audit log, ReturnValue 16"}, items[4])
+	})
+	t.Run("returns StorageWipeFailed when a certificate is not deleted", func(t *testing.T) {
+		rfa := append(emptyConfig(),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, certs),
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, publicprivate.PullResponseEnvelope{}),
+			respondServerErrFunc(),
+			respondMsgFunc(t, ClearLogResponse{}),
+			unprovision,
+		)
+		rc, items, deactivated := run(rfa)
+		assert.Equal(t, utils.StorageWipeFailed, rc)
+		assert.True(t, deactivated)
+		assert.Equal(t, WipeItem{Item: "certificates", Error: "unable to delete Intel(r) AMT Certificate: Handle: 1"}, items[3])
+	})
+	t.Run("returns the unprovision failure first", func(t *testing.T) {
+		rfa := append(ResponseFuncArray{respondServerErrFunc()}, ResponseFuncArray{
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, TLSSettingDataPullResponse{}),
+		}...)
+		rfa = append(rfa, emptyGetWifiIeee8021xCerts(t)...)
+		rfa = append(rfa,
+			respondMsgFunc(t, common.EnumerationResponse{}),
+			respondMsgFunc(t, wifi.PullResponseEnvelope{}),
+		)
+		rfa = append(rfa, emptyPublicPrivateCertsResponsers(t)...)
+		rfa = append(rfa, respondMsgFunc(t, ClearLogResponse{}), respondServerErrFunc())
+		rc, items, deactivated := run(rfa)
+		assert.Equal(t, utils.UnableToDeactivate, rc)
+		assert.False(t, deactivated)
+		assert.Equal(t, WipeItem{Item: "cira", Error: "CIRAConfigurationFailed"}, items[0])
+	})
+}
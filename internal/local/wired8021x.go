@@ -0,0 +1,215 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"rpc/pkg/utils"
+	"strings"
+
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/amt/publickey"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/cim/models"
+	"github.com/open-amt-cloud-toolkit/go-wsman-messages/pkg/ips/ieee8021x"
+)
+
+const ieee8021xSettingsNamespace = "http://intel.com/wbem/wscim/1/ips-schema/1/" + ieee8021x.IPS_IEEE8021xSettings
+
+// ieee8021xSettingsInput is the body of the IPS_IEEE8021xSettings Put. The Put of
+// go-wsman-messages leaves out the namespace and the selector AMT requires.
+type ieee8021xSettingsInput struct {
+	XMLName                xml.Name `xml:"h:IPS_IEEE8021xSettings"`
+	H                      string   `xml:"xmlns:h,attr"`
+	ElementName            string   `xml:"h:ElementName"`
+	InstanceID             string   `xml:"h:InstanceID"`
+	AuthenticationProtocol int      `xml:"h:AuthenticationProtocol"`
+	Username               string   `xml:"h:Username,omitempty"`
+	Password               string   `xml:"h:Password,omitempty"`
+	Enabled                int      `xml:"h:Enabled"`
+	PxeTimeout             int      `xml:"h:PxeTimeout"`
+	AvailableInS0          bool     `xml:"h:AvailableInS0"`
+}
+
+// certificateReference is the endpoint reference of a certificate in AMT
+type certificateReference struct {
+	Address     string `xml:"a:Address"`
+	ResourceURI string `xml:"a:ReferenceParameters>w:ResourceURI"`
+	Selector    struct {
+		Name  string `xml:"Name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"a:ReferenceParameters>w:SelectorSet>w:Selector"`
+}
+
+// setCertificatesInput passes the certificates as endpoint references, the
+// go-wsman-messages SetCertificates only takes them as plain strings
+type setCertificatesInput struct {
+	XMLName                 xml.Name              `xml:"h:SetCertificates_INPUT"`
+	H                       string                `xml:"xmlns:h,attr"`
+	ServerCertificateIssuer *certificateReference `xml:"h:ServerCertificateIssuer,omitempty"`
+	ClientCertificate       *certificateReference `xml:"h:ClientCertificate,omitempty"`
+}
+
+type IEEE8021xSettingsResponse struct {
+	Body struct {
+		Settings struct {
+			ElementName            string `xml:"ElementName"`
+			InstanceID             string `xml:"InstanceID"`
+			Enabled                int    `xml:"Enabled"`
+			AuthenticationProtocol int    `xml:"AuthenticationProtocol"`
+			Username               string `xml:"Username"`
+			PxeTimeout             int    `xml:"PxeTimeout"`
+			AvailableInS0          bool   `xml:"AvailableInS0"`
+		} `xml:"IPS_IEEE8021xSettings"`
+	} `xml:"Body"`
+}
+
+type SetCertificatesResponse struct {
+	Body struct {
+		Output struct {
+			ReturnValue int `xml:"ReturnValue"`
+		} `xml:"SetCertificates_OUTPUT"`
+	} `xml:"Body"`
+}
+
+func newCertificateReference(handle string) *certificateReference {
+	if handle == "" {
+		return nil
+	}
+	ref := &certificateReference{
+		Address:     "/wsman",
+		ResourceURI: "http://intel.com/wbem/wscim/1/amt-schema/1/" + publickey.AMT_PublicKeyCertificate,
+	}
+	ref.Selector.Name = "InstanceID"
+	ref.Selector.Value = handle
+	return ref
+}
+
+// ConfigureWired8021x adds the certificates of the 802.1x configuration and
+// enables 802.1x on the wired interface, or disables it with -disable. A failed step
+// removes the certificates added before it and puts back the settings read from AMT,
+// but for the password of PEAP that AMT does not return.
+func (service *ProvisioningService) ConfigureWired8021x() utils.ReturnCode {
+	var current IEEE8021xSettingsResponse
+	if rc := service.PostAndUnmarshal(service.ipsMessages.IEEE8021xSettings.Get(), &current); rc != utils.Success {
+		return utils.Ieee8021xConfigurationFailed
+	}
+	settings := ieee8021xSettingsInput{
+		ElementName: current.Body.Settings.ElementName,
+		InstanceID:  current.Body.Settings.InstanceID,
+		Enabled:     int(ieee8021x.Disabled),
+	}
+	if service.flags.Wired8021x.Disable {
+		if rc := service.putIEEE8021xSettings(settings); rc != utils.Success {
+			return rc
+		}
+		log.Info("Status: 802.1x disabled on the wired interface")
+		return utils.Success
+	}
+
+	ieee8021xConfig := &models.IEEE8021xSettings{}
+	handles := Handles{}
+	service.handlesWithCerts = make(map[string]string)
+	tx := service.beginConfig("802.1x")
+	rc := service.ProcessIeee8012xConfig(service.flags.Wired8021x.ProfileName, ieee8021xConfig, &handles)
+	tx.appliedHandles(&handles)
+	if rc != utils.Success {
+		return tx.rollback(rc)
+	}
+	settings.AuthenticationProtocol = int(ieee8021xConfig.AuthenticationProtocol)
+	settings.Username = ieee8021xConfig.Username
+	settings.Password = ieee8021xConfig.Password
+	settings.Enabled = int(ieee8021x.EnabledWithCertificates)
+	settings.PxeTimeout = service.flags.Wired8021x.PxeTimeout
+	settings.AvailableInS0 = true
+	if rc = service.putIEEE8021xSettings(settings); rc != utils.Success {
+		return tx.rollback(rc)
+	}
+	previous := ieee8021xSettingsInput{
+		ElementName:            current.Body.Settings.ElementName,
+		InstanceID:             current.Body.Settings.InstanceID,
+		AuthenticationProtocol: current.Body.Settings.AuthenticationProtocol,
+		Username:               current.Body.Settings.Username,
+		Enabled:                current.Body.Settings.Enabled,
+		PxeTimeout:             current.Body.Settings.PxeTimeout,
+		AvailableInS0:          current.Body.Settings.AvailableInS0,
+	}
+	tx.applied("802.1x settings", func() error {
+		if rc := service.putIEEE8021xSettings(previous); rc != utils.Success {
+			return fmt.Errorf("return code %d (%s)", rc, rc)
+		}
+		return nil
+	})
+
+	// the RADIUS server certificate is trusted through its CA, EAP-TLS also authenticates with the client certificate
+	xmlMsg, err := service.setCertificatesMessage(handles.rootCertHandle, handles.clientCertHandle)
+	if err != nil {
+		log.Error("unable to create the 802.1x certificates message: ", err)
+		return tx.rollback(utils.Ieee8021xConfigurationFailed)
+	}
+	var certsRsp SetCertificatesResponse
+	if rc = service.PostAndUnmarshal(xmlMsg, &certsRsp); rc != utils.Success {
+		return tx.rollback(utils.Ieee8021xConfigurationFailed)
+	}
+	if certsRsp.Body.Output.ReturnValue != 0 {
+		log.Errorf("SetCertificates_OUTPUT.ReturnValue: %d", certsRsp.Body.Output.ReturnValue)
+		return tx.rollback(utils.Ieee8021xConfigurationFailed)
+	}
+	log.Infof("Status: 802.1x enabled on the wired interface with profile %s", service.flags.Wired8021x.ProfileName)
+	return utils.Success
+}
+
+func (service *ProvisioningService) putIEEE8021xSettings(settings ieee8021xSettingsInput) utils.ReturnCode {
+	xmlMsg, err := service.ieee8021xSettingsPut(settings)
+	if err != nil {
+		log.Error("unable to create the 802.1x settings: ", err)
+		return utils.Ieee8021xConfigurationFailed
+	}
+	var rsp IEEE8021xSettingsResponse
+	if rc := service.PostAndUnmarshal(xmlMsg, &rsp); rc != utils.Success {
+		return utils.Ieee8021xConfigurationFailed
+	}
+	return utils.Success
+}
+
+// ieee8021xSettingsPut returns the Put of the 802.1x settings, reusing the header of the
+// go-wsman-messages Put with the selector of the instance added
+func (service *ProvisioningService) ieee8021xSettingsPut(settings ieee8021xSettingsInput) (string, error) {
+	settings.H = ieee8021xSettingsNamespace
+	body, err := xml.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	var selector strings.Builder
+	selector.WriteString(`<w:SelectorSet><w:Selector Name="InstanceID">`)
+	if err = xml.EscapeText(&selector, []byte(settings.InstanceID)); err != nil {
+		return "", err
+	}
+	selector.WriteString(`</w:Selector></w:SelectorSet>`)
+	return replaceBody(service.ipsMessages.IEEE8021xSettings.Put(ieee8021x.IEEE8021xSettings{}), selector.String(), string(body))
+}
+
+func (service *ProvisioningService) setCertificatesMessage(serverCertHandle string, clientCertHandle string) (string, error) {
+	body, err := xml.Marshal(setCertificatesInput{
+		H:                       ieee8021xSettingsNamespace,
+		ServerCertificateIssuer: newCertificateReference(serverCertHandle),
+		ClientCertificate:       newCertificateReference(clientCertHandle),
+	})
+	if err != nil {
+		return "", err
+	}
+	return replaceBody(service.ipsMessages.IEEE8021xSettings.SetCertificates("", ""), "", string(body))
+}
+
+// replaceBody replaces the body of a go-wsman-messages message and adds header elements
+func replaceBody(xmlMsg string, header string, body string) (string, error) {
+	headerEnd := strings.Index(xmlMsg, "</Header>")
+	start := strings.Index(xmlMsg, "<Body>")
+	end := strings.LastIndex(xmlMsg, "</Body>")
+	if headerEnd < 0 || start < headerEnd || end < start {
+		return "", errors.New("unexpected wsman message")
+	}
+	return xmlMsg[:headerEnd] + header + xmlMsg[headerEnd:start] + "<Body>" + body + xmlMsg[end:], nil
+}
@@ -0,0 +1,105 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"rpc/internal/config"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ieee8021xSettingsXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:h="http://intel.com/wbem/wscim/1/ips-schema/1/IPS_IEEE8021xSettings"><a:Body><h:IPS_IEEE8021xSettings><h:ElementName>Intel(r) AMT: 8021X Settings</h:ElementName><h:Enabled>3</h:Enabled><h:InstanceID>Intel(r) AMT: 8021X Settings</h:InstanceID></h:IPS_IEEE8021xSettings></a:Body></a:Envelope>`
+
+const setCertificatesXMLResponse = `<a:Envelope xmlns:a="http://www.w3.org/2003/05/soap-envelope" xmlns:h="http://intel.com/wbem/wscim/1/ips-schema/1/IPS_IEEE8021xSettings"><a:Body><h:SetCertificates_OUTPUT><h:ReturnValue>%d</h:ReturnValue></h:SetCertificates_OUTPUT></a:Body></a:Envelope>`
+
+// respondCheckBodyFunc checks the request contains each of the strings and responds with the xml
+func respondCheckBodyFunc(t *testing.T, xmlResponse string, contains ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		for _, s := range contains {
+			assert.Contains(t, string(body), s)
+		}
+		_, err = w.Write([]byte(xmlResponse))
+		assert.Nil(t, err)
+	}
+}
+
+func TestConfigureWired8021x(t *testing.T) {
+	f := &flags.Flags{}
+	f.LocalConfig.Ieee8021xConfigs = config.Ieee8021xConfigs{ieee8021xCfgEAPTLS}
+
+	t.Run("expect Success when disabled", func(t *testing.T) {
+		f.Wired8021x.Disable = true
+		defer func() { f.Wired8021x.Disable = false }()
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondCheckBodyFunc(t, ieee8021xSettingsXMLResponse,
+				`<w:Selector Name="InstanceID">Intel(r) AMT: 8021X Settings</w:Selector>`,
+				`<h:Enabled>3</h:Enabled>`),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureWired8021x())
+	})
+	t.Run("expect Success with EAP-TLS", func(t *testing.T) {
+		f.Wired8021x.ProfileName = ieee8021xCfgEAPTLS.ProfileName
+		f.Wired8021x.PxeTimeout = 120
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondStringFunc(t, addKeyXMLResponse),
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondCheckBodyFunc(t, ieee8021xSettingsXMLResponse,
+				`<h:AuthenticationProtocol>0</h:AuthenticationProtocol>`,
+				`<h:Enabled>2</h:Enabled>`,
+				`<h:PxeTimeout>120</h:PxeTimeout>`),
+			respondCheckBodyFunc(t, fmt.Sprintf(setCertificatesXMLResponse, 0),
+				`<h:ServerCertificateIssuer><a:Address>/wsman</a:Address>`,
+				`<w:Selector Name="InstanceID">Intel(r) AMT Certificate: Handle: 2</w:Selector>`,
+				`<w:Selector Name="InstanceID">Intel(r) AMT Certificate: Handle: 1</w:Selector>`),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Success, lps.ConfigureWired8021x())
+	})
+	t.Run("expect Ieee8021xConfigurationFailed when the settings can not be read", func(t *testing.T) {
+		rfa := ResponseFuncArray{respondServerErrFunc()}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Ieee8021xConfigurationFailed, lps.ConfigureWired8021x())
+	})
+	t.Run("expect Ieee8021xConfigurationFailed when SetCertificates fails", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondStringFunc(t, addKeyXMLResponse),
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondStringFunc(t, fmt.Sprintf(setCertificatesXMLResponse, 1)),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.Ieee8021xConfigurationFailed, lps.ConfigureWired8021x())
+	})
+	t.Run("expect PartialConfigRolledBack when SetCertificates fails and the changes are undone", func(t *testing.T) {
+		rfa := ResponseFuncArray{
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondStringFunc(t, addKeyXMLResponse),
+			respondStringFunc(t, clientCertXMLResponse),
+			respondStringFunc(t, trustedRootXMLResponse),
+			respondStringFunc(t, ieee8021xSettingsXMLResponse),
+			respondStringFunc(t, fmt.Sprintf(setCertificatesXMLResponse, 1)),
+			respondCheckBodyFunc(t, ieee8021xSettingsXMLResponse, `<h:Enabled>3</h:Enabled>`),
+			respondCheckBodyFunc(t, "deleted", "AMT_PublicPrivateKeyPair"),
+			respondCheckBodyFunc(t, "deleted", "Intel(r) AMT Certificate: Handle: 1"),
+			respondCheckBodyFunc(t, "deleted", "Intel(r) AMT Certificate: Handle: 2"),
+		}
+		lps := setupWsmanResponses(t, f, rfa)
+		assert.Equal(t, utils.PartialConfigRolledBack, lps.ConfigureWired8021x())
+	})
+}
@@ -0,0 +1,27 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import "rpc/pkg/utils"
+
+// WSMAN sends the envelope of rpc wsman to AMT with the admin credentials and prints the
+// response as it is. It is the way to AMT features rpc has no command for.
+func (service *ProvisioningService) WSMAN() utils.ReturnCode {
+	service.setupWsmanClient("admin", service.flags.Password)
+	log.Trace(service.flags.WSMAN.Envelope)
+	response, err := service.client.Post(service.flags.WSMAN.Envelope)
+	if err != nil {
+		// a SOAP fault of AMT is part of the error
+		log.Error("wsman: ", err)
+		return utils.WSMANMessageError
+	}
+	w := service.newOutputWriter()
+	w.Field("response", "", string(response))
+	w.Println(string(response))
+	if err := w.Flush(); err != nil {
+		log.Error(err)
+	}
+	return utils.Success
+}
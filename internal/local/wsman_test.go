@@ -0,0 +1,54 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWSMAN(t *testing.T) {
+	f := &flags.Flags{}
+	f.Command = utils.CommandWSMAN
+	f.Password = "P@ssw0rd"
+	f.WSMAN.Envelope = `<Envelope><Body><Get/></Body></Envelope>`
+	const response = `<Envelope><Body><AMT_GeneralSettings/></Body></Envelope>`
+
+	t.Run("sends the envelope and prints the response", func(t *testing.T) {
+		var body string
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+			_, _ = w.Write([]byte(response))
+		}})
+		var out bytes.Buffer
+		lps.out = &out
+		assert.Equal(t, utils.Success, lps.WSMAN())
+		assert.Equal(t, f.WSMAN.Envelope, body)
+		assert.Equal(t, response+"\n", out.String())
+	})
+	t.Run("prints the response as json", func(t *testing.T) {
+		f.JsonOutput = true
+		defer func() { f.JsonOutput = false }()
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondStringFunc(t, response)})
+		var out bytes.Buffer
+		lps.out = &out
+		assert.Equal(t, utils.Success, lps.WSMAN())
+		var result map[string]string
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		assert.Equal(t, response, result["response"])
+	})
+	t.Run("returns WSMANMessageError on a fault", func(t *testing.T) {
+		lps := setupWsmanResponses(t, f, ResponseFuncArray{respondServerErrFunc()})
+		assert.Equal(t, utils.WSMANMessageError, lps.WSMAN())
+	})
+}
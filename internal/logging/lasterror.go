@@ -0,0 +1,39 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package logging
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lastErrorHook keeps the message of the last error logged by any module, the
+// JSON error envelope of a failed command reports it
+type lastErrorHook struct {
+	mu      sync.Mutex
+	message string
+}
+
+var lastError = &lastErrorHook{}
+
+// LastError returns the message of the last error logged, it is empty when none was logged
+func LastError() string {
+	lastError.mu.Lock()
+	defer lastError.mu.Unlock()
+	return lastError.message
+}
+
+func (h *lastErrorHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+// Fire runs after the redactHook, the message kept has its secrets replaced
+func (h *lastErrorHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.message = entry.Message
+	return nil
+}
@@ -0,0 +1,178 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package logging configures the loggers of the rpc modules. Each module
+// logs through its own logger so the level can be set per module, while
+// output, format and redaction of secrets are shared.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ModuleRPC   = "rpc"
+	ModuleFlags = "flags"
+	ModuleAMT   = "amt"
+	ModuleRPS   = "rps"
+	ModuleLMS   = "lms"
+	ModuleLocal = "local"
+	ModuleAgent = "agent"
+	ModuleInfo  = "info"
+
+	// DefaultMaxSize is the size in bytes at which the log file is rotated
+	DefaultMaxSize = 10 * 1024 * 1024
+)
+
+type Fields = logrus.Fields
+
+// Options selects where and how the loggers write
+type Options struct {
+	// Level applies to all modules without a level in ModuleLevels
+	Level   string
+	Verbose bool
+	JSON    bool
+	// File is written instead of stderr when set, rotated at MaxSize bytes
+	File    string
+	MaxSize int64
+	// ModuleLevels holds comma separated module=level pairs, ex. "rps=debug,amt=trace"
+	ModuleLevels string
+}
+
+// Modules lists the modules that can be given their own level
+var Modules = []string{ModuleRPC, ModuleFlags, ModuleAMT, ModuleRPS, ModuleLMS, ModuleLocal, ModuleAgent, ModuleInfo}
+
+var (
+	mu       sync.Mutex
+	loggers            = map[string]*logrus.Logger{}
+	redactor           = &redactHook{}
+	output   io.Writer = os.Stderr
+)
+
+// For returns the logger of the module
+func For(module string) *logrus.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	if logger, ok := loggers[module]; ok {
+		return logger
+	}
+	logger := logrus.New()
+	logger.SetOutput(output)
+	logger.AddHook(redactor)
+	logger.AddHook(lastError)
+	logger.AddHook(&transcriptHook{module: module})
+	loggers[module] = logger
+	return logger
+}
+
+// AddHook adds the hook to the loggers of all modules, e.g. to copy log entries to the Windows event log
+func AddHook(hook logrus.Hook) {
+	for _, module := range Modules {
+		For(module).AddHook(hook)
+	}
+}
+
+// Setup applies the options to the loggers of all modules
+func Setup(opts Options) error {
+	level := logrus.InfoLevel
+	var err error
+	if opts.Verbose {
+		level = logrus.TraceLevel
+	} else if opts.Level != "" {
+		if level, err = logrus.ParseLevel(opts.Level); err != nil {
+			level = logrus.InfoLevel
+		}
+	}
+	moduleLevels, moduleErr := parseModuleLevels(opts.ModuleLevels)
+	if err == nil {
+		err = moduleErr
+	}
+
+	out := io.Writer(os.Stderr)
+	if opts.File != "" {
+		maxSize := opts.MaxSize
+		if maxSize <= 0 {
+			maxSize = DefaultMaxSize
+		}
+		file, fileErr := NewRotatingFile(opts.File, maxSize)
+		if fileErr != nil {
+			return fileErr
+		}
+		out = file
+	}
+
+	// the modules log before Setup, e.g. while parsing flags, so make sure they all exist
+	for _, module := range Modules {
+		For(module)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if closer, ok := output.(io.Closer); ok && output != out {
+		closer.Close()
+	}
+	output = out
+	for module, logger := range loggers {
+		logger.SetOutput(out)
+		if lvl, ok := moduleLevels[module]; ok {
+			logger.SetLevel(lvl)
+		} else {
+			logger.SetLevel(level)
+		}
+		if opts.JSON {
+			logger.SetFormatter(moduleFormatter{module: module, Formatter: &logrus.JSONFormatter{}})
+		} else {
+			logger.SetFormatter(&logrus.TextFormatter{
+				DisableColors: true,
+				FullTimestamp: true,
+			})
+		}
+	}
+	return err
+}
+
+func parseModuleLevels(s string) (map[string]logrus.Level, error) {
+	levels := map[string]logrus.Level{}
+	if s == "" {
+		return levels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		module, levelName, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || !isModule(module) {
+			return levels, fmt.Errorf("invalid module log level %q, expected module=level with module one of %s", pair, strings.Join(Modules, ","))
+		}
+		level, err := logrus.ParseLevel(levelName)
+		if err != nil {
+			return levels, err
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// moduleFormatter adds the module to structured log entries
+type moduleFormatter struct {
+	module string
+	logrus.Formatter
+}
+
+func (f moduleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry.Data["module"] = f.module
+	return f.Formatter.Format(entry)
+}
+
+func isModule(name string) bool {
+	for _, module := range Modules {
+		if module == name {
+			return true
+		}
+	}
+	return false
+}
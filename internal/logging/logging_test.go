@@ -0,0 +1,202 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupModuleLevels(t *testing.T) {
+	err := Setup(Options{Level: "warn", ModuleLevels: "rps=debug, amt=trace"})
+	assert.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, For(ModuleRPS).GetLevel())
+	assert.Equal(t, logrus.TraceLevel, For(ModuleAMT).GetLevel())
+	assert.Equal(t, logrus.WarnLevel, For(ModuleLocal).GetLevel())
+}
+
+func TestSetupVerbose(t *testing.T) {
+	err := Setup(Options{Level: "error", Verbose: true})
+	assert.NoError(t, err)
+	assert.Equal(t, logrus.TraceLevel, For(ModuleFlags).GetLevel())
+}
+
+func TestSetupInvalidLevels(t *testing.T) {
+	err := Setup(Options{Level: "loud"})
+	assert.Error(t, err)
+	assert.Equal(t, logrus.InfoLevel, For(ModuleRPC).GetLevel())
+
+	err = Setup(Options{ModuleLevels: "nosuchmodule=debug"})
+	assert.Error(t, err)
+	err = Setup(Options{ModuleLevels: "rps"})
+	assert.Error(t, err)
+	err = Setup(Options{ModuleLevels: "rps=loud"})
+	assert.Error(t, err)
+}
+
+func TestSetupJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpc.log")
+	err := Setup(Options{JSON: true, File: path})
+	assert.NoError(t, err)
+	defer Setup(Options{})
+	For(ModuleLMS).Info("hello")
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(data, &entry))
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, ModuleLMS, entry["module"])
+}
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := For("redacttest")
+	logger.SetOutput(&buf)
+	logger.SetFormatter(messageFormatter{})
+	Redact("S3cr3tPass", "abc")
+
+	tests := map[string]struct {
+		line string
+		want string
+	}{
+		"registered secret": {
+			line: "using S3cr3tPass for AMT",
+			want: "using ******** for AMT",
+		},
+		"short secrets are not registered": {
+			line: "abc",
+			want: "abc",
+		},
+		"json field": {
+			line: `{"password":"other", "pskPassphrase": "wifi"}`,
+			want: `{"password":"********", "pskPassphrase": "********"}`,
+		},
+		"wsman element": {
+			line: "<h:Password>other</h:Password><h:PSKPassPhrase>wifi</h:PSKPassPhrase>",
+			want: "<h:Password>********</h:Password><h:PSKPassPhrase>********</h:PSKPassPhrase>",
+		},
+		"command line": {
+			line: "deactivate --password other -f",
+			want: "deactivate --password ******** -f",
+		},
+		"secret flags": {
+			line: "configure cira -mpspassword mps -token=jwt -vaultToken vault -mqttPassword mqtt -proxypassword proxy",
+			want: "configure cira -mpspassword ******** -token=******** -vaultToken ******** -mqttPassword ******** -proxypassword ********",
+		},
+		"secret json fields": {
+			line: `{"mpsPassword":"mps","token":"jwt","vaultToken":"vault","mqttPassword":"mqtt"}`,
+			want: `{"mpsPassword":"********","token":"********","vaultToken":"********","mqttPassword":"********"}`,
+		},
+		"logfmt fields": {
+			line: `token=jwt mqttPassword="my mqtt" user=admin`,
+			want: `token=******** mqttPassword=******** user=admin`,
+		},
+		"environment": {
+			line: "MPS_PASSWORD=mps VAULT_TOKEN=vault RPS_TOKEN=jwt MQTT_PASSWORD=mqtt AMT_PASSWORD=amt",
+			want: "MPS_PASSWORD=******** VAULT_TOKEN=******** RPS_TOKEN=******** MQTT_PASSWORD=******** AMT_PASSWORD=********",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf.Reset()
+			logger.Info(tc.line)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+
+	buf.Reset()
+	logger.WithField("password", "S3cr3tPass").Info("fields")
+	assert.Equal(t, "fields password=********", buf.String())
+	assert.Equal(t, "copy of ******** -password ******** x", RedactString("copy of S3cr3tPass -password other x"))
+}
+
+func TestLastError(t *testing.T) {
+	logger := For("lasterrortest")
+	logger.SetOutput(io.Discard)
+	Redact("S3cr3tPass")
+
+	logger.Error("AMT refused S3cr3tPass")
+	logger.Warn("only a warning")
+	assert.Equal(t, "AMT refused ********", LastError())
+}
+
+func TestTranscript(t *testing.T) {
+	logger := For("transcripttest")
+	logger.SetOutput(io.Discard)
+	Redact("S3cr3tPass")
+
+	logger.Info("before the transcript")
+	transcript := StartTranscript()
+	assert.Same(t, transcript, StartTranscript())
+	logger.WithField("error", errors.New("AMT refused S3cr3tPass")).Info("activating with S3cr3tPass")
+	logger.Debug("disabled by the level")
+	entries, dropped := transcript.Take()
+	assert.Zero(t, dropped)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "info", entries[0].Level)
+	assert.Equal(t, "transcripttest", entries[0].Module)
+	assert.Equal(t, "activating with ********", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{"error": "AMT refused ********"}, entries[0].Fields)
+
+	for i := 0; i < maxTranscriptEntries+2; i++ {
+		logger.Info(i)
+	}
+	entries, dropped = transcript.Take()
+	assert.Equal(t, 2, dropped)
+	assert.Len(t, entries, maxTranscriptEntries)
+	assert.Equal(t, "2", entries[0].Message)
+
+	transcript.Stop()
+	logger.Info("after the transcript")
+	entries, _ = transcript.Take()
+	assert.Empty(t, entries)
+}
+
+// messageFormatter writes the message and fields without decoration
+type messageFormatter struct{}
+
+func (messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	line := entry.Message
+	for key, value := range entry.Data {
+		line += fmt.Sprintf(" %s=%v", key, value)
+	}
+	return []byte(line), nil
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpc.log")
+	r, err := NewRotatingFile(path, 10)
+	assert.NoError(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n", "fifth\n"} {
+		_, err = r.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, r.Close())
+
+	for name, want := range map[string]string{
+		path:        "fifth\n",
+		path + ".1": "fourth\n",
+		path + ".2": "third\n",
+		path + ".3": "second\n",
+	} {
+		data, err := os.ReadFile(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+	_, err = os.Stat(path + ".4")
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{path, path + ".1", path + ".2", path + ".3"}, LogFiles(path))
+	assert.Empty(t, LogFiles(path+".missing"))
+}
@@ -0,0 +1,87 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package logging
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "********"
+
+// secrets shorter than this are not redacted by value, they would mangle unrelated text
+const minSecretLength = 4
+
+// jsonSecrets are the JSON names of secrets
+const jsonSecrets = `password|amtPassword|mebxPassword|staticPassword|mpsPassword|mqttPassword|proxyPassword|pskPassphrase|provisioningCertPwd|privateKey|token|vaultToken`
+
+// secretPatterns match secrets by their name in JSON, also quoted in a log message, WSMAN
+// XML, command lines, logfmt fields and environment variables. They cover every secret of
+// flags.Flags.Secrets, so the logs of an earlier run, whose secrets are not registered,
+// are redacted as well.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)("(?:` + jsonSecrets + `)"\s*:\s*")[^"]*(")`),
+	regexp.MustCompile(`(?i)(\\"(?:` + jsonSecrets + `)\\"\s*:\s*\\")(?:[^\\]|\\[^"])*(\\")`),
+	regexp.MustCompile(`(?i)(<(?:\w+:)?(?:Password|PSKPassPhrase|PSKValue|PrivateKey)>)[^<]*(</)`),
+	regexp.MustCompile(`(?i)(-{1,2}(?:password|static|mebxPassword|provisioningCertPwd|proxypassword|mpspassword|mqttPassword|token|vaultToken)[ =])\S+()`),
+	regexp.MustCompile(`(?i)(\b(?:password|mpsPassword|mqttPassword|proxyPassword|token|vaultToken)=)(?:"[^"]*"|[^\s"]+)()`),
+	regexp.MustCompile(`((?:AMT|MEBX|MPS|MQTT|PROXY|PROVISIONING_CERT)_PASSWORD=|(?:VAULT|RPS)_TOKEN=)\S+()`),
+}
+
+// redactHook replaces secrets in the message and fields of every log entry
+type redactHook struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// Redact registers secrets to be replaced in all log lines
+func Redact(secrets ...string) {
+	redactor.add(secrets...)
+}
+
+func (h *redactHook) add(secrets ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			h.secrets = append(h.secrets, secret)
+		}
+	}
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redact(entry.Message)
+	for key, value := range entry.Data {
+		if s, ok := value.(string); ok {
+			entry.Data[key] = h.redact(s)
+		}
+	}
+	return nil
+}
+
+func (h *redactHook) redact(s string) string {
+	h.mu.RLock()
+	for _, secret := range h.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	h.mu.RUnlock()
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	}
+	return s
+}
+
+// RedactString replaces the registered secrets and the secrets matched by name in s, for
+// text written somewhere else than the log
+func RedactString(s string) string {
+	return redactor.redact(s)
+}
@@ -0,0 +1,99 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// maxBackups is the number of rotated log files kept next to the log file
+const maxBackups = 3
+
+// RotatingFile is a log file that is moved to <path>.1 once it reaches maxSize bytes.
+// Older files are shifted to <path>.2 and so on, up to maxBackups.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	size    int64
+	file    *os.File
+}
+
+func NewRotatingFile(path string, maxSize int64) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxSize: maxSize}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	for i := maxBackups - 1; i > 0; i-- {
+		// missing backups are expected until the file was rotated maxBackups times
+		_ = os.Rename(backupName(r.path, i), backupName(r.path, i+1))
+	}
+	if err := os.Rename(r.path, backupName(r.path, 1)); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// LogFiles returns the log file at path and its rotated files that exist, newest first
+func LogFiles(path string) []string {
+	var files []string
+	for i := 0; i <= maxBackups; i++ {
+		name := path
+		if i > 0 {
+			name = backupName(path, i)
+		}
+		if _, err := os.Stat(name); err == nil {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
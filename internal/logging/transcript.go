@@ -0,0 +1,115 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxTranscriptEntries bounds the entries kept between two Takes, the oldest are dropped
+const maxTranscriptEntries = 1000
+
+// TranscriptEntry is a log entry of a Transcript, with its secrets replaced
+type TranscriptEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Module  string                 `json:"module"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Transcript collects the entries logged by all modules while it is started, so they can be
+// sent elsewhere, e.g. uploaded to the server. Only entries enabled by the level of their
+// module are collected.
+type Transcript struct {
+	mu      sync.Mutex
+	entries []TranscriptEntry
+	dropped int
+}
+
+var (
+	transcriptMu sync.Mutex
+	transcript   *Transcript
+)
+
+// StartTranscript starts collecting the log entries of all modules, it returns the
+// transcript in progress when one was started and not stopped yet
+func StartTranscript() *Transcript {
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	if transcript == nil {
+		transcript = &Transcript{}
+	}
+	return transcript
+}
+
+// Stop ends the collection of log entries, the entries not taken yet are kept
+func (t *Transcript) Stop() {
+	transcriptMu.Lock()
+	defer transcriptMu.Unlock()
+	if transcript == t {
+		transcript = nil
+	}
+}
+
+// Take returns the entries collected since the last Take and the number of entries dropped
+// in between because too many were logged
+func (t *Transcript) Take() ([]TranscriptEntry, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries, dropped := t.entries, t.dropped
+	t.entries, t.dropped = nil, 0
+	return entries, dropped
+}
+
+func (t *Transcript) add(entry TranscriptEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.entries) == maxTranscriptEntries {
+		t.entries = t.entries[1:]
+		t.dropped++
+	}
+	t.entries = append(t.entries, entry)
+}
+
+// transcriptHook copies the entries of its module to the started transcript
+type transcriptHook struct {
+	module string
+}
+
+func (h *transcriptHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire runs after the redactHook, the entry kept has its secrets replaced
+func (h *transcriptHook) Fire(entry *logrus.Entry) error {
+	transcriptMu.Lock()
+	t := transcript
+	transcriptMu.Unlock()
+	if t == nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if len(entry.Data) > 0 {
+		fields = make(map[string]interface{}, len(entry.Data))
+		for key, value := range entry.Data {
+			if err, ok := value.(error); ok {
+				value = RedactString(err.Error())
+			}
+			fields[key] = value
+		}
+	}
+	t.add(TranscriptEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Module:  h.module,
+		Message: entry.Message,
+		Fields:  fields,
+	})
+	return nil
+}
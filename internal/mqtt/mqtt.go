@@ -0,0 +1,138 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package mqtt publishes messages to an MQTT broker with the Eclipse Paho MQTT
+// client. rpc only connects, publishes with QoS 1 and disconnects.
+package mqtt
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	DefaultPort    = "1883"
+	DefaultTLSPort = "8883"
+
+	keepAlive        = 30 * time.Second
+	protocolLevel311 = 4
+	// disconnectQuiesce is how long the client waits for work in progress when it
+	// disconnects, in milliseconds
+	disconnectQuiesce = 250
+)
+
+// Publisher sends messages to a single broker. Each Publish opens its own
+// connection, rpc publishes a handful of messages per run.
+type Publisher struct {
+	Address  string
+	UseTLS   bool
+	ClientID string
+	Username string
+	Password string
+	Timeout  time.Duration
+	// TLSConfig is used for mqtts brokers, the system roots are used when nil
+	TLSConfig *tls.Config
+	packetID  uint16
+}
+
+// NewPublisher parses the broker address. Brokers are given as tcp://host:port,
+// mqtt://host:port or, for TLS, ssl://, tls:// or mqtts://. User info in the
+// URL is used to authenticate.
+func NewPublisher(broker string, clientID string) (*Publisher, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("mqtt broker %q has no host, expected scheme://host:port", broker)
+	}
+	p := &Publisher{ClientID: clientID, Timeout: 10 * time.Second}
+	port := DefaultPort
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		p.UseTLS = true
+		port = DefaultTLSPort
+	default:
+		return nil, fmt.Errorf("unsupported mqtt broker scheme %q", u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	p.Address = net.JoinHostPort(u.Hostname(), port)
+	if u.User != nil {
+		p.Username = u.User.Username()
+		p.Password, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+// Publish delivers the payload to the topic and waits for the broker to acknowledge it
+func (p *Publisher) Publish(topic string, payload []byte) error {
+	scheme := "tcp://"
+	if p.UseTLS {
+		scheme = "ssl://"
+	}
+	options := paho.NewClientOptions().
+		AddBroker(scheme + p.Address).
+		SetClientID(p.ClientID).
+		SetUsername(p.Username).
+		SetPassword(p.Password).
+		SetProtocolVersion(protocolLevel311).
+		SetCleanSession(true).
+		SetKeepAlive(keepAlive).
+		SetConnectTimeout(p.Timeout).
+		SetWriteTimeout(p.Timeout).
+		SetAutoReconnect(false)
+	if p.UseTLS {
+		config := p.TLSConfig
+		if config == nil {
+			host, _, _ := net.SplitHostPort(p.Address)
+			config = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		}
+		options.SetTLSConfig(config)
+	}
+	client := paho.NewClient(options)
+	connect := client.Connect()
+	if !connect.WaitTimeout(p.Timeout) {
+		return errors.New("mqtt broker did not acknowledge the connection")
+	}
+	if err := connect.Error(); err != nil {
+		if code := connect.(*paho.ConnectToken).ReturnCode(); code > 0 && code <= 5 {
+			return fmt.Errorf("mqtt broker refused the connection: %s", connectReturnCode(code))
+		}
+		return err
+	}
+	defer client.Disconnect(disconnectQuiesce)
+
+	publish := client.Publish(topic, 1, false, payload)
+	if !publish.WaitTimeout(p.Timeout) {
+		return errors.New("mqtt broker did not acknowledge the message")
+	}
+	return publish.Error()
+}
+
+func connectReturnCode(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
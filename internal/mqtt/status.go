@@ -0,0 +1,113 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package mqtt
+
+import (
+	"encoding/json"
+	"os"
+	"rpc/internal/logging"
+	"rpc/pkg/utils"
+	"time"
+)
+
+var log = logging.For(logging.ModuleRPC)
+
+const (
+	DefaultTopic = "rpc/status"
+
+	StatusStarted = "started"
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Event is the JSON message published when an operation starts and ends
+type Event struct {
+	Command    string           `json:"command"`
+	SubCommand string           `json:"subCommand,omitempty"`
+	Status     string           `json:"status"`
+	ReturnCode utils.ReturnCode `json:"returnCode"`
+	Result     string           `json:"result,omitempty"`
+	Hostname   string           `json:"hostname"`
+	UUID       string           `json:"uuid,omitempty"`
+	Time       time.Time        `json:"time"`
+	// Info holds the amtinfo output, as JSON when -json is used
+	Info json.RawMessage `json:"info,omitempty"`
+}
+
+// StatusReporter publishes the events of one rpc operation. A nil reporter
+// publishes nothing. Failing to publish is logged and does not fail the operation.
+type StatusReporter struct {
+	publisher Interface
+	topic     string
+	event     Event
+}
+
+// Interface is implemented by Publisher
+type Interface interface {
+	Publish(topic string, payload []byte) error
+}
+
+func NewStatusReporter(publisher Interface, topic string, command string, subCommand string, uuid string) *StatusReporter {
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	hostname, _ := os.Hostname()
+	return &StatusReporter{
+		publisher: publisher,
+		topic:     topic,
+		event: Event{
+			Command:    command,
+			SubCommand: subCommand,
+			Hostname:   hostname,
+			UUID:       uuid,
+		},
+	}
+}
+
+// Started publishes the start of the operation
+func (r *StatusReporter) Started() {
+	if r == nil {
+		return
+	}
+	event := r.event
+	event.Status = StatusStarted
+	r.publish(event)
+}
+
+// Finished publishes the result of the operation with the output of amtinfo, if any
+func (r *StatusReporter) Finished(rc utils.ReturnCode, info []byte) {
+	if r == nil {
+		return
+	}
+	event := r.event
+	event.Status = StatusSuccess
+	if rc != utils.Success {
+		event.Status = StatusFailure
+	}
+	event.ReturnCode = rc
+	event.Result = rc.String()
+	if len(info) > 0 {
+		if json.Valid(info) {
+			event.Info = info
+		} else if text, err := json.Marshal(string(info)); err == nil {
+			event.Info = text
+		}
+	}
+	r.publish(event)
+}
+
+func (r *StatusReporter) publish(event Event) {
+	event.Time = time.Now().UTC()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Warn("unable to encode mqtt status: ", err)
+		return
+	}
+	if err = r.publisher.Publish(r.topic, payload); err != nil {
+		log.Warn("unable to publish status to mqtt broker: ", err)
+		return
+	}
+	log.Debugf("published %s status to mqtt topic %s", event.Status, r.topic)
+}
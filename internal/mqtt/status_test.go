@@ -0,0 +1,66 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPublisher struct {
+	topics   []string
+	messages []Event
+	err      error
+}
+
+func (m *mockPublisher) Publish(topic string, payload []byte) error {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+	m.topics = append(m.topics, topic)
+	m.messages = append(m.messages, event)
+	return m.err
+}
+
+func TestStatusReporter(t *testing.T) {
+	publisher := &mockPublisher{}
+	r := NewStatusReporter(publisher, "", utils.CommandMaintenance, utils.SubCommandSyncClock, "1234")
+	r.Started()
+	r.Finished(utils.SyncClockFailed, nil)
+
+	assert.Equal(t, []string{DefaultTopic, DefaultTopic}, publisher.topics)
+	assert.Equal(t, StatusStarted, publisher.messages[0].Status)
+	assert.Equal(t, utils.CommandMaintenance, publisher.messages[0].Command)
+	assert.Equal(t, utils.SubCommandSyncClock, publisher.messages[0].SubCommand)
+	assert.Equal(t, "1234", publisher.messages[0].UUID)
+	assert.Equal(t, StatusFailure, publisher.messages[1].Status)
+	assert.Equal(t, utils.SyncClockFailed, publisher.messages[1].ReturnCode)
+	assert.Equal(t, "SyncClockFailed", publisher.messages[1].Result)
+}
+
+func TestStatusReporterInfo(t *testing.T) {
+	publisher := &mockPublisher{}
+	r := NewStatusReporter(publisher, "fleet/rpc", utils.CommandAMTInfo, "", "")
+	r.Finished(utils.Success, []byte(`{"uuid":"1234"}`))
+	r.Finished(utils.Success, []byte("UUID : 1234\n"))
+
+	assert.Equal(t, "fleet/rpc", publisher.topics[0])
+	assert.Equal(t, StatusSuccess, publisher.messages[0].Status)
+	assert.JSONEq(t, `{"uuid":"1234"}`, string(publisher.messages[0].Info))
+	assert.Equal(t, `"UUID : 1234\n"`, string(publisher.messages[1].Info))
+}
+
+func TestStatusReporterIgnoresErrors(t *testing.T) {
+	publisher := &mockPublisher{err: errors.New("broker down")}
+	r := NewStatusReporter(publisher, "", utils.CommandActivate, "", "")
+	assert.NotPanics(t, func() { r.Started() })
+	var nilReporter *StatusReporter
+	assert.NotPanics(t, func() { nilReporter.Finished(utils.Success, nil) })
+}
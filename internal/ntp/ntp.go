@@ -0,0 +1,85 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package ntp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultPort is the well known NTP port
+	DefaultPort = "123"
+	// packetSize is the size of an SNTP packet without extension fields
+	packetSize = 48
+	// ntpEpochOffset is the number of seconds between 1900 (NTP epoch) and 1970 (Unix epoch)
+	ntpEpochOffset = 2208988800
+	// LI = 0 (no warning), VN = 4, Mode = 3 (client)
+	clientHeader = 0x23
+	modeServer   = 4
+)
+
+// Query requests the current time from an NTP server using the SNTP (RFC 4330) client mode.
+// The server may be given as host or host:port.
+func Query(server string, timeout time.Duration) (time.Time, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, DefaultPort)
+	}
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return time.Time{}, err
+	}
+
+	req := make([]byte, packetSize)
+	req[0] = clientHeader
+	sent := time.Now()
+	if _, err = conn.Write(req); err != nil {
+		return time.Time{}, err
+	}
+	rsp := make([]byte, packetSize)
+	n, err := conn.Read(rsp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	received := time.Now()
+	return ParseResponse(rsp[:n], received.Sub(sent))
+}
+
+// ParseResponse decodes the transmit timestamp of an NTP server response and
+// adjusts it by half of the measured round trip
+func ParseResponse(rsp []byte, roundTrip time.Duration) (time.Time, error) {
+	if len(rsp) < packetSize {
+		return time.Time{}, errors.New("ntp response too short")
+	}
+	if rsp[0]&0x07 != modeServer {
+		return time.Time{}, errors.New("ntp response is not in server mode")
+	}
+	// stratum 0 is a kiss-o'-death packet
+	if rsp[1] == 0 {
+		return time.Time{}, errors.New("ntp server sent kiss-o'-death")
+	}
+	seconds := binary.BigEndian.Uint32(rsp[40:44])
+	fraction := binary.BigEndian.Uint32(rsp[44:48])
+	if seconds == 0 {
+		return time.Time{}, errors.New("ntp response has no transmit timestamp")
+	}
+	nanos := (int64(fraction) * int64(time.Second)) >> 32
+	t := time.Unix(int64(seconds)-ntpEpochOffset, nanos)
+	return t.Add(roundTrip / 2), nil
+}
+
+// EncodeTimestamp is the inverse of the transmit timestamp decoding in ParseResponse
+func EncodeTimestamp(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+	return b
+}
@@ -0,0 +1,84 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package ntp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serverResponse(t time.Time) []byte {
+	rsp := make([]byte, packetSize)
+	rsp[0] = 0x24 // VN 4, server mode
+	rsp[1] = 2
+	copy(rsp[40:48], EncodeTimestamp(t))
+	return rsp
+}
+
+func TestParseResponse(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 30, 15, 500000000, time.UTC)
+	t.Run("decodes transmit timestamp", func(t *testing.T) {
+		got, err := ParseResponse(serverResponse(now), 0)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, now, got, time.Millisecond)
+	})
+	t.Run("adds half the round trip", func(t *testing.T) {
+		got, err := ParseResponse(serverResponse(now), 2*time.Second)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, now.Add(time.Second), got, time.Millisecond)
+	})
+	t.Run("fails on short packet", func(t *testing.T) {
+		_, err := ParseResponse([]byte{0x24}, 0)
+		assert.NotNil(t, err)
+	})
+	t.Run("fails on client mode packet", func(t *testing.T) {
+		rsp := serverResponse(now)
+		rsp[0] = clientHeader
+		_, err := ParseResponse(rsp, 0)
+		assert.NotNil(t, err)
+	})
+	t.Run("fails on kiss-o'-death", func(t *testing.T) {
+		rsp := serverResponse(now)
+		rsp[1] = 0
+		_, err := ParseResponse(rsp, 0)
+		assert.NotNil(t, err)
+	})
+	t.Run("fails on missing timestamp", func(t *testing.T) {
+		rsp := serverResponse(now)
+		copy(rsp[40:48], make([]byte, 8))
+		_, err := ParseResponse(rsp, 0)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 30, 15, 0, time.UTC)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, packetSize)
+		_, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		conn.WriteTo(serverResponse(now), addr)
+	}()
+
+	got, err := Query(conn.LocalAddr().String(), time.Second)
+	assert.Nil(t, err)
+	assert.WithinDuration(t, now, got, time.Second)
+
+	t.Run("times out without a server", func(t *testing.T) {
+		silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		defer silent.Close()
+		_, err = Query(silent.LocalAddr().String(), 50*time.Millisecond)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,54 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package output
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Destinations of -output besides a file path. The command always writes to stdout, the
+// other destinations receive a copy of its result document.
+const (
+	Stdout   = "stdout"
+	Syslog   = "syslog"
+	EventLog = "eventlog"
+)
+
+// SyslogTag names rpc in the syslog and Windows Event Log entries
+const SyslogTag = "rpc"
+
+// CheckDestination fails for a destination -output can not write on this platform
+func CheckDestination(destination string) error {
+	switch destination {
+	case Stdout:
+		return nil
+	case Syslog:
+		return checkSyslog()
+	case EventLog:
+		return checkEventLog()
+	}
+	return nil
+}
+
+// WriteDocument writes the result document of a command to a destination of -output. A file
+// is replaced with the document of the last command, syslog and the Event Log get an entry.
+func WriteDocument(destination string, document []byte, failed bool) error {
+	switch destination {
+	case Stdout:
+		return nil
+	case Syslog:
+		return writeSyslog(document, failed)
+	case EventLog:
+		return writeEventLog(document, failed)
+	}
+	if dir := filepath.Dir(destination); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+	// the document may hold addresses and identifiers of the device
+	return os.WriteFile(destination, document, 0600)
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+// +build !windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package output
+
+import (
+	"errors"
+	"log/syslog"
+	"strings"
+)
+
+func checkSyslog() error {
+	return nil
+}
+
+func checkEventLog() error {
+	return errors.New("the Windows Event Log is only available on Windows, use -output syslog")
+}
+
+func writeSyslog(document []byte, failed bool) error {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, SyslogTag)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	message := strings.TrimSpace(string(document))
+	if failed {
+		return w.Err(message)
+	}
+	return w.Info(message)
+}
+
+func writeEventLog(document []byte, failed bool) error {
+	return checkEventLog()
+}
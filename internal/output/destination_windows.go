@@ -0,0 +1,44 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package output
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// event IDs of the result documents, the service logs with 1 to 3
+const (
+	eventIDSucceeded = 10
+	eventIDFailed    = 11
+)
+
+func checkSyslog() error {
+	return errors.New("syslog is not available on Windows, use -output eventlog")
+}
+
+func checkEventLog() error {
+	return nil
+}
+
+func writeSyslog(document []byte, failed bool) error {
+	return checkSyslog()
+}
+
+// writeEventLog adds an entry of the rpc source, which rpc service install registers
+func writeEventLog(document []byte, failed bool) error {
+	elog, err := eventlog.Open(SyslogTag)
+	if err != nil {
+		return err
+	}
+	defer elog.Close()
+	message := strings.TrimSpace(string(document))
+	if failed {
+		return elog.Error(eventIDFailed, message)
+	}
+	return elog.Info(eventIDSucceeded, message)
+}
@@ -0,0 +1,135 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// OutputWriter collects the results of a command and renders them in a specific format.
+// Field values are named with a key for structured output and a label for text output.
+// Free-form lines written with Println/Printf only appear in text output.
+type OutputWriter interface {
+	Field(key string, label string, value interface{})
+	Println(line string)
+	Printf(format string, args ...interface{})
+	Flush() error
+}
+
+// NewWriter returns the OutputWriter for the format writing to stdout
+func NewWriter(format Format) OutputWriter {
+	return NewWriterTo(format, os.Stdout)
+}
+
+func NewWriterTo(format Format, out io.Writer) OutputWriter {
+	switch format {
+	case JSON:
+		return &JSONWriter{structuredWriter{out: out, data: map[string]interface{}{}}}
+	case YAML:
+		return &YAMLWriter{structuredWriter{out: out, data: map[string]interface{}{}}}
+	default:
+		return &TextWriter{out: out}
+	}
+}
+
+// FormatFromFlags picks the output format from the command line selections
+func FormatFromFlags(jsonOutput bool, yamlOutput bool) Format {
+	if yamlOutput {
+		return YAML
+	}
+	if jsonOutput {
+		return JSON
+	}
+	return Text
+}
+
+// TextWriter writes human readable lines immediately
+type TextWriter struct {
+	out io.Writer
+}
+
+// Field prints the label and value. An empty label keeps the value out of text output,
+// which is useful for values the caller formats itself with Println/Printf.
+func (w *TextWriter) Field(key string, label string, value interface{}) {
+	if label == "" {
+		return
+	}
+	fmt.Fprintf(w.out, "%s: %v\n", label, value)
+}
+
+func (w *TextWriter) Println(line string) {
+	fmt.Fprintln(w.out, line)
+}
+
+func (w *TextWriter) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.out, format, args...)
+}
+
+func (w *TextWriter) Flush() error {
+	return nil
+}
+
+type structuredWriter struct {
+	out  io.Writer
+	data map[string]interface{}
+}
+
+func (w *structuredWriter) Field(key string, label string, value interface{}) {
+	w.data[key] = value
+}
+
+func (w *structuredWriter) Println(line string) {}
+
+func (w *structuredWriter) Printf(format string, args ...interface{}) {}
+
+// JSONWriter collects fields and writes them as a single JSON document on Flush
+type JSONWriter struct {
+	structuredWriter
+}
+
+func (w *JSONWriter) Flush() error {
+	outBytes, err := json.MarshalIndent(w.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w.out, string(outBytes))
+	return err
+}
+
+// YAMLWriter collects fields and writes them as a single YAML document on Flush.
+// Values are converted through JSON first so keys match the JSON output.
+type YAMLWriter struct {
+	structuredWriter
+}
+
+func (w *YAMLWriter) Flush() error {
+	jsonBytes, err := json.Marshal(w.data)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(jsonBytes, &generic); err != nil {
+		return err
+	}
+	outBytes, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(outBytes)
+	return err
+}
@@ -0,0 +1,94 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2023
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	IPAddress string `json:"ipAddress"`
+	Enabled   bool   `json:"isEnabled"`
+}
+
+func writeSample(w OutputWriter) {
+	w.Field("uuid", "UUID\t\t\t", "123-456")
+	w.Println("---Wired Adapter---")
+	w.Field("wired", "", sample{IPAddress: "10.0.0.1", Enabled: true})
+	w.Printf("%s (%s)\n", "name", "Default")
+}
+
+func TestFormatFromFlags(t *testing.T) {
+	assert.Equal(t, Text, FormatFromFlags(false, false))
+	assert.Equal(t, JSON, FormatFromFlags(true, false))
+	assert.Equal(t, YAML, FormatFromFlags(false, true))
+	assert.Equal(t, YAML, FormatFromFlags(true, true))
+}
+
+func TestTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterTo(Text, &buf)
+	writeSample(w)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, "UUID\t\t\t: 123-456\n---Wired Adapter---\nname (Default)\n", buf.String())
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterTo(JSON, &buf)
+	writeSample(w)
+	assert.Nil(t, w.Flush())
+	expected := "{\n  \"uuid\": \"123-456\",\n  \"wired\": {\n    \"ipAddress\": \"10.0.0.1\",\n    \"isEnabled\": true\n  }\n}\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestYAMLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterTo(YAML, &buf)
+	writeSample(w)
+	assert.Nil(t, w.Flush())
+	expected := "uuid: 123-456\nwired:\n    ipAddress: 10.0.0.1\n    isEnabled: true\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestStructuredWriterError(t *testing.T) {
+	var buf bytes.Buffer
+	for _, format := range []Format{JSON, YAML} {
+		w := NewWriterTo(format, &buf)
+		w.Field("bad", "", make(chan int))
+		assert.NotNil(t, w.Flush())
+	}
+}
+
+func TestWriteDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpc", "last-info.json")
+	assert.NoError(t, WriteDocument(path, []byte("{\"uuid\": \"123-456\"}\n"), false))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"uuid\": \"123-456\"}\n", string(content))
+	// the document of the last command replaces the file
+	assert.NoError(t, WriteDocument(path, []byte("{}\n"), true))
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, "{}\n", string(content))
+	assert.NoError(t, WriteDocument(Stdout, []byte("{}\n"), false))
+}
+
+func TestCheckDestination(t *testing.T) {
+	assert.NoError(t, CheckDestination(Stdout))
+	assert.NoError(t, CheckDestination("/var/lib/rpc/last-info.json"))
+	if runtime.GOOS == "windows" {
+		assert.Error(t, CheckDestination(Syslog))
+		assert.NoError(t, CheckDestination(EventLog))
+	} else {
+		assert.NoError(t, CheckDestination(Syslog))
+		assert.Error(t, CheckDestination(EventLog))
+	}
+}
@@ -0,0 +1,227 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"rpc/internal/amt"
+	"strings"
+	"time"
+
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	// ErrInvalidChain is wrapped by the errors of a provisioning certificate that can not be
+	// decoded or whose chain does not verify
+	ErrInvalidChain = errors.New("invalid provisioning certificate")
+	// ErrHashNotFound is wrapped by the errors of a root certificate that is not trusted by AMT
+	ErrHashNotFound = errors.New("certificate hash not found")
+)
+
+// amtProvisioningOID is the extended key usage of Intel AMT provisioning certificates
+var amtProvisioningOID = asn1.ObjectIdentifier{2, 16, 840, 1, 113741, 1, 2, 3}
+
+// Chain is a provisioning certificate decoded from a .pfx, ordered from the leaf to the root
+type Chain struct {
+	Certs []*x509.Certificate
+	Key   crypto.PrivateKey
+}
+
+// Leaf returns the certificate of the provisioning key
+func (c Chain) Leaf() *x509.Certificate {
+	return c.Certs[0]
+}
+
+// Root returns the self-signed certificate ending the chain, nil when the .pfx does not include it
+func (c Chain) Root() *x509.Certificate {
+	last := c.Certs[len(c.Certs)-1]
+	if len(c.Certs) == 1 || !bytes.Equal(last.RawSubject, last.RawIssuer) {
+		return nil
+	}
+	return last
+}
+
+// DecodePFX decodes a base64 encoded .pfx and orders its certificates by issuer, the
+// certificates that are not part of the chain of the leaf are left out
+func DecodePFX(pfxb64 string, password string) (Chain, error) {
+	pfx, err := base64.StdEncoding.DecodeString(pfxb64)
+	if err != nil {
+		return Chain{}, fmt.Errorf("%w: %w", ErrInvalidChain, err)
+	}
+	key, leaf, extra, err := pkcs12.DecodeChain(pfx, password)
+	if err != nil {
+		return Chain{}, fmt.Errorf("%w: decrypting the .pfx failed, check the password", ErrInvalidChain)
+	}
+	chain := Chain{Certs: []*x509.Certificate{leaf}, Key: key}
+	for cert := leaf; !bytes.Equal(cert.RawSubject, cert.RawIssuer); {
+		issuer := findIssuer(cert, extra)
+		if issuer == nil {
+			break
+		}
+		chain.Certs = append(chain.Certs, issuer)
+		cert = issuer
+	}
+	return chain, nil
+}
+
+func findIssuer(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if candidate != cert && bytes.Equal(candidate.RawSubject, cert.RawIssuer) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// Verify checks the signatures and validity of the chain at the given time, and that the
+// leaf is meant for AMT provisioning
+func (c Chain) Verify(now time.Time) error {
+	root := c.Root()
+	if root == nil {
+		last := c.Certs[len(c.Certs)-1]
+		return fmt.Errorf("%w: the chain ends at %s, the .pfx does not include its root certificate", ErrInvalidChain, last.Subject)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	intermediates := x509.NewCertPool()
+	for _, cert := range c.Certs[1 : len(c.Certs)-1] {
+		intermediates.AddCert(cert)
+	}
+	_, err := c.Leaf().Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidChain, err)
+	}
+	if !provisioningUsage(c.Leaf()) {
+		return fmt.Errorf("%w: %s has neither the AMT provisioning nor the server authentication extended key usage", ErrInvalidChain, c.Leaf().Subject)
+	}
+	return nil
+}
+
+func provisioningUsage(cert *x509.Certificate) bool {
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageServerAuth {
+			return true
+		}
+	}
+	for _, oid := range cert.UnknownExtKeyUsage {
+		if oid.Equal(amtProvisioningOID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint returns the hex encoded hash of the certificate, with the algorithm that
+// produces hashes of the given length in bytes
+func Fingerprint(cert *x509.Certificate, size int) string {
+	var sum []byte
+	switch size {
+	case md5.Size:
+		hash := md5.Sum(cert.Raw)
+		sum = hash[:]
+	case sha1.Size:
+		hash := sha1.Sum(cert.Raw)
+		sum = hash[:]
+	case sha256.Size:
+		hash := sha256.Sum256(cert.Raw)
+		sum = hash[:]
+	case sha512.Size384:
+		hash := sha512.Sum384(cert.Raw)
+		sum = hash[:]
+	case sha512.Size:
+		hash := sha512.Sum512(cert.Raw)
+		sum = hash[:]
+	default:
+		return ""
+	}
+	return hex.EncodeToString(sum)
+}
+
+// MatchHash returns the AMT hash of the root certificate. The error says why none matched:
+// AMT has no active hashes, the matching hash is not active, or the root is not trusted.
+func MatchHash(root *x509.Certificate, hashes []amt.CertHashEntry) (amt.CertHashEntry, error) {
+	active := 0
+	var inactive *amt.CertHashEntry
+	for i, hash := range hashes {
+		if hash.IsActive {
+			active++
+		}
+		// the algorithm names of AMT are not reliable, the length of the hash tells it
+		if !strings.EqualFold(Fingerprint(root, len(hash.Hash)/2), hash.Hash) {
+			continue
+		}
+		if hash.IsActive {
+			return hash, nil
+		}
+		inactive = &hashes[i]
+	}
+	switch {
+	case inactive != nil:
+		return amt.CertHashEntry{}, fmt.Errorf("%w: %s matches the AMT hash %q, which is not active", ErrHashNotFound, root.Subject, inactive.Name)
+	case active == 0:
+		return amt.CertHashEntry{}, fmt.Errorf("%w: AMT has no active trusted root certificate hashes", ErrHashNotFound)
+	}
+	return amt.CertHashEntry{}, fmt.Errorf("%w: the SHA256 fingerprint %s of %s matches none of the %d active AMT hashes",
+		ErrHashNotFound, Fingerprint(root, sha256.Size), root.Subject, active)
+}
+
+// CertInfo describes a certificate of the chain in the report
+type CertInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+// Report is the result of checking a provisioning certificate
+type Report struct {
+	Chain       []CertInfo `json:"chain,omitempty"`
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	// MatchedHash is the AMT hash of the root certificate
+	MatchedHash *amt.CertHashEntry `json:"matchedHash,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// Check decodes the provisioning certificate, verifies its chain and matches its root
+// with the AMT hashes. The error wraps ErrInvalidChain or ErrHashNotFound.
+func Check(pfxb64 string, password string, hashes []amt.CertHashEntry, now time.Time) (Report, error) {
+	report := Report{}
+	chain, err := DecodePFX(pfxb64, password)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	for _, cert := range chain.Certs {
+		report.Chain = append(report.Chain, CertInfo{Subject: cert.Subject.String(), Issuer: cert.Issuer.String(), NotAfter: cert.NotAfter})
+	}
+	if err = chain.Verify(now); err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	report.Fingerprint = Fingerprint(chain.Root(), sha256.Size)
+	hash, err := MatchHash(chain.Root(), hashes)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	report.MatchedHash = &hash
+	return report, nil
+}
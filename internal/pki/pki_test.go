@@ -0,0 +1,87 @@
+package pki
+
+import (
+	"errors"
+	"rpc/internal/amt"
+	"rpc/internal/certtest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCerts = certtest.New("P@ssw0rd")
+
+func TestDecodePFX(t *testing.T) {
+	t.Run("orders the chain from the leaf to the root", func(t *testing.T) {
+		chain, err := DecodePFX(testCerts.Pfxb64, testCerts.PfxPassword)
+		assert.NoError(t, err)
+		assert.Len(t, chain.Certs, 3)
+		assert.Equal(t, testCerts.LeafCert.Raw, chain.Leaf().Raw)
+		assert.Equal(t, testCerts.InterCert.Raw, chain.Certs[1].Raw)
+		assert.Equal(t, testCerts.CaCert.Raw, chain.Root().Raw)
+		assert.NotNil(t, chain.Key)
+	})
+	t.Run("fails on the wrong password", func(t *testing.T) {
+		_, err := DecodePFX(testCerts.Pfxb64, "wrong")
+		assert.ErrorIs(t, err, ErrInvalidChain)
+	})
+	t.Run("fails on invalid base64", func(t *testing.T) {
+		_, err := DecodePFX("not base64", testCerts.PfxPassword)
+		assert.ErrorIs(t, err, ErrInvalidChain)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	chain, err := DecodePFX(testCerts.Pfxb64, testCerts.PfxPassword)
+	assert.NoError(t, err)
+
+	t.Run("passes a valid chain", func(t *testing.T) {
+		assert.NoError(t, chain.Verify(time.Now()))
+	})
+	t.Run("fails an expired chain", func(t *testing.T) {
+		assert.ErrorIs(t, chain.Verify(time.Now().AddDate(1, 0, 0)), ErrInvalidChain)
+	})
+	t.Run("fails without the root certificate", func(t *testing.T) {
+		partial := Chain{Certs: chain.Certs[:2], Key: chain.Key}
+		assert.Nil(t, partial.Root())
+		assert.ErrorIs(t, partial.Verify(time.Now()), ErrInvalidChain)
+	})
+}
+
+func TestMatchHash(t *testing.T) {
+	root := &testCerts.CaCert
+	tests := []struct {
+		name    string
+		hashes  []amt.CertHashEntry
+		want    string
+		wantErr bool
+	}{
+		{"matches the SHA256 hash", []amt.CertHashEntry{{Name: "other", Hash: "00", IsActive: true}, {Name: "root", Hash: testCerts.CaFingerprint, IsActive: true}}, "root", false},
+		{"matches the SHA384 hash", []amt.CertHashEntry{{Name: "root", Hash: Fingerprint(root, 48), IsActive: true}}, "root", false},
+		{"fails on an inactive hash", []amt.CertHashEntry{{Name: "root", Hash: testCerts.CaFingerprint}}, "", true},
+		{"fails without active hashes", nil, "", true},
+		{"fails when no hash matches", []amt.CertHashEntry{{Name: "other", Hash: Fingerprint(&testCerts.InterCert, 32), IsActive: true}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := MatchHash(root, tt.hashes)
+			assert.Equal(t, tt.want, hash.Name)
+			assert.Equal(t, tt.wantErr, errors.Is(err, ErrHashNotFound))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	hashes := []amt.CertHashEntry{{Name: "root", Hash: testCerts.CaFingerprint, IsActive: true}}
+	report, err := Check(testCerts.Pfxb64, testCerts.PfxPassword, hashes, time.Now())
+	assert.NoError(t, err)
+	assert.Len(t, report.Chain, 3)
+	assert.Equal(t, testCerts.CaFingerprint, report.Fingerprint)
+	assert.Equal(t, "root", report.MatchedHash.Name)
+
+	report, err = Check(testCerts.Pfxb64, testCerts.PfxPassword, nil, time.Now())
+	assert.ErrorIs(t, err, ErrHashNotFound)
+	assert.Nil(t, report.MatchedHash)
+	assert.Equal(t, err.Error(), report.Error)
+}
@@ -0,0 +1,129 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+)
+
+// taskFailures maps the maintenance tasks to the return code of a failed task
+var taskFailures = map[string]utils.ReturnCode{
+	utils.SubCommandSyncClock:      utils.SyncClockFailed,
+	utils.SubCommandSyncHostname:   utils.SyncHostnameFailed,
+	utils.SubCommandSyncIP:         utils.SyncIpFailed,
+	utils.SubCommandSyncDeviceInfo: utils.SyncDeviceInfoFailed,
+}
+
+// TaskResult is the outcome of one maintenance task of a batch
+type TaskResult struct {
+	Task       string           `json:"task"`
+	ReturnCode utils.ReturnCode `json:"returnCode"`
+	Result     string           `json:"result"`
+	Status     string           `json:"status,omitempty"`
+}
+
+// ExecuteBatch runs the maintenance tasks given with -task or -all one after the other
+// over a single connection to RPS. Every task runs even if an earlier one failed, the
+// return code of the first failed task is returned.
+func ExecuteBatch(flags *flags.Flags) utils.ReturnCode {
+	return ExecuteBatchTo(flags, os.Stdout)
+}
+
+// ExecuteBatchTo runs the maintenance tasks like ExecuteBatch and writes the results to out
+func ExecuteBatchTo(flags *flags.Flags, out io.Writer) utils.ReturnCode {
+	results, rc := executeBatch(flags, NewPayload(flags), out)
+	if results == nil {
+		return rc
+	}
+	if err := writeBatchResults(out, results, flags.JsonOutput); err != nil {
+		log.Error(err)
+	}
+	return rc
+}
+
+func executeBatch(flags *flags.Flags, payload Payload, out io.Writer) ([]TaskResult, utils.ReturnCode) {
+	var messages []Message
+	for _, task := range flags.MaintenanceTasks {
+		// the message is built from the command, so each task gets its own copy
+		taskFlags := *flags
+		taskFlags.SubCommand = task
+		setCommandMethod(&taskFlags)
+		message, err := payload.CreateMessageRequest(taskFlags)
+		if err != nil {
+			log.Error(err)
+			return nil, utils.MissingOrIncorrectPassword
+		}
+		if flags.DryRun {
+			if err = writeDryRun(out, message, flags.Secrets()); err != nil {
+				log.Error(err)
+				return nil, utils.UnmarshalMessageFailed
+			}
+			continue
+		}
+		messages = append(messages, message)
+	}
+	if flags.DryRun {
+		return nil, utils.DryRunCompleted
+	}
+
+	executor, err := NewExecutor(*flags)
+	if err != nil {
+		log.Error(err)
+		return nil, utils.ServerCerificateVerificationFailed
+	}
+	progress := executor.MakeItSoAll(messages)
+
+	rc := utils.Success
+	results := make([]TaskResult, len(flags.MaintenanceTasks))
+	for i, task := range flags.MaintenanceTasks {
+		results[i] = TaskResult{Task: task, ReturnCode: taskFailures[task], Result: "failed"}
+		if i < len(progress) {
+			results[i].Status = progress[i].Status
+			if progress[i].Phase == PhaseComplete {
+				results[i].ReturnCode = utils.Success
+				results[i].Result = "success"
+			}
+		} else {
+			results[i].Result = "not run"
+		}
+		if rc == utils.Success {
+			rc = results[i].ReturnCode
+		}
+	}
+	if rc != utils.Success && cancelled(flags) {
+		rc = utils.CancelledByUser
+	}
+	return results, rc
+}
+
+// writeBatchResults prints the result of each task as a table or as a JSON array
+func writeBatchResults(w io.Writer, results []TaskResult, jsonOutput bool) error {
+	if jsonOutput {
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	}
+	for _, result := range results {
+		line := fmt.Sprintf("%-16s: %s", result.Task, result.Result)
+		if result.ReturnCode != utils.Success {
+			line += fmt.Sprintf(" (%d %s)", result.ReturnCode, result.ReturnCode)
+		}
+		if result.Status != "" {
+			line += ", " + result.Status
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
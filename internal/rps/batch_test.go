@@ -0,0 +1,220 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rpc/internal/flags"
+	"rpc/internal/lm"
+	"rpc/internal/logging"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteBatchDryRun(t *testing.T) {
+	f := &flags.Flags{
+		Command:          utils.CommandMaintenance,
+		Password:         "P@ssw0rd",
+		DryRun:           true,
+		MaintenanceTasks: []string{utils.SubCommandSyncClock, utils.SubCommandSyncDeviceInfo},
+	}
+	var out bytes.Buffer
+	results, rc := executeBatch(f, Payload{AMT: MockAMT{}}, &out)
+	assert.Nil(t, results)
+	assert.Equal(t, utils.DryRunCompleted, rc)
+	assert.Equal(t, 2, strings.Count(out.String(), "Dry run, this message would be sent to the server:"))
+	assert.Contains(t, out.String(), `"method": "maintenance -password ******** --synctime"`)
+	assert.Contains(t, out.String(), `"method": "maintenance -password ******** --syncdeviceinfo"`)
+	assert.NotContains(t, out.String(), "P@ssw0rd")
+	// the tasks work on copies of the flags
+	assert.Equal(t, utils.CommandMaintenance, f.Command)
+}
+
+func TestWriteBatchResults(t *testing.T) {
+	results := []TaskResult{
+		{Task: utils.SubCommandSyncClock, ReturnCode: utils.Success, Result: "success", Status: "Time Synchronized"},
+		{Task: utils.SubCommandSyncIP, ReturnCode: utils.SyncIpFailed, Result: "failed"},
+	}
+	t.Run("prints a line per task", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, writeBatchResults(&out, results, false))
+		assert.Equal(t, "syncclock       : success, Time Synchronized\nsyncip          : failed (152 SyncIpFailed)\n", out.String())
+	})
+	t.Run("prints JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, writeBatchResults(&out, results, true))
+		assert.Contains(t, out.String(), `"task": "syncip"`)
+		assert.Contains(t, out.String(), `"returnCode": 152`)
+		assert.Contains(t, out.String(), `"result": "failed"`)
+	})
+}
+
+type mockLocalManagement struct{}
+
+func (mockLocalManagement) Initialize() error      { return nil }
+func (mockLocalManagement) Connect() error         { return nil }
+func (mockLocalManagement) Listen()                {}
+func (mockLocalManagement) Send(data []byte) error { return nil }
+func (mockLocalManagement) Close() error           { return nil }
+
+// newBatchServer answers every request with success, closing the connection
+// after each answer when closeAfterRequest is set
+func newBatchServer(closeAfterRequest bool) (*httptest.Server, *int) {
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		connections++
+		defer c.Close()
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+			c.WriteJSON(Message{Method: "success", Message: `{"Status":"ok"}`})
+			if closeAfterRequest {
+				return
+			}
+		}
+	}))
+	return server, &connections
+}
+
+func TestMakeItSoAll(t *testing.T) {
+	for _, closeAfterRequest := range []bool{false, true} {
+		server, connections := newBatchServer(closeAfterRequest)
+		f := flags.NewFlags([]string{})
+		f.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+		executor := Executor{
+			server:          NewAMTActivationServer(f),
+			localManagement: &lm.Connection{LocalMananger: mockLocalManagement{}, Data: make(chan []byte), Errors: make(chan error)},
+		}
+		assert.NoError(t, executor.server.Connect(true))
+		progress := executor.MakeItSoAll([]Message{{Method: "first"}, {Method: "second"}})
+		assert.Len(t, progress, 2)
+		for _, p := range progress {
+			assert.Equal(t, PhaseComplete, p.Phase)
+			assert.Equal(t, "ok", p.Status)
+		}
+		if closeAfterRequest {
+			assert.Equal(t, 2, *connections)
+		} else {
+			assert.Equal(t, 1, *connections)
+		}
+		server.Close()
+	}
+}
+
+func TestMakeItSoAllCancelled(t *testing.T) {
+	received := make(chan Message, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			var message Message
+			if err := c.ReadJSON(&message); err != nil {
+				close(received)
+				return
+			}
+			// the request is never answered, so only the interrupt ends it
+			received <- message
+		}
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := flags.NewFlags([]string{})
+	f.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	f.Context = ctx
+	executor := Executor{
+		server:          NewAMTActivationServer(f),
+		localManagement: &lm.Connection{LocalMananger: mockLocalManagement{}, Data: make(chan []byte), Errors: make(chan error)},
+	}
+	assert.NoError(t, executor.server.Connect(true))
+	go func() {
+		<-received
+		cancel()
+	}()
+	progress := executor.MakeItSoAll([]Message{{Method: "first"}, {Method: "second"}})
+	assert.Len(t, progress, 1)
+	assert.Equal(t, PhaseCancelled, progress[0].Phase)
+	goodbye := <-received
+	assert.Equal(t, "cancel", goodbye.Method)
+	assert.Equal(t, "cancelled", goodbye.Status)
+	_, open := <-received
+	assert.False(t, open)
+	assert.True(t, cancelled(f))
+}
+
+func TestMakeItSoAllUploadLogs(t *testing.T) {
+	received := make(chan Message, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		for {
+			var message Message
+			if err := c.ReadJSON(&message); err != nil {
+				close(received)
+				return
+			}
+			received <- message
+			// log messages are not answered
+			if message.Method == "first" {
+				c.WriteJSON(Message{Method: "success", Message: `{"Status":"ok"}`})
+			}
+		}
+	}))
+	defer server.Close()
+	f := flags.NewFlags([]string{})
+	f.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	executor := Executor{
+		server:          NewAMTActivationServer(f),
+		localManagement: &lm.Connection{LocalMananger: mockLocalManagement{}, Data: make(chan []byte), Errors: make(chan error)},
+	}
+	assert.NoError(t, executor.server.Connect(true))
+	executor.transcript = logging.StartTranscript()
+	log.Warn("activating with P@ssw0rd")
+	logging.Redact("P@ssw0rd")
+	log.Warn("activating with P@ssw0rd")
+
+	progress := executor.MakeItSoAll([]Message{{Method: "first"}})
+	assert.Len(t, progress, 1)
+	assert.NoError(t, executor.server.Close())
+
+	upload := <-received
+	assert.Equal(t, "log", upload.Method)
+	data, err := base64.StdEncoding.DecodeString(upload.Payload)
+	assert.NoError(t, err)
+	var payload LogPayload
+	assert.NoError(t, json.Unmarshal(data, &payload))
+	assert.Len(t, payload.Entries, 2)
+	assert.Equal(t, "warning", payload.Entries[1].Level)
+	assert.Equal(t, logging.ModuleRPS, payload.Entries[1].Module)
+	assert.Equal(t, "activating with ********", payload.Entries[1].Message)
+	assert.Equal(t, "first", (<-received).Method)
+
+	// the entries logged after the request are uploaded once it is done
+	assert.Equal(t, "log", (<-received).Method)
+	_, open := <-received
+	assert.False(t, open)
+	log.Warn("after the requests")
+	entries, _ := executor.transcript.Take()
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,72 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// splitMessage returns the message in chunks whose payloads are at most size bytes of
+// the base64 payload. The message is returned whole when size is 0 or it is small enough.
+func splitMessage(message Message, size int) []Message {
+	if size <= 0 || len(message.Payload) <= size {
+		return []Message{message}
+	}
+	count := (len(message.Payload) + size - 1) / size
+	messages := make([]Message, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := message
+		end := (i + 1) * size
+		if end > len(message.Payload) {
+			end = len(message.Payload)
+		}
+		chunk.Payload = message.Payload[i*size : end]
+		chunk.Chunk = i + 1
+		chunk.Chunks = count
+		messages = append(messages, chunk)
+	}
+	return messages
+}
+
+// chunkAssembler joins the payloads of chunked messages from RPS
+type chunkAssembler struct {
+	message Message
+	payload strings.Builder
+}
+
+// add returns the message once its last chunk arrived and nil before. Messages that are
+// not chunked are returned unchanged. A chunk out of order discards the message.
+func (c *chunkAssembler) add(data []byte) ([]byte, error) {
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil || message.Chunks <= 1 {
+		// ProcessMessage reports messages that are not JSON
+		return data, nil
+	}
+	if message.Chunk == 1 {
+		// a new message starts, an incomplete one before it is dropped
+		c.reset()
+	}
+	if message.Chunk != c.message.Chunk+1 || c.message.Chunk > 0 && message.Chunks != c.message.Chunks {
+		expected := c.message.Chunk + 1
+		c.reset()
+		return nil, fmt.Errorf("received chunk %d of %d, expected chunk %d", message.Chunk, message.Chunks, expected)
+	}
+	c.payload.WriteString(message.Payload)
+	c.message = message
+	if message.Chunk < message.Chunks {
+		return nil, nil
+	}
+	message.Payload = c.payload.String()
+	message.Chunk, message.Chunks = 0, 0
+	c.reset()
+	return json.Marshal(message)
+}
+
+func (c *chunkAssembler) reset() {
+	c.message = Message{}
+	c.payload.Reset()
+}
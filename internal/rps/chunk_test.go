@@ -0,0 +1,95 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/json"
+	"rpc/internal/flags"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMessage(t *testing.T) {
+	message := Message{Method: "response", Payload: "0123456789"}
+	assert.Equal(t, []Message{message}, splitMessage(message, 0))
+	assert.Equal(t, []Message{message}, splitMessage(message, 10))
+
+	chunks := splitMessage(message, 4)
+	assert.Len(t, chunks, 3)
+	for i, payload := range []string{"0123", "4567", "89"} {
+		assert.Equal(t, "response", chunks[i].Method)
+		assert.Equal(t, payload, chunks[i].Payload)
+		assert.Equal(t, i+1, chunks[i].Chunk)
+		assert.Equal(t, 3, chunks[i].Chunks)
+	}
+}
+
+func TestChunkAssembler(t *testing.T) {
+	message := Message{Method: "wsman", Payload: "0123456789"}
+	whole, _ := json.Marshal(message)
+	marshal := func(m Message) []byte {
+		data, _ := json.Marshal(m)
+		return data
+	}
+
+	t.Run("returns messages that are not chunked unchanged", func(t *testing.T) {
+		var c chunkAssembler
+		data, err := c.add(whole)
+		assert.NoError(t, err)
+		assert.Equal(t, whole, data)
+	})
+	t.Run("joins the chunks", func(t *testing.T) {
+		var c chunkAssembler
+		chunks := splitMessage(message, 4)
+		for _, chunk := range chunks[:2] {
+			data, err := c.add(marshal(chunk))
+			assert.NoError(t, err)
+			assert.Nil(t, data)
+		}
+		data, err := c.add(marshal(chunks[2]))
+		assert.NoError(t, err)
+		assert.Equal(t, whole, data)
+	})
+	t.Run("drops a message with a missing chunk", func(t *testing.T) {
+		var c chunkAssembler
+		chunks := splitMessage(message, 4)
+		_, err := c.add(marshal(chunks[0]))
+		assert.NoError(t, err)
+		data, err := c.add(marshal(chunks[2]))
+		assert.Error(t, err)
+		assert.Nil(t, data)
+		// the next message is assembled again
+		for _, chunk := range chunks {
+			data, err = c.add(marshal(chunk))
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, whole, data)
+	})
+}
+
+func TestSendChunked(t *testing.T) {
+	f := flags.NewFlags([]string{})
+	f.URL = testUrl
+	f.ChunkSize = 16
+	server := NewAMTActivationServer(f)
+	err := server.Connect(true)
+	assert.NoError(t, err)
+	defer server.Close()
+	rpsChan := server.Listen()
+	message := Message{Method: "response", Payload: strings.Repeat("QUJD", 20)}
+	err = server.Send(message)
+	assert.NoError(t, err)
+	// the echo server returns each chunk, Listen joins them again
+	expected, _ := json.Marshal(message)
+	select {
+	case data := <-rpsChan:
+		assert.Equal(t, expected, data)
+	case <-time.After(2 * time.Second):
+		t.Error("no message received")
+	}
+}
@@ -0,0 +1,97 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"time"
+)
+
+// syncDeviceInfoContinuously sends the device info right away and then on every -interval
+// over one connection to RPS, which the heartbeat keeps open in between, until rpc is
+// stopped. The device info is read again for each update. A failed update is logged and
+// the next one is sent on time, a lost connection is opened again for it.
+func syncDeviceInfoContinuously(flags *flags.Flags, payload Payload) utils.ReturnCode {
+	executor, err := NewExecutor(*flags)
+	if err != nil {
+		log.Error(err)
+		return utils.ServerCerificateVerificationFailed
+	}
+	ticker := time.NewTicker(flags.SyncDeviceInfo.Interval)
+	defer ticker.Stop()
+	executor.syncEvery(ticker.C, func() (Message, error) {
+		return payload.CreateMessageRequest(*flags)
+	})
+	return utils.Success
+}
+
+// syncEvery sends the request built by next right away and then on every tick, until rpc
+// is interrupted. It keeps the connection of the last reconnect, so e is a pointer.
+func (e *Executor) syncEvery(tick <-chan time.Time, next func() (Message, error)) {
+	rpsDataChannel := e.server.Listen()
+	defer e.endTranscript()
+	defer e.localManagement.Close()
+	defer close(e.localManagement.Data)
+	defer close(e.localManagement.Errors)
+	if e.localManagement.Status != nil {
+		defer close(e.localManagement.Status)
+	}
+
+	for {
+		message, err := next()
+		if err != nil {
+			log.Error("unable to read the device info: ", err)
+		} else {
+			rpsDataChannel = e.sendUpdate(message, rpsDataChannel)
+		}
+		if cancelled(e.server.flags) {
+			return
+		}
+		log.Infof("next device info update in %s", e.server.flags.SyncDeviceInfo.Interval)
+		select {
+		case <-tick:
+		case <-e.server.done():
+			log.Info("device info updates stopped")
+			e.uploadLogs()
+			if err = e.server.Close(); err != nil {
+				log.Debug("closing the RPS connection failed: ", err)
+			}
+			return
+		}
+	}
+}
+
+// sendUpdate sends one device info update and returns the channel of the connection it was
+// sent on. RPS may have closed the idle connection, the update is then sent again on a new
+// connection as long as nothing was exchanged with AMT for it.
+func (e *Executor) sendUpdate(message Message, rpsDataChannel chan []byte) chan []byte {
+	for attempt := 0; ; attempt++ {
+		// the connection is nil when the last reconnect failed
+		outcome := requestLost
+		if e.server.Conn != nil {
+			if err := e.send(message); err != nil {
+				log.Debug("sending the device info failed: ", err)
+			} else {
+				e.server.progress.Report(Progress{Phase: PhaseRequestSent, Percent: 10, Status: message.Method})
+				outcome = e.runRequest(rpsDataChannel)
+			}
+		}
+		switch {
+		case outcome == requestDone && e.server.progress.Last().Phase == PhaseComplete:
+			log.Info("device info updated")
+			return rpsDataChannel
+		case outcome != requestLost || attempt > 0 || e.server.progress.Last().Phase == PhaseExchanging:
+			log.Errorf("device info update failed with return code %d (%s)", utils.SyncDeviceInfoFailed, utils.SyncDeviceInfoFailed)
+			return rpsDataChannel
+		}
+		log.Info("RPS closed the connection, reconnecting")
+		if err := e.server.ConnectWithRetry(e.server.flags.SkipCertCheck); err != nil {
+			log.Error(err)
+			return rpsDataChannel
+		}
+		rpsDataChannel = e.server.Listen()
+	}
+}
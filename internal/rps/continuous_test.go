@@ -0,0 +1,55 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"context"
+	"errors"
+	"rpc/internal/flags"
+	"rpc/internal/lm"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncEvery(t *testing.T) {
+	for _, closeAfterRequest := range []bool{false, true} {
+		server, connections := newBatchServer(closeAfterRequest)
+		ctx, cancel := context.WithCancel(context.Background())
+		f := flags.NewFlags([]string{})
+		f.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+		f.Context = ctx
+		executor := Executor{
+			server:          NewAMTActivationServer(f),
+			localManagement: &lm.Connection{LocalMananger: mockLocalManagement{}, Data: make(chan []byte), Errors: make(chan error)},
+		}
+		assert.NoError(t, executor.server.Connect(true))
+
+		tick := make(chan time.Time)
+		go func() {
+			tick <- time.Now()
+			tick <- time.Now()
+		}()
+		updates := 0
+		executor.syncEvery(tick, func() (Message, error) {
+			updates++
+			if updates == 3 {
+				cancel()
+				return Message{}, errors.New("stopped")
+			}
+			return Message{Method: "maintenance --syncdeviceinfo"}, nil
+		})
+		assert.Equal(t, 3, updates)
+		assert.Equal(t, PhaseComplete, executor.server.progress.Last().Phase)
+		if closeAfterRequest {
+			assert.Equal(t, 2, *connections, "the update after the server closed the connection is sent on a new one")
+		} else {
+			assert.Equal(t, 1, *connections)
+		}
+		server.Close()
+	}
+}
@@ -5,54 +5,38 @@
 package rps
 
 import (
-	"os"
-	"os/signal"
+	"errors"
 	"rpc/internal/flags"
 	"rpc/internal/lm"
-	"rpc/pkg/utils"
-	"syscall"
-
-	log "github.com/sirupsen/logrus"
+	"rpc/internal/logging"
+	"rpc/internal/telemetry"
 )
 
 type Executor struct {
 	server          AMTActivationServer
-	localManagement lm.LocalMananger
-	isLME           bool
+	localManagement *lm.Connection
 	payload         Payload
-	data            chan []byte
-	errors          chan error
-	status          chan bool
+	// session saves the progress of an activation for activate -resume, nil for other commands
+	session *Session
+	// transcript collects the log entries uploaded with -uploadLogs, nil without it
+	transcript *logging.Transcript
 }
 
 func NewExecutor(flags flags.Flags) (Executor, error) {
-	// these are closed in the close function for each lm implementation
-	lmDataChannel := make(chan []byte)
-	lmErrorChannel := make(chan error)
-
 	client := Executor{
-		server:          NewAMTActivationServer(&flags),
-		localManagement: lm.NewLMSConnection(utils.LMSAddress, utils.LMSPort, lmDataChannel, lmErrorChannel),
-		data:            lmDataChannel,
-		errors:          lmErrorChannel,
+		server: NewAMTActivationServer(&flags),
 	}
-
-	// TEST CONNECTION TO SEE IF LMS EXISTS
-	err := client.localManagement.Connect()
-
-	if err != nil {
-		// client.localManagement.Close()
-		log.Trace("LMS not running.  Using LME Connection\n")
-		client.status = make(chan bool)
-		client.localManagement = lm.NewLMEConnection(lmDataChannel, lmErrorChannel, client.status)
-		client.isLME = true
-		client.localManagement.Initialize()
-	} else {
-		log.Trace("Using existing LMS\n")
-		client.localManagement.Close()
+	if flags.UploadLogs {
+		client.transcript = logging.StartTranscript()
 	}
 
-	err = client.server.Connect(flags.SkipCertCheck)
+	// LMS when it is running, the LME driver of rpc otherwise, unless -transport selects
+	// one of them. The exchanges with AMT fail when the transport can not be used.
+	var err error
+	client.localManagement, err = lm.Select(flags.Transport)
+	lm.LogSelectError(flags.Transport, err)
+
+	err = client.server.ConnectWithRetry(flags.SkipCertCheck)
 	if err != nil {
 		log.Error("error connecting to RPS")
 		// TODO: should the connection be closed?
@@ -62,50 +46,102 @@ func NewExecutor(flags flags.Flags) (Executor, error) {
 }
 
 func (e Executor) MakeItSo(messageRequest Message) {
+	e.MakeItSoAll([]Message{messageRequest})
+}
 
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+// MakeItSoAll sends the requests one after the other over the same RPS connection, the
+// next request is sent once RPS reported the previous one complete or failed. It returns
+// the progress reported last for each request that was sent. Once rpc is interrupted
+// no further request is sent.
+func (e Executor) MakeItSoAll(messageRequests []Message) []Progress {
 	rpsDataChannel := e.server.Listen()
-
-	log.Debug("sending activation request to RPS")
-	err := e.server.Send(messageRequest)
-	if err != nil {
-		log.Error(err.Error())
-		return
-	}
+	defer e.endTranscript()
 	defer e.localManagement.Close()
-	defer close(e.data)
-	defer close(e.errors)
-	if e.status != nil {
-		defer close(e.status)
+	defer close(e.localManagement.Data)
+	defer close(e.localManagement.Errors)
+	if e.localManagement.Status != nil {
+		defer close(e.localManagement.Status)
+	}
+
+	var results []Progress
+	reconnected := false
+	for i := 0; i < len(messageRequests); i++ {
+		if cancelled(e.server.flags) {
+			e.HandleInterrupt()
+			return results
+		}
+		log.Debug("sending activation request to RPS")
+		err := e.send(messageRequests[i])
+		outcome := requestLost
+		if err != nil {
+			log.Error(err.Error())
+		} else {
+			e.server.progress.Report(Progress{Phase: PhaseRequestSent, Percent: 10, Status: messageRequests[i].Method})
+			e.session.acknowledge(e.server.progress)
+			outcome = e.runRequest(rpsDataChannel)
+		}
+		// RPS may close the connection once a request is done, the next request is sent
+		// again on a new connection as long as nothing was exchanged with AMT for it
+		if outcome == requestLost && i > 0 && !reconnected && e.server.progress.Last().Phase != PhaseExchanging {
+			log.Info("RPS closed the connection, reconnecting")
+			reconnected = true
+			if err = e.server.ConnectWithRetry(e.server.flags.SkipCertCheck); err != nil {
+				log.Error(err)
+				return results
+			}
+			rpsDataChannel = e.server.Listen()
+			i--
+			continue
+		}
+		reconnected = false
+		results = append(results, e.server.progress.Last())
+		if outcome != requestDone {
+			return results
+		}
 	}
+	return results
+}
 
+const (
+	requestDone = iota
+	requestLost
+	requestInterrupted
+)
+
+// runRequest relays the messages of a request between RPS and AMT until RPS reports it
+// complete or failed, the connection to RPS is lost or rpc is interrupted. A round trip is
+// timed from the message sent to RPS until its answer.
+func (e Executor) runRequest(rpsDataChannel chan []byte) int {
+	roundTrip := telemetry.StartRPSRoundTrip()
 	for {
 		select {
-		case dataFromServer := <-rpsDataChannel:
+		case dataFromServer, ok := <-rpsDataChannel:
+			if !ok {
+				roundTrip.End(errors.New("the connection to RPS was lost"))
+				return requestLost
+			}
+			roundTrip.End(nil)
 			shallIReturn := e.HandleDataFromRPS(dataFromServer)
 			if shallIReturn { //quits the loop -- we're either done or reached a point where we need to stop
-				return
+				return requestDone
 			}
-		case <-interrupt:
+			roundTrip = telemetry.StartRPSRoundTrip()
+		case <-e.server.done():
+			roundTrip.End(errors.New("cancelled by user"))
 			e.HandleInterrupt()
-			return
+			return requestInterrupted
 		}
 	}
-
 }
 
+// HandleInterrupt tells RPS that the session was cancelled, so it can roll back the
+// request in flight, and closes the connection
 func (e Executor) HandleInterrupt() {
-	log.Info("interrupt")
-
-	// Cleanly close the connection by sending a close message and then
-	// waiting (with timeout) for the server to close the connection.
-	// err := e.localManagement.Close()
-	// if err != nil {
-	// 	log.Error("Connection close failed", err)
-	// 	return
-	// }
-
+	log.Warn("cancelled by user, ending the RPS session")
+	e.server.progress.Report(Progress{Phase: PhaseCancelled, Percent: e.server.progress.Last().Percent, Status: "cancelled by user"})
+	if err := e.send(e.payload.CreateMessageCancel()); err != nil {
+		log.Debug("sending the cancel message failed: ", err)
+	}
 	err := e.server.Close()
 	if err != nil {
 		log.Error("Connection close failed", err)
@@ -121,45 +157,14 @@ func (e Executor) HandleDataFromRPS(dataFromServer []byte) bool {
 		return false
 	}
 
-	// send channel open
-	err := e.localManagement.Connect()
-	go e.localManagement.Listen()
-
-	if err != nil {
-		log.Error(err)
-		return true
-	}
-	if e.isLME {
-		// wait for channel open confirmation
-		<-e.status
-		log.Trace("Channel open confirmation received")
-	} else {
-		//with LMS we open/close websocket on every request, so setup close for when we're done handling LMS data
-		defer e.localManagement.Close()
-	}
-
-	// send our data to LMX
-	err = e.localManagement.Send(msgPayload)
+	e.server.progress.Exchange()
+	dataFromLM, err := e.localManagement.Exchange(msgPayload)
 	if err != nil {
 		log.Error(err)
 		return true
 	}
-
-	for {
-		select {
-		case dataFromLM := <-e.data:
-			e.HandleDataFromLM(dataFromLM)
-			if e.isLME {
-				<-e.status
-			}
-			return false
-		case errFromLMS := <-e.errors:
-			if errFromLMS != nil {
-				log.Error("error from LMS")
-				return true
-			}
-		}
-	}
+	e.HandleDataFromLM(dataFromLM)
+	return false
 }
 
 func (e Executor) HandleDataFromLM(data []byte) {
@@ -167,9 +172,46 @@ func (e Executor) HandleDataFromLM(data []byte) {
 		log.Debug("received data from LMX")
 		log.Trace(string(data))
 
-		err := e.server.Send(e.payload.CreateMessageResponse(data))
+		err := e.send(e.payload.CreateMessageResponse(data))
 		if err != nil {
 			log.Error(err)
+			return
 		}
+		e.session.acknowledge(e.server.progress)
+	}
+}
+
+// send sends the message to RPS, after the log entries collected for -uploadLogs since the
+// last message
+func (e Executor) send(message Message) error {
+	e.uploadLogs()
+	return e.server.Send(message)
+}
+
+// uploadLogs sends the collected log entries to RPS. The upload is best effort, a failure
+// is logged at debug level and does not fail the command.
+func (e Executor) uploadLogs() {
+	if e.transcript == nil || e.server.Conn == nil {
+		return
+	}
+	entries, dropped := e.transcript.Take()
+	if len(entries) == 0 && dropped == 0 {
+		return
+	}
+	message, err := e.payload.CreateMessageLog(entries, dropped)
+	if err == nil {
+		err = e.server.Send(message)
+	}
+	if err != nil {
+		log.Debug("unable to upload the log entries: ", err)
+	}
+}
+
+// endTranscript uploads the entries logged after the last message and stops collecting them
+func (e Executor) endTranscript() {
+	if e.transcript == nil {
+		return
 	}
+	e.uploadLogs()
+	e.transcript.Stop()
 }
@@ -7,15 +7,14 @@ package rps
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"rpc/internal/amt"
 	"rpc/internal/flags"
-	"rpc/internal/local"
+	"rpc/internal/info"
+	"rpc/internal/logging"
 	"rpc/pkg/utils"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type Payload struct {
@@ -33,6 +32,9 @@ type Message struct {
 	Fqdn            string `json:"fqdn"`
 	Payload         string `json:"payload"`
 	TenantID        string `json:"tenantId"`
+	// Chunk numbers the part of a payload sent in Chunks messages, both are 0 for a payload sent whole
+	Chunk  int `json:"chunk,omitempty"`
+	Chunks int `json:"chunks,omitempty"`
 }
 
 // Status Message is used for displaying and parsing status messages from RPS
@@ -45,10 +47,12 @@ type StatusMessage struct {
 
 // MessagePayload struct is used for the initial request to RPS to activate or manage a device
 type MessagePayload struct {
-	Version           string                `json:"ver"`
-	Build             string                `json:"build"`
-	SKU               string                `json:"sku"`
-	Features          string                `json:"features"`
+	Version  string `json:"ver"`
+	Build    string `json:"build"`
+	SKU      string `json:"sku"`
+	Features string `json:"features"`
+	// SKUClass is amt, ism or none, RPS leaves out the features the SKU does not have
+	SKUClass          string                `json:"skuClass,omitempty"`
 	UUID              string                `json:"uuid"`
 	Username          string                `json:"username"`
 	Password          string                `json:"password"`
@@ -60,45 +64,56 @@ type MessagePayload struct {
 	IPConfiguration   flags.IPConfiguration `json:"ipConfiguration"`
 	HostnameInfo      flags.HostnameInfo    `json:"hostnameInfo"`
 	FriendlyName      string                `json:"friendlyName,omitempty"`
+	Hardware          *info.HardwareInfo    `json:"hardware,omitempty"`
+	Tags              map[string]string     `json:"tags,omitempty"`
+	// Reason is given with -reason for a deactivation
+	Reason string `json:"reason,omitempty"`
+	// Resume is set by activate -resume with the progress of an interrupted activation
+	Resume *ResumeState `json:"resume,omitempty"`
 }
 
-func NewPayload() Payload {
+// NewPayload returns the payload whose MEI commands use the context and timeout of the flags
+func NewPayload(flags *flags.Flags) Payload {
 	return Payload{
-		AMT: amt.NewAMTCommand(),
+		AMT: amt.NewAMTCommandContext(flags.Context, flags.Timeout),
 	}
 }
 
 // createPayload gathers data from ME to assemble required information for sending to the server
 func (p Payload) createPayload(dnsSuffix string, hostname string, amtTimeout time.Duration) (MessagePayload, error) {
 	payload := MessagePayload{}
-	var err error
 	wired, _ := p.AMT.GetLANInterfaceSettings(false)
 	if wired.LinkStatus != "up" {
 		log.Warn("link status is down, unable to activate AMT in Admin Control Mode (ACM)")
 	}
-	payload.Version, err = p.AMT.GetVersionDataFromME("AMT", amtTimeout)
-	if err != nil {
-		return payload, err
-	}
-	payload.Build, err = p.AMT.GetVersionDataFromME("Build Number", amtTimeout)
-	if err != nil {
-		return payload, err
+	// the queries share p.AMT so they run one after the other
+	collector := info.Collector{
+		NewAMTCommand: func() amt.Interface { return p.AMT },
+		Workers:       1,
 	}
-	payload.SKU, err = p.AMT.GetVersionDataFromME("Sku", amtTimeout)
-	if err != nil {
+	result := collector.Collect(info.InfoRequest{
+		Version:    true,
+		Build:      true,
+		SKU:        true,
+		UUID:       true,
+		Mode:       true,
+		DNS:        dnsSuffix == "",
+		Hostname:   hostname == "",
+		CertHashes: true,
+		Hardware:   true,
+		AMTTimeout: amtTimeout,
+	})
+	payload.Version = result.Version
+	payload.Build = result.BuildNumber
+	payload.SKU = result.SKU
+	payload.UUID = result.UUID
+	payload.CurrentMode = result.ControlMode
+	if err := result.Err(info.QueryVersion, info.QueryBuild, info.QuerySKU, info.QueryUUID, info.QueryMode); err != nil {
 		return payload, err
 	}
+	payload.Features = info.DecodeAMT(payload.Version, payload.SKU)
+	payload.SKUClass = info.DecodeAMTFeatures(payload.Version, payload.SKU).SKUClass
 
-	payload.Features = local.DecodeAMT(payload.Version, payload.SKU)
-
-	payload.UUID, err = p.AMT.GetUUID()
-	if err != nil {
-		return payload, err
-	}
-	payload.CurrentMode, err = p.AMT.GetControlMode()
-	if err != nil {
-		return payload, err
-	}
 	lsa, err := p.AMT.GetLocalSystemAccount()
 	if err != nil {
 		return payload, err
@@ -106,29 +121,31 @@ func (p Payload) createPayload(dnsSuffix string, hostname string, amtTimeout tim
 	payload.Username = lsa.Username
 	payload.Password = lsa.Password
 
-	if hostname != "" {
-		payload.Hostname = hostname
-	} else {
-		payload.Hostname, err = os.Hostname()
-		if err != nil {
-			return payload, err
-		}
-	}
-	payload.Client = utils.ClientName
-	hashes, err := p.AMT.GetCertificateHashes()
-	if err != nil {
+	if err := result.Err(info.QueryHostname, info.QueryCertHashes); err != nil {
 		return payload, err
 	}
-	for _, v := range hashes {
+	payload.Hostname = hostname
+	if hostname == "" {
+		payload.Hostname = result.HostnameOS
+	}
+	payload.Client = utils.ClientName
+	for _, v := range result.CertHashes {
 		payload.CertificateHashes = append(payload.CertificateHashes, v.Hash)
 	}
 
+	// the inventory is informational, a host without readable SMBIOS tables still activates
+	if err := result.Err(info.QueryHardware); err != nil {
+		log.Debug("unable to read the hardware inventory: ", err)
+	} else {
+		payload.Hardware = &result.Hardware
+	}
+
 	if dnsSuffix != "" {
 		payload.FQDN = dnsSuffix
 	} else {
-		payload.FQDN, _ = p.AMT.GetDNSSuffix()
+		payload.FQDN = result.DNSSuffix
 		if payload.FQDN == "" {
-			payload.FQDN, _ = p.AMT.GetOSDNSSuffix()
+			payload.FQDN = result.DNSSuffixOS
 		}
 		if payload.FQDN == "" {
 			log.Warn("DNS suffix is empty, unable to activate AMT in admin Control Mode (ACM)")
@@ -163,6 +180,9 @@ func (p Payload) CreateMessageRequest(flags flags.Flags) (Message, error) {
 
 	// Update with AMT password for activated devices
 	if payload.CurrentMode != 0 {
+		if flags.Password == "" && flags.NonInteractive {
+			return message, errors.New("the AMT password of the activated device is required, -nonInteractive does not prompt for it")
+		}
 		if flags.Password == "" {
 			for flags.Password == "" {
 				fmt.Println("Please enter AMT Password: ")
@@ -172,11 +192,15 @@ func (p Payload) CreateMessageRequest(flags flags.Flags) (Message, error) {
 					return message, err
 				}
 			}
+			logging.Redact(flags.Password)
 		}
 		payload.Password = flags.Password
 	}
 
 	payload.FriendlyName = flags.FriendlyName
+	payload.Tags = flags.Tags
+	payload.Reason = flags.Deactivate.Reason
+	payload.exclude(flags.SyncDeviceInfo)
 	//convert struct to json
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -188,6 +212,32 @@ func (p Payload) CreateMessageRequest(flags flags.Flags) (Message, error) {
 	return message, nil
 }
 
+// exclude leaves the fields of syncdeviceinfo -exclude out of the payload
+func (payload *MessagePayload) exclude(opts flags.SyncDeviceInfoFlags) {
+	if opts.Excludes(flags.DeviceInfoHostname) {
+		payload.Hostname = ""
+		payload.HostnameInfo = flags.HostnameInfo{}
+	}
+	if opts.Excludes(flags.DeviceInfoFQDN) {
+		payload.FQDN = ""
+	}
+	if opts.Excludes(flags.DeviceInfoIPAddress) {
+		payload.IPConfiguration = flags.IPConfiguration{}
+	}
+	if opts.Excludes(flags.DeviceInfoHardware) {
+		payload.Hardware = nil
+	}
+	if opts.Excludes(flags.DeviceInfoCertHashes) {
+		payload.CertificateHashes = nil
+	}
+	if opts.Excludes(flags.DeviceInfoFriendlyName) {
+		payload.FriendlyName = ""
+	}
+	if opts.Excludes(flags.DeviceInfoTags) {
+		payload.Tags = nil
+	}
+}
+
 // CreateMessageResponse is used for creating a response to the server
 func (p Payload) CreateMessageResponse(payload []byte) Message {
 	message := Message{
@@ -201,3 +251,43 @@ func (p Payload) CreateMessageResponse(payload []byte) Message {
 	}
 	return message
 }
+
+// LogPayload is the payload of a log message, the entries logged since the previous one
+type LogPayload struct {
+	Entries []logging.TranscriptEntry `json:"entries"`
+	// Dropped counts the entries left out because too many were logged in between
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// CreateMessageLog is used for uploading the log entries of rpc with -uploadLogs, the
+// server keeps them as the transcript of the session
+func (p Payload) CreateMessageLog(entries []logging.TranscriptEntry, dropped int) (Message, error) {
+	message := Message{
+		Method:          "log",
+		APIKey:          "key",
+		AppVersion:      utils.ProjectVersion,
+		ProtocolVersion: utils.ProtocolVersion,
+		Status:          "ok",
+		Message:         "ok",
+	}
+	data, err := json.Marshal(LogPayload{Entries: entries, Dropped: dropped})
+	if err != nil {
+		return message, err
+	}
+	message.Payload = base64.StdEncoding.EncodeToString(data)
+	return message, nil
+}
+
+// CreateMessageCancel is used for telling the server that rpc was interrupted and
+// ends the session before the request completed
+func (p Payload) CreateMessageCancel() Message {
+	message := Message{
+		Method:          "cancel",
+		APIKey:          "key",
+		AppVersion:      utils.ProjectVersion,
+		ProtocolVersion: utils.ProtocolVersion,
+		Status:          "cancelled",
+		Message:         "cancelled by user",
+	}
+	return message
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"rpc/internal/amt"
 	"rpc/internal/flags"
+	"rpc/internal/logging"
 	"rpc/pkg/utils"
 	"testing"
 	"time"
@@ -27,16 +28,20 @@ var controlMode int = 0
 var err error = nil
 var mode int = 0
 
-func (c MockAMT) Initialize() (utils.ReturnCode, error) {
-	return utils.Success, nil
+func (c MockAMT) Initialize() error {
+	return nil
 }
 func (c MockAMT) GetVersionDataFromME(key string, amtTimeout time.Duration) (string, error) {
 	return "Version", nil
 }
-func (c MockAMT) GetUUID() (string, error)        { return "123-456-789", nil }
-func (c MockAMT) GetUUIDV2() (string, error)      { return "", nil }
-func (c MockAMT) GetControlMode() (int, error)    { return controlMode, nil }
-func (c MockAMT) GetControlModeV2() (int, error)  { return controlMode, nil }
+func (c MockAMT) GetUUID() (string, error)           { return "123-456-789", nil }
+func (c MockAMT) GetUUIDV2() (string, error)         { return "", nil }
+func (c MockAMT) GetControlMode() (int, error)       { return controlMode, nil }
+func (c MockAMT) GetControlModeV2() (int, error)     { return controlMode, nil }
+func (c MockAMT) GetOSDNSServers() ([]string, error) { return []string{}, nil }
+func (c MockAMT) GetOperationalState() (amt.OperationalState, error) {
+	return amt.OperationalState{}, nil
+}
 func (c MockAMT) GetOSDNSSuffix() (string, error) { return osDNSSuffix, nil }
 func (c MockAMT) GetDNSSuffix() (string, error)   { return mebxDNSSuffix, nil }
 func (c MockAMT) GetCertificateHashes() ([]amt.CertHashEntry, error) {
@@ -54,6 +59,21 @@ func (c MockAMT) GetLocalSystemAccount() (amt.LocalSystemAccount, error) {
 func (c MockAMT) Unprovision() (int, error) {
 	return mode, nil
 }
+func (c MockAMT) SetDNSSuffix(suffix string) (int, error) {
+	return 0, nil
+}
+func (c MockAMT) GetChangeEnabled() (amt.ChangeEnabled, error) {
+	return amt.ChangeEnabled{AMTEnabled: true}, nil
+}
+func (c MockAMT) GetZeroTouchEnabled() (bool, error) {
+	return true, nil
+}
+func (c MockAMT) GetProvisioningTLSMode() (string, error) {
+	return "PKI", nil
+}
+func (c MockAMT) SetAMTEnabled(enabled bool) (int, error) {
+	return 0, nil
+}
 
 var p Payload
 
@@ -70,6 +90,7 @@ func TestCreatePayload(t *testing.T) {
 	assert.Equal(t, "Version", result.Version)
 	assert.Equal(t, "Version", result.Build)
 	assert.Equal(t, "Version", result.SKU)
+	assert.Empty(t, result.SKUClass, "the SKU class of a version that can not be decoded")
 	assert.Equal(t, "123-456-789", result.UUID)
 	assert.Equal(t, "Username", result.Username)
 	assert.Equal(t, "Password", result.Password)
@@ -101,6 +122,24 @@ func TestCreatePayloadWithNODNSSuffix(t *testing.T) {
 	assert.Equal(t, "", result.FQDN)
 }
 
+func TestCreatePayloadExclude(t *testing.T) {
+	payload := MessagePayload{
+		UUID:              "123-456-789",
+		Hostname:          "host",
+		FQDN:              "vprodemo.com",
+		CertificateHashes: []string{"hash"},
+		IPConfiguration:   flags.IPConfiguration{IpAddress: "192.168.1.7"},
+		Tags:              map[string]string{"site": "lab"},
+	}
+	payload.exclude(flags.SyncDeviceInfoFlags{Exclude: []string{flags.DeviceInfoHostname, flags.DeviceInfoIPAddress, flags.DeviceInfoTags}})
+	assert.Equal(t, "123-456-789", payload.UUID)
+	assert.Empty(t, payload.Hostname)
+	assert.Empty(t, payload.IPConfiguration)
+	assert.Nil(t, payload.Tags)
+	assert.Equal(t, "vprodemo.com", payload.FQDN)
+	assert.Equal(t, []string{"hash"}, payload.CertificateHashes)
+}
+
 func TestCreateActivationRequestNoDNSSuffixProvided(t *testing.T) {
 	flags := flags.Flags{
 		Command: "method",
@@ -190,6 +229,23 @@ func TestCreateActivationResponse(t *testing.T) {
 	assert.Equal(t, utils.ProjectVersion, result.AppVersion)
 }
 
+func TestCreateMessageLog(t *testing.T) {
+	entries := []logging.TranscriptEntry{{Level: "info", Module: logging.ModuleRPS, Message: "connected"}}
+	result, err := p.CreateMessageLog(entries, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "log", result.Method)
+	assert.Equal(t, "key", result.APIKey)
+	assert.Equal(t, "ok", result.Status)
+	assert.Equal(t, utils.ProtocolVersion, result.ProtocolVersion)
+	assert.Equal(t, utils.ProjectVersion, result.AppVersion)
+	data, err := base64.StdEncoding.DecodeString(result.Payload)
+	assert.NoError(t, err)
+	var payload LogPayload
+	assert.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, entries, payload.Entries)
+	assert.Equal(t, 2, payload.Dropped)
+}
+
 func TestCreateMessageRequestIPConfiguration(t *testing.T) {
 	flags := flags.Flags{
 		IpConfiguration: flags.IPConfiguration{
@@ -286,3 +342,18 @@ func TestCreateMessageRequestWithoutFriendlyName(t *testing.T) {
 	_, isInMap := m["friendlyName"]
 	assert.False(t, isInMap)
 }
+
+func TestCreateMessageRequestTags(t *testing.T) {
+	flags := flags.Flags{
+		TenantID: "tenant01",
+		Tags:     map[string]string{"site": "berlin"},
+	}
+	result, createErr := p.CreateMessageRequest(flags)
+	assert.NoError(t, createErr)
+	assert.Equal(t, "tenant01", result.TenantID)
+	decodedBytes, decodeErr := base64.StdEncoding.DecodeString(result.Payload)
+	assert.NoError(t, decodeErr)
+	msgPayload := MessagePayload{}
+	assert.NoError(t, json.Unmarshal(decodedBytes, &msgPayload))
+	assert.Equal(t, map[string]string{"site": "berlin"}, msgPayload.Tags)
+}
@@ -0,0 +1,207 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"time"
+)
+
+// profileCheckTimeout bounds each request to the RPS API before activating
+const profileCheckTimeout = 10 * time.Second
+
+// activation methods of RPS profiles
+const (
+	profileActivationACM = "acmactivate"
+	profileActivationCCM = "ccmactivate"
+)
+
+// errProfileNotFound is returned when RPS has no profile of the name
+var errProfileNotFound = errors.New("profile not found")
+
+// ProfileExpectations is what a profile of RPS expects of the device it activates
+type ProfileExpectations struct {
+	ProfileName string `json:"profileName"`
+	// Activation is acmactivate or ccmactivate
+	Activation string `json:"activation"`
+	// Domains are the provisioning certificates RPS has for ACM, read for ACM profiles only
+	Domains []ProfileDomain `json:"-"`
+}
+
+// ProfileDomain is a provisioning certificate of RPS and the DNS suffix it is issued for
+type ProfileDomain struct {
+	ProfileName    string    `json:"profileName"`
+	DomainSuffix   string    `json:"domainSuffix"`
+	ExpirationDate time.Time `json:"expirationDate"`
+}
+
+// profileCheck is the request of the expectations of a profile to the API of RPS
+type profileCheck struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// newProfileCheck returns the check using the API of RPS at -rpsAPI, with the proxy and
+// server certificate settings of the websocket connection
+func newProfileCheck(f *flags.Flags) (*profileCheck, error) {
+	server := NewAMTActivationServer(f)
+	proxy, err := server.proxy()
+	if err != nil {
+		return nil, err
+	}
+	return &profileCheck{
+		baseURL: strings.TrimSuffix(f.Activate.RPSAPI, "/"),
+		token:   f.Token,
+		client: &http.Client{
+			Timeout: profileCheckTimeout,
+			Transport: &http.Transport{
+				Proxy:           proxy,
+				TLSClientConfig: serverTLSConfig(f.SkipCertCheck, f.ServerTLS),
+			},
+		},
+	}, nil
+}
+
+// expectations reads the profile and, for ACM, the domains of RPS
+func (c *profileCheck) expectations(profile string) (ProfileExpectations, error) {
+	var expected ProfileExpectations
+	if err := c.get("/api/v1/admin/profiles/"+url.PathEscape(profile), profile, &expected); err != nil {
+		return expected, err
+	}
+	if expected.Activation != profileActivationACM {
+		return expected, nil
+	}
+	// RPS lists the domains as an array, or in data when the count is requested
+	var domains json.RawMessage
+	if err := c.get("/api/v1/admin/domains", "", &domains); err != nil {
+		return expected, err
+	}
+	if err := json.Unmarshal(domains, &expected.Domains); err != nil {
+		var page struct {
+			Data []ProfileDomain `json:"data"`
+		}
+		if err = json.Unmarshal(domains, &page); err != nil {
+			return expected, err
+		}
+		expected.Domains = page.Data
+	}
+	return expected, nil
+}
+
+// get reads the resource at path into v. A 404 of RPS saying that the profile is not found
+// is errProfileNotFound, any other 404 is an error like other failures.
+func (c *profileCheck) get(path string, profile string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNotFound && profile != "" && isProfileNotFound(rsp.Body, profile) {
+		return errProfileNotFound
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s answered %s", path, rsp.Status)
+	}
+	return json.NewDecoder(rsp.Body).Decode(v)
+}
+
+// isProfileNotFound tells the 404 of RPS for a missing profile, a JSON error naming the
+// profile, from the 404 of a gateway or server without the route, such as
+// {"message":"no Route matched with those values"} of Kong
+func isProfileNotFound(body io.Reader, profile string) bool {
+	var rsp struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(body).Decode(&rsp); err != nil {
+		return false
+	}
+	return strings.EqualFold(rsp.Error, "Not Found") && strings.Contains(rsp.Message, profile) &&
+		strings.Contains(strings.ToLower(rsp.Message), "not found")
+}
+
+// validate checks the device described by the payload against the expectations of the
+// profile, it returns the return code and the reason of the first mismatch
+func (expected ProfileExpectations) validate(device MessagePayload) (utils.ReturnCode, error) {
+	mode := map[string]int{profileActivationCCM: 1, profileActivationACM: 2}[expected.Activation]
+	if mode == 0 {
+		return utils.MissingOrIncorrectProfile, fmt.Errorf("profile %s has the unknown activation %q", expected.ProfileName, expected.Activation)
+	}
+	if device.CurrentMode != 0 && device.CurrentMode != mode {
+		return utils.UnableToActivate, fmt.Errorf("the device is %s, profile %s activates in %s",
+			utils.InterpretControlMode(device.CurrentMode), expected.ProfileName, strings.ToUpper(strings.TrimSuffix(expected.Activation, "activate")))
+	}
+	if mode != 2 {
+		return utils.Success, nil
+	}
+	if device.FQDN == "" {
+		return utils.MissingDNSSuffix, fmt.Errorf("profile %s activates in ACM, which needs the DNS suffix of the device, give it with -d", expected.ProfileName)
+	}
+	if len(device.CertificateHashes) == 0 {
+		return utils.CertHashNotFound, fmt.Errorf("profile %s activates in ACM, which needs a trusted root certificate hash in AMT", expected.ProfileName)
+	}
+	var suffixes []string
+	for _, domain := range expected.Domains {
+		if !strings.EqualFold(strings.TrimSuffix(domain.DomainSuffix, "."), strings.TrimSuffix(device.FQDN, ".")) {
+			suffixes = append(suffixes, domain.DomainSuffix)
+			continue
+		}
+		if !domain.ExpirationDate.IsZero() && domain.ExpirationDate.Before(time.Now()) {
+			return utils.InvalidProvisioningCert, fmt.Errorf("the provisioning certificate of RPS for %s expired on %s",
+				domain.DomainSuffix, domain.ExpirationDate.Format("2006-01-02"))
+		}
+		return utils.Success, nil
+	}
+	return utils.DNSSuffixMismatch, fmt.Errorf("the DNS suffix %s of the device matches no domain of RPS (%s), ACM activation with profile %s would fail",
+		device.FQDN, strings.Join(suffixes, ", "), expected.ProfileName)
+}
+
+// checkProfile reads the profile of the activation from the API of RPS at -rpsAPI and checks
+// the device against it, so a device the profile can not activate fails before the
+// activation starts. A server whose API can not be read, ex. without -token, is not checked.
+func checkProfile(f *flags.Flags, startMessage Message) utils.ReturnCode {
+	device, err := decodePayload(startMessage)
+	if err != nil {
+		log.Debug("profile check skipped: ", err)
+		return utils.Success
+	}
+	check, err := newProfileCheck(f)
+	if err != nil {
+		log.Debug("profile check skipped: ", err)
+		return utils.Success
+	}
+	expected, err := check.expectations(f.Profile)
+	if errors.Is(err, errProfileNotFound) {
+		log.Errorf("RPS has no profile %s at %s", f.Profile, f.Activate.RPSAPI)
+		return utils.MissingOrIncorrectProfile
+	}
+	if err != nil {
+		log.Infof("profile check skipped, the profile could not be read from RPS: %s", err)
+		return utils.Success
+	}
+	rc, err := expected.validate(device)
+	if rc != utils.Success {
+		log.Error(err)
+		return rc
+	}
+	log.Debugf("the device meets the expectations of profile %s", f.Profile)
+	return utils.Success
+}
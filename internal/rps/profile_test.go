@@ -0,0 +1,131 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// profileAPI serves the profiles and domains of RPS, to requests with the token when one is given
+func profileAPI(t *testing.T, token string, profiles map[string]string, domains string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/rps/api/v1/admin/domains" {
+			w.Write([]byte(domains))
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/rps/api/v1/admin/profiles/") {
+			// the 404 of Kong for a route it does not serve
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"no Route matched with those values"}`))
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/rps/api/v1/admin/profiles/")
+		profile, ok := profiles[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not Found","message":"Profile ` + name + ` Not Found"}`))
+			return
+		}
+		w.Write([]byte(profile))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func startMessageOf(t *testing.T, payload MessagePayload) Message {
+	data, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	return Message{Payload: base64.StdEncoding.EncodeToString(data)}
+}
+
+func TestCheckProfile(t *testing.T) {
+	profiles := map[string]string{
+		"acm": `{"profileName":"acm","activation":"acmactivate"}`,
+		"ccm": `{"profileName":"ccm","activation":"ccmactivate"}`,
+	}
+	domains := `[{"profileName":"corp","domainSuffix":"corp.example.com","expirationDate":"2099-01-01T00:00:00.000Z"}]`
+	server := profileAPI(t, "s3cr3t", profiles, domains)
+	device := MessagePayload{FQDN: "corp.example.com", CertificateHashes: []string{"abcd"}}
+
+	tests := map[string]struct {
+		profile string
+		token   string
+		api     string
+		device  func(*MessagePayload)
+		want    utils.ReturnCode
+	}{
+		"API route not served is not fatal": {profile: "nosuch", token: "s3cr3t", api: server.URL, want: utils.Success},
+		"ACM profile":                       {profile: "acm", token: "s3cr3t", want: utils.Success},
+		"CCM profile":                       {profile: "ccm", token: "s3cr3t", device: func(p *MessagePayload) { p.FQDN = "" }, want: utils.Success},
+		"unknown profile":                   {profile: "nosuch", token: "s3cr3t", want: utils.MissingOrIncorrectProfile},
+		"DNS suffix of no domain":           {profile: "acm", token: "s3cr3t", device: func(p *MessagePayload) { p.FQDN = "other.example.com" }, want: utils.DNSSuffixMismatch},
+		"activated in the other mode":       {profile: "acm", token: "s3cr3t", device: func(p *MessagePayload) { p.CurrentMode = 1 }, want: utils.UnableToActivate},
+		"API not readable is not fatal":     {profile: "nosuch", token: "wrong", want: utils.Success},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			payload := device
+			if tc.device != nil {
+				tc.device(&payload)
+			}
+			f := &flags.Flags{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/activate", Profile: tc.profile}
+			f.Token = tc.token
+			f.Activate.RPSAPI = server.URL + "/rps/"
+			if tc.api != "" {
+				f.Activate.RPSAPI = tc.api
+			}
+			assert.Equal(t, tc.want, checkProfile(f, startMessageOf(t, payload)))
+		})
+	}
+}
+
+func TestProfileExpectationsValidate(t *testing.T) {
+	device := MessagePayload{FQDN: "corp.example.com.", CertificateHashes: []string{"abcd"}}
+	expected := ProfileExpectations{ProfileName: "acm", Activation: profileActivationACM, Domains: []ProfileDomain{
+		{DomainSuffix: "Corp.Example.com", ExpirationDate: time.Now().Add(-time.Hour)},
+	}}
+	rc, err := expected.validate(device)
+	assert.Equal(t, utils.InvalidProvisioningCert, rc)
+	assert.ErrorContains(t, err, "expired")
+
+	device.CertificateHashes = nil
+	rc, _ = expected.validate(device)
+	assert.Equal(t, utils.CertHashNotFound, rc)
+
+	device.FQDN = ""
+	rc, err = expected.validate(device)
+	assert.Equal(t, utils.MissingDNSSuffix, rc)
+	assert.ErrorContains(t, err, "-d")
+
+	rc, _ = ProfileExpectations{Activation: "unknown"}.validate(device)
+	assert.Equal(t, utils.MissingOrIncorrectProfile, rc)
+}
+
+func TestProfileCheckDomainsPage(t *testing.T) {
+	server := profileAPI(t, "", map[string]string{"acm": `{"profileName":"acm","activation":"acmactivate"}`},
+		`{"data":[{"domainSuffix":"corp.example.com"}],"totalCount":1}`)
+	f := &flags.Flags{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/activate"}
+	f.Activate.RPSAPI = server.URL + "/rps"
+	check, err := newProfileCheck(f)
+	assert.NoError(t, err)
+	expected, err := check.expectations("acm")
+	assert.NoError(t, err)
+	assert.Equal(t, []ProfileDomain{{DomainSuffix: "corp.example.com"}}, expected.Domains)
+}
@@ -0,0 +1,96 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"rpc/internal/logging"
+	"strings"
+)
+
+const (
+	PhaseConnecting  = "connecting"
+	PhaseConnected   = "connected"
+	PhaseRequestSent = "requestSent"
+	PhaseExchanging  = "exchanging"
+	PhaseComplete    = "complete"
+	PhaseFailed      = "failed"
+	PhaseCancelled   = "cancelled"
+
+	progressBarWidth = 30
+)
+
+// Progress describes how far an activation or maintenance operation has come
+type Progress struct {
+	Phase   string `json:"phase"`
+	Percent int    `json:"percent"`
+	Status  string `json:"status,omitempty"`
+}
+
+// ProgressReporter logs progress as structured messages and, with -verbose-progress,
+// renders a progress bar
+type ProgressReporter struct {
+	out       io.Writer
+	render    bool
+	exchanges int
+	last      Progress
+}
+
+func NewProgressReporter(render bool) *ProgressReporter {
+	return &ProgressReporter{out: os.Stderr, render: render}
+}
+
+// Report logs the progress and redraws the progress bar
+func (p *ProgressReporter) Report(progress Progress) {
+	if p == nil {
+		return
+	}
+	entry := log.WithFields(logging.Fields{
+		"phase":   progress.Phase,
+		"percent": progress.Percent,
+	})
+	// repeated phases are logged at debug level to keep the output short
+	if progress.Phase != p.last.Phase && !p.render {
+		entry.Info("progress: ", progress.Status)
+	} else {
+		entry.Debug("progress: ", progress.Status)
+	}
+	p.last = progress
+	if !p.render {
+		return
+	}
+	filled := progressBarWidth * progress.Percent / 100
+	fmt.Fprintf(p.out, "\r[%s%s] %3d%% %s: %s\033[K",
+		strings.Repeat("#", filled),
+		strings.Repeat(" ", progressBarWidth-filled),
+		progress.Percent, progress.Phase, progress.Status)
+	if progress.Phase == PhaseComplete || progress.Phase == PhaseFailed || progress.Phase == PhaseCancelled {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// Exchange reports a message relayed between RPS and AMT. The number of messages
+// is not known up front, so the percent is estimated and stays below completion.
+func (p *ProgressReporter) Exchange() {
+	if p == nil {
+		return
+	}
+	p.exchanges++
+	percent := 10 + p.exchanges
+	if percent > 95 {
+		percent = 95
+	}
+	p.Report(Progress{Phase: PhaseExchanging, Percent: percent, Status: fmt.Sprintf("message %d", p.exchanges)})
+}
+
+// Last returns the most recently reported progress
+func (p *ProgressReporter) Last() Progress {
+	if p == nil {
+		return Progress{}
+	}
+	return p.last
+}
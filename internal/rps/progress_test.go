@@ -0,0 +1,47 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporterRender(t *testing.T) {
+	var out bytes.Buffer
+	p := &ProgressReporter{out: &out, render: true}
+	p.Report(Progress{Phase: PhaseConnected, Percent: 50, Status: "wss://rps"})
+	assert.Equal(t, "\r[###############               ]  50% connected: wss://rps\033[K", out.String())
+	out.Reset()
+	p.Report(Progress{Phase: PhaseComplete, Percent: 100, Status: "activated"})
+	assert.Equal(t, "\r[##############################] 100% complete: activated\033[K\n", out.String())
+}
+
+func TestProgressReporterQuiet(t *testing.T) {
+	var out bytes.Buffer
+	p := &ProgressReporter{out: &out}
+	p.Report(Progress{Phase: PhaseConnected, Percent: 5})
+	assert.Empty(t, out.String())
+	assert.Equal(t, PhaseConnected, p.Last().Phase)
+}
+
+func TestProgressReporterExchange(t *testing.T) {
+	p := &ProgressReporter{out: &bytes.Buffer{}}
+	p.Exchange()
+	assert.Equal(t, Progress{Phase: PhaseExchanging, Percent: 11, Status: "message 1"}, p.Last())
+	for i := 0; i < 200; i++ {
+		p.Exchange()
+	}
+	assert.Equal(t, 95, p.Last().Percent)
+}
+
+func TestProgressReporterNil(t *testing.T) {
+	var p *ProgressReporter
+	p.Report(Progress{Phase: PhaseConnected})
+	p.Exchange()
+	assert.Equal(t, Progress{}, p.Last())
+}
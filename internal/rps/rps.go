@@ -5,27 +5,64 @@
 package rps
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
 	"rpc/internal/flags"
+	"rpc/internal/logging"
 	"rpc/pkg/utils"
+	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
-	log "github.com/sirupsen/logrus"
+)
+
+var log = logging.For(logging.ModuleRPS)
+
+const (
+	maxRetryDelay  = 30 * time.Second
+	dryRunRedacted = "********"
+	closeTimeout   = time.Second
 )
 
 // AMTActivationServer struct represents the connection to RPS
 type AMTActivationServer struct {
-	URL   string
-	Conn  *websocket.Conn
-	flags *flags.Flags
+	// URL is the server connected to last, one of servers
+	URL      string
+	servers  []string
+	Conn     *websocket.Conn
+	flags    *flags.Flags
+	progress *ProgressReporter
 }
 
 func ExecuteCommand(flags *flags.Flags) utils.ReturnCode {
+	return ExecuteCommandTo(flags, os.Stdout)
+}
+
+// ExecuteCommandTo runs the command with RPS writing the device info or dry run to out
+func ExecuteCommandTo(flags *flags.Flags, out io.Writer) utils.ReturnCode {
 	rc := utils.Success
+	// the transcript starts before the device info is read, so the upload has all of it
+	if flags.UploadLogs && !flags.DryRun {
+		defer logging.StartTranscript().Stop()
+	}
+	if flags.Command == utils.CommandDeactivate && !flags.DryRun {
+		if rc = flags.ConfirmDeactivation(NewPayload(flags).AMT); rc != utils.Success {
+			return rc
+		}
+		log.WithField("reason", flags.Deactivate.Reason).Info("deactivating AMT through the server")
+	}
+	command := flags.Command
 	setCommandMethod(flags)
 
 	startMessage, err := PrepareInitialMessage(flags)
@@ -35,18 +72,67 @@ func ExecuteCommand(flags *flags.Flags) utils.ReturnCode {
 		return utils.MissingOrIncorrectPassword
 	}
 
+	if flags.SyncDeviceInfo.Show {
+		if err = writeDeviceInfo(out, startMessage, !flags.JsonOutput && !flags.YamlOutput); err != nil {
+			log.Error(err)
+			return utils.UnmarshalMessageFailed
+		}
+		return utils.Success
+	}
+
+	if flags.DryRun {
+		if err = writeDryRun(out, startMessage, flags.Secrets()); err != nil {
+			log.Error(err)
+			return utils.UnmarshalMessageFailed
+		}
+		return utils.DryRunCompleted
+	}
+
+	if command == utils.CommandActivate && flags.Activate.RPSAPI != "" {
+		if rc = checkProfile(flags, startMessage); rc != utils.Success {
+			return rc
+		}
+	}
+
+	if flags.SyncDeviceInfo.Continuous {
+		return syncDeviceInfoContinuously(flags, NewPayload(flags))
+	}
+
+	var session *Session
+	if command == utils.CommandActivate {
+		if session, rc = startSession(flags, &startMessage); rc != utils.Success {
+			return rc
+		}
+	}
+
 	executor, err := NewExecutor(*flags)
 	if err != nil {
 		log.Error(err)
+		if session.finish(Progress{}) {
+			return utils.ActivationInterrupted
+		}
 		// TODO: this error mapping is rather random?
 		return utils.ServerCerificateVerificationFailed
 	}
+	executor.session = session
 
 	executor.MakeItSo(startMessage)
+	if cancelled(flags) {
+		session.finish(executor.server.progress.Last())
+		return utils.CancelledByUser
+	}
+	if session.finish(executor.server.progress.Last()) {
+		return utils.ActivationInterrupted
+	}
 
 	return rc
 }
 
+// cancelled reports whether rpc was interrupted with SIGINT or SIGTERM
+func cancelled(flags *flags.Flags) bool {
+	return flags.Context != nil && flags.Context.Err() != nil
+}
+
 func setCommandMethod(flags *flags.Flags) {
 	switch flags.Command {
 	case utils.CommandActivate:
@@ -75,49 +161,259 @@ func setCommandMethod(flags *flags.Flags) {
 // TODO: suggest this be renamed to RemoteProvisioningService
 func NewAMTActivationServer(flags *flags.Flags) AMTActivationServer {
 	amtactivationserver := AMTActivationServer{
-		URL:   flags.URL,
-		flags: flags,
+		URL:      flags.URL,
+		flags:    flags,
+		progress: NewProgressReporter(flags.VerboseProgress),
+	}
+	if servers := flags.ServerURLs(); len(servers) > 0 {
+		amtactivationserver.URL = servers[0]
 	}
 	return amtactivationserver
 }
 func PrepareInitialMessage(flags *flags.Flags) (Message, error) {
-	payload := NewPayload()
+	payload := NewPayload(flags)
 	return payload.CreateMessageRequest(*flags)
 }
 
+// writeDryRun prints the message that would start the session with the
+// payload decoded and the secrets in it replaced
+func writeDryRun(w io.Writer, message Message, secrets []string) error {
+	payload, err := redactedPayload(message)
+	if err != nil {
+		return err
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			message.Method = strings.ReplaceAll(message.Method, secret, dryRunRedacted)
+		}
+	}
+	out, err := json.MarshalIndent(struct {
+		Message
+		Payload MessagePayload `json:"payload"`
+	}{message, payload}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Dry run, this message would be sent to the server:")
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// writeDeviceInfo prints the payload of syncdeviceinfo -show with the password replaced.
+// The header line is left out for -json and -yaml, so the output can be parsed.
+func writeDeviceInfo(w io.Writer, message Message, header bool) error {
+	payload, err := redactedPayload(message)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	if header {
+		fmt.Fprintln(w, "This device info would be sent to the server:")
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+// decodePayload decodes the base64 JSON payload of the message
+func decodePayload(message Message) (MessagePayload, error) {
+	var payload MessagePayload
+	data, err := base64.StdEncoding.DecodeString(message.Payload)
+	if err != nil {
+		return payload, err
+	}
+	err = json.Unmarshal(data, &payload)
+	return payload, err
+}
+
+// redactedPayload decodes the payload of the message and replaces the password in it
+func redactedPayload(message Message) (MessagePayload, error) {
+	payload, err := decodePayload(message)
+	if err != nil {
+		return payload, err
+	}
+	if payload.Password != "" {
+		payload.Password = dryRunRedacted
+	}
+	return payload, nil
+}
+
 // Connect is used to connect to the RPS Server
 func (amt *AMTActivationServer) Connect(skipCertCheck bool) error {
 	log.Info("connecting to ", amt.URL)
 	log.Info(amt.URL)
+	amt.progress.Report(Progress{Phase: PhaseConnecting, Percent: 0, Status: amt.URL})
 	var err error
 	websocketDialer := websocket.Dialer{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: skipCertCheck,
-		},
+		TLSClientConfig: serverTLSConfig(skipCertCheck, amt.flags.ServerTLS),
+		// messages are compressed only when the server agrees to permessage-deflate
+		EnableCompression: !amt.flags.NoCompression,
 	}
-	if amt.flags.Proxy != "" {
-		// Parse the URL of the proxy.
-		proxyURL, err := url.Parse(amt.flags.Proxy)
-		if err != nil {
-			panic(err)
-		}
-		websocketDialer.Proxy = func(*http.Request) (*url.URL, error) {
-			return proxyURL, nil
-		}
-	} else {
-		websocketDialer.Proxy = http.ProxyFromEnvironment
+	websocketDialer.Proxy, err = amt.proxy()
+	if err != nil {
+		return err
 	}
 	amt.Conn, _, err = websocketDialer.Dial(amt.URL, nil)
 	if err != nil {
 		return err
 	}
+	// RPS answers pings with pongs, which keeps idle sessions open through load balancers
+	amt.Conn.SetPongHandler(func(string) error {
+		log.Trace("pong received from RPS")
+		return nil
+	})
 	log.Info("connected to ", amt.URL)
+	amt.progress.Report(Progress{Phase: PhaseConnected, Percent: 5, Status: amt.URL})
 	return nil
 }
 
-// Close closes the connection to rps
+// proxy returns the proxy selection for the websocket connection. An explicit proxy
+// takes precedence over HTTPS_PROXY/NO_PROXY from the environment. Credentials are
+// sent with basic authentication on the CONNECT request.
+func (amt *AMTActivationServer) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if amt.flags.Proxy == "" {
+		return func(req *http.Request) (*url.URL, error) {
+			proxyURL, err := http.ProxyFromEnvironment(req)
+			if proxyURL != nil && amt.flags.ProxyUser != "" {
+				proxyURL.User = url.UserPassword(amt.flags.ProxyUser, amt.flags.ProxyPassword)
+			}
+			return proxyURL, err
+		}, nil
+	}
+	proxyURL, err := url.Parse(amt.flags.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	if amt.flags.ProxyUser != "" {
+		proxyURL.User = url.UserPassword(amt.flags.ProxyUser, amt.flags.ProxyPassword)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// serverTLSConfig verifies the server with the system roots and the -cacert CAs.
+// With -pin-sha256 the server must also present a pinned public key, this is checked
+// even when verification is skipped.
+func serverTLSConfig(skipCertCheck bool, settings flags.ServerTLSFlags) *tls.Config {
+	config := &tls.Config{
+		InsecureSkipVerify: skipCertCheck,
+		RootCAs:            settings.RootCAs,
+	}
+	if len(settings.Pins) > 0 {
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(pinCandidates(state), settings.Pins)
+		}
+	}
+	return config
+}
+
+// pinCandidates returns the certificates a pin may match. The certificates sent by the
+// server are not verified when verification is skipped, any of them could be appended by
+// an attacker, so only the leaf counts then. Otherwise the certificates of the verified
+// chains count.
+func pinCandidates(state tls.ConnectionState) []*x509.Certificate {
+	if len(state.VerifiedChains) == 0 {
+		if len(state.PeerCertificates) == 0 {
+			return nil
+		}
+		return state.PeerCertificates[:1]
+	}
+	var certs []*x509.Certificate
+	for _, chain := range state.VerifiedChains {
+		certs = append(certs, chain...)
+	}
+	return certs
+}
+
+// verifyPins checks that the SHA-256 hash of a certificate public key is one of the pins
+func verifyPins(certs []*x509.Certificate, pins [][]byte) error {
+	for _, cert := range certs {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(hash[:], pin) {
+				return nil
+			}
+		}
+	}
+	return errors.New("no certificate of the server matches -pin-sha256")
+}
+
+// ConnectWithRetry connects to the RPS Server, retrying failed attempts
+// with exponential backoff until the configured retries are exhausted
+func (amt *AMTActivationServer) ConnectWithRetry(skipCertCheck bool) error {
+	if skipCertCheck && len(amt.flags.ServerTLS.Pins) == 0 {
+		log.Warn("WARNING: server certificate verification is disabled, anyone on the network path can intercept the connection to RPS and the AMT credentials sent over it")
+	}
+	if amt.servers == nil {
+		amt.servers = orderServers(amt.flags)
+	}
+	for attempt := 0; ; attempt++ {
+		err := amt.connectAny(skipCertCheck)
+		if err == nil || attempt >= amt.flags.Retries {
+			return err
+		}
+		delay := backoffDelay(amt.flags.RetryDelay, attempt)
+		log.Warnf("connection to RPS failed: %s, retrying in %s (%d of %d)", err, delay, attempt+1, amt.flags.Retries)
+		select {
+		case <-time.After(delay):
+		case <-amt.done():
+			return err
+		}
+	}
+}
+
+// connectAny connects to the first server that accepts the session. That server is tried
+// first when rpc reconnects, it holds the state of the session.
+func (amt *AMTActivationServer) connectAny(skipCertCheck bool) error {
+	if len(amt.servers) == 0 {
+		return amt.Connect(skipCertCheck)
+	}
+	var err error
+	for i, server := range amt.servers {
+		amt.URL = server
+		if err = amt.Connect(skipCertCheck); err == nil {
+			amt.servers = append([]string{server}, append(amt.servers[:i:i], amt.servers[i+1:]...)...)
+			return nil
+		}
+		if len(amt.servers) > 1 {
+			log.Warnf("connection to %s failed: %s", server, err)
+		}
+	}
+	return err
+}
+
+// done returns the channel closed when rpc is interrupted, a nil channel blocks forever
+func (amt *AMTActivationServer) done() <-chan struct{} {
+	if amt.flags.Context == nil {
+		return nil
+	}
+	return amt.flags.Context.Done()
+}
+
+// backoffDelay doubles the base delay for each attempt, up to maxRetryDelay,
+// and picks a random delay between half and all of it so clients spread out
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// Close sends a websocket close frame and closes the connection to rps
 func (amt *AMTActivationServer) Close() error {
 	log.Info("closed RPS connection")
+	// RPS may have closed the connection already, so the close frame is best effort
+	_ = amt.Conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(closeTimeout))
 	err := amt.Conn.Close()
 	if err != nil {
 		return err
@@ -125,18 +421,20 @@ func (amt *AMTActivationServer) Close() error {
 	return nil
 }
 
-// Send is used for sending data to the RPS Server
+// Send is used for sending data to the RPS Server, a payload larger than -chunksize is sent in chunks
 func (amt *AMTActivationServer) Send(data Message) error {
-	dataToSend, err := json.Marshal(data)
-	if err != nil {
-		log.Error("unable to marshal activationResponse to JSON")
-		return err
-	}
-	log.Debug("sending message to RPS")
+	for _, message := range splitMessage(data, amt.flags.ChunkSize) {
+		dataToSend, err := json.Marshal(message)
+		if err != nil {
+			log.Error("unable to marshal activationResponse to JSON")
+			return err
+		}
+		log.Debug("sending message to RPS")
 
-	err = amt.Conn.WriteMessage(websocket.TextMessage, dataToSend)
-	if err != nil {
-		return err
+		err = amt.Conn.WriteMessage(websocket.TextMessage, dataToSend)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -145,21 +443,53 @@ func (amt *AMTActivationServer) Send(data Message) error {
 func (amt *AMTActivationServer) Listen() chan []byte {
 	log.Debug("listening to RPS...")
 	dataChannel := make(chan []byte)
+	done := make(chan struct{})
+	if amt.flags.HeartbeatInterval > 0 {
+		go amt.heartbeat(amt.flags.HeartbeatInterval, done)
+	}
 
 	go func() {
 		defer close(dataChannel)
+		defer close(done)
+		var chunks chunkAssembler
 		for {
 			_, message, err := amt.Conn.ReadMessage()
 			if err != nil {
 				log.Error("error:", err)
 				break
 			}
-			dataChannel <- message
+			message, err = chunks.add(message)
+			if err != nil {
+				log.Error("dropping chunked message from RPS: ", err)
+				continue
+			}
+			if message != nil {
+				dataChannel <- message
+			}
 		}
 	}()
 	return dataChannel
 }
 
+// heartbeat sends a websocket ping every interval until done is closed
+func (amt *AMTActivationServer) heartbeat(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			log.Trace("sending ping to RPS")
+			err := amt.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+			if err != nil {
+				log.Debug("ping to RPS failed: ", err)
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
 // ProcessMessage inspects RPS messages, decodes the base64 payload from the server and relays it to LMS
 func (amt *AMTActivationServer) ProcessMessage(message []byte) []byte {
 	log.Debug("received messages from RPS")
@@ -186,6 +516,7 @@ func (amt *AMTActivationServer) ProcessMessage(message []byte) []byte {
 			log.Info("CIRA: " + statusMessage.CIRAConnection)
 			log.Info("TLS: " + statusMessage.TLSConfiguration)
 		}
+		amt.progress.Report(Progress{Phase: PhaseComplete, Percent: 100, Status: statusMessage.Status})
 		return nil
 	} else if activation.Method == "error" {
 		err := json.Unmarshal([]byte(activation.Message), &statusMessage)
@@ -194,6 +525,7 @@ func (amt *AMTActivationServer) ProcessMessage(message []byte) []byte {
 		} else {
 			log.Error(activation.Message)
 		}
+		amt.progress.Report(Progress{Phase: PhaseFailed, Percent: amt.progress.Last().Percent, Status: statusMessage.Status})
 		return nil
 	}
 	msgPayload, err := base64.StdEncoding.DecodeString(activation.Payload)
@@ -5,6 +5,14 @@
 package rps
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"rpc/internal/flags"
@@ -12,6 +20,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
@@ -136,12 +145,201 @@ func TestPrepareInitialMessage(t *testing.T) {
 	assert.NotEqual(t, payload, payload1)
 }
 
+func TestWriteDryRun(t *testing.T) {
+	payload, err := json.Marshal(MessagePayload{UUID: "123-456-789", Password: "P@ssw0rd", CurrentMode: 1})
+	assert.Nil(t, err)
+	message := Message{
+		Method:  utils.CommandDeactivate + " --password P@ssw0rd",
+		Payload: base64.StdEncoding.EncodeToString(payload),
+	}
+	var out bytes.Buffer
+	err = writeDryRun(&out, message, []string{"P@ssw0rd", ""})
+	assert.Nil(t, err)
+	assert.NotContains(t, out.String(), "P@ssw0rd")
+	assert.Contains(t, out.String(), `"method": "deactivate --password ********"`)
+	assert.Contains(t, out.String(), `"uuid": "123-456-789"`)
+
+	message.Payload = "not base64"
+	assert.NotNil(t, writeDryRun(&out, message, nil))
+}
+
+func TestWriteDeviceInfo(t *testing.T) {
+	payload, err := json.Marshal(MessagePayload{UUID: "123-456-789", Password: "P@ssw0rd", Hostname: "host"})
+	assert.Nil(t, err)
+	var out bytes.Buffer
+	message := Message{Method: "maintenance -password P@ssw0rd --syncdeviceinfo", Payload: base64.StdEncoding.EncodeToString(payload)}
+	err = writeDeviceInfo(&out, message, true)
+	assert.Nil(t, err)
+	assert.NotContains(t, out.String(), "P@ssw0rd")
+	assert.NotContains(t, out.String(), `"method"`)
+	assert.Contains(t, out.String(), `"hostname": "host"`)
+
+	// -json and -yaml print the payload alone
+	out.Reset()
+	assert.Nil(t, writeDeviceInfo(&out, message, false))
+	var shown MessagePayload
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &shown))
+	assert.Equal(t, "host", shown.Hostname)
+	assert.NotContains(t, out.String(), "P@ssw0rd")
+}
+
 func TestConnect(t *testing.T) {
 	server := NewAMTActivationServer(testFlags)
 	err := server.Connect(true)
 	defer server.Close()
 	assert.NoError(t, err)
 }
+func TestConnectWithRetry(t *testing.T) {
+	t.Run("connects on first attempt", func(t *testing.T) {
+		server := NewAMTActivationServer(testFlags)
+		err := server.ConnectWithRetry(true)
+		defer server.Close()
+		assert.NoError(t, err)
+	})
+	t.Run("returns error after retries are exhausted", func(t *testing.T) {
+		f := &flags.Flags{URL: "ws://localhost:0", Retries: 2, RetryDelay: time.Millisecond}
+		server := NewAMTActivationServer(f)
+		start := time.Now()
+		err := server.ConnectWithRetry(true)
+		assert.Error(t, err)
+		// two retries wait at least half of 1ms and 2ms
+		assert.GreaterOrEqual(t, time.Since(start), 1500*time.Microsecond)
+	})
+}
+
+func TestConnectServerTLS(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(echo))
+	defer tlsServer.Close()
+	serverCert := tlsServer.Certificate()
+	pin := sha256.Sum256(serverCert.RawSubjectPublicKeyInfo)
+	wssURL := "wss" + strings.TrimPrefix(tlsServer.URL, "https")
+
+	t.Run("fails on unknown CA", func(t *testing.T) {
+		server := NewAMTActivationServer(&flags.Flags{URL: wssURL})
+		err := server.Connect(false)
+		assert.Error(t, err)
+	})
+	t.Run("connects with -cacert", func(t *testing.T) {
+		f := &flags.Flags{URL: wssURL}
+		f.ServerTLS.RootCAs = x509.NewCertPool()
+		f.ServerTLS.RootCAs.AddCert(serverCert)
+		server := NewAMTActivationServer(f)
+		err := server.Connect(false)
+		assert.NoError(t, err)
+		defer server.Close()
+	})
+	t.Run("connects with matching pin when verification is skipped", func(t *testing.T) {
+		f := &flags.Flags{URL: wssURL}
+		f.ServerTLS.Pins = [][]byte{pin[:]}
+		server := NewAMTActivationServer(f)
+		err := server.Connect(true)
+		assert.NoError(t, err)
+		defer server.Close()
+	})
+	t.Run("fails when no pin matches", func(t *testing.T) {
+		f := &flags.Flags{URL: wssURL}
+		f.ServerTLS.Pins = [][]byte{make([]byte, sha256.Size)}
+		server := NewAMTActivationServer(f)
+		err := server.Connect(true)
+		assert.ErrorContains(t, err, "-pin-sha256")
+	})
+}
+
+func TestPinCandidates(t *testing.T) {
+	leaf := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("leaf")}
+	pinned := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("pinned")}
+	root := &x509.Certificate{RawSubjectPublicKeyInfo: []byte("root")}
+	pin := sha256.Sum256(pinned.RawSubjectPublicKeyInfo)
+	config := serverTLSConfig(true, flags.ServerTLSFlags{Pins: [][]byte{pin[:]}})
+
+	t.Run("only the leaf counts when the chain is not verified", func(t *testing.T) {
+		err := config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf, pinned}})
+		assert.ErrorContains(t, err, "-pin-sha256")
+		err = config.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{pinned, leaf}})
+		assert.NoError(t, err)
+	})
+	t.Run("only the verified chains count", func(t *testing.T) {
+		err := config.VerifyConnection(tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf, pinned},
+			VerifiedChains:   [][]*x509.Certificate{{leaf, root}},
+		})
+		assert.ErrorContains(t, err, "-pin-sha256")
+		err = config.VerifyConnection(tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf},
+			VerifiedChains:   [][]*x509.Certificate{{leaf, pinned, root}},
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), backoffDelay(0, 3))
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		got := backoffDelay(time.Second, attempt)
+		assert.GreaterOrEqual(t, got, want/2)
+		assert.LessOrEqual(t, got, want)
+	}
+	got := backoffDelay(time.Second, 100)
+	assert.GreaterOrEqual(t, got, maxRetryDelay/2)
+	assert.LessOrEqual(t, got, maxRetryDelay)
+}
+
+func TestProxy(t *testing.T) {
+	t.Run("uses explicit proxy with credentials", func(t *testing.T) {
+		f := &flags.Flags{Proxy: "http://proxy.example.com:3128", ProxyUser: "user", ProxyPassword: "P@ssw0rd"}
+		server := NewAMTActivationServer(f)
+		proxyFunc, err := server.proxy()
+		assert.NoError(t, err)
+		req, _ := http.NewRequest("GET", "https://rps.example.com/activate", nil)
+		proxyURL, err := proxyFunc(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+		password, _ := proxyURL.User.Password()
+		assert.Equal(t, "user", proxyURL.User.Username())
+		assert.Equal(t, "P@ssw0rd", password)
+	})
+	t.Run("returns error on bad proxy url", func(t *testing.T) {
+		f := &flags.Flags{Proxy: "http://bad host:3128"}
+		server := NewAMTActivationServer(f)
+		_, err := server.proxy()
+		assert.Error(t, err)
+	})
+	t.Run("connects through proxy with basic auth", func(t *testing.T) {
+		var gotAuth string
+		proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Proxy-Authorization")
+			if r.Method != http.MethodConnect {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			upstream, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			client, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				upstream.Close()
+				return
+			}
+			go func() {
+				defer upstream.Close()
+				defer client.Close()
+				go io.Copy(upstream, client)
+				io.Copy(client, upstream)
+			}()
+		}))
+		defer proxyServer.Close()
+		f := &flags.Flags{URL: testUrl, Proxy: proxyServer.URL, ProxyUser: "user", ProxyPassword: "pass"}
+		server := NewAMTActivationServer(f)
+		err := server.Connect(true)
+		assert.NoError(t, err)
+		defer server.Close()
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")), gotAuth)
+	})
+}
+
 func TestSend(t *testing.T) {
 	server := NewAMTActivationServer(testFlags)
 	err := server.Connect(true)
@@ -174,6 +372,39 @@ func TestListen(t *testing.T) {
 	server.Send(message)
 	wgAll.Wait()
 }
+func TestListenHeartbeat(t *testing.T) {
+	pings := make(chan struct{}, 10)
+	pingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		c.SetPingHandler(func(data string) error {
+			pings <- struct{}{}
+			return c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				break
+			}
+		}
+	}))
+	defer pingServer.Close()
+	f := flags.NewFlags([]string{})
+	f.URL = "ws" + strings.TrimPrefix(pingServer.URL, "http")
+	f.HeartbeatInterval = 10 * time.Millisecond
+	server := NewAMTActivationServer(f)
+	err := server.Connect(true)
+	assert.NoError(t, err)
+	defer server.Close()
+	server.Listen()
+	select {
+	case <-pings:
+	case <-time.After(2 * time.Second):
+		t.Error("no ping received")
+	}
+}
 func TestProcessMessageHeartbeat(t *testing.T) {
 	activation := `{
         "method": "heartbeat_request"
@@ -192,6 +423,7 @@ func TestProcessMessageSuccess(t *testing.T) {
 	server.Connect(true)
 	decodedMessage := server.ProcessMessage([]byte(activation))
 	assert.Nil(t, decodedMessage)
+	assert.Equal(t, Progress{Phase: PhaseComplete, Percent: 100, Status: "ok"}, server.progress.Last())
 }
 func TestProcessMessageUnformattedSuccess(t *testing.T) {
 	activation := `{
@@ -212,6 +444,7 @@ func TestProcessMessageError(t *testing.T) {
 	server.Connect(true)
 	decodedMessage := server.ProcessMessage([]byte(activation))
 	assert.Nil(t, decodedMessage)
+	assert.Equal(t, PhaseFailed, server.progress.Last().Phase)
 }
 func TestProcessMessageForLMS(t *testing.T) {
 	activation := `{
@@ -0,0 +1,101 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"net"
+	"net/url"
+	"rpc/internal/flags"
+	"sort"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds the TCP connection that measures the latency of a server
+const probeTimeout = 2 * time.Second
+
+// probeLatency returns how long a TCP connection to the address takes, it is replaced in tests
+var probeLatency = func(address string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, probeTimeout)
+	if err != nil {
+		return 0, err
+	}
+	conn.Close()
+	return time.Since(start), nil
+}
+
+// orderServers returns the servers in the order they are tried, by latency with
+// -serverOrder latency. Probing from the host says nothing about a path through a proxy,
+// so the listed order is kept then.
+func orderServers(f *flags.Flags) []string {
+	servers := f.ServerURLs()
+	if f.ServerOrder != flags.ServerOrderLatency || len(servers) < 2 {
+		return servers
+	}
+	if f.Proxy != "" {
+		log.Warn("the servers are tried in the listed order, their latency is not probed through the proxy")
+		return servers
+	}
+	return byLatency(servers)
+}
+
+// byLatency sorts the servers by the time a TCP connection takes. Servers that can not be
+// reached follow in the listed order, the websocket connection may still succeed.
+func byLatency(servers []string) []string {
+	latencies := make([]time.Duration, len(servers))
+	var wg sync.WaitGroup
+	for i, server := range servers {
+		wg.Add(1)
+		go func(i int, server string) {
+			defer wg.Done()
+			latencies[i] = -1
+			address, err := serverAddress(server)
+			if err != nil {
+				return
+			}
+			latency, err := probeLatency(address)
+			if err != nil {
+				log.Debugf("probing %s failed: %s", server, err)
+				return
+			}
+			log.Debugf("latency of %s is %s", server, latency)
+			latencies[i] = latency
+		}(i, server)
+	}
+	wg.Wait()
+	order := make([]int, len(servers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		la, lb := latencies[order[a]], latencies[order[b]]
+		if la < 0 || lb < 0 {
+			return lb < 0 && la >= 0
+		}
+		return la < lb
+	})
+	sorted := make([]string, len(servers))
+	for i, index := range order {
+		sorted[i] = servers[index]
+	}
+	return sorted
+}
+
+// serverAddress returns the host and port of a websocket URL
+func serverAddress(server string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "ws" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
@@ -0,0 +1,47 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"errors"
+	"rpc/internal/flags"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectFailover(t *testing.T) {
+	f := &flags.Flags{URL: "ws://localhost:0," + testUrl}
+	server := NewAMTActivationServer(f)
+	assert.Equal(t, "ws://localhost:0", server.URL)
+	assert.NoError(t, server.ConnectWithRetry(true))
+	defer server.Close()
+	assert.Equal(t, testUrl, server.URL)
+	// a reconnect starts with the server that accepted the session
+	assert.Equal(t, []string{testUrl, "ws://localhost:0"}, server.servers)
+}
+
+func TestOrderServers(t *testing.T) {
+	orig := probeLatency
+	defer func() { probeLatency = orig }()
+	latencies := map[string]time.Duration{"slow:443": 50 * time.Millisecond, "fast:80": time.Millisecond}
+	probeLatency = func(address string) (time.Duration, error) {
+		if latency, ok := latencies[address]; ok {
+			return latency, nil
+		}
+		return 0, errors.New("connection refused")
+	}
+	servers := "wss://down/activate,wss://slow/activate,ws://fast/activate"
+
+	f := &flags.Flags{URL: servers}
+	assert.Equal(t, []string{"wss://down/activate", "wss://slow/activate", "ws://fast/activate"}, orderServers(f))
+
+	f.ServerOrder = flags.ServerOrderLatency
+	assert.Equal(t, []string{"ws://fast/activate", "wss://slow/activate", "wss://down/activate"}, orderServers(f))
+
+	f.Proxy = "http://proxy:3128"
+	assert.Equal(t, []string{"wss://down/activate", "wss://slow/activate", "ws://fast/activate"}, orderServers(f))
+}
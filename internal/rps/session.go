@@ -0,0 +1,207 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"time"
+)
+
+// sessionFileName is the file in the rpc folder of the user cache directory the state of an
+// RPS activation is saved to
+const sessionFileName = "activation.json"
+
+// Session is the state of an RPS activation. It is saved while the activation runs, so
+// activate -resume can tell the server how far an activation whose connection was lost came.
+// RPS does not continue an activation, it runs it again from the start.
+type Session struct {
+	UUID     string    `json:"uuid"`
+	URL      string    `json:"url"`
+	Profile  string    `json:"profile"`
+	TenantID string    `json:"tenantId,omitempty"`
+	Started  time.Time `json:"started"`
+	Updated  time.Time `json:"updated"`
+	// Phase and Exchanges are the progress of the last message RPS received from rpc
+	Phase     string `json:"phase"`
+	Exchanges int    `json:"exchanges"`
+	// Resumed counts the times the activation was continued with -resume
+	Resumed int `json:"resumed,omitempty"`
+	path    string
+	// resumedAt is the number of Exchanges when the activation was resumed
+	resumedAt int
+}
+
+// ResumeState tells RPS how far the interrupted activation came before it was resumed
+type ResumeState struct {
+	Started   time.Time `json:"started"`
+	Phase     string    `json:"phase"`
+	Exchanges int       `json:"exchanges"`
+	Resumed   int       `json:"resumed"`
+}
+
+// sessionPath returns the -sessionFile of the flags or the session file in the user cache directory
+func sessionPath(flags *flags.Flags) (string, error) {
+	if flags.Activate.SessionFile != "" {
+		return flags.Activate.SessionFile, nil
+	}
+	dir, err := utils.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionFileName), nil
+}
+
+// loadSession reads the saved session, it is nil when no activation was interrupted
+func loadSession(path string) (*Session, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{path: path}
+	if err = json.Unmarshal(content, session); err != nil {
+		return nil, fmt.Errorf("%s is not an activation session: %w", path, err)
+	}
+	return session, nil
+}
+
+// startSession returns the session the activation of the start message is saved to. With
+// -resume the start message tells the server where the interrupted activation of the device
+// stopped, without it a session left by an earlier activation is replaced.
+func startSession(flags *flags.Flags, message *Message) (*Session, utils.ReturnCode) {
+	path, err := sessionPath(flags)
+	if err != nil {
+		if flags.Activate.Resume {
+			log.Error("unable to find the activation session: ", err)
+			return nil, utils.NoActivationToResume
+		}
+		log.Warn("the activation is not saved, it can not be resumed: ", err)
+		return nil, utils.Success
+	}
+	payload, err := decodePayload(*message)
+	if err != nil {
+		log.Error(err)
+		return nil, utils.UnmarshalMessageFailed
+	}
+	saved, err := loadSession(path)
+	if err != nil {
+		if flags.Activate.Resume {
+			log.Error(err)
+			return nil, utils.FailedReadingConfiguration
+		}
+		log.Warn("removing the activation session: ", err)
+		removeSession(path)
+		saved = nil
+	}
+	if saved != nil && saved.UUID != payload.UUID {
+		if flags.Activate.Resume {
+			log.Errorf("the interrupted activation in %s is of device %s, not of this device", path, saved.UUID)
+			return nil, utils.NoActivationToResume
+		}
+		log.Warnf("removing the interrupted activation of device %s in %s", saved.UUID, path)
+		removeSession(path)
+		saved = nil
+	}
+	if saved != nil && !flags.Activate.Resume {
+		log.Warnf("the activation of this device with profile %s was interrupted at %s after %d messages, starting it over",
+			saved.Profile, saved.Updated.Format(time.RFC3339), saved.Exchanges)
+		removeSession(path)
+		saved = nil
+	}
+	if saved == nil {
+		if flags.Activate.Resume {
+			log.Errorf("no interrupted activation of this device in %s", path)
+			return nil, utils.NoActivationToResume
+		}
+		return &Session{
+			UUID:     payload.UUID,
+			URL:      flags.URL,
+			Profile:  flags.Profile,
+			TenantID: flags.TenantID,
+			Started:  time.Now(),
+			path:     path,
+		}, utils.Success
+	}
+	if saved.Profile != flags.Profile {
+		log.Errorf("the interrupted activation used profile %s, not %s", saved.Profile, flags.Profile)
+		return nil, utils.MissingOrIncorrectProfile
+	}
+	if saved.URL != flags.URL {
+		log.Warnf("the interrupted activation was started with %s, continuing it with %s", saved.URL, flags.URL)
+	}
+	saved.Resumed++
+	saved.resumedAt = saved.Exchanges
+	payload.Resume = &ResumeState{Started: saved.Started, Phase: saved.Phase, Exchanges: saved.Exchanges, Resumed: saved.Resumed}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Error(err)
+		return nil, utils.UnmarshalMessageFailed
+	}
+	message.Payload = base64.StdEncoding.EncodeToString(data)
+	log.Infof("sending the progress of the activation started at %s after %d messages to the server", saved.Started.Format(time.RFC3339), saved.Exchanges)
+	return saved, utils.Success
+}
+
+// save writes the session, the rpc folder is created when it is missing
+func (s *Session) save() error {
+	s.Updated = time.Now()
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, append(content, '\n'), 0600)
+}
+
+// acknowledge saves the progress once a message was sent to RPS
+func (s *Session) acknowledge(progress *ProgressReporter) {
+	if s == nil {
+		return
+	}
+	s.Phase = progress.Last().Phase
+	s.Exchanges = s.resumedAt + progress.exchanges
+	if err := s.save(); err != nil {
+		log.Warn("unable to save the activation session: ", err)
+	}
+}
+
+// finish removes the session once RPS reported the activation complete or failed, or rpc
+// cancelled it. The session of an activation whose connection was lost is kept for -resume,
+// finish reports whether it was kept.
+func (s *Session) finish(last Progress) bool {
+	if s == nil {
+		return false
+	}
+	switch last.Phase {
+	case PhaseComplete, PhaseFailed, PhaseCancelled:
+		removeSession(s.path)
+		return false
+	}
+	// nothing was sent when the connection failed before the request
+	if s.Phase == "" {
+		return false
+	}
+	log.Errorf("the activation was interrupted after %d messages, run activate again to start it over", s.Exchanges)
+	return true
+}
+
+// removeSession deletes the session file, a file that does not exist is not an error
+func removeSession(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn("unable to remove the activation session: ", err)
+	}
+}
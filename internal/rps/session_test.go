@@ -0,0 +1,130 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rps
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sessionTestUUID = "123e4567-e89b-12d3-a456-426614174000"
+
+func sessionTestMessage(t *testing.T, uuid string) Message {
+	data, err := json.Marshal(MessagePayload{UUID: uuid})
+	assert.NoError(t, err)
+	return Message{Method: "activate --profile profile01", Payload: base64.StdEncoding.EncodeToString(data)}
+}
+
+func sessionTestFlags(path string, resume bool) *flags.Flags {
+	f := &flags.Flags{URL: "wss://rps/activate", Profile: "profile01"}
+	f.Activate.SessionFile = path
+	f.Activate.Resume = resume
+	return f
+}
+
+func TestStartSession(t *testing.T) {
+	t.Run("starts a session that is saved once the request is sent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rpc", sessionFileName)
+		message := sessionTestMessage(t, sessionTestUUID)
+		session, rc := startSession(sessionTestFlags(path, false), &message)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, sessionTestUUID, session.UUID)
+		assert.NoFileExists(t, path)
+
+		progress := &ProgressReporter{}
+		progress.Report(Progress{Phase: PhaseRequestSent})
+		session.acknowledge(progress)
+		progress.Exchange()
+		progress.Exchange()
+		session.acknowledge(progress)
+		saved, err := loadSession(path)
+		assert.NoError(t, err)
+		assert.Equal(t, PhaseExchanging, saved.Phase)
+		assert.Equal(t, 2, saved.Exchanges)
+		assert.Equal(t, "profile01", saved.Profile)
+
+		// the connection was lost, the session is kept
+		assert.True(t, session.finish(progress.Last()))
+		assert.FileExists(t, path)
+	})
+	t.Run("starts an interrupted activation over without -resume", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), sessionFileName)
+		saveTestSession(t, path, sessionTestUUID)
+		message := sessionTestMessage(t, sessionTestUUID)
+		session, rc := startSession(sessionTestFlags(path, false), &message)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, 0, session.Exchanges)
+		assert.NoFileExists(t, path)
+		payload, err := decodePayload(message)
+		assert.NoError(t, err)
+		assert.Nil(t, payload.Resume)
+	})
+	t.Run("resumes an interrupted activation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), sessionFileName)
+		saveTestSession(t, path, sessionTestUUID)
+		message := sessionTestMessage(t, sessionTestUUID)
+		session, rc := startSession(sessionTestFlags(path, true), &message)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, 1, session.Resumed)
+		payload, err := decodePayload(message)
+		assert.NoError(t, err)
+		assert.Equal(t, &ResumeState{Started: session.Started, Phase: PhaseExchanging, Exchanges: 7, Resumed: 1}, payload.Resume)
+
+		progress := &ProgressReporter{}
+		progress.Exchange()
+		session.acknowledge(progress)
+		assert.Equal(t, 8, session.Exchanges)
+		assert.False(t, session.finish(Progress{Phase: PhaseComplete}))
+		assert.NoFileExists(t, path)
+	})
+	t.Run("resumes only the same profile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), sessionFileName)
+		saveTestSession(t, path, sessionTestUUID)
+		message := sessionTestMessage(t, sessionTestUUID)
+		f := sessionTestFlags(path, true)
+		f.Profile = "profile02"
+		_, rc := startSession(f, &message)
+		assert.Equal(t, utils.MissingOrIncorrectProfile, rc)
+	})
+	t.Run("resumes only the activation of the device", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), sessionFileName)
+		saveTestSession(t, path, "00000000-0000-0000-0000-000000000000")
+		message := sessionTestMessage(t, sessionTestUUID)
+		_, rc := startSession(sessionTestFlags(path, true), &message)
+		assert.Equal(t, utils.NoActivationToResume, rc)
+		// a new activation replaces the session of the other device
+		session, rc := startSession(sessionTestFlags(path, false), &message)
+		assert.Equal(t, utils.Success, rc)
+		assert.Equal(t, sessionTestUUID, session.UUID)
+	})
+	t.Run("has nothing to resume", func(t *testing.T) {
+		message := sessionTestMessage(t, sessionTestUUID)
+		_, rc := startSession(sessionTestFlags(filepath.Join(t.TempDir(), sessionFileName), true), &message)
+		assert.Equal(t, utils.NoActivationToResume, rc)
+	})
+	t.Run("rejects a file that is not a session", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), sessionFileName)
+		assert.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+		message := sessionTestMessage(t, sessionTestUUID)
+		_, rc := startSession(sessionTestFlags(path, true), &message)
+		assert.Equal(t, utils.FailedReadingConfiguration, rc)
+		// a new activation removes it
+		_, rc = startSession(sessionTestFlags(path, false), &message)
+		assert.Equal(t, utils.Success, rc)
+		assert.NoFileExists(t, path)
+	})
+}
+
+func saveTestSession(t *testing.T, path, uuid string) {
+	session := &Session{UUID: uuid, URL: "wss://rps/activate", Profile: "profile01", Phase: PhaseExchanging, Exchanges: 7, path: path}
+	assert.NoError(t, session.save())
+}
@@ -0,0 +1,122 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package secretstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// cyberArkStore reads the password of a CyberArk account with the Central Credential
+// Provider (CCP) and changes it with the Password Vault Web Access (PVWA) API. PVWA has no
+// check-and-set, the account is read again right before the change instead.
+type cyberArkStore struct {
+	client    *http.Client
+	ccpURL    string
+	updateURL string
+	token     string
+	// password read or written last
+	last string
+}
+
+type ccpResponse struct {
+	Content  string `json:"Content"`
+	ErrorMsg string `json:"ErrorMsg"`
+}
+
+func newCyberArkStore(client *http.Client, u *url.URL, token string) (*cyberArkStore, error) {
+	query := u.Query()
+	appID, safe, object := query.Get("appId"), query.Get("safe"), query.Get("object")
+	if u.Host == "" || appID == "" || safe == "" || object == "" {
+		return nil, fmt.Errorf("%s does not name an account, expected cyberark://host/?appId=...&safe=...&object=...", Redact(u.String()))
+	}
+	ccp := url.Values{"AppID": {appID}, "Safe": {safe}, "Object": {object}}
+	s := &cyberArkStore{
+		client: client,
+		ccpURL: "https://" + u.Host + "/AIMWebService/api/Accounts?" + ccp.Encode(),
+		token:  token,
+	}
+	if accountID := query.Get("accountId"); accountID != "" {
+		pvwa := query.Get("pvwa")
+		if pvwa == "" {
+			pvwa = u.Host
+		}
+		s.updateURL = "https://" + pvwa + "/PasswordVault/API/Accounts/" + url.PathEscape(accountID) + "/Password/Update"
+	}
+	return s, nil
+}
+
+func (s *cyberArkStore) Read() (string, error) {
+	rsp, err := s.client.Get(s.ccpURL)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	var account ccpResponse
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+	_ = json.Unmarshal(data, &account)
+	if rsp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return "", fmt.Errorf("CyberArk CCP answered %s: %s", rsp.Status, account.ErrorMsg)
+	}
+	if account.Content == "" {
+		return "", ErrNotFound
+	}
+	s.last = account.Content
+	return account.Content, nil
+}
+
+func (s *cyberArkStore) Write(password string) error {
+	if s.updateURL == "" {
+		return errors.New("the CyberArk account can not be changed without its accountId")
+	}
+	if s.token == "" {
+		return errors.New("a PVWA session token is required, set -vaultToken or VAULT_TOKEN")
+	}
+	expected := s.last
+	current, err := s.Read()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if current != expected {
+		return errors.New("the password of the CyberArk account was changed in the meantime")
+	}
+	body, err := json.Marshal(map[string]interface{}{"ChangeEntireGroup": false, "NewCredentials": password})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.updateURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", s.token)
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return fmt.Errorf("CyberArk PVWA answered %s", rsp.Status)
+	}
+	s.last = password
+	return nil
+}
+
+// Delete is not supported, the CyberArk account outlives the password
+func (s *cyberArkStore) Delete() error {
+	return errors.New("rpc does not delete CyberArk accounts")
+}
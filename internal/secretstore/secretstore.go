@@ -0,0 +1,66 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package secretstore reads and writes the AMT admin password in an external secret
+// store. The store is selected by the scheme of its URL:
+//
+//	vault://vault.example.com:8200/secret/amt/device1    HashiCorp Vault KV version 2, the mount is looked up
+//	vault+http://127.0.0.1:8200/secret/amt/device1       the same over http, for a development server
+//	cyberark://ccp.example.com/?appId=rpc&safe=AMT&object=device1&accountId=12_3
+//	                                                     CyberArk, read with the Central Credential Provider
+//	                                                     and written with the Password Vault Web Access API
+package secretstore
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// requestTimeout bounds each request to the secret store
+const requestTimeout = 30 * time.Second
+
+var ErrNotFound = errors.New("password not found in the secret store")
+
+// Store holds the AMT admin password of one device
+type Store interface {
+	// Read returns the stored password, ErrNotFound when none is stored
+	Read() (string, error)
+	// Write stores the password. It fails when the password was changed by someone
+	// else since it was read last, as far as the store can tell.
+	Write(password string) error
+	// Delete removes the password written last
+	Delete() error
+}
+
+// Open returns the store of the URL. The token authenticates rpc with the store.
+func Open(rawURL string, token string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: requestTimeout}
+	switch u.Scheme {
+	case "vault", "vault+https":
+		return newVaultStore(client, u, "https", token)
+	case "vault+http":
+		return newVaultStore(client, u, "http", token)
+	case "cyberark":
+		return newCyberArkStore(client, u, token)
+	}
+	return nil, fmt.Errorf("unsupported secret store %q, expected a vault:// or cyberark:// URL", u.Scheme)
+}
+
+// Redact returns the URL without user info, for messages
+func Redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "secret store"
+	}
+	u.User = nil
+	return u.String()
+}
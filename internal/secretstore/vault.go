@@ -0,0 +1,217 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package secretstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultField is the key of the password in the secret, ?field= selects another one
+const defaultField = "password"
+
+// vaultStore keeps the password in a HashiCorp Vault KV version 2 secret. Writes use
+// check-and-set with the version read or written last, so a password changed by
+// someone else in the meantime is never overwritten. The other keys of the secret are
+// kept, each write stores them again with the password.
+type vaultStore struct {
+	client  *http.Client
+	baseURL string
+	// secretPath is the mount and the path of the secret in it, split by mountPath
+	secretPath string
+	dataURL    string
+	token      string
+	field      string
+	// data of the secret read or written last, with version, 0 when it does not exist
+	data    map[string]string
+	version int
+	read    bool
+}
+
+type vaultSecret struct {
+	Data struct {
+		Data     map[string]string `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+type vaultWriteResponse struct {
+	Data struct {
+		Version int `json:"version"`
+	} `json:"data"`
+}
+
+type vaultMount struct {
+	Data struct {
+		Path    string            `json:"path"`
+		Type    string            `json:"type"`
+		Options map[string]string `json:"options"`
+	} `json:"data"`
+}
+
+type vaultErrors struct {
+	Errors []string `json:"errors"`
+}
+
+func newVaultStore(client *http.Client, u *url.URL, scheme string, token string) (*vaultStore, error) {
+	secretPath := strings.Trim(u.Path, "/")
+	if u.Host == "" || !strings.Contains(secretPath, "/") {
+		return nil, fmt.Errorf("%s does not name a secret, expected %s://host:port/mount/path", Redact(u.String()), u.Scheme)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a Vault token is required, set -vaultToken or VAULT_TOKEN")
+	}
+	field := u.Query().Get("field")
+	if field == "" {
+		field = defaultField
+	}
+	return &vaultStore{
+		client:     client,
+		baseURL:    fmt.Sprintf("%s://%s/v1/", scheme, u.Host),
+		secretPath: secretPath,
+		token:      token,
+		field:      field,
+	}, nil
+}
+
+// mountPath asks Vault for the mount of the secret, a mount may span several path
+// segments. Vault versions without the mounts endpoint take the first segment.
+func (s *vaultStore) mountPath() (string, error) {
+	var mount vaultMount
+	status, err := s.do(http.MethodGet, s.baseURL+"sys/internal/ui/mounts/"+s.secretPath, nil, &mount)
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed {
+		first, _, _ := strings.Cut(s.secretPath, "/")
+		return first + "/", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if mount.Data.Type != "kv" || mount.Data.Options["version"] != "2" {
+		return "", fmt.Errorf("%s is not a KV version 2 secrets engine", strings.TrimSuffix(mount.Data.Path, "/"))
+	}
+	if mount.Data.Path == "" || !strings.HasPrefix(s.secretPath, mount.Data.Path) {
+		return "", fmt.Errorf("vault reported the mount %q, which does not contain %s", mount.Data.Path, s.secretPath)
+	}
+	return mount.Data.Path, nil
+}
+
+// secretURL returns the data URL of the secret, the mount is looked up on first use
+func (s *vaultStore) secretURL() (string, error) {
+	if s.dataURL != "" {
+		return s.dataURL, nil
+	}
+	mount, err := s.mountPath()
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimPrefix(s.secretPath, mount)
+	if path == "" {
+		return "", fmt.Errorf("%s names the mount, not a secret in it", s.secretPath)
+	}
+	s.dataURL = s.baseURL + mount + "data/" + path
+	return s.dataURL, nil
+}
+
+func (s *vaultStore) Read() (string, error) {
+	dataURL, err := s.secretURL()
+	if err != nil {
+		return "", err
+	}
+	var secret vaultSecret
+	status, err := s.do(http.MethodGet, dataURL, nil, &secret)
+	if status == http.StatusNotFound {
+		s.data, s.version, s.read = nil, 0, true
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	s.data, s.version, s.read = secret.Data.Data, secret.Data.Metadata.Version, true
+	password := s.data[s.field]
+	if password == "" {
+		return "", ErrNotFound
+	}
+	return password, nil
+}
+
+// Write stores the password in a new version of the secret with the other keys of the
+// version read last, the secret is read first when it was not read yet
+func (s *vaultStore) Write(password string) error {
+	if !s.read {
+		if _, err := s.Read(); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	data := map[string]string{}
+	for key, value := range s.data {
+		data[key] = value
+	}
+	data[s.field] = password
+	request := map[string]interface{}{
+		"data":    data,
+		"options": map[string]int{"cas": s.version},
+	}
+	var rsp vaultWriteResponse
+	if _, err := s.do(http.MethodPost, s.dataURL, request, &rsp); err != nil {
+		return err
+	}
+	s.data, s.version = data, rsp.Data.Version
+	return nil
+}
+
+// Delete deletes the latest version of the secret, the version before it stays readable
+func (s *vaultStore) Delete() error {
+	dataURL, err := s.secretURL()
+	if err != nil {
+		return err
+	}
+	_, err = s.do(http.MethodDelete, dataURL, nil, nil)
+	return err
+}
+
+func (s *vaultStore) do(method string, target string, request interface{}, response interface{}) (int, error) {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rsp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return rsp.StatusCode, err
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		var vaultErr vaultErrors
+		_ = json.Unmarshal(data, &vaultErr)
+		return rsp.StatusCode, fmt.Errorf("vault answered %s: %s", rsp.Status, strings.Join(vaultErr.Errors, ", "))
+	}
+	if response == nil || len(data) == 0 {
+		return rsp.StatusCode, nil
+	}
+	return rsp.StatusCode, json.Unmarshal(data, response)
+}
@@ -0,0 +1,92 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package service installs rpc as a Windows service or systemd unit that runs
+// the agent, and runs the agent under the Windows service control manager.
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/internal/logging"
+	"rpc/pkg/utils"
+)
+
+var log = logging.For(logging.ModuleAgent)
+
+const (
+	Name        = "rpc"
+	DisplayName = "Remote Provisioning Client"
+	Description = "Periodically runs AMT maintenance tasks against the Remote Provisioning Server"
+)
+
+// Config describes the service to install
+type Config struct {
+	Executable string
+	Args       []string
+	// Secrets are the NAME=value lines of the secrets file of the agent, see installSecrets
+	Secrets []string
+}
+
+// Runner is the agent run by the service
+type Runner interface {
+	Run() utils.ReturnCode
+	RunUntil(stop <-chan struct{})
+}
+
+// ExecuteCommand runs the service subcommand selected in the flags
+func ExecuteCommand(f *flags.Flags) utils.ReturnCode {
+	var err error
+	switch f.SubCommand {
+	case utils.SubCommandServiceInstall:
+		var executable string
+		executable, err = os.Executable()
+		if err == nil {
+			err = install(Config{
+				Executable: executable,
+				Args:       append(f.Service.AgentArgs, "-secretsFile", secretsPath),
+				Secrets:    f.Service.Secrets,
+			})
+		}
+	case utils.SubCommandServiceUninstall:
+		err = uninstall()
+	case utils.SubCommandServiceStart:
+		err = start()
+	case utils.SubCommandServiceStop:
+		err = stop()
+	default:
+		return utils.IncorrectCommandLineParameters
+	}
+	if err != nil {
+		log.Errorf("service %s failed: %s", f.SubCommand, err)
+		return utils.ServiceCommandFailed
+	}
+	log.Infof("Status: service %s complete", f.SubCommand)
+	return utils.Success
+}
+
+// installSecrets writes the secrets of the agent to secretsPath. The secrets are kept out
+// of the command line and the environment of the service, which other users can read, the
+// file is only readable by administrators.
+func installSecrets(secrets []string) error {
+	if err := os.MkdirAll(filepath.Dir(secretsPath), 0700); err != nil {
+		return err
+	}
+	content := ""
+	for _, secret := range secrets {
+		content += secret + "\n"
+	}
+	return writeSecretsFile(secretsPath, []byte(content))
+}
+
+// removeSecrets removes the secrets file of an uninstalled service
+func removeSecrets() error {
+	if err := os.Remove(secretsPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"rpc/pkg/utils"
+	"strings"
+)
+
+var errNotInstalled = errors.New("service is not installed")
+
+// unitPath is the systemd unit file of the service
+var unitPath = "/etc/systemd/system/" + Name + ".service"
+
+// secretsPath is the secrets file of the agent, only readable by root
+var secretsPath = "/etc/" + Name + "/agent.secrets"
+
+// systemctl runs systemctl, it is replaced in tests
+var systemctl = func(args ...string) error {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func install(config Config) error {
+	if _, err := os.Stat(unitPath); err == nil {
+		return fmt.Errorf("%s already exists, uninstall the service first", unitPath)
+	}
+	if err := installSecrets(config.Secrets); err != nil {
+		return err
+	}
+	if err := os.WriteFile(unitPath, []byte(unitFile(config)), 0644); err != nil {
+		return err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", Name)
+}
+
+func uninstall() error {
+	if _, err := os.Stat(unitPath); errors.Is(err, os.ErrNotExist) {
+		return errNotInstalled
+	}
+	if err := systemctl("disable", "--now", Name); err != nil {
+		log.Warn(err)
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return err
+	}
+	if err := removeSecrets(); err != nil {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+func start() error {
+	return systemctl("start", Name)
+}
+
+func stop() error {
+	return systemctl("stop", Name)
+}
+
+// unitFile returns the systemd unit running the agent. systemd restarts the agent
+// when it fails and the journal collects its log.
+func unitFile(config Config) string {
+	execStart := []string{systemdQuote(config.Executable)}
+	for _, arg := range config.Args {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+	unit := "[Unit]\n"
+	unit += "Description=" + DisplayName + "\n"
+	unit += "After=network-online.target\n"
+	unit += "Wants=network-online.target\n"
+	unit += "StartLimitIntervalSec=0\n"
+	unit += "\n[Service]\n"
+	unit += "ExecStart=" + strings.Join(execStart, " ") + "\n"
+	unit += "Restart=on-failure\n"
+	unit += "RestartSec=60\n"
+	unit += "\n[Install]\n"
+	unit += "WantedBy=multi-user.target\n"
+	return unit
+}
+
+// systemdQuote quotes an argument of ExecStart, escaping quotes, backslashes, specifiers
+// and variables
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	return `"` + s + `"`
+}
+
+// writeSecretsFile writes the file only root can read. A file left behind is replaced, so
+// its permissions do not apply.
+func writeSecretsFile(path string, content []byte) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Run runs the agent, under systemd it is an ordinary process stopped with SIGTERM
+func Run(r Runner) utils.ReturnCode {
+	return r.Run()
+}
@@ -0,0 +1,158 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"rpc/internal/flags"
+	"rpc/pkg/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeSystemctl(t *testing.T, err error) *[]string {
+	var calls []string
+	orig := systemctl
+	systemctl = func(args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return err
+	}
+	t.Cleanup(func() { systemctl = orig })
+	origPath, origSecrets := unitPath, secretsPath
+	dir := t.TempDir()
+	unitPath = filepath.Join(dir, "rpc.service")
+	secretsPath = filepath.Join(dir, "rpc", "agent.secrets")
+	t.Cleanup(func() { unitPath, secretsPath = origPath, origSecrets })
+	return &calls
+}
+
+func TestUnitFile(t *testing.T) {
+	unit := unitFile(Config{
+		Executable: "/usr/local/bin/rpc",
+		Args:       []string{"agent", "-u", "wss://server/activate", "-interval", "1h0m0s"},
+		Secrets:    []string{"AMT_PASSWORD=P@ssw0rd"},
+	})
+	assert.Contains(t, unit, `ExecStart="/usr/local/bin/rpc" "agent" "-u" "wss://server/activate" "-interval" "1h0m0s"`+"\n")
+	assert.NotContains(t, unit, "P@ssw0rd")
+	assert.Contains(t, unit, "Restart=on-failure\n")
+	assert.Contains(t, unit, "WantedBy=multi-user.target\n")
+}
+
+func TestSystemdQuote(t *testing.T) {
+	assert.Equal(t, `"a b"`, systemdQuote("a b"))
+	assert.Equal(t, `"$$HOME %%h \\ \""`, systemdQuote(`$HOME %h \ "`))
+}
+
+func TestInstall(t *testing.T) {
+	t.Run("writes and enables the unit", func(t *testing.T) {
+		calls := fakeSystemctl(t, nil)
+		err := install(Config{Executable: "/usr/local/bin/rpc", Args: []string{"agent"}, Secrets: []string{"AMT_PASSWORD=P@ssw0rd", "RPS_TOKEN=jwt"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"daemon-reload", "enable rpc"}, *calls)
+		info, err := os.Stat(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		content, err := os.ReadFile(secretsPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "AMT_PASSWORD=P@ssw0rd\nRPS_TOKEN=jwt\n", string(content))
+		unit, err := os.ReadFile(unitPath)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(unit), "P@ssw0rd")
+
+		err = install(Config{Executable: "/usr/local/bin/rpc"})
+		assert.ErrorContains(t, err, "already exists")
+	})
+	t.Run("fails when systemctl fails", func(t *testing.T) {
+		fakeSystemctl(t, errors.New("no systemd"))
+		err := install(Config{Executable: "/usr/local/bin/rpc"})
+		assert.Error(t, err)
+	})
+}
+
+func TestUninstall(t *testing.T) {
+	t.Run("disables and removes the unit", func(t *testing.T) {
+		calls := fakeSystemctl(t, nil)
+		assert.NoError(t, os.WriteFile(unitPath, []byte("[Unit]\n"), 0644))
+		assert.NoError(t, installSecrets([]string{"AMT_PASSWORD=P@ssw0rd"}))
+		err := uninstall()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"disable --now rpc", "daemon-reload"}, *calls)
+		_, err = os.Stat(unitPath)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(secretsPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("fails when not installed", func(t *testing.T) {
+		fakeSystemctl(t, nil)
+		err := uninstall()
+		assert.Equal(t, errNotInstalled, err)
+	})
+}
+
+func TestExecuteCommand(t *testing.T) {
+	tests := map[string]struct {
+		subCommand string
+		err        error
+		wantCalls  []string
+		wantResult utils.ReturnCode
+	}{
+		"start": {
+			subCommand: utils.SubCommandServiceStart,
+			wantCalls:  []string{"start rpc"},
+			wantResult: utils.Success,
+		},
+		"stop": {
+			subCommand: utils.SubCommandServiceStop,
+			wantCalls:  []string{"stop rpc"},
+			wantResult: utils.Success,
+		},
+		"start fails": {
+			subCommand: utils.SubCommandServiceStart,
+			err:        errors.New("unit not found"),
+			wantCalls:  []string{"start rpc"},
+			wantResult: utils.ServiceCommandFailed,
+		},
+		"install": {
+			subCommand: utils.SubCommandServiceInstall,
+			wantCalls:  []string{"daemon-reload", "enable rpc"},
+			wantResult: utils.Success,
+		},
+		"unknown": {
+			subCommand: "restart",
+			wantResult: utils.IncorrectCommandLineParameters,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := fakeSystemctl(t, tc.err)
+			f := &flags.Flags{SubCommand: tc.subCommand}
+			f.Service.AgentArgs = []string{"agent", "-u", "wss://server/activate"}
+			rc := ExecuteCommand(f)
+			assert.Equal(t, tc.wantResult, rc)
+			assert.Equal(t, tc.wantCalls, *calls)
+		})
+	}
+}
+
+type testRunner struct {
+	ran bool
+}
+
+func (r *testRunner) Run() utils.ReturnCode {
+	r.ran = true
+	return utils.Success
+}
+
+func (r *testRunner) RunUntil(stop <-chan struct{}) {}
+
+func TestRun(t *testing.T) {
+	r := &testRunner{}
+	assert.Equal(t, utils.Success, Run(r))
+	assert.True(t, r.ran)
+}
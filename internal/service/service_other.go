@@ -0,0 +1,41 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package service
+
+import (
+	"errors"
+	"rpc/pkg/utils"
+)
+
+var errUnsupported = errors.New("services are only supported on Windows and Linux")
+
+var secretsPath = ""
+
+func writeSecretsFile(path string, content []byte) error {
+	return errUnsupported
+}
+
+func install(config Config) error {
+	return errUnsupported
+}
+
+func uninstall() error {
+	return errUnsupported
+}
+
+func start() error {
+	return errUnsupported
+}
+
+func stop() error {
+	return errUnsupported
+}
+
+func Run(r Runner) utils.ReturnCode {
+	return r.Run()
+}
@@ -0,0 +1,224 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"rpc/internal/logging"
+	"rpc/pkg/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// the service is restarted after a failure, the failure count resets after a day
+var recoveryActions = []mgr.RecoveryAction{
+	{Type: mgr.ServiceRestart, Delay: time.Minute},
+	{Type: mgr.ServiceRestart, Delay: 5 * time.Minute},
+	{Type: mgr.ServiceRestart, Delay: 15 * time.Minute},
+}
+
+const recoveryResetPeriod = 24 * 60 * 60
+
+func install(config Config) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(Name); err == nil {
+		s.Close()
+		return errors.New("service already exists, uninstall it first")
+	}
+	s, err := m.CreateService(Name, config.Executable, mgr.Config{
+		DisplayName: DisplayName,
+		Description: Description,
+		StartType:   mgr.StartAutomatic,
+	}, config.Args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := s.SetRecoveryActions(recoveryActions, recoveryResetPeriod); err != nil {
+		s.Delete()
+		return err
+	}
+	if err := installSecrets(config.Secrets); err != nil {
+		s.Delete()
+		return err
+	}
+	if err := eventlog.InstallAsEventCreate(Name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		// the event source remains from an earlier installation
+		log.Debug("unable to install event log source: ", err)
+	}
+	return nil
+}
+
+// secretsPath is the secrets file of the agent in the rpc folder of ProgramData
+var secretsPath = filepath.Join(os.Getenv("ProgramData"), Name, "agent.secrets")
+
+// secretsFileSDDL grants LocalSystem, which runs the service, and the administrators access
+// to the secrets file, without the permissions inherited from ProgramData
+const secretsFileSDDL = "D:P(A;;FA;;;SY)(A;;FA;;;BA)"
+
+// writeSecretsFile writes the file only LocalSystem and the administrators can read. The
+// file is created empty and restricted before the secrets are written to it.
+func writeSecretsFile(path string, content []byte) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	sd, err := windows.SecurityDescriptorFromString(secretsFileSDDL)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	err = windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, dacl, nil)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	return err
+}
+
+func uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if _, err := s.Control(svc.Stop); err != nil {
+		log.Debug("service not stopped: ", err)
+	}
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	if err := removeSecrets(); err != nil {
+		log.Warn("unable to remove the secrets of the agent: ", err)
+	}
+	if err := eventlog.Remove(Name); err != nil {
+		log.Debug("unable to remove event log source: ", err)
+	}
+	return nil
+}
+
+func start() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+func stop() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(Name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	_, err = s.Control(svc.Stop)
+	return err
+}
+
+// Run runs the agent as a Windows service when started by the service control
+// manager and as an ordinary process otherwise
+func Run(r Runner) utils.ReturnCode {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return r.Run()
+	}
+	elog, err := eventlog.Open(Name)
+	if err == nil {
+		defer elog.Close()
+		logging.AddHook(eventLogHook{elog: elog})
+	}
+	if err := svc.Run(Name, handler{runner: r}); err != nil {
+		log.Error(err)
+		return utils.ServiceCommandFailed
+	}
+	return utils.Success
+}
+
+type handler struct {
+	runner Runner
+}
+
+func (h handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	stopAgent := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.runner.RunUntil(stopAgent)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				log.Info("agent stopping")
+				status <- svc.Status{State: svc.StopPending}
+				close(stopAgent)
+				<-done
+				return false, 0
+			}
+		case <-done:
+			return false, 0
+		}
+	}
+}
+
+// eventLogHook copies warnings and errors and the info status messages to the event log
+type eventLogHook struct {
+	elog *eventlog.Log
+}
+
+func (h eventLogHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel}
+}
+
+func (h eventLogHook) Fire(entry *logrus.Entry) error {
+	switch entry.Level {
+	case logrus.InfoLevel:
+		return h.elog.Info(1, entry.Message)
+	case logrus.WarnLevel:
+		return h.elog.Warning(2, entry.Message)
+	default:
+		return h.elog.Error(3, entry.Message)
+	}
+}
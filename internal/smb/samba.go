@@ -4,14 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"github.com/hirochachacha/go-smb2"
-	log "github.com/sirupsen/logrus"
 	"net"
 	"net/url"
 	"os"
 	"os/user"
+	"rpc/internal/logging"
 	"strings"
 )
 
+var log = logging.For(logging.ModuleFlags)
+
 type Service struct {
 	Url          string
 	Host         string
@@ -22,6 +24,8 @@ type Service struct {
 	ShareName    string
 	FilePath     string
 	FileContents []byte
+	// NonInteractive fails instead of prompting for the password
+	NonInteractive bool
 }
 
 func NewSambaService(url string) Service {
@@ -48,7 +52,7 @@ func (s *Service) FetchFile() error {
 	log.Infof("fetching remote file server: %s:%s, user: %s, pwd: %s, domain: %s, share: %s, path: %s",
 		s.Host, s.Port, s.User, pwdOutput, s.Domain, s.ShareName, s.FilePath)
 
-	conn, err := net.Dial("tcp", fmt.Sprintf("%s:%s", s.Host, s.Port))
+	conn, err := net.Dial("tcp", net.JoinHostPort(s.Host, s.Port))
 	if err != nil {
 		return err
 	}
@@ -136,12 +140,16 @@ func (s *Service) ParseUrl() error {
 	}
 
 	s.Password, _ = u.User.Password()
+	if s.Password == "*" && s.NonInteractive {
+		return errors.New("the smb password is required, -nonInteractive does not prompt for it")
+	}
 	if s.Password == "*" {
 		fmt.Println("Please enter smb password: ")
 		_, err := fmt.Scanln(&s.Password)
 		if err != nil {
 			return err
 		}
+		logging.Redact(s.Password)
 	}
 
 	return nil
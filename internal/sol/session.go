@@ -0,0 +1,324 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package sol is the client side of the AMT redirection protocol for Serial-over-LAN,
+// the console of the BIOS or OS reached through AMT
+package sol
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	// RedirectionPort and RedirectionTLSPort are the ports AMT listens on for SOL, IDE-R and KVM
+	RedirectionPort    = 16994
+	RedirectionTLSPort = 16995
+)
+
+// message types of the redirection protocol
+const (
+	startRedirectionSession      byte = 0x10
+	startRedirectionSessionReply byte = 0x11
+	endRedirectionSession        byte = 0x12
+	authenticateSession          byte = 0x13
+	authenticateSessionReply     byte = 0x14
+	startSOL                     byte = 0x20
+	startSOLReply                byte = 0x21
+	hostData                     byte = 0x28
+	serialSettings               byte = 0x29
+	consoleData                  byte = 0x2A
+	heartbeat                    byte = 0x2B
+)
+
+const (
+	authQuery  byte = 0
+	authDigest byte = 4
+	// digestURI is the URI the digest response is computed for
+	digestURI = "/RedirectionService"
+	// maxTxBuffer is the largest console data message AMT is asked to accept
+	maxTxBuffer = 10000
+)
+
+var (
+	// ErrAuthentication is returned when AMT rejects the digest user and password
+	ErrAuthentication = errors.New("AMT rejected the user and password of the SOL session")
+	// ErrRefused is returned when AMT refuses SOL, ex. when SOL is disabled in the redirection settings
+	ErrRefused = errors.New("AMT refused the SOL session, check that SOL is enabled with configure redirection")
+)
+
+// Session is an open SOL session, Read returns the console output of the device and
+// Write sends keystrokes to it
+type Session struct {
+	conn     io.ReadWriteCloser
+	reader   *bufio.Reader
+	sequence uint32
+	// pending is console output not yet returned by Read
+	pending []byte
+	writeMu sync.Mutex
+}
+
+// Open starts a redirection session on conn, authenticates with HTTP digest and starts
+// SOL. conn is closed when Open fails.
+func Open(conn io.ReadWriteCloser, user, password string) (*Session, error) {
+	s := &Session{conn: conn, reader: bufio.NewReader(conn)}
+	if err := s.open(user, password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Session) open(user, password string) error {
+	if err := s.send([]byte{startRedirectionSession, 0, 0, 0, 'S', 'O', 'L', ' '}); err != nil {
+		return err
+	}
+	reply, err := s.readMessage()
+	if err != nil {
+		return err
+	}
+	if reply[0] != startRedirectionSessionReply || reply[1] != 0 {
+		return ErrRefused
+	}
+	if err = s.authenticate(user, password); err != nil {
+		return err
+	}
+	settings := []byte{startSOL, 0, 0, 0}
+	settings = binary.LittleEndian.AppendUint32(settings, s.nextSequence())
+	for _, value := range []uint16{
+		maxTxBuffer, // MaxTxBuffer
+		100,         // TxTimeout in milliseconds
+		0,           // TxOverflowTimeout
+		10000,       // RxTimeout in milliseconds
+		100,         // RxFlushTimeout in milliseconds
+		0,           // Heartbeat interval, AMT sends none
+	} {
+		settings = binary.LittleEndian.AppendUint16(settings, value)
+	}
+	if err = s.send(append(settings, 0, 0, 0, 0)); err != nil {
+		return err
+	}
+	for {
+		if reply, err = s.readMessage(); err != nil {
+			return err
+		}
+		switch reply[0] {
+		case startSOLReply:
+			if reply[1] != 0 {
+				return ErrRefused
+			}
+			return nil
+		case heartbeat, serialSettings:
+		default:
+			return fmt.Errorf("unexpected redirection message 0x%02x while starting SOL", reply[0])
+		}
+	}
+}
+
+// authenticate queries the authentication types of AMT and answers its digest challenge
+func (s *Session) authenticate(user, password string) error {
+	if err := s.send(authenticationMessage(authQuery, nil)); err != nil {
+		return err
+	}
+	reply, err := s.readMessage()
+	if err != nil {
+		return err
+	}
+	if reply[0] != authenticateSessionReply {
+		return fmt.Errorf("unexpected redirection message 0x%02x while authenticating", reply[0])
+	}
+	if !containsByte(reply[9:], authDigest) {
+		return errors.New("AMT does not offer digest authentication for SOL")
+	}
+	// the first digest message has only the user and the URI, AMT answers with its challenge
+	if err = s.send(authenticationMessage(authDigest, lengthPrefixed(user, "", "", digestURI, "", "", "", ""))); err != nil {
+		return err
+	}
+	if reply, err = s.readMessage(); err != nil {
+		return err
+	}
+	fields := splitLengthPrefixed(reply[9:])
+	if reply[0] != authenticateSessionReply || reply[4] != authDigest || len(fields) < 3 {
+		return ErrAuthentication
+	}
+	realm, nonce, qop := fields[0], fields[1], fields[2]
+	cnonce := make([]byte, 16)
+	if _, err = rand.Read(cnonce); err != nil {
+		return err
+	}
+	clientNonce, nc := hex.EncodeToString(cnonce), "00000002"
+	response := DigestResponse(user, password, realm, nonce, nc, clientNonce, qop)
+	if err = s.send(authenticationMessage(authDigest, lengthPrefixed(user, realm, nonce, digestURI, clientNonce, nc, response, qop))); err != nil {
+		return err
+	}
+	if reply, err = s.readMessage(); err != nil {
+		return err
+	}
+	if reply[0] != authenticateSessionReply || reply[1] != 0 {
+		return ErrAuthentication
+	}
+	return nil
+}
+
+// DigestResponse is the HTTP digest response AMT expects for the redirection service
+func DigestResponse(user, password, realm, nonce, nc, cnonce, qop string) string {
+	ha1 := md5Hex(user + ":" + realm + ":" + password)
+	ha2 := md5Hex("POST:" + digestURI)
+	return md5Hex(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+}
+
+// Read returns the console output of the device, the heartbeats of AMT are skipped
+func (s *Session) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		message, err := s.readMessage()
+		if err != nil {
+			return 0, err
+		}
+		switch message[0] {
+		case consoleData:
+			s.pending = message[10:]
+		case heartbeat, serialSettings:
+		case endRedirectionSession:
+			return 0, io.EOF
+		default:
+			return 0, fmt.Errorf("unexpected redirection message 0x%02x", message[0])
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Write sends keystrokes to the device, in messages of at most maxTxBuffer bytes
+func (s *Session) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > maxTxBuffer {
+			chunk = chunk[:maxTxBuffer]
+		}
+		message := []byte{hostData, 0, 0, 0}
+		message = binary.LittleEndian.AppendUint32(message, s.nextSequence())
+		message = binary.LittleEndian.AppendUint16(message, uint16(len(chunk)))
+		if err := s.send(append(message, chunk...)); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// Close ends the redirection session and closes the connection
+func (s *Session) Close() error {
+	// AMT may already have closed the connection
+	_ = s.send([]byte{endRedirectionSession, 0, 0, 0})
+	return s.conn.Close()
+}
+
+func (s *Session) nextSequence() uint32 {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.sequence++
+	return s.sequence
+}
+
+func (s *Session) send(message []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err := s.conn.Write(message)
+	return err
+}
+
+// readMessage reads one message, its length follows from the type
+func (s *Session) readMessage() ([]byte, error) {
+	messageType, err := s.reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	var size int
+	switch messageType[0] {
+	case startRedirectionSessionReply:
+		// the OEM defined data follows the 13 byte header
+		header, err := s.reader.Peek(13)
+		if err != nil {
+			return nil, err
+		}
+		size = 13 + int(header[12])
+	case authenticateSessionReply:
+		header, err := s.reader.Peek(9)
+		if err != nil {
+			return nil, err
+		}
+		size = 9 + int(binary.LittleEndian.Uint32(header[5:9]))
+	case startSOLReply:
+		size = 23
+	case serialSettings:
+		size = 10
+	case consoleData:
+		header, err := s.reader.Peek(10)
+		if err != nil {
+			return nil, err
+		}
+		size = 10 + int(binary.LittleEndian.Uint16(header[8:10]))
+	case heartbeat:
+		size = 8
+	case endRedirectionSession:
+		size = 4
+	default:
+		return nil, fmt.Errorf("unknown redirection message 0x%02x", messageType[0])
+	}
+	message := make([]byte, size)
+	if _, err = io.ReadFull(s.reader, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func authenticationMessage(authType byte, data []byte) []byte {
+	message := []byte{authenticateSession, 0, 0, 0, authType}
+	message = binary.LittleEndian.AppendUint32(message, uint32(len(data)))
+	return append(message, data...)
+}
+
+// lengthPrefixed joins the fields of a digest message, each preceded by its length
+func lengthPrefixed(fields ...string) []byte {
+	var data []byte
+	for _, field := range fields {
+		data = append(data, byte(len(field)))
+		data = append(data, field...)
+	}
+	return data
+}
+
+func splitLengthPrefixed(data []byte) []string {
+	var fields []string
+	for len(data) > 0 && int(data[0]) < len(data) {
+		fields = append(fields, string(data[1:1+int(data[0])]))
+		data = data[1+int(data[0]):]
+	}
+	return fields
+}
+
+func containsByte(data []byte, b byte) bool {
+	for _, d := range data {
+		if d == b {
+			return true
+		}
+	}
+	return false
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package sol
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAMT answers the redirection protocol on the server side of a pipe
+type fakeAMT struct {
+	t        *testing.T
+	conn     net.Conn
+	password string
+	// refuseSOL answers the start of SOL with an error status
+	refuseSOL bool
+	// received is the keystrokes of the host
+	received chan []byte
+}
+
+func newSession(t *testing.T, amt *fakeAMT, password string) (*Session, error) {
+	client, server := net.Pipe()
+	amt.t, amt.conn = t, server
+	amt.received = make(chan []byte, 10)
+	go amt.serve()
+	t.Cleanup(func() { server.Close() })
+	return Open(client, "admin", password)
+}
+
+func (a *fakeAMT) read(n int) []byte {
+	data := make([]byte, n)
+	if _, err := io.ReadFull(a.conn, data); err != nil {
+		return nil
+	}
+	return data
+}
+
+func (a *fakeAMT) readAuthentication() (byte, []string) {
+	header := a.read(9)
+	if header == nil {
+		return 0, nil
+	}
+	assert.Equal(a.t, authenticateSession, header[0])
+	data := a.read(int(binary.LittleEndian.Uint32(header[5:9])))
+	return header[4], splitLengthPrefixed(data)
+}
+
+func (a *fakeAMT) authenticationReply(status, authType byte, data []byte) {
+	reply := []byte{authenticateSessionReply, status, 0, 0, authType}
+	reply = binary.LittleEndian.AppendUint32(reply, uint32(len(data)))
+	a.conn.Write(append(reply, data...))
+}
+
+func (a *fakeAMT) serve() {
+	start := a.read(8)
+	if start == nil {
+		return
+	}
+	assert.Equal(a.t, []byte{startRedirectionSession, 0, 0, 0, 'S', 'O', 'L', ' '}, start)
+	a.conn.Write([]byte{startRedirectionSessionReply, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	authType, _ := a.readAuthentication()
+	assert.Equal(a.t, authQuery, authType)
+	a.authenticationReply(0, authQuery, []byte{1, authDigest})
+
+	authType, fields := a.readAuthentication()
+	assert.Equal(a.t, authDigest, authType)
+	assert.Equal(a.t, []string{"admin", "", "", digestURI, "", "", "", ""}, fields)
+	a.authenticationReply(1, authDigest, lengthPrefixed("Digest:AMT", "nonce", "auth"))
+
+	_, fields = a.readAuthentication()
+	if len(fields) != 8 || fields[6] != DigestResponse("admin", a.password, "Digest:AMT", "nonce", fields[5], fields[4], "auth") {
+		a.authenticationReply(1, authDigest, nil)
+		return
+	}
+	a.authenticationReply(0, authDigest, nil)
+
+	settings := a.read(24)
+	if settings == nil {
+		return
+	}
+	assert.Equal(a.t, startSOL, settings[0])
+	status := byte(0)
+	if a.refuseSOL {
+		status = 1
+	}
+	a.conn.Write([]byte{heartbeat, 0, 0, 0, 0, 0, 0, 0})
+	a.conn.Write(append([]byte{startSOLReply, status}, make([]byte, 21)...))
+
+	a.conn.Write([]byte{consoleData, 0, 0, 0, 1, 0, 0, 0, 5, 0, 'B', 'I', 'O', 'S', '>'})
+	for {
+		header := a.read(10)
+		if header == nil || header[0] == endRedirectionSession {
+			close(a.received)
+			return
+		}
+		assert.Equal(a.t, hostData, header[0])
+		a.received <- a.read(int(binary.LittleEndian.Uint16(header[8:10])))
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Run("authenticates with digest and exchanges console data", func(t *testing.T) {
+		amt := &fakeAMT{password: "P@ssw0rd"}
+		session, err := newSession(t, amt, "P@ssw0rd")
+		assert.NoError(t, err)
+		buf := make([]byte, 3)
+		n, err := session.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "BIO", string(buf[:n]))
+		n, err = session.Read(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "S>", string(buf[:n]))
+
+		n, err = session.Write([]byte("\r"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []byte("\r"), <-amt.received)
+		session.Close()
+	})
+	t.Run("a wrong password fails", func(t *testing.T) {
+		_, err := newSession(t, &fakeAMT{password: "P@ssw0rd"}, "wrong")
+		assert.ErrorIs(t, err, ErrAuthentication)
+	})
+	t.Run("SOL refused by AMT fails", func(t *testing.T) {
+		_, err := newSession(t, &fakeAMT{password: "P@ssw0rd", refuseSOL: true}, "P@ssw0rd")
+		assert.ErrorIs(t, err, ErrRefused)
+	})
+}
+
+func TestWriteSplitsLargeInput(t *testing.T) {
+	amt := &fakeAMT{password: "P@ssw0rd"}
+	session, err := newSession(t, amt, "P@ssw0rd")
+	assert.NoError(t, err)
+	go io.Copy(io.Discard, session)
+	n, err := session.Write(make([]byte, maxTxBuffer+1))
+	assert.NoError(t, err)
+	assert.Equal(t, maxTxBuffer+1, n)
+	assert.Len(t, <-amt.received, maxTxBuffer)
+	assert.Len(t, <-amt.received, 1)
+}
+
+func TestDigestResponse(t *testing.T) {
+	// computed with the HTTP digest algorithm of RFC 2617
+	assert.Equal(t, md5Hex(md5Hex("admin:Digest:AMT:P@ssw0rd")+":nonce:00000002:cnonce:auth:"+md5Hex("POST:/RedirectionService")),
+		DigestResponse("admin", "P@ssw0rd", "Digest:AMT", "nonce", "00000002", "cnonce", "auth"))
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package sol
+
+import (
+	"errors"
+	"os"
+)
+
+// MakeRaw is not supported on this OS, the session runs with the line mode of the terminal
+func MakeRaw(terminal *os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this OS")
+}
@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package sol
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// MakeRaw turns off line input, echo and Ctrl+C processing of the console and passes the
+// keys on as VT sequences. The returned function restores the previous mode.
+func MakeRaw(terminal *os.File) (func(), error) {
+	console := windows.Handle(terminal.Fd())
+	var previous uint32
+	if err := windows.GetConsoleMode(console, &previous); err != nil {
+		return nil, err
+	}
+	raw := previous &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT | windows.ENABLE_PROCESSED_INPUT)
+	raw |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(console, raw); err != nil {
+		return nil, err
+	}
+	return func() { _ = windows.SetConsoleMode(console, previous) }, nil
+}
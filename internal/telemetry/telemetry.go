@@ -0,0 +1,339 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package telemetry records OpenTelemetry traces and metrics of an rpc operation and
+// exports them with the OTLP over HTTP exporters of the OpenTelemetry SDK. Nothing is
+// recorded until Setup is given an endpoint, the spans of a disabled recorder are nil
+// and their methods do nothing.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"rpc/internal/logging"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var log = logging.For(logging.ModuleRPC)
+
+const (
+	// MetricOperationDuration is the histogram of the duration of rpc commands
+	MetricOperationDuration = "rpc.operation.duration"
+	// MetricMEIDuration is the histogram of the latency of MEI commands
+	MetricMEIDuration = "rpc.mei.duration"
+	// MetricRPSRoundTrip is the histogram of the time RPS takes to answer a message
+	MetricRPSRoundTrip = "rpc.rps.round_trip.duration"
+
+	// maxSpans bounds the spans waiting for an export, the exporter sends them in
+	// batches before the queue fills up
+	maxSpans = 4096
+)
+
+// exportTimeout bounds each request to the OTLP endpoint, so an unreachable collector
+// delays the end of the command by this much at most
+const exportTimeout = 5 * time.Second
+
+// exportInterval is how often the spans and metrics are exported while a long running
+// command, ex. the agent or continuous sync, is still running
+var exportInterval = 30 * time.Second
+
+// durationBounds are the bucket bounds of the duration histograms in milliseconds
+var durationBounds = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+var metricDescriptions = map[string]string{
+	MetricOperationDuration: "Duration of rpc commands",
+	MetricMEIDuration:       "Latency of MEI commands",
+	MetricRPSRoundTrip:      "Time RPS takes to answer a message",
+}
+
+type recorder struct {
+	traces     *sdktrace.TracerProvider
+	metrics    *sdkmetric.MeterProvider
+	tracer     trace.Tracer
+	histograms map[string]metric.Float64Histogram
+
+	mu sync.Mutex
+	// root is the context of the operation span, the other spans are its children
+	root     context.Context
+	rootSpan trace.Span
+}
+
+var (
+	currentMu sync.Mutex
+	current   *recorder
+)
+
+// Setup starts recording for export to the OTLP endpoint, ex. http://collector:4318.
+// The spans and metrics are exported every exportInterval and by Shutdown. An empty
+// endpoint stops recording and exports what is left.
+func Setup(endpoint string) {
+	currentMu.Lock()
+	previous := current
+	current = nil
+	currentMu.Unlock()
+	if previous != nil {
+		if err := previous.shutdown(); err != nil {
+			log.Warn(err)
+		}
+	}
+	if endpoint == "" {
+		return
+	}
+	rec, err := newRecorder(endpoint)
+	if err != nil {
+		log.Warn("telemetry is not recorded: ", err)
+		return
+	}
+	currentMu.Lock()
+	current = rec
+	currentMu.Unlock()
+}
+
+func newRecorder(endpoint string) (*recorder, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	traceOptions := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(path + "/v1/traces"),
+		otlptracehttp.WithTimeout(exportTimeout),
+		otlptracehttp.WithRetry(otlptracehttp.RetryConfig{Enabled: false}),
+	}
+	metricOptions := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(u.Host),
+		otlpmetrichttp.WithURLPath(path + "/v1/metrics"),
+		otlpmetrichttp.WithTimeout(exportTimeout),
+		otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig{Enabled: false}),
+		// each export holds the measurements since the previous one
+		otlpmetrichttp.WithTemporalitySelector(func(sdkmetric.InstrumentKind) metricdata.Temporality {
+			return metricdata.DeltaTemporality
+		}),
+	}
+	if u.Scheme == "http" {
+		traceOptions = append(traceOptions, otlptracehttp.WithInsecure())
+		metricOptions = append(metricOptions, otlpmetrichttp.WithInsecure())
+	}
+	ctx := context.Background()
+	traceExporter, err := otlptracehttp.New(ctx, traceOptions...)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOptions...)
+	if err != nil {
+		return nil, err
+	}
+	// the exports in the background report their errors here
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Warn("exporting telemetry to ", endpoint, " failed: ", err)
+	}))
+
+	hostname, _ := os.Hostname()
+	res := resource.NewSchemaless(
+		attribute.String("service.name", utils.ProjectName),
+		attribute.String("service.version", utils.ProjectVersion),
+		attribute.String("host.name", hostname),
+	)
+	rec := &recorder{
+		traces: sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithBatcher(traceExporter,
+				sdktrace.WithBatchTimeout(exportInterval),
+				sdktrace.WithMaxQueueSize(maxSpans),
+				sdktrace.WithExportTimeout(exportTimeout)),
+		),
+		metrics: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter,
+				sdkmetric.WithInterval(exportInterval),
+				sdkmetric.WithTimeout(exportTimeout))),
+			sdkmetric.WithView(sdkmetric.NewView(
+				sdkmetric.Instrument{Kind: sdkmetric.InstrumentKindHistogram},
+				sdkmetric.Stream{Aggregation: sdkmetric.AggregationExplicitBucketHistogram{Boundaries: durationBounds}},
+			)),
+		),
+		histograms: map[string]metric.Float64Histogram{},
+	}
+	rec.tracer = rec.traces.Tracer(utils.ProjectName, trace.WithInstrumentationVersion(utils.ProjectVersion))
+	meter := rec.metrics.Meter(utils.ProjectName, metric.WithInstrumentationVersion(utils.ProjectVersion))
+	for name, description := range metricDescriptions {
+		if rec.histograms[name], err = meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit("ms")); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// ValidateEndpoint checks that the endpoint is an http or https URL
+func ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s is not an http:// or https:// URL", endpoint)
+	}
+	return nil
+}
+
+func active() *recorder {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	return current
+}
+
+// Span times one step of the operation, a nil span records nothing
+type Span struct {
+	rec       *recorder
+	span      trace.Span
+	metric    string
+	operation bool
+	start     time.Time
+	// attrs are the attributes of the duration measurement
+	attrs []attribute.KeyValue
+	once  sync.Once
+}
+
+// StartOperation starts the span of the command, the other spans are its children
+func StartOperation(command string, subCommand string) *Span {
+	rec := active()
+	if rec == nil {
+		return nil
+	}
+	name := command
+	if subCommand != "" {
+		name += " " + subCommand
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.command", command),
+		attribute.String("rpc.subcommand", subCommand),
+	}
+	span := rec.start(name, trace.SpanKindInternal, MetricOperationDuration, attrs)
+	span.operation = true
+	rec.mu.Lock()
+	rec.root = trace.ContextWithSpan(context.Background(), span.span)
+	rec.rootSpan = span.span
+	rec.mu.Unlock()
+	return span
+}
+
+// StartMEICall starts the span of an MEI command
+func StartMEICall(method string) *Span {
+	rec := active()
+	if rec == nil {
+		return nil
+	}
+	return rec.start("mei "+method, trace.SpanKindClient, MetricMEIDuration, []attribute.KeyValue{attribute.String("rpc.mei.method", method)})
+}
+
+// StartRPSRoundTrip starts the span of a message to RPS that ends when RPS answers
+func StartRPSRoundTrip() *Span {
+	rec := active()
+	if rec == nil {
+		return nil
+	}
+	return rec.start("rps round trip", trace.SpanKindClient, MetricRPSRoundTrip, nil)
+}
+
+func (rec *recorder) start(name string, kind trace.SpanKind, metric string, attrs []attribute.KeyValue) *Span {
+	rec.mu.Lock()
+	parent := rec.root
+	rec.mu.Unlock()
+	if parent == nil {
+		parent = context.Background()
+	}
+	_, span := rec.tracer.Start(parent, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+	return &Span{rec: rec, span: span, metric: metric, start: time.Now(), attrs: attrs}
+}
+
+// End records the span and its duration, err marks it failed. Only the first End counts.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		status := "ok"
+		if err != nil {
+			status = "error"
+			s.span.SetStatus(codes.Error, err.Error())
+		} else {
+			s.span.SetStatus(codes.Ok, "")
+		}
+		if s.operation {
+			s.span.SetAttributes(attribute.String("rpc.return_code", strconv.Itoa(int(rpcerr.ReturnCodeOf(err)))))
+		}
+		s.span.End()
+		ms := float64(time.Since(s.start)) / float64(time.Millisecond)
+		attrs := append([]attribute.KeyValue{attribute.String("status", status)}, s.attrs...)
+		s.rec.histograms[s.metric].Record(context.Background(), ms, metric.WithAttributes(attrs...))
+
+		s.rec.mu.Lock()
+		if s.rec.rootSpan == s.span {
+			s.rec.root, s.rec.rootSpan = nil, nil
+		}
+		s.rec.mu.Unlock()
+	})
+}
+
+// Flush exports the spans and metrics recorded since the last export
+func Flush() error {
+	rec := active()
+	if rec == nil {
+		return nil
+	}
+	return rec.flush()
+}
+
+// Shutdown exports what was not exported yet and stops recording, it is called when
+// rpc exits, also after SIGINT or SIGTERM
+func Shutdown() error {
+	currentMu.Lock()
+	rec := current
+	current = nil
+	currentMu.Unlock()
+	if rec == nil {
+		return nil
+	}
+	return rec.shutdown()
+}
+
+func (rec *recorder) flush() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*exportTimeout)
+	defer cancel()
+	if err := rec.traces.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("exporting traces failed: %w", err)
+	}
+	if err := rec.metrics.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("exporting metrics failed: %w", err)
+	}
+	return nil
+}
+
+func (rec *recorder) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*exportTimeout)
+	defer cancel()
+	return errors.Join(rec.traces.Shutdown(ctx), rec.metrics.Shutdown(ctx))
+}
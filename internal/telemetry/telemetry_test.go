@@ -0,0 +1,179 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package telemetry
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector decodes the export requests like an OTLP/HTTP collector does
+type collector struct {
+	mu       sync.Mutex
+	requests map[string][]byte
+	status   int
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	c := &collector{requests: map[string][]byte{}, status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		c.mu.Lock()
+		c.requests[r.URL.Path] = body
+		status := c.status
+		c.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(func() {
+		Setup("")
+		server.Close()
+	})
+	return c, server
+}
+
+func (c *collector) request(path string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	body, ok := c.requests[path]
+	return body, ok
+}
+
+func (c *collector) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = map[string][]byte{}
+}
+
+func (c *collector) spans(t *testing.T) []*tracepb.Span {
+	body, ok := c.request("/otlp/v1/traces")
+	assert.True(t, ok, "traces were exported")
+	var traces coltracepb.ExportTraceServiceRequest
+	assert.NoError(t, proto.Unmarshal(body, &traces))
+	var spans []*tracepb.Span
+	for _, resourceSpans := range traces.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			spans = append(spans, scopeSpans.Spans...)
+		}
+	}
+	return spans
+}
+
+func TestFlush(t *testing.T) {
+	c, server := newCollector(t)
+	Setup(server.URL + "/otlp/")
+
+	operation := StartOperation("activate", "")
+	StartMEICall("GetUUID").End(nil)
+	StartRPSRoundTrip().End(errors.New("connection lost"))
+	operation.End(rpcerr.New(utils.ActivationFailed, "activation failed"))
+	operation.End(nil)
+	assert.NoError(t, Flush())
+
+	spans := c.spans(t)
+	assert.Len(t, spans, 3)
+	root := spans[2]
+	assert.Equal(t, "activate", root.Name)
+	assert.Empty(t, root.ParentSpanId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, root.Status.Code)
+	var returnCode string
+	for _, attr := range root.Attributes {
+		if attr.Key == "rpc.return_code" {
+			returnCode = attr.Value.GetStringValue()
+		}
+	}
+	assert.Equal(t, "102", returnCode)
+	for _, span := range spans[:2] {
+		assert.Equal(t, root.SpanId, span.ParentSpanId)
+		assert.Equal(t, root.TraceId, span.TraceId)
+		assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, span.Kind)
+	}
+	assert.Equal(t, "mei GetUUID", spans[0].Name)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_OK, spans[0].Status.Code)
+	assert.Equal(t, "connection lost", spans[1].Status.Message)
+
+	body, ok := c.request("/otlp/v1/metrics")
+	assert.True(t, ok, "metrics were exported")
+	var metrics colmetricpb.ExportMetricsServiceRequest
+	assert.NoError(t, proto.Unmarshal(body, &metrics))
+	var names []string
+	for _, metric := range metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		names = append(names, metric.Name)
+		point := metric.GetHistogram().DataPoints[0]
+		assert.Equal(t, uint64(1), point.Count)
+		assert.Len(t, point.BucketCounts, len(durationBounds)+1)
+		assert.Equal(t, "ms", metric.Unit)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{MetricMEIDuration, MetricOperationDuration, MetricRPSRoundTrip}, names)
+
+	c.reset()
+	assert.NoError(t, Flush())
+	_, ok = c.request("/otlp/v1/traces")
+	assert.False(t, ok, "nothing is exported twice")
+}
+
+func TestPeriodicExport(t *testing.T) {
+	defer func(interval time.Duration) { exportInterval = interval }(exportInterval)
+	exportInterval = 20 * time.Millisecond
+	c, server := newCollector(t)
+	Setup(server.URL + "/otlp")
+	// a long running command exports while it runs, before its operation ends
+	StartOperation("agent", "")
+	StartMEICall("GetUUID").End(nil)
+	assert.Eventually(t, func() bool {
+		_, ok := c.request("/otlp/v1/traces")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestShutdown(t *testing.T) {
+	c, server := newCollector(t)
+	Setup(server.URL + "/otlp")
+	StartOperation("amtinfo", "").End(nil)
+	assert.NoError(t, Shutdown())
+	assert.Len(t, c.spans(t), 1)
+	assert.Nil(t, StartMEICall("GetUUID"), "nothing is recorded after Shutdown")
+}
+
+func TestFlushFails(t *testing.T) {
+	c, server := newCollector(t)
+	c.status = http.StatusServiceUnavailable
+	Setup(server.URL)
+	StartOperation("amtinfo", "").End(nil)
+	assert.Error(t, Flush())
+}
+
+func TestDisabled(t *testing.T) {
+	Setup("")
+	span := StartOperation("amtinfo", "")
+	assert.Nil(t, span)
+	span.End(nil)
+	StartMEICall("GetUUID").End(nil)
+	assert.NoError(t, Flush())
+	assert.NoError(t, Shutdown())
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	assert.NoError(t, ValidateEndpoint("http://collector:4318"))
+	assert.NoError(t, ValidateEndpoint("https://collector.example.com"))
+	assert.Error(t, ValidateEndpoint("collector:4318"))
+	assert.Error(t, ValidateEndpoint("grpc://collector:4317"))
+	assert.Error(t, ValidateEndpoint("http://"))
+}
@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package wlan
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// connectionsDir holds the NetworkManager keyfile connections, readable by root only
+var connectionsDir = "/etc/NetworkManager/system-connections"
+
+func profiles() ([]Profile, error) {
+	files, err := filepath.Glob(filepath.Join(connectionsDir, "*.nmconnection"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var result []Profile
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if profile, ok := parseKeyfile(string(content)); ok {
+			result = append(result, profile)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+//go:build linux
+// +build linux
+
+package wlan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	defer func(dir string) { connectionsDir = dir }(connectionsDir)
+	connectionsDir = t.TempDir()
+	files := map[string]string{
+		"b-lab.nmconnection":    "[connection]\nid=Lab\ntype=wifi\n[wifi]\nssid=lab\n[wifi-security]\nkey-mgmt=wpa-psk\npsk=labP@ss\n",
+		"a-office.nmconnection": "[connection]\nid=Office\ntype=wifi\n[wifi]\nssid=office\n[wifi-security]\nkey-mgmt=wpa-psk\npsk=officeP@ss\n",
+		"wired.nmconnection":    "[connection]\nid=Wired\ntype=ethernet\n",
+		"notes.txt":             "[connection]\ntype=wifi\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(connectionsDir, name), []byte(content), 0600))
+	}
+	profiles, err := Profiles()
+	assert.NoError(t, err)
+	assert.Len(t, profiles, 2)
+	assert.Equal(t, "office", profiles[0].SSID)
+	assert.Equal(t, "lab", profiles[1].SSID)
+}
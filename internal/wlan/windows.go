@@ -0,0 +1,121 @@
+//go:build windows
+// +build windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package wlan
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	wlanClientVersion2         = 2
+	wlanProfileGetPlaintextKey = 4
+	wlanInterfaceInfoSize      = 16 + 256*2 + 4
+	wlanProfileInfoSize        = 256*2 + 4
+	wlanListHeaderSize         = 8
+)
+
+var (
+	wlanapi                = windows.NewLazySystemDLL("wlanapi.dll")
+	procWlanOpenHandle     = wlanapi.NewProc("WlanOpenHandle")
+	procWlanCloseHandle    = wlanapi.NewProc("WlanCloseHandle")
+	procWlanEnumInterfaces = wlanapi.NewProc("WlanEnumInterfaces")
+	procWlanGetProfileList = wlanapi.NewProc("WlanGetProfileList")
+	procWlanGetProfile     = wlanapi.NewProc("WlanGetProfile")
+	procWlanFreeMemory     = wlanapi.NewProc("WlanFreeMemory")
+)
+
+// wlanList mirrors the header of WLAN_INTERFACE_INFO_LIST and WLAN_PROFILE_INFO_LIST
+type wlanList struct {
+	NumberOfItems uint32
+	Index         uint32
+}
+
+func wlanCall(proc *windows.LazyProc, args ...uintptr) error {
+	if err := proc.Find(); err != nil {
+		// wlanapi.dll is missing on servers without the wireless LAN service
+		return ErrUnsupported
+	}
+	r, _, _ := proc.Call(args...)
+	if r != 0 {
+		return windows.Errno(r)
+	}
+	return nil
+}
+
+// profiles reads the profiles of all wireless interfaces. The keys are returned in plain
+// text when rpc runs as administrator, which it needs for the MEI anyway.
+func profiles() ([]Profile, error) {
+	var version uint32
+	var handle windows.Handle
+	if err := wlanCall(procWlanOpenHandle, wlanClientVersion2, 0, uintptr(unsafe.Pointer(&version)), uintptr(unsafe.Pointer(&handle))); err != nil {
+		return nil, err
+	}
+	defer procWlanCloseHandle.Call(uintptr(handle), 0)
+
+	var interfaces *wlanList
+	if err := wlanCall(procWlanEnumInterfaces, uintptr(handle), 0, uintptr(unsafe.Pointer(&interfaces))); err != nil {
+		return nil, err
+	}
+	defer procWlanFreeMemory.Call(uintptr(unsafe.Pointer(interfaces)))
+
+	var result []Profile
+	seen := map[string]bool{}
+	for i := uint32(0); i < interfaces.NumberOfItems; i++ {
+		// the GUID is the first field of WLAN_INTERFACE_INFO
+		guid := unsafe.Add(unsafe.Pointer(interfaces), wlanListHeaderSize+int(i)*wlanInterfaceInfoSize)
+		names, err := profileNames(handle, guid)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			profile, err := interfaceProfile(handle, guid, name)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, profile)
+		}
+	}
+	return result, nil
+}
+
+func profileNames(handle windows.Handle, guid unsafe.Pointer) ([]string, error) {
+	var list *wlanList
+	if err := wlanCall(procWlanGetProfileList, uintptr(handle), uintptr(guid), 0, uintptr(unsafe.Pointer(&list))); err != nil {
+		return nil, err
+	}
+	defer procWlanFreeMemory.Call(uintptr(unsafe.Pointer(list)))
+	names := make([]string, 0, list.NumberOfItems)
+	for i := uint32(0); i < list.NumberOfItems; i++ {
+		// strProfileName is the first field of WLAN_PROFILE_INFO
+		name := (*[256]uint16)(unsafe.Add(unsafe.Pointer(list), wlanListHeaderSize+int(i)*wlanProfileInfoSize))
+		names = append(names, windows.UTF16ToString(name[:]))
+	}
+	return names, nil
+}
+
+func interfaceProfile(handle windows.Handle, guid unsafe.Pointer, name string) (Profile, error) {
+	profileName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return Profile{}, err
+	}
+	var profileXML *uint16
+	flags := uint32(wlanProfileGetPlaintextKey)
+	var access uint32
+	if err = wlanCall(procWlanGetProfile, uintptr(handle), uintptr(guid), uintptr(unsafe.Pointer(profileName)), 0,
+		uintptr(unsafe.Pointer(&profileXML)), uintptr(unsafe.Pointer(&flags)), uintptr(unsafe.Pointer(&access))); err != nil {
+		return Profile{}, err
+	}
+	defer procWlanFreeMemory.Call(uintptr(unsafe.Pointer(profileXML)))
+	return parseWindowsProfile(windows.UTF16PtrToString(profileXML))
+}
@@ -0,0 +1,127 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package wlan
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// Authentication of a wifi profile, named as in the Windows WLAN profile schema
+const (
+	AuthOpen       = "open"
+	AuthWPAPSK     = "WPAPSK"
+	AuthWPA2PSK    = "WPA2PSK"
+	AuthWPA3SAE    = "WPA3SAE"
+	AuthEnterprise = "WPA2"
+)
+
+// Encryption of a wifi profile
+const (
+	EncryptionNone = "none"
+	EncryptionTKIP = "TKIP"
+	EncryptionAES  = "AES"
+)
+
+var ErrUnsupported = errors.New("reading the wifi profiles is not supported on this OS")
+
+// Profile is a wifi network configured in the host OS
+type Profile struct {
+	Name           string
+	SSID           string
+	Authentication string
+	Encryption     string
+	// Passphrase is the pre-shared key, empty when the OS did not reveal it
+	Passphrase string
+}
+
+// Profiles returns the wifi profiles of the host OS, read through the WLAN API on
+// Windows and from the NetworkManager connections on Linux
+func Profiles() ([]Profile, error) {
+	return profiles()
+}
+
+// windowsProfile is the part of a Windows WLAN profile XML the sync needs
+type windowsProfile struct {
+	Name           string `xml:"name"`
+	SSID           string `xml:"SSIDConfig>SSID>name"`
+	Authentication string `xml:"MSM>security>authEncryption>authentication"`
+	Encryption     string `xml:"MSM>security>authEncryption>encryption"`
+	KeyMaterial    string `xml:"MSM>security>sharedKey>keyMaterial"`
+	Protected      bool   `xml:"MSM>security>sharedKey>protected"`
+}
+
+// parseWindowsProfile reads a profile returned by WlanGetProfile
+func parseWindowsProfile(profileXML string) (Profile, error) {
+	var p windowsProfile
+	if err := xml.Unmarshal([]byte(profileXML), &p); err != nil {
+		return Profile{}, err
+	}
+	profile := Profile{
+		Name:           p.Name,
+		SSID:           p.SSID,
+		Authentication: p.Authentication,
+		Encryption:     p.Encryption,
+	}
+	if profile.SSID == "" {
+		profile.SSID = p.Name
+	}
+	switch profile.Authentication {
+	case "WPA3", "WPA3ENT192", "WPA3ENT":
+		profile.Authentication = AuthEnterprise
+	}
+	if !p.Protected {
+		profile.Passphrase = p.KeyMaterial
+	}
+	return profile, nil
+}
+
+// parseKeyfile reads a NetworkManager keyfile connection, ok is false for other
+// connection types than wifi
+func parseKeyfile(content string) (profile Profile, ok bool) {
+	values := map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.Trim(line, "[]")
+		default:
+			key, value, found := strings.Cut(line, "=")
+			if found {
+				values[section+"."+strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if values["connection.type"] != "wifi" && values["connection.type"] != "802-11-wireless" {
+		return Profile{}, false
+	}
+	profile = Profile{
+		Name:           values["connection.id"],
+		SSID:           values["wifi.ssid"],
+		Authentication: AuthOpen,
+		Encryption:     EncryptionNone,
+		Passphrase:     values["wifi-security.psk"],
+	}
+	switch values["wifi-security.key-mgmt"] {
+	case "wpa-psk":
+		profile.Authentication, profile.Encryption = AuthWPA2PSK, EncryptionAES
+		if values["wifi-security.proto"] == "wpa" {
+			profile.Authentication = AuthWPAPSK
+		}
+		if values["wifi-security.pairwise"] == "tkip" {
+			profile.Encryption = EncryptionTKIP
+		}
+	case "sae":
+		profile.Authentication, profile.Encryption = AuthWPA3SAE, EncryptionAES
+	case "wpa-eap", "wpa-eap-suite-b-192":
+		profile.Authentication, profile.Encryption = AuthEnterprise, EncryptionAES
+	}
+	return profile, true
+}
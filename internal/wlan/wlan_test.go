@@ -0,0 +1,86 @@
+package wlan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const windowsProfileXML = `<?xml version="1.0"?>
+<WLANProfile xmlns="http://www.microsoft.com/networking/WLAN/profile/v1">
+	<name>Office</name>
+	<SSIDConfig>
+		<SSID>
+			<hex>4F66666963652D3547</hex>
+			<name>Office-5G</name>
+		</SSID>
+	</SSIDConfig>
+	<connectionType>ESS</connectionType>
+	<MSM>
+		<security>
+			<authEncryption>
+				<authentication>WPA2PSK</authentication>
+				<encryption>AES</encryption>
+				<useOneX>false</useOneX>
+			</authEncryption>
+			<sharedKey>
+				<keyType>passPhrase</keyType>
+				<protected>false</protected>
+				<keyMaterial>officeP@ss</keyMaterial>
+			</sharedKey>
+		</security>
+	</MSM>
+</WLANProfile>`
+
+func TestParseWindowsProfile(t *testing.T) {
+	profile, err := parseWindowsProfile(windowsProfileXML)
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{Name: "Office", SSID: "Office-5G", Authentication: AuthWPA2PSK, Encryption: EncryptionAES, Passphrase: "officeP@ss"}, profile)
+
+	_, err = parseWindowsProfile("<WLANProfile>")
+	assert.Error(t, err)
+}
+
+func TestParseKeyfile(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    Profile
+		wantOk  bool
+	}{
+		"wpa2 personal": {
+			content: "[connection]\nid=Office\ntype=wifi\n\n[wifi]\nmode=infrastructure\nssid=Office-5G\n\n[wifi-security]\nkey-mgmt=wpa-psk\npsk=officeP@ss\n",
+			want:    Profile{Name: "Office", SSID: "Office-5G", Authentication: AuthWPA2PSK, Encryption: EncryptionAES, Passphrase: "officeP@ss"},
+			wantOk:  true,
+		},
+		"wpa personal with tkip": {
+			content: "[connection]\nid=Lab\ntype=wifi\n[wifi]\nssid=lab\n[wifi-security]\nkey-mgmt=wpa-psk\nproto=wpa\npairwise=tkip\npsk=labP@ss\n",
+			want:    Profile{Name: "Lab", SSID: "lab", Authentication: AuthWPAPSK, Encryption: EncryptionTKIP, Passphrase: "labP@ss"},
+			wantOk:  true,
+		},
+		"wpa3 personal": {
+			content: "[connection]\nid=Home\ntype=802-11-wireless\n[802-11-wireless]\n[wifi]\nssid=home\n[wifi-security]\nkey-mgmt=sae\npsk=homeP@ss\n",
+			want:    Profile{Name: "Home", SSID: "home", Authentication: AuthWPA3SAE, Encryption: EncryptionAES, Passphrase: "homeP@ss"},
+			wantOk:  true,
+		},
+		"enterprise": {
+			content: "[connection]\nid=Corp\ntype=wifi\n[wifi]\nssid=corp\n[wifi-security]\nkey-mgmt=wpa-eap\n",
+			want:    Profile{Name: "Corp", SSID: "corp", Authentication: AuthEnterprise, Encryption: EncryptionAES},
+			wantOk:  true,
+		},
+		"open": {
+			content: "# guest network\n[connection]\nid=Guest\ntype=wifi\n[wifi]\nssid=guest\n",
+			want:    Profile{Name: "Guest", SSID: "guest", Authentication: AuthOpen, Encryption: EncryptionNone},
+			wantOk:  true,
+		},
+		"ethernet": {
+			content: "[connection]\nid=Wired\ntype=ethernet\n",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			profile, ok := parseKeyfile(tc.content)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, profile)
+		})
+	}
+}
@@ -5,15 +5,19 @@
 package apf
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"rpc/internal/logging"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
+var log = logging.For(logging.ModuleLMS)
+
 func Process(data []byte, session *LMESession) bytes.Buffer {
 	var bin_buf bytes.Buffer
 	var dataToSend interface{}
@@ -68,6 +72,9 @@ func ProcessChannelClose(data []byte, session *LMESession) APF_CHANNEL_CLOSE_MES
 	log.Tracef("%+v", closeMessage)
 	// session.DataBuffer <- session.Tempdata
 	// session.Tempdata = []byte{}
+	if len(session.Tempdata) > 0 {
+		signalComplete(session)
+	}
 	close := ChannelClose(closeMessage.RecipientChannel)
 	return close
 }
@@ -128,6 +135,9 @@ func ProcessChannelData(data []byte, session *LMESession) {
 	// 	session.RXWindow = 0
 	// }
 	session.Timer.Reset(3 * time.Second)
+	if responseComplete(session.Tempdata) {
+		signalComplete(session)
+	}
 	// var windowAdjust APF_CHANNEL_WINDOW_ADJUST_MESSAGE
 	// if session.RXWindow > 1024 { // TODO: Check this
 	// 	windowAdjust = ChannelWindowAdjust(channelData.RecipientChannel, session.RXWindow)
@@ -137,6 +147,31 @@ func ProcessChannelData(data []byte, session *LMESession) {
 	// return windowAdjust
 	//return windowAdjust
 }
+
+// responseComplete reports whether data holds a whole HTTP response, whose body length
+// is given by Content-Length or ends with the last chunk
+func responseComplete(data []byte) bool {
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return false
+	}
+	defer response.Body.Close()
+	if response.ContentLength < 0 && len(response.TransferEncoding) == 0 {
+		// the body ends when AMT closes the channel
+		return false
+	}
+	_, err = io.Copy(io.Discard, response.Body)
+	return err == nil
+}
+
+// signalComplete tells the listener that the response can be delivered
+func signalComplete(session *LMESession) {
+	select {
+	case session.Complete <- struct{}{}:
+	default:
+	}
+}
+
 func ProcessServiceRequest(data []byte) APF_SERVICE_ACCEPT_MESSAGE {
 	service := 0
 	message := APF_SERVICE_REQUEST_MESSAGE{}
@@ -199,7 +234,7 @@ func ProcessProtocolVersion(data []byte) APF_PROTOCOL_VERSION_MESSAGE {
 func ServiceAccept(serviceName string) APF_SERVICE_ACCEPT_MESSAGE {
 	log.Debug("sending APF_SERVICE_ACCEPT_MESSAGE")
 	var test [18]byte
-	copy(test[:], []byte(serviceName)[:18])
+	copy(test[:], []byte(serviceName))
 	serviceAcceptMessage := APF_SERVICE_ACCEPT_MESSAGE{
 		MessageType:       APF_SERVICE_ACCEPT,
 		ServiceNameLength: 18,
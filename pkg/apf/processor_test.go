@@ -5,6 +5,8 @@
 package apf
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 	"time"
 
@@ -75,6 +77,52 @@ func TestProcessChannelData(t *testing.T) {
 	ProcessChannelData(data, session)
 
 }
+func TestProcessChannelDataComplete(t *testing.T) {
+	channelData := func(data string) []byte {
+		var message bytes.Buffer
+		binary.Write(&message, binary.BigEndian, uint8(APF_CHANNEL_DATA))
+		binary.Write(&message, binary.BigEndian, uint32(1))
+		binary.Write(&message, binary.BigEndian, uint32(len(data)))
+		message.WriteString(data)
+		return message.Bytes()
+	}
+	session := &LMESession{
+		Timer:    time.NewTimer(time.Minute),
+		Complete: make(chan struct{}, 1),
+	}
+	defer session.Timer.Stop()
+	ProcessChannelData(channelData("HTTP/1.1 200 OK\r\nContent-Length: 5\r\n\r\nhel"), session)
+	assert.Len(t, session.Complete, 0, "the body is not complete")
+	ProcessChannelData(channelData("lo"), session)
+	assert.Len(t, session.Complete, 1, "the response is complete")
+}
+func TestProcessChannelCloseComplete(t *testing.T) {
+	session := &LMESession{Complete: make(chan struct{}, 1)}
+	ProcessChannelClose([]byte{APF_CHANNEL_CLOSE}, session)
+	assert.Len(t, session.Complete, 0, "nothing was received")
+	session.Tempdata = []byte("HTTP/1.1 200 OK\r\n\r\nbody until the channel closes")
+	ProcessChannelClose([]byte{APF_CHANNEL_CLOSE}, session)
+	assert.Len(t, session.Complete, 1)
+}
+func TestResponseComplete(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want bool
+	}{
+		"partial headers":       {"HTTP/1.1 200 OK\r\nContent-Le", false},
+		"partial body":          {"HTTP/1.1 200 OK\r\nContent-Length: 4\r\n\r\nab", false},
+		"whole body":            {"HTTP/1.1 200 OK\r\nContent-Length: 4\r\n\r\nabcd", true},
+		"empty body":            {"HTTP/1.1 401 Unauthorized\r\nContent-Length: 0\r\n\r\n", true},
+		"partial chunked body":  {"HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n4\r\nabcd\r\n", false},
+		"whole chunked body":    {"HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n4\r\nabcd\r\n0\r\n\r\n", true},
+		"body without a length": {"HTTP/1.1 200 OK\r\n\r\nabcd", false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, responseComplete([]byte(tc.data)))
+		})
+	}
+}
 func TestProcessServiceRequestWhenAUTH(t *testing.T) {
 	data := []byte{0x01, 0x00, 0x00, 0x00, 0x12, 0x61, 0x75, 0x74, 0x68, 0x40, 0x61, 0x6d, 0x74, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x6c, 0x2e, 0x63, 0x6f, 0x6d}
 	hi := int(0x12)
@@ -119,6 +167,9 @@ func TestServiceAccept(t *testing.T) {
 	serviceName := ""
 	result := ServiceAccept(serviceName)
 	assert.NotNil(t, result)
+
+	result = ServiceAccept(APF_SERVICE_PFWD)
+	assert.Equal(t, APF_SERVICE_PFWD, string(result.ServiceName[:len(APF_SERVICE_PFWD)]))
 }
 func TestProtocolVersion(t *testing.T) {
 	result := ProtocolVersion(1, 0, 9)
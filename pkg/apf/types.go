@@ -251,4 +251,7 @@ type LMESession struct {
 	ErrorBuffer      chan error
 	Status           chan bool
 	Timer            *time.Timer
+	// Complete is signaled when Tempdata holds a whole HTTP response or AMT closes the
+	// channel, so the response is delivered without waiting for Timer
+	Complete chan struct{}
 }
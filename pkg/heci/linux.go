@@ -10,14 +10,21 @@ package heci
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"rpc/internal/logging"
+	"strings"
 	"syscall"
 	"unsafe"
 
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
 )
 
+var log = logging.For(logging.ModuleAMT)
+
 type Driver struct {
 	meiDevice       *os.File
 	bufferSize      uint32
@@ -25,7 +32,8 @@ type Driver struct {
 }
 
 const (
-	Device                   = "/dev/mei0"
+	Device = "/dev/mei0"
+	// _IOWR('H', 0x01, 16), the encoding is the same on x86 and arm64
 	IOCTL_MEI_CONNECT_CLIENT = 0xC0104801
 )
 
@@ -41,17 +49,55 @@ func NewDriver() *Driver {
 	return &Driver{}
 }
 
+// findDevice returns /dev/mei0, or the first other MEI device on platforms that number it differently
+func findDevice() string {
+	if _, err := os.Stat(Device); err == nil {
+		return Device
+	}
+	if devices, err := filepath.Glob("/dev/mei[0-9]*"); err == nil && len(devices) > 0 {
+		return devices[0]
+	}
+	return Device
+}
+
+// DriverVersion returns the version of the mei_me kernel module, or the kernel release when
+// the module is built into the kernel and reports no version
+func DriverVersion() (string, error) {
+	if !Supported() {
+		return "", ErrUnsupportedPlatform
+	}
+	device := findDevice()
+	if _, err := os.Stat(device); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %w", ErrDriverMissing, err)
+		}
+		return "", err
+	}
+	for _, path := range []string{"/sys/module/mei_me/version", "/sys/module/mei/version"} {
+		if version, err := os.ReadFile(path); err == nil {
+			return strings.TrimSpace(string(version)), nil
+		}
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return "", err
+	}
+	return "kernel " + strings.TrimSpace(string(release)), nil
+}
+
 func (heci *Driver) Init(useLME bool) error {
+	if !Supported() {
+		return ErrUnsupportedPlatform
+	}
 	var err error
-	heci.meiDevice, err = os.OpenFile(Device, syscall.O_RDWR, 0)
+	heci.meiDevice, err = os.OpenFile(findDevice(), syscall.O_RDWR, 0)
 	if err != nil {
-		if err.Error() == "open /dev/mei0: permission denied" {
-			log.Error("need administrator privileges")
-		} else if err.Error() == "open /dev/mei0: no such file or directory" {
-			log.Error("AMT not found: MEI/driver is missing or the call to the HECI driver failed")
-		} else {
-			log.Error("Cannot open MEI Device")
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+		} else if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %w", ErrDriverMissing, err)
 		}
+		log.Error("Cannot open MEI Device")
 		return err
 	}
 
@@ -1,5 +1,35 @@
 package heci
 
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrUnsupportedPlatform is returned by Init where rpc has no MEI driver for the OS or CPU architecture
+var ErrUnsupportedPlatform = errors.New("the MEI driver is not supported on " + runtime.GOOS + "/" + runtime.GOARCH)
+
+// ErrDriverMissing is returned by Init when no MEI device is present, the driver is not installed or Intel ME is disabled
+var ErrDriverMissing = errors.New("no MEI device found")
+
+// ErrAccessDenied is returned by Init when the MEI device is present but rpc lacks the privileges to open it
+var ErrAccessDenied = errors.New("access to the MEI device was denied")
+
+// supportedPlatforms are the OS and architectures with a known MEI driver interface.
+// The driver ioctls and structures are the same on ARM64 as on x86.
+var supportedPlatforms = map[string]bool{
+	"linux/386":     true,
+	"linux/amd64":   true,
+	"linux/arm64":   true,
+	"windows/386":   true,
+	"windows/amd64": true,
+	"windows/arm64": true,
+}
+
+// Supported reports whether rpc can talk to the MEI driver on this platform
+func Supported() bool {
+	return supportedPlatforms[runtime.GOOS+"/"+runtime.GOARCH]
+}
+
 type Interface interface {
 	Init(useLME bool) error
 	GetBufferSize() uint32
@@ -0,0 +1,37 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package heci
+
+// Driver is a placeholder on platforms without an MEI driver, every call fails with ErrUnsupportedPlatform
+type Driver struct{}
+
+func NewDriver() *Driver {
+	return &Driver{}
+}
+
+func DriverVersion() (string, error) {
+	return "", ErrUnsupportedPlatform
+}
+
+func (heci *Driver) Init(useLME bool) error {
+	return ErrUnsupportedPlatform
+}
+
+func (heci *Driver) GetBufferSize() uint32 {
+	return 0
+}
+
+func (heci *Driver) SendMessage(buffer []byte, done *uint32) (bytesWritten uint32, err error) {
+	return 0, ErrUnsupportedPlatform
+}
+
+func (heci *Driver) ReceiveMessage(buffer []byte, done *uint32) (bytesRead uint32, err error) {
+	return 0, ErrUnsupportedPlatform
+}
+
+func (heci *Driver) Close() {}
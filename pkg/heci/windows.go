@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"syscall"
 	"unsafe"
 
@@ -26,6 +27,15 @@ func ctl_code(device_type, function, method, access uint32) uint32 {
 	return (device_type << 16) | (access << 14) | (function << 2) | method
 }
 
+// deviceInterfaceDetailSize is the cbSize of SP_DEVICE_INTERFACE_DETAIL_DATA_W,
+// 8 on amd64 and arm64 and 6 on 386 where the structure is packed
+func deviceInterfaceDetailSize() uint16 {
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		return 6
+	}
+	return 8
+}
+
 type Driver struct {
 	meiDevice  windows.Handle
 	bufferSize uint32
@@ -33,6 +43,7 @@ type Driver struct {
 	PTHIGUID   windows.GUID
 	LMEGUID    windows.GUID
 	useLME     bool
+	version    HeciVersion
 }
 
 type HeciVersion struct {
@@ -50,6 +61,9 @@ func NewDriver() *Driver {
 }
 
 func (heci *Driver) Init(useLME bool) error {
+	if !Supported() {
+		return ErrUnsupportedPlatform
+	}
 	var err error
 	heci.useLME = useLME
 
@@ -84,6 +98,9 @@ func (heci *Driver) FindDevices(guid *windows.GUID) error {
 	interfaceData := setupapi.SpDevInterfaceData{}
 	interfaceData.CbSize = (uint32)(unsafe.Sizeof(interfaceData))
 	edi, err := setupapi.SetupDiEnumDeviceInterfaces(deviceInfo, nil, guid, 0, &interfaceData)
+	if errors.Is(err, windows.ERROR_NO_MORE_ITEMS) {
+		return fmt.Errorf("%w: %w", ErrDriverMissing, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -99,7 +116,7 @@ func (heci *Driver) FindDevices(guid *windows.GUID) error {
 	// 	return errors.New("invalid handle")
 	// }
 	buf := make([]uint16, heci.bufferSize)
-	buf[0] = 8
+	buf[0] = deviceInterfaceDetailSize()
 	err = setupapi.SetupDiGetDeviceInterfaceDetail(deviceInfo, &interfaceData, &buf[0], heci.bufferSize, nil, nil)
 	if err != nil {
 		return err
@@ -122,6 +139,9 @@ func (heci *Driver) FindDevices(guid *windows.GUID) error {
 	}
 	heci.meiDevice, err = windows.CreateFile(&buf[2], windows.GENERIC_READ|windows.GENERIC_WRITE, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, windows.FILE_FLAG_OVERLAPPED, 0)
 
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return fmt.Errorf("%w: %w", ErrAccessDenied, err)
+	}
 	if err != nil {
 		return err
 	}
@@ -157,10 +177,22 @@ func (heci *Driver) GetHeciVersion() error {
 	binary.Read(buf2, binary.LittleEndian, &version.minor)
 	binary.Read(buf2, binary.LittleEndian, &version.hotfix)
 	binary.Read(buf2, binary.LittleEndian, &version.build)
+	heci.version = version
 
 	return nil
 }
 
+// DriverVersion opens the MEI device and returns the version of the HECI driver
+func DriverVersion() (string, error) {
+	heci := NewDriver()
+	if err := heci.Init(false); err != nil {
+		return "", err
+	}
+	defer heci.Close()
+	v := heci.version
+	return fmt.Sprintf("%d.%d.%d.%d", v.major, v.minor, v.hotfix, v.build), nil
+}
+
 func (heci *Driver) ConnectHeciClient() error {
 	properties := MEIConnectClientData{}
 	propertiesPacked := CMEIConnectClientData{}
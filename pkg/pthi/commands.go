@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"rpc/pkg/heci"
 )
 
@@ -22,12 +23,19 @@ type Interface interface {
 	GetCodeVersions() (GetCodeVersionsResponse, error)
 	GetUUID() (uuid string, err error)
 	GetControlMode() (state int, err error)
+	GetProvisioningState() (response GetProvisioningStateResponse, err error)
+	GetProvisioningMode() (response GetProvisioningModeResponse, err error)
 	GetDNSSuffix() (suffix string, err error)
 	GetCertificateHashes(hashHandles AMTHashHandles) (hashEntryList []CertHashEntry, err error)
 	GetRemoteAccessConnectionStatus() (RAStatus GetRemoteAccessConnectionStatusResponse, err error)
 	GetLANInterfaceSettings(useWireless bool) (LANInterface GetLANInterfaceSettingsResponse, err error)
 	GetLocalSystemAccount() (localAccount GetLocalSystemAccountResponse, err error)
 	Unprovision() (mode int, err error)
+	SetDNSSuffix(suffix string) (status int, err error)
+	GetChangeEnabled() (ChangeEnabledResponse, error)
+	GetZeroTouchEnabled() (enabled bool, err error)
+	GetProvisioningTLSMode() (mode int, err error)
+	SetAMTOperationalState(state AMTOperationalState) (status int, err error)
 }
 
 func NewCommand() Command {
@@ -160,10 +168,53 @@ func (pthi Command) GetControlMode() (state int, err error) {
 		Header: readHeaderResponse(buf2),
 	}
 
+	// the state reported with a failed command is not a control mode
+	if response.Header.Status != AMT_STATUS_SUCCESS {
+		return -1, fmt.Errorf("GetControlMode returned the AMT status %d", response.Header.Status)
+	}
 	binary.Read(buf2, binary.LittleEndian, &response.State)
 	return int(response.State), nil
 }
 
+func (pthi Command) GetProvisioningState() (response GetProvisioningStateResponse, err error) {
+	command := GetRequest{
+		Header: CreateRequestHeader(PROVISIONING_STATE_REQUEST, 0),
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), GET_REQUEST_SIZE)
+	if err != nil {
+		return GetProvisioningStateResponse{}, err
+	}
+	buf2 := bytes.NewBuffer(result)
+	response = GetProvisioningStateResponse{
+		Header: readHeaderResponse(buf2),
+	}
+
+	binary.Read(buf2, binary.LittleEndian, &response.ProvisioningState)
+	return response, nil
+}
+
+func (pthi Command) GetProvisioningMode() (response GetProvisioningModeResponse, err error) {
+	command := GetRequest{
+		Header: CreateRequestHeader(PROVISIONING_MODE_REQUEST, 0),
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), GET_REQUEST_SIZE)
+	if err != nil {
+		return GetProvisioningModeResponse{}, err
+	}
+	buf2 := bytes.NewBuffer(result)
+	response = GetProvisioningModeResponse{
+		Header: readHeaderResponse(buf2),
+	}
+
+	binary.Read(buf2, binary.LittleEndian, &response.ProvisioningMode)
+	binary.Read(buf2, binary.LittleEndian, &response.LegacyMode)
+	return response, nil
+}
+
 func (pthi Command) Unprovision() (state int, err error) {
 	command := UnprovisionRequest{
 		Header: CreateRequestHeader(UNPROVISION_REQUEST, 4),
@@ -180,6 +231,10 @@ func (pthi Command) Unprovision() (state int, err error) {
 		Header: readHeaderResponse(buf2),
 	}
 
+	// the status of the command takes precedence over the state reported with it
+	if response.Header.Status != AMT_STATUS_SUCCESS {
+		return int(response.Header.Status), nil
+	}
 	binary.Read(buf2, binary.LittleEndian, &response.State)
 	return int(response.State), nil
 }
@@ -222,6 +277,117 @@ func (pthi Command) GetDNSSuffix() (suffix string, err error) {
 	return "", nil
 }
 
+// SetDNSSuffix sets the PKI DNS suffix that AMT matches against the provisioning
+// certificate. It returns the AMT status, AMT rejects the change once the device is provisioned.
+func (pthi Command) SetDNSSuffix(suffix string) (status int, err error) {
+	if len(suffix) > len(AMTANSIString{}.Buffer) {
+		return -1, errors.New("dns suffix is too long")
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, CreateRequestHeader(SET_DNS_SUFFIX_REQUEST, uint32(2+len(suffix))))
+	binary.Write(&bin_buf, binary.LittleEndian, uint16(len(suffix)))
+	bin_buf.WriteString(suffix)
+	result, err := pthi.Call(bin_buf.Bytes(), uint32(bin_buf.Len()))
+	if err != nil {
+		return -1, err
+	}
+	response := SetDNSSuffixResponse{
+		Header: readHeaderResponse(bytes.NewBuffer(result)),
+	}
+	return int(response.Header.Status), nil
+}
+
+// GetChangeEnabled reads whether AMT is enabled and whether the BIOS allows the OS to change it
+func (pthi Command) GetChangeEnabled() (ChangeEnabledResponse, error) {
+	command := StateIndependenceHeader{
+		Command:       STATE_INDEPENDENCE_COMMAND,
+		ByteCount:     2,
+		SubCommand:    IS_CHANGE_TO_AMT_ENABLED_SUBCOMMAND,
+		VersionNumber: STATE_INDEPENDENCE_VERSION,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), uint32(bin_buf.Len()))
+	if err != nil {
+		return 0, err
+	}
+	if len(result) == 0 {
+		return 0, errors.New("empty response to IsChangeToAMTEnabled")
+	}
+	return ChangeEnabledResponse(result[0]), nil
+}
+
+// GetZeroTouchEnabled reads whether remote configuration, the ACM activation with a
+// provisioning certificate, is enabled in the MEBx
+func (pthi Command) GetZeroTouchEnabled() (enabled bool, err error) {
+	command := GetRequest{
+		Header: CreateRequestHeader(GET_ZERO_TOUCH_ENABLED_REQUEST, 0),
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), GET_REQUEST_SIZE)
+	if err != nil {
+		return false, err
+	}
+	buf2 := bytes.NewBuffer(result)
+	response := GetZeroTouchEnabledResponse{
+		Header: readHeaderResponse(buf2),
+	}
+	if response.Header.Status != AMT_STATUS_SUCCESS {
+		return false, fmt.Errorf("GetZeroTouchEnabled returned the AMT status %d", response.Header.Status)
+	}
+	binary.Read(buf2, binary.LittleEndian, &response.Enabled)
+	return response.Enabled != 0, nil
+}
+
+// GetProvisioningTLSMode reads whether AMT is provisioned with a pre-shared key or a
+// provisioning certificate, one of the PROVISIONING_TLS_MODE values
+func (pthi Command) GetProvisioningTLSMode() (mode int, err error) {
+	command := GetRequest{
+		Header: CreateRequestHeader(GET_PROVISIONING_TLS_MODE_REQUEST, 0),
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), GET_REQUEST_SIZE)
+	if err != nil {
+		return -1, err
+	}
+	buf2 := bytes.NewBuffer(result)
+	response := GetProvisioningTLSModeResponse{
+		Header: readHeaderResponse(buf2),
+	}
+	if response.Header.Status != AMT_STATUS_SUCCESS {
+		return -1, fmt.Errorf("GetProvisioningTLSMode returned the AMT status %d", response.Header.Status)
+	}
+	binary.Read(buf2, binary.LittleEndian, &response.TLSMode)
+	return int(response.TLSMode), nil
+}
+
+// SetAMTOperationalState enables or disables AMT and returns the AMT status. The firmware
+// only accepts it when GetChangeEnabled reports the transition allowed and the new interface.
+func (pthi Command) SetAMTOperationalState(state AMTOperationalState) (status int, err error) {
+	command := SetAMTOperationalStateRequest{
+		Header: StateIndependenceHeader{
+			Command:       STATE_INDEPENDENCE_COMMAND,
+			ByteCount:     3,
+			SubCommand:    SET_AMT_OPERATIONAL_STATE_SUBCOMMAND,
+			VersionNumber: STATE_INDEPENDENCE_VERSION,
+		},
+		State: state,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, command)
+	result, err := pthi.Call(bin_buf.Bytes(), uint32(bin_buf.Len()))
+	if err != nil {
+		return -1, err
+	}
+	response := SetAMTOperationalStateResponse{}
+	if err = binary.Read(bytes.NewBuffer(result), binary.LittleEndian, &response); err != nil {
+		return -1, err
+	}
+	return int(response.Status), nil
+}
+
 func (pthi Command) enumerateHashHandles() (AMTHashHandles, error) {
 	// Enumerate a list of hash handles to request from
 	enumerateCommand := GetRequest{
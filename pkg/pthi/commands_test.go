@@ -86,6 +86,44 @@ func TestGetControlMode(t *testing.T) {
 	result, err := pthi.GetControlMode()
 	assert.NoError(t, err)
 	assert.Equal(t, 3, result)
+
+	prepareMessage.Header.Status = AMT_STATUS_NOT_PERMITTED
+	bin_buf.Reset()
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+	result, err = pthi.GetControlMode()
+	assert.ErrorContains(t, err, "AMT status")
+	assert.Equal(t, -1, result)
+}
+
+func TestGetProvisioningState(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE
+	prepareMessage := GetProvisioningStateResponse{
+		Header:            ResponseMessageHeader{},
+		ProvisioningState: 2,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.GetProvisioningState()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), result.ProvisioningState)
+}
+
+func TestGetProvisioningMode(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE
+	prepareMessage := GetProvisioningModeResponse{
+		Header:           ResponseMessageHeader{},
+		ProvisioningMode: 3,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.GetProvisioningMode()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), result.ProvisioningMode)
 }
 
 func TestUnprovision(t *testing.T) {
@@ -101,6 +139,21 @@ func TestUnprovision(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, result)
 }
+
+func TestUnprovisionNotPermitted(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE + 4
+	prepareMessage := UnprovisionResponse{
+		Header: ResponseMessageHeader{},
+	}
+	prepareMessage.Header.Status = AMT_STATUS_NOT_PERMITTED
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.Unprovision()
+	assert.NoError(t, err)
+	assert.Equal(t, AMT_STATUS_NOT_PERMITTED, result)
+}
 func TestGetCodeVersions(t *testing.T) {
 
 	numBytes = GET_REQUEST_SIZE
@@ -141,6 +194,65 @@ func TestGetDNSSuffix(t *testing.T) {
 	assert.Equal(t, "\x01\x02\x03\x04", result)
 }
 
+func TestSetDNSSuffix(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE + 2 + uint32(len("corp.example.com"))
+	prepareMessage := SetDNSSuffixResponse{
+		Header: ResponseMessageHeader{},
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.SetDNSSuffix("corp.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, AMT_STATUS_SUCCESS, result)
+
+	prepareMessage.Header.Status = AMT_STATUS_INVALID_PT_MODE
+	bin_buf.Reset()
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+	result, err = pthi.SetDNSSuffix("corp.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, AMT_STATUS_INVALID_PT_MODE, result)
+}
+
+func TestGetChangeEnabled(t *testing.T) {
+	numBytes = 4
+	message = []byte{0x83}
+	result, err := pthi.GetChangeEnabled()
+	assert.NoError(t, err)
+	assert.True(t, result.IsTransitionAllowed())
+	assert.True(t, result.IsAMTEnabled())
+	assert.True(t, result.IsNewInterfaceVersion())
+
+	message = []byte{0x00}
+	result, err = pthi.GetChangeEnabled()
+	assert.NoError(t, err)
+	assert.False(t, result.IsTransitionAllowed())
+	assert.False(t, result.IsAMTEnabled())
+}
+
+func TestSetAMTOperationalState(t *testing.T) {
+	numBytes = 5
+	prepareMessage := SetAMTOperationalStateResponse{
+		Header: StateIndependenceHeader{Command: STATE_INDEPENDENCE_COMMAND, SubCommand: SET_AMT_OPERATIONAL_STATE_SUBCOMMAND},
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+	result, err := pthi.SetAMTOperationalState(AMT_OPERATIONAL_STATE_ENABLED)
+	assert.NoError(t, err)
+	assert.Equal(t, AMT_STATUS_SUCCESS, result)
+
+	prepareMessage.Status = AMT_STATUS_NOT_PERMITTED
+	bin_buf.Reset()
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+	result, err = pthi.SetAMTOperationalState(AMT_OPERATIONAL_STATE_DISABLED)
+	assert.NoError(t, err)
+	assert.Equal(t, AMT_STATUS_NOT_PERMITTED, result)
+}
+
 func TestEnumerateHashHandles(t *testing.T) {
 	numBytes = GET_REQUEST_SIZE
 	prepareMessage := GetHashHandlesResponse{
@@ -258,3 +370,40 @@ func TestGetLocalSystemAccount(t *testing.T) {
 	assert.Equal(t, result.Account.Password, [CFG_MAX_ACL_USER_LENGTH]uint8{8, 7, 6, 5})
 
 }
+
+func TestGetZeroTouchEnabled(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE
+	prepareMessage := GetZeroTouchEnabledResponse{
+		Header:  ResponseMessageHeader{},
+		Enabled: 1,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.GetZeroTouchEnabled()
+	assert.NoError(t, err)
+	assert.True(t, result)
+
+	prepareMessage.Header.Status = AMT_STATUS_NOT_PERMITTED
+	bin_buf.Reset()
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+	_, err = pthi.GetZeroTouchEnabled()
+	assert.Error(t, err)
+}
+
+func TestGetProvisioningTLSMode(t *testing.T) {
+	numBytes = GET_REQUEST_SIZE
+	prepareMessage := GetProvisioningTLSModeResponse{
+		Header:  ResponseMessageHeader{},
+		TLSMode: PROVISIONING_TLS_MODE_PKI,
+	}
+	var bin_buf bytes.Buffer
+	binary.Write(&bin_buf, binary.LittleEndian, prepareMessage)
+	message = bin_buf.Bytes()
+
+	result, err := pthi.GetProvisioningTLSMode()
+	assert.NoError(t, err)
+	assert.Equal(t, PROVISIONING_TLS_MODE_PKI, result)
+}
@@ -19,6 +19,15 @@ const BIOS_VERSION_LEN = 65
 const VERSIONS_NUMBER = 50
 const UNICODE_STRING_LEN = 20
 
+const AMT_STATUS_SUCCESS = 0
+
+// AMT_STATUS_INVALID_PT_MODE is returned when AMT is disabled in the MEBx
+const AMT_STATUS_INVALID_PT_MODE = 3
+
+// AMT_STATUS_NOT_PERMITTED is returned when the firmware does not allow the command over
+// the MEI, e.g. unprovision of devices that must be unprovisioned with the admin password
+const AMT_STATUS_NOT_PERMITTED = 0x10
+
 const CFG_MAX_ACL_USER_LENGTH = 33
 const CFG_MAX_ACL_PWD_LENGTH = 33
 
@@ -187,6 +196,34 @@ type GetControlModeResponse struct {
 	State  uint32
 }
 
+type GetProvisioningStateResponse struct {
+	Header            ResponseMessageHeader
+	ProvisioningState uint32
+}
+
+type GetProvisioningModeResponse struct {
+	Header           ResponseMessageHeader
+	ProvisioningMode uint32
+	LegacyMode       uint8
+}
+
+type GetZeroTouchEnabledResponse struct {
+	Header  ResponseMessageHeader
+	Enabled uint8
+}
+
+// provisioning TLS modes of GetProvisioningTLSMode
+const (
+	PROVISIONING_TLS_MODE_NOT_READY = 0
+	PROVISIONING_TLS_MODE_PSK       = 1
+	PROVISIONING_TLS_MODE_PKI       = 2
+)
+
+type GetProvisioningTLSModeResponse struct {
+	Header  ResponseMessageHeader
+	TLSMode uint32
+}
+
 type UnprovisionRequest struct {
 	Header MessageHeader
 	Mode   uint32
@@ -197,6 +234,59 @@ type UnprovisionResponse struct {
 	State  uint32
 }
 
+type SetDNSSuffixResponse struct {
+	Header ResponseMessageHeader
+}
+
+// The state independence messages of the MEI change whether AMT is enabled from the OS,
+// they have their own 4 byte header instead of the PTHI MessageHeader
+const STATE_INDEPENDENCE_COMMAND = 0x05
+const STATE_INDEPENDENCE_VERSION = 0x10
+const IS_CHANGE_TO_AMT_ENABLED_SUBCOMMAND = 0x51
+const SET_AMT_OPERATIONAL_STATE_SUBCOMMAND = 0x53
+
+type AMTOperationalState uint8
+
+const (
+	AMT_OPERATIONAL_STATE_DISABLED AMTOperationalState = 0
+	AMT_OPERATIONAL_STATE_ENABLED  AMTOperationalState = 1
+)
+
+type StateIndependenceHeader struct {
+	Command       uint8
+	ByteCount     uint8
+	SubCommand    uint8
+	VersionNumber uint8
+}
+
+// ChangeEnabledResponse holds the bits of IsChangeToAMTEnabled
+type ChangeEnabledResponse uint8
+
+// IsTransitionAllowed reports whether the BIOS lets the OS enable or disable AMT
+func (r ChangeEnabledResponse) IsTransitionAllowed() bool {
+	return r&0x01 != 0
+}
+
+// IsAMTEnabled reports whether AMT is enabled
+func (r ChangeEnabledResponse) IsAMTEnabled() bool {
+	return r&0x02 != 0
+}
+
+// IsNewInterfaceVersion reports whether the firmware has the interface that sets the operational state
+func (r ChangeEnabledResponse) IsNewInterfaceVersion() bool {
+	return r&0x80 != 0
+}
+
+type SetAMTOperationalStateRequest struct {
+	Header StateIndependenceHeader
+	State  AMTOperationalState
+}
+
+type SetAMTOperationalStateResponse struct {
+	Header StateIndependenceHeader
+	Status uint32
+}
+
 type LocalSystemAccount struct {
 	Username [CFG_MAX_ACL_USER_LENGTH]uint8
 	Password [CFG_MAX_ACL_USER_LENGTH]uint8
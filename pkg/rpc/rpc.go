@@ -0,0 +1,363 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package rpc lets other Go programs run the RPC commands in process
+// instead of executing the rpc binary. The fields of a request are the
+// flags of the command, they are validated with the same rules as the
+// command line, but the environment and the defaults file are not read
+// and a missing value fails instead of being prompted for. Commands run
+// one at a time, a call that is cancelled returns once its command stopped.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"rpc/internal/amt"
+	"rpc/internal/flags"
+	"rpc/internal/info"
+	"rpc/internal/local"
+	"rpc/internal/logging"
+	"rpc/internal/rps"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"sync"
+)
+
+type (
+	AMTFeatures        = info.AMTFeatures
+	OperationalState   = amt.OperationalState
+	RemoteAccessStatus = amt.RemoteAccessStatus
+	RemoteAccessInfo   = local.RemoteAccessInfo
+	InterfaceSettings  = amt.InterfaceSettings
+	CertHashEntry      = amt.CertHashEntry
+	CertHashInfo       = info.CertHashInfo
+	PublicKeyCertInfo  = local.PublicKeyCertInfo
+	AuditLog           = local.AuditLog
+	AuditLogRecord     = local.AuditLogRecord
+	HardwareInfo       = info.HardwareInfo
+	BIOSInfo           = info.BIOSInfo
+	FirmwareInfo       = info.FirmwareInfo
+	SystemInfo         = info.SystemInfo
+	RedirectionState   = local.RedirectionState
+)
+
+// Error reports the return code, its stable name and the cause of a failed command
+type Error = rpcerr.Error
+
+// Result holds the return code of a completed command
+type Result struct {
+	ReturnCode utils.ReturnCode
+}
+
+// ConnectionOptions are shared by the commands that talk to the server
+type ConnectionOptions struct {
+	// URL may list several servers separated by commas, they are tried in order or, with
+	// ServerOrder "latency", fastest first
+	URL           string
+	ServerOrder   string
+	SkipCertCheck bool
+	// CACert is a PEM file of private CAs, PinSHA256 pins the server public key
+	CACert    string
+	PinSHA256 string
+	Proxy     string
+	Token     string
+	TenantID  string
+	// Tags are key=value metadata sent to the server with the request
+	Tags     map[string]string
+	Password string
+	Force    bool
+}
+
+// apply fills in the flags of the server connection
+func (o ConnectionOptions) apply(f *flags.Flags) {
+	f.URL = o.URL
+	f.ServerOrder = o.ServerOrder
+	f.SkipCertCheck = o.SkipCertCheck
+	f.ServerTLS.CACertFile = o.CACert
+	f.ServerTLS.PinSHA256 = o.PinSHA256
+	f.Proxy = o.Proxy
+	f.Token = o.Token
+	f.TenantID = o.TenantID
+	f.Tags = o.Tags
+	f.Password = o.Password
+}
+
+type ActivateRequest struct {
+	ConnectionOptions
+	Profile      string
+	DNS          string
+	Hostname     string
+	FriendlyName string
+	UUID         string
+	// Local activation
+	Local               bool
+	UseCCM              bool
+	UseACM              bool
+	ConfigFile          string
+	ProvisioningCert    string
+	ProvisioningCertPwd string
+	// MEBxPassword is set after a successful local ACM activation
+	MEBxPassword string
+}
+
+func (r ActivateRequest) flags(ctx context.Context) *flags.Flags {
+	f := flags.NewRequest(ctx, utils.CommandActivate, "")
+	r.ConnectionOptions.apply(f)
+	f.Profile = r.Profile
+	f.DNS = r.DNS
+	f.Hostname = r.Hostname
+	f.FriendlyName = r.FriendlyName
+	f.UUID = r.UUID
+	f.Local = r.Local
+	f.UseCCM = r.UseCCM
+	f.UseACM = r.UseACM
+	f.SetConfigFile(r.ConfigFile)
+	f.LocalConfig.ACMSettings.ProvisioningCert = r.ProvisioningCert
+	f.LocalConfig.ACMSettings.ProvisioningCertPwd = r.ProvisioningCertPwd
+	f.MEBxPassword = r.MEBxPassword
+	return f
+}
+
+// DeactivateRequest deactivates AMT. Force also skips the confirmation rpc asks for in ACM
+// or while CIRA is connected, without it the deactivation of such a device fails with
+// InvalidUserInput.
+type DeactivateRequest struct {
+	ConnectionOptions
+	Local   bool
+	Partial bool
+	// Wipe removes the stored configuration and credentials before unprovisioning
+	Wipe bool
+	// Reason is logged and sent to the server
+	Reason string
+}
+
+func (r DeactivateRequest) flags(ctx context.Context) *flags.Flags {
+	f := flags.NewRequest(ctx, utils.CommandDeactivate, "")
+	r.ConnectionOptions.apply(f)
+	f.Force = r.Force
+	f.Local = r.Local
+	f.PartialDeactivate = r.Partial
+	f.WipeStorage = r.Wipe
+	f.Deactivate.Reason = r.Reason
+	return f
+}
+
+// MaintenanceRequest runs one of the maintenance tasks, e.g. utils.SubCommandSyncClock
+type MaintenanceRequest struct {
+	ConnectionOptions
+	Task string
+	// Tasks runs several tasks over one server connection instead of Task
+	Tasks []string
+	// Task specific options
+	NTPServer      string
+	StaticPassword string
+	DNSSuffix      string
+	StaticIP       string
+	Netmask        string
+	Gateway        string
+	PrimaryDNS     string
+	SecondaryDNS   string
+}
+
+func (r MaintenanceRequest) flags(ctx context.Context) *flags.Flags {
+	task := r.Task
+	if len(r.Tasks) > 0 {
+		task = ""
+	}
+	f := flags.NewRequest(ctx, utils.CommandMaintenance, task)
+	r.ConnectionOptions.apply(f)
+	f.Force = r.Force
+	if len(r.Tasks) > 0 {
+		f.MaintenanceTasks = append([]string(nil), r.Tasks...)
+	}
+	f.NTPServer = r.NTPServer
+	f.StaticPassword = r.StaticPassword
+	f.DNS = r.DNSSuffix
+	f.IpConfiguration.IpAddress = r.StaticIP
+	f.IpConfiguration.Netmask = r.Netmask
+	f.IpConfiguration.Gateway = r.Gateway
+	f.IpConfiguration.PrimaryDns = r.PrimaryDNS
+	f.IpConfiguration.SecondaryDns = r.SecondaryDNS
+	return f
+}
+
+// InfoRequest selects the values reported by Info. All values except
+// certificates are reported when nothing is selected.
+type InfoRequest struct {
+	All      bool
+	Version  bool
+	Build    bool
+	SKU      bool
+	UUID     bool
+	Mode     bool
+	OpState  bool
+	DNS      bool
+	Hostname bool
+	RAS      bool
+	LAN      bool
+	Hardware bool
+	BIOS     bool
+	// System reports the host OS, the rpc version and whether LMS or the AMT of -host
+	// accepts connections
+	System bool
+	Cert   bool
+	// CertWarnOnly limits Cert to the hashes of deprecated CAs
+	CertWarnOnly bool
+	UserCert     bool
+	Audit        bool
+	// Redirection reports the KVM, SOL and IDE-R redirection state, it needs the password
+	Redirection bool
+	// paging of the audit log records, a count of 0 reads all records
+	AuditCount  int
+	AuditOffset int
+	Password    string
+}
+
+func (r InfoRequest) flags(ctx context.Context) *flags.Flags {
+	f := flags.NewRequest(ctx, utils.CommandAMTInfo, "")
+	f.JsonOutput = true
+	f.Password = r.Password
+	f.AmtInfo.All = r.All
+	f.AmtInfo.Ver = r.Version
+	f.AmtInfo.Bld = r.Build
+	f.AmtInfo.Sku = r.SKU
+	f.AmtInfo.UUID = r.UUID
+	f.AmtInfo.Mode = r.Mode
+	f.AmtInfo.OpState = r.OpState
+	f.AmtInfo.DNS = r.DNS
+	f.AmtInfo.Hostname = r.Hostname
+	f.AmtInfo.Ras = r.RAS
+	f.AmtInfo.Lan = r.LAN
+	f.AmtInfo.Hardware = r.Hardware
+	f.AmtInfo.BIOS = r.BIOS
+	f.AmtInfo.Sys = r.System
+	f.AmtInfo.Cert = r.Cert
+	f.AmtInfo.CertWarnOnly = r.CertWarnOnly
+	f.AmtInfo.UserCert = r.UserCert
+	f.AmtInfo.Audit = r.Audit
+	f.AmtInfo.Redirection = r.Redirection
+	f.AmtInfo.AuditCount = r.AuditCount
+	f.AmtInfo.AuditOffset = r.AuditOffset
+	return f
+}
+
+// InfoResponse holds the values selected in the InfoRequest
+type InfoResponse struct {
+	Version           string                       `json:"amt,omitempty"`
+	BuildNumber       string                       `json:"buildNumber,omitempty"`
+	SKU               string                       `json:"sku,omitempty"`
+	Features          *AMTFeatures                 `json:"features,omitempty"`
+	UUID              string                       `json:"uuid,omitempty"`
+	ControlMode       string                       `json:"controlMode,omitempty"`
+	OperationalState  *OperationalState            `json:"operationalState,omitempty"`
+	DNSSuffix         string                       `json:"dnsSuffix,omitempty"`
+	DNSSuffixOS       string                       `json:"dnsSuffixOS,omitempty"`
+	HostnameOS        string                       `json:"hostnameOS,omitempty"`
+	RAS               *RemoteAccessInfo            `json:"ras,omitempty"`
+	WiredAdapter      *InterfaceSettings           `json:"wiredAdapter,omitempty"`
+	WirelessAdapter   *InterfaceSettings           `json:"wirelessAdapter,omitempty"`
+	Hardware          *HardwareInfo                `json:"hardware,omitempty"`
+	BIOS              *BIOSInfo                    `json:"bios,omitempty"`
+	Firmware          *FirmwareInfo                `json:"firmware,omitempty"`
+	System            *SystemInfo                  `json:"system,omitempty"`
+	CertificateHashes []CertHashInfo               `json:"certificateHashes,omitempty"`
+	PublicKeyCerts    map[string]PublicKeyCertInfo `json:"publicKeyCerts,omitempty"`
+	AuditLog          *AuditLog                    `json:"auditLog,omitempty"`
+	Redirection       *RedirectionState            `json:"redirection,omitempty"`
+}
+
+// CheckAccess verifies the MEI driver is present and AMT can be reached
+func CheckAccess() error {
+	return amt.NewAMTCommand().Initialize()
+}
+
+func Activate(ctx context.Context, req ActivateRequest) (Result, error) {
+	if req.Local && req.Password == "" {
+		return failed(rpcerr.New(utils.MissingOrIncorrectPassword, "the AMT password is required"))
+	}
+	return run(ctx, req.flags(ctx))
+}
+
+func Deactivate(ctx context.Context, req DeactivateRequest) (Result, error) {
+	if req.Password == "" {
+		return failed(rpcerr.New(utils.MissingOrIncorrectPassword, "the AMT password is required"))
+	}
+	return run(ctx, req.flags(ctx))
+}
+
+func Maintenance(ctx context.Context, req MaintenanceRequest) (Result, error) {
+	if req.Password == "" {
+		return failed(rpcerr.New(utils.MissingOrIncorrectPassword, "the AMT password is required"))
+	}
+	return run(ctx, req.flags(ctx))
+}
+
+func Info(ctx context.Context, req InfoRequest) (InfoResponse, error) {
+	var resp InfoResponse
+	if (req.UserCert || req.Audit || req.Redirection) && req.Password == "" {
+		_, err := failed(rpcerr.New(utils.MissingOrIncorrectPassword, "the AMT password is required"))
+		return resp, err
+	}
+	var out bytes.Buffer
+	if _, err := runTo(ctx, req.flags(ctx), &out); err != nil {
+		return resp, err
+	}
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// execute validates the flags of the request and runs the command, it is replaced in tests
+var execute = func(f *flags.Flags, out io.Writer) error {
+	err := f.Validate()
+	logging.Redact(f.Secrets()...)
+	if err != nil {
+		return err
+	}
+	if len(f.MaintenanceTasks) > 0 {
+		return rpcerr.FromReturnCode(rps.ExecuteBatchTo(f, out))
+	}
+	if f.Local {
+		return rpcerr.FromReturnCode(local.ExecuteCommandTo(f, out))
+	}
+	return rpcerr.FromReturnCode(rps.ExecuteCommandTo(f, out))
+}
+
+// commandMu runs one command at a time, they share the MEI of the device
+var commandMu sync.Mutex
+
+func run(ctx context.Context, f *flags.Flags) (Result, error) {
+	return runTo(ctx, f, io.Discard)
+}
+
+// runTo executes the command until it completes. Cancelling the context stops the MEI
+// commands and the WSMAN messages not sent yet, what the command added to AMT is rolled back
+// before runTo returns, so AMT is not changed by the command anymore once it did.
+func runTo(ctx context.Context, f *flags.Flags, out io.Writer) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	commandMu.Lock()
+	defer commandMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	var buf bytes.Buffer
+	if err := execute(f, &buf); err != nil {
+		if ctx.Err() != nil {
+			return Result{ReturnCode: utils.CancelledByUser}, ctx.Err()
+		}
+		return failed(err)
+	}
+	_, err := out.Write(buf.Bytes())
+	return Result{ReturnCode: utils.Success}, err
+}
+
+func failed(err error) (Result, error) {
+	return Result{ReturnCode: rpcerr.ReturnCodeOf(err)}, err
+}
@@ -0,0 +1,243 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rpc
+
+import (
+	"context"
+	"io"
+	"rpc/internal/flags"
+	"rpc/pkg/rpcerr"
+	"rpc/pkg/utils"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockExecute(t *testing.T, rc utils.ReturnCode, output string) **flags.Flags {
+	var got *flags.Flags
+	original := execute
+	execute = func(f *flags.Flags, out io.Writer) error {
+		got = f
+		_, _ = out.Write([]byte(output))
+		return rpcerr.FromReturnCode(rc)
+	}
+	t.Cleanup(func() { execute = original })
+	return &got
+}
+
+func TestActivate(t *testing.T) {
+	t.Run("fills in the flags of remote activation", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, "")
+		req := ActivateRequest{Profile: "profile01", DNS: "test.com"}
+		req.URL = "wss://localhost"
+		req.SkipCertCheck = true
+		result, err := Activate(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.Success, result.ReturnCode)
+		f := *got
+		assert.Equal(t, utils.CommandActivate, f.Command)
+		assert.Equal(t, "wss://localhost", f.URL)
+		assert.True(t, f.SkipCertCheck)
+		assert.Equal(t, "profile01", f.Profile)
+		assert.Equal(t, "test.com", f.DNS)
+		assert.False(t, f.Local)
+		assert.Equal(t, 3, f.Retries, "the flags not in the request have the defaults of the command line")
+		assert.True(t, f.NonInteractive)
+	})
+	t.Run("passes tenant and tags", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, "")
+		req := ActivateRequest{Profile: "profile01"}
+		req.URL = "wss://localhost"
+		req.TenantID = "tenant01"
+		req.Tags = map[string]string{"site": "berlin", "rack": "r12"}
+		_, err := Activate(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "tenant01", (*got).TenantID)
+		assert.Equal(t, map[string]string{"site": "berlin", "rack": "r12"}, (*got).Tags)
+	})
+	t.Run("requires password for local activation", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, "")
+		_, err := Activate(context.Background(), ActivateRequest{Local: true, UseCCM: true})
+		assert.ErrorIs(t, err, rpcerr.New(utils.MissingOrIncorrectPassword, ""))
+		assert.Nil(t, *got)
+	})
+	t.Run("returns error with return code", func(t *testing.T) {
+		mockExecute(t, utils.ActivationFailed, "")
+		req := ActivateRequest{Local: true, UseCCM: true}
+		req.Password = "P@ssw0rd"
+		result, err := Activate(context.Background(), req)
+		assert.Equal(t, utils.ActivationFailed, result.ReturnCode)
+		assert.EqualError(t, err, "rpc failed with return code 102 (ActivationFailed)")
+	})
+}
+
+func TestDeactivate(t *testing.T) {
+	got := mockExecute(t, utils.Success, "")
+	req := DeactivateRequest{Partial: true}
+	req.Password = "P@ssw0rd"
+	_, err := Deactivate(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.CommandDeactivate, (*got).Command)
+	assert.Equal(t, "P@ssw0rd", (*got).Password)
+	assert.True(t, (*got).PartialDeactivate)
+
+	req = DeactivateRequest{Wipe: true}
+	req.Password = "P@ssw0rd"
+	_, err = Deactivate(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, (*got).WipeStorage)
+	assert.False(t, (*got).PartialDeactivate)
+
+	req = DeactivateRequest{Local: true, Reason: "decommissioned"}
+	req.Password = "P@ssw0rd"
+	req.Force = true
+	_, err = Deactivate(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, (*got).Local)
+	assert.True(t, (*got).Force)
+	assert.Equal(t, "decommissioned", (*got).Deactivate.Reason)
+}
+
+func TestMaintenance(t *testing.T) {
+	got := mockExecute(t, utils.Success, "")
+	req := MaintenanceRequest{Task: utils.SubCommandSyncClock, NTPServer: "pool.ntp.org"}
+	req.URL = "wss://localhost"
+	req.Password = "P@ssw0rd"
+	req.Force = true
+	_, err := Maintenance(context.Background(), req)
+	assert.NoError(t, err)
+	f := *got
+	assert.Equal(t, utils.CommandMaintenance, f.Command)
+	assert.Equal(t, utils.SubCommandSyncClock, f.SubCommand)
+	assert.Equal(t, "wss://localhost", f.URL)
+	assert.True(t, f.Force)
+	assert.Equal(t, "pool.ntp.org", f.NTPServer)
+	assert.Nil(t, f.MaintenanceTasks)
+}
+
+func TestMaintenanceTasks(t *testing.T) {
+	got := mockExecute(t, utils.Success, "")
+	req := MaintenanceRequest{Tasks: []string{utils.SubCommandSyncClock, utils.SubCommandSyncIP}}
+	req.URL = "wss://localhost"
+	req.Password = "P@ssw0rd"
+	_, err := Maintenance(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "", (*got).SubCommand)
+	assert.Equal(t, []string{utils.SubCommandSyncClock, utils.SubCommandSyncIP}, (*got).MaintenanceTasks)
+}
+
+func TestInfo(t *testing.T) {
+	t.Run("selects defaults when nothing is requested", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, `{"amt":"16.1.25","controlMode":"activated in client control mode","ras":{"networkStatus":"direct"}}`)
+		resp, err := Info(context.Background(), InfoRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, utils.CommandAMTInfo, (*got).Command)
+		assert.True(t, (*got).JsonOutput)
+		assert.Equal(t, flags.AmtInfoFlags{}, (*got).AmtInfo, "the defaults are selected when the flags are validated")
+		assert.Equal(t, "16.1.25", resp.Version)
+		assert.Equal(t, "activated in client control mode", resp.ControlMode)
+		assert.Equal(t, "direct", resp.RAS.NetworkStatus)
+	})
+	t.Run("passes selection", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, `{"uuid":"1234"}`)
+		resp, err := Info(context.Background(), InfoRequest{UUID: true})
+		assert.NoError(t, err)
+		assert.Equal(t, flags.AmtInfoFlags{UUID: true}, (*got).AmtInfo)
+		assert.Equal(t, "1234", resp.UUID)
+	})
+	t.Run("passes hardware inventory", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, `{"hardware":{"manufacturer":"Intel Corporation","memoryMB":16384}}`)
+		resp, err := Info(context.Background(), InfoRequest{Hardware: true})
+		assert.NoError(t, err)
+		assert.Equal(t, flags.AmtInfoFlags{Hardware: true}, (*got).AmtInfo)
+		assert.Equal(t, "Intel Corporation", resp.Hardware.Manufacturer)
+		assert.Equal(t, uint64(16384), resp.Hardware.MemoryMB)
+	})
+	t.Run("passes audit paging", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, `{"auditLog":{"totalRecords":2,"records":[{"eventId":1}]}}`)
+		resp, err := Info(context.Background(), InfoRequest{Audit: true, AuditCount: 1, Password: "P@ssw0rd"})
+		assert.NoError(t, err)
+		assert.Equal(t, flags.AmtInfoFlags{Audit: true, AuditCount: 1}, (*got).AmtInfo)
+		assert.Equal(t, "P@ssw0rd", (*got).Password)
+		assert.Equal(t, 2, resp.AuditLog.TotalRecords)
+		assert.Equal(t, 1, resp.AuditLog.Records[0].EventID)
+	})
+	t.Run("passes redirection state", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, `{"redirection":{"listener":true,"kvm":true,"sol":false,"ider":false}}`)
+		resp, err := Info(context.Background(), InfoRequest{Redirection: true, Password: "P@ssw0rd"})
+		assert.NoError(t, err)
+		assert.Equal(t, flags.AmtInfoFlags{Redirection: true}, (*got).AmtInfo)
+		assert.Equal(t, RedirectionState{Listener: true, KVM: true}, *resp.Redirection)
+	})
+	t.Run("requires password for user certificates", func(t *testing.T) {
+		mockExecute(t, utils.Success, "")
+		_, err := Info(context.Background(), InfoRequest{UserCert: true})
+		assert.ErrorIs(t, err, rpcerr.New(utils.MissingOrIncorrectPassword, ""))
+	})
+}
+
+func TestContextCancellation(t *testing.T) {
+	t.Run("done before start", func(t *testing.T) {
+		got := mockExecute(t, utils.Success, "")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Info(ctx, InfoRequest{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, *got)
+	})
+	t.Run("deadline while running waits for the command to stop", func(t *testing.T) {
+		stopped := false
+		original := execute
+		execute = func(f *flags.Flags, out io.Writer) error {
+			<-f.Context.Done()
+			stopped = true
+			return rpcerr.FromReturnCode(utils.CancelledByUser)
+		}
+		t.Cleanup(func() { execute = original })
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		result, err := Maintenance(ctx, MaintenanceRequest{Task: utils.SubCommandSyncClock, ConnectionOptions: ConnectionOptions{Password: "P@ssw0rd"}})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, utils.CancelledByUser, result.ReturnCode)
+		assert.True(t, stopped)
+	})
+}
+
+func TestCommandsRunOneAtATime(t *testing.T) {
+	var running, overlapped int32
+	original := execute
+	execute = func(f *flags.Flags, out io.Writer) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+	t.Cleanup(func() { execute = original })
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Maintenance(context.Background(), MaintenanceRequest{Task: utils.SubCommandSyncClock, ConnectionOptions: ConnectionOptions{Password: "P@ssw0rd"}})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), overlapped)
+}
+
+func TestExecuteDoesNotReadTheEnvironment(t *testing.T) {
+	t.Setenv("AMT_PASSWORD", "P@ssw0rd")
+	// without a password in the request the parser fails instead of using AMT_PASSWORD or prompting
+	req := DeactivateRequest{}
+	req.URL = "wss://localhost/activate"
+	err := execute(req.flags(context.Background()), io.Discard)
+	assert.Equal(t, utils.MissingOrIncorrectPassword, rpcerr.ReturnCodeOf(err))
+}
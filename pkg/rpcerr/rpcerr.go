@@ -0,0 +1,120 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+
+// Package rpcerr defines the error returned by failed RPC commands. It carries
+// the ReturnCode used as exit status, so callers get the same machine-readable
+// failure from the library, the command line and JSON output.
+package rpcerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"rpc/pkg/utils"
+)
+
+// Error reports a failed command
+type Error struct {
+	ReturnCode utils.ReturnCode
+	// Code is the stable name of the return code, ex. MissingOrIncorrectPassword
+	Code    string
+	Message string
+	Cause   error
+	// Translation is the message in the language of the text output, Message stays the
+	// English one of the JSON output, the log and the library
+	Translation string
+}
+
+// New returns the error for a return code with a message for the user, the message may be empty
+func New(rc utils.ReturnCode, message string) *Error {
+	return &Error{ReturnCode: rc, Code: rc.String(), Message: message}
+}
+
+// Newf returns the error for a return code with a formatted message
+func Newf(rc utils.ReturnCode, format string, args ...interface{}) *Error {
+	return New(rc, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns the error for a return code caused by another error
+func Wrap(rc utils.ReturnCode, cause error, message string) *Error {
+	e := New(rc, message)
+	e.Cause = cause
+	return e
+}
+
+// FromReturnCode returns nil for Success and the error for any other return code
+func FromReturnCode(rc utils.ReturnCode) error {
+	if rc == utils.Success {
+		return nil
+	}
+	return New(rc, "")
+}
+
+// ReturnCodeOf returns the return code carried by err. It is Success for nil
+// and GenericFailure for errors that do not carry a return code.
+func ReturnCodeOf(err error) utils.ReturnCode {
+	if err == nil {
+		return utils.Success
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.ReturnCode
+	}
+	return utils.GenericFailure
+}
+
+func (e *Error) Error() string {
+	return e.withCause(e.Message)
+}
+
+// TranslatedError returns the error with its Translation, or Error when it has none
+func (e *Error) TranslatedError() string {
+	if e.Translation == "" {
+		return e.Error()
+	}
+	return e.withCause(e.Translation)
+}
+
+func (e *Error) withCause(message string) string {
+	if message == "" {
+		message = fmt.Sprintf("rpc failed with return code %d (%s)", e.ReturnCode, e.ReturnCode)
+	}
+	if e.Cause != nil {
+		message = message + ": " + e.Cause.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches errors with the same return code, ex. errors.Is(err, rpcerr.New(utils.AmtNotDetected, ""))
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.ReturnCode == e.ReturnCode
+}
+
+// MarshalJSON writes the error with the message of its cause, the message
+// defaults to the return code when none was given
+func (e *Error) MarshalJSON() ([]byte, error) {
+	out := struct {
+		ReturnCode utils.ReturnCode `json:"returnCode"`
+		Code       string           `json:"code"`
+		Message    string           `json:"message"`
+		Cause      string           `json:"cause,omitempty"`
+	}{
+		ReturnCode: e.ReturnCode,
+		Code:       e.Code,
+		Message:    e.Message,
+	}
+	if e.Cause != nil {
+		out.Cause = e.Cause.Error()
+	}
+	if out.Message == "" && e.Cause == nil {
+		out.Message = e.Error()
+	}
+	return json.Marshal(out)
+}
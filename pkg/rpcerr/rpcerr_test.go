@@ -0,0 +1,71 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package rpcerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"rpc/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	t.Run("reports the message", func(t *testing.T) {
+		err := New(utils.MissingOrIncorrectURL, "-u flag is required and cannot be empty")
+		assert.EqualError(t, err, "-u flag is required and cannot be empty")
+		assert.Equal(t, "MissingOrIncorrectURL", err.Code)
+	})
+	t.Run("reports the return code without message", func(t *testing.T) {
+		assert.EqualError(t, New(utils.ActivationFailed, ""), "rpc failed with return code 102 (ActivationFailed)")
+	})
+	t.Run("reports the cause", func(t *testing.T) {
+		cause := errors.New("handle is invalid")
+		err := Wrap(utils.HECIDriverNotDetected, cause, "unable to open the MEI connection")
+		assert.EqualError(t, err, "unable to open the MEI connection: handle is invalid")
+		assert.ErrorIs(t, err, cause)
+	})
+	t.Run("formats the message", func(t *testing.T) {
+		assert.EqualError(t, Newf(utils.InvalidUUID, "invalid uuid %s", "x"), "invalid uuid x")
+	})
+	t.Run("reports the translation of the message", func(t *testing.T) {
+		err := Wrap(utils.FailedReadingConfiguration, errors.New("no such file"), "unable to read the document")
+		assert.Equal(t, "unable to read the document: no such file", err.TranslatedError())
+		err.Translation = "no se puede leer el documento"
+		assert.Equal(t, "no se puede leer el documento: no such file", err.TranslatedError())
+		assert.EqualError(t, err, "unable to read the document: no such file")
+	})
+	t.Run("matches errors with the same return code", func(t *testing.T) {
+		err := fmt.Errorf("activate: %w", New(utils.UnableToActivate, "already activated"))
+		assert.ErrorIs(t, err, New(utils.UnableToActivate, ""))
+		assert.NotErrorIs(t, err, New(utils.ActivationFailed, ""))
+	})
+}
+
+func TestReturnCodeOf(t *testing.T) {
+	assert.Equal(t, utils.Success, ReturnCodeOf(nil))
+	assert.Equal(t, utils.InvalidUUID, ReturnCodeOf(fmt.Errorf("wrapped: %w", New(utils.InvalidUUID, ""))))
+	assert.Equal(t, utils.GenericFailure, ReturnCodeOf(errors.New("plain error")))
+}
+
+func TestFromReturnCode(t *testing.T) {
+	assert.Nil(t, FromReturnCode(utils.Success))
+	assert.Equal(t, utils.AmtNotReady, ReturnCodeOf(FromReturnCode(utils.AmtNotReady)))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	t.Run("writes code, message and cause", func(t *testing.T) {
+		out, err := json.Marshal(Wrap(utils.HECIDriverNotDetected, errors.New("handle is invalid"), "unable to open the MEI connection"))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"returnCode": 2, "code": "HECIDriverNotDetected", "message": "unable to open the MEI connection", "cause": "handle is invalid"}`, string(out))
+	})
+	t.Run("defaults the message to the return code", func(t *testing.T) {
+		out, err := json.Marshal(New(utils.ActivationFailed, ""))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"returnCode": 102, "code": "ActivationFailed", "message": "rpc failed with return code 102 (ActivationFailed)"}`, string(out))
+	})
+}
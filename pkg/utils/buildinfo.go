@@ -0,0 +1,54 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// CommitHash and BuildDate are set at build time with
+// -ldflags "-X rpc/pkg/utils.CommitHash=<sha> -X rpc/pkg/utils.BuildDate=<RFC3339 date>".
+// When they are not set the VCS information Go embeds in the binary is used.
+var (
+	CommitHash string
+	BuildDate  string
+)
+
+const unknownBuildInfo = "unknown"
+
+// BuildInfo returns the commit and date the executable was built from
+func BuildInfo() (commit string, date string) {
+	commit, date = CommitHash, BuildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		modified := false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if commit == "" {
+					commit = setting.Value
+				}
+			case "vcs.time":
+				if date == "" {
+					date = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && CommitHash == "" && commit != "" {
+			commit += "-dirty"
+		}
+	}
+	if commit == "" {
+		commit = unknownBuildInfo
+	}
+	if date == "" {
+		date = unknownBuildInfo
+	} else if t, err := time.Parse(time.RFC3339, date); err == nil {
+		date = t.UTC().Format(time.RFC3339)
+	}
+	return commit, date
+}
@@ -0,0 +1,28 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInfo(t *testing.T) {
+	origCommit, origDate := CommitHash, BuildDate
+	defer func() { CommitHash, BuildDate = origCommit, origDate }()
+
+	CommitHash = "0123abcd"
+	BuildDate = "2024-05-01T10:00:00+02:00"
+	commit, date := BuildInfo()
+	assert.Equal(t, "0123abcd", commit)
+	assert.Equal(t, "2024-05-01T08:00:00Z", date)
+
+	// test binaries carry no VCS information
+	CommitHash, BuildDate = "", ""
+	commit, date = BuildInfo()
+	assert.Equal(t, "unknown", commit)
+	assert.Equal(t, "unknown", date)
+}
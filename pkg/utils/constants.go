@@ -12,6 +12,8 @@ const (
 	// ProjectVersion is the full version of this executable
 	ProjectVersion  = "2.21.0"
 	ProtocolVersion = "4.0.0"
+	// MinAMTVersion is the oldest AMT firmware release rpc supports
+	MinAMTVersion = "11.0.0"
 	// ClientName is the name of the exectable
 	ClientName = "RPC"
 
@@ -19,24 +21,67 @@ const (
 	LMSAddress = "localhost"
 	// LMSPort is used for determining what port to connect to LMS on
 	LMSPort = "16992"
+	// LMSTLSPort is the port of LMS once TLS is enabled on the local interface of AMT
+	LMSTLSPort = "16993"
 
 	// MPSServerMaxLength is the max length of the servername
 	MPSServerMaxLength = 256
 
+	// MinPasswordLength and MaxPasswordLength bound AMT strong passwords
+	MinPasswordLength = 8
+	MaxPasswordLength = 32
+	// DefaultGeneratedPasswordLength is the length of the passwords rpc generates by default
+	DefaultGeneratedPasswordLength = 16
+
 	CommandActivate    = "activate"
+	CommandAgent       = "agent"
 	CommandAMTInfo     = "amtinfo"
 	CommandDeactivate  = "deactivate"
 	CommandMaintenance = "maintenance"
+	CommandReturnCodes = "returncodes"
 	CommandVersion     = "version"
 	CommandConfigure   = "configure"
+	CommandService     = "service"
+	CommandPower       = "power"
+	CommandStatus      = "status"
+	CommandCheckCert   = "checkcert"
+	CommandWSMAN       = "wsman"
+	CommandBulk        = "bulk"
+	CommandSelfTest    = "selftest"
+	CommandApply       = "apply"
+	CommandHelp        = "help"
+	CommandSOL         = "sol"
+	CommandBoot        = "boot"
+	CommandDiag        = "diag"
 
 	SubCommandAddWifiSettings = "addwifisettings"
 	SubCommandEnableWifiPort  = "enablewifiport"
+	SubCommandConfigureTLS    = "tlssettings"
+	SubCommandConfigureCIRA   = "cira"
+	SubCommandConfigureMPS    = "mps"
+	SubCommandWired8021x      = "wired8021x"
+	SubCommandAlarmClock      = "alarmclock"
+	SubCommandRedirection     = "redirection"
+	SubCommandDNSSuffix       = "dnssuffix"
+	SubCommandAMTFeatures     = "amtfeatures"
+	SubCommandCertHash        = "certhash"
 	SubCommandChangePassword  = "changepassword"
 	SubCommandSyncDeviceInfo  = "syncdeviceinfo"
 	SubCommandSyncClock       = "syncclock"
 	SubCommandSyncHostname    = "synchostname"
 	SubCommandSyncIP          = "syncip"
+	SubCommandSyncDNS         = "syncdns"
+	SubCommandSyncWifi        = "syncwifi"
+
+	SubCommandServiceInstall   = "install"
+	SubCommandServiceUninstall = "uninstall"
+	SubCommandServiceStart     = "start"
+	SubCommandServiceStop      = "stop"
+
+	SubCommandPowerOn    = "on"
+	SubCommandPowerOff   = "off"
+	SubCommandPowerReset = "reset"
+	SubCommandPowerCycle = "cycle"
 
 	// Return Codes
 	Success ReturnCode = 0
@@ -48,6 +93,20 @@ const (
 	HECIDriverNotDetected ReturnCode = 2
 	AmtNotDetected        ReturnCode = 3
 	AmtNotReady           ReturnCode = 4
+	// DryRunCompleted is returned instead of Success when -dryrun made no changes
+	DryRunCompleted ReturnCode = 5
+	// GenericFailure is returned for errors that do not carry a more specific return code
+	GenericFailure ReturnCode = 6
+	// UnsupportedPlatform is returned where rpc has no MEI driver for the OS or CPU architecture
+	UnsupportedPlatform ReturnCode = 7
+	// MEITimeout is returned when the MEI driver does not answer within -timeout
+	MEITimeout ReturnCode = 8
+	// NotAdministrator is returned when the MEI device is present but rpc runs without administrator or root privileges
+	NotAdministrator ReturnCode = 9
+	// MEIDriverMissing is returned when no MEI device is present
+	MEIDriverMissing ReturnCode = 10
+	// CancelledByUser is returned when rpc was stopped with SIGINT or SIGTERM before the command completed
+	CancelledByUser ReturnCode = 11
 
 	// (20-69) Input errors to RPC
 	MissingOrIncorrectURL              ReturnCode = 20
@@ -68,11 +127,22 @@ const (
 	MissingOrInvalidConfiguration      ReturnCode = 35
 	InvalidUserInput                   ReturnCode = 36
 	InvalidUUID                        ReturnCode = 37
+	MissingOrIncorrectMEBxPassword     ReturnCode = 38
+	MissingOrIncorrectMQTTBroker       ReturnCode = 39
+	MissingOrIncorrectCACert           ReturnCode = 40
+	// DNSSuffixMismatch is returned when the DNS suffix is not the domain of the provisioning certificate
+	DNSSuffixMismatch ReturnCode = 41
+	// InvalidProvisioningCert is returned when the provisioning certificate can not be decrypted or its chain does not verify
+	InvalidProvisioningCert ReturnCode = 42
+	// InputRequired is returned with -nonInteractive when rpc would have to prompt for a confirmation or other input
+	InputRequired ReturnCode = 43
 
 	// (70-99) Connection Errors
 	RPSAuthenticationFailed         ReturnCode = 70
 	AMTConnectionFailed             ReturnCode = 71
 	OSNetworkInterfacesLookupFailed ReturnCode = 72
+	// ControlAPIFailed is returned when the agent can not serve the control API on the -control address
+	ControlAPIFailed ReturnCode = 73
 
 	// (100-149) Activation, and configuration errors
 	AMTAuthenticationFailed           ReturnCode = 100
@@ -92,6 +162,39 @@ const (
 	DeleteWifiConfigFailed            ReturnCode = 114
 	MissingOrIncorrectWifiProfileName ReturnCode = 116
 	MissingIeee8021xConfiguration     ReturnCode = 117
+	SetMEBxPasswordFailed             ReturnCode = 118
+	ServiceCommandFailed              ReturnCode = 119
+	DeactivationIncomplete            ReturnCode = 120
+	PowerActionFailed                 ReturnCode = 121
+	StorageWipeFailed                 ReturnCode = 122
+	StatusCheckWarning                ReturnCode = 123
+	StatusCheckFailed                 ReturnCode = 124
+	ClockSkewExceeded                 ReturnCode = 125
+	CertHashNotFound                  ReturnCode = 126
+	AlarmClockConfigurationFailed     ReturnCode = 127
+	RedirectionConfigurationFailed    ReturnCode = 128
+	DNSSuffixConfigurationFailed      ReturnCode = 129
+	AMTStateChangeNotAllowed          ReturnCode = 130
+	AMTStateChangeFailed              ReturnCode = 131
+	ActivationInterrupted             ReturnCode = 132
+	NoActivationToResume              ReturnCode = 133
+	SelfTestFailed                    ReturnCode = 134
+	// SOLSessionFailed is returned when AMT refuses the Serial-over-LAN session or it ends with an error
+	SOLSessionFailed ReturnCode = 135
+	// BootConfigurationFailed is returned when AMT does not accept the boot source of rpc boot
+	BootConfigurationFailed ReturnCode = 136
+	// CertHashConfigurationFailed is returned when AMT does not list, add or delete its trusted root certificate hashes
+	CertHashConfigurationFailed ReturnCode = 137
+	// DiagBundleFailed is returned when rpc diag can not write the diagnostics bundle
+	DiagBundleFailed ReturnCode = 138
+	// UnsupportedBySKU is returned when the SKU of the firmware lacks the feature a command configures
+	UnsupportedBySKU ReturnCode = 139
+	// PartialConfigRolledBack is returned when a step of a TLS, CIRA or 802.1x configuration
+	// failed and the steps made before it were undone
+	PartialConfigRolledBack ReturnCode = 140
+	// CIRAConfigurationRemoved is returned when a step of a CIRA configuration failed and
+	// the steps made before it were undone, but the replaced CIRA configuration stays removed
+	CIRAConfigurationRemoved ReturnCode = 141
 
 	// (150-199) Maintenance Errors
 	SyncClockFailed      ReturnCode = 150
@@ -99,6 +202,8 @@ const (
 	SyncIpFailed         ReturnCode = 152
 	ChangePasswordFailed ReturnCode = 153
 	SyncDeviceInfoFailed ReturnCode = 154
+	SyncDNSFailed        ReturnCode = 155
+	SyncWifiFailed       ReturnCode = 156
 
 	// (200-299) KPMU
 
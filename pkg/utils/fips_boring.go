@@ -0,0 +1,15 @@
+//go:build boringcrypto
+// +build boringcrypto
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import "crypto/boring"
+
+// fipsModule is the FIPS 140 validated module crypto/rand reads from, when it is enabled
+func fipsModule() (string, bool) {
+	return "the SP 800-90A CTR_DRBG of the BoringCrypto module (FIPS 140-2)", boring.Enabled()
+}
@@ -0,0 +1,15 @@
+//go:build go1.24 && !boringcrypto
+// +build go1.24,!boringcrypto
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import "crypto/fips140"
+
+// fipsModule is the FIPS 140 validated module crypto/rand reads from, when it is enabled
+func fipsModule() (string, bool) {
+	return "the SP 800-90A CTR_DRBG of the Go Cryptographic Module (FIPS 140-3)", fips140.Enabled()
+}
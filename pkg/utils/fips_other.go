@@ -0,0 +1,14 @@
+//go:build !go1.24 && !boringcrypto
+// +build !go1.24,!boringcrypto
+
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+// fipsModule is the FIPS 140 validated module crypto/rand reads from, Go before 1.24
+// has none without BoringCrypto
+func fipsModule() (string, bool) {
+	return "", false
+}
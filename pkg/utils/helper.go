@@ -4,6 +4,21 @@
  **********************************************************************/
 package utils
 
+import (
+	"os"
+	"path/filepath"
+)
+
+// CacheDir returns the rpc folder of the user cache directory, rpc keeps the state it
+// needs between runs there
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rpc"), nil
+}
+
 func InterpretControlMode(mode int) string {
 	switch mode {
 	case 0:
@@ -17,6 +32,34 @@ func InterpretControlMode(mode int) string {
 	}
 }
 
+func InterpretProvisioningState(state int) string {
+	switch state {
+	case 0:
+		return "pre-provisioning"
+	case 1:
+		return "in-provisioning"
+	case 2:
+		return "post-provisioning"
+	default:
+		return "unknown state"
+	}
+}
+
+func InterpretProvisioningMode(mode int) string {
+	switch mode {
+	case 0:
+		return "none"
+	case 1:
+		return "enterprise"
+	case 2:
+		return "small business"
+	case 3:
+		return "remote connectivity"
+	default:
+		return "unknown"
+	}
+}
+
 func InterpretHashAlgorithm(hashAlgorithm int) (hashSize int, algorithm string) {
 	switch hashAlgorithm {
 	case 0: // MD5
@@ -29,6 +29,20 @@ func TestInterpretControlMode3(t *testing.T) {
 	assert.Equal(t, "unknown state", algorithm)
 }
 
+func TestInterpretProvisioningState(t *testing.T) {
+	assert.Equal(t, "pre-provisioning", InterpretProvisioningState(0))
+	assert.Equal(t, "in-provisioning", InterpretProvisioningState(1))
+	assert.Equal(t, "post-provisioning", InterpretProvisioningState(2))
+	assert.Equal(t, "unknown state", InterpretProvisioningState(3))
+}
+
+func TestInterpretProvisioningMode(t *testing.T) {
+	assert.Equal(t, "none", InterpretProvisioningMode(0))
+	assert.Equal(t, "enterprise", InterpretProvisioningMode(1))
+	assert.Equal(t, "remote connectivity", InterpretProvisioningMode(3))
+	assert.Equal(t, "unknown", InterpretProvisioningMode(4))
+}
+
 func TestInterpretHashAlgorithm0(t *testing.T) {
 	hashSize, algorithm := InterpretHashAlgorithm(0)
 	assert.Equal(t, "MD5", algorithm)
@@ -0,0 +1,148 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+const (
+	passwordUpper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordLower  = "abcdefghijklmnopqrstuvwxyz"
+	passwordDigits = "0123456789"
+	// passwordSymbols are the non alphanumeric characters of AMT strong passwords, AMT
+	// rejects : , and " and does not count _ as one
+	passwordSymbols = "!@#$%^&*()-=+[]{}.?"
+	// passwordPlainSymbols are the symbols of noSymbols, they need no quoting in shells,
+	// PowerShell, cmd or XML. AMT needs a symbol in every password.
+	passwordPlainSymbols = "+-./="
+)
+
+// ErrFIPSUnavailable is returned by the FIPS password generator of an rpc built or run
+// without a FIPS 140 validated crypto module
+var ErrFIPSUnavailable = errors.New("no FIPS 140 validated crypto module is enabled, build rpc with Go 1.24 or later and run it with GODEBUG=fips140=on, or build it with GOEXPERIMENT=boringcrypto")
+
+// PasswordGenerator generates the AMT passwords rpc sets, with changepassword -generate
+// and local CCM activation with -generatePassword
+type PasswordGenerator interface {
+	// Generate returns a random AMT strong password of the given length, noSymbols limits
+	// its symbols to the ones that need no quoting
+	Generate(length int, noSymbols bool) (string, error)
+	// RNG describes the random number generator the passwords are drawn from
+	RNG() string
+}
+
+// NewPasswordGenerator returns the generator of -fips when fips is set, the default
+// generator otherwise
+func NewPasswordGenerator(fips bool) PasswordGenerator {
+	if fips {
+		return FIPSPasswordGenerator{}
+	}
+	return RandomPasswordGenerator{}
+}
+
+// RandomPasswordGenerator draws the passwords from Reader, the CSPRNG of crypto/rand when
+// it is nil. Each character is drawn uniformly from its set with rejection sampling.
+type RandomPasswordGenerator struct {
+	Reader io.Reader
+}
+
+// FIPSPasswordGenerator draws the passwords from crypto/rand like RandomPasswordGenerator,
+// and fails unless crypto/rand is backed by a FIPS 140 validated module
+type FIPSPasswordGenerator struct{}
+
+// GeneratePassword returns a random AMT strong password of the given length from the
+// default generator
+func GeneratePassword(length int, noSymbols bool) (string, error) {
+	return RandomPasswordGenerator{}.Generate(length, noSymbols)
+}
+
+// Generate returns a random AMT strong password of the given length. It always holds an
+// upper case letter, a lower case letter, a digit and a symbol. With noSymbols the symbols
+// are taken from passwordPlainSymbols, which need no quoting in shells or XML.
+func (g RandomPasswordGenerator) Generate(length int, noSymbols bool) (string, error) {
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return "", errors.New("password length out of range")
+	}
+	reader := g.Reader
+	if reader == nil {
+		reader = rand.Reader
+	}
+	classes := []string{passwordUpper, passwordLower, passwordDigits, passwordSymbols}
+	if noSymbols {
+		classes[3] = passwordPlainSymbols
+	}
+	all := ""
+	for _, class := range classes {
+		all += class
+	}
+	password := make([]byte, length)
+	for i := range password {
+		set := all
+		// the first characters take one from each class, the shuffle below moves them
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomIndex(reader, len(set))
+		if err != nil {
+			return "", err
+		}
+		password[i] = set[c]
+	}
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(reader, i+1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// RNG describes the random number generator of the generator
+func (g RandomPasswordGenerator) RNG() string {
+	if g.Reader != nil {
+		return fmt.Sprintf("%T", g.Reader)
+	}
+	return "crypto/rand, the CSPRNG of the operating system (getrandom on Linux, ProcessPrng on Windows)"
+}
+
+// CheckFIPS returns ErrFIPSUnavailable unless crypto/rand is backed by a FIPS 140 validated module
+func CheckFIPS() error {
+	if _, enabled := fipsModule(); !enabled {
+		return ErrFIPSUnavailable
+	}
+	return nil
+}
+
+// Generate returns a random AMT strong password like RandomPasswordGenerator, or
+// ErrFIPSUnavailable
+func (FIPSPasswordGenerator) Generate(length int, noSymbols bool) (string, error) {
+	if err := CheckFIPS(); err != nil {
+		return "", err
+	}
+	return RandomPasswordGenerator{}.Generate(length, noSymbols)
+}
+
+// RNG describes the DRBG of the FIPS 140 validated module
+func (FIPSPasswordGenerator) RNG() string {
+	module, enabled := fipsModule()
+	if !enabled {
+		return "unavailable, " + ErrFIPSUnavailable.Error()
+	}
+	return module
+}
+
+func randomIndex(reader io.Reader, n int) (int, error) {
+	v, err := rand.Int(reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
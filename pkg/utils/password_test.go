@@ -0,0 +1,76 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	for _, length := range []int{MinPasswordLength, 16, MaxPasswordLength} {
+		password, err := GeneratePassword(length, false)
+		assert.NoError(t, err)
+		assert.Len(t, password, length)
+		assert.True(t, strings.ContainsAny(password, passwordUpper))
+		assert.True(t, strings.ContainsAny(password, passwordLower))
+		assert.True(t, strings.ContainsAny(password, passwordDigits))
+		assert.True(t, strings.ContainsAny(password, passwordSymbols))
+	}
+}
+
+func TestPasswordSymbolsAcceptedByAMT(t *testing.T) {
+	assert.False(t, strings.ContainsAny(passwordSymbols, `:,"_ `))
+	assert.False(t, strings.ContainsAny(passwordPlainSymbols, `:,"_ `))
+}
+
+func TestGeneratePasswordNoSymbols(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		password, err := GeneratePassword(MinPasswordLength, true)
+		assert.NoError(t, err)
+		assert.Len(t, password, MinPasswordLength)
+		assert.True(t, strings.ContainsAny(password, passwordDigits))
+		assert.True(t, strings.ContainsAny(password, passwordPlainSymbols), "AMT needs a symbol in the password")
+		assert.Equal(t, "", strings.Trim(password, passwordUpper+passwordLower+passwordDigits+passwordPlainSymbols), "only symbols that need no quoting")
+		assert.False(t, strings.ContainsAny(password, "!@#$%^&*()[]{}?'<>;|`\\"))
+	}
+}
+
+func TestGeneratePasswordLength(t *testing.T) {
+	_, err := GeneratePassword(MinPasswordLength-1, false)
+	assert.Error(t, err)
+	_, err = GeneratePassword(MaxPasswordLength+1, false)
+	assert.Error(t, err)
+}
+
+func TestRandomPasswordGeneratorReader(t *testing.T) {
+	// the same random bytes give the same password
+	seed := bytes.Repeat([]byte{0x5a, 0x13, 0xc7, 0x88}, 64)
+	first, err := RandomPasswordGenerator{Reader: bytes.NewReader(seed)}.Generate(16, false)
+	assert.NoError(t, err)
+	second, err := RandomPasswordGenerator{Reader: bytes.NewReader(seed)}.Generate(16, false)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	_, err = RandomPasswordGenerator{Reader: bytes.NewReader(nil)}.Generate(16, false)
+	assert.Error(t, err, "a reader without random bytes left fails")
+	assert.Equal(t, "*bytes.Reader", RandomPasswordGenerator{Reader: bytes.NewReader(nil)}.RNG())
+}
+
+func TestFIPSPasswordGenerator(t *testing.T) {
+	generator := NewPasswordGenerator(true)
+	password, err := generator.Generate(16, false)
+	if _, enabled := fipsModule(); !enabled {
+		assert.ErrorIs(t, err, ErrFIPSUnavailable)
+		assert.Contains(t, generator.RNG(), "unavailable")
+		return
+	}
+	assert.NoError(t, err)
+	assert.Len(t, password, 16)
+	assert.Contains(t, generator.RNG(), "CTR_DRBG")
+}
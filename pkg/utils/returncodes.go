@@ -0,0 +1,145 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import "fmt"
+
+// ReturnCodeInfo describes a ReturnCode for scripts mapping exit statuses to actions
+type ReturnCodeInfo struct {
+	Code        ReturnCode `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+}
+
+// ReturnCodes lists every ReturnCode in ascending order
+var ReturnCodes = []ReturnCodeInfo{
+	{Success, "Success", "command completed successfully"},
+
+	{IncorrectPermissions, "IncorrectPermissions", "not running with administrator or root privileges"},
+	{HECIDriverNotDetected, "HECIDriverNotDetected", "the MEI/HECI driver was not detected"},
+	{AmtNotDetected, "AmtNotDetected", "Intel AMT was not detected on this device"},
+	{AmtNotReady, "AmtNotReady", "Intel AMT is not ready"},
+	{DryRunCompleted, "DryRunCompleted", "the command was checked with -dryrun, nothing was changed"},
+	{GenericFailure, "GenericFailure", "the command failed without a more specific return code"},
+	{UnsupportedPlatform, "UnsupportedPlatform", "rpc has no MEI driver support for this operating system or CPU architecture"},
+	{MEITimeout, "MEITimeout", "the MEI driver did not answer within -timeout, or the command was cancelled"},
+	{NotAdministrator, "NotAdministrator", "rpc needs administrator or root privileges to open the MEI device"},
+	{MEIDriverMissing, "MEIDriverMissing", "no MEI device was found, the MEI driver is not installed or Intel ME is disabled"},
+	{CancelledByUser, "CancelledByUser", "rpc was interrupted with Ctrl+C or SIGTERM before the command completed"},
+
+	{MissingOrIncorrectURL, "MissingOrIncorrectURL", "the server URL is missing or invalid"},
+	{MissingOrIncorrectProfile, "MissingOrIncorrectProfile", "the profile is missing or invalid"},
+	{ServerCerificateVerificationFailed, "ServerCerificateVerificationFailed", "the server certificate could not be verified"},
+	{MissingOrIncorrectPassword, "MissingOrIncorrectPassword", "the AMT password is missing or incorrect"},
+	{MissingDNSSuffix, "MissingDNSSuffix", "the DNS suffix is missing"},
+	{MissingHostname, "MissingHostname", "the hostname is missing"},
+	{MissingProxyAddressAndPort, "MissingProxyAddressAndPort", "the proxy address or port is missing or invalid"},
+	{MissingOrIncorrectStaticIP, "MissingOrIncorrectStaticIP", "the static IP address is missing or invalid"},
+	{IncorrectCommandLineParameters, "IncorrectCommandLineParameters", "the command line parameters are invalid"},
+	{MissingOrIncorrectNetworkMask, "MissingOrIncorrectNetworkMask", "the network mask is missing or invalid"},
+	{MissingOrIncorrectGateway, "MissingOrIncorrectGateway", "the gateway is missing or invalid"},
+	{MissingOrIncorrectPrimaryDNS, "MissingOrIncorrectPrimaryDNS", "the primary DNS is missing or invalid"},
+	{MissingOrIncorrectSecondaryDNS, "MissingOrIncorrectSecondaryDNS", "the secondary DNS is missing or invalid"},
+	{InvalidParameterCombination, "InvalidParameterCombination", "the combination of command line parameters is not allowed"},
+	{FailedReadingConfiguration, "FailedReadingConfiguration", "the configuration could not be read"},
+	{MissingOrInvalidConfiguration, "MissingOrInvalidConfiguration", "the configuration is missing or invalid"},
+	{InvalidUserInput, "InvalidUserInput", "the user input is invalid"},
+	{InvalidUUID, "InvalidUUID", "the UUID is invalid"},
+	{MissingOrIncorrectMEBxPassword, "MissingOrIncorrectMEBxPassword", "the MEBx password is missing or does not meet the complexity rules"},
+	{MissingOrIncorrectMQTTBroker, "MissingOrIncorrectMQTTBroker", "the MQTT broker address is missing or invalid"},
+	{MissingOrIncorrectCACert, "MissingOrIncorrectCACert", "the -cacert file or -pin-sha256 hashes of the server are missing or invalid"},
+	{DNSSuffixMismatch, "DNSSuffixMismatch", "the DNS suffix does not match the domain of the provisioning certificate"},
+	{InvalidProvisioningCert, "InvalidProvisioningCert", "the provisioning certificate can not be decrypted or its chain does not verify"},
+	{InputRequired, "InputRequired", "a confirmation or other input is required, which -nonInteractive does not prompt for"},
+
+	{RPSAuthenticationFailed, "RPSAuthenticationFailed", "authentication with the server failed"},
+	{AMTConnectionFailed, "AMTConnectionFailed", "the connection to AMT failed"},
+	{OSNetworkInterfacesLookupFailed, "OSNetworkInterfacesLookupFailed", "the OS network interfaces could not be read"},
+	{ControlAPIFailed, "ControlAPIFailed", "the agent could not serve the control API on the -control address or read its token"},
+
+	{AMTAuthenticationFailed, "AMTAuthenticationFailed", "authentication with AMT failed"},
+	{WSMANMessageError, "WSMANMessageError", "a WSMAN message failed"},
+	{ActivationFailed, "ActivationFailed", "activation failed"},
+	{NetworkConfigurationFailed, "NetworkConfigurationFailed", "network configuration failed"},
+	{CIRAConfigurationFailed, "CIRAConfigurationFailed", "CIRA configuration failed"},
+	{TLSConfigurationFailed, "TLSConfigurationFailed", "TLS configuration failed"},
+	{WiFiConfigurationFailed, "WiFiConfigurationFailed", "wifi configuration failed"},
+	{AMTFeaturesConfigurationFailed, "AMTFeaturesConfigurationFailed", "AMT features configuration failed"},
+	{Ieee8021xConfigurationFailed, "Ieee8021xConfigurationFailed", "ieee8021x configuration failed"},
+	{UnableToDeactivate, "UnableToDeactivate", "the device could not be deactivated"},
+	{DeactivationFailed, "DeactivationFailed", "deactivation failed"},
+	{UnableToActivate, "UnableToActivate", "the device could not be activated"},
+	{WifiConfigurationWithWarnings, "WifiConfigurationWithWarnings", "wifi configuration completed with warnings"},
+	{UnmarshalMessageFailed, "UnmarshalMessageFailed", "a response message could not be parsed"},
+	{DeleteWifiConfigFailed, "DeleteWifiConfigFailed", "an existing wifi configuration could not be deleted"},
+	{MissingOrIncorrectWifiProfileName, "MissingOrIncorrectWifiProfileName", "the wifi profile name is missing or invalid"},
+	{MissingIeee8021xConfiguration, "MissingIeee8021xConfiguration", "the ieee8021x configuration is missing"},
+	{SetMEBxPasswordFailed, "SetMEBxPasswordFailed", "the device was activated but setting the MEBx password failed"},
+	{ServiceCommandFailed, "ServiceCommandFailed", "installing, removing, starting or stopping the rpc service failed"},
+	{DeactivationIncomplete, "DeactivationIncomplete", "AMT accepted the unprovision request but did not return to pre-provisioning"},
+	{PowerActionFailed, "PowerActionFailed", "AMT did not change the power state or the boot options"},
+	{StorageWipeFailed, "StorageWipeFailed", "the device was deactivated but deactivate -wipe could not remove all configuration"},
+	{StatusCheckWarning, "StatusCheckWarning", "rpc status found a check that needs attention (WARN)"},
+	{StatusCheckFailed, "StatusCheckFailed", "rpc status found a failed check (FAIL)"},
+	{ClockSkewExceeded, "ClockSkewExceeded", "the host clock differs from the server or NTP time by more than -maxSkew"},
+	{CertHashNotFound, "CertHashNotFound", "AMT has no active trusted root certificate hash for the provisioning certificate"},
+	{AlarmClockConfigurationFailed, "AlarmClockConfigurationFailed", "AMT did not list, add or delete the wake alarms"},
+	{RedirectionConfigurationFailed, "RedirectionConfigurationFailed", "AMT did not change the KVM, SOL or IDE-R redirection state"},
+	{DNSSuffixConfigurationFailed, "DNSSuffixConfigurationFailed", "AMT did not set the PKI DNS suffix, it is only set before activation"},
+	{AMTStateChangeNotAllowed, "AMTStateChangeNotAllowed", "the BIOS does not allow enabling or disabling AMT from the OS"},
+	{AMTStateChangeFailed, "AMTStateChangeFailed", "AMT did not change to the requested state"},
+	{ActivationInterrupted, "ActivationInterrupted", "the connection to RPS was lost during the activation of the device, run activate again"},
+	{NoActivationToResume, "NoActivationToResume", "activate -resume found no interrupted activation of the device"},
+	{SelfTestFailed, "SelfTestFailed", "rpc selftest found a failed check (FAIL)"},
+	{SOLSessionFailed, "SOLSessionFailed", "AMT refused the Serial-over-LAN session or the session ended with an error"},
+	{BootConfigurationFailed, "BootConfigurationFailed", "AMT did not accept the boot settings, the boot configuration role or the boot order"},
+	{CertHashConfigurationFailed, "CertHashConfigurationFailed", "AMT did not list, add or delete the trusted root certificate hashes"},
+	{DiagBundleFailed, "DiagBundleFailed", "rpc diag could not write the diagnostics bundle"},
+	{UnsupportedBySKU, "UnsupportedBySKU", "the firmware SKU, such as Standard Manageability, does not have the feature or can not be activated"},
+	{PartialConfigRolledBack, "PartialConfigRolledBack", "a configuration step failed and the changes made before it were rolled back"},
+	{CIRAConfigurationRemoved, "CIRAConfigurationRemoved", "a CIRA configuration step failed, the changes were rolled back and the replaced CIRA configuration stays removed"},
+
+	{SyncClockFailed, "SyncClockFailed", "syncing the clock failed"},
+	{SyncHostnameFailed, "SyncHostnameFailed", "syncing the hostname failed"},
+	{SyncIpFailed, "SyncIpFailed", "syncing the IP configuration failed"},
+	{ChangePasswordFailed, "ChangePasswordFailed", "changing the AMT password failed"},
+	{SyncDeviceInfoFailed, "SyncDeviceInfoFailed", "syncing the device info failed"},
+	{SyncDNSFailed, "SyncDNSFailed", "syncing the DNS suffix or DNS servers failed"},
+	{SyncWifiFailed, "SyncWifiFailed", "reading the wifi profiles of the host OS failed or none can be synced"},
+
+	{AmtPtStatusCodeBase, "AmtPtStatusCodeBase", "AMT returned a PT status code, which is added to this base value"},
+}
+
+var returnCodeNames = func() map[ReturnCode]string {
+	names := make(map[ReturnCode]string, len(ReturnCodes))
+	for _, info := range ReturnCodes {
+		names[info.Code] = info.Name
+	}
+	return names
+}()
+
+// String returns the name of the return code
+func (rc ReturnCode) String() string {
+	if name, ok := returnCodeNames[rc]; ok {
+		return name
+	}
+	if rc > AmtPtStatusCodeBase && rc <= AmtPtStatusCodeBase+2000 {
+		return fmt.Sprintf("AmtPtStatusCode(%d)", rc-AmtPtStatusCodeBase)
+	}
+	return fmt.Sprintf("ReturnCode(%d)", int(rc))
+}
+
+// Description returns what the return code means, the description of ReturnCodes
+func (rc ReturnCode) Description() string {
+	for _, info := range ReturnCodes {
+		if info.Code == rc {
+			return info.Description
+		}
+	}
+	if rc > AmtPtStatusCodeBase && rc <= AmtPtStatusCodeBase+2000 {
+		return fmt.Sprintf("AMT returned the PT status code %d", rc-AmtPtStatusCodeBase)
+	}
+	return "unknown return code"
+}
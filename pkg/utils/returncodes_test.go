@@ -0,0 +1,31 @@
+/*********************************************************************
+ * Copyright (c) Intel Corporation 2024
+ * SPDX-License-Identifier: Apache-2.0
+ **********************************************************************/
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReturnCodeString(t *testing.T) {
+	assert.Equal(t, "Success", Success.String())
+	assert.Equal(t, "MissingOrIncorrectPassword", MissingOrIncorrectPassword.String())
+	assert.Equal(t, "AmtPtStatusCode(38)", (AmtPtStatusCodeBase + 38).String())
+	assert.Equal(t, "ReturnCode(115)", ReturnCode(115).String())
+}
+
+func TestReturnCodesAreUniqueAndOrdered(t *testing.T) {
+	for i := 1; i < len(ReturnCodes); i++ {
+		assert.Less(t, ReturnCodes[i-1].Code, ReturnCodes[i].Code)
+		assert.NotEmpty(t, ReturnCodes[i].Description)
+	}
+}
+
+func TestReturnCodeDescription(t *testing.T) {
+	assert.Equal(t, "the AMT password is missing or incorrect", MissingOrIncorrectPassword.Description())
+	assert.Equal(t, "AMT returned the PT status code 38", (AmtPtStatusCodeBase + 38).Description())
+	assert.Equal(t, "unknown return code", ReturnCode(115).Description())
+}